@@ -1,7 +1,8 @@
-// Command thaimaturgy-bot runs the Telegram multiplayer bot standalone. It boots
+// Command thaimaturgy-bot runs the multiplayer chat bot standalone. It boots
 // the shared internal/ core (config, adventure, session, provider), then hands a
-// live virtual-DM session to internal/tgbot. The desktop app hosts the same bot
-// in-process; this binary is for running it headless.
+// live virtual-DM session to internal/chathost, playing on Telegram
+// (internal/tgbot), IRC (internal/ircbot) or both at one table. The desktop app
+// hosts the Telegram bot in-process; this binary is for running it headless.
 package main
 
 import (
@@ -11,8 +12,10 @@ import (
 	"os"
 
 	"github.com/theburrowhub/thaimaturgy/internal/auth"
+	"github.com/theburrowhub/thaimaturgy/internal/chathost"
 	"github.com/theburrowhub/thaimaturgy/internal/domain"
 	"github.com/theburrowhub/thaimaturgy/internal/engine"
+	"github.com/theburrowhub/thaimaturgy/internal/ircbot"
 	"github.com/theburrowhub/thaimaturgy/internal/mcpserve"
 	"github.com/theburrowhub/thaimaturgy/internal/mcptools"
 	"github.com/theburrowhub/thaimaturgy/internal/providers"
@@ -35,15 +38,22 @@ func main() {
 	sessionName := flag.String("session", "", "session name (default: <adventure>-telegram)")
 	token := flag.String("token", "", "Telegram bot token (overrides env/config)")
 	chatID := flag.Int64("chat", 0, "restrict to this chat id (overrides config; 0 = any)")
+	var irc ircbot.Options
+	flag.StringVar(&irc.Addr, "irc-server", "", "also play on this IRC server (host:port)")
+	flag.BoolVar(&irc.TLS, "irc-tls", false, "connect to the IRC server over TLS")
+	flag.StringVar(&irc.Nick, "irc-nick", "thaimaturgy", "the bot's IRC nickname")
+	flag.StringVar(&irc.Channel, "irc-channel", "", "the IRC channel to play in, e.g. #thaimaturgy")
 	flag.Parse()
+	irc.Password = os.Getenv("THAIM_IRC_PASSWORD")
+	irc.Key = os.Getenv("THAIM_IRC_CHANNEL_KEY")
 
-	if err := run(*advID, *sessionName, *token, *chatID); err != nil {
+	if err := run(*advID, *sessionName, *token, *chatID, irc); err != nil {
 		fmt.Fprintf(os.Stderr, "bot: %v\n", err)
 		os.Exit(1)
 	}
 }
 
-func run(advID, sessionName, token string, chatID int64) error {
+func run(advID, sessionName, token string, chatID int64, irc ircbot.Options) error {
 	store, err := storage.New()
 	if err != nil {
 		return err
@@ -68,8 +78,8 @@ func run(advID, sessionName, token string, chatID int64) error {
 	if token == "" {
 		token = config.TelegramToken
 	}
-	if token == "" {
-		return fmt.Errorf("no Telegram token: pass -token, set THAIM_TELEGRAM_TOKEN, or configure it")
+	if token == "" && irc.Addr == "" {
+		return fmt.Errorf("no Telegram token: pass -token, set THAIM_TELEGRAM_TOKEN, or configure it (or play on IRC with -irc-server)")
 	}
 	if chatID == 0 {
 		chatID = config.TelegramChatID
@@ -103,10 +113,21 @@ func run(advID, sessionName, token string, chatID int64) error {
 		return err
 	}
 
-	bot, err := tgbot.New(store, session, oracle, tgbot.Options{Token: token, ChatID: chatID, AllowedUsers: config.TelegramAllowedUsers})
-	if err != nil {
-		return err
+	var adapters []chathost.Adapter
+	if token != "" {
+		tg, err := tgbot.NewAdapter(tgbot.Options{Token: token, ChatID: chatID, AllowedUsers: config.TelegramAllowedUsers})
+		if err != nil {
+			return err
+		}
+		adapters = append(adapters, tg)
+	}
+	if irc.Addr != "" {
+		a, err := ircbot.New(irc)
+		if err != nil {
+			return err
+		}
+		adapters = append(adapters, a)
 	}
-	bot.Run(context.Background()) // blocks until interrupted
+	chathost.New(store, session, oracle, chathost.Options{}, adapters...).Run(context.Background()) // blocks until interrupted
 	return nil
 }
@@ -0,0 +1,161 @@
+// Command thaimaturgy-playtest plays an adventure headless: simulated players
+// declare actions round after round for the virtual DM, and the run ends with a
+// coverage report — rooms visited per zone, events triggered, NPCs met, scenes
+// reached, dead ends and tool errors. It uses the same ~/.thaimaturgy data (or
+// a THAIM_DATA_DIR volume) and provider configuration as the other binaries;
+// -stub plays with a scripted provider instead, offline and without a key.
+//
+// Usage:
+//
+//	thaimaturgy-playtest -adventure lost-mine                  # report to stdout
+//	thaimaturgy-playtest -adventure lost-mine -stub -rounds 30 # offline smoke test
+//	thaimaturgy-playtest -adventure lost-mine -format json -out report.json -save lost-mine-playtest
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/theburrowhub/thaimaturgy/internal/auth"
+	"github.com/theburrowhub/thaimaturgy/internal/domain"
+	"github.com/theburrowhub/thaimaturgy/internal/mcpserve"
+	"github.com/theburrowhub/thaimaturgy/internal/mcptools"
+	"github.com/theburrowhub/thaimaturgy/internal/playtest"
+	"github.com/theburrowhub/thaimaturgy/internal/providers"
+	"github.com/theburrowhub/thaimaturgy/internal/storage"
+)
+
+func main() {
+	// The oracle's Claude-CLI backend runs the session tools through this binary.
+	if len(os.Args) > 1 && os.Args[1] == mcptools.SubcommandArg {
+		if err := mcpserve.RunSubcommand(os.Args[2:]); err != nil {
+			fmt.Fprintln(os.Stderr, "mcp-tools:", err)
+			os.Exit(1)
+		}
+		return
+	}
+	if err := run(); err != nil {
+		fmt.Fprintln(os.Stderr, "error:", err)
+		os.Exit(1)
+	}
+}
+
+func run() error {
+	var (
+		advID       = flag.String("adventure", "", "adventure id to playtest (required)")
+		rounds      = flag.Int("rounds", playtest.DefaultRounds, "rounds to play after the opening")
+		players     = flag.Int("players", playtest.DefaultPlayers, "simulated players, one character each")
+		stub        = flag.Bool("stub", false, "play with a scripted provider: offline, no API key")
+		model       = flag.String("model", "", "override the DM's model id (default: from config)")
+		playerModel = flag.String("player-model", "", "model id for the simulated players (default: the DM's)")
+		format      = flag.String("format", "md", "report format: md | json")
+		out         = flag.String("out", "", "write the report to this file (default: stdout)")
+		save        = flag.String("save", "", "save the played session under this name")
+		timeout     = flag.Duration("timeout", time.Hour, "max time for the whole run")
+		quiet       = flag.Bool("quiet", false, "don't print each round as it is played")
+	)
+	flag.Parse()
+	if strings.TrimSpace(*advID) == "" {
+		flag.Usage()
+		return fmt.Errorf("-adventure <id> is required")
+	}
+	f := strings.ToLower(strings.TrimSpace(*format))
+	if f != "md" && f != "markdown" && f != "json" {
+		return fmt.Errorf("unknown -format %q (use md or json)", *format)
+	}
+
+	var store *storage.Storage
+	var err error
+	if dataDir := strings.TrimSpace(os.Getenv("THAIM_DATA_DIR")); dataDir != "" {
+		store, err = storage.NewWithPath(dataDir)
+	} else {
+		store, err = storage.New()
+	}
+	if err != nil {
+		return fmt.Errorf("storage: %w", err)
+	}
+	_ = store.LoadEnvFile()
+	config, err := store.LoadConfig()
+	if err != nil {
+		return fmt.Errorf("config: %w", err)
+	}
+	adv, err := store.LoadAdventure(*advID)
+	if err != nil {
+		return err
+	}
+
+	var dm providers.Provider
+	if *stub {
+		dm = playtest.NewStub(adv)
+		config.Model = "stub"
+	} else {
+		msg := auth.AutoConfigure(config)
+		if config.RunModel != "" {
+			config.Model = config.RunModel
+		}
+		if strings.TrimSpace(*model) != "" {
+			config.Model = *model
+		}
+		if dm = providers.New(config); dm == nil {
+			return fmt.Errorf("no AI provider configured; set an API key, or pass -stub to play offline")
+		}
+		fmt.Fprintf(os.Stderr, "provider: %s\n", msg)
+	}
+
+	name := *save
+	if name == "" {
+		name = adv.ID + "-playtest"
+	}
+	session := domain.NewSession(domain.NewSessionState(name, adv), adv, config)
+
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+	opts := playtest.Options{Rounds: *rounds, Players: *players, PlayerModel: *playerModel}
+	if !*quiet {
+		opts.Progress = func(round int, actions []domain.RoundAction, narration string) {
+			fmt.Fprintf(os.Stderr, "\n— round %d —\n", round)
+			for _, a := range actions {
+				fmt.Fprintf(os.Stderr, "%s: %s\n", a.CharacterName, a.Text)
+			}
+			if narration != "" {
+				fmt.Fprintf(os.Stderr, "DM: %s\n", narration)
+			}
+		}
+	}
+	fmt.Fprintf(os.Stderr, "playtesting %q: %d rounds, %d players…\n", adv.Title, *rounds, *players)
+	rep, runErr := playtest.Run(ctx, session, dm, opts)
+	if rep == nil {
+		return runErr
+	}
+
+	if *save != "" {
+		if err := store.SaveSession(session.State); err != nil {
+			return fmt.Errorf("save session: %w", err)
+		}
+		fmt.Fprintf(os.Stderr, "session saved as %q\n", *save)
+	}
+
+	var data []byte
+	if f == "json" {
+		if data, err = json.MarshalIndent(rep, "", "  "); err != nil {
+			return err
+		}
+		data = append(data, '\n')
+	} else {
+		data = []byte(rep.Markdown())
+	}
+	if *out == "" {
+		_, err = os.Stdout.Write(data)
+	} else if err = os.WriteFile(*out, data, 0644); err == nil {
+		fmt.Fprintf(os.Stderr, "report written to %s\n", *out)
+	}
+	if err != nil {
+		return err
+	}
+	return runErr
+}
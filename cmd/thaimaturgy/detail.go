@@ -5,6 +5,7 @@ import (
 	"strings"
 
 	"github.com/theburrowhub/thaimaturgy/internal/domain"
+	"github.com/theburrowhub/thaimaturgy/internal/srd"
 )
 
 // This file renders authored adventure content as readable Markdown for the GUI
@@ -95,7 +96,9 @@ func zoneMarkdown(z *domain.Zone) string {
 	return strings.TrimSpace(sb.String())
 }
 
-func roomMarkdown(r *domain.Room) string {
+// roomMarkdown renders a room; encounters carry their XP budget, rated against
+// the current party.
+func roomMarkdown(adv *domain.Adventure, r *domain.Room, party []domain.Character) string {
 	var sb strings.Builder
 	sb.WriteString(mdHeading(r.Name, r.ID))
 	sb.WriteString(mdQuote("Read-aloud", r.ReadAloud))
@@ -129,6 +132,14 @@ func roomMarkdown(r *domain.Room) string {
 				line += ": " + e.Description
 			}
 			sb.WriteString("- " + line + "\n")
+			if len(e.Creatures) > 0 {
+				b := adv.EncounterBudget(&e, srd.Lookup)
+				sb.WriteString("  - Creatures: " + strings.Join(e.Creatures, ", ") + "\n")
+				sb.WriteString("  - Budget: " + b.Summary(domain.PartyLevels(party)) + "\n")
+				if len(b.Unresolved) > 0 {
+					sb.WriteString("  - No stat block (not counted): " + strings.Join(b.Unresolved, ", ") + "\n")
+				}
+			}
 		}
 		sb.WriteString("\n")
 	}
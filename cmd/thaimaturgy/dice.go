@@ -54,7 +54,7 @@ func (g *gui) showDiceRoller() {
 	)
 
 	notation := widget.NewEntry()
-	notation.SetPlaceHolder("e.g. 2d6+3, 4d6, 1d20-1")
+	notation.SetPlaceHolder("e.g. 2d6+3, 4d6kh3, 2d20kh1+5, 1d8+2d6[fire]")
 	notation.OnSubmitted = func(s string) { roll(s) }
 	rollBtn := widget.NewButton("Roll", func() { roll(notation.Text) })
 	notationRow := container.NewBorder(nil, nil, nil, rollBtn, notation)
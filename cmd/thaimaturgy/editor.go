@@ -23,9 +23,11 @@ import (
 	"github.com/theburrowhub/thaimaturgy/internal/bookpdf"
 	"github.com/theburrowhub/thaimaturgy/internal/dmbook"
 	"github.com/theburrowhub/thaimaturgy/internal/domain"
+	"github.com/theburrowhub/thaimaturgy/internal/engine"
 	"github.com/theburrowhub/thaimaturgy/internal/ingest"
 	"github.com/theburrowhub/thaimaturgy/internal/nativeui"
 	"github.com/theburrowhub/thaimaturgy/internal/providers"
+	"github.com/theburrowhub/thaimaturgy/internal/srd"
 	"github.com/theburrowhub/thaimaturgy/internal/storage"
 )
 
@@ -81,6 +83,12 @@ type editor struct {
 	formHost   *fyne.Container
 	status     *widget.Label
 	currentUID string
+
+	// filterQuery narrows the tree to the nodes the adventure search index ranks
+	// for it; filter holds those node ids (plus the zones of matching rooms), nil
+	// when there is no query.
+	filterQuery string
+	filter      map[widget.TreeNodeID]bool
 }
 
 // useLocalBackend resets the (shared, reused) editor to persist to the on-disk
@@ -252,8 +260,12 @@ func (e *editor) buildUI() fyne.CanvasObject {
 		widget.NewButton("+Image", e.addImage),
 		widget.NewButton("Delete", e.deleteSelected),
 	)
+	e.filterQuery, e.filter = "", nil
+	filterEntry := widget.NewEntry()
+	filterEntry.SetPlaceHolder("Filter…")
+	filterEntry.OnChanged = e.setFilter
 	e.nav = e.buildTree()
-	left := widget.NewCard("Adventure", "", container.NewBorder(navTools, nil, nil, nil, e.nav))
+	left := widget.NewCard("Adventure", "", container.NewBorder(container.NewVBox(navTools, filterEntry), nil, nil, nil, e.nav))
 	form := widget.NewCard("Editor", "", container.NewVScroll(e.formHost))
 
 	split := container.NewHSplit(left, form)
@@ -279,7 +291,51 @@ func (e *editor) buildTree() *widget.Tree {
 	return t
 }
 
+// childUIDs lists a node's children, narrowed to the filter's matches when one
+// is set.
 func (e *editor) childUIDs(uid widget.TreeNodeID) []widget.TreeNodeID {
+	all := e.allChildUIDs(uid)
+	if e.filter == nil || uid == "" {
+		return all
+	}
+	var out []widget.TreeNodeID
+	for _, c := range all {
+		if e.filter[c] {
+			out = append(out, c)
+		}
+	}
+	return out
+}
+
+// setFilter narrows the tree to the content matching query, ranked by the same
+// full-text index as /search; an empty query shows everything again.
+func (e *editor) setFilter(query string) {
+	e.filterQuery = strings.TrimSpace(query)
+	e.refreshTree()
+}
+
+// applyFilter recomputes the filter from the current adventure, so nodes
+// added or edited since still match.
+func (e *editor) applyFilter() {
+	if e.filterQuery == "" || e.adv == nil {
+		e.filter = nil
+		return
+	}
+	e.filter = map[widget.TreeNodeID]bool{}
+	for _, h := range engine.NewAdventureIndex(e.adv).Search(e.filterQuery, 0, nil) {
+		switch h.Kind {
+		case "zone", "npc", "event", "item", "table":
+			e.filter[h.Kind+":"+h.ID] = true
+		case "room":
+			if _, z := e.adv.Room(h.ID); z != nil {
+				e.filter["zone:"+z.ID] = true
+				e.filter["room:"+z.ID+"::"+h.ID] = true
+			}
+		}
+	}
+}
+
+func (e *editor) allChildUIDs(uid widget.TreeNodeID) []widget.TreeNodeID {
 	switch {
 	case uid == "":
 		return []widget.TreeNodeID{"meta", "zones", "npcs", "events", "items", "tables", "images"}
@@ -446,6 +502,7 @@ func (e *editor) showForm(uid widget.TreeNodeID) {
 func (e *editor) refreshForm() { e.showForm(e.currentUID) }
 
 func (e *editor) refreshTree() {
+	e.applyFilter()
 	if e.nav != nil {
 		e.nav.Refresh()
 		e.nav.OpenAllBranches()
@@ -977,13 +1034,17 @@ func (e *editor) validate() {
 		info, err := os.Stat(filepath.Join(e.workingDir, filepath.FromSlash(rel)))
 		return err == nil && !info.IsDir()
 	}
-	errs := domain.ValidateAdventure(e.adv, imageExists)
+	errs := domain.ValidateAdventure(e.adv, imageExists, srd.Lookup)
 	if len(errs) == 0 {
 		go nativeui.Info("Validation", "✓ The adventure is valid.")
 		return
 	}
 	var sb strings.Builder
-	sb.WriteString(fmt.Sprintf("%d problem(s):\n\n", len(errs)))
+	if n := len(domain.ValidationErrors(errs)); n == 0 {
+		sb.WriteString(fmt.Sprintf("✓ The adventure is valid, with %d warning(s):\n\n", len(errs)))
+	} else {
+		sb.WriteString(fmt.Sprintf("%d problem(s):\n\n", len(errs)))
+	}
 	for _, er := range errs {
 		sb.WriteString("• " + er.Error() + "\n")
 	}
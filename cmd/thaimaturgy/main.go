@@ -11,6 +11,7 @@ import (
 	"os"
 	"sort"
 	"strings"
+	"sync/atomic"
 	"time"
 
 	"fyne.io/fyne/v2"
@@ -95,6 +96,8 @@ type gui struct {
 	exportBtn   *widget.Button
 	libraryBtn  *widget.Button
 	telegramBtn *widget.Button
+	undoBtn     *widget.Button // roll back the last oracle turn or command (also while hosting)
+	redoBtn     *widget.Button
 	busy        bool // an oracle request is in flight; block state reads/mutations from the UI
 
 	// In-process Telegram bot hosting the current DM session (nil when stopped).
@@ -122,7 +125,10 @@ type gui struct {
 	remoteURL    string             // the server URL the client targets (editable in Settings)
 	remoteToken  string             // the bearer token used to connect ("" = none)
 	remoteName   string             // the open remote session, if any
-	remoteCancel context.CancelFunc // cancels the open session's SSE stream
+	remoteCancel context.CancelFunc // cancels the open session's WebSocket
+	// remoteConn is the open session's WebSocket while it's connected (nil
+	// between reconnects); turns go over it. Set from the reconnect goroutine.
+	remoteConn atomic.Pointer[apiclient.SessionConn]
 }
 
 func main() {
@@ -238,11 +244,13 @@ func (g *gui) showLibrary() {
 			label := fmt.Sprintf("↻  %s — %s   (%s)", s.Name, s.AdventureTitle, formatSessionTime(sessionModTime(s)))
 			resume := widget.NewButton(label, func() { g.resumeSession(name) })
 			resume.Alignment = widget.ButtonAlignLeading
+			snaps := widget.NewButtonWithIcon("", theme.HistoryIcon(), func() { g.showSnapshots(name) })
+			snaps.Importance = widget.LowImportance
 			rename := widget.NewButtonWithIcon("", theme.DocumentCreateIcon(), func() { g.renameSession(name) })
 			rename.Importance = widget.LowImportance
 			del := widget.NewButtonWithIcon("", theme.DeleteIcon(), func() { g.deleteSession(name) })
 			del.Importance = widget.LowImportance
-			list.Add(container.NewBorder(nil, nil, nil, container.NewHBox(rename, del), resume))
+			list.Add(container.NewBorder(nil, nil, nil, container.NewHBox(snaps, rename, del), resume))
 		}
 	}
 
@@ -404,6 +412,117 @@ func (g *gui) renameSession(name string) {
 	g.win.Canvas().Focus(entry)
 }
 
+// --- Snapshots -------------------------------------------------------------
+
+// snapshot takes a named save point of the open session (/save snapshot).
+func (g *gui) snapshot(label string) {
+	if g.session == nil {
+		return
+	}
+	if _, err := g.store.SaveSnapshot(g.session.State, label); err != nil {
+		g.showErr(err)
+		return
+	}
+	g.appendTranscript("_📌 Snapshot “" + label + "” taken._")
+}
+
+// showSnapshots lists a session's snapshots in a popup, each of which can be
+// branched from or deleted.
+func (g *gui) showSnapshots(name string) {
+	list, err := g.store.ListSnapshots(name)
+	if err != nil {
+		g.showErr(err)
+		return
+	}
+	var pop *widget.PopUp
+	rows := container.NewVBox()
+	if len(list) == 0 {
+		rows.Add(widget.NewLabel("No snapshots yet. Take one in play with /save snapshot <label>."))
+	}
+	for _, sn := range list {
+		id, label := sn.ID, sn.Label
+		branch := widget.NewButton("Branch", func() {
+			pop.Hide()
+			g.branchFrom(name, id)
+		})
+		del := widget.NewButtonWithIcon("", theme.DeleteIcon(), func() {
+			go func() {
+				if !nativeui.Confirm("Delete snapshot", fmt.Sprintf("Delete snapshot %q of %q?", label, name)) {
+					return
+				}
+				if err := g.store.DeleteSnapshot(name, id); err != nil {
+					g.showErr(err)
+					return
+				}
+				fyne.Do(func() {
+					pop.Hide()
+					g.showSnapshots(name)
+				})
+			}()
+		})
+		del.Importance = widget.LowImportance
+		text := fmt.Sprintf("%s   (%s)", label, formatSessionTime(sn.CreatedAt))
+		rows.Add(container.NewBorder(nil, nil, nil, container.NewHBox(branch, del), widget.NewLabel(text)))
+	}
+	closeBtn := widget.NewButton("Close", func() { pop.Hide() })
+	content := container.NewBorder(
+		widget.NewLabelWithStyle("Snapshots of "+name, fyne.TextAlignCenter, fyne.TextStyle{Bold: true}),
+		container.NewHBox(layoutSpacer(), closeBtn), nil, nil,
+		container.NewVScroll(rows),
+	)
+	pop = widget.NewModalPopUp(container.NewPadded(content), g.win.Canvas())
+	pop.Resize(fyne.NewSize(520, 360))
+	pop.Show()
+}
+
+// branchFrom prompts for a name and forks a new session from one of name's
+// snapshots (by id or label), then opens it. The session open now is saved
+// first; the original is left as it is, to go back to from the library.
+func (g *gui) branchFrom(name, ref string) {
+	def := name + "-branch"
+	for i := 2; g.store.SessionExists(def); i++ {
+		def = fmt.Sprintf("%s-branch-%d", name, i)
+	}
+	entry := widget.NewEntry()
+	entry.SetText(def)
+
+	var pop *widget.PopUp
+	doBranch := func() {
+		newName := strings.TrimSpace(entry.Text)
+		if newName == "" {
+			return
+		}
+		if g.session != nil {
+			if err := g.store.SaveSession(g.session.State); err != nil {
+				g.showErr(err)
+				return
+			}
+		}
+		if _, err := g.store.BranchSession(name, ref, newName); err != nil {
+			g.showErr(err)
+			return
+		}
+		pop.Hide()
+		g.showLibrary() // leaves the open session (journal, Telegram host)
+		g.resumeSession(newName)
+	}
+	entry.OnSubmitted = func(string) { doBranch() }
+
+	branch := widget.NewButton("Branch", doBranch)
+	branch.Importance = widget.HighImportance
+	cancel := widget.NewButton("Cancel", func() { pop.Hide() })
+
+	content := container.NewVBox(
+		widget.NewLabelWithStyle("Name the new branch", fyne.TextAlignCenter, fyne.TextStyle{Bold: true}),
+		entry,
+		container.NewHBox(branch, cancel),
+	)
+	pop = widget.NewModalPopUp(container.NewPadded(content), g.win.Canvas())
+	pop.Resize(fyne.NewSize(380, 160))
+	pop.Show()
+	g.win.Canvas().Focus(entry)
+}
+
 // --- Session screen ------------------------------------------------------
 
 func (g *gui) startSession(advID string) {
@@ -478,20 +597,7 @@ func (g *gui) openSession(state *domain.SessionState, adv *domain.Adventure) {
 	// per-message formatting while allowing selection + copy.
 	g.transcriptBox = container.NewVBox()
 	g.transScroll = container.NewVScroll(g.transcriptBox)
-	g.appendTranscript(fmt.Sprintf("_Running **%s**. Ask a question or type a /command._", adv.Title))
-	// Restore the saved oracle chat when resuming a session so it isn't empty.
-	if state.Conversation != nil {
-		for _, m := range state.Conversation.Messages {
-			switch m.Role {
-			case domain.RoleUser:
-				g.appendTranscript("**» " + m.Content + "**")
-			case domain.RoleAssistant:
-				if strings.TrimSpace(m.Content) != "" {
-					g.appendTranscript(m.Content)
-				}
-			}
-		}
-	}
+	g.renderTranscript()
 
 	g.logText = widget.NewRichTextFromMarkdown("")
 	g.logText.Wrapping = fyne.TextWrapWord
@@ -556,6 +662,8 @@ func (g *gui) openSession(state *domain.SessionState, adv *domain.Adventure) {
 	g.diceBtn = widget.NewButton("Dice", g.showDiceRoller)
 	g.telegramBtn = widget.NewButton("Telegram", g.toggleTelegram)
 	g.telegramBtn.Hide() // shown only in virtual-DM mode (applyMode)
+	g.undoBtn = widget.NewButtonWithIcon("Undo", theme.ContentUndoIcon(), g.undo)
+	g.redoBtn = widget.NewButtonWithIcon("Redo", theme.ContentRedoIcon(), g.redo)
 	g.libraryBtn = widget.NewButtonWithIcon("Library", theme.NavigateBackIcon(), g.showLibrary)
 	g.saveBtn = widget.NewButtonWithIcon("Save", theme.DocumentSaveIcon(), g.save)
 	g.exportBtn = widget.NewButtonWithIcon("Novel", theme.DocumentCreateIcon(), g.openNovelEditor)
@@ -564,6 +672,8 @@ func (g *gui) openSession(state *domain.SessionState, adv *domain.Adventure) {
 		g.libraryBtn,
 		g.saveBtn,
 		g.exportBtn,
+		g.undoBtn,
+		g.redoBtn,
 		g.diceBtn,
 		g.modeBtn,
 		g.beginBtn,
@@ -583,6 +693,26 @@ func (g *gui) openSession(state *domain.SessionState, adv *domain.Adventure) {
 	g.scrollTranscriptToBottom()
 }
 
+// renderTranscript fills the chat log from the session's conversation: the
+// saved chat when resuming, or what is left of it after an undo.
+func (g *gui) renderTranscript() {
+	g.transcriptBox.RemoveAll()
+	g.appendTranscript(fmt.Sprintf("_Running **%s**. Ask a question or type a /command._", g.session.Adventure.Title))
+	if g.session.State.Conversation == nil {
+		return
+	}
+	for _, m := range g.session.State.Conversation.Messages {
+		switch m.Role {
+		case domain.RoleUser:
+			g.appendTranscript("**» " + m.Content + "**")
+		case domain.RoleAssistant:
+			if strings.TrimSpace(m.Content) != "" {
+				g.appendTranscript(m.Content)
+			}
+		}
+	}
+}
+
 // scrollTranscriptToBottom scrolls the chat log to the newest message. It scrolls
 // immediately and once more after a short delay, because at session-open time the
 // content size isn't laid out yet, so the first call alone would be a no-op.
@@ -757,7 +887,7 @@ func (g *gui) showDetail(uid widget.TreeNodeID) {
 	case strings.HasPrefix(uid, "room:"):
 		_, rid := splitRoomUID(uid)
 		if r, _ := adv.Room(rid); r != nil {
-			md = roomMarkdown(r)
+			md = roomMarkdown(adv, r, g.session.State.PartySnapshot())
 			groups = roomGroups(adv, r)
 			images = adv.RoomImages(r)
 			id := r.ID
@@ -1000,8 +1130,18 @@ func (g *gui) submit(raw string) {
 			g.importDialog()
 		case "load":
 			g.showLibrary()
+		case "snapshot":
+			g.snapshot(result.UIArg)
+		case "branch":
+			if result.UIArg == "" {
+				g.showSnapshots(g.session.State.Name)
+			} else {
+				g.branchFrom(g.session.State.Name, result.UIArg)
+			}
 		case "mode":
 			g.onModeChanged()
+		case "undo":
+			g.afterUndo(result.Message)
 		}
 	} else if result.Message != "" {
 		if !result.Success {
@@ -1015,7 +1155,7 @@ func (g *gui) submit(raw string) {
 	// and detail-actions autosave in their own handlers). Without this a typed
 	// /rest, /note or /flag would be lost on autosave/restart.
 	switch cmd.Type {
-	case engine.CmdRest, engine.CmdNote, engine.CmdFlag:
+	case engine.CmdRest, engine.CmdNote, engine.CmdFlag, engine.CmdCombat, engine.CmdCheck, engine.CmdLevelUp, engine.CmdDeathSave:
 		if result.Success {
 			g.autosave()
 		}
@@ -1053,6 +1193,8 @@ func (g *gui) setBusy(busy bool) {
 	toggle(g.exportBtn)
 	toggle(g.libraryBtn)
 	toggle(g.telegramBtn)
+	toggle(g.undoBtn)
+	toggle(g.redoBtn)
 	if g.entry != nil {
 		if busy {
 			g.entry.Disable()
@@ -1067,7 +1209,7 @@ func (g *gui) ask(input string) {
 		g.showErr(fmt.Errorf("no AI provider configured; set an API key"))
 		return
 	}
-	g.appendTranscript("_Consulting the oracle…_")
+	status := g.appendTranscript("_Consulting the oracle…_")
 	if g.journal != nil {
 		g.journal.Note("oracle-q", input)
 	}
@@ -1079,14 +1221,51 @@ func (g *gui) ask(input string) {
 	go func() {
 		ctx, cancel := context.WithTimeout(context.Background(), timeout)
 		defer cancel()
-		resp := g.oracle.Ask(ctx, input)
+		// The reply is rendered into one label as it streams; answer and the
+		// labels are only touched on the UI goroutine, inside fyne.Do.
+		var answer *widget.Label
+		var text strings.Builder
+		resp := g.oracle.AskStream(ctx, input, func(e engine.Event) {
+			switch e.Kind {
+			case engine.EventText:
+				text.WriteString(e.Text)
+				md := text.String()
+				fyne.Do(func() {
+					if answer == nil {
+						answer = g.appendTranscript(md)
+						return
+					}
+					answer.SetText(cleanMarkdown(md))
+					g.transScroll.ScrollToBottom()
+				})
+			case engine.EventReset:
+				text.Reset()
+				fyne.Do(func() {
+					if answer != nil {
+						g.transcriptBox.Remove(answer)
+						answer = nil
+					}
+				})
+			case engine.EventTool:
+				if status != nil {
+					fyne.Do(func() { status.SetText(cleanMarkdown("_Consulting the oracle… (" + e.Tool + ")_")) })
+				}
+			}
+		})
 		fyne.Do(func() {
 			g.setBusy(false)
 			if resp.Error != nil {
+				if answer != nil {
+					g.transcriptBox.Remove(answer)
+				}
 				g.showErr(resp.Error)
 				return
 			}
-			g.appendTranscript(resp.Answer)
+			if answer == nil {
+				g.appendTranscript(resp.Answer)
+			} else {
+				answer.SetText(cleanMarkdown(resp.Answer))
+			}
 			if g.journal != nil {
 				g.journal.Note("oracle-a", resp.Answer)
 			}
@@ -1115,9 +1294,10 @@ func (g *gui) save() {
 
 // appendTranscript adds one chat message to the log as a selectable Label, styled
 // by role (bold question, italic status, plain narration), and scrolls to it.
-func (g *gui) appendTranscript(md string) {
+// It returns the label so a streamed reply can be updated in place.
+func (g *gui) appendTranscript(md string) *widget.Label {
 	if g.transcriptBox == nil {
-		return
+		return nil
 	}
 	style := fyne.TextStyle{}
 	t := strings.TrimSpace(md)
@@ -1132,6 +1312,7 @@ func (g *gui) appendTranscript(md string) {
 	lbl.Selectable = true // enables mouse selection + copy (Cmd/Ctrl+C)
 	g.transcriptBox.Add(lbl)
 	g.transScroll.ScrollToBottom()
+	return lbl
 }
 
 // modeIsDM reports whether the active session is running in virtual-DM mode.
@@ -1235,9 +1416,41 @@ func (g *gui) refreshPCPanel() {
 		}
 		name := party[i].Name
 		objs = append(objs, buildPCSheet(&party[i])...)
-		objs = append(objs, widget.NewButtonWithIcon("Edit sheet…", theme.DocumentCreateIcon(), func() {
+		edit := widget.NewButtonWithIcon("Edit sheet…", theme.DocumentCreateIcon(), func() {
 			g.showSheetEditor(name)
-		}))
+		})
+		var extra []fyne.CanvasObject
+		if party[i].IsDying() {
+			// At 0 HP: roll the death save (or stabilize) through the shared
+			// /deathsave command, like the oracle tool and Telegram.
+			save := widget.NewButtonWithIcon("Death save", theme.WarningIcon(), func() {
+				g.submit("/deathsave " + name)
+			})
+			save.Importance = widget.DangerImportance
+			extra = append(extra, save, widget.NewButton("Stabilize", func() {
+				g.submit("/deathsave " + name + " stabilize")
+			}))
+		}
+		if party[i].Dead {
+			// Only an explicit revive raises the dead (setting HP doesn't).
+			extra = append(extra, widget.NewButton("Revive", func() {
+				g.submit("/deathsave " + name + " revive")
+			}))
+		}
+		if party[i].LevelsPending() > 0 {
+			// The XP threshold is reached: offer the level-up through the shared
+			// /levelup command (same rules as the oracle tool and Telegram).
+			levelUp := widget.NewButtonWithIcon("Level up", theme.MoveUpIcon(), func() {
+				g.submit("/levelup " + name)
+			})
+			levelUp.Importance = widget.HighImportance
+			extra = append(extra, levelUp)
+		}
+		if len(extra) == 0 {
+			objs = append(objs, edit)
+			continue
+		}
+		objs = append(objs, container.NewHBox(append([]fyne.CanvasObject{edit}, extra...)...))
 	}
 	g.pcSheet.Objects = objs
 	g.pcSheet.Refresh()
@@ -1331,6 +1544,40 @@ func (g *gui) restParty() {
 	}()
 }
 
+// undo rolls back the last oracle turn or command through the shared /undo
+// command; while hosting on Telegram it goes through the bot instead, which
+// tells the table — undo is one of the host's controls.
+func (g *gui) undo() { g.rollChange("/undo", (*tgbot.Bot).Undo, "Undid") }
+
+// redo re-applies what undo rolled back, like undo.
+func (g *gui) redo() { g.rollChange("/redo", (*tgbot.Bot).Redo, "Redid") }
+
+func (g *gui) rollChange(cmd string, hosted func(*tgbot.Bot) (domain.ChangeSet, error), verb string) {
+	if g.session == nil || g.busy {
+		return
+	}
+	if !g.hosting || g.tg == nil {
+		g.submit(cmd)
+		return
+	}
+	set, err := hosted(g.tg)
+	if err != nil {
+		g.showErr(err)
+		return
+	}
+	g.afterUndo(engine.DescribeChangeSet(verb, set))
+}
+
+// afterUndo redraws the session after an undo or redo rolled it back or forth,
+// and saves it.
+func (g *gui) afterUndo(msg string) {
+	g.renderTranscript()
+	g.appendTranscript("_" + msg + "_")
+	g.applyMode()
+	g.refreshState()
+	g.autosave()
+}
+
 // toggleTelegram starts or stops the in-process Telegram bot hosting the current
 // virtual-DM session. Available only in virtual-DM mode.
 func (g *gui) toggleTelegram() {
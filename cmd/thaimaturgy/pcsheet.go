@@ -33,6 +33,9 @@ func buildPCSheet(c *domain.Character) []fyne.CanvasObject {
 		statBox("Speed", strconv.Itoa(c.Speed)),
 		statBox("Prof", fmt.Sprintf("+%d", c.ProficiencyBonus)),
 	))
+	if line := dyingLine(c); line != "" {
+		objs = append(objs, widget.NewLabelWithStyle(line, fyne.TextAlignLeading, fyne.TextStyle{Bold: true}))
+	}
 
 	// Ability grid (3×2).
 	a := c.Abilities
@@ -52,7 +55,14 @@ func buildPCSheet(c *domain.Character) []fyne.CanvasObject {
 	}
 	objs = append(objs, sectionLabel("Saving throws"), wrapLabel(strings.Join(saves, "   ")))
 
-	line := fmt.Sprintf("Gold: %d      XP: %d      Hit dice: %d/%d", c.Gold, c.XP, c.HitDiceRemaining(), c.HitDiceMax())
+	xp := strconv.Itoa(c.XP)
+	if c.Level < domain.MaxLevel {
+		xp += "/" + strconv.Itoa(domain.XPForLevel(c.Level+1))
+	}
+	line := fmt.Sprintf("Gold: %d      XP: %s      Hit dice: %d/%d", c.Gold, xp, c.HitDiceRemaining(), c.HitDiceMax())
+	if c.LevelsPending() > 0 {
+		line += "      ⬆ Level up!"
+	}
 	if c.Inspiration {
 		line += "      ★ Inspiration"
 	}
@@ -198,3 +208,20 @@ func cleanMarkdown(s string) string {
 	}
 	return strings.Join(lines, "\n")
 }
+
+// dyingLine shows a character's state at 0 HP with the death-save tally as pips
+// ("💀 Dying — successes ●○○  failures ●●○"); "" while conscious.
+func dyingLine(c *domain.Character) string {
+	pips := func(n int) string {
+		return strings.Repeat("●", n) + strings.Repeat("○", max(domain.DeathSavesToResolve-n, 0))
+	}
+	switch {
+	case c.Dead:
+		return "✝ Dead"
+	case c.Stable && c.CurrentHP <= 0:
+		return "🩹 Stable — unconscious at 0 HP"
+	case c.IsDying():
+		return fmt.Sprintf("💀 Dying — successes %s  failures %s", pips(c.DeathSuccesses), pips(c.DeathFailures))
+	}
+	return ""
+}
@@ -2,6 +2,8 @@ package main
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"strconv"
 	"strings"
@@ -21,8 +23,9 @@ import (
 // This file is the desktop GUI's REMOTE mode (#60): with --server, the app talks
 // to a thaimaturgy-server over internal/apiclient instead of the in-process core.
 // It is a lightweight client (library + a session view with transcript, party,
-// command/oracle input, and a live SSE log); full-fidelity play stays in local
-// mode or the web UI. The in-process path is untouched.
+// command/oracle input, and a live log, with turns and events over the session's
+// WebSocket); full-fidelity play stays in local mode or the web UI. The
+// in-process path is untouched.
 //
 // Fyne threading: every remote HTTP call runs in a background goroutine so the UI
 // never blocks; only widget mutations are marshalled back via fyne.Do.
@@ -193,13 +196,14 @@ func (g *gui) buildRemoteSession(name string, st *domain.SessionState) {
 
 	transcript := container.NewVBox()
 	transScroll := container.NewVScroll(transcript)
-	appendTx := func(prefix, text string) {
+	appendTx := func(prefix, text string) *widget.Label {
 		lbl := widget.NewLabel(prefix + cleanMarkdown(text))
 		lbl.Wrapping = fyne.TextWrapWord
 		transcript.Add(lbl)
 		transScroll.ScrollToBottom()
+		return lbl
 	}
-	for _, m := range conversationMessages(st) {
+	appendMsg := func(m domain.Message) {
 		switch m.Role {
 		case domain.RoleAssistant:
 			appendTx("", m.Content)
@@ -207,6 +211,18 @@ func (g *gui) buildRemoteSession(name string, st *domain.SessionState) {
 			appendTx("» ", m.Content)
 		}
 	}
+	txLen := 0 // conversation messages the transcript shows
+	// replayTx redraws the transcript from a session's conversation, on open and
+	// after an undo or redo rewrites it.
+	replayTx := func(s *domain.SessionState) {
+		transcript.RemoveAll()
+		msgs := conversationMessages(s)
+		for _, m := range msgs {
+			appendMsg(m)
+		}
+		txLen = len(msgs)
+	}
+	replayTx(st)
 
 	var refreshParty func() // set when the party panel is built (below)
 
@@ -215,7 +231,7 @@ func (g *gui) buildRemoteSession(name string, st *domain.SessionState) {
 
 	input := widget.NewEntry()
 
-	var sendBtn, modeBtn, beginBtn, restBtn, tgBtn *widget.Button
+	var sendBtn, modeBtn, beginBtn, restBtn, tgBtn, undoBtn, redoBtn *widget.Button
 	var applyRemoteMode func(*domain.SessionState)
 	busy := false
 	hosting := false
@@ -232,17 +248,22 @@ func (g *gui) buildRemoteSession(name string, st *domain.SessionState) {
 				w.Enable()
 			}
 		}
-		// The host toggle stays usable while hosting (to stop it) but not mid-turn.
-		if busy {
-			tgBtn.Disable()
-		} else {
-			tgBtn.Enable()
+		// The host toggle stays usable while hosting (to stop it) but not mid-turn,
+		// and so do undo/redo: the server rolls a hosted session back via its bot.
+		for _, w := range []*widget.Button{tgBtn, undoBtn, redoBtn} {
+			if busy {
+				w.Disable()
+			} else {
+				w.Enable()
+			}
 		}
 	}
 	setBusy := func(b bool) { busy = b; refreshControls() }
 
-	// runCmd sends a command or oracle input; for state-mutating commands it then
-	// refetches the session and reconciles the party + mode UI.
+	// runCmd sends a command or oracle input, streaming the narration into the
+	// transcript as it's written. The party and mode UI follow the session's
+	// state events; a typed /undo or /redo rewrites the conversation, so the
+	// session is refetched to redraw it.
 	runCmd := func(text string, echo bool) {
 		if text == "" || busy || hosting {
 			return
@@ -251,28 +272,53 @@ func (g *gui) buildRemoteSession(name string, st *domain.SessionState) {
 		if echo {
 			appendTx("» ", text)
 		}
+		rolled := false
+		if c := engine.ParseCommand(text); c != nil {
+			rolled = c.Type == engine.CmdUndo || c.Type == engine.CmdRedo
+		}
+		var live *widget.Label // the narration so far, while it streams
+		var liveText string
+		onUpdate := func(u apiclient.OracleUpdate) {
+			fyne.Do(func() {
+				switch u.Kind {
+				case string(engine.EventText):
+					liveText += u.Text
+					if live == nil {
+						live = appendTx("", liveText)
+					} else {
+						live.SetText(cleanMarkdown(liveText))
+					}
+					transScroll.ScrollToBottom()
+				case string(engine.EventReset):
+					if live != nil {
+						transcript.Remove(live)
+						live, liveText = nil, ""
+					}
+				}
+			})
+		}
 		go func() {
-			resp, isCmd, err := g.remoteTurn(name, text)
+			resp, _, err := g.remoteTurn(name, text, onUpdate)
 			var fresh *domain.SessionState
-			if isCmd && err == nil {
+			if rolled && err == nil {
 				fctx, fcancel := bg(15)
 				fresh, _ = g.remote.Session(fctx, name)
 				fcancel()
 			}
 			fyne.Do(func() {
 				setBusy(false)
+				if live != nil {
+					transcript.Remove(live)
+				}
+				if fresh != nil {
+					curState = fresh
+					replayTx(fresh)
+				}
 				if err != nil {
 					appendTx("⚠ ", err.Error())
 				} else if resp != "" {
 					appendTx("", resp)
 				}
-				if fresh != nil {
-					curState = fresh
-					if refreshParty != nil {
-						refreshParty()
-					}
-					applyRemoteMode(fresh)
-				}
 			})
 		}()
 	}
@@ -309,6 +355,48 @@ func (g *gui) buildRemoteSession(name string, st *domain.SessionState) {
 	})
 	restBtn.Hide()
 
+	// Undo/Redo roll the last turn or command back (or forward) on the server,
+	// then the transcript is redrawn from the session's conversation.
+	rollChange := func(redo bool) {
+		if busy {
+			return
+		}
+		setBusy(true)
+		go func() {
+			ctx, cancel := bg(15)
+			var res apiclient.ChangeResult
+			var err error
+			if redo {
+				res, err = g.remote.Redo(ctx, name)
+			} else {
+				res, err = g.remote.Undo(ctx, name)
+			}
+			var fresh *domain.SessionState
+			if err == nil {
+				fresh, _ = g.remote.Session(ctx, name)
+			}
+			cancel()
+			fyne.Do(func() {
+				setBusy(false)
+				if err != nil {
+					appendTx("⚠ ", err.Error())
+					return
+				}
+				if fresh != nil {
+					curState = fresh
+					replayTx(fresh)
+					if refreshParty != nil {
+						refreshParty()
+					}
+					applyRemoteMode(fresh)
+				}
+				appendTx("", "_"+res.Message+"_")
+			})
+		}()
+	}
+	undoBtn = widget.NewButtonWithIcon("Undo", theme.ContentUndoIcon(), func() { rollChange(false) })
+	redoBtn = widget.NewButtonWithIcon("Redo", theme.ContentRedoIcon(), func() { rollChange(true) })
+
 	// Host-on-Telegram toggle (virtual-DM only): the SERVER runs the bot bound to
 	// this session, using the server-configured token. While hosting, the bot is
 	// the sole driver, so this client's turn controls are disabled (the server
@@ -415,7 +503,7 @@ func (g *gui) buildRemoteSession(name string, st *domain.SessionState) {
 
 	novelBtn := widget.NewButtonWithIcon("Novel", theme.DocumentCreateIcon(), g.openNovelEditor)
 	head := container.NewHBox(back, widget.NewLabelWithStyle(name, fyne.TextAlignLeading, fyne.TextStyle{Bold: true}),
-		layoutSpacer(), modeBtn, beginBtn, restBtn, tgBtn, novelBtn, save)
+		layoutSpacer(), modeBtn, beginBtn, restBtn, undoBtn, redoBtn, tgBtn, novelBtn, save)
 	partyPanel, rp := g.remotePartyPanel(name, st)
 	refreshParty = rp
 	left := modernPanel("Party", "", partyPanel)
@@ -426,7 +514,39 @@ func (g *gui) buildRemoteSession(name string, st *domain.SessionState) {
 	bottom := container.NewBorder(nil, nil, nil, sendBtn, input)
 	g.win.SetContent(appShell(container.NewBorder(head, bottom, nil, nil, body)))
 
-	g.startRemoteLogStream(name, logBox, logScroll)
+	// State events from the stream keep the view current when another client
+	// (the web UI, a Telegram host) changes the session. This client's own turns
+	// show their results when they return, so the conversation events they cause
+	// only advance txLen.
+	g.startRemoteLogStream(name, logBox, logScroll, func(ev apiclient.StreamEvent) {
+		switch domain.StateTopic(ev.Type) {
+		case domain.TopicParty:
+			if refreshParty != nil {
+				refreshParty()
+			}
+		case domain.TopicMode:
+			var m struct {
+				Mode    domain.SessionMode `json:"mode"`
+				Started bool               `json:"started"`
+			}
+			if json.Unmarshal(ev.Data, &m) == nil && curState != nil {
+				curState.Mode, curState.Started = m.Mode, m.Started
+				applyRemoteMode(curState)
+			}
+		case domain.TopicConversation:
+			var c struct {
+				Length  int             `json:"length"`
+				Message *domain.Message `json:"message"`
+			}
+			if json.Unmarshal(ev.Data, &c) != nil {
+				return
+			}
+			if !busy && c.Length == txLen+1 && c.Message != nil {
+				appendMsg(*c.Message)
+			}
+			txLen = c.Length
+		}
+	})
 
 	// Reflect any Telegram host already running for this session (e.g. started
 	// from the web or a previous GUI), so the toggle opens in the right state.
@@ -446,11 +566,26 @@ func (g *gui) buildRemoteSession(name string, st *domain.SessionState) {
 
 // remoteTurn runs one command/oracle turn synchronously (call from a goroutine),
 // returning the text to show, whether it was a slash command, and any error.
-func (g *gui) remoteTurn(name, text string) (resp string, isCmd bool, err error) {
+// Turns go over the session's WebSocket when it's connected, with onUpdate
+// (called off the UI thread) receiving the narration as it streams; otherwise
+// they fall back to plain requests.
+func (g *gui) remoteTurn(name, text string, onUpdate func(apiclient.OracleUpdate)) (resp string, isCmd bool, err error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
 	defer cancel()
+	command := g.remote.Command
+	oracle := func(ctx context.Context, name, input string) (apiclient.OracleResult, error) {
+		return g.remote.Oracle(ctx, name, input)
+	}
+	if conn := g.remoteConn.Load(); conn != nil {
+		command = func(ctx context.Context, _, input string) (apiclient.CommandResult, error) {
+			return conn.Command(ctx, input)
+		}
+		oracle = func(ctx context.Context, _, input string) (apiclient.OracleResult, error) {
+			return conn.Oracle(ctx, input, onUpdate)
+		}
+	}
 	if text[0] == '/' {
-		res, e := g.remote.Command(ctx, name, text)
+		res, e := command(ctx, name, text)
 		if e != nil {
 			return "", true, e
 		}
@@ -458,10 +593,38 @@ func (g *gui) remoteTurn(name, text string) (resp string, isCmd bool, err error)
 		if out == "" {
 			out = res.Message
 		}
+		// Save points live on the server: take one, branch from one, or list them.
+		switch {
+		case res.UIAction == "snapshot":
+			if _, se := g.remote.Snapshot(ctx, name, res.UIArg); se != nil {
+				return "", true, se
+			}
+			return "📌 Snapshot “" + res.UIArg + "” taken.", true, nil
+		case res.UIAction == "branch" && res.UIArg != "":
+			branch, be := g.remote.Branch(ctx, name, res.UIArg, "")
+			if be != nil {
+				return "", true, be
+			}
+			return "Branched “" + branch + "” from “" + res.UIArg + "”; open it from the library.", true, nil
+		case res.UIAction == "branch":
+			list, le := g.remote.ListSnapshots(ctx, name)
+			if le != nil {
+				return "", true, le
+			}
+			if len(list) == 0 {
+				return "No snapshots yet. Take one with /save snapshot <label>.", true, nil
+			}
+			var sb strings.Builder
+			sb.WriteString("Snapshots:")
+			for _, sn := range list {
+				fmt.Fprintf(&sb, "\n• %s (%s)", sn.Label, formatSessionTime(sn.CreatedAt))
+			}
+			return sb.String(), true, nil
+		}
 		// A command may ask the DM to narrate (e.g. /begin sets the opening scene
 		// via ui_action "oracle"): run that oracle turn and append its narration.
 		if res.UIAction == "oracle" && res.UIArg != "" {
-			ans, oe := g.remote.Oracle(ctx, name, res.UIArg)
+			ans, oe := oracle(ctx, name, res.UIArg)
 			if oe != nil {
 				return out, true, oe
 			}
@@ -477,7 +640,7 @@ func (g *gui) remoteTurn(name, text string) (resp string, isCmd bool, err error)
 		}
 		return out, true, nil
 	}
-	res, e := g.remote.Oracle(ctx, name, text)
+	res, e := oracle(ctx, name, text)
 	if e != nil {
 		return "", false, e
 	}
@@ -487,38 +650,91 @@ func (g *gui) remoteTurn(name, text string) (resp string, isCmd bool, err error)
 	return res.Answer, false, nil
 }
 
-// startRemoteLogStream tails the session's SSE log, reconnecting with bounded
-// backoff if the stream drops, until the session is left.
-func (g *gui) startRemoteLogStream(name string, logBox *fyne.Container, logScroll *container.Scroll) {
+// startRemoteLogStream connects the session's WebSocket, which carries the
+// turns (see remoteTurn) and tails the session's events into the live log. It
+// reconnects with bounded backoff if the connection drops (resuming after the
+// last event seen), until the session is left or closed. onState is called on
+// the UI thread with each state event, so the caller can reflect changes made
+// by other clients.
+func (g *gui) startRemoteLogStream(name string, logBox *fyne.Container, logScroll *container.Scroll, onState func(apiclient.StreamEvent)) {
 	g.stopRemoteSession()
 	ctx, cancel := context.WithCancel(context.Background())
 	g.remoteCancel = cancel
-	appendLog := func(line string) {
+	var entries []fyne.CanvasObject // the timeline's labels, apart from notices
+	appendLog := func(line string, entry bool) {
 		fyne.Do(func() {
 			lbl := widget.NewLabel(line)
 			lbl.Wrapping = fyne.TextWrapWord
 			logBox.Add(lbl)
+			if entry {
+				entries = append(entries, lbl)
+			}
 			logScroll.ScrollToBottom()
 		})
 	}
+	onEvent := func(ev apiclient.StreamEvent) {
+		switch domain.StateTopic(ev.Type) {
+		case domain.TopicLog:
+			var e domain.LogEntry
+			if json.Unmarshal(ev.Data, &e) != nil {
+				return
+			}
+			ts := ""
+			if !e.Timestamp.IsZero() {
+				ts = e.Timestamp.Format("15:04") + "  "
+			}
+			appendLog(fmt.Sprintf("%s %s%s", engine.LogIcon(e.Type), ts, e.Message), true)
+		case "sync":
+			// The timeline is replayed in full next.
+			fyne.Do(func() {
+				logBox.RemoveAll()
+				entries = nil
+			})
+		case domain.TopicRewind:
+			var rw struct {
+				LogLength int `json:"log_length"`
+			}
+			if json.Unmarshal(ev.Data, &rw) != nil {
+				return
+			}
+			fyne.Do(func() {
+				for len(entries) > rw.LogLength {
+					logBox.Remove(entries[len(entries)-1])
+					entries = entries[:len(entries)-1]
+				}
+			})
+		default:
+			fyne.Do(func() { onState(ev) })
+		}
+	}
 	go func() {
 		backoff := time.Second
+		lastID := ""
 		for ctx.Err() == nil {
-			err := g.remote.StreamEvents(ctx, name, func(e domain.LogEntry) {
-				ts := ""
-				if !e.Timestamp.IsZero() {
-					ts = e.Timestamp.Format("15:04") + "  "
+			conn, err := g.remote.DialSession(ctx, name, lastID, func(ev apiclient.StreamEvent) {
+				if ev.ID != "" {
+					lastID = ev.ID
 				}
-				appendLog(fmt.Sprintf("%s %s%s", engine.LogIcon(e.Type), ts, e.Message))
+				onEvent(ev)
 			})
+			if err == nil {
+				backoff = time.Second
+				g.remoteConn.Store(conn)
+				err = conn.Wait()
+				g.remoteConn.CompareAndSwap(conn, nil)
+			}
 			if ctx.Err() != nil {
 				return
 			}
+			if errors.Is(err, apiclient.ErrSessionClosed) {
+				appendLog("… the session was closed on the server", false)
+				return
+			}
 			msg := "live log disconnected; reconnecting…"
 			if err != nil {
 				msg = "live log error (" + err.Error() + "); reconnecting…"
 			}
-			appendLog("… " + msg)
+			appendLog("… "+msg, false)
 			select {
 			case <-ctx.Done():
 				return
@@ -568,10 +784,7 @@ func (g *gui) showRemoteSettings() {
 
 	// --- Server settings: full parity with the local Settings form — needs a
 	// live connection. Mirrors cmd/thaimaturgy/settings.go field-for-field. ---
-	provider := widget.NewSelect([]string{
-		string(domain.ProviderOpenAI), string(domain.ProviderAnthropic),
-		string(domain.ProviderGemini), string(domain.ProviderClaudeCLI),
-	}, nil)
+	provider := widget.NewSelect(providerOptions(), nil)
 	model := widget.NewEntry()
 	runModel := widget.NewEntry()
 	runModel.SetPlaceHolder("(defaults to Model)")
@@ -599,10 +812,14 @@ func (g *gui) showRemoteSettings() {
 	openaiKey := widget.NewPasswordEntry()
 	anthropicKey := widget.NewPasswordEntry()
 	geminiKey := widget.NewPasswordEntry()
+	localKey := widget.NewPasswordEntry()
 	telegramToken := widget.NewPasswordEntry()
-	for _, e := range []*widget.Entry{openaiKey, anthropicKey, geminiKey, telegramToken} {
+	for _, e := range []*widget.Entry{openaiKey, anthropicKey, geminiKey, localKey, telegramToken} {
 		e.SetPlaceHolder("(leave blank to keep the current value)")
 	}
+	localURL := widget.NewEntry()
+	localURL.SetPlaceHolder("(as seen from the server, e.g. http://localhost:11434/v1)")
+	localTools := widget.NewSelect(localToolModes(), nil)
 	telegramChat := widget.NewEntry()
 	telegramChat.SetPlaceHolder("(optional: restrict the bot to one chat)")
 	telegramUsers := widget.NewMultiLineEntry()
@@ -633,6 +850,9 @@ func (g *gui) showRemoteSettings() {
 		widget.NewFormItem("OpenAI API key", openaiKey),
 		widget.NewFormItem("Anthropic API key", anthropicKey),
 		widget.NewFormItem("Gemini API key", geminiKey),
+		widget.NewFormItem("Local server URL", localURL),
+		widget.NewFormItem("Local tool calling", localTools),
+		widget.NewFormItem("Local API key", localKey),
 		widget.NewFormItem("Telegram bot token", telegramToken),
 		widget.NewFormItem("Telegram chat id", telegramChat),
 		widget.NewFormItem("Telegram allowed users", telegramUsers),
@@ -668,6 +888,9 @@ func (g *gui) showRemoteSettings() {
 		loaded.OpenAIAPIKey = strings.TrimSpace(openaiKey.Text)
 		loaded.AnthropicAPIKey = strings.TrimSpace(anthropicKey.Text)
 		loaded.GeminiAPIKey = strings.TrimSpace(geminiKey.Text)
+		loaded.LocalAPIKey = strings.TrimSpace(localKey.Text)
+		loaded.LocalBaseURL = strings.TrimSpace(localURL.Text)
+		loaded.LocalToolMode = localToolModeValue(localTools.Selected)
 		loaded.TelegramToken = strings.TrimSpace(telegramToken.Text)
 		if s := strings.TrimSpace(telegramChat.Text); s == "" {
 			loaded.TelegramChatID = 0
@@ -752,6 +975,8 @@ func (g *gui) showRemoteSettings() {
 			spoilerGuard.SetChecked(cfg.SpoilerGuard.Enabled)
 			spoilerProvider.SetSelected(spoilerProviderLabel(cfg.SpoilerGuard.Provider))
 			spoilerModel.SetText(cfg.SpoilerGuard.Model)
+			localURL.SetText(cfg.LocalBaseURL)
+			localTools.SetSelected(localToolModeLabel(cfg.LocalToolMode))
 			if cfg.TelegramChatID != 0 {
 				telegramChat.SetText(strconv.FormatInt(cfg.TelegramChatID, 10))
 			}
@@ -774,6 +999,7 @@ func (g *gui) stopRemoteSession() {
 		g.remoteCancel()
 		g.remoteCancel = nil
 	}
+	g.remoteConn.Store(nil)
 	g.remoteName = ""
 }
 
@@ -24,13 +24,13 @@ func (g *gui) showSettings() {
 		cfg = domain.DefaultConfig()
 	}
 
-	provider := widget.NewSelect([]string{
-		string(domain.ProviderOpenAI), string(domain.ProviderAnthropic),
-		string(domain.ProviderGemini), string(domain.ProviderClaudeCLI),
-	}, nil)
+	provider := widget.NewSelect(providerOptions(), nil)
 	provider.SetSelected(string(cfg.Provider))
 
-	model := entryWith(cfg.Model)
+	// The model field doubles as a dropdown of the models a local server offers
+	// once "Discover models" has asked it.
+	model := widget.NewSelectEntry(nil)
+	model.SetText(cfg.Model)
 	runModel := entryWith(cfg.RunModel)
 	runModel.SetPlaceHolder("(defaults to Model)")
 	editModel := entryWith(cfg.EditModel)
@@ -72,10 +72,49 @@ func (g *gui) showSettings() {
 	openaiKey := widget.NewPasswordEntry()
 	anthropicKey := widget.NewPasswordEntry()
 	geminiKey := widget.NewPasswordEntry()
-	for _, e := range []*widget.Entry{openaiKey, anthropicKey, geminiKey} {
+	localKey := widget.NewPasswordEntry()
+	for _, e := range []*widget.Entry{openaiKey, anthropicKey, geminiKey, localKey} {
 		e.SetPlaceHolder("(applied this session; not written to disk)")
 	}
 
+	localURL := entryWith(cfg.LocalBaseURL)
+	localURL.SetPlaceHolder("(e.g. http://localhost:11434/v1 — Ollama, LM Studio, llama.cpp, vLLM)")
+	localTools := widget.NewSelect(localToolModes(), nil)
+	localTools.SetSelected(localToolModeLabel(cfg.LocalToolMode))
+	localStatus := widget.NewLabel("")
+	localStatus.Wrapping = fyne.TextWrapWord
+	discover := widget.NewButtonWithIcon("Discover models", theme.SearchIcon(), func() {
+		base := strings.TrimSpace(localURL.Text)
+		if base == "" {
+			localStatus.SetText("Enter the local server URL first.")
+			return
+		}
+		key := strings.TrimSpace(localKey.Text)
+		if key == "" {
+			key = cfg.LocalAPIKey
+		}
+		localStatus.SetText("Asking " + base + "…")
+		go func() {
+			ctx, cancel := bg(5)
+			defer cancel()
+			models, err := providers.ListModels(ctx, base, key)
+			fyne.Do(func() {
+				switch {
+				case err != nil:
+					localStatus.SetText("Could not list models: " + err.Error())
+				case len(models) == 0:
+					localStatus.SetText("The server lists no models.")
+				default:
+					model.SetOptions(models)
+					if provider.Selected == string(domain.ProviderLocal) && strings.TrimSpace(model.Text) == "" {
+						model.SetText(models[0])
+					}
+					localStatus.SetText(fmt.Sprintf("%d model(s) found — pick one from the Model dropdown.", len(models)))
+				}
+			})
+		}()
+	})
+
 	telegramToken := widget.NewPasswordEntry()
 	telegramToken.SetText(cfg.TelegramToken)
 	telegramToken.SetPlaceHolder("(bot token from @BotFather; saved to config)")
@@ -110,6 +149,10 @@ func (g *gui) showSettings() {
 		widget.NewFormItem("OpenAI API key", openaiKey),
 		widget.NewFormItem("Anthropic API key", anthropicKey),
 		widget.NewFormItem("Gemini API key", geminiKey),
+		widget.NewFormItem("Local server URL", localURL),
+		widget.NewFormItem("Local tool calling", localTools),
+		widget.NewFormItem("Local API key", localKey),
+		widget.NewFormItem("", container.NewVBox(discover, localStatus)),
 		widget.NewFormItem("Telegram bot token", telegramToken),
 		widget.NewFormItem("Telegram chat id", telegramChat),
 		widget.NewFormItem("Telegram allowed users", telegramUsers),
@@ -143,6 +186,11 @@ func (g *gui) showSettings() {
 		if k := strings.TrimSpace(geminiKey.Text); k != "" {
 			cfg.GeminiAPIKey = k
 		}
+		cfg.LocalBaseURL = strings.TrimSpace(localURL.Text)
+		cfg.LocalToolMode = localToolModeValue(localTools.Selected)
+		if k := strings.TrimSpace(localKey.Text); k != "" {
+			cfg.LocalAPIKey = k
+		}
 		cfg.TelegramToken = strings.TrimSpace(telegramToken.Text)
 		if s := strings.TrimSpace(telegramChat.Text); s == "" {
 			cfg.TelegramChatID = 0
@@ -195,6 +243,37 @@ func (g *gui) applySettings(cfg *domain.Config) error {
 	return nil
 }
 
+// providerOptions is the provider dropdown: every supported engine.
+func providerOptions() []string {
+	return []string{
+		string(domain.ProviderOpenAI), string(domain.ProviderAnthropic),
+		string(domain.ProviderGemini), string(domain.ProviderClaudeCLI),
+		string(domain.ProviderLocal),
+	}
+}
+
+// localToolModes is the dropdown of local tool-calling modes, "auto" first
+// (stored as an empty LocalToolMode).
+func localToolModes() []string {
+	return []string{domain.LocalToolsAuto, domain.LocalToolsNative, domain.LocalToolsPrompt}
+}
+
+// localToolModeLabel maps a stored tool mode to its dropdown label ("" → auto).
+func localToolModeLabel(mode string) string {
+	if mode == "" {
+		return domain.LocalToolsAuto
+	}
+	return mode
+}
+
+// localToolModeValue maps a dropdown label back to a stored tool mode (auto → "").
+func localToolModeValue(label string) string {
+	if label == domain.LocalToolsAuto {
+		return ""
+	}
+	return label
+}
+
 // spoilerSameAsOracle is the Select label meaning "reuse the oracle's provider"
 // (stored as an empty SpoilerGuard.Provider).
 const spoilerSameAsOracle = "(same as oracle)"
@@ -202,11 +281,7 @@ const spoilerSameAsOracle = "(same as oracle)"
 // spoilerProviderOptions is the provider dropdown for the spoiler-guard section:
 // the "same as oracle" sentinel plus every supported engine.
 func spoilerProviderOptions() []string {
-	return []string{
-		spoilerSameAsOracle,
-		string(domain.ProviderOpenAI), string(domain.ProviderAnthropic),
-		string(domain.ProviderGemini), string(domain.ProviderClaudeCLI),
-	}
+	return append([]string{spoilerSameAsOracle}, providerOptions()...)
 }
 
 // spoilerProviderLabel maps a stored provider to its dropdown label ("" → sentinel).
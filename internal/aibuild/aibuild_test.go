@@ -24,6 +24,9 @@ type stubProvider struct {
 func (s *stubProvider) Name() string         { return "stub" }
 func (s *stubProvider) SupportsTools() bool  { return false }
 func (s *stubProvider) SupportsVision() bool { return true }
+func (s *stubProvider) ChatStream(ctx context.Context, req providers.ChatRequest, _ providers.StreamFunc) (*providers.ChatResponse, error) {
+	return s.Chat(ctx, req)
+}
 func (s *stubProvider) Chat(_ context.Context, req providers.ChatRequest) (*providers.ChatResponse, error) {
 	s.lastReq = req
 	return &providers.ChatResponse{Content: s.content}, nil
@@ -106,6 +109,9 @@ type seqProvider struct {
 func (s *seqProvider) Name() string         { return "seq" }
 func (s *seqProvider) SupportsTools() bool  { return false }
 func (s *seqProvider) SupportsVision() bool { return true }
+func (s *seqProvider) ChatStream(ctx context.Context, req providers.ChatRequest, _ providers.StreamFunc) (*providers.ChatResponse, error) {
+	return s.Chat(ctx, req)
+}
 func (s *seqProvider) Chat(_ context.Context, _ providers.ChatRequest) (*providers.ChatResponse, error) {
 	i := s.calls
 	if i >= len(s.resps) {
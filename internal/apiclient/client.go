@@ -9,6 +9,7 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"mime/multipart"
@@ -52,12 +53,22 @@ type CommandResult struct {
 	UIArg    string `json:"ui_arg"`
 }
 
+// ChangeResult mirrors the server's /undo and /redo responses.
+type ChangeResult struct {
+	Label   string   `json:"label"`
+	Changes []string `json:"changes"`
+	Message string   `json:"message"`
+}
+
 // OracleResult mirrors the server's /oracle response.
 type OracleResult struct {
 	Answer     string `json:"answer"`
 	TokensUsed int    `json:"tokens_used"`
 	LatencyMs  int64  `json:"latency_ms"`
 	Error      string `json:"error"`
+	Provider   string `json:"provider,omitempty"`
+	Model      string `json:"model,omitempty"`
+	Fallback   bool   `json:"fallback,omitempty"`
 }
 
 // do performs a request, sending/expecting JSON, and decodes the 2xx body into
@@ -233,6 +244,34 @@ func (c *Client) RenameSession(ctx context.Context, name, newName string) error
 	return c.do(ctx, "POST", "/api/sessions/"+enc(name)+"/rename", map[string]string{"new_name": newName}, nil)
 }
 
+// ListSnapshots lists a session's named save points, oldest first.
+func (c *Client) ListSnapshots(ctx context.Context, name string) ([]storage.SnapshotInfo, error) {
+	var out []storage.SnapshotInfo
+	err := c.do(ctx, "GET", "/api/sessions/"+enc(name)+"/snapshots", nil, &out)
+	return out, err
+}
+
+// Snapshot takes a named save point of a session.
+func (c *Client) Snapshot(ctx context.Context, name, label string) (storage.SnapshotInfo, error) {
+	var out storage.SnapshotInfo
+	err := c.do(ctx, "POST", "/api/sessions/"+enc(name)+"/snapshots", map[string]string{"label": label}, &out)
+	return out, err
+}
+
+func (c *Client) DeleteSnapshot(ctx context.Context, name, id string) error {
+	return c.do(ctx, "DELETE", "/api/sessions/"+enc(name)+"/snapshots/"+enc(id), nil, nil)
+}
+
+// Branch forks a new session from a snapshot (by id or label) and returns its
+// name; an empty newName lets the server pick one.
+func (c *Client) Branch(ctx context.Context, name, ref, newName string) (string, error) {
+	var out struct {
+		Name string `json:"name"`
+	}
+	err := c.do(ctx, "POST", "/api/sessions/"+enc(name)+"/snapshots/"+enc(ref)+"/branch", map[string]string{"name": newName}, &out)
+	return out.Name, err
+}
+
 // Command runs a shared engine command (the parity path) against a session.
 func (c *Client) Command(ctx context.Context, name, input string) (CommandResult, error) {
 	var out CommandResult
@@ -240,6 +279,20 @@ func (c *Client) Command(ctx context.Context, name, input string) (CommandResult
 	return out, err
 }
 
+// Undo rolls a session's latest turn or command back.
+func (c *Client) Undo(ctx context.Context, name string) (ChangeResult, error) {
+	var out ChangeResult
+	err := c.do(ctx, "POST", "/api/sessions/"+enc(name)+"/undo", nil, &out)
+	return out, err
+}
+
+// Redo re-applies what a session's last Undo rolled back.
+func (c *Client) Redo(ctx context.Context, name string) (ChangeResult, error) {
+	var out ChangeResult
+	err := c.do(ctx, "POST", "/api/sessions/"+enc(name)+"/redo", nil, &out)
+	return out, err
+}
+
 // Oracle runs one oracle/DM turn against a session.
 func (c *Client) Oracle(ctx context.Context, name, input string) (OracleResult, error) {
 	var out OracleResult
@@ -333,10 +386,43 @@ func (c *Client) SSETicket(ctx context.Context) (string, error) {
 	return out.Ticket, err
 }
 
+// ErrSessionClosed is returned by StreamSession when the server closed the
+// session: the stream has ended for good, so there's nothing to reconnect to.
+var ErrSessionClosed = errors.New("the session was closed")
+
+// StreamEvent is one event of a session's stream. Type is a domain.StateTopic
+// or "sync" (the stream is starting over: the timeline follows as log events,
+// and Resync asks the client to refetch the state). ID is what to pass to
+// StreamSession to resume after this event ("" for the log events of a
+// replay). Data is the event's JSON.
+type StreamEvent struct {
+	ID     string
+	Type   string
+	Data   json.RawMessage
+	Resync bool
+}
+
 // StreamEvents opens the session's SSE stream and calls onLog for each timeline
 // entry until ctx is cancelled or the stream ends. When the server requires a
 // token it first mints an SSE ticket. Blocks; run it in a goroutine.
 func (c *Client) StreamEvents(ctx context.Context, name string, onLog func(domain.LogEntry)) error {
+	return c.StreamSession(ctx, name, "", func(ev StreamEvent) {
+		if ev.Type != string(domain.TopicLog) {
+			return
+		}
+		var e domain.LogEntry
+		if json.Unmarshal(ev.Data, &e) == nil {
+			onLog(e)
+		}
+	})
+}
+
+// StreamSession opens the session's SSE stream and calls onEvent for each
+// event until ctx is cancelled or the stream ends. lastEventID is the ID of the
+// last event seen, to resume after it; "" starts with the whole timeline. It
+// returns ErrSessionClosed when the server closed the session. Blocks; run it
+// in a goroutine.
+func (c *Client) StreamSession(ctx context.Context, name, lastEventID string, onEvent func(StreamEvent)) error {
 	u := c.base + "/api/sessions/" + enc(name) + "/events"
 	if c.token != "" {
 		ticket, err := c.SSETicket(ctx)
@@ -349,6 +435,9 @@ func (c *Client) StreamEvents(ctx context.Context, name string, onLog func(domai
 	if err != nil {
 		return err
 	}
+	if lastEventID != "" {
+		req.Header.Set("Last-Event-ID", lastEventID)
+	}
 	resp, err := c.hc.Do(req)
 	if err != nil {
 		return err
@@ -360,21 +449,32 @@ func (c *Client) StreamEvents(ctx context.Context, name string, onLog func(domai
 	}
 	sc := bufio.NewScanner(resp.Body)
 	sc.Buffer(make([]byte, 0, 64*1024), 1<<20)
-	var event string
+	var ev StreamEvent
 	for sc.Scan() {
 		line := sc.Text()
 		switch {
+		case strings.HasPrefix(line, "id:"):
+			ev.ID = strings.TrimSpace(line[len("id:"):])
 		case strings.HasPrefix(line, "event:"):
-			event = strings.TrimSpace(line[len("event:"):])
+			ev.Type = strings.TrimSpace(line[len("event:"):])
 		case strings.HasPrefix(line, "data:"):
-			if event == "log" {
-				var e domain.LogEntry
-				if json.Unmarshal([]byte(strings.TrimSpace(line[len("data:"):])), &e) == nil {
-					onLog(e)
+			ev.Data = json.RawMessage(strings.TrimSpace(line[len("data:"):]))
+		case line == "": // end of one SSE event
+			switch ev.Type {
+			case "":
+			case "closed":
+				return ErrSessionClosed
+			case "sync":
+				var sync struct {
+					Resync bool `json:"resync"`
 				}
+				_ = json.Unmarshal(ev.Data, &sync)
+				ev.Resync = sync.Resync
+				onEvent(ev)
+			default:
+				onEvent(ev)
 			}
-		case line == "":
-			event = "" // end of one SSE event
+			ev = StreamEvent{}
 		}
 	}
 	return sc.Err()
@@ -393,3 +393,48 @@ func TestClientAdventureEditRoundTrip(t *testing.T) {
 		t.Error("expected an error fetching a missing adventure")
 	}
 }
+
+func TestClientSessionConn(t *testing.T) {
+	c := liveServer(t, "s3cret")
+	ctx := context.Background()
+	name, err := c.NewSession(ctx, "crypt")
+	if err != nil {
+		t.Fatalf("new session: %v", err)
+	}
+	events := make(chan StreamEvent, 64)
+	conn, err := c.DialSession(ctx, name, "", func(ev StreamEvent) { events <- ev })
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	defer conn.Close()
+	if ev := <-events; ev.Type != "sync" || ev.ID == "" {
+		t.Fatalf("first event = %+v; want a sync", ev)
+	}
+
+	res, err := conn.Command(ctx, "/flag gate=true")
+	if err != nil || !res.Success {
+		t.Fatalf("command = %+v (%v)", res, err)
+	}
+	for ev := range events {
+		if ev.Type == string(domain.TopicWorld) {
+			if !strings.Contains(string(ev.Data), `"gate":true`) {
+				t.Errorf("world event = %s", ev.Data)
+			}
+			break
+		}
+	}
+	// No provider is configured, so the turn reports an error.
+	if ans, err := conn.Oracle(ctx, "hello?", nil); err != nil || ans.Error == "" {
+		t.Errorf("oracle without a provider = %+v (%v)", ans, err)
+	}
+
+	if err := c.CloseSession(ctx, name); err != nil {
+		t.Fatalf("close: %v", err)
+	}
+	if err := conn.Wait(); err != ErrSessionClosed {
+		t.Errorf("Wait = %v; want ErrSessionClosed", err)
+	}
+	if _, err := conn.Command(ctx, "/status"); err == nil {
+		t.Error("a command ran on a closed connection")
+	}
+}
@@ -0,0 +1,253 @@
+package apiclient
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"sync"
+
+	"golang.org/x/net/websocket"
+)
+
+// OracleUpdate is one piece of an oracle turn as it streams: Kind "token" adds
+// Text to the reply, "tool" says the DM ran Tool (Text is its error, if it
+// failed), and "reset" discards the reply so far (it was preamble to tool
+// calls).
+type OracleUpdate struct {
+	Kind string
+	Text string
+	Tool string
+}
+
+// wsFrame mirrors the server's WebSocket frames.
+type wsFrame struct {
+	Type  string          `json:"type"`
+	ID    string          `json:"id,omitempty"`
+	Ref   string          `json:"ref,omitempty"`
+	Data  json.RawMessage `json:"data,omitempty"`
+	Error string          `json:"error,omitempty"`
+}
+
+// SessionConn is a WebSocket to one session (WS /api/sessions/{name}/ws):
+// commands and oracle turns go over it, and the session's state events come
+// back on it, as StreamSession delivers them. The server runs requests one at
+// a time, in the order they're sent. It is safe for concurrent use.
+type SessionConn struct {
+	ws      *websocket.Conn
+	onEvent func(StreamEvent)
+	done    chan struct{}
+
+	mu      sync.Mutex // guards the fields below and serializes writes
+	seq     int
+	pending map[string]func(wsFrame) // reply handlers by request ref
+	err     error
+}
+
+// DialSession opens a session's WebSocket. onEvent is called, from the
+// connection's reader, for each state event; lastEventID resumes after the
+// event with that ID, as for StreamSession. The connection lasts until ctx is
+// cancelled, Close is called, or the server ends it (see Wait).
+func (c *Client) DialSession(ctx context.Context, name, lastEventID string, onEvent func(StreamEvent)) (*SessionConn, error) {
+	u, err := url.Parse(c.base + "/api/sessions/" + enc(name) + "/ws")
+	if err != nil {
+		return nil, err
+	}
+	q := url.Values{}
+	if c.token != "" {
+		ticket, err := c.SSETicket(ctx)
+		if err != nil {
+			return nil, err
+		}
+		q.Set("ticket", ticket)
+	}
+	if lastEventID != "" {
+		q.Set("last_event_id", lastEventID)
+	}
+	u.RawQuery = q.Encode()
+	if u.Scheme == "https" {
+		u.Scheme = "wss"
+	} else {
+		u.Scheme = "ws"
+	}
+	cfg, err := websocket.NewConfig(u.String(), c.base)
+	if err != nil {
+		return nil, err
+	}
+	ws, err := cfg.DialContext(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("session socket: %w", err)
+	}
+	sc := &SessionConn{
+		ws:      ws,
+		onEvent: onEvent,
+		done:    make(chan struct{}),
+		pending: make(map[string]func(wsFrame)),
+	}
+	go sc.read()
+	go func() {
+		select {
+		case <-ctx.Done():
+			sc.finish(ctx.Err())
+		case <-sc.done:
+		}
+	}()
+	return sc, nil
+}
+
+// read dispatches the server's frames until the connection ends.
+func (sc *SessionConn) read() {
+	for {
+		var f wsFrame
+		if err := websocket.JSON.Receive(sc.ws, &f); err != nil {
+			sc.finish(err)
+			return
+		}
+		switch {
+		case f.Ref != "":
+			sc.mu.Lock()
+			h := sc.pending[f.Ref]
+			sc.mu.Unlock()
+			if h != nil {
+				h(f)
+			}
+		case f.Type == "closed":
+			sc.finish(ErrSessionClosed)
+			return
+		case f.Type == "heartbeat":
+		default:
+			ev := StreamEvent{ID: f.ID, Type: f.Type, Data: f.Data}
+			if f.Type == "sync" {
+				var sync struct {
+					Resync bool `json:"resync"`
+				}
+				_ = json.Unmarshal(f.Data, &sync)
+				ev.Resync = sync.Resync
+			}
+			if sc.onEvent != nil {
+				sc.onEvent(ev)
+			}
+		}
+	}
+}
+
+// finish ends the connection with err (the first one wins) and fails the
+// requests still waiting for a reply.
+func (sc *SessionConn) finish(err error) {
+	sc.mu.Lock()
+	if sc.err != nil {
+		sc.mu.Unlock()
+		return
+	}
+	sc.err = err
+	pending := sc.pending
+	sc.pending = nil
+	sc.mu.Unlock()
+	close(sc.done)
+	_ = sc.ws.Close()
+	for _, h := range pending {
+		h(wsFrame{Type: "error", Error: err.Error()})
+	}
+}
+
+// Close closes the connection.
+func (sc *SessionConn) Close() error {
+	sc.finish(errors.New("connection closed"))
+	return nil
+}
+
+// Wait blocks until the connection ends and returns why: ErrSessionClosed when
+// the server closed the session, otherwise the error that ended it.
+func (sc *SessionConn) Wait() error {
+	<-sc.done
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+	return sc.err
+}
+
+// request sends one request and waits for its final frame ("result", "done" or
+// "error"), passing the frames before it to onFrame.
+func (sc *SessionConn) request(ctx context.Context, typ, input string, onFrame func(wsFrame)) (wsFrame, error) {
+	final := make(chan wsFrame, 1)
+	sc.mu.Lock()
+	if sc.err != nil {
+		err := sc.err
+		sc.mu.Unlock()
+		return wsFrame{}, err
+	}
+	sc.seq++
+	ref := strconv.Itoa(sc.seq)
+	sc.pending[ref] = func(f wsFrame) {
+		switch f.Type {
+		case "result", "done", "error":
+			sc.mu.Lock()
+			delete(sc.pending, ref)
+			sc.mu.Unlock()
+			select {
+			case final <- f:
+			default: // the connection ended as the reply came in
+			}
+		default:
+			if onFrame != nil {
+				onFrame(f)
+			}
+		}
+	}
+	err := websocket.JSON.Send(sc.ws, map[string]string{"ref": ref, "type": typ, "input": input})
+	sc.mu.Unlock()
+	if err != nil {
+		sc.finish(err)
+	}
+	select {
+	case f := <-final:
+		if f.Type == "error" {
+			return f, errors.New(f.Error)
+		}
+		return f, nil
+	case <-ctx.Done():
+		sc.mu.Lock()
+		delete(sc.pending, ref)
+		sc.mu.Unlock()
+		return wsFrame{}, ctx.Err()
+	}
+}
+
+// Command runs a slash command on the session, as Client.Command does.
+func (sc *SessionConn) Command(ctx context.Context, input string) (CommandResult, error) {
+	var out CommandResult
+	f, err := sc.request(ctx, "command", input, nil)
+	if err != nil {
+		return out, err
+	}
+	err = json.Unmarshal(f.Data, &out)
+	return out, err
+}
+
+// Oracle runs one oracle/DM turn on the session, calling onUpdate (if non-nil,
+// from the connection's reader) as the reply streams in.
+func (sc *SessionConn) Oracle(ctx context.Context, input string, onUpdate func(OracleUpdate)) (OracleResult, error) {
+	var out OracleResult
+	f, err := sc.request(ctx, "oracle", input, func(f wsFrame) {
+		if onUpdate == nil {
+			return
+		}
+		var d struct {
+			Text  string `json:"text"`
+			Tool  string `json:"tool"`
+			Error string `json:"error"`
+		}
+		_ = json.Unmarshal(f.Data, &d)
+		u := OracleUpdate{Kind: f.Type, Text: d.Text, Tool: d.Tool}
+		if f.Type == "tool" {
+			u.Text = d.Error
+		}
+		onUpdate(u)
+	})
+	if err != nil {
+		return out, err
+	}
+	err = json.Unmarshal(f.Data, &out)
+	return out, err
+}
@@ -24,6 +24,8 @@ import (
 	"github.com/theburrowhub/thaimaturgy/internal/domain"
 	"github.com/theburrowhub/thaimaturgy/internal/engine"
 	"github.com/theburrowhub/thaimaturgy/internal/providers"
+	"github.com/theburrowhub/thaimaturgy/internal/search"
+	"github.com/theburrowhub/thaimaturgy/internal/srd"
 	"github.com/theburrowhub/thaimaturgy/internal/storage"
 	"github.com/theburrowhub/thaimaturgy/internal/tgbot"
 )
@@ -69,6 +71,9 @@ type Service struct {
 
 	novelMu sync.Mutex // serializes the read-modify-write of saved novels (#65)
 
+	indexMu sync.Mutex               // guards indexes
+	indexes map[string]*search.Index // adventure search indexes by id, dropped when the adventure changes
+
 	// hostMu serializes the Telegram host lifecycle across ALL sessions. The
 	// server has a single Telegram bot token, and Telegram allows only one
 	// getUpdates consumer per bot, so at most one session may host at a time.
@@ -84,6 +89,7 @@ type OpenSession struct {
 	Oracle  *engine.Oracle
 	Cmd     *engine.CommandHandler
 	journal *storage.SessionJournal
+	feed    *eventFeed // state events, for Subscribe
 
 	// opMu serializes this session's mutating operations (command, oracle turn,
 	// save) with its closure, so no mutation or save crosses CloseSession. closed
@@ -98,6 +104,13 @@ type OpenSession struct {
 	tg       *tgbot.Bot
 	tgCancel context.CancelFunc
 
+	// turn is set while the DM runs a turn of the web round loop (see
+	// roundTurn); turnSig is closed, and replaced, each time it changes, to
+	// wake the tables watching.
+	turnMu  sync.Mutex
+	turn    bool
+	turnSig chan struct{}
+
 	errMu       sync.Mutex
 	lastSaveErr error // most recent save failure (nil once a save succeeds)
 }
@@ -126,6 +139,7 @@ func New(store *storage.Storage, config *domain.Config, provider providers.Provi
 		sessions:   make(map[string]*OpenSession),
 		autosaveCh: make(chan string, 128),
 		nameLocks:  make(map[string]*sync.Mutex),
+		indexes:    make(map[string]*search.Index),
 	}
 	go s.autosaveLoop()
 	return s
@@ -205,9 +219,16 @@ func (s *Service) LoadAdventure(id string) (*domain.Adventure, error) {
 	return s.store.LoadAdventure(id)
 }
 func (s *Service) ImportAdventure(path string) (*domain.Adventure, error) {
-	return s.store.ImportModule(path)
+	adv, err := s.store.ImportModule(path)
+	if err == nil {
+		s.dropIndex(adv.ID)
+	}
+	return adv, err
+}
+func (s *Service) DeleteAdventure(id string) error {
+	defer s.dropIndex(id)
+	return s.store.DeleteAdventure(id)
 }
-func (s *Service) DeleteAdventure(id string) error { return s.store.DeleteAdventure(id) }
 
 // AdventureExists reports whether an adventure with the given ID is imported, so
 // a transport can tell "not found" (404) apart from an operational delete failure
@@ -227,20 +248,26 @@ func (s *Service) SaveAdventure(id string, adv *domain.Adventure) error {
 		return fmt.Errorf("the adventure needs a title")
 	}
 	adv.ID = id
+	defer s.dropIndex(id)
 	return s.store.SaveAdventure(id, adv)
 }
 
 // ValidateAdventure runs full validation (required fields, referential integrity,
-// referenced images present) against a candidate adventure and returns the errors
-// as strings (empty when valid).
-func (s *Service) ValidateAdventure(id string, adv *domain.Adventure) []string {
+// referenced images present) against a candidate adventure and returns, as
+// strings, the problems that make it invalid and apart from them the warnings
+// that don't (e.g. a creature with no stat block). Both are empty when clean.
+func (s *Service) ValidateAdventure(id string, adv *domain.Adventure) (problems, warnings []string) {
 	imageExists := func(rel string) bool { _, err := s.store.ResolveImagePath(id, rel); return err == nil }
-	errs := domain.ValidateAdventure(adv, imageExists)
-	out := make([]string, 0, len(errs))
-	for _, e := range errs {
-		out = append(out, e.Error())
+	problems, warnings = []string{}, []string{}
+	for _, e := range domain.ValidateAdventure(adv, imageExists, srd.Lookup) {
+		var w domain.ValidationWarning
+		if errors.As(e, &w) {
+			warnings = append(warnings, w.Msg)
+		} else {
+			problems = append(problems, e.Error())
+		}
 	}
-	return out
+	return problems, warnings
 }
 
 // ExportModule packages an imported adventure into a temporary .tar.gz and
@@ -365,12 +392,15 @@ func (s *Service) registerLocked(state *domain.SessionState, adv *domain.Adventu
 		return nil, fmt.Errorf("cannot open session journal for %q: %w", state.Name, err)
 	}
 	state.SetLogHook(func(e domain.LogEntry) { journal.Append(e) })
+	feed := newEventFeed()
+	state.SetEventHook(feed.publish)
 	sess := domain.NewSession(state, adv, s.config)
 	os := &OpenSession{
 		Session: sess,
 		Oracle:  engine.NewOracle(sess, s.provider),
 		Cmd:     engine.NewCommandHandler(sess),
 		journal: journal,
+		feed:    feed,
 	}
 	s.sessions[state.Name] = os
 	return os, nil
@@ -444,6 +474,7 @@ func (s *Service) CloseSession(name string) error {
 	s.mu.Lock()
 	delete(s.sessions, name)
 	s.mu.Unlock()
+	os.feed.close()
 	if os.journal != nil {
 		_ = os.journal.Close()
 	}
@@ -500,6 +531,74 @@ func (s *Service) DeleteSession(name string) error {
 	return s.store.DeleteSession(name)
 }
 
+// Snapshot takes a named save point of a session: of its live state when it is
+// open (under opMu, so it can't catch a command or turn half-applied), else of
+// its saved file. Serialized on the name so it can't race a rename or delete.
+func (s *Service) Snapshot(name, label string) (storage.SnapshotInfo, error) {
+	unlock := s.lockName(name)
+	defer unlock()
+	if os, ok := s.Get(name); ok {
+		os.opMu.Lock()
+		defer os.opMu.Unlock()
+		if !os.closed {
+			return s.store.SaveSnapshot(os.Session.State, label)
+		}
+	}
+	state, err := s.store.LoadSession(name)
+	if err != nil {
+		return storage.SnapshotInfo{}, err
+	}
+	return s.store.SaveSnapshot(state, label)
+}
+
+// ListSnapshots lists a session's snapshots, oldest first.
+func (s *Service) ListSnapshots(name string) ([]storage.SnapshotInfo, error) {
+	return s.store.ListSnapshots(name)
+}
+
+// DeleteSnapshot removes one of a session's snapshots.
+func (s *Service) DeleteSnapshot(name, id string) error {
+	unlock := s.lockName(name)
+	defer unlock()
+	return s.store.DeleteSnapshot(name, id)
+}
+
+// Branch forks a new session from one of name's snapshots (by id or label) and
+// returns the branch's name: newName, or "<name>-branch[-N]" when empty. The
+// original session — open or not — is left untouched; the branch is saved but
+// not opened (resume it to play). The branch's name is locked and checked
+// against open sessions as NewSession does.
+func (s *Service) Branch(name, ref, newName string) (string, error) {
+	newName = strings.TrimSpace(newName)
+	auto := newName == ""
+	for i := 0; ; i++ {
+		candidate := newName
+		if auto {
+			candidate = name + "-branch"
+			if i > 0 {
+				candidate = fmt.Sprintf("%s-branch-%d", name, i+1)
+			}
+		}
+		unlock := s.lockName(candidate)
+		s.mu.Lock()
+		taken := s.takenLocked(candidate)
+		s.mu.Unlock()
+		if taken {
+			unlock()
+			if auto {
+				continue
+			}
+			return "", fmt.Errorf("a session named %q already exists", candidate)
+		}
+		_, err := s.store.BranchSession(name, ref, candidate)
+		unlock()
+		if err != nil {
+			return "", err
+		}
+		return candidate, nil
+	}
+}
+
 // ExecuteCommand runs a shared engine command (the parity path, #20) against an
 // open session and returns its result, autosaving after a successful mutation.
 func (s *Service) ExecuteCommand(name, raw string) (*engine.CommandResult, error) {
@@ -529,6 +628,12 @@ func (s *Service) ExecuteCommand(name, raw string) (*engine.CommandResult, error
 // waits for it to finish and captures its mutations. Requires a configured
 // provider.
 func (s *Service) AskOracle(ctx context.Context, name, input string) (*engine.Response, error) {
+	return s.AskOracleStream(ctx, name, input, nil)
+}
+
+// AskOracleStream is AskOracle reporting the turn to fn as it streams (see
+// engine.Oracle.AskStream). fn runs with the session's opMu held.
+func (s *Service) AskOracleStream(ctx context.Context, name, input string, fn engine.EventFunc) (*engine.Response, error) {
 	os, ok := s.Get(name)
 	if !ok {
 		return nil, fmt.Errorf("session %q is not open", name)
@@ -542,12 +647,49 @@ func (s *Service) AskOracle(ctx context.Context, name, input string) (*engine.Re
 		os.opMu.Unlock()
 		return nil, ErrSessionHosted
 	}
-	resp := os.Oracle.Ask(ctx, input)
+	resp := os.Oracle.AskStream(ctx, input, fn)
 	os.opMu.Unlock()
 	s.Autosave(name)
 	return resp, nil
 }
 
+// Undo rolls back an open session's last oracle turn or command — state,
+// timeline and conversation together — and autosaves. A session hosted on
+// Telegram is rolled back through its bot, which tells the table; undo is one
+// of the host's controls, so it isn't rejected with ErrSessionHosted.
+func (s *Service) Undo(name string) (domain.ChangeSet, error) {
+	return s.rollChange(name, (*tgbot.Bot).Undo, (*domain.SessionState).Undo)
+}
+
+// Redo re-applies what Undo rolled back, like Undo.
+func (s *Service) Redo(name string) (domain.ChangeSet, error) {
+	return s.rollChange(name, (*tgbot.Bot).Redo, (*domain.SessionState).Redo)
+}
+
+func (s *Service) rollChange(name string, hosted func(*tgbot.Bot) (domain.ChangeSet, error), local func(*domain.SessionState) (domain.ChangeSet, error)) (domain.ChangeSet, error) {
+	os, ok := s.Get(name)
+	if !ok {
+		return domain.ChangeSet{}, fmt.Errorf("session %q is not open", name)
+	}
+	os.opMu.Lock()
+	if os.closed {
+		os.opMu.Unlock()
+		return domain.ChangeSet{}, fmt.Errorf("session %q is not open", name)
+	}
+	var set domain.ChangeSet
+	var err error
+	if os.tg != nil {
+		set, err = hosted(os.tg)
+	} else {
+		set, err = local(os.Session.State)
+	}
+	os.opMu.Unlock()
+	if err == nil {
+		s.Autosave(name)
+	}
+	return set, err
+}
+
 // --- Party & characters (#67) --------------------------------------------
 
 // withOpenSession runs fn under an open session's operation lock, rejecting a
@@ -118,6 +118,78 @@ func TestExecuteCommandAndAutosave(t *testing.T) {
 	}
 }
 
+func TestUndoRedo(t *testing.T) {
+	svc, _ := newService(t)
+	name, err := svc.NewSession("crypt")
+	if err != nil {
+		t.Fatalf("NewSession: %v", err)
+	}
+	if _, err := svc.Undo(name); err == nil {
+		t.Error("a fresh session has nothing to undo")
+	}
+	if _, err := svc.ExecuteCommand(name, "/flag gate=true"); err != nil {
+		t.Fatalf("ExecuteCommand: %v", err)
+	}
+	os, _ := svc.Get(name)
+	set, err := svc.Undo(name)
+	if err != nil || set.Label != "/flag gate=true" || os.Session.State.Flags["gate"] {
+		t.Fatalf("Undo = %+v (%v), flags %v", set, err, os.Session.State.Flags)
+	}
+	if _, err := svc.Redo(name); err != nil || !os.Session.State.Flags["gate"] {
+		t.Fatalf("Redo: %v, flags %v", err, os.Session.State.Flags)
+	}
+	if _, err := svc.Undo("nope"); err == nil {
+		t.Error("undo on an unopened session should fail")
+	}
+}
+
+func TestSnapshotAndBranch(t *testing.T) {
+	svc, store := newService(t)
+	name, err := svc.NewSession("crypt")
+	if err != nil {
+		t.Fatalf("NewSession: %v", err)
+	}
+	// An open session is snapshotted live, even before it was first saved.
+	info, err := svc.Snapshot(name, "before the vault heist")
+	if err != nil {
+		t.Fatalf("Snapshot: %v", err)
+	}
+	if _, err := svc.ExecuteCommand(name, "/flag vault=true"); err != nil {
+		t.Fatalf("ExecuteCommand: %v", err)
+	}
+
+	branch, err := svc.Branch(name, info.ID, "")
+	if err != nil || branch != name+"-branch" {
+		t.Fatalf("Branch = %q, %v", branch, err)
+	}
+	if again, err := svc.Branch(name, "before the vault heist", ""); err != nil || again != name+"-branch-2" {
+		t.Fatalf("second Branch = %q, %v", again, err)
+	}
+	if _, err := svc.Branch(name, info.ID, name); err == nil {
+		t.Error("branching onto the open original should fail")
+	}
+	os, err := svc.ResumeSession(branch)
+	if err != nil {
+		t.Fatalf("ResumeSession(branch): %v", err)
+	}
+	if os.Session.State.Flags["vault"] {
+		t.Error("the branch should start from the snapshot, before the flag was set")
+	}
+	live, _ := svc.Get(name)
+	if !live.Session.State.Flags["vault"] {
+		t.Error("branching must leave the original untouched")
+	}
+	if list, _ := svc.ListSnapshots(name); len(list) != 1 {
+		t.Errorf("snapshots = %+v", list)
+	}
+	if err := svc.DeleteSnapshot(name, info.ID); err != nil {
+		t.Fatalf("DeleteSnapshot: %v", err)
+	}
+	if list, _ := store.ListSnapshots(name); len(list) != 0 {
+		t.Errorf("snapshots after delete = %+v", list)
+	}
+}
+
 func TestNewSessionConcurrentUniqueNames(t *testing.T) {
 	svc, _ := newService(t)
 	const n = 8
@@ -0,0 +1,160 @@
+package appservice
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/theburrowhub/thaimaturgy/internal/domain"
+)
+
+// feedBacklog is how many of a session's latest events are kept, so a
+// subscriber that reconnects with the id of the last event it saw can catch up
+// without replaying the whole state.
+const feedBacklog = 1024
+
+// subBuffer is how far a subscriber may fall behind before it's dropped. A
+// dropped subscriber's channel is closed; it reconnects and resumes from the
+// backlog.
+const subBuffer = 256
+
+// Event is a session state event as subscribers see it. ID is "<epoch>-<seq>":
+// the epoch changes each time the session is opened, since the state numbers
+// its events afresh, so an id from before a reopen never resumes.
+type Event struct {
+	ID string `json:"id"`
+	domain.StateEvent
+}
+
+// eventFeed is an open session's in-process pub/sub bus: the state publishes
+// into it (under the state's lock, so in order) and each subscriber gets the
+// events on its own buffered channel.
+type eventFeed struct {
+	epoch string
+
+	mu     sync.Mutex
+	ring   []Event // latest events, oldest first; at most feedBacklog are live
+	head   uint64  // seq of the latest event published
+	subs   map[*Subscription]struct{}
+	closed bool
+}
+
+func newEventFeed() *eventFeed {
+	return &eventFeed{
+		epoch: strconv.FormatInt(time.Now().UnixNano(), 36),
+		subs:  make(map[*Subscription]struct{}),
+	}
+}
+
+func (f *eventFeed) id(seq uint64) string { return fmt.Sprintf("%s-%d", f.epoch, seq) }
+
+// publish is the state's event hook. It never blocks: a subscriber whose buffer
+// is full is dropped.
+func (f *eventFeed) publish(e domain.StateEvent) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.closed {
+		return
+	}
+	ev := Event{ID: f.id(e.Seq), StateEvent: e}
+	if len(f.ring) == 2*feedBacklog {
+		f.ring = append(f.ring[:0], f.ring[feedBacklog:]...)
+	}
+	f.ring = append(f.ring, ev)
+	f.head = e.Seq
+	for sub := range f.subs {
+		select {
+		case sub.c <- ev:
+		default:
+			delete(f.subs, sub)
+			close(sub.c)
+		}
+	}
+}
+
+// backlog returns the kept events after seq, or false if some of them are no
+// longer kept (or seq is from the future). Caller holds f.mu.
+func (f *eventFeed) backlog(seq uint64) ([]Event, bool) {
+	live := f.ring
+	if len(live) > feedBacklog {
+		live = live[len(live)-feedBacklog:]
+	}
+	if seq > f.head || f.head-seq > uint64(len(live)) {
+		return nil, false
+	}
+	return append([]Event(nil), live[uint64(len(live))-(f.head-seq):]...), true
+}
+
+// close ends every subscription; it's called when the session closes.
+func (f *eventFeed) close() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.closed {
+		return
+	}
+	f.closed = true
+	for sub := range f.subs {
+		delete(f.subs, sub)
+		close(sub.c)
+	}
+}
+
+// Subscription is a subscriber's view of a session's events. C is closed when
+// the subscriber falls behind, unsubscribes, or the session closes
+// (SessionClosed tells which).
+type Subscription struct {
+	C <-chan Event
+
+	c    chan Event
+	feed *eventFeed
+}
+
+// ID is the event id of seq in this subscription's epoch, for a caller that
+// replays the state itself and wants its clients to resume after it.
+func (sub *Subscription) ID(seq uint64) string { return sub.feed.id(seq) }
+
+// SessionClosed reports whether the session the subscription was on has been
+// closed, so a closed C means there's nothing more to wait for.
+func (sub *Subscription) SessionClosed() bool {
+	sub.feed.mu.Lock()
+	defer sub.feed.mu.Unlock()
+	return sub.feed.closed
+}
+
+// Close unsubscribes. It is safe to call more than once, and after C closed.
+func (sub *Subscription) Close() {
+	f := sub.feed
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if _, ok := f.subs[sub]; ok {
+		delete(f.subs, sub)
+		close(sub.c)
+	}
+}
+
+// Subscribe starts receiving the session's events. If lastEventID is the id of
+// an event still in the backlog (from this opening of the session), the events
+// after it are returned in backlog and resumed is true; C then continues right
+// after them. Otherwise resumed is false and the caller must bring its client up
+// to date itself — typically from SessionState.LogAt, skipping the events on C
+// numbered at or below the seq LogAt reports.
+func (o *OpenSession) Subscribe(lastEventID string) (sub *Subscription, backlog []Event, resumed bool) {
+	f := o.feed
+	c := make(chan Event, subBuffer)
+	sub = &Subscription{C: c, c: c, feed: f}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.closed {
+		close(c)
+		return sub, nil, false
+	}
+	f.subs[sub] = struct{}{}
+	if epoch, seq, ok := strings.Cut(lastEventID, "-"); ok && epoch == f.epoch {
+		if n, err := strconv.ParseUint(seq, 10, 64); err == nil {
+			backlog, resumed = f.backlog(n)
+		}
+	}
+	return sub, backlog, resumed
+}
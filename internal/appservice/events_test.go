@@ -0,0 +1,85 @@
+package appservice
+
+import (
+	"testing"
+
+	"github.com/theburrowhub/thaimaturgy/internal/domain"
+)
+
+func TestSubscribeAndResume(t *testing.T) {
+	svc, _ := newService(t)
+	name, err := svc.NewSession("crypt")
+	if err != nil {
+		t.Fatalf("NewSession: %v", err)
+	}
+	os, _ := svc.Get(name)
+	sub, backlog, resumed := os.Subscribe("")
+	defer sub.Close()
+	if resumed || backlog != nil {
+		t.Fatalf("a fresh subscription resumed: %v %v", resumed, backlog)
+	}
+
+	if _, err := svc.ExecuteCommand(name, "/flag gate=true"); err != nil {
+		t.Fatalf("command: %v", err)
+	}
+	var got []Event
+	for len(got) < 2 {
+		got = append(got, <-sub.C)
+	}
+	if got[0].Topic != domain.TopicLog || got[1].Topic != domain.TopicWorld {
+		t.Fatalf("topics = %q, %q; want log, world", got[0].Topic, got[1].Topic)
+	}
+
+	// Resuming after the first event replays the second.
+	again, backlog, resumed := os.Subscribe(got[0].ID)
+	defer again.Close()
+	if !resumed || len(backlog) != 1 || backlog[0].ID != got[1].ID {
+		t.Fatalf("resume = %v %+v", resumed, backlog)
+	}
+	// An id from another opening of the session doesn't resume.
+	if sub, _, resumed := os.Subscribe("x-1"); resumed {
+		t.Error("a foreign epoch resumed")
+	} else {
+		sub.Close()
+	}
+
+	if err := svc.CloseSession(name); err != nil {
+		t.Fatalf("close: %v", err)
+	}
+	if _, ok := <-again.C; ok {
+		t.Error("the subscription outlived the session")
+	}
+	if !again.SessionClosed() {
+		t.Error("SessionClosed = false after the session closed")
+	}
+}
+
+// A subscriber that stops reading is dropped instead of blocking the session,
+// and can resume from the backlog.
+func TestSlowSubscriberIsDropped(t *testing.T) {
+	svc, _ := newService(t)
+	name, err := svc.NewSession("crypt")
+	if err != nil {
+		t.Fatalf("NewSession: %v", err)
+	}
+	os, _ := svc.Get(name)
+	sub, _, _ := os.Subscribe("")
+	flags := subBuffer // each publishes a log and a world event
+	for i := 0; i < flags; i++ {
+		os.Session.State.SetFlag("gate", i%2 == 0)
+	}
+	var last Event
+	n := 0
+	for e := range sub.C {
+		last = e
+		n++
+	}
+	if n != subBuffer || sub.SessionClosed() {
+		t.Fatalf("received %d events (closed %v); want %d and dropped", n, sub.SessionClosed(), subBuffer)
+	}
+	again, backlog, resumed := os.Subscribe(last.ID)
+	defer again.Close()
+	if want := 2*flags - subBuffer; !resumed || len(backlog) != want {
+		t.Errorf("resume after drop = %v, %d events; want %d", resumed, len(backlog), want)
+	}
+}
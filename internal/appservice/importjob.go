@@ -61,6 +61,9 @@ type ImportJob struct {
 	adventure   string // resulting title, for display
 	createdAt   time.Time
 	endedAt     time.Time // when it reached a terminal state (for eviction)
+	// usage is the import's model usage. An import belongs to no session, so it
+	// is reported with the job rather than in a session's ledger.
+	usage domain.UsageLedger
 }
 
 // Snapshot returns a JSON-friendly view of the job under its lock.
@@ -75,10 +78,19 @@ func (j *ImportJob) Snapshot() map[string]any {
 		m["adventure_id"] = j.adventureID
 		m["adventure_title"] = j.adventure
 	}
+	if len(j.usage) > 0 {
+		m["usage"] = append(domain.UsageLedger(nil), j.usage...)
+		m["tokens_used"] = j.usage.Tokens()
+	}
 	return m
 }
 
 func (j *ImportJob) setStage(s string) { j.mu.Lock(); j.stage = s; j.mu.Unlock() }
+func (j *ImportJob) addUsage(r domain.UsageRecord) {
+	j.mu.Lock()
+	j.usage.Add(r)
+	j.mu.Unlock()
+}
 func (j *ImportJob) fail(err error) {
 	j.mu.Lock()
 	j.status, j.errMsg, j.endedAt = ImportError, err.Error(), time.Now()
@@ -153,7 +165,7 @@ func (s *Service) StartImportJob(kind, src, title string) (*ImportJob, error) {
 	s.importJobs[id] = job
 	s.jobMu.Unlock()
 
-	go s.runImportJob(job, kind, src, title, &cfgCopy, prov)
+	go s.runImportJob(job, kind, src, title, &cfgCopy, meter(prov, domain.PurposeImport, job.addUsage))
 	return job, nil
 }
 
@@ -227,7 +239,7 @@ func (s *Service) buildImport(job *ImportJob, kind, src, title string, cfg *doma
 	if err := storage.PackageModule(workingDir, tgzPath); err != nil {
 		return "", "", err
 	}
-	imported, err := s.store.ImportModule(tgzPath)
+	imported, err := s.ImportAdventure(tgzPath)
 	if err != nil {
 		return "", "", err
 	}
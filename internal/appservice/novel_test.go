@@ -4,6 +4,8 @@ import (
 	"errors"
 	"testing"
 	"time"
+
+	"github.com/theburrowhub/thaimaturgy/internal/domain"
 )
 
 // waitNovelJob polls a novel job until it leaves "running" (or times out).
@@ -163,3 +165,27 @@ func TestNovelJobRequiresProviderAndSession(t *testing.T) {
 		t.Error("adjust job on an unopened session should error")
 	}
 }
+
+// A novel's model calls count against the session it was written from.
+func TestNovelJobUsageCountsAgainstSession(t *testing.T) {
+	svc, _ := newService(t)
+	defer svc.CloseSession("crypt")
+	svc.SetProvider(&planProvider{resp: "# The Tale\n\n## One\nIt happened."})
+	name, _ := svc.NewSession("crypt")
+	if _, err := svc.ExecuteCommand(name, "/note the door creaked open"); err != nil {
+		t.Fatalf("note: %v", err)
+	}
+	job, err := svc.StartNovelJob(name)
+	if err != nil {
+		t.Fatalf("StartNovelJob: %v", err)
+	}
+	waitNovelJob(t, svc, job.ID)
+
+	rep, err := svc.Usage(name)
+	if err != nil {
+		t.Fatalf("Usage: %v", err)
+	}
+	if len(rep.Lines) != 1 || rep.Lines[0].Purpose != domain.PurposeNovel || rep.Lines[0].Calls == 0 {
+		t.Errorf("usage = %+v", rep)
+	}
+}
@@ -212,6 +212,8 @@ func (s *Service) novelSnapshot(sessionName string) (*domain.Adventure, *domain.
 	if err := json.Unmarshal(raw, stCopy); err != nil {
 		return nil, nil, nil, "", err
 	}
+	// The writing runs on the copy, but its tokens count against the live session.
+	prov = s.meterSession(sessionName, os.Session.State, prov, domain.PurposeNovel)
 	return adv, stCopy, prov, model, nil
 }
 
@@ -45,6 +45,9 @@ type blockProvider struct{ release chan struct{} }
 func (p *blockProvider) Name() string         { return "block" }
 func (p *blockProvider) SupportsTools() bool  { return false }
 func (p *blockProvider) SupportsVision() bool { return false }
+func (p *blockProvider) ChatStream(ctx context.Context, req providers.ChatRequest, _ providers.StreamFunc) (*providers.ChatResponse, error) {
+	return p.Chat(ctx, req)
+}
 func (p *blockProvider) Chat(ctx context.Context, _ providers.ChatRequest) (*providers.ChatResponse, error) {
 	select {
 	case <-p.release:
@@ -19,6 +19,9 @@ type planProvider struct {
 func (p *planProvider) Name() string         { return "stub" }
 func (p *planProvider) SupportsTools() bool  { return false }
 func (p *planProvider) SupportsVision() bool { return false }
+func (p *planProvider) ChatStream(ctx context.Context, req providers.ChatRequest, _ providers.StreamFunc) (*providers.ChatResponse, error) {
+	return p.Chat(ctx, req)
+}
 func (p *planProvider) Chat(_ context.Context, _ providers.ChatRequest) (*providers.ChatResponse, error) {
 	if p.onChat != nil {
 		p.onChat()
@@ -0,0 +1,175 @@
+package appservice
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/theburrowhub/thaimaturgy/internal/domain"
+	"github.com/theburrowhub/thaimaturgy/internal/engine"
+)
+
+// The player portal: what a player account (domain.RolePlayer) may see and do in
+// the sessions its roster characters play in. A session is the player's when a
+// party member is linked (by roster ID) to one of the user's CharacterIDs; the
+// view is engine.PlayerView plus the player's own sheets, and the only change a
+// player may make is an engine.SheetEdit to one of those sheets.
+
+// ErrNotYourSession is returned when none of the player's characters is in the
+// session's party. It is also returned for a session that isn't open, so a
+// player can't probe for session names.
+var ErrNotYourSession = errors.New("none of your characters is in this session")
+
+// ErrNotYourCharacter is returned for a sheet edit on a character the player
+// doesn't own.
+var ErrNotYourCharacter = errors.New("that character isn't yours")
+
+// PlayerSession is an open session a player has characters in.
+type PlayerSession struct {
+	Name       string   `json:"name"`
+	Adventure  string   `json:"adventure"`
+	Characters []string `json:"characters"`
+}
+
+// PlayerSessionView is a player's view of one session: the players' side of it
+// and the player's own character sheets.
+type PlayerSessionView struct {
+	*engine.PlayerView
+	Characters []domain.Character `json:"characters"`
+}
+
+// ownCharacters returns the party members of st linked to u's roster
+// characters.
+func ownCharacters(st *domain.SessionState, u *domain.User) []domain.Character {
+	var own []domain.Character
+	for _, c := range st.PartySnapshot() {
+		if c.ID != "" && u.HasCharacter(c.ID) {
+			own = append(own, c)
+		}
+	}
+	return own
+}
+
+// PlayerSessions lists the open sessions u has characters in, by name.
+func (s *Service) PlayerSessions(u *domain.User) []PlayerSession {
+	s.mu.Lock()
+	open := make([]*OpenSession, 0, len(s.sessions))
+	for _, os := range s.sessions {
+		open = append(open, os)
+	}
+	s.mu.Unlock()
+	out := []PlayerSession{}
+	for _, os := range open {
+		own := ownCharacters(os.Session.State, u)
+		if len(own) == 0 {
+			continue
+		}
+		ps := PlayerSession{Name: os.Session.State.Name, Adventure: os.Session.Adventure.Title}
+		for _, c := range own {
+			ps.Characters = append(ps.Characters, c.Name)
+		}
+		out = append(out, ps)
+	}
+	slices.SortFunc(out, func(a, b PlayerSession) int { return strings.Compare(a.Name, b.Name) })
+	return out
+}
+
+// playerSession returns the open session called name and u's characters in it,
+// or ErrNotYourSession.
+func (s *Service) playerSession(name string, u *domain.User) (*OpenSession, []domain.Character, error) {
+	os, ok := s.Get(name)
+	if !ok {
+		return nil, nil, ErrNotYourSession
+	}
+	own := ownCharacters(os.Session.State, u)
+	if len(own) == 0 {
+		return nil, nil, ErrNotYourSession
+	}
+	return os, own, nil
+}
+
+// PlayerView returns u's view of an open session they have characters in.
+func (s *Service) PlayerView(name string, u *domain.User) (*PlayerSessionView, error) {
+	os, own, err := s.playerSession(name, u)
+	if err != nil {
+		return nil, err
+	}
+	v, err := engine.NewPlayerView(os.Session)
+	if err != nil {
+		return nil, err
+	}
+	return &PlayerSessionView{PlayerView: v, Characters: own}, nil
+}
+
+// EditPlayerSheet applies a sheet edit (see engine.ParseSheetEdit) to one of u's
+// characters in an open session, autosaving afterwards, and returns the
+// character's name and the change as the timeline records it. As on Telegram,
+// it is refused while the DM resolves a round (ErrDMBusy).
+func (s *Service) EditPlayerSheet(name string, u *domain.User, char, kind, arg string) (string, string, error) {
+	os, _, err := s.playerSession(name, u)
+	if err != nil {
+		return "", "", err
+	}
+	if os.inTurn() {
+		return "", "", ErrDMBusy
+	}
+	edit, err := engine.ParseSheetEdit(kind, arg)
+	if err != nil {
+		return "", "", err
+	}
+	var charName, desc string
+	err = s.withOpenSession(name, func(os *OpenSession) (bool, error) {
+		// Check ownership under opMu, so the party can't change in between.
+		mine := false
+		for _, c := range ownCharacters(os.Session.State, u) {
+			if strings.EqualFold(c.Name, char) {
+				mine, char = true, c.Name
+				break
+			}
+		}
+		if !mine {
+			return false, ErrNotYourCharacter
+		}
+		var err error
+		charName, desc, err = engine.EditSheet(os.Session, char, edit)
+		return err == nil, err
+	})
+	return charName, desc, err
+}
+
+// PlayerMap returns the on-disk path of the map of the zone the party is in, for
+// a player with characters in the session. Only the current zone's map is
+// served, so unexplored areas stay hidden.
+func (s *Service) PlayerMap(name string, u *domain.User) (string, error) {
+	os, _, err := s.playerSession(name, u)
+	if err != nil {
+		return "", err
+	}
+	adv := os.Session.Adventure
+	zoneID, _ := os.Session.State.Location()
+	zone := adv.Zone(zoneID)
+	if zone == nil || adv.ZoneMap(zone) == "" {
+		return "", errors.New("no map for the party's zone")
+	}
+	return s.store.ResolveImagePath(adv.ID, adv.ZoneMap(zone))
+}
+
+// PlayerPortrait returns the on-disk path of the portrait of an NPC the party has
+// met, for a player with characters in the session.
+func (s *Service) PlayerPortrait(name string, u *domain.User, npcID string) (string, error) {
+	os, _, err := s.playerSession(name, u)
+	if err != nil {
+		return "", err
+	}
+	adv := os.Session.Adventure
+	npc := adv.NPC(npcID)
+	if npc == nil || !os.Session.State.NPCKnown(npc.ID) {
+		return "", fmt.Errorf("you haven't met anyone called %q", npcID)
+	}
+	imgs := adv.NPCImages(npc)
+	if len(imgs) == 0 {
+		return "", fmt.Errorf("no portrait for %s", npc.Name)
+	}
+	return s.store.ResolveImagePath(adv.ID, imgs[0])
+}
@@ -0,0 +1,395 @@
+package appservice
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/theburrowhub/thaimaturgy/internal/domain"
+	"github.com/theburrowhub/thaimaturgy/internal/engine"
+)
+
+// The multiplayer round loop for the web, at parity with the Telegram bot: the
+// players of a virtual-DM session take seats with their characters, declare
+// actions (/do), speak in character (/chat) or ask the DM out of character
+// (/meta), and the host has the DM open the game and resolve each round
+// (Oracle.RunGroupTurn). Every player at the table follows it live through
+// WatchTable. Only a player's own roster characters (see ownCharacters) can be
+// claimed, so a seat is always the account's.
+
+// ErrDMBusy is returned while the DM is running a turn of the round loop
+// (setting the scene, resolving the round or answering a /meta), as the
+// Telegram bot turns players away meanwhile.
+var ErrDMBusy = errors.New("the DM is resolving the round — try again in a moment")
+
+// ErrNotStarted is returned for actions and in-character lines before the host
+// has begun the game.
+var ErrNotStarted = errors.New("the game hasn't begun yet — the host starts it")
+
+// ErrNoSeats is returned when the host begins a game nobody has a seat in.
+var ErrNoSeats = errors.New("no player has picked a character yet")
+
+// RoundAction is a declared action as the table sees it.
+type RoundAction struct {
+	Character string `json:"character"`
+	Player    string `json:"player"`
+	Text      string `json:"text"`
+}
+
+// RoundStatus is where the round stands. Playing and Active are the asking
+// player's characters and the one acting by default; the host's status leaves
+// them empty.
+type RoundStatus struct {
+	Started   bool          `json:"started"`
+	Resolving bool          `json:"resolving"`
+	Actions   []RoundAction `json:"actions"`
+	Pending   []string      `json:"pending"` // characters still to act, as "Character (player)"
+	Playing   []string      `json:"playing,omitempty"`
+	Active    string        `json:"active,omitempty"`
+}
+
+// seat is the player id a web player takes a seat under: their linked Telegram
+// id, so they keep the same seat on either front-end, or else their account id.
+func seat(u *domain.User) string {
+	if u.TelegramID != "" {
+		return u.TelegramID
+	}
+	return "web:" + u.ID
+}
+
+// roundStatus reports os's round, for u when u is a player.
+func roundStatus(os *OpenSession, u *domain.User) *RoundStatus {
+	st := os.Session.State
+	rs := &RoundStatus{
+		Started:   st.GameStarted(),
+		Resolving: os.inTurn(),
+		Actions:   []RoundAction{},
+		Pending:   st.PendingPlayers(),
+	}
+	for _, a := range st.RoundActions() {
+		rs.Actions = append(rs.Actions, RoundAction{Character: a.CharacterName, Player: a.DisplayName, Text: a.Text})
+	}
+	if rs.Pending == nil {
+		rs.Pending = []string{}
+	}
+	if u != nil && !u.IsAdmin() {
+		rs.Playing = st.PlayerCharacterNames(seat(u))
+		rs.Active = st.PlayerCharacterName(seat(u))
+	}
+	return rs
+}
+
+// setTurn marks the DM at work (or done), reporting false if that's already
+// so, and wakes the tables watching.
+func (o *OpenSession) setTurn(on bool) bool {
+	o.turnMu.Lock()
+	defer o.turnMu.Unlock()
+	if o.turn == on {
+		return false
+	}
+	o.turn = on
+	if o.turnSig != nil {
+		close(o.turnSig)
+		o.turnSig = nil
+	}
+	return true
+}
+
+// inTurn reports whether the DM is running a turn of the round loop.
+func (o *OpenSession) inTurn() bool {
+	on, _ := o.turnState()
+	return on
+}
+
+// turnState reports whether the DM is running a turn, and a channel closed
+// when that changes.
+func (o *OpenSession) turnState() (bool, <-chan struct{}) {
+	o.turnMu.Lock()
+	defer o.turnMu.Unlock()
+	if o.turnSig == nil {
+		o.turnSig = make(chan struct{})
+	}
+	return o.turn, o.turnSig
+}
+
+// Round reports an open session's round to the host.
+func (s *Service) Round(name string) (*RoundStatus, error) {
+	os, ok := s.Get(name)
+	if !ok {
+		return nil, fmt.Errorf("session %q is not open", name)
+	}
+	return roundStatus(os, nil), nil
+}
+
+// PlayerRound reports the round of an open session u has characters in.
+func (s *Service) PlayerRound(name string, u *domain.User) (*RoundStatus, error) {
+	os, _, err := s.playerSession(name, u)
+	if err != nil {
+		return nil, err
+	}
+	return roundStatus(os, u), nil
+}
+
+// playerRoundOp runs fn for u at an open virtual-DM session they have
+// characters in, under the session's operation lock, and autosaves after it
+// succeeds. It turns u away while the DM is running a turn.
+func (s *Service) playerRoundOp(name string, u *domain.User, fn func(st *domain.SessionState) error) error {
+	os, _, err := s.playerSession(name, u)
+	if err != nil {
+		return err
+	}
+	if os.inTurn() {
+		return ErrDMBusy
+	}
+	return s.withOpenSession(name, func(os *OpenSession) (bool, error) {
+		if os.Session.State.EffectiveMode() != domain.ModeVirtualDM {
+			return false, ErrNotVirtualDM
+		}
+		err := fn(os.Session.State)
+		return err == nil, err
+	})
+}
+
+// ClaimCharacter seats u at the table with one of their characters in the
+// party (/pick), adding it to any they already play and making it active.
+// Returns the character's name.
+func (s *Service) ClaimCharacter(name string, u *domain.User, char string) (string, error) {
+	var claimed string
+	err := s.playerRoundOp(name, u, func(st *domain.SessionState) error {
+		for _, c := range ownCharacters(st, u) {
+			if strings.EqualFold(c.Name, strings.TrimSpace(char)) {
+				var err error
+				claimed, err = st.ClaimCharacter(seat(u), u.Username, c.Name)
+				return err
+			}
+		}
+		return ErrNotYourCharacter
+	})
+	return claimed, err
+}
+
+// SetActiveCharacter chooses which of the characters u plays acts by default
+// (/as). Returns the character's name.
+func (s *Service) SetActiveCharacter(name string, u *domain.User, char string) (string, error) {
+	var active string
+	err := s.playerRoundOp(name, u, func(st *domain.SessionState) error {
+		var err error
+		active, err = st.SetActiveCharacter(seat(u), char)
+		return err
+	})
+	return active, err
+}
+
+// SubmitAction declares what one of u's characters does this round (/do); char
+// empty means the active one. A second action for the same character replaces
+// the first.
+func (s *Service) SubmitAction(name string, u *domain.User, char, text string) (RoundAction, error) {
+	var act domain.RoundAction
+	err := s.playerRoundOp(name, u, func(st *domain.SessionState) error {
+		if !st.GameStarted() {
+			return ErrNotStarted
+		}
+		var err error
+		act, err = st.SubmitAction(seat(u), char, text)
+		return err
+	})
+	return RoundAction{Character: act.CharacterName, Player: act.DisplayName, Text: act.Text}, err
+}
+
+// Chat records an in-character line from one of u's characters (/chat) as
+// context for the DM, without declaring an action. Returns the speaker.
+func (s *Service) Chat(name string, u *domain.User, char, text string) (string, error) {
+	var speaker string
+	err := s.playerRoundOp(name, u, func(st *domain.SessionState) error {
+		if !st.GameStarted() {
+			return ErrNotStarted
+		}
+		if strings.TrimSpace(text) == "" {
+			return errors.New("say something")
+		}
+		var err error
+		if speaker, err = st.ResolvePlayerCharacter(seat(u), char); err != nil {
+			return err
+		}
+		st.AddChat(speaker, text)
+		return nil
+	})
+	return speaker, err
+}
+
+// roundTurn runs one DM turn of the round loop under the session's operation
+// lock, marking the DM busy meanwhile, and autosaves after it.
+func (s *Service) roundTurn(name string, fn func(os *OpenSession) (*engine.Response, error)) (*engine.Response, error) {
+	os, ok := s.Get(name)
+	if !ok {
+		return nil, fmt.Errorf("session %q is not open", name)
+	}
+	if !os.setTurn(true) {
+		return nil, ErrDMBusy
+	}
+	defer os.setTurn(false)
+	var resp *engine.Response
+	err := s.withOpenSession(name, func(os *OpenSession) (bool, error) {
+		if os.Session.State.EffectiveMode() != domain.ModeVirtualDM {
+			return false, ErrNotVirtualDM
+		}
+		var err error
+		resp, err = fn(os)
+		return err == nil, err
+	})
+	return resp, err
+}
+
+// Meta puts an out-of-character question or correction from u to the DM
+// (/meta), who answers it at once for the whole table.
+func (s *Service) Meta(ctx context.Context, name string, u *domain.User, text string) (*engine.Response, error) {
+	if strings.TrimSpace(text) == "" {
+		return nil, errors.New("ask the DM something")
+	}
+	if _, _, err := s.playerSession(name, u); err != nil {
+		return nil, err
+	}
+	return s.roundTurn(name, func(os *OpenSession) (*engine.Response, error) {
+		return os.Oracle.Ask(ctx, engine.MetaInput(text, os.Session.Config.Language)), nil
+	})
+}
+
+// BeginGame has the DM set the opening scene of a virtual-DM session the
+// players have taken seats in (/begin), and marks the game started.
+func (s *Service) BeginGame(ctx context.Context, name string) (*engine.Response, error) {
+	return s.roundTurn(name, func(os *OpenSession) (*engine.Response, error) {
+		st := os.Session.State
+		if st.GameStarted() {
+			return nil, errors.New("the game is already underway")
+		}
+		if st.PlayerCount() == 0 {
+			return nil, ErrNoSeats
+		}
+		resp := os.Oracle.Ask(ctx, domain.DMKickoffPrompt(os.Session.Config.Language))
+		if resp.Error == nil {
+			st.StartGame()
+		}
+		return resp, nil
+	})
+}
+
+// ResolveRound has the DM resolve the actions declared this round (/dm) and
+// narrate what happens.
+func (s *Service) ResolveRound(ctx context.Context, name string) (*engine.Response, error) {
+	return s.roundTurn(name, func(os *OpenSession) (*engine.Response, error) {
+		if !os.Session.State.GameStarted() {
+			return nil, ErrNotStarted
+		}
+		return os.Oracle.RunGroupTurn(ctx), nil
+	})
+}
+
+// TableEvent is an event of a player's live view of the table: "round" (the
+// RoundStatus), "narration" (the DM's text, with any suggested actions split off
+// as on Telegram), "chat" (an in-character line), "view" (what the player view
+// shows has changed) or "closed" (the session closed).
+type TableEvent struct {
+	Type string `json:"type"`
+	Data any    `json:"data"`
+}
+
+// Narration is a DM narration as a table event.
+type Narration struct {
+	Text    string `json:"text"`
+	Heading string `json:"heading,omitempty"`
+	Actions string `json:"actions,omitempty"`
+}
+
+// TableWatch is a player's subscription to a table. C starts with the current
+// round and is closed when the session closes (after a "closed" event), when
+// the watcher falls behind, or on Close.
+type TableWatch struct {
+	C <-chan TableEvent
+
+	sub *Subscription
+}
+
+// Close ends the watch.
+func (w *TableWatch) Close() { w.sub.Close() }
+
+// WatchTable follows an open session u has characters in, as its players see
+// it: the round, the DM's narration, in-character lines, and a nudge when the
+// player view changes. DM-facing traffic — the state's other fields, the
+// oracle's answers outside virtual-DM play — never reaches it.
+func (s *Service) WatchTable(name string, u *domain.User) (*TableWatch, error) {
+	os, _, err := s.playerSession(name, u)
+	if err != nil {
+		return nil, err
+	}
+	sub, _, _ := os.Subscribe("")
+	c := make(chan TableEvent, subBuffer)
+	c <- TableEvent{"round", roundStatus(os, u)}
+	go func() {
+		defer close(c)
+		send := func(evs ...TableEvent) {
+			for _, ev := range evs {
+				select {
+				case c <- ev:
+				default:
+					sub.Close() // fell behind: the player reconnects
+					return
+				}
+			}
+		}
+		round := func() TableEvent { return TableEvent{"round", roundStatus(os, u)} }
+		view := TableEvent{"view", struct{}{}}
+		rewound := false
+		for {
+			_, turned := os.turnState()
+			var e Event
+			var ok bool
+			select {
+			case <-turned:
+				send(round())
+				continue
+			case e, ok = <-sub.C:
+			}
+			if !ok {
+				break
+			}
+			switch e.Topic {
+			case domain.TopicRound, domain.TopicMode:
+				send(round())
+			case domain.TopicParty:
+				send(round(), view)
+			case domain.TopicLocation, domain.TopicNPC, domain.TopicWorld:
+				send(view)
+			case domain.TopicRewind:
+				// An undo republishes the state, conversation last: that message
+				// isn't new narration.
+				rewound = true
+				send(view)
+			case domain.TopicLog:
+				var entry domain.LogEntry
+				if json.Unmarshal(e.Data, &entry) == nil && entry.Type == domain.LogChat {
+					send(TableEvent{"chat", map[string]string{"text": entry.Message}})
+				}
+			case domain.TopicConversation:
+				if rewound {
+					rewound = false
+					continue
+				}
+				var conv struct {
+					Message *domain.Message `json:"message"`
+				}
+				if json.Unmarshal(e.Data, &conv) != nil || conv.Message == nil || conv.Message.Role != domain.RoleAssistant ||
+					os.Session.State.EffectiveMode() != domain.ModeVirtualDM {
+					continue
+				}
+				narr, heading, actions := domain.SplitActions(conv.Message.Content)
+				send(TableEvent{"narration", Narration{Text: narr, Heading: heading, Actions: actions}})
+			}
+		}
+		if sub.SessionClosed() {
+			send(TableEvent{"closed", struct{}{}})
+		}
+	}()
+	return &TableWatch{C: c, sub: sub}, nil
+}
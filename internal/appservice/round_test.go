@@ -0,0 +1,137 @@
+package appservice
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/theburrowhub/thaimaturgy/internal/domain"
+)
+
+// roundSession opens a virtual-DM session whose party has a character linked
+// to player "aria" and one that isn't, with the DM answering narration.
+func roundSession(t *testing.T, narration string) (*Service, *domain.User, string) {
+	t.Helper()
+	svc, _ := newService(t)
+	svc.SetProvider(&planProvider{resp: narration})
+	kael := domain.NewCharacter("Kael", "Elf", "Wizard")
+	id, err := svc.SaveCharacter(kael)
+	if err != nil {
+		t.Fatal(err)
+	}
+	kael.ID = id
+	u, err := svc.CreateUser("aria", domain.RolePlayer, "pw123")
+	if err != nil {
+		t.Fatal(err)
+	}
+	u.AssignCharacter(id)
+	name, err := svc.NewSession("crypt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = svc.CloseSession(name) })
+	if err := svc.SetParty(name, []*domain.Character{kael, domain.NewCharacter("Bryn", "Dwarf", "Cleric")}); err != nil {
+		t.Fatal(err)
+	}
+	os, _ := svc.Get(name)
+	os.Session.State.SetMode(domain.ModeVirtualDM)
+	return svc, u, name
+}
+
+// nextEvent waits for the watch's next event of type typ, skipping others.
+func nextEvent(t *testing.T, w *TableWatch, typ string) TableEvent {
+	t.Helper()
+	timeout := time.After(5 * time.Second)
+	for {
+		select {
+		case e, ok := <-w.C:
+			if !ok {
+				t.Fatalf("watch closed waiting for %q", typ)
+			}
+			if e.Type == typ {
+				return e
+			}
+		case <-timeout:
+			t.Fatalf("no %q event", typ)
+		}
+	}
+}
+
+func TestRoundLoop(t *testing.T) {
+	svc, aria, name := roundSession(t, "The gate creaks open.\n\nPossible actions:\n- Step inside")
+	ctx := context.Background()
+
+	if _, err := svc.ClaimCharacter(name, aria, "Bryn"); !errors.Is(err, ErrNotYourCharacter) {
+		t.Fatalf("claim Bryn = %v; want ErrNotYourCharacter", err)
+	}
+	if got, err := svc.ClaimCharacter(name, aria, "kael"); err != nil || got != "Kael" {
+		t.Fatalf("claim Kael = %q, %v", got, err)
+	}
+	if _, err := svc.SubmitAction(name, aria, "", "I knock"); !errors.Is(err, ErrNotStarted) {
+		t.Fatalf("action before the game began = %v; want ErrNotStarted", err)
+	}
+	if _, err := svc.ResolveRound(ctx, name); !errors.Is(err, ErrNotStarted) {
+		t.Fatalf("resolve before the game began = %v; want ErrNotStarted", err)
+	}
+	if resp, err := svc.BeginGame(ctx, name); err != nil || resp.Error != nil {
+		t.Fatalf("BeginGame: %v %v", err, resp)
+	}
+
+	watch, err := svc.WatchTable(name, aria)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer watch.Close()
+	if rs := nextEvent(t, watch, "round").Data.(*RoundStatus); !rs.Started || rs.Active != "Kael" || len(rs.Pending) != 1 {
+		t.Fatalf("first round event = %+v", rs)
+	}
+
+	if _, err := svc.Chat(name, aria, "", "Open up!"); err != nil {
+		t.Fatal(err)
+	}
+	if e := nextEvent(t, watch, "chat"); e.Data.(map[string]string)["text"] != "Kael: Open up!" {
+		t.Errorf("chat event = %+v", e.Data)
+	}
+	act, err := svc.SubmitAction(name, aria, "", "I knock")
+	if err != nil || act.Character != "Kael" || act.Player != "aria" {
+		t.Fatalf("SubmitAction = %+v, %v", act, err)
+	}
+	if rs, _ := svc.PlayerRound(name, aria); len(rs.Actions) != 1 || len(rs.Pending) != 0 {
+		t.Fatalf("round after acting = %+v", rs)
+	}
+
+	if resp, err := svc.ResolveRound(ctx, name); err != nil || resp.Error != nil {
+		t.Fatalf("ResolveRound: %v %v", err, resp)
+	}
+	n := nextEvent(t, watch, "narration").Data.(Narration)
+	if n.Text != "The gate creaks open." || n.Actions != "- Step inside" {
+		t.Errorf("narration = %+v; want the actions split off", n)
+	}
+	if rs, _ := svc.Round(name); len(rs.Actions) != 0 || rs.Resolving {
+		t.Errorf("round after resolving = %+v", rs)
+	}
+}
+
+func TestRoundTurnTurnsPlayersAway(t *testing.T) {
+	svc, aria, name := roundSession(t, "ok")
+	if _, err := svc.ClaimCharacter(name, aria, "Kael"); err != nil {
+		t.Fatal(err)
+	}
+	os, _ := svc.Get(name)
+	os.setTurn(true)
+	if _, err := svc.BeginGame(context.Background(), name); !errors.Is(err, ErrDMBusy) {
+		t.Errorf("second DM turn = %v; want ErrDMBusy", err)
+	}
+	if _, err := svc.SetActiveCharacter(name, aria, "Kael"); !errors.Is(err, ErrDMBusy) {
+		t.Errorf("switching during a turn = %v; want ErrDMBusy", err)
+	}
+	if _, _, err := svc.EditPlayerSheet(name, aria, "Kael", "gold", "+5"); !errors.Is(err, ErrDMBusy) {
+		t.Errorf("sheet edit during a turn = %v; want ErrDMBusy", err)
+	}
+	os.setTurn(false)
+	os.Session.State.SetMode(domain.ModeAssistant)
+	if _, err := svc.SetActiveCharacter(name, aria, "Kael"); !errors.Is(err, ErrNotVirtualDM) {
+		t.Errorf("round loop outside virtual-DM play = %v; want ErrNotVirtualDM", err)
+	}
+}
@@ -0,0 +1,47 @@
+package appservice
+
+import (
+	"github.com/theburrowhub/thaimaturgy/internal/engine"
+	"github.com/theburrowhub/thaimaturgy/internal/search"
+)
+
+// SearchAdventure runs a ranked full-text search over an imported adventure,
+// best match first. It is the author's view: nothing is withheld. The index is
+// built when the adventure is first searched and kept until it is saved,
+// re-imported or deleted.
+func (s *Service) SearchAdventure(id, query string, limit int) ([]search.Hit, error) {
+	ix, err := s.adventureIndex(id)
+	if err != nil {
+		return nil, err
+	}
+	hits := ix.Search(query, limit, nil)
+	if hits == nil {
+		hits = []search.Hit{}
+	}
+	return hits, nil
+}
+
+// adventureIndex returns an adventure's cached index, building it on a miss.
+// The lock is held across the load so a save landing meanwhile (which drops
+// the index after writing) can't leave a stale index cached.
+func (s *Service) adventureIndex(id string) (*search.Index, error) {
+	s.indexMu.Lock()
+	defer s.indexMu.Unlock()
+	if ix, ok := s.indexes[id]; ok {
+		return ix, nil
+	}
+	adv, err := s.store.LoadAdventure(id)
+	if err != nil {
+		return nil, err
+	}
+	ix := engine.NewAdventureIndex(adv)
+	s.indexes[id] = ix
+	return ix, nil
+}
+
+// dropIndex forgets an adventure's search index after it changed on disk.
+func (s *Service) dropIndex(id string) {
+	s.indexMu.Lock()
+	delete(s.indexes, id)
+	s.indexMu.Unlock()
+}
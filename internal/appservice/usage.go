@@ -0,0 +1,46 @@
+package appservice
+
+import (
+	"github.com/theburrowhub/thaimaturgy/internal/domain"
+	"github.com/theburrowhub/thaimaturgy/internal/engine"
+	"github.com/theburrowhub/thaimaturgy/internal/providers"
+)
+
+// Usage returns a session's usage ledger priced against the configured price
+// table and budget, resuming the session if it isn't open.
+func (s *Service) Usage(name string) (domain.UsageReport, error) {
+	os, ok := s.Get(name)
+	if !ok {
+		var err error
+		if os, err = s.ResumeSession(name); err != nil {
+			return domain.UsageReport{}, err
+		}
+	}
+	return engine.UsageReport(os.Session), nil
+}
+
+// meter wraps prov so every call it makes is added to a usage ledger through
+// add, under the given purpose.
+func meter(prov providers.Provider, purpose domain.UsagePurpose, add func(domain.UsageRecord)) providers.Provider {
+	return providers.Meter(prov, func(name, model string, u providers.Usage) {
+		add(domain.UsageRecord{
+			Provider:         name,
+			Model:            model,
+			Purpose:          purpose,
+			PromptTokens:     u.PromptTokens,
+			CompletionTokens: u.CompletionTokens,
+			TotalTokens:      u.TotalTokens,
+			CacheReadTokens:  u.CacheReadTokens,
+			CacheWriteTokens: u.CacheWriteTokens,
+		})
+	})
+}
+
+// meterSession wraps prov so its usage lands in an open session's ledger, which
+// is then autosaved.
+func (s *Service) meterSession(name string, st *domain.SessionState, prov providers.Provider, purpose domain.UsagePurpose) providers.Provider {
+	return meter(prov, purpose, func(r domain.UsageRecord) {
+		st.RecordUsage(r)
+		s.Autosave(name)
+	})
+}
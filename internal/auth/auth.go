@@ -1,10 +1,12 @@
 // Package auth discovers AI-provider credentials already present on the machine
-// — provider API keys in the environment, and OAuth logins from local tools
-// like Claude Code and the Gemini CLI — so the app can auto-configure itself
-// and tell the user which credential it picked up.
+// — provider API keys in the environment, OAuth logins from local tools like
+// Claude Code and the Gemini CLI, and a model server running on this machine —
+// so the app can auto-configure itself and tell the user which credential it
+// picked up.
 package auth
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"os"
@@ -15,6 +17,7 @@ import (
 	"time"
 
 	"github.com/theburrowhub/thaimaturgy/internal/domain"
+	"github.com/theburrowhub/thaimaturgy/internal/providers"
 )
 
 // Method describes how a credential authenticates.
@@ -23,6 +26,8 @@ type Method string
 const (
 	MethodAPIKey Method = "api_key"
 	MethodOAuth  Method = "oauth"
+	// MethodLocal is a model server on this machine, which needs no key.
+	MethodLocal Method = "local"
 )
 
 // Credential is a discovered way to talk to a provider.
@@ -33,16 +38,22 @@ type Credential struct {
 	Token    string
 	Source   string // human-readable origin, e.g. "Claude Code login (Keychain)"
 	Expired  bool
+	// BaseURL and Model locate a local model server and the first model it
+	// offers.
+	BaseURL string
+	Model   string
 }
 
 // Detect probes every known credential source and returns what it finds, in a
 // stable priority order: for each provider, explicit env API keys first, then
-// reused local OAuth logins.
+// reused local OAuth logins; a local model server comes last, so it is only
+// picked when there is nothing else (e.g. offline).
 func Detect() []Credential {
 	var creds []Credential
 	creds = append(creds, detectAnthropic()...)
 	creds = append(creds, detectOpenAI()...)
 	creds = append(creds, detectGemini()...)
+	creds = append(creds, detectLocal()...)
 	return creds
 }
 
@@ -99,11 +110,16 @@ func apply(c *domain.Config, cr Credential) {
 		} else {
 			c.GeminiAPIKey = cr.APIKey
 		}
+	case domain.ProviderLocal:
+		c.LocalBaseURL = cr.BaseURL
 	}
 	// Keep a model the user configured for this provider; only set a default
 	// when switching provider or when none is set.
 	if switchedProvider || c.Model == "" {
 		c.Model = domain.DefaultModel(cr.Provider)
+		if cr.Model != "" {
+			c.Model = cr.Model
+		}
 	}
 	c.AuthSource = cr.Source
 }
@@ -116,6 +132,8 @@ func label(p domain.ProviderType) string {
 		return "Anthropic (Claude)"
 	case domain.ProviderGemini:
 		return "Gemini"
+	case domain.ProviderLocal:
+		return "a local model server"
 	}
 	return string(p)
 }
@@ -230,6 +248,44 @@ func geminiCLIToken() (token string, expired bool, source string, ok bool) {
 	return tok, exp, "Gemini CLI login (~/.gemini)", true
 }
 
+// --- Local model server ------------------------------------------------
+
+// localEndpoints are the default addresses of the common local servers:
+// Ollama, LM Studio, llama.cpp and vLLM. A package var so tests can stub it.
+var localEndpoints = []string{
+	"http://localhost:11434/v1",
+	"http://localhost:1234/v1",
+	"http://localhost:8080/v1",
+	"http://localhost:8000/v1",
+}
+
+// localProbeTimeout bounds each probe; a server on this machine answers in
+// milliseconds.
+const localProbeTimeout = 500 * time.Millisecond
+
+// detectLocal looks for an OpenAI-compatible model server: the one named by
+// THAIM_LOCAL_BASE_URL, else the first default address that lists a model.
+func detectLocal() []Credential {
+	endpoints := localEndpoints
+	source := "%s"
+	if u := firstEnv("THAIM_LOCAL_BASE_URL"); u != "" {
+		endpoints = []string{u}
+		source = "%s (THAIM_LOCAL_BASE_URL)"
+	}
+	for _, base := range endpoints {
+		ctx, cancel := context.WithTimeout(context.Background(), localProbeTimeout)
+		models, err := providers.ListModels(ctx, base, firstEnv("THAIM_LOCAL_API_KEY"))
+		cancel()
+		if err != nil || len(models) == 0 {
+			continue
+		}
+		base = providers.NormalizeBaseURL(base)
+		return []Credential{{Provider: domain.ProviderLocal, Method: MethodLocal, BaseURL: base, Model: models[0],
+			Source: fmt.Sprintf(source, base)}}
+	}
+	return nil
+}
+
 func firstEnv(keys ...string) string {
 	for _, k := range keys {
 		if v := strings.TrimSpace(os.Getenv(k)); v != "" {
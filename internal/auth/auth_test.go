@@ -1,8 +1,11 @@
 package auth
 
 import (
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/theburrowhub/thaimaturgy/internal/domain"
@@ -16,6 +19,7 @@ func isolate(t *testing.T) string {
 		"THAIM_OPENAI_API_KEY", "OPENAI_API_KEY",
 		"THAIM_ANTHROPIC_API_KEY", "ANTHROPIC_API_KEY",
 		"THAIM_GEMINI_API_KEY", "GEMINI_API_KEY", "GOOGLE_API_KEY",
+		"THAIM_LOCAL_BASE_URL", "THAIM_LOCAL_API_KEY",
 	} {
 		t.Setenv(k, "")
 	}
@@ -25,6 +29,10 @@ func isolate(t *testing.T) string {
 	orig := macKeychainCreds
 	macKeychainCreds = func() ([]byte, bool) { return nil, false }
 	t.Cleanup(func() { macKeychainCreds = orig })
+
+	origLocal := localEndpoints
+	localEndpoints = nil
+	t.Cleanup(func() { localEndpoints = origLocal })
 	return home
 }
 
@@ -129,3 +137,42 @@ func TestAutoConfigureNothing(t *testing.T) {
 		t.Errorf("expected empty message with no creds, got %q", msg)
 	}
 }
+
+func TestDetectLocalServer(t *testing.T) {
+	isolate(t)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/models" {
+			http.NotFound(w, r)
+			return
+		}
+		_, _ = w.Write([]byte(`{"object":"list","data":[{"id":"llama3.2:3b"}]}`))
+	}))
+	defer srv.Close()
+	down := httptest.NewServer(http.NotFoundHandler()) // something else on a default port
+	defer down.Close()
+	localEndpoints = []string{down.URL + "/v1", srv.URL + "/v1"}
+
+	c := domain.DefaultConfig()
+	msg := AutoConfigure(c)
+	if !strings.Contains(msg, srv.URL) {
+		t.Errorf("message = %q", msg)
+	}
+	if c.Provider != domain.ProviderLocal || c.LocalBaseURL != srv.URL+"/v1" || c.Model != "llama3.2:3b" || !c.IsConfigured() {
+		t.Errorf("config = provider %q, base %q, model %q", c.Provider, c.LocalBaseURL, c.Model)
+	}
+
+	// A cloud credential still wins; the local server comes last.
+	t.Setenv("OPENAI_API_KEY", "sk-oai")
+	creds := Detect()
+	if len(creds) != 2 || creds[0].Provider != domain.ProviderOpenAI || creds[1].Provider != domain.ProviderLocal {
+		t.Errorf("creds = %+v", creds)
+	}
+
+	// THAIM_LOCAL_BASE_URL replaces the default addresses.
+	t.Setenv("OPENAI_API_KEY", "")
+	t.Setenv("THAIM_LOCAL_BASE_URL", strings.TrimPrefix(srv.URL, "http://"))
+	localEndpoints = nil
+	if creds := Detect(); len(creds) != 1 || creds[0].BaseURL != srv.URL+"/v1" {
+		t.Errorf("creds from THAIM_LOCAL_BASE_URL = %+v", creds)
+	}
+}
@@ -0,0 +1,1081 @@
+// Package chathost implements the multiplayer chat front-end independently of
+// any chat platform: it drives a virtual-DM session over one or more chats
+// (players claim party members, declare actions with /do, and trigger the AI DM
+// with /dm). The platform side — delivering text, images and spoilers, and
+// turning inbound messages into commands from identified users — is an Adapter;
+// internal/tgbot is the Telegram one and internal/ircbot the IRC one. A Host
+// with several adapters runs one table shared by players on different platforms.
+package chathost
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/theburrowhub/thaimaturgy/internal/domain"
+	"github.com/theburrowhub/thaimaturgy/internal/engine"
+	"github.com/theburrowhub/thaimaturgy/internal/storage"
+)
+
+// Adapter connects a Host to a chat platform. Chats are identified by the
+// platform's own ids, as strings (a Telegram chat id, an IRC channel). Sends
+// split text the platform can't take in one message themselves.
+//
+// An adapter may also implement interface{ Help() string } to add lines for
+// platform-specific commands it answers itself to the host's /help.
+type Adapter interface {
+	// Platform names the chat platform ("Telegram", "IRC"), for logs and to
+	// scope /assign @username reservations, which bind only on the platform
+	// they were made on.
+	Platform() string
+	// Home is the chat the adapter is configured to play in, or "" when it
+	// isn't restricted to one; table-wide posts go there (or, failing that, to
+	// the chat last heard from on the adapter).
+	Home() string
+	// Receive delivers every accepted inbound message to fn, one at a time,
+	// until ctx is cancelled or Stop is called.
+	Receive(ctx context.Context, fn func(Message))
+	// Stop ends Receive.
+	Stop()
+	Send(chat, text string) error
+	// SendImage posts the image file at path with a caption.
+	SendImage(chat, path, caption string) error
+	// SendSpoiler posts body hidden until a reader chooses to see it, under a
+	// visible heading (which may be empty).
+	SendSpoiler(chat, heading, body string) error
+}
+
+// User identifies who sent a message.
+type User struct {
+	// ID is the sender's stable id, the key of their player slot. It must not
+	// collide across platforms: Telegram uses the bare numeric user id (as it
+	// always has), other adapters prefix theirs ("irc:…").
+	ID string
+	// Name is how the table sees the sender.
+	Name string
+	// Username is the handle /assign @username reserves a character for, on
+	// the sender's platform; empty when the sender has none.
+	Username string
+}
+
+// Message is one inbound message. Command is empty for ordinary chatter, which
+// the host still sees so a character reserved for the sender binds to them.
+type Message struct {
+	Chat    string
+	From    User
+	Command string // without its prefix, e.g. "do"
+	Args    string
+	ReplyTo *User // the sender of the message this one replies to, if any
+
+	adapter Adapter // set by the Host: where the message came from
+}
+
+// Options configures a Host.
+type Options struct {
+	// OnEvent, if set, is called with short human-readable activity lines (player
+	// joins, actions, narration) so a host UI can mirror what happens in the chat.
+	OnEvent func(string)
+}
+
+// Host hosts a multiplayer virtual-DM session over one or more chat adapters.
+type Host struct {
+	adapters []Adapter
+	store    *storage.Storage
+	session  *domain.Session
+	oracle   *engine.Oracle
+	onEvent  func(string)
+
+	mu        sync.Mutex // guards resolving and lastChat
+	resolving bool
+	lastChat  map[Adapter]string // the chat each adapter last heard from, for table-wide posts
+	saveMu    sync.Mutex         // serializes session file writes
+	runCtx    context.Context    // set by Run; parents each /dm turn so Stop cancels it
+	turns     sync.WaitGroup     // tracks in-flight /dm turns so Stop can wait them out
+}
+
+// New builds a Host bound to a live session and oracle, playing over adapters.
+// The session should already be in virtual-DM mode with a party (the caller
+// ensures this).
+func New(store *storage.Storage, session *domain.Session, oracle *engine.Oracle, opts Options, adapters ...Adapter) *Host {
+	// A session already played (in the GUI or a prior run) shouldn't demand /begin
+	// or re-narrate an opening — treat it as started when there's evidence.
+	session.State.MarkStartedIfInProgress()
+	return &Host{
+		adapters: adapters,
+		store:    store,
+		session:  session,
+		oracle:   oracle,
+		onEvent:  opts.OnEvent,
+		lastChat: map[Adapter]string{},
+	}
+}
+
+// Run processes inbound messages from every adapter until ctx is cancelled.
+// Call it directly (blocking) for the standalone binary, or in a goroutine with
+// a cancellable context for the in-app host; Stop ends the receive loops.
+func (h *Host) Run(ctx context.Context) {
+	h.runCtx = ctx
+	platforms := make([]string, len(h.adapters))
+	for i, a := range h.adapters {
+		platforms[i] = a.Platform()
+	}
+	log.Printf("thaimaturgy-bot hosting adventure %q, session %q on %s", h.session.Adventure.ID, h.session.State.Name, strings.Join(platforms, ", "))
+	// Messages from different platforms are handled one at a time, as a single
+	// platform's are.
+	var handle sync.Mutex
+	var wg sync.WaitGroup
+	for _, a := range h.adapters {
+		wg.Add(1)
+		go func(a Adapter) {
+			defer wg.Done()
+			a.Receive(ctx, func(m Message) {
+				m.adapter = a
+				handle.Lock()
+				defer handle.Unlock()
+				h.onMessage(&m)
+			})
+		}(a)
+	}
+	wg.Wait()
+}
+
+// Stop ends the receive loops and waits for any in-flight /dm turn to finish, so
+// a torn-down host never keeps mutating/saving the (now abandoned) session.
+// Cancel the context passed to Run first to abort a slow turn promptly.
+func (h *Host) Stop() {
+	for _, a := range h.adapters {
+		a.Stop()
+	}
+	h.turns.Wait()
+}
+
+func (h *Host) onMessage(m *Message) {
+	h.mu.Lock()
+	h.lastChat[m.adapter] = m.Chat
+	h.mu.Unlock()
+	// Bind any character reserved for this sender's @username (via /assign) the
+	// first time they appear. Reservations are per platform: a Telegram @ana's
+	// can't be taken by whoever is "ana" on IRC.
+	if pc, bound := h.session.State.ResolvePending(m.From.ID, m.adapter.Platform(), m.From.Username, m.From.Name); bound {
+		h.save()
+		h.event(fmt.Sprintf("%s → %s (assigned)", m.From.Name, pc))
+		h.reply(m, fmt.Sprintf("%s is now playing %s.", m.From.Name, pc))
+	}
+	if m.Command != "" {
+		h.handleCommand(m)
+	}
+}
+
+func (h *Host) handleCommand(m *Message) {
+	playerID := m.From.ID
+	display := m.From.Name
+	arg := strings.TrimSpace(m.Args)
+
+	switch m.Command {
+	case "begin", "beginadventure":
+		h.startGame(m)
+	case "start", "help":
+		// /start is what Telegram auto-sends when a user opens the chat, so it must
+		// stay harmless (help) — the game is begun explicitly with /begin.
+		h.reply(m, h.helpText(m))
+	case "map":
+		h.sendZoneMap(m)
+	case "portrait", "npcart":
+		h.sendNPCArt(m, arg)
+	case "party":
+		h.reply(m, h.partyText())
+	case "roster":
+		h.reply(m, h.rosterText())
+	case "pick", "play":
+		name, err := h.session.State.ClaimCharacter(playerID, display, arg)
+		if err != nil {
+			h.reply(m, "⚠ "+err.Error())
+			return
+		}
+		h.save()
+		h.event(fmt.Sprintf("%s picked %s", display, name))
+		controlled := h.session.State.PlayerCharacterNames(playerID)
+		if len(controlled) > 1 {
+			h.reply(m, fmt.Sprintf("%s now also plays %s (active). You control: %s. Use /as <name> to switch, or “/do <name>: …”.", display, name, strings.Join(controlled, ", ")))
+		} else {
+			h.reply(m, fmt.Sprintf("%s now plays %s. Declare actions with /do.", display, name))
+		}
+	case "as", "switch":
+		if arg == "" {
+			h.reply(m, "Usage: /as <character> — choose which of your characters is active.")
+			return
+		}
+		name, err := h.session.State.SetActiveCharacter(playerID, arg)
+		if err != nil {
+			h.reply(m, "⚠ "+err.Error())
+			return
+		}
+		h.save()
+		h.reply(m, "You are now acting as "+name+".")
+	case "chat", "say":
+		if !h.session.State.GameStarted() {
+			h.reply(m, notStartedMsg)
+			return
+		}
+		if arg == "" {
+			h.reply(m, "Usage: /chat [<character>:] <what your character says>")
+			return
+		}
+		actor, text := splitActor(arg, h.session.State.PlayerCharacterNames(playerID))
+		char, err := h.session.State.ResolvePlayerCharacter(playerID, actor)
+		if err != nil {
+			h.reply(m, "⚠ "+err.Error())
+			return
+		}
+		if strings.TrimSpace(text) == "" {
+			h.reply(m, "Usage: /chat [<character>:] <what your character says>")
+			return
+		}
+		if h.isResolving() {
+			h.reply(m, "The DM is resolving the round — try again in a moment.")
+			return
+		}
+		h.session.State.AddChat(char, text)
+		h.save()
+		h.event(fmt.Sprintf("%s (in character): %s", char, text))
+		h.announce(m, fmt.Sprintf("💬 %s: %s", char, text))
+	case "meta", "ooc":
+		if arg == "" {
+			h.reply(m, "Usage: /meta <question or correction for the DM>")
+			return
+		}
+		h.runMeta(m, arg)
+	case "assign":
+		h.assign(m)
+	case "me":
+		h.reply(m, h.sheetText(playerID, arg))
+	case "do":
+		if !h.session.State.GameStarted() {
+			h.reply(m, notStartedMsg)
+			return
+		}
+		if arg == "" {
+			h.reply(m, "Usage: /do [<character>:] <what your character does>")
+			return
+		}
+		actor, text := splitActor(arg, h.session.State.PlayerCharacterNames(playerID))
+		if strings.TrimSpace(text) == "" {
+			h.reply(m, "Usage: /do [<character>:] <what your character does>")
+			return
+		}
+		act, err := h.session.State.SubmitAction(playerID, actor, text)
+		if err != nil {
+			h.reply(m, "⚠ "+err.Error())
+			return
+		}
+		h.save()
+		h.event(fmt.Sprintf("%s: %s", act.CharacterName, text))
+		h.mirror(m, fmt.Sprintf("✋ %s: %s", act.CharacterName, text))
+		h.reply(m, h.roundStatus())
+	case "dm", "narrate":
+		if !h.session.State.GameStarted() {
+			h.reply(m, notStartedMsg)
+			return
+		}
+		h.runDM(m)
+	case "roll":
+		h.reply(m, rollText(arg))
+	case "check":
+		h.check(m, playerID, arg)
+	case "levelup":
+		h.levelUp(m, playerID, arg)
+	case "deathsave", "ds":
+		h.deathSave(m, playerID, arg)
+	case "hp", "ac", "temphp", "thp", "slot", "slots", "condition", "cond", "uncondition", "uncond",
+		"gold", "xp", "item", "inv", "savethrow", "st", "skill", "spell", "spells", "setnote":
+		h.editSheet(m, m.Command, arg)
+	case "save":
+		h.saveAndReport(m)
+	case "log":
+		h.reply(m, h.logText(arg))
+	case "combat":
+		h.reply(m, h.combatText())
+	default:
+		h.delegateToEngine(m)
+	}
+}
+
+// logText renders the recent session timeline for players (issue #25). It hides
+// free-form DM notes (LogNote), which are DM-only, to avoid leaking them.
+func (h *Host) logText(arg string) string {
+	n := 15
+	if v, err := strconv.Atoi(strings.TrimSpace(arg)); err == nil && v > 0 {
+		if v > 50 {
+			v = 50
+		}
+		n = v
+	}
+	entries := h.session.State.RecentLog(n * 3) // over-fetch: DM notes are filtered out below
+	lines := make([]string, 0, n)
+	for _, e := range entries {
+		if e.Type == domain.LogNote {
+			continue
+		}
+		ts := ""
+		if !e.Timestamp.IsZero() {
+			ts = e.Timestamp.Format("15:04") + "  "
+		}
+		lines = append(lines, fmt.Sprintf("%s %s%s", engine.LogIcon(e.Type), ts, e.Message))
+	}
+	if len(lines) == 0 {
+		return "The session log is empty."
+	}
+	if len(lines) > n {
+		lines = lines[len(lines)-n:]
+	}
+	// Blank line between entries so rolls (🎲) and other beats are easy to scan.
+	return fmt.Sprintf("📜 Session log (last %d):\n\n%s", len(lines), strings.Join(lines, "\n\n"))
+}
+
+// playerSafeCommands is the subset of engine slash commands the chat bot may
+// run on behalf of *any* player. It deliberately excludes:
+//   - DM-facing reads that would leak secrets/DM notes/hidden content to players
+//     (room/look, zone, npc, npcs, event, item, search) — see #28;
+//   - authoritative mutations players must not trigger (goto, flag);
+//   - desktop-only UI actions (load, import, mode, map, art).
+//
+// These stay available in the desktop DM console; multiplayer commands
+// (begin/pick/assign/me/do/dm) are handled explicitly above.
+var playerSafeCommands = map[string]bool{
+	"status": true,                // where the party is + progress counters (no DM notes)
+	"quests": true, "quest": true, // player-facing quest log
+	"note":       true, // benign: append a note to the timeline
+	"rest":       true, // short/long rest for the party (or a named character)
+	"recap":      true, // "previously on…" from session state (no DM notes/secrets)
+	"previously": true, // alias of /recap
+	"glosario":   true, // known people + visited places (player-safe, no secrets)
+	"glossary":   true, // alias of /glosario
+	"who":        true, // alias of /glosario
+}
+
+// delegateToEngine routes a small, player-safe subset of slash commands through
+// the SAME engine.CommandHandler the desktop app uses, so shared commands behave
+// identically across both frontends (parity, #20) without exposing DM-only
+// content or authoritative mutations to players.
+func (h *Host) delegateToEngine(m *Message) {
+	cmd := m.Command
+	if !playerSafeCommands[cmd] {
+		h.reply(m, "Unknown or DM-only command. "+h.helpText(m))
+		return
+	}
+	// Resting changes HP/hit dice, so it only makes sense once the game has begun
+	// (mirrors the desktop app, where Rest is hidden until then).
+	if cmd == "rest" && !h.session.State.GameStarted() {
+		h.reply(m, notStartedMsg)
+		return
+	}
+	mutating := cmd == "note" || cmd == "rest"
+	// Serialize against an in-flight /dm resolution: a mutation applied while the
+	// turn snapshot is open could be lost when that snapshot is merged back.
+	if mutating && h.isResolving() {
+		h.reply(m, "The DM is resolving the round — try again in a moment.")
+		return
+	}
+	raw := "/" + cmd
+	if arg := strings.TrimSpace(m.Args); arg != "" {
+		raw += " " + arg
+	}
+	res := engine.NewCommandHandler(h.session).Execute(engine.ParseCommand(raw))
+	if mutating && res.Success {
+		h.save() // persist the mutation even when it only sets Message
+	}
+	switch {
+	case res.Response != "":
+		h.reply(m, res.Response)
+	case res.Message != "":
+		h.reply(m, res.Message) // keep the command's specific message (e.g. usage errors)
+	default:
+		h.reply(m, "Done.")
+	}
+}
+
+// isResolving reports whether a /dm turn is currently being resolved.
+func (h *Host) isResolving() bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.resolving
+}
+
+// saveAndReport persists the current session on demand (the /save command) and
+// reports the outcome to the chat, unlike the internal best-effort save().
+func (h *Host) saveAndReport(m *Message) {
+	h.saveMu.Lock()
+	err := h.store.SaveSession(h.session.State)
+	h.saveMu.Unlock()
+	if err != nil {
+		log.Printf("save: %v", err)
+		h.reply(m, "⚠ Couldn't save the session: "+err.Error())
+		return
+	}
+	h.event(fmt.Sprintf("%s saved the session", m.From.Name))
+	h.reply(m, "💾 Session saved as “"+h.session.State.Name+"”.")
+}
+
+// assign lets a host give a character to a player who hasn't picked. Two forms:
+//   - reply to the target player's message with `/assign <character>` → bound
+//     immediately (works for anyone, even without a public @username);
+//   - `/assign @username <character>` → reserved for that username on the
+//     assigner's platform and bound when they next send a message there (a
+//     platform like Telegram can't resolve @username → id directly).
+func (h *Host) assign(m *Message) {
+	arg := strings.TrimSpace(m.Args)
+
+	// Reply form: /assign <character>, replying to the target's message.
+	if m.ReplyTo != nil {
+		if arg == "" {
+			h.reply(m, "Usage: reply to the player's message with /assign <character>")
+			return
+		}
+		target := m.ReplyTo
+		name, err := h.session.State.ClaimCharacter(target.ID, target.Name, arg)
+		if err != nil {
+			h.reply(m, "⚠ "+err.Error())
+			return
+		}
+		h.save()
+		h.event(fmt.Sprintf("%s assigned %s to %s", m.From.Name, name, target.Name))
+		h.reply(m, fmt.Sprintf("%s now plays %s.", target.Name, name))
+		return
+	}
+
+	// Username form: /assign @username <character>.
+	fields := strings.Fields(arg)
+	if len(fields) < 2 || !strings.HasPrefix(fields[0], "@") {
+		h.reply(m, "Usage: /assign @username <character>  (or reply to their message with /assign <character>)")
+		return
+	}
+	username := fields[0]
+	pc := strings.TrimSpace(strings.TrimPrefix(arg, fields[0]))
+	name, err := h.session.State.AssignByUsername(m.adapter.Platform(), username, pc)
+	if err != nil {
+		h.reply(m, "⚠ "+err.Error())
+		return
+	}
+	h.save()
+	h.event(fmt.Sprintf("%s assigned %s to %s", m.From.Name, name, username))
+	h.reply(m, fmt.Sprintf("%s reserved for %s on %s — it takes effect when they next send a message there.", name, username, m.adapter.Platform()))
+}
+
+// startGame begins the game: the DM sets the opening scene, then hands off to the
+// players. Before this, /do and /dm are ignored. Idempotent — a second /start
+// once underway just says so.
+func (h *Host) startGame(m *Message) {
+	if h.session.State.GameStarted() {
+		h.reply(m, "The game is already underway — declare actions with /do, then /dm.")
+		return
+	}
+	if h.session.State.PlayerCount() == 0 {
+		h.reply(m, "Everyone pick a character first (/party then /pick <name>), then /begin.")
+		return
+	}
+	h.mu.Lock()
+	if h.resolving {
+		h.mu.Unlock()
+		h.reply(m, "The DM is busy — hang on…")
+		return
+	}
+	h.resolving = true
+	h.mu.Unlock()
+
+	h.announce(m, "🎬 The DM is setting the scene…")
+	h.turns.Add(1)
+	go func() {
+		defer h.turns.Done()
+		defer func() {
+			h.mu.Lock()
+			h.resolving = false
+			h.mu.Unlock()
+		}()
+		ctx, cancel := context.WithTimeout(h.turnBase(), h.turnTimeout())
+		defer cancel()
+
+		resp := h.oracle.Ask(ctx, domain.DMKickoffPrompt(h.session.Config.Language))
+		if h.turnBase().Err() != nil {
+			return
+		}
+		if resp.Error != nil {
+			log.Printf("intro: %v", resp.Error)
+			h.reply(m, "⚠ The DM couldn't set the scene right now. Try /start again in a moment.")
+			return
+		}
+		h.session.State.StartGame()
+		h.save()
+		h.event("DM: " + resp.Answer)
+		h.narrate(m, resp.Answer)
+	}()
+}
+
+// turnBase returns the parent context for a DM turn (the Run ctx, so Stop cancels
+// an in-flight turn), defaulting to Background before Run is called.
+func (h *Host) turnBase() context.Context {
+	if h.runCtx == nil {
+		return context.Background()
+	}
+	return h.runCtx
+}
+
+// turnTimeout is the per-turn timeout from config (90s default).
+func (h *Host) turnTimeout() time.Duration {
+	if t := time.Duration(h.session.Config.RequestTimeoutSeconds) * time.Second; t > 0 {
+		return t
+	}
+	return 90 * time.Second
+}
+
+// runDM resolves the current round with the AI DM and posts the narration. Only
+// one turn runs at a time; other commands stay responsive meanwhile.
+// runMeta answers an out-of-character player question/correction immediately via
+// the oracle (issue #19). It reuses the same turn guard as runDM so it can't run
+// concurrently with a /dm resolution and races the session state.
+func (h *Host) runMeta(m *Message, text string) {
+	h.mu.Lock()
+	if h.resolving {
+		h.mu.Unlock()
+		h.reply(m, "The DM is busy resolving the round — try again in a moment.")
+		return
+	}
+	h.resolving = true
+	h.mu.Unlock()
+
+	h.reply(m, "🗨 The DM is considering your note…")
+	h.turns.Add(1)
+	go func() {
+		defer h.turns.Done()
+		defer func() {
+			h.mu.Lock()
+			h.resolving = false
+			h.mu.Unlock()
+		}()
+		ctx, cancel := context.WithTimeout(h.turnBase(), h.turnTimeout())
+		defer cancel()
+
+		resp := h.oracle.Ask(ctx, engine.MetaInput(text, h.session.Config.Language))
+		if h.turnBase().Err() != nil {
+			return
+		}
+		if resp.Error != nil {
+			log.Printf("meta: %v", resp.Error)
+			h.reply(m, "⚠ The DM couldn't answer right now. Try /meta again in a moment.")
+			return
+		}
+		h.save()
+		h.event("DM (meta): " + resp.Answer)
+		h.announce(m, resp.Answer)
+	}()
+}
+
+func (h *Host) runDM(m *Message) {
+	h.mu.Lock()
+	if h.resolving {
+		h.mu.Unlock()
+		h.reply(m, "The DM is already narrating — hang on…")
+		return
+	}
+	if len(h.session.State.RoundActions()) == 0 {
+		h.mu.Unlock()
+		h.reply(m, "No actions declared yet. Players, use /do first.")
+		return
+	}
+	h.resolving = true
+	h.mu.Unlock()
+
+	h.announce(m, "🎲 The DM is thinking…")
+	h.turns.Add(1)
+	go func() {
+		defer h.turns.Done()
+		defer func() {
+			h.mu.Lock()
+			h.resolving = false
+			h.mu.Unlock()
+		}()
+		ctx, cancel := context.WithTimeout(h.turnBase(), h.turnTimeout())
+		defer cancel()
+
+		resp := h.oracle.RunGroupTurn(ctx)
+		// If the host was torn down (Run ctx cancelled) mid-turn, don't save or
+		// post to a game that's been abandoned.
+		if h.turnBase().Err() != nil {
+			return
+		}
+		if resp.Error != nil {
+			log.Printf("group turn: %v", resp.Error) // don't leak details to the chat
+			h.reply(m, "⚠ The DM couldn't resolve the turn right now. Try /dm again in a moment.")
+			return
+		}
+		h.save()
+		h.event("DM: " + resp.Answer)
+		h.narrate(m, resp.Answer)
+	}()
+}
+
+// rosterText lists the persistent campaign roster (issue #33) for players to
+// consult from the chat. Creating/choosing characters is done host-side in the
+// app; this is the read-only chat view.
+func (h *Host) rosterText() string {
+	// ListCharacters may return decoded characters AND an error (some files
+	// unreadable); surface the warning but still list what loaded.
+	chars, err := h.store.ListCharacters()
+	if len(chars) == 0 {
+		if err != nil {
+			return "⚠ Couldn't read the roster: " + err.Error()
+		}
+		return "The campaign roster is empty. Save characters from the app (Edit party → Roster…)."
+	}
+	var sb strings.Builder
+	if err != nil {
+		sb.WriteString("⚠ Some roster entries could not be read: " + err.Error() + "\n")
+	}
+	sb.WriteString("🧑‍🤝‍🧑 Campaign roster:\n")
+	for _, c := range chars {
+		sb.WriteString(fmt.Sprintf("• %s — Lvl %d %s %s\n", c.Name, c.Level, c.Race, c.Class))
+	}
+	return sb.String()
+}
+
+func (h *Host) partyText() string {
+	party := h.session.State.PartySnapshot()
+	if len(party) == 0 {
+		return "The party is empty."
+	}
+	controllers := h.session.State.Controllers()
+	pending := h.session.State.PendingByCharacter()
+	var sb strings.Builder
+	sb.WriteString("Party — pick one with /pick <name>:\n")
+	for i := range party {
+		c := party[i]
+		line := fmt.Sprintf("• %s — Lvl %d %s %s", c.Name, c.Level, c.Race, c.Class)
+		if who, ok := controllers[c.Name]; ok {
+			line += " — played by " + who
+		} else if u, ok := pending[c.Name]; ok {
+			line += " — reserved for @" + u
+		}
+		sb.WriteString(line + "\n")
+	}
+	return sb.String()
+}
+
+// splitActor separates an optional "<character>: rest" prefix from an argument,
+// but ONLY when the part before the colon names one of the player's controlled
+// characters — so a mid-sentence colon ("open the chest: it's locked") isn't
+// mistaken for a character selector. Returns ("", arg) when no actor prefix
+// applies.
+func splitActor(arg string, controlled []string) (actor, rest string) {
+	i := strings.Index(arg, ":")
+	if i <= 0 {
+		return "", arg
+	}
+	cand := strings.TrimSpace(arg[:i])
+	for _, c := range controlled {
+		if strings.EqualFold(c, cand) {
+			return c, strings.TrimSpace(arg[i+1:])
+		}
+	}
+	return "", arg
+}
+
+// sheetText shows a player's character sheet. With no argument it shows the
+// active character (and, when the player controls several, lists them all with
+// the active one starred); "/me <name>" shows a specific controlled character.
+func (h *Host) sheetText(playerID, arg string) string {
+	names := h.session.State.PlayerCharacterNames(playerID)
+	if len(names) == 0 {
+		return "You haven't picked a character yet. See /party then /pick <name>."
+	}
+	active := h.session.State.PlayerCharacterName(playerID)
+	target := strings.TrimSpace(arg)
+	if target == "" {
+		target = active
+	} else {
+		ok := false
+		for _, n := range names {
+			if strings.EqualFold(n, target) {
+				target, ok = n, true
+				break
+			}
+		}
+		if !ok {
+			return "You don't control “" + target + "”. You play: " + strings.Join(names, ", ")
+		}
+	}
+	header := ""
+	if len(names) > 1 {
+		labeled := make([]string, len(names))
+		for i, n := range names {
+			if strings.EqualFold(n, active) {
+				n = "⭐ " + n
+			}
+			labeled[i] = n
+		}
+		header = "You control: " + strings.Join(labeled, ", ") + "\n(/me <name> for another · /as <name> to switch active)\n\n"
+	}
+	for _, c := range h.session.State.PartySnapshot() {
+		if strings.EqualFold(c.Name, target) {
+			return header + engine.FormatCharacter(&c)
+		}
+	}
+	return "Character not found: " + target
+}
+
+// combatText is the players' read-only view of the tracked fight: the party's
+// HP is exact, everyone else's is only a band (#28). Starting, advancing and
+// ending combat is the DM's job, so there are no subcommands here.
+func (h *Host) combatText() string {
+	c := h.session.State.CombatSnapshot()
+	if c == nil {
+		return "No combat in progress."
+	}
+	return "⚔️ " + engine.FormatCombat(c, h.session.State.PartySnapshot(), true)
+}
+
+func (h *Host) roundStatus() string {
+	pending := h.session.State.PendingPlayers()
+	if len(pending) == 0 {
+		return "Action recorded. Everyone has acted — a player can call /dm to let the DM narrate."
+	}
+	return fmt.Sprintf("Action recorded. Waiting on: %s (or /dm to resolve now).", strings.Join(pending, ", "))
+}
+
+func rollText(notation string) string {
+	if notation == "" {
+		notation = "1d20"
+	}
+	roll, err := engine.RollDice(notation)
+	if err != nil {
+		return "⚠ " + err.Error()
+	}
+	msg := fmt.Sprintf("🎲 %s: %s", roll.String(), roll.ResultString())
+	if roll.IsCriticalHit() {
+		msg += " — CRIT!"
+	} else if roll.IsCriticalFail() {
+		msg += " — FUMBLE!"
+	}
+	return msg
+}
+
+// check rolls a sheet-aware check for one of the sender's own characters (the
+// active one unless the arguments start with another controlled name), through
+// the same engine /check the desktop app uses.
+func (h *Host) check(m *Message, playerID, arg string) {
+	h.runForOwnCharacter(m, playerID, "check", arg,
+		"Usage: /check [name] <skill|ability> [save] [DC] [adv|dis] [vs <opponent>]", "🎲 ")
+}
+
+// levelUp levels up one of the sender's own characters once its XP has reached
+// the next threshold. Milestone levels are the DM's call, so players can't force
+// one from here.
+func (h *Host) levelUp(m *Message, playerID, arg string) {
+	for _, w := range strings.Fields(arg) {
+		if w = strings.ToLower(w); w == "milestone" || w == "force" {
+			h.reply(m, "Milestone levels are granted by the DM.")
+			return
+		}
+	}
+	h.runForOwnCharacter(m, playerID, "levelup", arg, "", "⬆ ")
+}
+
+// deathSave rolls a death saving throw for one of the sender's characters at 0
+// HP. Stabilizing is done by someone else (a Medicine check, a spell), and
+// raising the dead is the DM's call, so both are left to the DM.
+func (h *Host) deathSave(m *Message, playerID, arg string) {
+	for _, w := range strings.Fields(arg) {
+		w = strings.ToLower(w)
+		if strings.HasPrefix(w, "stabili") {
+			h.reply(m, "A dying character can't stabilize themselves — an ally's Medicine check or a spell does it, through the DM.")
+			return
+		}
+		if strings.HasPrefix(w, "reviv") {
+			h.reply(m, "Only the DM can bring a character back from death.")
+			return
+		}
+	}
+	h.runForOwnCharacter(m, playerID, "deathsave", arg, "", "💀 ")
+}
+
+// runForOwnCharacter runs an engine command on behalf of one of the sender's
+// characters: "/<cmd> <character> <arg>", where the character is the active one
+// unless arg starts with another name the player controls; the reply is prefixed
+// with icon. It logs to the timeline, so it is serialized against an in-flight
+// /dm and persisted.
+func (h *Host) runForOwnCharacter(m *Message, playerID, cmd, arg, usage, icon string) {
+	if !h.session.State.GameStarted() {
+		h.reply(m, notStartedMsg)
+		return
+	}
+	actor := h.session.State.PlayerCharacterName(playerID)
+	if actor == "" {
+		h.reply(m, "Pick a character first with /pick <name>.")
+		return
+	}
+	for _, n := range h.session.State.PlayerCharacterNames(playerID) {
+		if strings.EqualFold(arg, n) || (len(arg) > len(n) && strings.EqualFold(arg[:len(n)], n) && arg[len(n)] == ' ') {
+			actor, arg = n, strings.TrimSpace(arg[len(n):])
+			break
+		}
+	}
+	if arg == "" && usage != "" {
+		h.reply(m, usage)
+		return
+	}
+	if h.isResolving() {
+		h.reply(m, "The DM is resolving the round — try again in a moment.")
+		return
+	}
+	res := engine.NewCommandHandler(h.session).Execute(engine.ParseCommand(strings.TrimSpace("/" + cmd + " " + actor + " " + arg)))
+	if !res.Success {
+		h.reply(m, "⚠ "+res.Message)
+		return
+	}
+	h.save()
+	h.reply(m, icon+res.Message)
+}
+
+// Undo rolls back the last DM turn or command and tells the table. It is a
+// host control — the app hosting the bot calls it; players can't — and is
+// refused while the DM is resolving a turn.
+func (h *Host) Undo() (domain.ChangeSet, error) {
+	return h.roll(h.session.State.Undo, "Undid", "↩ The host rolled back: ")
+}
+
+// Redo re-applies what Undo rolled back, like Undo.
+func (h *Host) Redo() (domain.ChangeSet, error) {
+	return h.roll(h.session.State.Redo, "Redid", "↪ The host restored: ")
+}
+
+func (h *Host) roll(fn func() (domain.ChangeSet, error), verb, note string) (domain.ChangeSet, error) {
+	h.mu.Lock()
+	if h.resolving {
+		h.mu.Unlock()
+		return domain.ChangeSet{}, fmt.Errorf("the DM is resolving a turn; try again when it's done")
+	}
+	// Hold off new turns while the state is rolled and saved.
+	h.resolving = true
+	h.mu.Unlock()
+	defer func() {
+		h.mu.Lock()
+		h.resolving = false
+		h.mu.Unlock()
+	}()
+	set, err := fn()
+	if err != nil {
+		return set, err
+	}
+	h.save()
+	h.event(engine.DescribeChangeSet(verb, set))
+	for _, a := range h.adapters {
+		if chat := h.table(a); chat != "" {
+			h.send(a, chat, note+set.Label)
+		}
+	}
+	return set, nil
+}
+
+func (h *Host) save() {
+	h.saveMu.Lock()
+	defer h.saveMu.Unlock()
+	if err := h.store.SaveSession(h.session.State); err != nil {
+		log.Printf("save: %v", err)
+	}
+}
+
+func (h *Host) event(text string) {
+	if h.onEvent != nil {
+		h.onEvent(text)
+	}
+}
+
+func (h *Host) reply(m *Message, text string) { h.send(m.adapter, m.Chat, text) }
+
+// announce posts text to the message's chat and mirrors it to the other
+// platforms' tables, so players elsewhere follow the round too.
+func (h *Host) announce(m *Message, text string) {
+	h.reply(m, text)
+	h.mirror(m, text)
+}
+
+// mirror posts text to the table of every adapter other than the message's.
+func (h *Host) mirror(m *Message, text string) {
+	for _, a := range h.adapters {
+		if a == m.adapter {
+			continue
+		}
+		if chat := h.table(a); chat != "" {
+			h.send(a, chat, text)
+		}
+	}
+}
+
+// table is the chat an adapter's table-wide posts go to: its home chat, or the
+// chat it last heard from ("" before anyone has spoken there).
+func (h *Host) table(a Adapter) string {
+	if home := a.Home(); home != "" {
+		return home
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.lastChat[a]
+}
+
+func (h *Host) send(a Adapter, chat, text string) {
+	if err := a.Send(chat, text); err != nil {
+		log.Printf("send (%s): %v", a.Platform(), err)
+	}
+}
+
+// sendZoneMap sends the current zone's map image to the chat (issue #24). Only
+// the party's current zone is exposed, to avoid revealing unexplored areas.
+func (h *Host) sendZoneMap(m *Message) {
+	// Read the location under the session mutex — a /dm turn may be writing it in
+	// a background goroutine (Adventure itself is immutable, safe to read).
+	zoneID, _ := h.session.State.Location()
+	zone := h.session.Adventure.Zone(zoneID)
+	if zone == nil {
+		h.reply(m, "The party isn't in a known zone yet.")
+		return
+	}
+	rel := h.session.Adventure.ZoneMap(zone)
+	if rel == "" {
+		h.reply(m, "There's no map for "+zone.Name+".")
+		return
+	}
+	abs, err := h.store.ResolveImagePath(h.session.Adventure.ID, rel)
+	if err != nil {
+		h.reply(m, "The map for "+zone.Name+" is unavailable.")
+		return
+	}
+	if err := m.adapter.SendImage(m.Chat, abs, "🗺 "+zone.Name); err != nil {
+		log.Printf("send map: %v", err)
+		h.reply(m, "Couldn't send the map image.")
+	}
+}
+
+// sendNPCArt sends an NPC's portrait to the chat (issue #27). Only NPCs the
+// party has already MET are shown, so unmet NPCs aren't revealed (spoilers).
+func (h *Host) sendNPCArt(m *Message, arg string) {
+	arg = strings.TrimSpace(arg)
+	if arg == "" {
+		h.reply(m, "Usage: /portrait <npc name or id>")
+		return
+	}
+	adv := h.session.Adventure
+	npc := adv.NPC(arg)
+	if npc == nil {
+		for i := range adv.NPCs {
+			if strings.EqualFold(adv.NPCs[i].Name, arg) {
+				npc = &adv.NPCs[i]
+				break
+			}
+		}
+	}
+	if npc == nil || !h.session.State.NPCKnown(npc.ID) {
+		h.reply(m, "You haven't met anyone by that name yet.")
+		return
+	}
+	imgs := adv.NPCImages(npc)
+	if len(imgs) == 0 {
+		h.reply(m, "There's no portrait for "+npc.Name+".")
+		return
+	}
+	abs, err := h.store.ResolveImagePath(adv.ID, imgs[0])
+	if err != nil {
+		h.reply(m, "The portrait for "+npc.Name+" is unavailable.")
+		return
+	}
+	if err := m.adapter.SendImage(m.Chat, abs, npc.Name); err != nil {
+		log.Printf("send portrait: %v", err)
+		h.reply(m, "Couldn't send the portrait image.")
+	}
+}
+
+// narrate posts a virtual-DM narration to the message's chat and to the other
+// platforms' tables.
+func (h *Host) narrate(m *Message, text string) {
+	h.sendNarration(m.adapter, m.Chat, text)
+	for _, a := range h.adapters {
+		if a == m.adapter {
+			continue
+		}
+		if chat := h.table(a); chat != "" {
+			h.sendNarration(a, chat, text)
+		}
+	}
+}
+
+// sendNarration sends a virtual-DM narration, hiding the trailing "suggested
+// actions" list behind a spoiler so players aren't spoiled by options they
+// haven't discovered yet (#63). The narrative is sent as plain text; the
+// actions, when present, follow as the adapter's spoiler.
+func (h *Host) sendNarration(a Adapter, chat, text string) {
+	narr, heading, actions := domain.SplitActions(text)
+	if actions == "" {
+		h.send(a, chat, text) // no actions section detected → send verbatim
+		return
+	}
+	if narr != "" {
+		h.send(a, chat, narr)
+	}
+	if err := a.SendSpoiler(chat, heading, actions); err != nil {
+		log.Printf("send spoiler (%s): %v", a.Platform(), err)
+	}
+}
+
+// helpText is the command list, with the lines for any commands the message's
+// adapter answers itself.
+func (h *Host) helpText(m *Message) string {
+	help := commandHelp
+	if p, ok := m.adapter.(interface{ Help() string }); ok {
+		help += "\n" + p.Help()
+	}
+	return help + "\n/help — this help"
+}
+
+const notStartedMsg = "The game hasn't started yet. Pick characters with /pick, then a player runs /begin and the DM sets the scene."
+
+const commandHelp = `thAImaturgy — multiplayer DM bot
+/party — list characters and who plays them
+/roster — list the persistent campaign roster
+/pick <name> — claim a character to play (repeat to control several)
+/as <name> — choose which of your characters is active
+/assign @user <name> — assign a character to a player (or reply to them with /assign <name>)
+/me [name] — show your character sheet (or one of your characters)
+/begin — start the game (the DM sets the opening scene)
+/chat [name:] <line> — say something in character (context for the DM, not an action)
+/meta <text> — ask the DM a question or note a correction (out of character)
+/do [name:] <action> — declare an action this round (name: picks which of your characters)
+/dm — let the AI Dungeon Master resolve the round and narrate (after /begin)
+/roll <dice> — roll dice (e.g. 2d6+3, 4d6kh3, 2d20kl1+5, 1d8+2d6[fire])
+/check [name] <skill|ability> [save] [DC] [adv|dis] [vs <foe>] — roll a check with your sheet's bonus (e.g. /check stealth 15 adv, /check dex save 14)
+/save — save the current session
+/status — where the party is and session progress
+/map — show the map of the current zone
+/portrait <npc> — show a met NPC's portrait
+/log [n] — show the last n timeline entries (default 15)
+/combat — show the initiative order and whose turn it is
+/rest short|long [character] — take a short or long rest
+/hp -5 | +3 | =10 — damage, heal, or set your HP
+/ac +2 | =15 — adjust or set your armor class
+/temphp +3 | =5 — adjust or set your temporary HP
+/slot <1-9> use|restore — spend or recover a spell slot
+/condition <name> — apply a condition to your character
+/uncondition <name> — remove a condition
+/gold +50 | -10 | =100 — adjust or set your gold
+/xp <n> — award your character experience
+/levelup [roll] — level up once your XP reaches the next level (average HP, or roll the hit die)
+/deathsave — roll a death saving throw while your character is dying at 0 HP
+/item add|remove <name> [xN] — edit your inventory
+/savethrow <ability> on|off — set a saving-throw proficiency
+/skill <name> prof|expert|none — set a skill proficiency
+/spell add <lvl> <name> | remove|prepare|unprepare <name> — edit your spellbook
+/setnote <text> — set your character's notes
+/quests — list tracked quests
+/recap — a quick "previously on…" recap of the session so far
+/glosario (/glossary, /who) — people you know + places you've been
+/note <text> — add a note to the timeline`
@@ -0,0 +1,174 @@
+package chathost
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/theburrowhub/thaimaturgy/internal/domain"
+	"github.com/theburrowhub/thaimaturgy/internal/engine"
+	"github.com/theburrowhub/thaimaturgy/internal/providers"
+	"github.com/theburrowhub/thaimaturgy/internal/storage"
+)
+
+// fakeAdapter records what the host posts, one string per send.
+type fakeAdapter struct {
+	name, home string
+
+	mu   sync.Mutex
+	sent []string // "chat: text", "chat: [spoiler heading] body", "chat: [image caption]"
+}
+
+func (f *fakeAdapter) Platform() string                             { return f.name }
+func (f *fakeAdapter) Home() string                                 { return f.home }
+func (f *fakeAdapter) Receive(ctx context.Context, _ func(Message)) { <-ctx.Done() }
+func (f *fakeAdapter) Stop()                                        {}
+func (f *fakeAdapter) Send(chat, text string) error                 { return f.record(chat + ": " + text) }
+func (f *fakeAdapter) SendImage(chat, _, caption string) error {
+	return f.record(chat + ": [image " + caption + "]")
+}
+func (f *fakeAdapter) SendSpoiler(chat, heading, body string) error {
+	return f.record(chat + ": [spoiler " + heading + "] " + body)
+}
+func (f *fakeAdapter) record(s string) error {
+	f.mu.Lock()
+	f.sent = append(f.sent, s)
+	f.mu.Unlock()
+	return nil
+}
+
+// take returns and clears what was posted.
+func (f *fakeAdapter) take() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	sent := f.sent
+	f.sent = nil
+	return sent
+}
+
+type narrator struct{ text string }
+
+func (n *narrator) Name() string         { return "stub" }
+func (n *narrator) SupportsTools() bool  { return false }
+func (n *narrator) SupportsVision() bool { return false }
+func (n *narrator) Chat(context.Context, providers.ChatRequest) (*providers.ChatResponse, error) {
+	return &providers.ChatResponse{Content: n.text, FinishReason: "stop"}, nil
+}
+func (n *narrator) ChatStream(ctx context.Context, req providers.ChatRequest, _ providers.StreamFunc) (*providers.ChatResponse, error) {
+	return n.Chat(ctx, req)
+}
+
+// table is a Host playing a two-character party over a Telegram and an IRC
+// fake, and a say that delivers one message to it.
+type table struct {
+	h       *Host
+	state   *domain.SessionState
+	tg, irc *fakeAdapter
+	events  []string
+}
+
+func newTable(t *testing.T) *table {
+	t.Helper()
+	store, err := storage.NewWithPath(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	adv := &domain.Adventure{ID: "crypt", Title: "The Crypt",
+		Zones: []domain.Zone{{ID: "z1", Name: "Entrance", Rooms: []domain.Room{{ID: "r1", Name: "Gate"}}}}}
+	state := domain.NewSessionState("crypt-chat", adv)
+	state.SetMode(domain.ModeVirtualDM)
+	state.SetParty([]*domain.Character{domain.NewCharacter("Kael", "Elf", "Wizard"), domain.NewCharacter("Bryn", "Dwarf", "Cleric")})
+	session := domain.NewSession(state, adv, domain.DefaultConfig())
+	oracle := engine.NewOracle(session, &narrator{text: "The gate creaks open.\n\nPossible actions:\n- Step inside"})
+	tb := &table{state: state, tg: &fakeAdapter{name: "Telegram"}, irc: &fakeAdapter{name: "IRC", home: "#table"}}
+	tb.h = New(store, session, oracle, Options{OnEvent: func(e string) { tb.events = append(tb.events, e) }}, tb.tg, tb.irc)
+	return tb
+}
+
+func (tb *table) say(a *fakeAdapter, chat string, from User, text string) {
+	m := Message{Chat: chat, From: from, adapter: a}
+	if cmd, ok := strings.CutPrefix(text, "/"); ok {
+		m.Command, m.Args, _ = strings.Cut(cmd, " ")
+	}
+	tb.h.onMessage(&m)
+	tb.h.turns.Wait()
+}
+
+// TestSharedTable plays a round with one player on each of two platforms: each
+// sees the other's actions and lines, and the DM's narration reaches both with
+// the suggested actions as a spoiler.
+func TestSharedTable(t *testing.T) {
+	tb := newTable(t)
+	h, state, tg, irc, say := tb.h, tb.state, tb.tg, tb.irc, tb.say
+	ana := User{ID: "42", Name: "Ana", Username: "ana"}
+	bob := User{ID: "irc:bob", Name: "Bob", Username: "Bob"}
+
+	say(tg, "-100", ana, "/pick Kael")
+	say(irc, "#table", bob, "/pick Bryn")
+	if got := irc.take(); len(got) != 1 || got[0] != "#table: Bob now plays Bryn. Declare actions with /do." {
+		t.Fatalf("picking on IRC replied %q", got)
+	}
+	say(tg, "-100", ana, "/begin")
+	if got := irc.take(); len(got) < 3 || got[0] != "#table: 🎬 The DM is setting the scene…" || got[2] != "#table: [spoiler Possible actions:] - Step inside" {
+		t.Fatalf("IRC table during /begin = %q", got)
+	}
+	tg.take()
+
+	say(irc, "#table", bob, "/chat Stay close.")
+	say(irc, "#table", bob, "/do I hold the torch")
+	if got := tg.take(); len(got) != 2 || got[0] != "-100: 💬 Bryn: Stay close." || got[1] != "-100: ✋ Bryn: I hold the torch" {
+		t.Errorf("Telegram table saw %q; want Bob's line and action", got)
+	}
+	if got := irc.take(); len(got) != 2 || !strings.Contains(got[1], "Waiting on: Kael (Ana)") {
+		t.Errorf("IRC replies = %q; want the line and the round status", got)
+	}
+	say(tg, "-100", ana, "/do I open the gate")
+	say(tg, "-100", ana, "/dm")
+	if got := irc.take(); len(got) != 4 || got[2] != "#table: The gate creaks open." {
+		t.Errorf("IRC table during /dm = %q", got)
+	}
+	if acts := state.RoundActions(); len(acts) != 0 {
+		t.Errorf("round still has %d action(s) after /dm", len(acts))
+	}
+	tg.take()
+
+	set, err := h.Undo()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Telegram has no home chat here, so its table is the chat last heard from.
+	for chat, a := range map[string]*fakeAdapter{"-100": tg, "#table": irc} {
+		if got := a.take(); len(got) != 1 || got[0] != chat+": ↩ The host rolled back: "+set.Label {
+			t.Errorf("%s wasn't told about the undo: %q", a.name, got)
+		}
+	}
+	if len(tb.events) == 0 {
+		t.Error("no activity reported to OnEvent")
+	}
+}
+
+// TestReservationsArePerPlatform verifies an /assign @username reservation made
+// on Telegram binds only that Telegram user: anyone can take the same nick on
+// IRC, and mustn't get the character by doing so.
+func TestReservationsArePerPlatform(t *testing.T) {
+	tb := newTable(t)
+	host := User{ID: "42", Name: "Ana", Username: "ana"}
+	tb.say(tb.tg, "-100", host, "/assign @Alice Bryn")
+	if got := tb.tg.take(); len(got) != 1 || !strings.Contains(got[0], "Bryn reserved for @Alice on Telegram") {
+		t.Fatalf("/assign replied %q", got)
+	}
+
+	tb.say(tb.irc, "#table", User{ID: "irc:alice", Name: "alice", Username: "alice"}, "hello")
+	if got := tb.irc.take(); len(got) != 0 {
+		t.Errorf("an IRC namesake was told %q", got)
+	}
+	if who := tb.state.Controllers()["Bryn"]; who != "" {
+		t.Fatalf("an IRC namesake claimed the Telegram reservation: Bryn played by %s", who)
+	}
+
+	tb.say(tb.tg, "-100", User{ID: "7", Name: "Alice", Username: "alice"}, "hello")
+	if got := tb.tg.take(); len(got) != 1 || got[0] != "-100: Alice is now playing Bryn." {
+		t.Errorf("the Telegram user's reservation bound with %q", got)
+	}
+}
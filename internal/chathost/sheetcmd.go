@@ -0,0 +1,74 @@
+package chathost
+
+import (
+	"log"
+
+	"github.com/theburrowhub/thaimaturgy/internal/engine"
+)
+
+// This file implements editing a player character's sheet from the chat (#31).
+// Every command edits ONLY the sender's claimed character (a player can never
+// touch another player's sheet — the host adjusts any sheet from the app or via
+// the DM tools). The edits themselves are engine.SheetEdit, shared with the web
+// player portal: each is applied under the session lock, recorded in the
+// timeline as a LogParty entry so the DM sees it, and persisted.
+
+// sheetReplies is how the bot confirms each edit: an icon, then the character's
+// name and the edit's description, joined by sep.
+var sheetReplies = map[string]struct{ icon, sep string }{
+	"hp": {"❤️", ": "}, "ac": {"🛡️", ": "}, "temphp": {"🛟", ": "},
+	"slot": {"🔮", ": "}, "spell": {"🔮", ": "},
+	"condition": {"🩸", " "}, "uncondition": {"🩸", " "},
+	"gold": {"💰", ": "}, "xp": {"✨", ": "}, "item": {"🎒", ": "},
+	"savethrow": {"🎯", " "}, "skill": {"📚", " "},
+}
+
+// editSheet runs a sheet-edit command (/hp, /gold, /item…) against the sender's
+// claimed character. It enforces the guards — a character must be claimed, and
+// no edit may race an in-flight /dm resolution — replying when one fails.
+func (h *Host) editSheet(m *Message, cmd, arg string) {
+	edit, err := engine.ParseSheetEdit(cmd, arg)
+	if err != nil {
+		h.reply(m, err.Error())
+		return
+	}
+	playerID := m.From.ID
+	char := h.session.State.PlayerCharacterName(playerID)
+	if char == "" {
+		h.reply(m, "Pick a character first with /pick <name>, then you can edit your sheet.")
+		return
+	}
+	// A mutation applied while the DM's turn snapshot is open could be lost when
+	// that snapshot is merged back, so serialize against it (mirrors /rest).
+	if h.isResolving() {
+		h.reply(m, "The DM is resolving the round — try again in a moment.")
+		return
+	}
+	name, desc, err := engine.EditSheet(h.session, char, edit)
+	if err != nil {
+		h.reply(m, err.Error())
+		return
+	}
+	reply := "📝 " + name + ": notes updated."
+	if r, ok := sheetReplies[edit.Kind]; ok {
+		reply = r.icon + " " + name + r.sep + desc
+	}
+	h.recordSheetChange(m, name, desc, reply)
+}
+
+// recordSheetChange persists a sheet edit (already in the timeline) and replies.
+// If persistence fails the reply says so explicitly (the mutation is already in
+// memory but was NOT written to disk), so a full/unwritable disk never produces
+// a false success.
+func (h *Host) recordSheetChange(m *Message, name, desc, reply string) {
+	h.saveMu.Lock()
+	err := h.store.SaveSession(h.session.State)
+	h.saveMu.Unlock()
+	if err != nil {
+		log.Printf("save: %v", err)
+		h.reply(m, "⚠ "+name+" "+desc+" — applied in memory but NOT saved to disk ("+err.Error()+"). It may be lost on restart.")
+		return
+	}
+	h.event(name + " " + desc)
+	h.reply(m, reply)
+}
@@ -8,6 +8,7 @@ import (
 	"strings"
 
 	"github.com/theburrowhub/thaimaturgy/internal/domain"
+	"github.com/theburrowhub/thaimaturgy/internal/srd"
 )
 
 // Markdown renders the adventure as a DM sourcebook in GitHub-flavored Markdown:
@@ -199,6 +200,7 @@ func writeRoom(b *strings.Builder, adv *domain.Adventure, r *domain.Room) {
 			fmt.Fprintf(b, "- %s\n", oneLine(line))
 			if len(e.Creatures) > 0 {
 				fmt.Fprintf(b, "  - Creatures: %s\n", strings.Join(e.Creatures, ", "))
+				writeEncounterBudget(b, adv.EncounterBudget(&e, srd.Lookup))
 			}
 			if e.Tactics != "" {
 				fmt.Fprintf(b, "  - Tactics: %s\n", oneLine(e.Tactics))
@@ -229,6 +231,49 @@ func writeRoom(b *strings.Builder, adv *domain.Adventure, r *domain.Room) {
 	}
 }
 
+// bookPartySize is the party an encounter is rated against in the book, which is
+// written before anyone sits down to play: the standard four characters.
+const bookPartySize = 4
+
+// writeEncounterBudget renders an encounter's XP budget and how hard it is for
+// four characters at each level: "deadly at level 1, hard at 2, medium at 3,
+// easy at 4–5, trivial at 6+".
+func writeEncounterBudget(b *strings.Builder, budget domain.EncounterBudget) {
+	if budget.Monsters > 0 {
+		rateAt := func(level int) string {
+			levels := make([]int, bookPartySize)
+			for i := range levels {
+				levels[i] = level
+			}
+			return budget.Rate(levels).Difficulty
+		}
+		var runs []string
+		for start := 1; start <= 20; {
+			d, end := rateAt(start), start
+			for end < 20 && rateAt(end+1) == d {
+				end++
+			}
+			switch {
+			case d == domain.DifficultyTrivial || (end == 20 && start < end):
+				runs = append(runs, fmt.Sprintf("%s at %d+", d, start))
+			case start == end:
+				runs = append(runs, fmt.Sprintf("%s at %d", d, start))
+			default:
+				runs = append(runs, fmt.Sprintf("%s at %d–%d", d, start, end))
+			}
+			if d == domain.DifficultyTrivial {
+				break
+			}
+			start = end + 1
+		}
+		runs[0] = strings.Replace(runs[0], " at ", " at level ", 1)
+		fmt.Fprintf(b, "  - XP budget: %s; for four characters: %s\n", budget.Summary(nil), strings.Join(runs, ", "))
+	}
+	if len(budget.Unresolved) > 0 {
+		fmt.Fprintf(b, "  - No stat block (not counted): %s\n", strings.Join(budget.Unresolved, ", "))
+	}
+}
+
 func writeNPC(b *strings.Builder, n *domain.NPC) {
 	title := nz(n.Name, n.ID)
 	if n.Role != "" {
@@ -42,3 +42,22 @@ func TestMarkdownRendersExpandedStatBlock(t *testing.T) {
 		}
 	}
 }
+
+func TestMarkdownRatesEncountersByLevel(t *testing.T) {
+	adv := &domain.Adventure{
+		SchemaVersion: domain.SchemaVersion, ID: "b", Title: "Book",
+		Zones: []domain.Zone{{ID: "z", Name: "Zone", Rooms: []domain.Room{{
+			ID: "r", Name: "Guardroom",
+			Encounters: []domain.Encounter{{Name: "Goblin patrol", Creatures: []string{"Goblin x4", "Mystery"}}},
+		}}}},
+	}
+	md := Markdown(adv)
+	for _, want := range []string{
+		"XP budget: 200 XP ×2 = 400 adjusted; for four characters: deadly at level 1, medium at 2, easy at 3, trivial at 4+",
+		"No stat block (not counted): Mystery",
+	} {
+		if !strings.Contains(md, want) {
+			t.Errorf("DM book missing %q:\n%s", want, md)
+		}
+	}
+}
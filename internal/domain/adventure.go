@@ -1,6 +1,7 @@
 package domain
 
 import (
+	"errors"
 	"fmt"
 	"strings"
 )
@@ -134,6 +135,8 @@ type Room struct {
 	Encounters []Encounter `json:"encounters,omitempty"`
 	Treasure   []string    `json:"treasure,omitempty"`
 	Features   []Feature   `json:"features,omitempty"`
+
+	Visibility
 }
 
 // Exit connects a room to another room or zone.
@@ -142,6 +145,8 @@ type Exit struct {
 	Direction   string `json:"direction,omitempty"`
 	Description string `json:"description,omitempty"`
 	Locked      bool   `json:"locked,omitempty"`
+
+	Visibility
 }
 
 // Direction is a canonical compass/relative direction for a zone or room exit.
@@ -248,6 +253,8 @@ type Feature struct {
 	DC          int    `json:"dc,omitempty"`
 	Success     string `json:"success,omitempty"`
 	Failure     string `json:"failure,omitempty"`
+
+	Visibility
 }
 
 // Encounter is a combat or challenge staged in a room.
@@ -282,6 +289,8 @@ type NPC struct {
 	Image           string   `json:"image,omitempty"`            // relative asset path (legacy/direct)
 	ImageIDs        []string `json:"image_ids,omitempty"`        // references into Adventure.Images
 	DefaultLocation string   `json:"default_location,omitempty"` // room ID
+
+	Visibility
 }
 
 // StatBlock holds the mechanical combat statistics of an NPC or creature. It is a
@@ -341,6 +350,8 @@ type Event struct {
 	DMNotes      string    `json:"dm_notes,omitempty"`
 	Consequences string    `json:"consequences,omitempty"`
 	Outcomes     []Outcome `json:"outcomes,omitempty"`
+
+	Visibility
 }
 
 // Outcome is one branch of an Event.
@@ -358,6 +369,8 @@ type Item struct {
 	Mechanics   string   `json:"mechanics,omitempty"`
 	Image       string   `json:"image,omitempty"`     // relative asset path (legacy/direct)
 	ImageIDs    []string `json:"image_ids,omitempty"` // references into Adventure.Images
+
+	Visibility
 }
 
 // Table is a lookup or random table from the adventure — random encounters,
@@ -659,15 +672,48 @@ func (a *Adventure) ImageRefs() []string {
 
 // --- Validation ----------------------------------------------------------
 
+// ValidationWarning is a problem ValidateAdventure reports that doesn't make the
+// module unusable — e.g. an encounter creature with no stat block, which only
+// leaves its XP budget incomplete. An import doesn't fail on warnings.
+type ValidationWarning struct {
+	Msg string
+}
+
+func (w ValidationWarning) Error() string { return "warning: " + w.Msg }
+
+// IsValidationWarning reports whether a validation problem is only a warning.
+func IsValidationWarning(err error) bool {
+	var w ValidationWarning
+	return errors.As(err, &w)
+}
+
+// ValidationErrors drops the warnings from a ValidateAdventure result, leaving
+// the problems that make the module invalid.
+func ValidationErrors(errs []error) []error {
+	var out []error
+	for _, err := range errs {
+		if !IsValidationWarning(err) {
+			out = append(out, err)
+		}
+	}
+	return out
+}
+
 // ValidateAdventure checks required fields and referential integrity. It
 // returns a list of human-readable problems; an empty slice means the module
-// is structurally valid. imageExists, if non-nil, is called for each referenced
-// relative asset path to confirm the file is present on disk.
-func ValidateAdventure(a *Adventure, imageExists func(relPath string) bool) []error {
+// is structurally valid. Problems that don't invalidate the module are returned
+// as ValidationWarning (see ValidationErrors). imageExists, if non-nil, is called
+// for each referenced relative asset path to confirm the file is present on disk.
+// stock resolves encounter creatures that aren't authored NPCs (srd.Lookup); with
+// nil, unresolved creatures aren't checked, as nothing could resolve them.
+func ValidateAdventure(a *Adventure, imageExists func(relPath string) bool, stock CreatureLookup) []error {
 	var errs []error
 	add := func(format string, args ...any) {
 		errs = append(errs, fmt.Errorf(format, args...))
 	}
+	warn := func(format string, args ...any) {
+		errs = append(errs, ValidationWarning{Msg: fmt.Sprintf(format, args...)})
+	}
 
 	if a == nil {
 		return []error{fmt.Errorf("adventure is nil")}
@@ -813,6 +859,17 @@ func ValidateAdventure(a *Adventure, imageExists func(relPath string) bool) []er
 				if ex.To != "" && !roomIDs[ex.To] && !zoneIDs[ex.To] {
 					add("room %q: exit references unknown room/zone %q", r.ID, ex.To)
 				}
+				validateVisibility(fmt.Sprintf("room %q: exit to %q", r.ID, ex.To), ex.Visibility, eventIDs, roomIDs, add, warn)
+			}
+			validateVisibility(fmt.Sprintf("room %q", r.ID), r.Visibility, eventIDs, roomIDs, add, warn)
+			for _, f := range r.Features {
+				validateVisibility(fmt.Sprintf("room %q: feature %q", r.ID, f.Name), f.Visibility, eventIDs, roomIDs, add, warn)
+			}
+			for i := 0; stock != nil && i < len(r.Encounters); i++ {
+				e := &r.Encounters[i]
+				for _, name := range a.EncounterBudget(e, stock).Unresolved {
+					warn("room %q: encounter %q: creature %q resolves to no stat block (no NPC or SRD creature by that name), so its XP isn't counted", r.ID, e.Name, name)
+				}
 			}
 		}
 	}
@@ -846,9 +903,14 @@ func ValidateAdventure(a *Adventure, imageExists func(relPath string) bool) []er
 			add("npc %q: default_location references unknown room %q", n.ID, n.DefaultLocation)
 		}
 		checkImageIDs("npc "+n.ID, n.ImageIDs)
+		validateVisibility(fmt.Sprintf("npc %q", n.ID), n.Visibility, eventIDs, roomIDs, add, warn)
+	}
+	for _, e := range a.Events {
+		validateVisibility(fmt.Sprintf("event %q", e.ID), e.Visibility, eventIDs, roomIDs, add, warn)
 	}
 	for _, it := range a.Items {
 		checkImageIDs("item "+it.ID, it.ImageIDs)
+		validateVisibility(fmt.Sprintf("item %q", it.ID), it.Visibility, eventIDs, roomIDs, add, warn)
 	}
 
 	// Image presence.
@@ -30,14 +30,14 @@ func validAdv() *Adventure {
 }
 
 func TestValidateAdventureOK(t *testing.T) {
-	if errs := ValidateAdventure(validAdv(), nil); len(errs) != 0 {
+	if errs := ValidateAdventure(validAdv(), nil, nil); len(errs) != 0 {
 		t.Errorf("expected valid adventure, got %v", errs)
 	}
 }
 
 func TestValidateAdventureRequiredFields(t *testing.T) {
 	a := &Adventure{}
-	errs := ValidateAdventure(a, nil)
+	errs := ValidateAdventure(a, nil, nil)
 	if len(errs) == 0 {
 		t.Fatal("expected errors for empty adventure")
 	}
@@ -48,7 +48,7 @@ func TestValidateAdventureBadRefs(t *testing.T) {
 	a.Zones[0].Rooms[0].NPCIDs = []string{"ghost"}
 	a.Zones[0].Rooms[0].EventIDs = []string{"nope"}
 	a.Zones[0].Rooms[0].Exits = []Exit{{To: "void"}}
-	errs := ValidateAdventure(a, nil)
+	errs := ValidateAdventure(a, nil, nil)
 	if len(errs) < 3 {
 		t.Errorf("expected at least 3 referential errors, got %d: %v", len(errs), errs)
 	}
@@ -58,10 +58,10 @@ func TestValidateAdventureImagePresence(t *testing.T) {
 	a := validAdv()
 	a.Zones[0].MapImage = "assets/map.png"
 	present := map[string]bool{"assets/map.png": true}
-	if errs := ValidateAdventure(a, func(p string) bool { return present[p] }); len(errs) != 0 {
+	if errs := ValidateAdventure(a, func(p string) bool { return present[p] }, nil); len(errs) != 0 {
 		t.Errorf("expected no errors, got %v", errs)
 	}
-	if errs := ValidateAdventure(a, func(p string) bool { return false }); len(errs) == 0 {
+	if errs := ValidateAdventure(a, func(p string) bool { return false }, nil); len(errs) == 0 {
 		t.Error("expected an error for missing image")
 	}
 }
@@ -89,7 +89,7 @@ func TestImageIDReferencesValidation(t *testing.T) {
 	a.Zones[0].Rooms[0].ImageIDs = []string{"art1", "ghost-img"} // ghost-img is dangling
 	a.NPCs[0].ImageIDs = []string{"art1"}
 
-	errs := ValidateAdventure(a, nil)
+	errs := ValidateAdventure(a, nil, nil)
 	if len(errs) != 1 {
 		t.Fatalf("expected exactly 1 error (dangling image ref), got %d: %v", len(errs), errs)
 	}
@@ -128,6 +128,21 @@ var DefaultSkills = []Skill{
 	{Name: "Survival", Ability: WIS},
 }
 
+// ParseSkill resolves a standard 5e skill name case-insensitively, ignoring
+// spaces and hyphens ("sleight-of-hand"), reporting whether it matched.
+func ParseSkill(name string) (Skill, bool) {
+	norm := func(s string) string {
+		return strings.NewReplacer(" ", "", "-", "", "_", "").Replace(strings.ToLower(strings.TrimSpace(s)))
+	}
+	want := norm(name)
+	for _, sk := range DefaultSkills {
+		if norm(sk.Name) == want {
+			return sk, true
+		}
+	}
+	return Skill{}, false
+}
+
 type InventoryItem struct {
 	Name     string  `json:"name"`
 	Quantity int     `json:"quantity"`
@@ -240,6 +255,13 @@ type Character struct {
 	CurrentHP int `json:"current_hp"`
 	TempHP    int `json:"temp_hp,omitempty"`
 
+	// Dying state at 0 HP (see dying.go): the death-save tally, whether the
+	// character has been stabilized, and whether it has died.
+	DeathSuccesses int  `json:"death_successes,omitempty"`
+	DeathFailures  int  `json:"death_failures,omitempty"`
+	Stable         bool `json:"stable,omitempty"`
+	Dead           bool `json:"dead,omitempty"`
+
 	// HitDiceUsed counts hit dice already spent (out of one per level). Recovered
 	// on rests; used by ShortRest to bound healing.
 	HitDiceUsed int `json:"hit_dice_used,omitempty"`
@@ -554,22 +576,15 @@ func (c *Character) HasCondition(cond Condition) bool {
 	return false
 }
 
-func (c *Character) TakeDamage(damage int) {
-	if c.TempHP > 0 {
-		if damage <= c.TempHP {
-			c.TempHP -= damage
-			return
-		}
-		damage -= c.TempHP
-		c.TempHP = 0
+// Heal restores HP up to the maximum. Healing a character at 0 HP brings it
+// back to consciousness; a dead character can't be healed (see Revive).
+func (c *Character) Heal(amount int) {
+	if c.Dead || amount <= 0 {
+		return
 	}
-	c.CurrentHP -= damage
-	if c.CurrentHP < 0 {
-		c.CurrentHP = 0
+	if c.CurrentHP <= 0 {
+		c.regainConsciousness()
 	}
-}
-
-func (c *Character) Heal(amount int) {
 	c.CurrentHP += amount
 	if c.CurrentHP > c.MaxHP {
 		c.CurrentHP = c.MaxHP
@@ -594,7 +609,14 @@ func (c *Character) HitDiceRemaining() int {
 
 // LongRest restores the character after a long rest: HP to max, temp HP cleared,
 // up to half the total hit dice recovered, and all spell slots restored (D&D 5e).
+// A dead character doesn't rest.
 func (c *Character) LongRest() {
+	if c.Dead {
+		return
+	}
+	if c.CurrentHP <= 0 {
+		c.regainConsciousness()
+	}
 	c.CurrentHP = c.MaxHP
 	c.TempHP = 0
 	recover := c.HitDiceMax() / 2
@@ -634,15 +656,29 @@ func (c *Character) ShortRest(dice int) (healed, spent int) {
 }
 
 // SetHP sets current HP directly, clamping to the valid [0, MaxHP] range so an
-// explicit set can never persist invalid domain state.
-func (c *Character) SetHP(hp int) {
+// explicit set can never persist invalid domain state. A positive value brings a
+// dying or stable character back to consciousness, and setting a conscious
+// character to 0 starts the dying state. A dead character stays dead at 0 HP:
+// only Revive brings them back. It reports whether the set was applied.
+func (c *Character) SetHP(hp int) bool {
+	if c.Dead {
+		c.CurrentHP = 0
+		return false
+	}
 	if hp < 0 {
 		hp = 0
 	}
 	if hp > c.MaxHP {
 		hp = c.MaxHP
 	}
+	switch {
+	case hp > 0 && c.CurrentHP <= 0:
+		c.regainConsciousness()
+	case hp == 0 && c.CurrentHP > 0:
+		c.fallUnconscious()
+	}
 	c.CurrentHP = hp
+	return true
 }
 
 // SetGold sets gold directly, clamping negatives to zero.
@@ -662,10 +698,6 @@ func (c *Character) AwardXP(amount int) {
 	c.XP += amount
 }
 
-func (c *Character) IsAlive() bool {
-	return c.CurrentHP > 0
-}
-
 func (c *Character) Summary() string {
 	return fmt.Sprintf("%s - Level %d %s %s | HP: %d/%d | AC: %d",
 		c.Name, c.Level, c.Race, c.Class, c.CurrentHP, c.MaxHP, c.AC)
@@ -80,6 +80,18 @@ func TestParseAbility(t *testing.T) {
 	}
 }
 
+func TestParseSkill(t *testing.T) {
+	for in, want := range map[string]string{
+		"stealth": "Stealth", "Sleight of Hand": "Sleight of Hand", "sleight-of-hand": "Sleight of Hand",
+		" ANIMAL handling ": "Animal Handling", "bogus": "", "": "",
+	} {
+		got, ok := ParseSkill(in)
+		if ok != (want != "") || got.Name != want {
+			t.Errorf("ParseSkill(%q) = (%q,%v); want %q", in, got.Name, ok, want)
+		}
+	}
+}
+
 func TestSetSkillProficiency(t *testing.T) {
 	c := NewCharacter("Rogue", "Human", "Rogue")
 	c.ProficiencyBonus = 2
@@ -3,6 +3,8 @@ package domain
 import (
 	"fmt"
 	"strings"
+
+	"github.com/theburrowhub/thaimaturgy/internal/spells"
 )
 
 // This file generates D&D-style player characters from a race, class and level,
@@ -127,7 +129,7 @@ func warlockPact(level int) (count, slotLevel int) {
 
 // buildSpellcasting constructs the spellcasting block for a caster class at a
 // level, populating slots and the derived save DC / attack bonus. Returns nil for
-// non-casters. The spellbook (known spells) is left empty for the user/AI to fill.
+// non-casters. The spellbook (known spells) is left empty; see startingSpells.
 func buildSpellcasting(ci classInfo, level, profBonus int, ab AbilityScores) *Spellcasting {
 	if ci.caster == casterNone {
 		return nil
@@ -162,6 +164,41 @@ func buildSpellcasting(ci classInfo, level, profBonus int, ab AbilityScores) *Sp
 	}
 }
 
+// cantripsKnown is how many cantrips a caster class knows at 1st level.
+var cantripsKnown = map[string]int{"bard": 2, "cleric": 3, "druid": 2, "sorcerer": 4, "warlock": 2, "wizard": 3}
+
+// startingSpellsPerLevel is how many leveled spells chargen gives a caster for
+// each spell level it has slots for.
+const startingSpellsPerLevel = 3
+
+// startingSpells fills an empty spellbook from the SRD spell catalog: the class's
+// cantrips and the first few catalog spells of every level the character has
+// slots for (the pact slot level for a warlock), all prepared. A half caster
+// below level 2 gets none.
+func startingSpells(class string, sc *Spellcasting) {
+	if sc == nil || len(sc.Spells) > 0 {
+		return
+	}
+	maxLevel := 0
+	for i, n := range sc.Slots.Max {
+		if n > 0 {
+			maxLevel = i + 1
+		}
+	}
+	taken := map[int]int{}
+	for _, s := range spells.ForClass(class, maxLevel) {
+		limit := startingSpellsPerLevel
+		if s.Level == 0 {
+			limit = cantripsKnown[strings.ToLower(class)]
+		}
+		if taken[s.Level] >= limit {
+			continue
+		}
+		taken[s.Level]++
+		sc.Spells = append(sc.Spells, Spell{Name: s.Name, Level: s.Level, Prepared: true, School: s.School})
+	}
+}
+
 // NormalizeRace maps free-form input to a supported race name (default Human).
 func NormalizeRace(s string) string {
 	key := strings.ToLower(strings.TrimSpace(s))
@@ -292,6 +329,7 @@ func buildCharacter(name, race, class string, level int, base *AbilityScores) *C
 		c.Languages = append([]string(nil), ri.languages...)
 	}
 	c.Spellcasting = buildSpellcasting(ci, level, c.ProficiencyBonus, c.Abilities)
+	startingSpells(c.Class, c.Spellcasting)
 
 	if c.Name == "" {
 		c.Name = sampleName(ri, className)
@@ -89,3 +89,30 @@ func TestDefaultParty(t *testing.T) {
 		t.Errorf("party should be heterogeneous: %d races, %d classes", len(races), len(classes))
 	}
 }
+
+func TestGenerateCharacterStartingSpells(t *testing.T) {
+	count := func(c *Character, level int) int {
+		n := 0
+		for _, s := range c.Spellcasting.Spells {
+			if s.Level == level {
+				n++
+			}
+		}
+		return n
+	}
+	wiz := GenerateCharacter("Mage", "Elf", "Wizard", 3)
+	if count(wiz, 0) != 3 || count(wiz, 1) != 3 || count(wiz, 2) != 3 || count(wiz, 3) != 0 {
+		t.Errorf("level 3 wizard spellbook = %+v", wiz.Spellcasting.Spells)
+	}
+	if s := wiz.Spellcasting.Spells[0]; s.Name != "Fire Bolt" || !s.Prepared || s.School == "" {
+		t.Errorf("first wizard spell = %+v", s)
+	}
+	// A warlock's list follows its pact slot level.
+	if lock := GenerateCharacter("Hex", "Tiefling", "Warlock", 5); count(lock, 3) == 0 || count(lock, 0) != 2 {
+		t.Errorf("level 5 warlock spellbook = %+v", lock.Spellcasting.Spells)
+	}
+	// A 1st-level paladin has no slots and so no spells yet.
+	if p := GenerateCharacter("Sora", "Human", "Paladin", 1); len(p.Spellcasting.Spells) != 0 {
+		t.Errorf("level 1 paladin spells = %+v", p.Spellcasting.Spells)
+	}
+}
@@ -0,0 +1,306 @@
+package domain
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+)
+
+// CombatSide is which side of a fight a combatant is on.
+type CombatSide string
+
+const (
+	SideParty CombatSide = "party" // a player character (linked to a party sheet)
+	SideFoe   CombatSide = "foe"   // a hostile creature
+	SideAlly  CombatSide = "ally"  // a friendly NPC fighting with the party
+)
+
+// ParseCombatSide maps free text to a side, defaulting to SideFoe (the common
+// case for anything the DM adds to a fight that isn't a party member).
+func ParseCombatSide(s string) CombatSide {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "party", "pc", "player":
+		return SideParty
+	case "ally", "friend", "friendly":
+		return SideAlly
+	}
+	return SideFoe
+}
+
+// maxCombatantNameLen bounds a combatant's display name. Names may come from the
+// model (start_combat), so they are sanitized to a single short line before they
+// are stored and rendered into the always-on COMBAT grounding.
+const maxCombatantNameLen = 60
+
+// Combatant is one participant in a tracked fight. Party members link to their
+// sheet by name (Character) and read HP/AC/conditions from it; everyone else
+// carries their own HP/AC, seeded from an authored or SRD stat block.
+type Combatant struct {
+	Name       string     `json:"name"`
+	Side       CombatSide `json:"side"`
+	Initiative int        `json:"initiative"`
+	// InitBonus is the DEX-derived initiative modifier, kept to break ties
+	// (higher bonus acts first).
+	InitBonus int `json:"init_bonus,omitempty"`
+
+	CurrentHP  int         `json:"current_hp,omitempty"`
+	MaxHP      int         `json:"max_hp,omitempty"`
+	AC         int         `json:"ac,omitempty"`
+	Conditions []Condition `json:"conditions,omitempty"`
+
+	// Character is the party member's name for SideParty combatants; NPCID links
+	// an authored NPC and Creature names the SRD creature whose stat block was
+	// used; Instance names the spawned creature instance whose HP it shares. All
+	// are empty for an ad-hoc creature.
+	Character string `json:"character,omitempty"`
+	NPCID     string `json:"npc_id,omitempty"`
+	Creature  string `json:"creature,omitempty"`
+	Instance  string `json:"instance,omitempty"`
+
+	Defeated bool `json:"defeated,omitempty"`
+}
+
+// CombatState is the tracked structure of an active fight: the initiative order
+// (Combatants, sorted highest first), the round counter and whose turn it is.
+// It lives on SessionState only while a fight is running.
+type CombatState struct {
+	Round      int         `json:"round"`
+	Turn       int         `json:"turn"` // index into Combatants
+	Combatants []Combatant `json:"combatants"`
+	StartedAt  time.Time   `json:"started_at"`
+}
+
+// Current returns the combatant whose turn it is, or nil for an empty fight.
+func (c *CombatState) Current() *Combatant {
+	if c == nil || c.Turn < 0 || c.Turn >= len(c.Combatants) {
+		return nil
+	}
+	return &c.Combatants[c.Turn]
+}
+
+// Find returns the combatant with the given name (case-insensitive), or nil.
+func (c *CombatState) Find(name string) *Combatant {
+	if c == nil {
+		return nil
+	}
+	name = strings.TrimSpace(name)
+	for i := range c.Combatants {
+		if strings.EqualFold(c.Combatants[i].Name, name) {
+			return &c.Combatants[i]
+		}
+	}
+	return nil
+}
+
+// clone returns a deep copy, so a snapshot never aliases the live state.
+func (c *CombatState) clone() *CombatState {
+	if c == nil {
+		return nil
+	}
+	cp := *c
+	cp.Combatants = make([]Combatant, len(c.Combatants))
+	for i, cb := range c.Combatants {
+		cb.Conditions = slices.Clone(cb.Conditions)
+		cp.Combatants[i] = cb
+	}
+	return &cp
+}
+
+// InitiativeOrder returns a one-line "Name (init), ..." summary of the order.
+func (c *CombatState) InitiativeOrder() string {
+	if c == nil {
+		return ""
+	}
+	parts := make([]string, 0, len(c.Combatants))
+	for _, cb := range c.Combatants {
+		parts = append(parts, fmt.Sprintf("%s (%d)", cb.Name, cb.Initiative))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// sortInitiative orders combatants highest initiative first; ties go to the
+// higher bonus, then to the party (players win ties against monsters), then
+// keep their given order.
+func sortInitiative(cs []Combatant) {
+	sort.SliceStable(cs, func(i, j int) bool {
+		a, b := cs[i], cs[j]
+		if a.Initiative != b.Initiative {
+			return a.Initiative > b.Initiative
+		}
+		if a.InitBonus != b.InitBonus {
+			return a.InitBonus > b.InitBonus
+		}
+		return a.Side == SideParty && b.Side != SideParty
+	})
+}
+
+// uniqueCombatantNames sanitizes every name and numbers duplicates ("Goblin",
+// "Goblin #2", ...) so each combatant can be addressed unambiguously.
+func uniqueCombatantNames(cs []Combatant) {
+	seen := make(map[string]int, len(cs))
+	for i := range cs {
+		name := sanitizeWorldChange(cs[i].Name)
+		if r := []rune(name); len(r) > maxCombatantNameLen {
+			name = strings.TrimSpace(string(r[:maxCombatantNameLen]))
+		}
+		if name == "" {
+			name = "Combatant"
+		}
+		key := strings.ToLower(name)
+		seen[key]++
+		if n := seen[key]; n > 1 {
+			name = fmt.Sprintf("%s #%d", name, n)
+		}
+		cs[i].Name = name
+	}
+}
+
+// StartCombat begins a tracked fight with the given combatants, whose initiative
+// has already been rolled (dice live in the engine). It sorts them into
+// initiative order, starts round 1 on the first combatant, replaces any fight
+// already in progress, logs the order and returns a snapshot.
+func (s *SessionState) StartCombat(combatants []Combatant) *CombatState {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	cs := make([]Combatant, len(combatants))
+	copy(cs, combatants)
+	uniqueCombatantNames(cs)
+	sortInitiative(cs)
+	s.Combat = &CombatState{Round: 1, Combatants: cs, StartedAt: time.Now()}
+	msg := "Combat begins. Initiative: " + s.Combat.InitiativeOrder()
+	s.record(LogEntry{Type: LogCombat, Message: msg,
+		Data: map[string]any{"round": 1}})
+	s.touch()
+	s.publish(TopicRound)
+	return s.Combat.clone()
+}
+
+// InCombat reports whether a fight is being tracked.
+func (s *SessionState) InCombat() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.Combat != nil
+}
+
+// CombatSnapshot returns a deep copy of the active fight, or nil when there is
+// none, so a reader (prompt builder, UI) never races a concurrent mutation.
+func (s *SessionState) CombatSnapshot() *CombatState {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.Combat.clone()
+}
+
+// SetCombatantDefeated marks a combatant defeated (or back in the fight) and
+// reports whether one by that name exists. Defeated combatants keep their place
+// in the order but are skipped by EndTurn.
+func (s *SessionState) SetCombatantDefeated(name string, defeated bool) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	cb := s.Combat.Find(name)
+	if cb == nil {
+		return false
+	}
+	if cb.Defeated != defeated {
+		cb.Defeated = defeated
+		msg := cb.Name + " is defeated"
+		if !defeated {
+			msg = cb.Name + " rejoins the fight"
+		}
+		s.syncCombatantInstance(cb)
+		s.record(LogEntry{Type: LogCombat, Message: msg})
+		s.touch()
+		s.publish(TopicRound)
+	}
+	return true
+}
+
+// AdjustCombatantHP changes a (non-party) combatant's tracked HP by delta —
+// negative for damage, positive for healing — clamped to 0..MaxHP. Dropping to 0
+// marks it defeated and healing above 0 brings it back. It returns the updated
+// combatant and false when no combatant by that name exists. Party members'
+// HP lives on their sheets, not here.
+func (s *SessionState) AdjustCombatantHP(name string, delta int) (Combatant, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	cb := s.Combat.Find(name)
+	if cb == nil {
+		return Combatant{}, false
+	}
+	cb.CurrentHP = max(0, cb.CurrentHP+delta)
+	if cb.MaxHP > 0 {
+		cb.CurrentHP = min(cb.CurrentHP, cb.MaxHP)
+	}
+	msg := fmt.Sprintf("%s HP: %d/%d", cb.Name, cb.CurrentHP, cb.MaxHP)
+	switch {
+	case cb.CurrentHP == 0 && !cb.Defeated:
+		cb.Defeated = true
+		msg += " — defeated"
+	case cb.CurrentHP > 0 && cb.Defeated:
+		cb.Defeated = false
+		msg += " — back in the fight"
+	}
+	s.syncCombatantInstance(cb)
+	s.record(LogEntry{Type: LogCombat, Message: msg,
+		Data: map[string]any{"combatant": cb.Name, "delta": delta, "hp": cb.CurrentHP}})
+	s.touch()
+	s.publish(TopicRound)
+	out := *cb
+	out.Conditions = slices.Clone(cb.Conditions)
+	return out, true
+}
+
+// EndTurn ends the current combatant's turn and advances to the next one still
+// in the fight, wrapping to the top of the order (and incrementing the round)
+// past the last. It returns the combatant now acting and false when no fight is
+// active. If every combatant is defeated the turn stays where it is.
+func (s *SessionState) EndTurn() (Combatant, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	c := s.Combat
+	if c == nil || len(c.Combatants) == 0 {
+		return Combatant{}, false
+	}
+	n := len(c.Combatants)
+	for step := 1; step <= n; step++ {
+		next := c.Turn + step
+		if next >= n {
+			next -= n
+		}
+		if c.Combatants[next].Defeated {
+			continue
+		}
+		if next <= c.Turn {
+			c.Round++
+		}
+		c.Turn = next
+		break
+	}
+	cur := *c.Current()
+	cur.Conditions = slices.Clone(cur.Conditions)
+	s.record(LogEntry{Type: LogCombat,
+		Message: fmt.Sprintf("Round %d — %s's turn", c.Round, cur.Name),
+		Data:    map[string]any{"round": c.Round, "turn": cur.Name}})
+	s.touch()
+	s.publish(TopicRound)
+	return cur, true
+}
+
+// EndCombat clears the tracked fight, logs it and returns the final state (nil
+// when no fight was active).
+func (s *SessionState) EndCombat() *CombatState {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	c := s.Combat
+	if c == nil {
+		return nil
+	}
+	s.Combat = nil
+	s.record(LogEntry{Type: LogCombat,
+		Message: fmt.Sprintf("Combat ends after %d round(s)", c.Round),
+		Data:    map[string]any{"rounds": c.Round}})
+	s.touch()
+	s.publish(TopicRound)
+	return c
+}
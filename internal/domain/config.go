@@ -13,6 +13,18 @@ const (
 	// user's machine (the sanctioned client), rather than calling the API directly.
 	// Authentication is handled by the CLI's own login; no key/token is stored here.
 	ProviderClaudeCLI ProviderType = "claude-cli"
+	// ProviderLocal talks to a self-hosted server with an OpenAI-compatible API
+	// (llama.cpp, Ollama, vLLM, LM Studio) at LocalBaseURL, so play works offline.
+	ProviderLocal ProviderType = "local"
+)
+
+// Tool-calling modes for the local provider. Many local models or servers
+// can't do native tool calls; "prompt" describes the tools in the system
+// prompt and reads the calls back from JSON in the reply instead.
+const (
+	LocalToolsAuto   = "auto"   // native tool calls, falling back to the prompt protocol if the server rejects them
+	LocalToolsNative = "native" // native tool calls only
+	LocalToolsPrompt = "prompt" // always use the prompt protocol
 )
 
 const (
@@ -45,7 +57,7 @@ type TTSConfig struct {
 type SpoilerGuardConfig struct {
 	Enabled bool `json:"enabled"`
 	// Provider overrides the engine used for the review pass (openai | anthropic |
-	// gemini | claude-cli), reusing the same stored credentials. Empty → the active
+	// gemini | claude-cli | local), reusing the same stored credentials. Empty → the active
 	// oracle provider.
 	Provider ProviderType `json:"provider,omitempty"`
 	// Model overrides the model used for the review pass (may be a cheaper/faster
@@ -54,6 +66,28 @@ type SpoilerGuardConfig struct {
 	Model string `json:"model,omitempty"`
 }
 
+// Cassette modes: record every model call to a file, or replay the calls
+// from one instead of asking a real provider.
+const (
+	CassetteRecord = "record"
+	CassetteReplay = "replay"
+)
+
+// CassetteConfig records model calls to a cassette file, or replays them from
+// one, so a session can be reproduced or an adventure regression-tested
+// without an API key. An empty Mode talks to the provider as usual.
+type CassetteConfig struct {
+	Mode string `json:"mode,omitempty"` // "" | record | replay
+	Path string `json:"path,omitempty"`
+}
+
+// ProviderChoice names a backend of the provider fallback chain: a provider,
+// reusing its stored credentials, and the model to ask it for.
+type ProviderChoice struct {
+	Provider ProviderType `json:"provider"`
+	Model    string       `json:"model,omitempty"` // empty → the provider's default model
+}
+
 type Config struct {
 	Provider    ProviderType `json:"provider"`
 	Model       string       `json:"model"`
@@ -66,10 +100,28 @@ type Config struct {
 	RunModel  string `json:"run_model,omitempty"`
 	EditModel string `json:"edit_model,omitempty"`
 
+	// Fallbacks are tried in order when the provider fails with a transient
+	// error (outage, overload, rate limit, timeout). FallbackTimeoutSeconds is how
+	// long a backend may take to start answering before the next one is tried
+	// (0 = 45 seconds); the last backend runs under the caller's
+	// deadline only.
+	Fallbacks              []ProviderChoice `json:"fallbacks,omitempty"`
+	FallbackTimeoutSeconds int              `json:"fallback_timeout_seconds,omitempty"`
+
+	// Cassette records or replays every model call (see CassetteConfig).
+	Cassette CassetteConfig `json:"cassette"`
+
 	OpenAIAPIKey    string `json:"openai_api_key,omitempty"`
 	AnthropicAPIKey string `json:"anthropic_api_key,omitempty"`
 	GeminiAPIKey    string `json:"gemini_api_key,omitempty"`
 
+	// Local OpenAI-compatible server: its base URL (e.g.
+	// "http://localhost:11434/v1"), an API key if it wants one, and the
+	// tool-calling mode (LocalToolsAuto when empty).
+	LocalBaseURL  string `json:"local_base_url,omitempty"`
+	LocalAPIKey   string `json:"local_api_key,omitempty"`
+	LocalToolMode string `json:"local_tool_mode,omitempty"`
+
 	// OAuth tokens reused from local logins (Claude Code, Gemini CLI). Never
 	// persisted to disk.
 	AnthropicOAuthToken string `json:"-"`
@@ -112,6 +164,11 @@ type Config struct {
 	// SpoilerGuard reviews virtual-DM narration for leaks before players see it.
 	SpoilerGuard SpoilerGuardConfig `json:"spoiler_guard"`
 
+	// Pricing estimates what model usage costs, by model name or name prefix
+	// (see PriceFor). SessionBudget caps each session's tokens.
+	Pricing       map[string]ModelPrice `json:"pricing,omitempty"`
+	SessionBudget TokenBudget           `json:"session_budget"`
+
 	// Telegram multiplayer bot. The token lets the app launch the bot to host the
 	// current virtual-DM game; ChatID (optional) restricts it to one chat.
 	TelegramToken  string `json:"telegram_token,omitempty"`
@@ -156,6 +213,8 @@ func DefaultConfig() *Config {
 		},
 
 		SpoilerGuard: SpoilerGuardConfig{Enabled: false},
+
+		Pricing: DefaultPricing(),
 	}
 }
 
@@ -167,6 +226,8 @@ func (c *Config) GetActiveAPIKey() string {
 		return c.AnthropicAPIKey
 	case ProviderGemini:
 		return c.GeminiAPIKey
+	case ProviderLocal:
+		return c.LocalAPIKey
 	}
 	return ""
 }
@@ -183,11 +244,15 @@ func (c *Config) IsConfigured() bool {
 		return c.GeminiAPIKey != "" || c.GeminiOAuthToken != ""
 	case ProviderClaudeCLI:
 		return true // the CLI carries its own login; binary presence is checked when building the provider
+	case ProviderLocal:
+		return c.LocalBaseURL != ""
 	}
 	return false
 }
 
-// DefaultModel returns a sensible default model id for a provider.
+// DefaultModel returns a sensible default model id for a provider. A local
+// server has none: its models are discovered, and an empty model means the
+// first one it lists.
 func DefaultModel(p ProviderType) string {
 	switch p {
 	case ProviderOpenAI:
@@ -262,7 +327,7 @@ CORE PRINCIPLES:
 3. TRACK THE TABLE. When the DM tells you what the players did, record it with the session tools (set_location, mark_npc_met, trigger_event, set_flag, log_note, advance_quest, update_party_member).
 4. LABEL IMPROVISATION. If the module doesn't cover something and you must improvise, clearly mark it as a SUGGESTION consistent with the tone — never present invention as canon.
 5. RESPECT PLAYER AGENCY. Offer options and consequences; never dictate what the player characters do.
-6. ROLL WITH THE DICE TOOLS. When an outcome is uncertain and needs a roll — an attack, an ability/skill check, a saving throw, damage, or a random table — CALL roll_dice or ability_check (a saving throw is an ability_check against a DC) and report the tool's real result and the DC. Never invent or narrate a die result yourself.
+6. ROLL WITH THE DICE TOOLS. When an outcome is uncertain and needs a roll — an attack, an ability/skill check, a saving throw, damage, or a random table — CALL roll_dice or ability_check (name the character and the skill or ability — the tool reads the bonus from the sheet; save=true for a saving throw) and report the tool's real result and the DC. Never invent or narrate a die result yourself.
 
 WHAT THE DM WANTS FROM YOU:
 - What should happen here / what the module intends.
@@ -285,7 +350,7 @@ PRINCIPIOS FUNDAMENTALES:
 3. REGISTRA LA MESA. Cuando el DM te cuente lo que hicieron los jugadores, regístralo con las herramientas de sesión (set_location, mark_npc_met, trigger_event, set_flag, log_note, advance_quest, update_party_member).
 4. ETIQUETA LA IMPROVISACIÓN. Si el módulo no cubre algo y debes improvisar, márcalo claramente como SUGERENCIA coherente con el tono; nunca presentes lo inventado como canon.
 5. RESPETA LA AGENCIA DEL JUGADOR. Ofrece opciones y consecuencias; nunca dictes lo que hacen los personajes jugadores.
-6. TIRA CON LAS HERRAMIENTAS DE DADOS. Cuando un resultado sea incierto y requiera tirada —un ataque, una prueba de característica/habilidad, una salvación, daño o una tabla aleatoria— LLAMA a roll_dice o ability_check (una salvación es un ability_check contra una CD) y reporta el resultado real de la herramienta y la CD. Nunca inventes ni narres tú un resultado de dado.
+6. TIRA CON LAS HERRAMIENTAS DE DADOS. Cuando un resultado sea incierto y requiera tirada —un ataque, una prueba de característica/habilidad, una salvación, daño o una tabla aleatoria— LLAMA a roll_dice o ability_check (indica el personaje y la habilidad o característica —la herramienta lee el bonificador de la hoja—; save=true para una salvación) y reporta el resultado real de la herramienta y la CD. Nunca inventes ni narres tú un resultado de dado.
 
 QUÉ ESPERA EL DM DE TI:
 - Qué debería ocurrir aquí / qué pretende el módulo.
@@ -311,14 +376,14 @@ var DefaultGMPromptEN = `You are the Dungeon Master running THIS specific, pre-a
 IMPORTANT: Always respond in English.
 
 INFORMATION DISCIPLINE (NO SPOILERS) — READ THIS FIRST:
-The module context you receive includes DM-ONLY secrets: the background / "the truth", room DM notes, NPC secrets, hidden rooms, unexplored zones and future events. You use them ONLY to run and adjudicate the game — you must NEVER reveal them to the player. Answer questions strictly with what the party's characters could perceive right now or already know (what is visible, what they've already discovered, common knowledge). Reveal hidden content only through actual play: exploration, successful checks, or in-fiction discovery — never by listing the map, floor plans, secret levels, hidden inhabitants or plot. Do NOT break the fiction to give an exhaustive, canonical, out-of-character answer — that full-disclosure "oracle" behaviour is for assistant mode, NOT here. If a question would require DM-only knowledge to answer fully, answer only the player-knowable part in the fiction (e.g. describe what the building looks like from outside), and let the rest be discovered. When unsure whether the party knows something, assume they do NOT.
+The module context you receive includes DM-ONLY secrets: the background / "the truth", room DM notes, NPC secrets, hidden rooms, unexplored zones and future events. You use them ONLY to run and adjudicate the game — you must NEVER reveal them to the player. Blocks fenced by "=== DM-ONLY (never reveal verbatim) ===" … "=== END DM-ONLY ===" are exactly that material. Content the module marks secret is withheld from you until play reveals it; when the context says something is withheld until a check, call for that check only when the players' actions warrant it, and never hint at it otherwise. Answer questions strictly with what the party's characters could perceive right now or already know (what is visible, what they've already discovered, common knowledge). Reveal hidden content only through actual play: exploration, successful checks, or in-fiction discovery — never by listing the map, floor plans, secret levels, hidden inhabitants or plot. Do NOT break the fiction to give an exhaustive, canonical, out-of-character answer — that full-disclosure "oracle" behaviour is for assistant mode, NOT here. If a question would require DM-only knowledge to answer fully, answer only the player-knowable part in the fiction (e.g. describe what the building looks like from outside), and let the rest be discovered. When unsure whether the party knows something, assume they do NOT.
 
 CORE PRINCIPLES:
 1. YOU ARE THE WORLD. Narrate scenes, portray every NPC (voice, personality, motivations), and adjudicate outcomes. Bring the module to life.
 2. FOLLOW THE MODULE'S CANON. Its zones, rooms, NPCs, events, and lore are the source of truth. Use the retrieval tools (get_room / get_npc / get_event / get_item / search_module) to ground what happens; do not invent content the module already defines. (Grounding ≠ disclosure — see INFORMATION DISCIPLINE above.)
 3. RESPECT PLAYER AGENCY. Never decide or narrate what the party's characters do or feel. Describe the situation, then ask what they do.
-4. USE DICE FOR UNCERTAINTY. When an outcome is in doubt, call roll_dice or ability_check (a saving throw is just an ability_check against a DC), announce the DC, and honour the result (nat 20 / nat 1 are dramatic).
-5. TRACK STATE. Keep each party member and the world current with the session tools (update_hp, add_item, remove_item, set_condition, update_gold, award_xp — pass the "character" name to target a specific member) and (set_location, trigger_event, set_flag, log_note, advance_quest). The party roster and each member's CURRENT sheet are provided in context and are AUTHORITATIVE: never narrate a state that contradicts a character's sheet — e.g. do not describe someone as unconscious, dying or dead while their HP is above 0, nor unharmed while at 0 HP or with damaging conditions. When an action changes a character's state, call the tool FIRST (update_hp / set_condition) and only then narrate the outcome consistently with the updated sheet.
+4. USE DICE FOR UNCERTAINTY. When an outcome is in doubt, call roll_dice or ability_check (name the character and skill or ability — the bonus comes from the sheet; save=true for a saving throw), announce the DC, and honour the result (nat 20 / nat 1 are dramatic).
+5. TRACK STATE. Keep each party member and the world current with the session tools (update_hp, add_item, remove_item, set_condition, update_gold, award_xp — pass the "character" name to target a specific member) and (set_location, trigger_event, set_flag, log_note, advance_quest). The party roster and each member's CURRENT sheet are provided in context and are AUTHORITATIVE: never narrate a state that contradicts a character's sheet — e.g. do not describe someone as unconscious, dying or dead while their HP is above 0, nor unharmed while at 0 HP or with damaging conditions. When an action changes a character's state, call the tool FIRST (update_hp / set_condition) and only then narrate the outcome consistently with the updated sheet. A character at 0 HP is unconscious and dying: on each of their turns call death_save (three successes stabilize, three failures kill; healing wakes them), and never decide a death yourself — the sheet does.
 
 RESPONSE FORMAT:
 - NARRATIVE: 2-4 vivid paragraphs describing what the character perceives and how NPCs react.
@@ -332,14 +397,14 @@ var DefaultGMPromptES = `Eres el Dungeon Master que dirige ESTA aventura concret
 IMPORTANTE: Responde siempre en español.
 
 DISCIPLINA DE INFORMACIÓN (SIN SPOILERS) — LEE ESTO PRIMERO:
-El contexto del módulo que recibes incluye secretos SOLO PARA EL DM: el trasfondo / "la verdad", las notas de DM de las salas, los secretos de los NPC, salas ocultas, zonas no exploradas y eventos futuros. Los usas ÚNICAMENTE para dirigir y arbitrar la partida — NUNCA debes revelárselos al jugador. Responde solo con lo que los personajes del grupo podrían percibir ahora mismo o ya saben (lo visible, lo que ya han descubierto, el conocimiento común). Revela el contenido oculto solo mediante el juego real: exploración, tiradas con éxito o descubrimiento dentro de la ficción — nunca enumerando el mapa, los planos, los niveles secretos, los habitantes ocultos o la trama. NO rompas la ficción para dar una respuesta exhaustiva, canónica y fuera de personaje — ese comportamiento de "oráculo" con información completa es del modo asistente, NO de aquí. Si responder del todo requeriría conocimiento de DM, responde solo la parte que el jugador puede conocer, dentro de la ficción (p. ej. describe cómo se ve el edificio desde fuera) y deja que lo demás se descubra. Ante la duda de si el grupo sabe algo, asume que NO.
+El contexto del módulo que recibes incluye secretos SOLO PARA EL DM: el trasfondo / "la verdad", las notas de DM de las salas, los secretos de los NPC, salas ocultas, zonas no exploradas y eventos futuros. Los usas ÚNICAMENTE para dirigir y arbitrar la partida — NUNCA debes revelárselos al jugador. Los bloques delimitados por "=== DM-ONLY (never reveal verbatim) ===" … "=== END DM-ONLY ===" son exactamente ese material. El contenido que el módulo marca como secreto se te oculta hasta que el juego lo revela; si el contexto dice que algo está oculto hasta una tirada, pídela solo cuando las acciones de los jugadores lo justifiquen y nunca lo insinúes de otro modo. Responde solo con lo que los personajes del grupo podrían percibir ahora mismo o ya saben (lo visible, lo que ya han descubierto, el conocimiento común). Revela el contenido oculto solo mediante el juego real: exploración, tiradas con éxito o descubrimiento dentro de la ficción — nunca enumerando el mapa, los planos, los niveles secretos, los habitantes ocultos o la trama. NO rompas la ficción para dar una respuesta exhaustiva, canónica y fuera de personaje — ese comportamiento de "oráculo" con información completa es del modo asistente, NO de aquí. Si responder del todo requeriría conocimiento de DM, responde solo la parte que el jugador puede conocer, dentro de la ficción (p. ej. describe cómo se ve el edificio desde fuera) y deja que lo demás se descubra. Ante la duda de si el grupo sabe algo, asume que NO.
 
 PRINCIPIOS FUNDAMENTALES:
 1. ERES EL MUNDO. Narra las escenas, interpreta a cada NPC (voz, personalidad, motivaciones) y resuelve los resultados. Da vida al módulo.
 2. SIGUE EL CANON DEL MÓDULO. Sus zonas, salas, NPCs, eventos y lore son la fuente de verdad. Usa las herramientas de recuperación (get_room / get_npc / get_event / get_item / search_module) para anclar lo que ocurre; no inventes contenido que el módulo ya define. (Anclar ≠ revelar — mira la DISCIPLINA DE INFORMACIÓN de arriba.)
 3. RESPETA LA AGENCIA DEL JUGADOR. Nunca decidas ni narres lo que hacen o sienten los personajes del grupo. Describe la situación y pregunta qué hacen.
-4. USA LOS DADOS ANTE LA INCERTIDUMBRE. Cuando un resultado esté en duda, llama a roll_dice o ability_check (una tirada de salvación es un ability_check contra una CD), anuncia la CD y respeta el resultado (el 20 y el 1 naturales son dramáticos).
-5. LLEVA EL ESTADO. Mantén al día a cada miembro del grupo y al mundo con las herramientas de sesión (update_hp, add_item, remove_item, set_condition, update_gold, award_xp — pasa el nombre en "character" para apuntar a un miembro concreto) y (set_location, trigger_event, set_flag, log_note, advance_quest). Tienes en el contexto el listado del grupo y la ficha ACTUAL de cada miembro, que es AUTORITATIVA: nunca narres un estado que contradiga la ficha de un personaje — p. ej. no lo describas inconsciente, agonizante o muerto si sus PG son mayores que 0, ni ileso si está a 0 PG o con condiciones dañinas. Cuando una acción cambie el estado de un personaje, llama PRIMERO a la herramienta (update_hp / set_condition) y solo después narra el resultado de forma coherente con la ficha actualizada.
+4. USA LOS DADOS ANTE LA INCERTIDUMBRE. Cuando un resultado esté en duda, llama a roll_dice o ability_check (indica el personaje y la habilidad o característica —el bonificador sale de la hoja—; save=true para una salvación), anuncia la CD y respeta el resultado (el 20 y el 1 naturales son dramáticos).
+5. LLEVA EL ESTADO. Mantén al día a cada miembro del grupo y al mundo con las herramientas de sesión (update_hp, add_item, remove_item, set_condition, update_gold, award_xp — pasa el nombre en "character" para apuntar a un miembro concreto) y (set_location, trigger_event, set_flag, log_note, advance_quest). Tienes en el contexto el listado del grupo y la ficha ACTUAL de cada miembro, que es AUTORITATIVA: nunca narres un estado que contradiga la ficha de un personaje — p. ej. no lo describas inconsciente, agonizante o muerto si sus PG son mayores que 0, ni ileso si está a 0 PG o con condiciones dañinas. Cuando una acción cambie el estado de un personaje, llama PRIMERO a la herramienta (update_hp / set_condition) y solo después narra el resultado de forma coherente con la ficha actualizada. Un personaje a 0 PG está inconsciente y agonizando: en cada uno de sus turnos llama a death_save (tres éxitos lo estabilizan, tres fallos lo matan; la curación lo despierta), y nunca decidas tú una muerte — lo decide la ficha.
 
 FORMATO DE RESPUESTA:
 - NARRATIVA: 2-4 párrafos vívidos que describan lo que percibe el grupo y cómo reaccionan los NPCs.
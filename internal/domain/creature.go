@@ -0,0 +1,287 @@
+package domain
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// This file tracks spawned creatures: per-instance monsters ("Goblin #1" ..
+// "Goblin #4") with their own HP, conditions and defeated flag, so four goblins
+// from one authored stat block can each be hurt separately. Instances live on the
+// session, in or out of a tracked fight; dice (rolled HP) stay in the engine.
+
+// maxSpawnCount caps how many instances one spawn may add.
+const maxSpawnCount = 20
+
+// CreatureInstance is one spawned monster. Its stat block is the authored NPC
+// (NPCID) or SRD creature (Creature) it was spawned from; the instance carries
+// only what changes at the table.
+type CreatureInstance struct {
+	Name       string      `json:"name"` // unique in the session, e.g. "Goblin #2"
+	Creature   string      `json:"creature,omitempty"`
+	NPCID      string      `json:"npc_id,omitempty"`
+	Encounter  string      `json:"encounter,omitempty"` // the encounter it was spawned from
+	Room       string      `json:"room,omitempty"`      // where it was spawned
+	CurrentHP  int         `json:"current_hp"`
+	MaxHP      int         `json:"max_hp"`
+	AC         int         `json:"ac,omitempty"`
+	Conditions []Condition `json:"conditions,omitempty"`
+	Defeated   bool        `json:"defeated,omitempty"`
+}
+
+// Status is the instance's one-line state: "Goblin #2 HP 3/7 AC 15 [prone]",
+// or "Goblin #2 — defeated".
+func (c CreatureInstance) Status() string {
+	if c.Defeated {
+		return c.Name + " — defeated"
+	}
+	s := fmt.Sprintf("%s HP %d/%d", c.Name, c.CurrentHP, c.MaxHP)
+	if c.AC > 0 {
+		s += fmt.Sprintf(" AC %d", c.AC)
+	}
+	if len(c.Conditions) > 0 {
+		names := make([]string, len(c.Conditions))
+		for i, cd := range c.Conditions {
+			names[i] = string(cd)
+		}
+		s += " [" + strings.Join(names, ", ") + "]"
+	}
+	return s
+}
+
+func (c CreatureInstance) clone() CreatureInstance {
+	c.Conditions = slices.Clone(c.Conditions)
+	return c
+}
+
+// findCreature returns the instance with the given name (case-insensitive), or
+// nil. Callers hold the lock.
+func (s *SessionState) findCreature(name string) *CreatureInstance {
+	name = strings.TrimSpace(name)
+	for i := range s.Creatures {
+		if strings.EqualFold(s.Creatures[i].Name, name) {
+			return &s.Creatures[i]
+		}
+	}
+	return nil
+}
+
+// nextCreatureNumber is the first free "#n" for instances named base: one past
+// the highest in use (an unnumbered "Goblin" counts as #1).
+func (s *SessionState) nextCreatureNumber(base string) int {
+	next := 1
+	for _, c := range s.Creatures {
+		if strings.EqualFold(c.Name, base) {
+			next = max(next, 2)
+			continue
+		}
+		rest, ok := strings.CutPrefix(strings.ToLower(c.Name), strings.ToLower(base)+" #")
+		if n, err := strconv.Atoi(rest); ok && err == nil {
+			next = max(next, n+1)
+		}
+	}
+	return next
+}
+
+// SpawnCreatures adds instances of one creature, all named after base. A single
+// instance keeps the bare name unless it is taken; several are numbered "#1",
+// "#2", ... continuing past any already spawned. HP, AC and the stat block links
+// come from the given template (HP is rolled by the caller, one per instance).
+// It logs the spawn and returns copies of the new instances.
+func (s *SessionState) SpawnCreatures(base string, tmpl CreatureInstance, hps []int) []CreatureInstance {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	base = sanitizeWorldChange(base)
+	if r := []rune(base); len(r) > maxCombatantNameLen {
+		base = strings.TrimSpace(string(r[:maxCombatantNameLen]))
+	}
+	if base == "" {
+		base = "Creature"
+	}
+	hps = hps[:min(len(hps), maxSpawnCount)]
+	n := s.nextCreatureNumber(base)
+	numbered := len(hps) > 1 || n > 1
+	out := make([]CreatureInstance, 0, len(hps))
+	names := make([]string, 0, len(hps))
+	for _, hp := range hps {
+		c := tmpl.clone()
+		c.Name = base
+		if numbered {
+			c.Name = fmt.Sprintf("%s #%d", base, n)
+			n++
+		}
+		c.MaxHP, c.CurrentHP, c.Defeated = max(hp, 1), max(hp, 1), false
+		s.Creatures = append(s.Creatures, c)
+		out = append(out, c.clone())
+		names = append(names, fmt.Sprintf("%s (HP %d)", c.Name, c.MaxHP))
+	}
+	if len(out) > 0 {
+		s.record(LogEntry{Type: LogCombat, Message: "Spawned " + strings.Join(names, ", "),
+			Data: map[string]any{"creature": base, "count": len(out)}})
+		s.touch()
+		s.publish(TopicRound)
+	}
+	return out
+}
+
+// Creature returns a copy of the instance with the given name and whether it
+// exists.
+func (s *SessionState) Creature(name string) (CreatureInstance, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if c := s.findCreature(name); c != nil {
+		return c.clone(), true
+	}
+	return CreatureInstance{}, false
+}
+
+// CreaturesSnapshot returns a deep copy of every spawned instance, defeated ones
+// included, in spawn order.
+func (s *SessionState) CreaturesSnapshot() []CreatureInstance {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := make([]CreatureInstance, len(s.Creatures))
+	for i, c := range s.Creatures {
+		out[i] = c.clone()
+	}
+	return out
+}
+
+// AdjustCreatureHP changes an instance's HP by delta (negative for damage),
+// clamped to 0..MaxHP. Dropping to 0 marks it defeated and healing above 0 brings
+// it back. A combatant linked to the instance follows it. It returns the updated
+// instance and false when none has that name.
+func (s *SessionState) AdjustCreatureHP(name string, delta int) (CreatureInstance, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	c := s.findCreature(name)
+	if c == nil {
+		return CreatureInstance{}, false
+	}
+	c.CurrentHP = min(max(0, c.CurrentHP+delta), c.MaxHP)
+	msg := fmt.Sprintf("%s HP: %d/%d", c.Name, c.CurrentHP, c.MaxHP)
+	switch {
+	case c.CurrentHP == 0 && !c.Defeated:
+		c.Defeated = true
+		msg += " — defeated"
+	case c.CurrentHP > 0 && c.Defeated:
+		c.Defeated = false
+		msg += " — back on its feet"
+	}
+	s.syncInstanceCombatant(c)
+	s.record(LogEntry{Type: LogCombat, Message: msg,
+		Data: map[string]any{"creature": c.Name, "delta": delta, "hp": c.CurrentHP}})
+	s.touch()
+	s.publish(TopicRound)
+	return c.clone(), true
+}
+
+// SetCreatureCondition adds (on) or removes a condition on an instance, matching
+// an existing one case-insensitively. It returns the updated instance and false
+// when none has that name.
+func (s *SessionState) SetCreatureCondition(name string, cond Condition, on bool) (CreatureInstance, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	c := s.findCreature(name)
+	if c == nil {
+		return CreatureInstance{}, false
+	}
+	same := func(x Condition) bool { return strings.EqualFold(string(x), string(cond)) }
+	has := slices.ContainsFunc(c.Conditions, same)
+	switch {
+	case on && !has:
+		c.Conditions = append(c.Conditions, cond)
+		s.record(LogEntry{Type: LogCombat, Message: fmt.Sprintf("%s is now %s", c.Name, cond)})
+	case !on && has:
+		c.Conditions = slices.DeleteFunc(c.Conditions, same)
+		s.record(LogEntry{Type: LogCombat, Message: fmt.Sprintf("%s is no longer %s", c.Name, cond)})
+	}
+	s.syncInstanceCombatant(c)
+	s.touch()
+	s.publish(TopicRound)
+	return c.clone(), true
+}
+
+// RemoveCreature drops an instance (it fled, was captured, or the scene moved on)
+// and reports whether it existed.
+func (s *SessionState) RemoveCreature(name string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	c := s.findCreature(name)
+	if c == nil {
+		return false
+	}
+	gone := c.Name
+	s.Creatures = slices.DeleteFunc(s.Creatures, func(x CreatureInstance) bool { return x.Name == gone })
+	s.retireInstanceCombatant(gone)
+	s.record(LogEntry{Type: LogCombat, Message: "Removed " + gone})
+	s.touch()
+	s.publish(TopicRound)
+	return true
+}
+
+// RemoveDefeatedCreatures clears every defeated instance, returning their names.
+func (s *SessionState) RemoveDefeatedCreatures() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var gone []string
+	s.Creatures = slices.DeleteFunc(s.Creatures, func(x CreatureInstance) bool {
+		if x.Defeated {
+			gone = append(gone, x.Name)
+		}
+		return x.Defeated
+	})
+	for _, name := range gone {
+		s.retireInstanceCombatant(name)
+	}
+	if len(gone) > 0 {
+		s.record(LogEntry{Type: LogCombat, Message: "Removed " + strings.Join(gone, ", ")})
+		s.touch()
+		s.publish(TopicRound)
+	}
+	return gone
+}
+
+// syncInstanceCombatant copies an instance's HP, conditions and defeated flag to
+// the combatant linked to it, so the initiative tracker and the instance never
+// disagree. Callers hold the lock.
+func (s *SessionState) syncInstanceCombatant(c *CreatureInstance) {
+	if s.Combat == nil {
+		return
+	}
+	for i := range s.Combat.Combatants {
+		cb := &s.Combat.Combatants[i]
+		if cb.Instance != "" && strings.EqualFold(cb.Instance, c.Name) {
+			cb.CurrentHP, cb.MaxHP, cb.Defeated = c.CurrentHP, c.MaxHP, c.Defeated
+			cb.Conditions = slices.Clone(c.Conditions)
+		}
+	}
+}
+
+// retireInstanceCombatant marks the combatant linked to a removed instance
+// defeated and unlinks it: it keeps its place in the order, so the turn index
+// stays valid, but EndTurn skips it from then on. Callers hold the lock.
+func (s *SessionState) retireInstanceCombatant(name string) {
+	if s.Combat == nil {
+		return
+	}
+	for i := range s.Combat.Combatants {
+		cb := &s.Combat.Combatants[i]
+		if cb.Instance != "" && strings.EqualFold(cb.Instance, name) {
+			cb.Defeated, cb.Instance = true, ""
+		}
+	}
+}
+
+// syncCombatantInstance is the reverse: after a tracked combatant's HP changes,
+// the instance it was built from follows. Callers hold the lock.
+func (s *SessionState) syncCombatantInstance(cb *Combatant) {
+	if cb.Instance == "" {
+		return
+	}
+	if c := s.findCreature(cb.Instance); c != nil {
+		c.CurrentHP, c.Defeated = cb.CurrentHP, cb.Defeated
+	}
+}
@@ -0,0 +1,119 @@
+package domain
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestSpawnCreaturesNumbersInstances(t *testing.T) {
+	s := NewSessionState("s", nil)
+	got := s.SpawnCreatures("Goblin", CreatureInstance{Creature: "goblin", AC: 15}, []int{7, 5, 0})
+	if len(got) != 3 || got[0].Name != "Goblin #1" || got[2].Name != "Goblin #3" {
+		t.Fatalf("spawned = %+v", got)
+	}
+	if got[1].CurrentHP != 5 || got[1].MaxHP != 5 || got[2].MaxHP != 1 || got[0].AC != 15 {
+		t.Errorf("HP/AC per instance: %+v", got)
+	}
+	// More spawns continue the numbering; a lone boss keeps its bare name.
+	if more := s.SpawnCreatures("goblin", CreatureInstance{}, []int{4}); more[0].Name != "goblin #4" {
+		t.Errorf("next goblin = %q", more[0].Name)
+	}
+	if boss := s.SpawnCreatures("Grask", CreatureInstance{NPCID: "grask"}, []int{9}); boss[0].Name != "Grask" {
+		t.Errorf("single instance = %q", boss[0].Name)
+	}
+	if again := s.SpawnCreatures("Grask", CreatureInstance{}, []int{9}); again[0].Name != "Grask #2" {
+		t.Errorf("a taken name gets numbered: %q", again[0].Name)
+	}
+	if e := s.Log.Entries[len(s.Log.Entries)-1]; e.Type != LogCombat || !strings.Contains(e.Message, "Spawned Grask #2 (HP 9)") {
+		t.Errorf("spawn log = %+v", e)
+	}
+}
+
+func TestCreatureInstanceHPAndConditions(t *testing.T) {
+	s := NewSessionState("s", nil)
+	s.SpawnCreatures("Orc", CreatureInstance{AC: 13}, []int{15, 15})
+	c, ok := s.AdjustCreatureHP("orc #1", -20)
+	if !ok || c.CurrentHP != 0 || !c.Defeated || c.Status() != "Orc #1 — defeated" {
+		t.Fatalf("overkill = %+v", c)
+	}
+	if other, _ := s.Creature("Orc #2"); other.CurrentHP != 15 {
+		t.Errorf("each instance has its own HP: %+v", other)
+	}
+	if c, _ = s.AdjustCreatureHP("Orc #1", 30); c.CurrentHP != 15 || c.Defeated {
+		t.Errorf("healing clamps to max and revives: %+v", c)
+	}
+	s.SetCreatureCondition("Orc #2", ConditionProne, true)
+	if c, _ = s.Creature("Orc #2"); c.Status() != "Orc #2 HP 15/15 AC 13 [Prone]" {
+		t.Errorf("status = %q", c.Status())
+	}
+	if c, _ = s.SetCreatureCondition("Orc #2", "prone", false); len(c.Conditions) != 0 {
+		t.Errorf("condition not cleared: %+v", c)
+	}
+	if _, ok := s.AdjustCreatureHP("Orc #9", -1); ok {
+		t.Error("an unknown instance should report false")
+	}
+
+	s.AdjustCreatureHP("Orc #2", -15)
+	if gone := s.RemoveDefeatedCreatures(); len(gone) != 1 || gone[0] != "Orc #2" {
+		t.Errorf("removed = %v", gone)
+	}
+	if !s.RemoveCreature("orc #1") || len(s.CreaturesSnapshot()) != 0 {
+		t.Error("RemoveCreature should drop the instance")
+	}
+}
+
+func TestCreatureInstanceFollowsLinkedCombatant(t *testing.T) {
+	s := NewSessionState("s", nil)
+	s.SpawnCreatures("Wolf", CreatureInstance{}, []int{11})
+	s.StartCombat([]Combatant{{Name: "Wolf", Side: SideFoe, Instance: "Wolf", CurrentHP: 11, MaxHP: 11}})
+	s.AdjustCombatantHP("Wolf", -11)
+	if c, _ := s.Creature("Wolf"); c.CurrentHP != 0 || !c.Defeated {
+		t.Errorf("instance should follow the combatant: %+v", c)
+	}
+	s.AdjustCreatureHP("Wolf", 4)
+	if cb := s.CombatSnapshot().Find("Wolf"); cb.CurrentHP != 4 || cb.Defeated {
+		t.Errorf("combatant should follow the instance: %+v", cb)
+	}
+}
+
+func TestRemovedCreatureLeavesTheInitiative(t *testing.T) {
+	s := NewSessionState("s", nil)
+	s.SpawnCreatures("Wolf", CreatureInstance{}, []int{11, 11})
+	s.StartCombat([]Combatant{
+		{Name: "Kael", Side: SideParty, Initiative: 15, CurrentHP: 12, MaxHP: 12},
+		{Name: "Wolf #1", Side: SideFoe, Initiative: 12, Instance: "Wolf #1", CurrentHP: 11, MaxHP: 11},
+		{Name: "Wolf #2", Side: SideFoe, Initiative: 10, Instance: "Wolf #2", CurrentHP: 11, MaxHP: 11},
+	})
+	s.RemoveCreature("wolf #1")
+	if cb := s.CombatSnapshot().Find("Wolf #1"); cb == nil || !cb.Defeated || cb.Instance != "" {
+		t.Errorf("removed creature's combatant = %+v", cb)
+	}
+	if cur, _ := s.EndTurn(); cur.Name != "Wolf #2" {
+		t.Errorf("turn should skip the removed wolf, got %s", cur.Name)
+	}
+	s.AdjustCreatureHP("Wolf #2", -11)
+	s.RemoveDefeatedCreatures()
+	if cb := s.CombatSnapshot().Find("Wolf #2"); !cb.Defeated || cb.Instance != "" {
+		t.Errorf("cleared creature's combatant = %+v", cb)
+	}
+}
+
+func TestCreatureInstancesPersist(t *testing.T) {
+	s := NewSessionState("s", nil)
+	s.SpawnCreatures("Skeleton", CreatureInstance{Creature: "skeleton", Room: "r1"}, []int{13, 12})
+	b, err := json.Marshal(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var back SessionState
+	if err := json.Unmarshal(b, &back); err != nil {
+		t.Fatal(err)
+	}
+	if len(back.Creatures) != 2 || back.Creatures[1].Name != "Skeleton #2" || back.Creatures[1].CurrentHP != 12 || back.Creatures[0].Room != "r1" {
+		t.Errorf("creatures lost in round trip: %+v", back.Creatures)
+	}
+	if b, _ := json.Marshal(NewSessionState("s", nil)); strings.Contains(string(b), `"creatures"`) {
+		t.Error("a session without spawns should not serialize creatures")
+	}
+}
@@ -0,0 +1,178 @@
+package domain
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// DamageTypes are the 5e damage types, lower-cased.
+var DamageTypes = []string{
+	"acid", "bludgeoning", "cold", "fire", "force", "lightning", "necrotic",
+	"piercing", "poison", "psychic", "radiant", "slashing", "thunder",
+}
+
+// ParseDamageType returns the canonical damage type named by s (case-insensitive,
+// e.g. "Fire"), or "" when s isn't one.
+func ParseDamageType(s string) string {
+	s = strings.ToLower(strings.TrimSpace(s))
+	for _, t := range DamageTypes {
+		if s == t {
+			return t
+		}
+	}
+	return ""
+}
+
+// DamageResult is typed damage after a creature's defenses were applied.
+type DamageResult struct {
+	Rolled     int    `json:"rolled"`
+	Applied    int    `json:"applied"`
+	Type       string `json:"type,omitempty"`
+	Immune     bool   `json:"immune,omitempty"`
+	Resistant  bool   `json:"resistant,omitempty"`
+	Vulnerable bool   `json:"vulnerable,omitempty"`
+}
+
+// Note is a short explanation of any adjustment ("resistant: halved"), or "".
+func (d DamageResult) Note() string {
+	switch {
+	case d.Immune:
+		return "immune"
+	case d.Resistant && d.Vulnerable:
+		return "resistant and vulnerable: halved, then doubled"
+	case d.Resistant:
+		return "resistant: halved"
+	case d.Vulnerable:
+		return "vulnerable: doubled"
+	}
+	return ""
+}
+
+// defenseCovers reports whether a free-form stat block defense line (e.g.
+// "fire", "bludgeoning, piercing, and slashing from nonmagical attacks")
+// applies to damage of the given type. A line qualified as "nonmagical" does not
+// cover damage from a magical source.
+func defenseCovers(line, dtype string, magical bool) bool {
+	l := strings.ToLower(line)
+	if !containsWord(l, dtype) {
+		return false
+	}
+	if magical && (strings.Contains(l, "nonmagical") || strings.Contains(l, "non-magical")) {
+		return false
+	}
+	return true
+}
+
+// containsWord reports whether word occurs in s delimited by non-letters.
+func containsWord(s, word string) bool {
+	for i := 0; ; {
+		j := strings.Index(s[i:], word)
+		if j < 0 {
+			return false
+		}
+		start, end := i+j, i+j+len(word)
+		before := start == 0 || !isLetter(s[start-1])
+		after := end == len(s) || !isLetter(s[end])
+		if before && after {
+			return true
+		}
+		i = start + 1
+	}
+}
+
+func isLetter(b byte) bool { return b >= 'a' && b <= 'z' || b >= 'A' && b <= 'Z' }
+
+// ApplyDamage applies the block's immunities, resistances and vulnerabilities to
+// amount points of dtype damage: immunity zeroes it, resistance halves it
+// (rounded down) and vulnerability doubles it. With both, resistance applies
+// first, as the rules order them, so an odd amount loses a point (7 → 3 → 6)
+// rather than the two cancelling out. Untyped damage, or a nil block, passes
+// unchanged.
+func (s *StatBlock) ApplyDamage(amount int, dtype string, magical bool) DamageResult {
+	res := DamageResult{Rolled: amount, Applied: max(amount, 0), Type: dtype}
+	if s == nil || dtype == "" {
+		return res
+	}
+	for _, l := range s.DamageImmunities {
+		if defenseCovers(l, dtype, magical) {
+			res.Immune, res.Applied = true, 0
+			return res
+		}
+	}
+	for _, l := range s.DamageResistances {
+		if defenseCovers(l, dtype, magical) {
+			res.Resistant = true
+		}
+	}
+	for _, l := range s.DamageVulnerabilities {
+		if defenseCovers(l, dtype, magical) {
+			res.Vulnerable = true
+		}
+	}
+	if res.Resistant {
+		res.Applied /= 2
+	}
+	if res.Vulnerable {
+		res.Applied *= 2
+	}
+	return res
+}
+
+// saveLineRe matches a stat block saving-throw line such as "Dex +5" or "WIS -1".
+var saveLineRe = regexp.MustCompile(`^\s*([A-Za-z]+)\s*([+-]\s*\d+)`)
+
+// SaveBonus is the creature's saving-throw bonus for an ability: the listed
+// value from SavingThrows when present (e.g. "DEX +4"), otherwise the ability
+// modifier (0 when the block has no ability scores).
+func (s *StatBlock) SaveBonus(a Ability) int {
+	if s == nil {
+		return 0
+	}
+	for _, line := range s.SavingThrows {
+		for _, part := range strings.Split(line, ",") {
+			m := saveLineRe.FindStringSubmatch(part)
+			if m == nil {
+				continue
+			}
+			if ab, ok := ParseAbility(m[1]); ok && ab == a {
+				if v, err := strconv.Atoi(strings.ReplaceAll(m[2], " ", "")); err == nil {
+					return v
+				}
+			}
+		}
+	}
+	if s.Abilities == (AbilityScores{}) {
+		return 0
+	}
+	return Modifier(s.Abilities.Get(a))
+}
+
+// skillLineRe matches a stat block skill entry such as "Stealth +6" or
+// "Sleight of Hand +4".
+var skillLineRe = regexp.MustCompile(`^\s*([A-Za-z][A-Za-z ]*?)\s*([+-]\s*\d+)`)
+
+// SkillBonus is the creature's bonus for a skill: the listed value from Skills
+// when present (e.g. "Perception +4"), otherwise the modifier of the skill's
+// ability (0 for an unknown skill or a block without ability scores).
+func (s *StatBlock) SkillBonus(skill string) int {
+	if s == nil {
+		return 0
+	}
+	for _, line := range s.Skills {
+		for _, part := range strings.Split(line, ",") {
+			m := skillLineRe.FindStringSubmatch(part)
+			if m == nil || !strings.EqualFold(m[1], skill) {
+				continue
+			}
+			if v, err := strconv.Atoi(strings.ReplaceAll(m[2], " ", "")); err == nil {
+				return v
+			}
+		}
+	}
+	sk, ok := ParseSkill(skill)
+	if !ok || s.Abilities == (AbilityScores{}) {
+		return 0
+	}
+	return Modifier(s.Abilities.Get(sk.Ability))
+}
@@ -0,0 +1,73 @@
+package domain
+
+import "testing"
+
+func TestStatBlockApplyDamage(t *testing.T) {
+	sb := &StatBlock{
+		DamageResistances:     []string{"cold", "bludgeoning, piercing, and slashing from nonmagical attacks"},
+		DamageImmunities:      []string{"poison"},
+		DamageVulnerabilities: []string{"fire", "cold"},
+	}
+	for _, tc := range []struct {
+		dtype   string
+		magical bool
+		want    int
+	}{
+		{"poison", false, 0},   // immune
+		{"slashing", false, 5}, // resistant (nonmagical)
+		{"slashing", true, 11}, // magic bypasses the nonmagical resistance
+		{"fire", false, 22},    // vulnerable
+		{"cold", false, 10},    // resistant then vulnerable: 11/2*2
+		{"acid", false, 11},    // unaffected
+		{"", false, 11},        // untyped
+	} {
+		if got := sb.ApplyDamage(11, tc.dtype, tc.magical).Applied; got != tc.want {
+			t.Errorf("ApplyDamage(11, %q, magical=%v) = %d, want %d", tc.dtype, tc.magical, got, tc.want)
+		}
+	}
+	// Resistance before vulnerability: an odd amount doesn't come back whole.
+	if got := sb.ApplyDamage(7, "cold", false); got.Applied != 6 || got.Note() != "resistant and vulnerable: halved, then doubled" {
+		t.Errorf("ApplyDamage(7, cold) = %+v, want 6 (7/2*2)", got)
+	}
+	var none *StatBlock
+	if got := none.ApplyDamage(7, "fire", false); got.Applied != 7 || got.Note() != "" {
+		t.Errorf("nil block should pass damage through: %+v", got)
+	}
+	// "fire" must not match inside another word.
+	if (&StatBlock{DamageImmunities: []string{"firebolt traps"}}).ApplyDamage(4, "fire", false).Immune {
+		t.Error("defense lines match whole words only")
+	}
+}
+
+func TestStatBlockSaveBonus(t *testing.T) {
+	sb := &StatBlock{
+		Abilities:    AbilityScores{STR: 18, DEX: 14, CON: 16, INT: 8, WIS: 10, CHA: 6},
+		SavingThrows: []string{"Dex +5", "WIS +3, CHA -1"},
+	}
+	for ab, want := range map[Ability]int{DEX: 5, WIS: 3, CHA: -1, STR: 4, INT: -1} {
+		if got := sb.SaveBonus(ab); got != want {
+			t.Errorf("SaveBonus(%s) = %d, want %d", ab, got, want)
+		}
+	}
+	if got := (&StatBlock{}).SaveBonus(DEX); got != 0 {
+		t.Errorf("block without abilities should save at +0, got %d", got)
+	}
+}
+
+func TestStatBlockSkillBonus(t *testing.T) {
+	sb := &StatBlock{
+		Abilities: AbilityScores{STR: 19, DEX: 14, WIS: 8},
+		Skills:    []string{"Perception +4, Sleight of Hand +6"},
+	}
+	for skill, want := range map[string]int{"perception": 4, "Sleight of Hand": 6, "Athletics": 4, "Insight": -1, "Juggling": 0} {
+		if got := sb.SkillBonus(skill); got != want {
+			t.Errorf("SkillBonus(%q) = %d, want %d", skill, got, want)
+		}
+	}
+}
+
+func TestParseDamageType(t *testing.T) {
+	if ParseDamageType(" Fire ") != "fire" || ParseDamageType("banana") != "" {
+		t.Error("ParseDamageType should canonicalize known types and reject others")
+	}
+}
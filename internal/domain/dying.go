@@ -0,0 +1,251 @@
+package domain
+
+import (
+	"fmt"
+	"strings"
+)
+
+// This file implements the 5e rules for dropping to 0 hit points: a character at
+// 0 HP is unconscious and dying, rolls death saving throws until it gets three
+// successes (stable) or three failures (dead), and dies outright when the damage
+// left over after reaching 0 equals or exceeds its hit point maximum. Damage
+// taken while down is a failed save (two on a critical hit). Like the rest of the
+// domain it never rolls: callers pass the d20.
+
+// DeathSavesToResolve is how many successes stabilize, and failures kill, a
+// dying character.
+const DeathSavesToResolve = 3
+
+// IsAlive reports whether the character is not dead (a dying character is still
+// alive).
+func (c *Character) IsAlive() bool {
+	return !c.Dead
+}
+
+// IsDying reports whether the character is at 0 HP, not stable and not dead —
+// i.e. must make death saving throws.
+func (c *Character) IsDying() bool {
+	return c.CurrentHP <= 0 && !c.Stable && !c.Dead
+}
+
+// DyingStatus is a short description of the character's state at 0 HP: "dead",
+// "stable", "dying (1 success, 2 failures)"; "" for a conscious character.
+func (c *Character) DyingStatus() string {
+	switch {
+	case c.Dead:
+		return "dead"
+	case c.CurrentHP > 0:
+		return ""
+	case c.Stable:
+		return "stable"
+	}
+	return fmt.Sprintf("dying (%s, %s)", plural(c.DeathSuccesses, "success", "successes"), plural(c.DeathFailures, "failure", "failures"))
+}
+
+func plural(n int, one, many string) string {
+	if n == 1 {
+		return "1 " + one
+	}
+	return fmt.Sprintf("%d %s", n, many)
+}
+
+// DamageOutcome describes what a hit did to a character beyond the HP change.
+type DamageOutcome struct {
+	Applied       int  // HP lost after temporary hit points
+	Dropped       bool // fell to 0 HP (unconscious and dying) with this hit
+	Killed        bool // died with this hit
+	MassiveDamage bool // died of massive damage (the overflow reached max HP)
+	FailuresAdded int  // death-save failures from damage taken while down
+}
+
+// Note describes the outcome for a log line; "" when the character stayed up.
+func (o DamageOutcome) Note() string {
+	switch {
+	case o.MassiveDamage:
+		return "killed outright by massive damage"
+	case o.Killed:
+		return "dies (three death-save failures)"
+	case o.Dropped:
+		return "drops to 0 HP — unconscious and dying"
+	case o.FailuresAdded > 0:
+		return fmt.Sprintf("hit while down: +%s", plural(o.FailuresAdded, "death-save failure", "death-save failures"))
+	}
+	return ""
+}
+
+// TakeDamage deals non-critical damage; see TakeHit.
+func (c *Character) TakeDamage(damage int) DamageOutcome {
+	return c.TakeHit(damage, false)
+}
+
+// TakeHit deals damage: temporary hit points absorb it first, HP floors at 0. A
+// character brought to 0 falls unconscious and starts dying, unless the leftover
+// damage reaches its HP maximum (instant death). A character already at 0 takes
+// a death-save failure instead (two on a critical hit), or dies outright when the
+// damage alone reaches its maximum. A dead character is unaffected.
+func (c *Character) TakeHit(damage int, critical bool) DamageOutcome {
+	var out DamageOutcome
+	if c.Dead || damage <= 0 {
+		return out
+	}
+	if c.TempHP > 0 {
+		if damage <= c.TempHP {
+			c.TempHP -= damage
+			return out
+		}
+		damage -= c.TempHP
+		c.TempHP = 0
+	}
+	out.Applied = damage
+	if c.CurrentHP <= 0 {
+		c.Stable = false
+		if damage >= c.MaxHP {
+			out.Killed, out.MassiveDamage = true, true
+			c.die()
+			return out
+		}
+		out.FailuresAdded = 1
+		if critical {
+			out.FailuresAdded = 2
+		}
+		c.DeathFailures = min(c.DeathFailures+out.FailuresAdded, DeathSavesToResolve)
+		if c.DeathFailures >= DeathSavesToResolve {
+			out.Killed = true
+			c.die()
+		}
+		return out
+	}
+	overflow := damage - c.CurrentHP
+	c.CurrentHP -= damage
+	if c.CurrentHP > 0 {
+		return out
+	}
+	c.CurrentHP = 0
+	if overflow >= c.MaxHP {
+		out.Killed, out.MassiveDamage = true, true
+		c.die()
+		return out
+	}
+	out.Dropped = true
+	c.fallUnconscious()
+	return out
+}
+
+// fallUnconscious starts the dying state with a clean death-save tally.
+func (c *Character) fallUnconscious() {
+	c.DeathSuccesses, c.DeathFailures, c.Stable = 0, 0, false
+	c.AddCondition(ConditionUnconscious)
+}
+
+// regainConsciousness ends the dying/stable state once the character has HP.
+func (c *Character) regainConsciousness() {
+	c.DeathSuccesses, c.DeathFailures, c.Stable = 0, 0, false
+	c.RemoveCondition(ConditionUnconscious)
+}
+
+func (c *Character) die() {
+	c.CurrentHP = 0
+	c.Dead, c.Stable = true, false
+	c.AddCondition(ConditionUnconscious)
+}
+
+// Stabilize makes a dying character stable (Medicine check, Spare the Dying): it
+// stays unconscious at 0 HP but stops rolling death saves. It reports whether the
+// character was dying.
+func (c *Character) Stabilize() bool {
+	if !c.IsDying() {
+		return false
+	}
+	c.Stable = true
+	c.DeathSuccesses, c.DeathFailures = 0, 0
+	return true
+}
+
+// Revive brings a dead character back with hp hit points (Revivify, Raise Dead,
+// a DM ruling), clearing the dying state. It reports whether the character was
+// dead.
+func (c *Character) Revive(hp int) bool {
+	if !c.Dead {
+		return false
+	}
+	c.Dead = false
+	c.CurrentHP = min(max(hp, 1), c.MaxHP)
+	c.regainConsciousness()
+	return true
+}
+
+// DeathSaveResult describes one death saving throw.
+type DeathSaveResult struct {
+	Name       string
+	Roll       int // the natural d20
+	Success    bool
+	Successes  int // tally after the save
+	Failures   int
+	Stabilized bool // third success
+	Died       bool // third failure
+	Revived    bool // natural 20: back up with 1 HP
+}
+
+func (r DeathSaveResult) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s death save: d20 = %d — ", r.Name, r.Roll)
+	switch {
+	case r.Revived:
+		b.WriteString("natural 20! regains 1 HP and is conscious")
+	case r.Died:
+		b.WriteString("FAILURE — three failures: dies")
+	case r.Stabilized:
+		b.WriteString("SUCCESS — three successes: stable")
+	default:
+		result := "SUCCESS"
+		if !r.Success {
+			result = "FAILURE"
+			if r.Roll == 1 {
+				result = "natural 1: TWO FAILURES"
+			}
+		}
+		fmt.Fprintf(&b, "%s (%d/%d successes, %d/%d failures)", result, r.Successes, DeathSavesToResolve, r.Failures, DeathSavesToResolve)
+	}
+	return b.String()
+}
+
+// DeathSave applies a death saving throw with the given natural d20: 10 or
+// higher succeeds, a 1 counts as two failures, and a 20 brings the character
+// back with 1 HP. Three successes stabilize; three failures kill. It fails unless
+// the character is dying.
+func (c *Character) DeathSave(roll int) (DeathSaveResult, error) {
+	if !c.IsDying() {
+		if status := c.DyingStatus(); status != "" {
+			return DeathSaveResult{}, fmt.Errorf("%s is %s and makes no death saves", c.Name, status)
+		}
+		return DeathSaveResult{}, fmt.Errorf("%s is not dying (%d HP)", c.Name, c.CurrentHP)
+	}
+	if roll < 1 || roll > 20 {
+		return DeathSaveResult{}, fmt.Errorf("a death save is a d20 roll, got %d", roll)
+	}
+	res := DeathSaveResult{Name: c.Name, Roll: roll, Success: roll >= 10}
+	switch {
+	case roll == 20:
+		c.CurrentHP = 1
+		c.regainConsciousness()
+		res.Revived = true
+		return res, nil
+	case roll == 1:
+		c.DeathFailures += 2
+	case res.Success:
+		c.DeathSuccesses++
+	default:
+		c.DeathFailures++
+	}
+	c.DeathFailures = min(c.DeathFailures, DeathSavesToResolve)
+	res.Successes, res.Failures = c.DeathSuccesses, c.DeathFailures
+	switch {
+	case c.DeathFailures >= DeathSavesToResolve:
+		c.die()
+		res.Died = true
+	case c.DeathSuccesses >= DeathSavesToResolve:
+		c.Stabilize()
+		res.Stabilized = true
+	}
+	return res, nil
+}
@@ -0,0 +1,157 @@
+package domain
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func dyingFighter() *Character {
+	c := NewCharacter("Bram", "Human", "Fighter")
+	c.MaxHP, c.CurrentHP = 12, 5
+	return c
+}
+
+func TestDropToZeroStartsDying(t *testing.T) {
+	c := dyingFighter()
+	out := c.TakeDamage(8)
+	if !out.Dropped || out.Killed || c.CurrentHP != 0 || !c.IsDying() || !c.IsAlive() {
+		t.Fatalf("8 damage at 5 HP: %+v, HP %d", out, c.CurrentHP)
+	}
+	if !c.HasCondition(ConditionUnconscious) || c.DyingStatus() != "dying (0 successes, 0 failures)" {
+		t.Errorf("a dying character is unconscious: %v %q", c.Conditions, c.DyingStatus())
+	}
+
+	// Healing brings them back and clears the tally.
+	c.DeathFailures = 2
+	c.Heal(3)
+	if c.CurrentHP != 3 || c.IsDying() || c.DeathFailures != 0 || c.HasCondition(ConditionUnconscious) {
+		t.Errorf("healed from 0: HP %d, %+v", c.CurrentHP, c)
+	}
+}
+
+func TestMassiveDamageKills(t *testing.T) {
+	c := dyingFighter()
+	// 5 HP, 12 max: 17 leaves 12 over — instant death.
+	if out := c.TakeDamage(17); !out.MassiveDamage || !c.Dead || c.IsAlive() {
+		t.Fatalf("massive damage: %+v dead=%v", out, c.Dead)
+	}
+	if c.DyingStatus() != "dead" {
+		t.Errorf("status = %q", c.DyingStatus())
+	}
+	c.Heal(10)
+	c.LongRest()
+	if c.CurrentHP != 0 || !c.Dead {
+		t.Error("healing and rest must not raise the dead")
+	}
+	if !c.Revive(1) || c.Dead || c.CurrentHP != 1 || c.HasCondition(ConditionUnconscious) {
+		t.Errorf("Revive: %+v", c)
+	}
+
+	// One short of the maximum only knocks them down.
+	d := dyingFighter()
+	if out := d.TakeDamage(16); out.Killed || !out.Dropped {
+		t.Errorf("16 damage at 5/12 should drop, not kill: %+v", out)
+	}
+}
+
+func TestDamageWhileDown(t *testing.T) {
+	c := dyingFighter()
+	c.TakeDamage(5)
+	if out := c.TakeDamage(1); out.FailuresAdded != 1 || c.DeathFailures != 1 {
+		t.Errorf("a hit while down is one failure: %+v", out)
+	}
+	if out := c.TakeHit(1, true); !out.Killed || c.DeathFailures != 3 || !c.Dead {
+		t.Errorf("a critical hit while down is two failures: %+v", out)
+	}
+
+	s := dyingFighter()
+	s.TakeDamage(5)
+	s.Stabilize()
+	s.TakeDamage(1)
+	if s.Stable || s.DeathFailures != 1 {
+		t.Errorf("damage ends stability: %+v", s)
+	}
+	if out := s.TakeDamage(12); !out.MassiveDamage {
+		t.Errorf("damage >= max HP while down kills outright: %+v", out)
+	}
+
+	// Temporary hit points still soak a hit while down.
+	p := dyingFighter()
+	p.TakeDamage(5)
+	p.TempHP = 4
+	if out := p.TakeDamage(3); out.FailuresAdded != 0 || p.TempHP != 1 {
+		t.Errorf("temp HP should absorb the hit: %+v", out)
+	}
+}
+
+func TestDeathSaves(t *testing.T) {
+	c := dyingFighter()
+	if _, err := c.DeathSave(12); err == nil {
+		t.Error("a conscious character makes no death saves")
+	}
+	c.TakeDamage(5)
+	for _, roll := range []int{10, 3, 15} {
+		if _, err := c.DeathSave(roll); err != nil {
+			t.Fatal(err)
+		}
+	}
+	res, _ := c.DeathSave(19)
+	if !res.Stabilized || !c.Stable || c.IsDying() || c.DyingStatus() != "stable" {
+		t.Errorf("third success should stabilize: %+v %+v", res, c)
+	}
+	if _, err := c.DeathSave(10); err == nil || !strings.Contains(err.Error(), "stable") {
+		t.Errorf("a stable character makes no saves: %v", err)
+	}
+
+	d := dyingFighter()
+	d.TakeDamage(5)
+	d.DeathSave(5)
+	if res, _ := d.DeathSave(1); !res.Died || !d.Dead || !strings.Contains(res.String(), "dies") {
+		t.Errorf("a natural 1 is two failures: %+v", res)
+	}
+
+	n := dyingFighter()
+	n.TakeDamage(5)
+	n.DeathSave(2)
+	if res, _ := n.DeathSave(20); !res.Revived || n.CurrentHP != 1 || n.DeathFailures != 0 || n.HasCondition(ConditionUnconscious) {
+		t.Errorf("a natural 20 revives at 1 HP: %+v %+v", res, n)
+	}
+	if _, err := n.DeathSave(0); err == nil {
+		t.Error("a roll outside 1..20 is invalid")
+	}
+}
+
+func TestSetHPOverridesDyingState(t *testing.T) {
+	c := dyingFighter()
+	c.SetHP(0)
+	if !c.IsDying() || !c.HasCondition(ConditionUnconscious) {
+		t.Errorf("setting HP to 0 starts dying: %+v", c)
+	}
+	c.Stabilize()
+	if !c.SetHP(4) || c.IsDying() || c.Stable || c.CurrentHP != 4 || c.HasCondition(ConditionUnconscious) {
+		t.Errorf("a positive set brings a stable character round: %+v", c)
+	}
+	c.TakeDamage(40)
+	if c.SetHP(4) || !c.Dead || c.CurrentHP != 0 {
+		t.Errorf("setting HP must not raise the dead (that is Revive): %+v", c)
+	}
+}
+
+func TestDyingStateRoundTrips(t *testing.T) {
+	c := dyingFighter()
+	c.TakeDamage(5)
+	c.DeathSave(12)
+	c.DeathSave(4)
+	b, _ := json.Marshal(c)
+	var back Character
+	if err := json.Unmarshal(b, &back); err != nil {
+		t.Fatal(err)
+	}
+	if back.DeathSuccesses != 1 || back.DeathFailures != 1 || !back.IsDying() {
+		t.Errorf("dying state lost: %+v", back)
+	}
+	if b, _ := json.Marshal(dyingFighter()); strings.Contains(string(b), "death_") {
+		t.Errorf("a healthy sheet should not serialize death saves: %s", b)
+	}
+}
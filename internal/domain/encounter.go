@@ -0,0 +1,276 @@
+package domain
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// This file implements the 5e encounter-building math (DMG ch. 3): the XP each
+// creature is worth, the XP thresholds a party of given levels can handle, and
+// the group multiplier for fighting several monsters at once. An encounter's
+// authored Difficulty stays free text; these rules compute the actual rating for
+// whichever party is playing.
+
+// Encounter difficulty ratings, from easiest to hardest. "trivial" is below the
+// easy threshold.
+const (
+	DifficultyTrivial = "trivial"
+	DifficultyEasy    = "easy"
+	DifficultyMedium  = "medium"
+	DifficultyHard    = "hard"
+	DifficultyDeadly  = "deadly"
+)
+
+// crXP is the XP award for each challenge rating.
+var crXP = map[string]int{
+	"0": 10, "1/8": 25, "1/4": 50, "1/2": 100,
+	"1": 200, "2": 450, "3": 700, "4": 1100, "5": 1800,
+	"6": 2300, "7": 2900, "8": 3900, "9": 5000, "10": 5900,
+	"11": 7200, "12": 8400, "13": 10000, "14": 11500, "15": 13000,
+	"16": 15000, "17": 18000, "18": 20000, "19": 22000, "20": 25000,
+	"21": 33000, "22": 41000, "23": 50000, "24": 62000, "25": 75000,
+	"26": 90000, "27": 105000, "28": 120000, "29": 135000, "30": 155000,
+}
+
+// XPForCR is the XP a creature of the given challenge rating is worth ("1/4",
+// "0.25" and "3" forms are accepted); 0 when the rating is unknown.
+func XPForCR(cr string) int {
+	cr = strings.TrimSpace(cr)
+	switch cr {
+	case "0.125":
+		cr = "1/8"
+	case "0.25":
+		cr = "1/4"
+	case "0.5":
+		cr = "1/2"
+	}
+	return crXP[cr]
+}
+
+// StatBlockXP is the XP a stat block is worth: its XP field, or else the XP for
+// its challenge rating.
+func StatBlockXP(sb *StatBlock) int {
+	if sb == nil {
+		return 0
+	}
+	if sb.XP > 0 {
+		return sb.XP
+	}
+	return XPForCR(sb.CR)
+}
+
+// levelThresholds[level-1] holds the per-character easy, medium, hard and deadly
+// XP thresholds for levels 1-20.
+var levelThresholds = [20][4]int{
+	{25, 50, 75, 100},
+	{50, 100, 150, 200},
+	{75, 150, 225, 400},
+	{125, 250, 375, 500},
+	{250, 500, 750, 1100},
+	{300, 600, 900, 1400},
+	{350, 750, 1100, 1700},
+	{450, 900, 1400, 2100},
+	{550, 1100, 1600, 2400},
+	{600, 1200, 1900, 2800},
+	{800, 1600, 2400, 3600},
+	{1000, 2000, 3000, 4500},
+	{1100, 2200, 3400, 5100},
+	{1250, 2500, 3800, 5700},
+	{1400, 2800, 4300, 6400},
+	{1600, 3200, 4800, 7200},
+	{2000, 3900, 5900, 8800},
+	{2100, 4200, 6300, 9500},
+	{2400, 4900, 7300, 10900},
+	{2800, 5700, 8500, 12700},
+}
+
+// PartyThresholds sums the easy, medium, hard and deadly XP thresholds of a
+// party with the given character levels (each clamped to 1-20).
+func PartyThresholds(levels []int) [4]int {
+	var out [4]int
+	for _, l := range levels {
+		t := levelThresholds[min(max(l, 1), 20)-1]
+		for i := range out {
+			out[i] += t[i]
+		}
+	}
+	return out
+}
+
+// multiplierSteps are the group multipliers in order. The standard table spans
+// index 1 (one monster, x1) to 6 (fifteen or more, x4); a small party steps one
+// up and a large party one down, which reaches the ends.
+var multiplierSteps = []float64{0.5, 1, 1.5, 2, 2.5, 3, 4, 5}
+
+// EncounterMultiplier is the XP multiplier for fighting the given number of
+// monsters: x1 for one, x1.5 for two, x2 for 3-6, x2.5 for 7-10, x3 for 11-14
+// and x4 for 15 or more. A party of fewer than three steps up one row, a party
+// of six or more steps down one; partySize < 1 means "a standard party".
+func EncounterMultiplier(monsters, partySize int) float64 {
+	if monsters < 1 {
+		return 0
+	}
+	step := 6
+	switch {
+	case monsters == 1:
+		step = 1
+	case monsters == 2:
+		step = 2
+	case monsters <= 6:
+		step = 3
+	case monsters <= 10:
+		step = 4
+	case monsters <= 14:
+		step = 5
+	}
+	switch {
+	case partySize >= 1 && partySize < 3:
+		step++
+	case partySize >= 6:
+		step--
+	}
+	return multiplierSteps[step]
+}
+
+// Creature entries with a count: "Goblin x3" or "Goblin (×3)", and "3 goblins"
+// or "3x Goblin".
+var (
+	creatureTrailingCount = regexp.MustCompile(`^(.+?)\s*\(?\s*[x×]\s*(\d+)\s*\)?$`)
+	creatureLeadingCount  = regexp.MustCompile(`^(\d+)\s*[x×]?\s+(.+)$`)
+)
+
+// maxCreatureCount caps the count parsed from one creature entry.
+const maxCreatureCount = 100
+
+// ParseCreatureEntry splits an encounter creature entry into its name and how
+// many of it there are: "Goblin x3" and "3 goblins" are three, a bare name is
+// one.
+func ParseCreatureEntry(entry string) (name string, count int) {
+	entry = strings.TrimSpace(entry)
+	if m := creatureTrailingCount.FindStringSubmatch(entry); m != nil {
+		name, count = m[1], atoiOr(m[2], 1)
+	} else if m := creatureLeadingCount.FindStringSubmatch(entry); m != nil {
+		name, count = m[2], atoiOr(m[1], 1)
+	} else {
+		name, count = entry, 1
+	}
+	return strings.TrimSpace(name), min(max(count, 1), maxCreatureCount)
+}
+
+func atoiOr(s string, fallback int) int {
+	n, err := strconv.Atoi(s)
+	if err != nil {
+		return fallback
+	}
+	return n
+}
+
+// CreatureLookup resolves a creature name to a stock stat block: the SRD
+// catalog, srd.Lookup, which domain can't import itself. Callers pass it
+// explicitly; nil means no stock catalog.
+type CreatureLookup func(name string) (StatBlock, bool)
+
+// CreatureStatBlock resolves an encounter creature name to a stat block: an
+// authored NPC matched by id or name (case-insensitive) when it has one, else
+// the stock catalog. An authored block's slices are shared with the adventure;
+// treat the result as read-only.
+func (a *Adventure) CreatureStatBlock(name string, stock CreatureLookup) (StatBlock, bool) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return StatBlock{}, false
+	}
+	for i := range a.NPCs {
+		n := &a.NPCs[i]
+		if n.StatBlock != nil && (n.ID == name || strings.EqualFold(n.Name, name)) {
+			return *n.StatBlock, true
+		}
+	}
+	if stock != nil {
+		return stock(name)
+	}
+	return StatBlock{}, false
+}
+
+// EncounterBudget is the XP value of an encounter's creatures, before the group
+// multiplier. Creatures that resolve to no stat block are listed in Unresolved
+// and count for nothing.
+type EncounterBudget struct {
+	Monsters   int      // creatures with a stat block (a "Goblin x3" entry is three)
+	BaseXP     int      // their summed XP
+	Unresolved []string // creature names with no stat block
+}
+
+// EncounterBudget totals the XP of an encounter's creatures, resolving those
+// that aren't authored NPCs against the stock catalog.
+func (a *Adventure) EncounterBudget(e *Encounter, stock CreatureLookup) EncounterBudget {
+	var b EncounterBudget
+	for _, entry := range e.Creatures {
+		name, count := ParseCreatureEntry(entry)
+		if name == "" {
+			continue
+		}
+		sb, ok := a.CreatureStatBlock(name, stock)
+		if !ok {
+			b.Unresolved = append(b.Unresolved, name)
+			continue
+		}
+		b.Monsters += count
+		b.BaseXP += count * StatBlockXP(&sb)
+	}
+	return b
+}
+
+// EncounterRating is an encounter's difficulty for a particular party.
+type EncounterRating struct {
+	Difficulty string  // trivial/easy/medium/hard/deadly; "" with no party or no creatures
+	Multiplier float64 // group multiplier for the monster count and party size
+	AdjustedXP int     // BaseXP × Multiplier
+	Thresholds [4]int  // the party's easy/medium/hard/deadly thresholds
+}
+
+// Rate rates the budget against a party with the given character levels. With
+// no levels it still reports the adjusted XP for a standard party, unrated.
+func (b EncounterBudget) Rate(levels []int) EncounterRating {
+	r := EncounterRating{Multiplier: EncounterMultiplier(b.Monsters, len(levels))}
+	r.AdjustedXP = int(float64(b.BaseXP) * r.Multiplier)
+	if len(levels) == 0 || b.Monsters == 0 {
+		return r
+	}
+	r.Thresholds = PartyThresholds(levels)
+	r.Difficulty = DifficultyTrivial
+	for i, d := range []string{DifficultyEasy, DifficultyMedium, DifficultyHard, DifficultyDeadly} {
+		if r.AdjustedXP >= r.Thresholds[i] {
+			r.Difficulty = d
+		}
+	}
+	return r
+}
+
+// Summary is the budget line shown to the DM, e.g. "150 XP ×2 = 300 adjusted —
+// hard for this party (easy 100 / medium 200 / hard 300 / deadly 400)".
+func (b EncounterBudget) Summary(levels []int) string {
+	if b.Monsters == 0 {
+		return "no creatures with a stat block"
+	}
+	r := b.Rate(levels)
+	s := fmt.Sprintf("%d XP ×%s = %d adjusted", b.BaseXP, strconv.FormatFloat(r.Multiplier, 'f', -1, 64), r.AdjustedXP)
+	if r.Difficulty != "" {
+		t := r.Thresholds
+		s += fmt.Sprintf(" — %s for this party (easy %d / medium %d / hard %d / deadly %d)", r.Difficulty, t[0], t[1], t[2], t[3])
+	}
+	return s
+}
+
+// PartyLevels lists the levels of the living party members, the characters an
+// encounter is rated against.
+func PartyLevels(party []Character) []int {
+	var out []int
+	for i := range party {
+		if !party[i].Dead {
+			out = append(out, max(party[i].Level, 1))
+		}
+	}
+	return out
+}
@@ -0,0 +1,106 @@
+package domain
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestEncounterTables(t *testing.T) {
+	for cr, want := range map[string]int{"0": 10, "1/4": 50, "0.5": 100, "5": 1800, "30": 155000, "31": 0, "": 0} {
+		if got := XPForCR(cr); got != want {
+			t.Errorf("XPForCR(%q) = %d, want %d", cr, got, want)
+		}
+	}
+	if got := StatBlockXP(&StatBlock{CR: "2", XP: 500}); got != 500 {
+		t.Errorf("an explicit XP wins over the CR: %d", got)
+	}
+	if got := PartyThresholds([]int{1, 1, 3, 25}); got != [4]int{2925, 5950, 8875, 13300} {
+		t.Errorf("PartyThresholds = %v", got)
+	}
+	for _, c := range []struct {
+		monsters, party int
+		want            float64
+	}{
+		{0, 4, 0}, {1, 4, 1}, {2, 4, 1.5}, {6, 4, 2}, {7, 4, 2.5}, {14, 4, 3}, {15, 4, 4},
+		{1, 2, 1.5}, {15, 1, 5}, {1, 6, 0.5}, {3, 0, 2},
+	} {
+		if got := EncounterMultiplier(c.monsters, c.party); got != c.want {
+			t.Errorf("EncounterMultiplier(%d, %d) = %v, want %v", c.monsters, c.party, got, c.want)
+		}
+	}
+}
+
+func TestParseCreatureEntry(t *testing.T) {
+	for entry, want := range map[string]struct {
+		name  string
+		count int
+	}{
+		"Goblin x3":      {"Goblin", 3},
+		"Goblin (×2)":    {"Goblin", 2},
+		"4 skeletons":    {"skeletons", 4},
+		"2x Orc":         {"Orc", 2},
+		"Grask":          {"Grask", 1},
+		"Wolf x0":        {"Wolf", 1},
+		" giant rat x9 ": {"giant rat", 9},
+	} {
+		name, count := ParseCreatureEntry(entry)
+		if name != want.name || count != want.count {
+			t.Errorf("ParseCreatureEntry(%q) = %q, %d; want %q, %d", entry, name, count, want.name, want.count)
+		}
+	}
+}
+
+// stockLookup is a stand-in for the SRD catalog.
+func stockLookup(blocks map[string]StatBlock) CreatureLookup {
+	return func(name string) (StatBlock, bool) {
+		sb, ok := blocks[strings.ToLower(name)]
+		return sb, ok
+	}
+}
+
+func TestEncounterBudgetAndRating(t *testing.T) {
+	stock := stockLookup(map[string]StatBlock{"goblin": {CR: "1/4", XP: 50}})
+	a := validAdv()
+	a.NPCs[0].StatBlock = &StatBlock{CR: "1"}
+	e := &Encounter{Name: "Ambush", Creatures: []string{"Goblin x3", "n1", "Owlbear"}}
+
+	b := a.EncounterBudget(e, stock)
+	if b.Monsters != 4 || b.BaseXP != 350 || len(b.Unresolved) != 1 || b.Unresolved[0] != "Owlbear" {
+		t.Fatalf("budget = %+v", b)
+	}
+	// Four level-2 characters: x2 for four monsters, 700 adjusted against 200/400/600/800.
+	r := b.Rate([]int{2, 2, 2, 2})
+	if r.Multiplier != 2 || r.AdjustedXP != 700 || r.Difficulty != DifficultyHard {
+		t.Errorf("rating = %+v", r)
+	}
+	if got := b.Rate([]int{10, 10, 10, 10}).Difficulty; got != DifficultyTrivial {
+		t.Errorf("four level-10 characters: %s", got)
+	}
+	if got := b.Summary(nil); got != "350 XP ×2 = 700 adjusted" {
+		t.Errorf("unrated summary = %q", got)
+	}
+	if got := b.Summary([]int{1}); !strings.Contains(got, "×2.5 = 875 adjusted — deadly for this party (easy 25 /") {
+		t.Errorf("a lone character faces a bigger multiplier: %q", got)
+	}
+
+	party := []Character{{Level: 3}, {Level: 5, Dead: true}, {Level: 0}}
+	if got := PartyLevels(party); len(got) != 2 || got[0] != 3 || got[1] != 1 {
+		t.Errorf("PartyLevels = %v", got)
+	}
+}
+
+func TestValidateWarnsOnUnresolvedCreatures(t *testing.T) {
+	stock := stockLookup(map[string]StatBlock{"goblin": {CR: "1/4"}})
+	a := validAdv()
+	a.Zones[0].Rooms[0].Encounters = []Encounter{{Name: "Ambush", Creatures: []string{"Goblin x2", "Beholder"}}}
+	errs := ValidateAdventure(a, nil, stock)
+	if len(errs) != 1 || !IsValidationWarning(errs[0]) || !strings.Contains(errs[0].Error(), `creature "Beholder" resolves to no stat block`) {
+		t.Fatalf("expected one warning, got %v", errs)
+	}
+	if len(ValidationErrors(errs)) != 0 {
+		t.Error("a warning must not make the module invalid")
+	}
+	if errs := ValidateAdventure(a, nil, nil); len(errs) != 0 {
+		t.Errorf("without a stock catalog nothing is checked, got %v", errs)
+	}
+}
@@ -0,0 +1,129 @@
+package domain
+
+import "encoding/json"
+
+// StateTopic names the part of a session's state a StateEvent is about.
+type StateTopic string
+
+const (
+	TopicLog          StateTopic = "log"          // a timeline entry was added
+	TopicLocation     StateTopic = "location"     // zone, room, scene, visited rooms
+	TopicNPC          StateTopic = "npc"          // NPCs met, their disposition, alive or not
+	TopicParty        StateTopic = "party"        // characters, sheets, players and their seats
+	TopicRound        StateTopic = "round"        // declared actions, combat, creatures
+	TopicWorld        StateTopic = "world"        // events, flags, variables, quests, world edits, checks
+	TopicMode         StateTopic = "mode"         // oracle or virtual DM, game started
+	TopicConversation StateTopic = "conversation" // a message was added (or the dialogue rolled back)
+	TopicRewind       StateTopic = "rewind"       // an undo or redo rolled the timeline back to log_length entries
+)
+
+// stateTopics are the topics whose data is a view of the state, in the order
+// an undo or redo republishes them.
+var stateTopics = []StateTopic{TopicLocation, TopicNPC, TopicParty, TopicRound, TopicWorld, TopicMode, TopicConversation}
+
+// StateEvent is one change to a session, published to the event hook as it
+// happens. Seq numbers a session's events in order, from 1. Data is the JSON of
+// what changed: the entry for TopicLog, {"length", "message"} for
+// TopicConversation (message is the newest one), {"log_length"} for
+// TopicRewind, and for the other topics the state fields they cover, under
+// their usual JSON names, so a client can merge them into the state it holds.
+type StateEvent struct {
+	Seq   uint64          `json:"seq"`
+	Topic StateTopic      `json:"topic"`
+	Data  json.RawMessage `json:"data"`
+}
+
+// SetEventHook registers fn to receive every StateEvent. fn runs with the
+// state's lock held, in order: it must not block nor call back into the state.
+func (s *SessionState) SetEventHook(fn func(StateEvent)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.onEvent = fn
+}
+
+// LogAt returns a copy of the whole timeline and the Seq of the last event
+// published, read together: a subscriber that replays the timeline picks up
+// from the events after seq without missing or repeating an entry.
+func (s *SessionState) LogAt() (entries []LogEntry, seq uint64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.Log != nil {
+		entries = append(entries, s.Log.Entries...)
+	}
+	return entries, s.eventSeq
+}
+
+// publish sends the current view of topic to the event hook. Caller holds s.mu.
+func (s *SessionState) publish(topic StateTopic) {
+	s.publishData(topic, s.topicView(topic))
+}
+
+func (s *SessionState) publishData(topic StateTopic, v any) {
+	if s.onEvent == nil {
+		return
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return
+	}
+	s.eventSeq++
+	s.onEvent(StateEvent{Seq: s.eventSeq, Topic: topic, Data: b})
+}
+
+// topicView is the data of a state topic's event. Fields are listed without
+// omitempty, so a field that was cleared (combat over) reaches the client as
+// null. Caller holds s.mu.
+func (s *SessionState) topicView(topic StateTopic) any {
+	switch topic {
+	case TopicLocation:
+		return struct {
+			CurrentZone  string          `json:"current_zone"`
+			CurrentRoom  string          `json:"current_room"`
+			CurrentScene string          `json:"current_scene"`
+			VisitedRooms map[string]bool `json:"visited_rooms"`
+		}{s.CurrentZone, s.CurrentRoom, s.CurrentScene, s.VisitedRooms}
+	case TopicNPC:
+		return struct {
+			KnownNPCs map[string]*NPCStatus `json:"known_npcs"`
+		}{s.KnownNPCs}
+	case TopicParty:
+		return struct {
+			Party              []*PartyMember         `json:"party"`
+			Characters         []*Character           `json:"characters"`
+			Players            map[string]*PlayerSlot `json:"players"`
+			PendingAssignments map[string]string      `json:"pending_assignments"`
+		}{s.Party, s.Characters, s.Players, s.PendingAssignments}
+	case TopicRound:
+		return struct {
+			Round     *TurnRound         `json:"round"`
+			Combat    *CombatState       `json:"combat"`
+			Creatures []CreatureInstance `json:"creatures"`
+		}{s.Round, s.Combat, s.Creatures}
+	case TopicWorld:
+		return struct {
+			TriggeredEvents   map[string]bool          `json:"triggered_events"`
+			Flags             map[string]bool          `json:"flags"`
+			Variables         map[string]string        `json:"variables"`
+			Quests            []QuestProgress          `json:"quests"`
+			WorldEdits        map[string][]WorldChange `json:"world_edits"`
+			WorldDescriptions map[string]string        `json:"world_descriptions"`
+			CheckResults      map[string]int           `json:"check_results"`
+		}{s.TriggeredEvents, s.Flags, s.Variables, s.Quests, s.WorldEdits, s.WorldDescriptions, s.CheckResults}
+	case TopicMode:
+		return struct {
+			Mode    SessionMode `json:"mode"`
+			Started bool        `json:"started"`
+		}{s.Mode, s.Started}
+	case TopicConversation:
+		v := struct {
+			Length  int      `json:"length"`
+			Message *Message `json:"message"`
+		}{}
+		if c := s.Conversation; c != nil && len(c.Messages) > 0 {
+			v.Length = len(c.Messages)
+			v.Message = &c.Messages[len(c.Messages)-1]
+		}
+		return v
+	}
+	return nil
+}
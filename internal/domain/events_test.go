@@ -0,0 +1,73 @@
+package domain
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestStateEventsArePublishedInOrder(t *testing.T) {
+	st := partyState()
+	var got []StateEvent
+	st.SetEventHook(func(e StateEvent) { got = append(got, e) })
+
+	st.SetLocation("z1", "hall", "Hall")
+	st.MutateCharacter("Alden", func(c *Character) { c.CurrentHP = 3 })
+	st.AddUserMessage("we rest")
+
+	var topics []StateTopic
+	for i, e := range got {
+		if e.Seq != uint64(i+1) {
+			t.Errorf("event %d has seq %d", i, e.Seq)
+		}
+		topics = append(topics, e.Topic)
+	}
+	want := []StateTopic{TopicLog, TopicLocation, TopicParty, TopicConversation}
+	if len(topics) != len(want) {
+		t.Fatalf("topics = %v, want %v", topics, want)
+	}
+	for i := range want {
+		if topics[i] != want[i] {
+			t.Fatalf("topics = %v, want %v", topics, want)
+		}
+	}
+
+	var loc struct {
+		CurrentRoom  string          `json:"current_room"`
+		VisitedRooms map[string]bool `json:"visited_rooms"`
+	}
+	if err := json.Unmarshal(got[1].Data, &loc); err != nil || loc.CurrentRoom != "hall" || !loc.VisitedRooms["hall"] {
+		t.Errorf("location data = %s (%v)", got[1].Data, err)
+	}
+	var conv struct {
+		Length  int     `json:"length"`
+		Message Message `json:"message"`
+	}
+	if err := json.Unmarshal(got[3].Data, &conv); err != nil || conv.Length != 1 || conv.Message.Content != "we rest" {
+		t.Errorf("conversation data = %s (%v)", got[3].Data, err)
+	}
+
+	entries, seq := st.LogAt()
+	if seq != 4 || len(entries) != st.LogLen() {
+		t.Errorf("LogAt = %d entries at seq %d", len(entries), seq)
+	}
+}
+
+// An undo republishes every state topic, so a client holding the state can
+// catch up without refetching it.
+func TestUndoPublishesEveryTopic(t *testing.T) {
+	st := partyState()
+	st.BeginChange("flag")
+	st.SetFlag("door", true)
+	st.CommitChange()
+
+	seen := map[StateTopic]bool{}
+	st.SetEventHook(func(e StateEvent) { seen[e.Topic] = true })
+	if _, err := st.Undo(); err != nil {
+		t.Fatal(err)
+	}
+	for _, topic := range append(stateTopics, TopicLog, TopicRewind) {
+		if !seen[topic] {
+			t.Errorf("undo didn't publish %q", topic)
+		}
+	}
+}
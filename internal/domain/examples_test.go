@@ -38,7 +38,7 @@ func TestExampleAdventuresValidate(t *testing.T) {
 				_, err := os.Stat(filepath.Join(dir, rel))
 				return err == nil
 			}
-			for _, verr := range ValidateAdventure(&a, imageExists) {
+			for _, verr := range ValidateAdventure(&a, imageExists, nil) {
 				t.Errorf("validation: %v", verr)
 			}
 		})
@@ -97,7 +97,7 @@ func TestValidateZoneExitsAndStartRoom(t *testing.T) {
 			{ID: "b", Rooms: []Room{{ID: "b1"}}},
 		},
 	}
-	errs := ValidateAdventure(adv, nil)
+	errs := ValidateAdventure(adv, nil, nil)
 	joined := ""
 	for _, e := range errs {
 		joined += e.Error() + "\n"
@@ -0,0 +1,130 @@
+package domain
+
+import (
+	"fmt"
+	"strings"
+)
+
+// This file implements character advancement by the 5e rules: XP thresholds,
+// and a level-up that grows max HP by a hit die, refreshes the proficiency bonus
+// and, for the supported caster classes, the spell slots and save DC/attack.
+// Like chargen it never rolls: the caller passes the hit-die result (or none, to
+// take the average).
+
+// MaxLevel is the highest character level.
+const MaxLevel = 20
+
+// xpThresholds[level] is the total XP needed to reach that level.
+var xpThresholds = [MaxLevel + 1]int{
+	0, 0, 300, 900, 2700, 6500, 14000, 23000, 34000, 48000, 64000,
+	85000, 100000, 120000, 140000, 165000, 195000, 225000, 265000, 305000, 355000,
+}
+
+// XPForLevel is the total XP needed to reach a level (0 for level 1 or below).
+func XPForLevel(level int) int {
+	return xpThresholds[min(max(level, 0), MaxLevel)]
+}
+
+// LevelForXP is the level a total of XP entitles a character to.
+func LevelForXP(xp int) int {
+	level := 1
+	for level < MaxLevel && xp >= xpThresholds[level+1] {
+		level++
+	}
+	return level
+}
+
+// LevelsPending is how many levels the character's XP has earned beyond its
+// current level.
+func (c *Character) LevelsPending() int {
+	return max(LevelForXP(c.XP)-c.Level, 0)
+}
+
+// HitDieFor is a class's hit die size (8 for a class chargen doesn't know).
+func HitDieFor(class string) int {
+	if ci, ok := classTable[strings.ToLower(strings.TrimSpace(class))]; ok {
+		return ci.hitDie
+	}
+	return 8
+}
+
+// asiLevels are the levels that grant an Ability Score Improvement.
+var asiLevels = map[int]bool{4: true, 8: true, 12: true, 16: true, 19: true}
+
+// LevelUpResult describes one level gained.
+type LevelUpResult struct {
+	Name             string
+	Level            int  // the new level
+	HitDie           int  // die size
+	HitDieRoll       int  // 0 when the average was taken
+	HPGained         int  // after the CON modifier (at least 1)
+	MaxHP            int  // new maximum
+	ProficiencyBonus int  // new proficiency bonus
+	SlotsChanged     bool // spell slots (or pact slots) grew
+	ASI              bool // this level grants an Ability Score Improvement
+}
+
+func (r LevelUpResult) String() string {
+	var b strings.Builder
+	how := "average"
+	if r.HitDieRoll > 0 {
+		how = fmt.Sprintf("rolled %d", r.HitDieRoll)
+	}
+	fmt.Fprintf(&b, "%s reached level %d: +%d HP (d%d %s, with CON) → %d max HP; proficiency +%d; %d hit dice",
+		r.Name, r.Level, r.HPGained, r.HitDie, how, r.MaxHP, r.ProficiencyBonus, r.Level)
+	if r.SlotsChanged {
+		b.WriteString("; new spell slots")
+	}
+	if r.ASI {
+		b.WriteString("; Ability Score Improvement available (+2 to one ability or +1 to two)")
+	}
+	return b.String()
+}
+
+// LevelUp advances the character one level. hitDieRoll is the rolled hit die
+// (1..die size); 0 or less takes the fixed average (die/2+1). Max and current HP
+// both grow by the result plus the CON modifier (at least 1), the proficiency
+// bonus follows the new level, and for a known caster class the slot table and
+// spell save DC/attack are rebuilt, keeping the spellbook and spent slots. Hit
+// dice follow the level (one per level), so the new die is available at once.
+func (c *Character) LevelUp(hitDieRoll int) (LevelUpResult, error) {
+	if c.Level >= MaxLevel {
+		return LevelUpResult{}, fmt.Errorf("%s is already level %d", c.Name, MaxLevel)
+	}
+	die := HitDieFor(c.Class)
+	gain := die/2 + 1
+	if hitDieRoll > 0 {
+		gain = min(hitDieRoll, die)
+	} else {
+		hitDieRoll = 0
+	}
+	gain = max(gain+Modifier(c.Abilities.CON), 1)
+
+	c.Level = max(c.Level, 0) + 1
+	c.MaxHP += gain
+	c.CurrentHP += gain
+	c.ProficiencyBonus = ProficiencyBonusForLevel(c.Level)
+
+	res := LevelUpResult{
+		Name: c.Name, Level: c.Level, HitDie: die, HitDieRoll: hitDieRoll, HPGained: gain,
+		MaxHP: c.MaxHP, ProficiencyBonus: c.ProficiencyBonus, ASI: asiLevels[c.Level],
+	}
+	if ci, ok := classTable[strings.ToLower(strings.TrimSpace(c.Class))]; ok {
+		if sc := buildSpellcasting(ci, c.Level, c.ProficiencyBonus, c.Abilities); sc != nil {
+			if c.Spellcasting == nil {
+				c.Spellcasting = sc
+				res.SlotsChanged = sc.Slots.Max != [9]int{}
+			} else {
+				res.SlotsChanged = c.Spellcasting.Slots.Max != sc.Slots.Max
+				c.Spellcasting.Ability = sc.Ability
+				c.Spellcasting.SaveDC = sc.SaveDC
+				c.Spellcasting.AttackBonus = sc.AttackBonus
+				c.Spellcasting.Slots.Max = sc.Slots.Max
+				for i := range c.Spellcasting.Slots.Used {
+					c.Spellcasting.Slots.Used[i] = min(c.Spellcasting.Slots.Used[i], sc.Slots.Max[i])
+				}
+			}
+		}
+	}
+	return res, nil
+}
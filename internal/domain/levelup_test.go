@@ -0,0 +1,72 @@
+package domain
+
+import "testing"
+
+func TestXPThresholds(t *testing.T) {
+	for xp, want := range map[int]int{0: 1, 299: 1, 300: 2, 899: 2, 900: 3, 6500: 5, 355000: 20, 999999: 20} {
+		if got := LevelForXP(xp); got != want {
+			t.Errorf("LevelForXP(%d) = %d, want %d", xp, got, want)
+		}
+	}
+	if XPForLevel(2) != 300 || XPForLevel(20) != 355000 || XPForLevel(1) != 0 || XPForLevel(99) != 355000 {
+		t.Error("XPForLevel disagrees with the 5e table")
+	}
+	c := NewCharacter("Kael", "Elf", "Wizard")
+	c.XP = 2700
+	if n := c.LevelsPending(); n != 3 {
+		t.Errorf("LevelsPending = %d, want 3", n)
+	}
+}
+
+func TestLevelUpFighter(t *testing.T) {
+	c := GenerateCharacter("Bruna", "Dwarf", "Fighter", 3) // CON 16 → +3
+	maxHP, cur := c.MaxHP, c.CurrentHP-4
+	c.CurrentHP = cur
+	res, err := c.LevelUp(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Average d10 is 6, +3 CON.
+	if res.HPGained != 9 || c.MaxHP != maxHP+9 || c.CurrentHP != cur+9 {
+		t.Errorf("HP after level-up: gained %d, max %d, current %d", res.HPGained, c.MaxHP, c.CurrentHP)
+	}
+	if c.Level != 4 || !res.ASI || c.HitDiceMax() != 4 || c.Spellcasting != nil {
+		t.Errorf("level 4 fighter = %+v (result %+v)", c, res)
+	}
+	c.LevelUp(10)
+	if c.ProficiencyBonus != 3 || c.MaxHP != maxHP+9+13 {
+		t.Errorf("level 5: prof %d, max HP %d", c.ProficiencyBonus, c.MaxHP)
+	}
+	c.Level = MaxLevel
+	if _, err := c.LevelUp(0); err == nil {
+		t.Error("levelling past 20 should fail")
+	}
+}
+
+func TestLevelUpCasterKeepsSpellbook(t *testing.T) {
+	c := GenerateCharacter("Naivara", "Elf", "Wizard", 2)
+	c.AddSpell(Spell{Name: "Magic Missile", Level: 1, Prepared: true})
+	known := len(c.Spellcasting.Spells)
+	c.UseSpellSlot(1)
+	res, err := c.LevelUp(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sc := c.Spellcasting
+	if !res.SlotsChanged || sc.Slots.MaxAt(2) != 2 || sc.Slots.MaxAt(1) != 4 {
+		t.Errorf("level 3 wizard slots = %+v", sc.Slots)
+	}
+	if sc.Slots.RemainingAt(1) != 3 || len(sc.Spells) != known {
+		t.Errorf("level-up should keep spent slots and the spellbook: %+v", sc)
+	}
+
+	// A paladin gains spellcasting at level 2.
+	p := GenerateCharacter("Sora", "Human", "Paladin", 1)
+	if p.Spellcasting.Slots.MaxAt(1) != 0 {
+		t.Fatal("a level 1 paladin has no slots")
+	}
+	p.LevelUp(0)
+	if p.Spellcasting.Slots.MaxAt(1) != 2 {
+		t.Errorf("level 2 paladin slots = %+v", p.Spellcasting.Slots)
+	}
+}
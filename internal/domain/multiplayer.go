@@ -130,6 +130,7 @@ func (s *SessionState) ClaimCharacter(playerID, displayName, charName string) (s
 	s.record(LogEntry{Type: LogParty, Message: fmt.Sprintf("%s now plays %s", displayName, c.Name),
 		Data: map[string]any{"player": playerID, "character": c.Name}})
 	s.touch()
+	s.publish(TopicParty)
 	return c.Name, nil
 }
 
@@ -146,6 +147,7 @@ func (s *SessionState) SetActiveCharacter(playerID, charName string) (string, er
 		if strings.EqualFold(c, strings.TrimSpace(charName)) {
 			slot.Active = c
 			s.touch()
+			s.publish(TopicParty)
 			return c, nil
 		}
 	}
@@ -162,6 +164,7 @@ func (s *SessionState) ReleaseCharacter(playerID string) {
 		s.Round.Actions = filterActions(s.Round.Actions, playerID)
 	}
 	s.touch()
+	s.publish(TopicParty)
 }
 
 // PlayerCharacterName returns a player's ACTIVE character (empty if none). Kept
@@ -242,11 +245,13 @@ func (s *SessionState) SubmitAction(playerID, charName, text string) (RoundActio
 		if s.Round.Actions[i].PlayerID == playerID && strings.EqualFold(s.Round.Actions[i].CharacterName, target) {
 			s.Round.Actions[i] = act
 			s.touch()
+			s.publish(TopicRound)
 			return act, nil
 		}
 	}
 	s.Round.Actions = append(s.Round.Actions, act)
 	s.touch()
+	s.publish(TopicRound)
 	return act, nil
 }
 
@@ -279,6 +284,7 @@ func (s *SessionState) ResetRound() {
 		s.Round.Actions = nil
 	}
 	s.touch()
+	s.publish(TopicRound)
 }
 
 // RemoveResolvedActions drops exactly the given actions (matched by player and
@@ -302,6 +308,7 @@ func (s *SessionState) RemoveResolvedActions(resolved []RoundAction) {
 	}
 	s.Round.Actions = kept
 	s.touch()
+	s.publish(TopicRound)
 }
 
 // PendingPlayers lists the controlled CHARACTERS that have not yet declared an
@@ -332,11 +339,12 @@ func (s *SessionState) PendingPlayers() []string {
 	return pending
 }
 
-// AssignByUsername reserves a party character for a Telegram @username that hasn't
-// picked yet; the binding takes effect when that user next sends a message (see
-// ResolvePending). Fails if the character is unknown, already controlled, or
-// already reserved for a different username. Returns the canonical name.
-func (s *SessionState) AssignByUsername(username, charName string) (string, error) {
+// AssignByUsername reserves a party character for a chat platform's @username
+// that hasn't picked yet; the binding takes effect when that user next sends a
+// message on the same platform (see ResolvePending). Fails if the character is
+// unknown, already controlled, or already reserved for a different username.
+// Returns the canonical name.
+func (s *SessionState) AssignByUsername(platform, username, charName string) (string, error) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 	username = strings.ToLower(strings.TrimPrefix(strings.TrimSpace(username), "@"))
@@ -350,34 +358,42 @@ func (s *SessionState) AssignByUsername(username, charName string) (string, erro
 	if who := s.controlledByOther("", c.Name); who != "" {
 		return "", fmt.Errorf("%s is already controlled by %s", c.Name, who)
 	}
-	for u, pc := range s.PendingAssignments {
-		if u != username && strings.EqualFold(pc, c.Name) {
-			return "", fmt.Errorf("%s is already reserved for @%s", c.Name, u)
+	key := pendingKey(platform, username)
+	for k, pc := range s.PendingAssignments {
+		if k != key && strings.EqualFold(pc, c.Name) {
+			return "", fmt.Errorf("%s is already reserved for @%s", c.Name, pendingUser(k))
 		}
 	}
 	if s.PendingAssignments == nil {
 		s.PendingAssignments = make(map[string]string)
 	}
-	s.PendingAssignments[username] = c.Name
+	s.PendingAssignments[key] = c.Name
 	s.record(LogEntry{Type: LogParty, Message: fmt.Sprintf("Assigned %s to @%s", c.Name, username)})
 	s.touch()
+	s.publish(TopicParty)
 	return c.Name, nil
 }
 
-// ResolvePending binds a pending @username assignment to a real player the first
-// time they appear. Returns the character name and whether a binding happened.
-func (s *SessionState) ResolvePending(playerID, username, display string) (string, bool) {
+// ResolvePending binds a pending @username assignment made on platform to a real
+// player the first time they appear there. Returns the character name and
+// whether a binding happened.
+func (s *SessionState) ResolvePending(playerID, platform, username, display string) (string, bool) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 	username = strings.ToLower(strings.TrimSpace(username))
 	if username == "" || len(s.PendingAssignments) == 0 {
 		return "", false
 	}
-	pc, ok := s.PendingAssignments[username]
+	key := pendingKey(platform, username)
+	pc, ok := s.PendingAssignments[key]
+	if !ok && strings.EqualFold(platform, "telegram") {
+		key = username // reserved before reservations were scoped
+		pc, ok = s.PendingAssignments[key]
+	}
 	if !ok {
 		return "", false
 	}
-	delete(s.PendingAssignments, username)
+	delete(s.PendingAssignments, key)
 	c := s.resolveCharacter(pc)
 	if c == nil {
 		return "", false
@@ -399,6 +415,7 @@ func (s *SessionState) ResolvePending(playerID, username, display string) (strin
 	slot.add(c.Name) // a player may already control other characters (#29)
 	s.record(LogEntry{Type: LogParty, Message: fmt.Sprintf("%s now plays %s (assigned)", display, c.Name)})
 	s.touch()
+	s.publish(TopicParty)
 	return c.Name, true
 }
 
@@ -407,12 +424,29 @@ func (s *SessionState) PendingByCharacter() map[string]string {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 	out := make(map[string]string, len(s.PendingAssignments))
-	for u, pc := range s.PendingAssignments {
-		out[pc] = u
+	for k, pc := range s.PendingAssignments {
+		out[pc] = pendingUser(k)
 	}
 	return out
 }
 
+// pendingKey is a reservation's key in PendingAssignments: the username scoped
+// to its platform ("telegram:ana"). Usernames are only unique within a
+// platform — anyone on IRC can take the nick "ana" — so a reservation made on
+// one must not bind a namesake on another. Keys saved before reservations were
+// scoped are bare usernames, all made on Telegram.
+func pendingKey(platform, username string) string {
+	return strings.ToLower(platform) + ":" + username
+}
+
+// pendingUser is the username a PendingAssignments key reserves for.
+func pendingUser(key string) string {
+	if _, u, ok := strings.Cut(key, ":"); ok {
+		return u
+	}
+	return key
+}
+
 // Controllers returns a map of character name → controlling player's display
 // name, for showing who plays whom.
 func (s *SessionState) Controllers() map[string]string {
@@ -445,6 +479,7 @@ func (s *SessionState) StartGame() bool {
 	s.Started = true
 	s.record(LogEntry{Type: LogSystem, Message: "Game started"})
 	s.touch()
+	s.publish(TopicMode)
 	return true
 }
 
@@ -470,6 +505,7 @@ func (s *SessionState) MarkStartedIfInProgress() {
 	if inProgress {
 		s.Started = true
 		s.touch()
+		s.publish(TopicMode)
 	}
 }
 
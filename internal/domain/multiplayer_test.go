@@ -18,27 +18,31 @@ func TestAssignByUsernameAndResolve(t *testing.T) {
 	st := partyState() // Alden, Naivara
 
 	// Reserve Naivara for @luis (who hasn't picked yet).
-	if _, err := st.AssignByUsername("@Luis", "Naivara"); err != nil {
+	if _, err := st.AssignByUsername("telegram", "@Luis", "Naivara"); err != nil {
 		t.Fatalf("assign: %v", err)
 	}
 	if got := st.PendingByCharacter()["Naivara"]; got != "luis" {
 		t.Errorf("pending for Naivara = %q, want luis", got)
 	}
 	// Can't reserve the same character for someone else.
-	if _, err := st.AssignByUsername("@ana", "naivara"); err == nil {
+	if _, err := st.AssignByUsername("telegram", "@ana", "naivara"); err == nil {
 		t.Error("reserving an already-reserved character should fail")
 	}
 	// Unknown character rejected.
-	if _, err := st.AssignByUsername("@ana", "Gandalf"); err == nil {
+	if _, err := st.AssignByUsername("telegram", "@ana", "Gandalf"); err == nil {
 		t.Error("unknown character should fail")
 	}
 
 	// A message from a different user does not bind Luis's reservation.
-	if _, bound := st.ResolvePending("999", "someoneelse", "Someone"); bound {
+	if _, bound := st.ResolvePending("999", "telegram", "someoneelse", "Someone"); bound {
 		t.Error("unrelated user should not bind the reservation")
 	}
+	// Nor does someone with the same name on another platform.
+	if _, bound := st.ResolvePending("irc:luis", "irc", "luis", "luis"); bound {
+		t.Error("a namesake on IRC should not bind a Telegram reservation")
+	}
 	// Luis appears → bound to Naivara, pending cleared.
-	name, bound := st.ResolvePending("42", "luis", "Luis")
+	name, bound := st.ResolvePending("42", "Telegram", "luis", "Luis")
 	if !bound || name != "Naivara" {
 		t.Fatalf("resolve for luis = (%q,%v), want (Naivara,true)", name, bound)
 	}
@@ -49,9 +53,18 @@ func TestAssignByUsernameAndResolve(t *testing.T) {
 		t.Error("pending assignment should be cleared after binding")
 	}
 	// Second appearance is a no-op (already controls one).
-	if _, bound := st.ResolvePending("42", "luis", "Luis"); bound {
+	if _, bound := st.ResolvePending("42", "Telegram", "luis", "Luis"); bound {
 		t.Error("resolve should be a no-op once the player controls a character")
 	}
+
+	// A reservation saved before they were scoped by platform was made on Telegram.
+	st.PendingAssignments = map[string]string{"ana": "Alden"}
+	if _, bound := st.ResolvePending("irc:ana", "IRC", "ana", "ana"); bound {
+		t.Error("an unscoped reservation bound on IRC")
+	}
+	if name, bound := st.ResolvePending("7", "Telegram", "ana", "Ana"); !bound || name != "Alden" {
+		t.Errorf("unscoped reservation on Telegram = (%q,%v), want (Alden,true)", name, bound)
+	}
 }
 
 func TestGameLifecycle(t *testing.T) {
@@ -0,0 +1,193 @@
+package domain
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// This file implements the opt-in secrecy markers (docs/dm-information-discipline.md):
+// any Feature, Exit, Room, NPC, Event or Item may be marked secret, optionally
+// with a reveal_when condition evaluated against the session. The grounding
+// withholds a secret from the virtual DM until its condition holds, so "reveal
+// only through play" is enforced rather than merely requested.
+
+// Visibility is the secrecy marker embedded in revealable content. Without
+// Secret the content follows the implicit player-facing/DM-only split.
+type Visibility struct {
+	// Secret hides the content from the players. With no RevealWhen it is DM-only
+	// for good; with one, it is withheld until the condition holds.
+	Secret bool `json:"secret,omitempty"`
+	// RevealWhen is "flag:<name>", "event:<id>", "visited:<room-id>" or
+	// "check:<Skill>>=<DC>" (e.g. "check:Investigation>=15").
+	RevealWhen string `json:"reveal_when,omitempty"`
+}
+
+// Reveal condition kinds.
+const (
+	RevealFlag    = "flag"
+	RevealEvent   = "event"
+	RevealVisited = "visited"
+	RevealCheck   = "check"
+)
+
+// RevealCondition is a parsed reveal_when.
+type RevealCondition struct {
+	Kind   string // flag/event/visited/check
+	Target string // the flag, event id, room id, or skill/ability of a check
+	DC     int    // check only
+}
+
+func (c RevealCondition) String() string {
+	if c.Kind == RevealCheck {
+		return fmt.Sprintf("check:%s>=%d", c.Target, c.DC)
+	}
+	return c.Kind + ":" + c.Target
+}
+
+// ParseRevealWhen parses a reveal_when condition. The check target must be a
+// skill or an ability (a raw ability check).
+func ParseRevealWhen(s string) (RevealCondition, error) {
+	kind, target, ok := strings.Cut(strings.TrimSpace(s), ":")
+	kind, target = strings.ToLower(strings.TrimSpace(kind)), strings.TrimSpace(target)
+	if !ok || target == "" {
+		return RevealCondition{}, fmt.Errorf("reveal_when %q: want flag:, event:, visited: or check:Skill>=DC", s)
+	}
+	switch kind {
+	case RevealFlag, RevealEvent, RevealVisited:
+		return RevealCondition{Kind: kind, Target: target}, nil
+	case RevealCheck:
+		name, dc, ok := strings.Cut(target, ">=")
+		n, err := strconv.Atoi(strings.TrimSpace(dc))
+		if !ok || err != nil || n < 1 {
+			return RevealCondition{}, fmt.Errorf("reveal_when %q: a check is written check:Skill>=DC", s)
+		}
+		check, ok := checkName(name)
+		if !ok {
+			return RevealCondition{}, fmt.Errorf("reveal_when %q: unknown skill or ability %q", s, strings.TrimSpace(name))
+		}
+		return RevealCondition{Kind: RevealCheck, Target: check, DC: n}, nil
+	}
+	return RevealCondition{}, fmt.Errorf("reveal_when %q: unknown condition %q (want flag, event, visited or check)", s, kind)
+}
+
+// checkName canonicalizes a skill ("Investigation") or ability ("INT") name.
+func checkName(name string) (string, bool) {
+	if sk, ok := ParseSkill(name); ok {
+		return sk.Name, true
+	}
+	if ab, ok := ParseAbility(name); ok {
+		return ab.String(), true
+	}
+	return "", false
+}
+
+// checkKey indexes SessionState.CheckResults: the check and the room it was
+// rolled in.
+func checkKey(check, room string) string {
+	if c, ok := checkName(check); ok {
+		check = c
+	}
+	return strings.ToLower(strings.TrimSpace(check)) + "@" + room
+}
+
+// RecordCheck remembers the best total rolled for a skill or ability check in a
+// room, so check: reveal conditions can be met. Saves and contests don't count.
+func (s *SessionState) RecordCheck(check, room string, total int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.CheckResults == nil {
+		s.CheckResults = make(map[string]int)
+	}
+	k := checkKey(check, room)
+	if best, ok := s.CheckResults[k]; !ok || total > best {
+		s.CheckResults[k] = total
+		s.touch()
+		s.publish(TopicWorld)
+	}
+}
+
+// IsRevealed reports whether content with the given marker may reach the
+// players' side of the grounding. Unmarked content always may; a secret with no
+// reveal_when never does; otherwise its condition decides. room scopes a check:
+// condition to the room the content is in (a Feature or Exit), so only a check
+// rolled there reveals it; "" accepts a check rolled anywhere. An unparsable
+// condition keeps the secret.
+func (s *SessionState) IsRevealed(v Visibility, room string) bool {
+	if !v.Secret {
+		return true
+	}
+	if strings.TrimSpace(v.RevealWhen) == "" {
+		return false
+	}
+	c, err := ParseRevealWhen(v.RevealWhen)
+	if err != nil {
+		return false
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	switch c.Kind {
+	case RevealFlag:
+		return s.Flags[c.Target]
+	case RevealEvent:
+		return s.TriggeredEvents[c.Target]
+	case RevealVisited:
+		return s.VisitedRooms[c.Target]
+	case RevealCheck:
+		if room != "" {
+			return s.CheckResults[checkKey(c.Target, room)] >= c.DC
+		}
+		prefix := strings.ToLower(c.Target) + "@"
+		for k, total := range s.CheckResults {
+			if strings.HasPrefix(k, prefix) && total >= c.DC {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// validateVisibility checks a marker's reveal_when: that it parses and that the
+// event or room it names exists.
+func validateVisibility(where string, v Visibility, eventIDs, roomIDs map[string]bool, add, warn func(string, ...any)) {
+	if strings.TrimSpace(v.RevealWhen) == "" {
+		return
+	}
+	if !v.Secret {
+		warn("%s: reveal_when has no effect without secret", where)
+	}
+	c, err := ParseRevealWhen(v.RevealWhen)
+	if err != nil {
+		add("%s: %v", where, err)
+		return
+	}
+	switch {
+	case c.Kind == RevealEvent && !eventIDs[c.Target]:
+		add("%s: reveal_when references unknown event %q", where, c.Target)
+	case c.Kind == RevealVisited && !roomIDs[c.Target]:
+		add("%s: reveal_when references unknown room %q", where, c.Target)
+	}
+}
+
+// RoomRevealed reports whether a room may reach the players' side: once the
+// party has been there it is, whatever its marker says.
+func (s *SessionState) RoomRevealed(r *Room) bool {
+	s.mu.Lock()
+	visited := s.VisitedRooms[r.ID]
+	s.mu.Unlock()
+	return visited || s.IsRevealed(r.Visibility, "")
+}
+
+// NPCRevealed reports whether an NPC may reach the players' side: a met NPC is.
+func (s *SessionState) NPCRevealed(n *NPC) bool {
+	return s.NPCKnown(n.ID) || s.IsRevealed(n.Visibility, "")
+}
+
+// EventRevealed reports whether an event may reach the players' side: a
+// triggered event is.
+func (s *SessionState) EventRevealed(e *Event) bool {
+	s.mu.Lock()
+	triggered := s.TriggeredEvents[e.ID]
+	s.mu.Unlock()
+	return triggered || s.IsRevealed(e.Visibility, "")
+}
@@ -0,0 +1,129 @@
+package domain
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseRevealWhen(t *testing.T) {
+	for in, want := range map[string]RevealCondition{
+		"flag:door-opened":          {Kind: RevealFlag, Target: "door-opened"},
+		" Event:bell-rung ":         {Kind: RevealEvent, Target: "bell-rung"},
+		"visited:crypt":             {Kind: RevealVisited, Target: "crypt"},
+		"check:investigation>=15":   {Kind: RevealCheck, Target: "Investigation", DC: 15},
+		"check:Sleight of Hand>=12": {Kind: RevealCheck, Target: "Sleight of Hand", DC: 12},
+		"check:wis >= 10":           {Kind: RevealCheck, Target: "WIS", DC: 10},
+	} {
+		got, err := ParseRevealWhen(in)
+		if err != nil || got != want {
+			t.Errorf("ParseRevealWhen(%q) = %+v, %v; want %+v", in, got, err, want)
+		}
+	}
+	for _, bad := range []string{"", "door-opened", "flag:", "check:Investigation", "check:Juggling>=10", "check:Stealth>=0", "when:midnight"} {
+		if _, err := ParseRevealWhen(bad); err == nil {
+			t.Errorf("ParseRevealWhen(%q) should fail", bad)
+		}
+	}
+	if got := (RevealCondition{Kind: RevealCheck, Target: "Perception", DC: 12}).String(); got != "check:Perception>=12" {
+		t.Errorf("String() = %q", got)
+	}
+}
+
+func TestIsRevealed(t *testing.T) {
+	s := NewSessionState("s", validAdv())
+	if !s.IsRevealed(Visibility{}, "") {
+		t.Error("unmarked content is always revealed")
+	}
+	if s.IsRevealed(Visibility{Secret: true}, "") {
+		t.Error("a secret without reveal_when stays DM-only")
+	}
+	if s.IsRevealed(Visibility{Secret: true, RevealWhen: "nonsense"}, "") {
+		t.Error("an unparsable condition keeps the secret")
+	}
+
+	flag := Visibility{Secret: true, RevealWhen: "flag:lever"}
+	s.SetFlag("lever", true)
+	if !s.IsRevealed(flag, "") {
+		t.Error("flag condition not met after SetFlag")
+	}
+	event := Visibility{Secret: true, RevealWhen: "event:e1"}
+	if s.IsRevealed(event, "") {
+		t.Error("event condition met before the event")
+	}
+	s.TriggerEvent("e1", "Event One")
+	if !s.IsRevealed(event, "") {
+		t.Error("event condition not met after TriggerEvent")
+	}
+	s.SetLocation("z1", "r2", "Room 2")
+	if !s.IsRevealed(Visibility{Secret: true, RevealWhen: "visited:r2"}, "") {
+		t.Error("visited condition not met after entering the room")
+	}
+
+	check := Visibility{Secret: true, RevealWhen: "check:Investigation>=15"}
+	s.RecordCheck("investigation", "r1", 12)
+	if s.IsRevealed(check, "r1") {
+		t.Error("a 12 doesn't meet DC 15")
+	}
+	s.RecordCheck("Investigation", "r2", 17)
+	s.RecordCheck("Investigation", "r2", 3) // a worse roll doesn't lower the best
+	if s.IsRevealed(check, "r1") {
+		t.Error("a check rolled in another room must not reveal a room's secret")
+	}
+	if !s.IsRevealed(check, "r2") || !s.IsRevealed(check, "") {
+		t.Errorf("a 17 in r2 meets DC 15 (results %v)", s.CheckResults)
+	}
+	s.RecordCheck("INT", "r1", 14)
+	if !s.IsRevealed(Visibility{Secret: true, RevealWhen: "check:Intelligence>=14"}, "r1") {
+		t.Error("ability names are canonicalized when recorded and parsed")
+	}
+
+	n := &NPC{ID: "n1", Visibility: Visibility{Secret: true, RevealWhen: "flag:unmasked"}}
+	if s.NPCRevealed(n) {
+		t.Error("secret NPC revealed too early")
+	}
+	s.MeetNPC("n1", "NPC One")
+	if !s.NPCRevealed(n) {
+		t.Error("a met NPC is revealed")
+	}
+	r := &Room{ID: "r2", Visibility: Visibility{Secret: true}}
+	if !s.RoomRevealed(r) {
+		t.Error("a visited room is revealed")
+	}
+}
+
+func TestValidateRevealWhen(t *testing.T) {
+	a := validAdv()
+	r := &a.Zones[0].Rooms[0]
+	r.Features = []Feature{{Name: "Loose stone", Visibility: Visibility{Secret: true, RevealWhen: "check:Investigation>=15"}}}
+	r.Exits[0].Visibility = Visibility{Secret: true, RevealWhen: "event:e1"}
+	a.Zones[0].Rooms[1].Visibility = Visibility{Secret: true, RevealWhen: "visited:r1"}
+	a.NPCs[0].Visibility = Visibility{Secret: true}
+	a.Events[0].Visibility = Visibility{Secret: true, RevealWhen: "flag:anything"}
+	if errs := ValidateAdventure(a, nil, nil); len(errs) != 0 {
+		t.Fatalf("expected valid markers, got %v", errs)
+	}
+
+	r.Features[0].RevealWhen = "check:Juggling>=15"
+	r.Exits[0].RevealWhen = "event:nope"
+	a.Zones[0].Rooms[1].RevealWhen = "visited:nowhere"
+	a.Items = []Item{{ID: "i1", Name: "Ring", Visibility: Visibility{RevealWhen: "flag:x"}}}
+	errs := ValidateAdventure(a, nil, nil)
+	var msgs []string
+	for _, e := range errs {
+		msgs = append(msgs, e.Error())
+	}
+	all := strings.Join(msgs, "\n")
+	for _, want := range []string{
+		`feature "Loose stone": reveal_when "check:Juggling>=15": unknown skill or ability "Juggling"`,
+		`room "r1": exit to "r2": reveal_when references unknown event "nope"`,
+		`room "r2": reveal_when references unknown room "nowhere"`,
+		`warning: item "i1": reveal_when has no effect without secret`,
+	} {
+		if !strings.Contains(all, want) {
+			t.Errorf("missing %q in:\n%s", want, all)
+		}
+	}
+	if len(ValidationErrors(errs)) != 3 {
+		t.Errorf("want 3 errors and a warning, got %v", errs)
+	}
+}
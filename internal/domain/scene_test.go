@@ -50,7 +50,7 @@ func TestSceneHelpers(t *testing.T) {
 
 func TestValidateScenes(t *testing.T) {
 	a := sceneAdventure()
-	if errs := ValidateAdventure(a, nil); len(errs) != 0 {
+	if errs := ValidateAdventure(a, nil, nil); len(errs) != 0 {
 		t.Fatalf("valid scene adventure reported errors: %v", errs)
 	}
 
@@ -60,7 +60,7 @@ func TestValidateScenes(t *testing.T) {
 	bad.Scenes[1].Initial = true // two initials
 	bad.Scenes[1].Rooms = []SceneRoom{{Room: "ghostroom", NPCIDs: []string{"ghostnpc"}}}
 	bad.Scenes[1].Next = []SceneTransition{{To: "nowhere"}}
-	errs := ValidateAdventure(bad, nil)
+	errs := ValidateAdventure(bad, nil, nil)
 	joined := ""
 	for _, e := range errs {
 		joined += e.Error() + "\n"
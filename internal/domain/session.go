@@ -106,6 +106,7 @@ const (
 	LogSystem   LogEntryType = "system"   // system message
 	LogChat     LogEntryType = "chat"     // in-character player dialogue (context, not an action)
 	LogWorld    LogEntryType = "world"    // DM-recorded consequence changing the authored world
+	LogCombat   LogEntryType = "combat"   // combat started/ended, turn advanced
 )
 
 // LogEntry is a single event in the running session timeline — either a
@@ -259,13 +260,27 @@ type SessionState struct {
 	// turn until the DM resolves it. Both are empty in single-player use.
 	Players map[string]*PlayerSlot `json:"players,omitempty"`
 	Round   *TurnRound             `json:"round,omitempty"`
+	// Combat is the tracked fight (initiative order, round, current turn); nil
+	// outside combat.
+	Combat *CombatState `json:"combat,omitempty"`
+	// Creatures are the spawned monster instances ("Goblin #1"..), each with its
+	// own HP and conditions, in or out of a tracked fight.
+	Creatures []CreatureInstance `json:"creatures,omitempty"`
+	// CheckResults keeps the best skill/ability check total per room
+	// ("investigation@crypt" → 17), for check: reveal conditions.
+	CheckResults map[string]int `json:"check_results,omitempty"`
 	// Started marks that the game has begun (the DM gave the opening scene). Before
 	// it is set, a multiplayer front-end accepts only setup/start commands.
 	Started bool `json:"started,omitempty"`
-	// PendingAssignments reserves a character for a Telegram @username (lower-cased)
-	// that hasn't picked yet; it binds to the real player when they next message.
+	// PendingAssignments reserves a character for a chat @username that hasn't
+	// picked yet, keyed "<platform>:<username>" (lower-cased); it binds to the
+	// real player when they next message on that platform.
 	PendingAssignments map[string]string `json:"pending_assignments,omitempty"`
 
+	// Usage is the session's model usage by provider, model and purpose, for
+	// cost estimates and the token budget.
+	Usage UsageLedger `json:"usage,omitempty"`
+
 	// Free-form timeline and running summary.
 	Log     *SessionLog `json:"log"`
 	Summary string      `json:"summary,omitempty"`
@@ -282,6 +297,15 @@ type SessionState struct {
 	// unexported and therefore never serialized.
 	onLog func(LogEntry)
 
+	// onEvent, when set, receives every state change as a StateEvent (see
+	// events.go); eventSeq numbers them. Unexported, never serialized.
+	onEvent  func(StateEvent)
+	eventSeq uint64
+
+	// changes is the undo/redo history of turns and commands (see undo.go); it
+	// lives only in memory, so a reloaded session starts with none.
+	changes changeHistory
+
 	// mu guards concurrent mutation and serialization of the state. The oracle
 	// runs in its own goroutine mutating the state through the tool router while a
 	// frontend may read/serialize it (autosave); every exported mutator and the
@@ -362,6 +386,7 @@ func (s *SessionState) record(e LogEntry) {
 	if s.onLog != nil {
 		s.onLog(e)
 	}
+	s.publishData(TopicLog, e)
 }
 
 // NewSessionState creates a fresh session for an adventure, defaulting the
@@ -404,7 +429,10 @@ func NewSessionState(name string, adv *Adventure) *SessionState {
 	return s
 }
 
-func (s *SessionState) touch() { s.UpdatedAt = time.Now() }
+func (s *SessionState) touch() {
+	s.UpdatedAt = time.Now()
+	s.changes.touched()
+}
 
 // SetLocation records the party's current zone and room, marking the room
 // visited and logging the move.
@@ -432,6 +460,7 @@ func (s *SessionState) SetLocation(zoneID, roomID, roomName string) {
 	s.record(LogEntry{Type: LogLocation, Message: "Entered " + label,
 		Data: map[string]any{"zone": zoneID, "room": roomID}})
 	s.touch()
+	s.publish(TopicLocation)
 }
 
 // Scene returns the active scene id.
@@ -455,6 +484,7 @@ func (s *SessionState) SetScene(id, name string) {
 	s.record(LogEntry{Type: LogSystem, Message: "Scene: " + label,
 		Data: map[string]any{"scene": id}})
 	s.touch()
+	s.publish(TopicLocation)
 }
 
 // MeetNPC marks an NPC as met (creating status if needed) and returns it.
@@ -476,6 +506,7 @@ func (s *SessionState) MeetNPC(id, name string) *NPCStatus {
 			Data: map[string]any{"npc": id}})
 	}
 	s.touch()
+	s.publish(TopicNPC)
 	return st
 }
 
@@ -514,6 +545,7 @@ func (s *SessionState) SetNPCDisposition(id, disposition string) {
 	s.record(LogEntry{Type: LogNPC, Message: id + " disposition → " + disposition,
 		Data: map[string]any{"npc": id}})
 	s.touch()
+	s.publish(TopicNPC)
 }
 
 // SetNPCAlive records whether an NPC is alive under the lock.
@@ -528,6 +560,7 @@ func (s *SessionState) SetNPCAlive(id string, alive bool) {
 	s.record(LogEntry{Type: LogNPC, Message: id + " is now " + status,
 		Data: map[string]any{"npc": id}})
 	s.touch()
+	s.publish(TopicNPC)
 }
 
 // migratePC moves a legacy single PC into the party. Caller holds s.mu.
@@ -569,6 +602,7 @@ func (s *SessionState) MutateCharacter(name string, fn func(*Character)) (string
 	}
 	fn(c)
 	s.touch()
+	s.publish(TopicParty)
 	return c.Name, true
 }
 
@@ -577,6 +611,8 @@ func (s *SessionState) AddUserMessage(content string) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 	s.Conversation.AddUserMessage(content)
+	s.touch()
+	s.publish(TopicConversation)
 }
 
 // AddAssistantMessage appends an assistant message to the conversation under the
@@ -585,6 +621,8 @@ func (s *SessionState) AddAssistantMessage(content string) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 	s.Conversation.AddAssistantMessage(content)
+	s.touch()
+	s.publish(TopicConversation)
 }
 
 // RecentLog returns a copy of the last n timeline entries under the lock, so a
@@ -629,6 +667,12 @@ func (s *SessionState) ImportStructured(src *SessionState) {
 	s.Quests = src.Quests
 	s.Characters = src.Characters
 	s.PC = src.PC
+	s.Combat = src.Combat
+	s.Creatures = src.Creatures
+	s.CheckResults = src.CheckResults
+	for _, t := range []StateTopic{TopicLocation, TopicNPC, TopicParty, TopicRound, TopicWorld} {
+		s.publish(t)
+	}
 }
 
 // TriggerEvent records that a scripted event has fired.
@@ -646,6 +690,7 @@ func (s *SessionState) TriggerEvent(id, name string) {
 	s.record(LogEntry{Type: LogEvent, Message: "Triggered event: " + label,
 		Data: map[string]any{"event": id}})
 	s.touch()
+	s.publish(TopicWorld)
 }
 
 // SetFlag sets a boolean flag and logs it.
@@ -656,6 +701,7 @@ func (s *SessionState) SetFlag(key string, value bool) {
 	s.record(LogEntry{Type: LogFlag, Message: "Flag " + key + " set",
 		Data: map[string]any{"key": key, "value": value}})
 	s.touch()
+	s.publish(TopicWorld)
 }
 
 // SetVariable sets a string variable and logs it.
@@ -666,6 +712,7 @@ func (s *SessionState) SetVariable(key, value string) {
 	s.record(LogEntry{Type: LogFlag, Message: "Variable " + key + " = " + value,
 		Data: map[string]any{"key": key, "value": value}})
 	s.touch()
+	s.publish(TopicWorld)
 }
 
 // RecordWorldChange appends a DM-recorded consequence to an authored entity,
@@ -701,6 +748,7 @@ func (s *SessionState) RecordWorldChange(target, label, change string) bool {
 	s.record(LogEntry{Type: LogWorld, Message: "World change (" + name + "): " + change,
 		Data: map[string]any{"target": target}})
 	s.touch()
+	s.publish(TopicWorld)
 	return true
 }
 
@@ -734,6 +782,7 @@ func (s *SessionState) SetWorldDescription(target, desc string) (string, bool) {
 		s.record(LogEntry{Type: LogWorld, Message: "World description cleared (" + target + ")",
 			Data: map[string]any{"target": target}})
 		s.touch()
+		s.publish(TopicWorld)
 		return "", false
 	}
 	s.WorldDescriptions[target] = clean
@@ -742,6 +791,7 @@ func (s *SessionState) SetWorldDescription(target, desc string) (string, bool) {
 	s.record(LogEntry{Type: LogWorld, Message: "World description updated (" + target + ")",
 		Data: map[string]any{"target": target}})
 	s.touch()
+	s.publish(TopicWorld)
 	return clean, true
 }
 
@@ -822,6 +872,7 @@ func (s *SessionState) RestParty(long bool, characterName string, shortDice int)
 	}
 	s.record(LogEntry{Type: LogParty, Message: summary})
 	s.touch()
+	s.publish(TopicParty)
 	return summary
 }
 
@@ -837,12 +888,14 @@ func (s *SessionState) AdvanceQuest(id, name, status string) {
 			}
 			s.record(LogEntry{Type: LogQuest, Message: "Quest '" + s.Quests[i].Name + "' → " + status})
 			s.touch()
+			s.publish(TopicWorld)
 			return
 		}
 	}
 	s.Quests = append(s.Quests, QuestProgress{ID: id, Name: name, Status: status})
 	s.record(LogEntry{Type: LogQuest, Message: "New quest: " + name})
 	s.touch()
+	s.publish(TopicWorld)
 }
 
 // effectiveMode is the lock-free core of EffectiveMode, for callers that already
@@ -877,6 +930,7 @@ func (s *SessionState) SetMode(m SessionMode) {
 	s.record(LogEntry{Type: LogSystem, Message: "Mode switched to " + label,
 		Data: map[string]any{"mode": string(m)}})
 	s.touch()
+	s.publish(TopicMode)
 }
 
 // ToggleMode flips between assistant and virtual-DM mode and returns the new
@@ -903,6 +957,7 @@ func (s *SessionState) EnsureParty() bool {
 		return false
 	}
 	s.Characters = DefaultParty()
+	s.publish(TopicParty)
 	return true
 }
 
@@ -921,6 +976,7 @@ func (s *SessionState) SetParty(party []*Character) {
 	}
 	s.record(LogEntry{Type: LogParty, Message: "Party set: " + strings.Join(names, ", ")})
 	s.touch()
+	s.publish(TopicParty)
 }
 
 // LinkRosterIDs assigns roster IDs to party members by position (ids[i] → the
@@ -941,6 +997,7 @@ func (s *SessionState) LinkRosterIDs(ids []string) {
 	}
 	if changed {
 		s.touch()
+		s.publish(TopicParty)
 	}
 }
 
@@ -1024,6 +1081,7 @@ func (s *SessionState) UpsertPartyMember(name string, currentHP, maxHP, ac *int,
 	}
 	s.record(LogEntry{Type: LogParty, Message: "Updated " + name})
 	s.touch()
+	s.publish(TopicParty)
 }
 
 // Session is the runtime wrapper binding a persisted SessionState to its loaded
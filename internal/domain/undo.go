@@ -0,0 +1,267 @@
+package domain
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+)
+
+// undoDepth bounds how many change sets can be undone (and redone). Each holds
+// a full copy of the state, so the history stays short.
+const undoDepth = 10
+
+// ChangeSet is one undoable step: an oracle turn or a DM command, begun At,
+// with the mutations it made. Changes names them in order — the tools the
+// oracle called, or the command — leaving out steps that changed nothing.
+type ChangeSet struct {
+	Label   string    `json:"label"`
+	At      time.Time `json:"at"`
+	Changes []string  `json:"changes,omitempty"`
+
+	// state is the session as it was on the other side of the step: before it
+	// on the undo stack, after it on the redo stack.
+	state []byte
+}
+
+// changeHistory is the undo and redo stacks, and the change set being recorded.
+// Guarded by SessionState.mu.
+type changeHistory struct {
+	undo, redo []ChangeSet
+
+	open  *ChangeSet
+	busy  bool       // a change set is open (open is nil when it can't be recorded)
+	idle  *sync.Cond // signalled when the open change set closes; on SessionState.mu
+	dirty bool       // the open set changed the state
+	step  bool       // the state changed during the current TrackChange
+}
+
+// touched notes a mutation for the open change set; called by touch.
+func (h *changeHistory) touched() {
+	if h.open != nil {
+		h.dirty, h.step = true, true
+	}
+}
+
+// BeginChange opens a change set for a turn or command, capturing the state it
+// starts from. One set is open at a time: a set restores the whole state it
+// began from, so a command or sheet edit from another player mustn't land in
+// the middle of a turn, where undoing the turn would revert it too. BeginChange
+// waits until the open set is committed; a caller must not nest them.
+func (s *SessionState) BeginChange(label string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	h := &s.changes
+	if h.idle == nil {
+		h.idle = sync.NewCond(&s.mu)
+	}
+	for h.busy {
+		h.idle.Wait()
+	}
+	h.busy = true
+	h.dirty, h.step = false, false
+	b, err := s.snapshot()
+	if err != nil {
+		h.open = nil // unrecordable: the step just won't be undoable
+		return
+	}
+	h.open = &ChangeSet{Label: label, At: time.Now(), state: b}
+}
+
+// TrackChange runs fn, a step of the open change set — a tool call, a command
+// — and names it what among the set's changes if it changed the state. Outside
+// a change set it just runs fn.
+func (s *SessionState) TrackChange(what string, fn func()) {
+	s.mu.Lock()
+	s.changes.step = false
+	s.mu.Unlock()
+	fn()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if h := &s.changes; h.open != nil && h.step {
+		h.open.Changes = append(h.open.Changes, what)
+	}
+}
+
+// CommitChange closes the change set opened by BeginChange, letting the next
+// one begin. A set that changed the state becomes the one to undo next, and
+// clears the redo stack; one that changed nothing is dropped.
+func (s *SessionState) CommitChange() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	h := &s.changes
+	if !h.busy {
+		return
+	}
+	h.busy = false
+	h.idle.Broadcast()
+	set := h.open
+	h.open = nil
+	if set == nil || !h.dirty {
+		return
+	}
+	h.undo = pushChange(h.undo, *set)
+	h.redo = nil
+}
+
+// UndoLabel and RedoLabel name what the next Undo or Redo would roll, or ""
+// when there is nothing to.
+func (s *SessionState) UndoLabel() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if n := len(s.changes.undo); n > 0 {
+		return s.changes.undo[n-1].Label
+	}
+	return ""
+}
+
+func (s *SessionState) RedoLabel() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if n := len(s.changes.redo); n > 0 {
+		return s.changes.redo[n-1].Label
+	}
+	return ""
+}
+
+// Undo rolls the latest change set back: the structured state, the timeline
+// and the conversation return to where they were before it, as one step. The
+// table's bookkeeping is left alone — seats, reservations, usage, and actions
+// players declared since — and an entry saying what was undone is logged. It
+// fails while a change set is open (a turn is running) or when there is
+// nothing to undo.
+func (s *SessionState) Undo() (ChangeSet, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	h := &s.changes
+	if h.busy {
+		return ChangeSet{}, fmt.Errorf("a turn is in progress; undo once it finishes")
+	}
+	if len(h.undo) == 0 {
+		return ChangeSet{}, fmt.Errorf("nothing to undo")
+	}
+	set, err := s.roll(h.undo[len(h.undo)-1], "Undone: ")
+	if err != nil {
+		return ChangeSet{}, err
+	}
+	h.undo = h.undo[:len(h.undo)-1]
+	h.redo = pushChange(h.redo, set)
+	return set, nil
+}
+
+// Redo re-applies the change set Undo rolled back last, as Undo does the
+// reverse. Any new change set clears what there was to redo.
+func (s *SessionState) Redo() (ChangeSet, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	h := &s.changes
+	if h.busy {
+		return ChangeSet{}, fmt.Errorf("a turn is in progress; redo once it finishes")
+	}
+	if len(h.redo) == 0 {
+		return ChangeSet{}, fmt.Errorf("nothing to redo")
+	}
+	set, err := s.roll(h.redo[len(h.redo)-1], "Redone: ")
+	if err != nil {
+		return ChangeSet{}, err
+	}
+	h.redo = h.redo[:len(h.redo)-1]
+	h.undo = pushChange(h.undo, set)
+	return set, nil
+}
+
+// roll restores set's state and returns set holding the state it replaced, for
+// the opposite stack. Caller holds s.mu.
+func (s *SessionState) roll(set ChangeSet, verb string) (ChangeSet, error) {
+	current, err := s.snapshot()
+	if err != nil {
+		return ChangeSet{}, err
+	}
+	var prev SessionState
+	if err := json.Unmarshal(set.state, &prev); err != nil {
+		return ChangeSet{}, fmt.Errorf("restore: %w", err)
+	}
+	s.restore(&prev, set.At)
+	logLen := 0
+	if s.Log != nil {
+		logLen = s.Log.Len()
+	}
+	s.publishData(TopicRewind, struct {
+		LogLength int `json:"log_length"`
+	}{logLen})
+	s.record(LogEntry{Type: LogSystem, Message: verb + set.Label})
+	s.touch()
+	for _, t := range stateTopics {
+		s.publish(t)
+	}
+	set.state = current
+	return set, nil
+}
+
+// restore replaces what a turn or command can change with src's, keeping the
+// table's bookkeeping (name, seats, reservations, usage, timestamps). Round
+// keeps the actions declared since the step began. Caller holds s.mu.
+func (s *SessionState) restore(src *SessionState, since time.Time) {
+	s.CurrentZone = src.CurrentZone
+	s.CurrentRoom = src.CurrentRoom
+	s.CurrentScene = src.CurrentScene
+	s.VisitedRooms = src.VisitedRooms
+	s.KnownNPCs = src.KnownNPCs
+	s.TriggeredEvents = src.TriggeredEvents
+	s.Flags = src.Flags
+	s.Variables = src.Variables
+	s.Party = src.Party
+	s.Quests = src.Quests
+	s.WorldEdits = src.WorldEdits
+	s.WorldDescriptions = src.WorldDescriptions
+	s.Mode = src.Mode
+	s.Characters = src.Characters
+	s.PC = src.PC
+	s.Round = mergeRound(src.Round, s.Round, since)
+	s.Combat = src.Combat
+	s.Creatures = src.Creatures
+	s.CheckResults = src.CheckResults
+	s.Started = src.Started
+	s.Log = src.Log
+	s.Summary = src.Summary
+	s.Conversation = src.Conversation
+}
+
+// mergeRound is the restored round buffer with the actions declared after
+// since added back, each replacing the restored action of its character: what
+// players declared during or after an undone turn survives it, and the actions
+// the turn resolved come back.
+func mergeRound(restored, current *TurnRound, since time.Time) *TurnRound {
+	out := &TurnRound{}
+	if restored != nil {
+		out.Actions = append(out.Actions, restored.Actions...)
+	}
+	if current != nil {
+		for _, a := range current.Actions {
+			if !a.At.After(since) {
+				continue
+			}
+			out.Actions = slices.DeleteFunc(out.Actions, func(r RoundAction) bool { return r.CharacterName == a.CharacterName })
+			out.Actions = append(out.Actions, a)
+		}
+	}
+	if len(out.Actions) == 0 {
+		return nil
+	}
+	return out
+}
+
+// snapshot serializes the state. Caller holds s.mu.
+func (s *SessionState) snapshot() ([]byte, error) {
+	type alias SessionState
+	return json.Marshal((*alias)(s))
+}
+
+func pushChange(stack []ChangeSet, set ChangeSet) []ChangeSet {
+	stack = append(stack, set)
+	if len(stack) > undoDepth {
+		stack = append(stack[:0:0], stack[len(stack)-undoDepth:]...)
+	}
+	return stack
+}
@@ -0,0 +1,165 @@
+package domain
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestUndoRestoresStateLogAndConversation(t *testing.T) {
+	st := partyState()
+	st.SetLocation("z1", "gate", "Gate")
+	logLen := st.LogLen()
+
+	st.BeginChange("Turn: we go in")
+	st.AddUserMessage("we go in")
+	st.TrackChange("set_location", func() { st.SetLocation("z1", "hall", "Hall") })
+	st.TrackChange("list_exits", func() {})
+	st.SetFlag("door", true) // outside any TrackChange: in the set, unnamed
+	st.RecordUsage(UsageRecord{Provider: "p", Purpose: PurposeOracle, TotalTokens: 50})
+	st.AddAssistantMessage("You enter the hall.")
+	st.CommitChange()
+
+	if got := st.UndoLabel(); got != "Turn: we go in" {
+		t.Fatalf("undo label = %q", got)
+	}
+	set, err := st.Undo()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(set.Changes) != 1 || set.Changes[0] != "set_location" {
+		t.Errorf("changes = %v", set.Changes)
+	}
+	if _, room := st.Location(); room != "gate" || st.VisitedRooms["hall"] || st.Flags["door"] {
+		t.Errorf("room %q, visited %v, flags %v", room, st.VisitedRooms, st.Flags)
+	}
+	if st.Conversation.Len() != 0 || st.LogLen() != logLen+1 || st.RecentLog(1)[0].Message != "Undone: Turn: we go in" {
+		t.Errorf("conversation %d, log %+v", st.Conversation.Len(), st.RecentLog(2))
+	}
+	if st.TokensUsed() != 50 {
+		t.Errorf("usage was rolled back: %d tokens", st.TokensUsed())
+	}
+	if st.UndoLabel() != "" || st.RedoLabel() != "Turn: we go in" {
+		t.Errorf("labels = %q / %q", st.UndoLabel(), st.RedoLabel())
+	}
+
+	if _, err := st.Redo(); err != nil {
+		t.Fatal(err)
+	}
+	if _, room := st.Location(); room != "hall" || !st.Flags["door"] || st.Conversation.Len() != 2 {
+		t.Errorf("redo: room %q, flags %v, %d messages", room, st.Flags, st.Conversation.Len())
+	}
+	if _, err := st.Redo(); err == nil {
+		t.Error("nothing should be left to redo")
+	}
+}
+
+func TestUndoRefusedDuringATurn(t *testing.T) {
+	st := partyState()
+	st.BeginChange("first")
+	st.SetFlag("a", true)
+	st.CommitChange()
+
+	st.BeginChange("second")
+	if _, err := st.Undo(); err == nil {
+		t.Error("undo while a change set is open should fail")
+	}
+	st.CommitChange() // changed nothing: not recorded
+	if st.UndoLabel() != "first" {
+		t.Errorf("undo label = %q", st.UndoLabel())
+	}
+}
+
+// TestChangeSetsTakeTurns interleaves two mutations, as a player's sheet edit
+// arriving while the DM's turn streams: the second waits for the first to
+// commit, so each is its own step and undoing the turn leaves the edit alone.
+func TestChangeSetsTakeTurns(t *testing.T) {
+	st := partyState()
+	st.BeginChange("Turn: we go in")
+	st.SetFlag("door", true)
+
+	edited := make(chan struct{})
+	go func() {
+		defer close(edited)
+		st.BeginChange("Alden: /hp")
+		st.SetFlag("bandaged", true)
+		st.CommitChange()
+	}()
+	select {
+	case <-edited:
+		t.Fatal("a change set began while another was open")
+	case <-time.After(50 * time.Millisecond):
+	}
+	st.SetFlag("hall", true)
+	st.CommitChange()
+	<-edited
+
+	if got := st.UndoLabel(); got != "Alden: /hp" {
+		t.Fatalf("undo label = %q; want the edit, committed last", got)
+	}
+	if _, err := st.Undo(); err != nil {
+		t.Fatal(err)
+	}
+	if st.Flags["bandaged"] || !st.Flags["door"] || !st.Flags["hall"] {
+		t.Errorf("after undoing the edit, flags = %v", st.Flags)
+	}
+	if _, err := st.Undo(); err != nil {
+		t.Fatal(err)
+	}
+	if st.Flags["door"] || st.Flags["hall"] {
+		t.Errorf("after undoing the turn, flags = %v", st.Flags)
+	}
+}
+
+func TestUndoHistoryIsBounded(t *testing.T) {
+	st := partyState()
+	for i := 0; i < undoDepth+5; i++ {
+		st.BeginChange(fmt.Sprint("step ", i))
+		st.SetFlag(fmt.Sprint("f", i), true)
+		st.CommitChange()
+	}
+	n := 0
+	for ; ; n++ {
+		if _, err := st.Undo(); err != nil {
+			break
+		}
+	}
+	if n != undoDepth || !st.Flags["f4"] || st.Flags["f5"] {
+		t.Errorf("undid %d steps, flags %v", n, st.Flags)
+	}
+}
+
+// Undoing a round brings back the actions it resolved, and keeps an action
+// declared after it.
+func TestUndoKeepsTheRound(t *testing.T) {
+	st := partyState()
+	if _, err := st.ClaimCharacter("1", "Ana", "Alden"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := st.ClaimCharacter("2", "Luis", "Naivara"); err != nil {
+		t.Fatal(err)
+	}
+	st.SubmitAction("1", "", "I open the door")
+	st.SubmitAction("2", "", "I cast light")
+	resolved := st.RoundActions()
+
+	st.BeginChange("Round")
+	st.SetFlag("door", true)
+	st.RemoveResolvedActions(resolved)
+	st.CommitChange()
+	st.SubmitAction("2", "", "I read the runes")
+
+	if _, err := st.Undo(); err != nil {
+		t.Fatal(err)
+	}
+	got := map[string]string{}
+	for _, a := range st.RoundActions() {
+		got[a.CharacterName] = a.Text
+	}
+	if len(got) != 2 || got["Alden"] != "I open the door" || got["Naivara"] != "I read the runes" {
+		t.Errorf("round after undo = %v", got)
+	}
+	if st.PlayerCount() != 2 {
+		t.Errorf("players = %d, want the seats kept", st.PlayerCount())
+	}
+}
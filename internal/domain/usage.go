@@ -0,0 +1,211 @@
+package domain
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// UsagePurpose tells what a model call was made for, so the usage ledger can
+// say where a session's tokens went.
+type UsagePurpose string
+
+const (
+	PurposeOracle       UsagePurpose = "oracle"
+	PurposeSummary      UsagePurpose = "summary"
+	PurposeSpoilerGuard UsagePurpose = "spoiler_guard"
+	PurposeNovel        UsagePurpose = "novel"
+	PurposeImport       UsagePurpose = "import"
+	PurposePlaytest     UsagePurpose = "playtest" // simulated players' actions
+)
+
+// UsageRecord is the accumulated usage of one provider, model and purpose.
+type UsageRecord struct {
+	Provider         string       `json:"provider"`
+	Model            string       `json:"model,omitempty"`
+	Purpose          UsagePurpose `json:"purpose"`
+	Calls            int          `json:"calls"`
+	PromptTokens     int          `json:"prompt_tokens"`
+	CompletionTokens int          `json:"completion_tokens"`
+	TotalTokens      int          `json:"total_tokens"`
+	// Prompt tokens read from or written to a provider's prompt cache, apart
+	// from PromptTokens and part of TotalTokens.
+	CacheReadTokens  int `json:"cache_read_tokens,omitempty"`
+	CacheWriteTokens int `json:"cache_write_tokens,omitempty"`
+}
+
+// UsageLedger is the model usage of a session (or an import), one record per
+// provider, model and purpose.
+type UsageLedger []UsageRecord
+
+// Add folds one call's usage into the ledger. A record without a total counts
+// its prompt, completion and cache tokens; Calls defaults to one.
+func (l *UsageLedger) Add(r UsageRecord) {
+	if r.TotalTokens == 0 {
+		r.TotalTokens = r.PromptTokens + r.CompletionTokens + r.CacheReadTokens + r.CacheWriteTokens
+	}
+	if r.Calls == 0 {
+		r.Calls = 1
+	}
+	for i := range *l {
+		e := &(*l)[i]
+		if e.Provider == r.Provider && e.Model == r.Model && e.Purpose == r.Purpose {
+			e.Calls += r.Calls
+			e.PromptTokens += r.PromptTokens
+			e.CompletionTokens += r.CompletionTokens
+			e.TotalTokens += r.TotalTokens
+			e.CacheReadTokens += r.CacheReadTokens
+			e.CacheWriteTokens += r.CacheWriteTokens
+			return
+		}
+	}
+	*l = append(*l, r)
+}
+
+// Tokens returns the ledger's total token count.
+func (l UsageLedger) Tokens() int {
+	n := 0
+	for _, r := range l {
+		n += r.TotalTokens
+	}
+	return n
+}
+
+// ModelPrice is what a model costs, in US dollars per million tokens. Prompt
+// cache reads and writes cost the input price unless priced on their own.
+type ModelPrice struct {
+	Input      float64 `json:"input"`
+	Output     float64 `json:"output"`
+	CacheRead  float64 `json:"cache_read,omitempty" yaml:"cache_read,omitempty"`
+	CacheWrite float64 `json:"cache_write,omitempty" yaml:"cache_write,omitempty"`
+}
+
+// Cost prices a usage record.
+func (p ModelPrice) Cost(r UsageRecord) float64 {
+	read, write := p.CacheRead, p.CacheWrite
+	if read == 0 {
+		read = p.Input
+	}
+	if write == 0 {
+		write = p.Input
+	}
+	return (float64(r.PromptTokens)*p.Input + float64(r.CompletionTokens)*p.Output +
+		float64(r.CacheReadTokens)*read + float64(r.CacheWriteTokens)*write) / 1e6
+}
+
+// TokenBudget caps a session's token use. Past Soft the oracle warns once; at
+// Hard it refuses further turns. Zero disables either limit.
+type TokenBudget struct {
+	Soft int `json:"soft,omitempty"`
+	Hard int `json:"hard,omitempty"`
+}
+
+// DefaultPricing is the price table a config starts with: list prices for the
+// default models at the time of writing. They are estimates; config.yaml
+// overrides or extends them.
+func DefaultPricing() map[string]ModelPrice {
+	return map[string]ModelPrice{
+		"gpt-4o":           {Input: 2.50, Output: 10},
+		"gpt-4o-mini":      {Input: 0.15, Output: 0.60},
+		"claude-sonnet":    {Input: 3, Output: 15, CacheRead: 0.30, CacheWrite: 3.75},
+		"claude-haiku":     {Input: 1, Output: 5, CacheRead: 0.10, CacheWrite: 1.25},
+		"gemini-2.5-flash": {Input: 0.30, Output: 2.50},
+		"gemini-2.5-pro":   {Input: 1.25, Output: 10},
+	}
+}
+
+// PriceFor returns the price of a model from a price table: the entry named
+// exactly, else the longest entry the model name starts with ("claude-sonnet"
+// prices "claude-sonnet-5").
+func PriceFor(prices map[string]ModelPrice, model string) (ModelPrice, bool) {
+	model = strings.ToLower(strings.TrimSpace(model))
+	if model == "" {
+		return ModelPrice{}, false
+	}
+	if p, ok := prices[model]; ok {
+		return p, true
+	}
+	best, found := "", false
+	for k := range prices {
+		if strings.HasPrefix(model, strings.ToLower(k)) && len(k) > len(best) {
+			best, found = k, true
+		}
+	}
+	return prices[best], found
+}
+
+// UsageLine is a ledger record with its estimated cost; Priced is false when
+// the price table has no entry for the model (the cost is then 0).
+type UsageLine struct {
+	UsageRecord
+	CostUSD float64 `json:"cost_usd"`
+	Priced  bool    `json:"priced"`
+}
+
+// UsageReport is a ledger priced against a price table, with the budget it
+// runs under.
+type UsageReport struct {
+	Lines       []UsageLine `json:"lines"`
+	TotalTokens int         `json:"total_tokens"`
+	CostUSD     float64     `json:"cost_usd"`
+	Budget      TokenBudget `json:"budget"`
+}
+
+// Report prices the ledger, ordered by purpose then provider and model.
+func (l UsageLedger) Report(prices map[string]ModelPrice, budget TokenBudget) UsageReport {
+	rep := UsageReport{Lines: []UsageLine{}, Budget: budget}
+	for _, r := range l {
+		line := UsageLine{UsageRecord: r}
+		if p, ok := PriceFor(prices, r.Model); ok {
+			line.Priced = true
+			line.CostUSD = p.Cost(r)
+		}
+		rep.Lines = append(rep.Lines, line)
+		rep.TotalTokens += r.TotalTokens
+		rep.CostUSD += line.CostUSD
+	}
+	sort.SliceStable(rep.Lines, func(i, j int) bool {
+		a, b := rep.Lines[i], rep.Lines[j]
+		if a.Purpose != b.Purpose {
+			return a.Purpose < b.Purpose
+		}
+		if a.Provider != b.Provider {
+			return a.Provider < b.Provider
+		}
+		return a.Model < b.Model
+	})
+	return rep
+}
+
+// RecordUsage adds a model call to the session's usage ledger and returns the
+// session's token total after it.
+func (s *SessionState) RecordUsage(r UsageRecord) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.Usage.Add(r)
+	s.touch()
+	return s.Usage.Tokens()
+}
+
+// UsageSnapshot returns a copy of the session's usage ledger.
+func (s *SessionState) UsageSnapshot() UsageLedger {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append(UsageLedger(nil), s.Usage...)
+}
+
+// TokensUsed returns the session's total token count.
+func (s *SessionState) TokensUsed() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.Usage.Tokens()
+}
+
+// TokenBudgetError is returned when a session has used up its hard token budget.
+type TokenBudgetError struct {
+	Used, Hard int
+}
+
+func (e *TokenBudgetError) Error() string {
+	return fmt.Sprintf("this session has used %d tokens of its %d-token budget; raise usage.hard_token_budget in config.yaml to continue", e.Used, e.Hard)
+}
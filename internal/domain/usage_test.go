@@ -0,0 +1,89 @@
+package domain
+
+import (
+	"encoding/json"
+	"math"
+	"testing"
+)
+
+func TestUsageLedgerAggregatesAndPrices(t *testing.T) {
+	var l UsageLedger
+	l.Add(UsageRecord{Provider: "openai", Model: "gpt-4o-mini", Purpose: PurposeOracle, PromptTokens: 1000, CompletionTokens: 200})
+	l.Add(UsageRecord{Provider: "openai", Model: "gpt-4o-mini", Purpose: PurposeOracle, PromptTokens: 3000, CompletionTokens: 800, TotalTokens: 3800})
+	l.Add(UsageRecord{Provider: "anthropic", Model: "claude-sonnet-5", Purpose: PurposeSpoilerGuard, PromptTokens: 500, CompletionTokens: 100})
+	l.Add(UsageRecord{Provider: "custom", Model: "local-llama", Purpose: PurposeSummary, TotalTokens: 50})
+	if len(l) != 3 || l[0].Calls != 2 || l[0].TotalTokens != 5000 {
+		t.Fatalf("ledger = %+v", l)
+	}
+	if l.Tokens() != 5000+600+50 {
+		t.Errorf("tokens = %d", l.Tokens())
+	}
+
+	rep := l.Report(DefaultPricing(), TokenBudget{Hard: 10000})
+	if rep.TotalTokens != 5650 || rep.Budget.Hard != 10000 {
+		t.Errorf("report totals = %+v", rep)
+	}
+	if rep.Lines[0].Purpose != PurposeOracle || rep.Lines[2].Purpose != PurposeSummary {
+		t.Errorf("lines not ordered by purpose: %+v", rep.Lines)
+	}
+	// gpt-4o-mini: 4000 in × 0.15 + 1000 out × 0.60 per million.
+	if got := rep.Lines[0].CostUSD; math.Abs(got-0.0012) > 1e-9 {
+		t.Errorf("gpt-4o-mini cost = %v", got)
+	}
+	// claude-sonnet-5 falls back to the claude-sonnet prefix: 500 × 3 + 100 × 15.
+	if got := rep.Lines[1].CostUSD; !rep.Lines[1].Priced || math.Abs(got-0.003) > 1e-9 {
+		t.Errorf("prefix-priced cost = %+v", rep.Lines[1])
+	}
+	if rep.Lines[2].Priced || rep.Lines[2].CostUSD != 0 {
+		t.Errorf("an unknown model has no price: %+v", rep.Lines[2])
+	}
+}
+
+// TestCacheTokensArePricedApart verifies prompt-cache reads and writes count
+// towards the total and are priced at their own rate, or the input rate when
+// the model has none.
+func TestCacheTokensArePricedApart(t *testing.T) {
+	var l UsageLedger
+	l.Add(UsageRecord{Provider: "anthropic", Model: "claude-sonnet-5", Purpose: PurposeOracle, PromptTokens: 100, CompletionTokens: 100, CacheReadTokens: 10000, CacheWriteTokens: 1000})
+	l.Add(UsageRecord{Provider: "openai", Model: "gpt-4o", Purpose: PurposeSummary, PromptTokens: 100, CacheReadTokens: 900})
+	if l.Tokens() != 11200+1000 {
+		t.Errorf("tokens = %d", l.Tokens())
+	}
+	rep := l.Report(DefaultPricing(), TokenBudget{})
+	// claude-sonnet: 100 × 3 + 100 × 15 + 10000 × 0.30 + 1000 × 3.75 per million.
+	if got := rep.Lines[0].CostUSD; math.Abs(got-0.00855) > 1e-9 {
+		t.Errorf("cached sonnet cost = %v", got)
+	}
+	// gpt-4o has no cache price: 1000 × 2.50.
+	if got := rep.Lines[1].CostUSD; math.Abs(got-0.0025) > 1e-9 {
+		t.Errorf("gpt-4o cost = %v", got)
+	}
+}
+
+func TestPriceForPrefersTheLongestPrefix(t *testing.T) {
+	p, ok := PriceFor(DefaultPricing(), "gpt-4o-mini-2024-07-18")
+	if !ok || p.Input != 0.15 {
+		t.Errorf("price = %+v, %v", p, ok)
+	}
+	if _, ok := PriceFor(DefaultPricing(), ""); ok {
+		t.Error("no model, no price")
+	}
+}
+
+func TestSessionUsagePersists(t *testing.T) {
+	s := NewSessionState("s", nil)
+	if total := s.RecordUsage(UsageRecord{Provider: "openai", Model: "gpt-4o", Purpose: PurposeNovel, PromptTokens: 10, CompletionTokens: 5}); total != 15 {
+		t.Errorf("total = %d", total)
+	}
+	b, err := json.Marshal(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var back SessionState
+	if err := json.Unmarshal(b, &back); err != nil {
+		t.Fatal(err)
+	}
+	if u := back.UsageSnapshot(); len(u) != 1 || u[0].Purpose != PurposeNovel || back.TokensUsed() != 15 {
+		t.Errorf("reloaded usage = %+v", u)
+	}
+}
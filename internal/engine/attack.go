@@ -0,0 +1,552 @@
+package engine
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/theburrowhub/thaimaturgy/internal/domain"
+	"github.com/theburrowhub/thaimaturgy/internal/srd"
+	"github.com/theburrowhub/thaimaturgy/internal/types"
+)
+
+// This file implements the combat-aware mechanics tools (issue #22, Phase B):
+// attack rolls against AC, saving throws, and typed damage that honours a
+// creature's resistances, immunities and vulnerabilities automatically.
+
+// combatTarget is whoever an attack, save or damage is aimed at. Exactly one HP
+// path applies: a party sheet (PC), a tracked combatant, a spawned creature
+// instance, or none (a creature nobody tracks, whose HP the DM keeps by hand).
+type combatTarget struct {
+	Name      string
+	AC        int
+	PC        *domain.Character // party member (snapshot); HP lives on the sheet
+	Combatant bool              // tracked non-party combatant; HP lives in CombatState
+	Instance  bool              // spawned creature instance; HP lives in SessionState.Creatures
+	Block     *domain.StatBlock // creature defenses and saves (nil for PCs)
+}
+
+// resolveTarget finds a target by name: a party member first, then a combatant
+// in the tracked fight, then a spawned creature instance, then an authored NPC
+// (by id or name), then an SRD creature.
+func (tr *ToolRouter) resolveTarget(name string) (*combatTarget, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, fmt.Errorf("missing 'target'")
+	}
+	if pc := partyMember(tr.state().PartySnapshot(), name); pc != nil {
+		return &combatTarget{Name: pc.Name, AC: pc.AC, PC: pc}, nil
+	}
+	if cb := tr.state().CombatSnapshot().Find(name); cb != nil {
+		return &combatTarget{Name: cb.Name, AC: cb.AC, Combatant: true, Block: tr.combatantBlock(cb)}, nil
+	}
+	if c, ok := tr.state().Creature(name); ok {
+		return &combatTarget{Name: c.Name, AC: c.AC, Instance: true, Block: tr.instanceBlock(&c)}, nil
+	}
+	n := tr.adv().NPC(name)
+	if n == nil {
+		n = npcByName(tr.adv(), name)
+	}
+	if n != nil {
+		sb := n.StatBlock
+		if sb == nil {
+			if b, ok := srd.Lookup(n.Name); ok {
+				sb = &b
+			}
+		}
+		t := &combatTarget{Name: n.Name, Block: sb}
+		if sb != nil {
+			t.AC = sb.AC
+		}
+		return t, nil
+	}
+	if sb, ok := srd.Lookup(name); ok {
+		return &combatTarget{Name: name, AC: sb.AC, Block: &sb}, nil
+	}
+	return nil, fmt.Errorf("no party member, combatant, NPC or SRD creature named %q", name)
+}
+
+// combatantBlock returns the stat block a combatant was built from (authored NPC
+// or SRD creature), or nil for an ad-hoc one.
+func (tr *ToolRouter) combatantBlock(cb *domain.Combatant) *domain.StatBlock {
+	if cb.NPCID != "" {
+		if n := tr.adv().NPC(cb.NPCID); n != nil {
+			if n.StatBlock != nil {
+				sb := *n.StatBlock
+				return &sb
+			}
+			if sb, ok := srd.Lookup(n.Name); ok {
+				return &sb
+			}
+		}
+	}
+	if cb.Creature != "" {
+		if sb, ok := srd.Lookup(cb.Creature); ok {
+			return &sb
+		}
+	}
+	return nil
+}
+
+// attackerBlock returns the stat block for an attacker name (combatant, NPC or
+// SRD creature), or nil — used to fill an attack from one of its actions.
+func (tr *ToolRouter) attackerBlock(name string) *domain.StatBlock {
+	if strings.TrimSpace(name) == "" {
+		return nil
+	}
+	t, err := tr.resolveTarget(name)
+	if err != nil {
+		return nil
+	}
+	return t.Block
+}
+
+// findAction returns the stat block action with the given name
+// (case-insensitive), or nil.
+func findAction(sb *domain.StatBlock, name string) *domain.Action {
+	if sb == nil {
+		return nil
+	}
+	for i := range sb.Actions {
+		if strings.EqualFold(sb.Actions[i].Name, strings.TrimSpace(name)) {
+			return &sb.Actions[i]
+		}
+	}
+	return nil
+}
+
+// damagePart is one typed component of a damage expression.
+type damagePart struct {
+	Notation string // dice notation or a flat number
+	Type     string // canonical damage type; "" when untyped
+}
+
+// parseDamageSpec splits a damage expression into its typed parts: "1d6+2
+// slashing" has one, "1d8+3 piercing plus 2d8 poison" two. The type may be
+// omitted. Remarks in parentheses and notes after a part's type ("2d6+2
+// piercing in melee, 1d6+2 at range") are ignored, as stat blocks write them.
+// Every part's notation is checked, so a bad expression fails before any die is
+// rolled; an empty expression has no parts.
+func parseDamageSpec(s string) ([]damagePart, error) {
+	s = strings.ToLower(stripParens(s))
+	var parts []damagePart
+	for _, seg := range strings.Split(s, " plus ") {
+		seg, _, _ = strings.Cut(seg, ",")
+		fields := strings.Fields(seg)
+		var notation []string
+		for _, f := range fields {
+			if domain.ParseDamageType(f) != "" || !strings.ContainsAny(f, "0123456789+-") {
+				break
+			}
+			notation = append(notation, f)
+		}
+		if len(notation) == 0 {
+			continue // a rider such as "plus the target is grappled"
+		}
+		p := damagePart{Notation: strings.Join(notation, "")}
+		if len(fields) > len(notation) {
+			p.Type = domain.ParseDamageType(fields[len(notation)])
+		}
+		if _, err := strconv.Atoi(p.Notation); err != nil {
+			if _, err := ParseDice(p.Notation); err != nil {
+				return nil, err
+			}
+		}
+		parts = append(parts, p)
+	}
+	if len(parts) == 0 && strings.TrimSpace(s) != "" {
+		return nil, fmt.Errorf("no dice or amount in %q", strings.TrimSpace(s))
+	}
+	return parts, nil
+}
+
+// stripParens drops parenthesized remarks from s.
+func stripParens(s string) string {
+	var sb strings.Builder
+	depth := 0
+	for _, r := range s {
+		switch {
+		case r == '(':
+			depth++
+		case r == ')' && depth > 0:
+			depth--
+		case depth == 0:
+			sb.WriteRune(r)
+		}
+	}
+	return sb.String()
+}
+
+// overrideDamageType applies an explicit damage_type argument: it replaces the
+// type of the first (primary) part. An empty or unknown dtype changes nothing.
+func overrideDamageType(parts []damagePart, dtype string) {
+	if dt := domain.ParseDamageType(dtype); dt != "" && len(parts) > 0 {
+		parts[0].Type = dt
+	}
+}
+
+// rollDamage rolls a damage expression (dice notation or a flat number). On a
+// critical hit the dice are doubled (the modifier is not). It returns the total
+// (never negative) and a breakdown for the log.
+func rollDamage(notation string, crit bool) (int, string, error) {
+	if n, err := strconv.Atoi(notation); err == nil {
+		return max(n, 0), strconv.Itoa(n), nil
+	}
+	dr, err := ParseDice(notation)
+	if err != nil {
+		return 0, "", err
+	}
+	if crit {
+		dr.DoubleDice()
+	}
+	dr.Roll()
+	return max(dr.Total, 0), dr.String() + " " + dr.ResultString(), nil
+}
+
+// rolledDamage is a damage part after its roll.
+type rolledDamage struct {
+	damagePart
+	Amount    int
+	Breakdown string
+}
+
+// rollDamageParts rolls every part of a parsed damage expression.
+func rollDamageParts(parts []damagePart, crit bool) ([]rolledDamage, error) {
+	out := make([]rolledDamage, 0, len(parts))
+	for _, p := range parts {
+		amount, breakdown, err := rollDamage(p.Notation, crit)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, rolledDamage{damagePart: p, Amount: amount, Breakdown: breakdown})
+	}
+	return out, nil
+}
+
+// damageBreakdown renders rolled parts for the log, e.g. "1d8+3 [5]+3 piercing
+// plus 2d8 [4, 6] poison".
+func damageBreakdown(rolled []rolledDamage) string {
+	strs := make([]string, len(rolled))
+	for i, r := range rolled {
+		strs[i] = r.Breakdown
+		if r.Type != "" {
+			strs[i] += " " + r.Type
+		}
+	}
+	return strings.Join(strs, " plus ")
+}
+
+// applyDamage deals amount points of dtype damage to the target through its HP
+// path, after applying the creature's defenses. It returns the adjusted damage
+// and a status line describing the target's HP afterwards.
+func (tr *ToolRouter) applyDamage(t *combatTarget, amount int, dtype string, magical, crit bool) (domain.DamageResult, string) {
+	res := t.Block.ApplyDamage(amount, dtype, magical)
+	return res, tr.dealHit(t, res.Applied, crit)
+}
+
+// dealHit takes amount points of damage, already adjusted for the target's
+// defenses, off the target's HP as ONE hit and returns the HP status line. A
+// hit of several damage types is added up first: a party member already at 0
+// HP fails one death save per hit (two on a crit), not one per damage type,
+// and massive damage is judged against the whole hit.
+func (tr *ToolRouter) dealHit(t *combatTarget, amount int, crit bool) string {
+	switch {
+	case t.PC != nil:
+		var status string
+		tr.state().MutateCharacter(t.PC.Name, func(c *domain.Character) {
+			note := c.TakeHit(amount, crit).Note()
+			status = hpStatus(c)
+			if note != "" {
+				status += " (" + note + ")"
+			}
+		})
+		tr.state().AppendLog(domain.LogEntry{Type: domain.LogParty, Message: status})
+		tr.syncPartyCombatant(t.PC.Name)
+		return status
+	case t.Combatant:
+		cb, _ := tr.state().AdjustCombatantHP(t.Name, -amount)
+		status := fmt.Sprintf("%s HP: %d/%d", cb.Name, cb.CurrentHP, cb.MaxHP)
+		if cb.Defeated {
+			status += " — defeated"
+		}
+		return status
+	case t.Instance:
+		c, _ := tr.state().AdjustCreatureHP(t.Name, -amount)
+		return c.Status()
+	}
+	return t.Name + "'s HP is not tracked (spawn_creatures or start_combat to track it)"
+}
+
+// damageText renders typed damage with any defense adjustment, e.g.
+// "7 fire (resistant: halved → 3)".
+func damageText(res domain.DamageResult) string {
+	s := strconv.Itoa(res.Rolled)
+	if res.Type != "" {
+		s += " " + res.Type
+	}
+	if note := res.Note(); note != "" {
+		s += fmt.Sprintf(" (%s → %d)", note, res.Applied)
+	}
+	return s
+}
+
+func (tr *ToolRouter) attackRoll(id string, args map[string]any) types.ToolResult {
+	attacker, _ := args["attacker"].(string)
+	targetName, _ := args["target"].(string)
+	t, err := tr.resolveTarget(targetName)
+	if err != nil {
+		return errResult(id, err.Error())
+	}
+	bonus, hasBonus := intArg(args, "bonus")
+	damage, _ := args["damage"].(string)
+	dtype, _ := args["damage_type"].(string)
+	label, _ := args["label"].(string)
+	// An action from the attacker's stat block supplies the to-hit and damage the
+	// caller didn't give explicitly.
+	if name, _ := args["action"].(string); name != "" {
+		act := findAction(tr.attackerBlock(attacker), name)
+		if act == nil {
+			return errResult(id, fmt.Sprintf("%s has no action named %q", attacker, name))
+		}
+		if !hasBonus {
+			if v, err := strconv.Atoi(strings.TrimPrefix(strings.TrimSpace(act.ToHit), "+")); err == nil {
+				bonus, hasBonus = v, true
+			}
+		}
+		if damage == "" {
+			damage = act.Damage
+		}
+		if label == "" {
+			label = act.Name
+		}
+	}
+	if !hasBonus {
+		return errResult(id, "provide 'bonus' (or an 'action' from the attacker's stat block)")
+	}
+	if t.AC <= 0 {
+		if ac, ok := intArg(args, "ac"); ok {
+			t.AC = ac
+		} else {
+			return errResult(id, t.Name+" has no known AC; pass 'ac'")
+		}
+	}
+	parts, err := parseDamageSpec(damage)
+	if err != nil {
+		return errResult(id, "damage: "+err.Error())
+	}
+	overrideDamageType(parts, dtype)
+	magical, _ := args["magical"].(bool)
+	adv, _ := args["advantage"].(bool)
+	dis, _ := args["disadvantage"].(bool)
+	mode := D20ModeFrom(adv, dis)
+
+	roll, other := RollD20Mode(bonus, mode)
+	nat := roll.Rolls[0]
+	crit := roll.IsCriticalHit()
+	hit := !roll.IsCriticalFail() && (crit || roll.Total >= t.AC)
+
+	var sb strings.Builder
+	who := strings.TrimSpace(attacker)
+	if who == "" {
+		who = "Attack"
+	} else {
+		who += " attacks"
+	}
+	fmt.Fprintf(&sb, "%s %s", who, t.Name)
+	if label != "" {
+		sb.WriteString(" (" + label + ")")
+	}
+	fmt.Fprintf(&sb, ": d20(%d)", nat)
+	if mode != D20Normal {
+		fmt.Fprintf(&sb, " [%s, other %d]", mode, other)
+	}
+	fmt.Fprintf(&sb, "%s = %d vs AC %d — ", signed(bonus), roll.Total, t.AC)
+	data := map[string]any{
+		"attacker": attacker, "target": t.Name, "d20": nat, "bonus": bonus,
+		"total": roll.Total, "ac": t.AC, "mode": mode.String(), "hit": hit, "crit": crit,
+	}
+	switch {
+	case crit:
+		sb.WriteString("CRITICAL HIT")
+	case hit:
+		sb.WriteString("HIT")
+	case nat == 1:
+		sb.WriteString("MISS (natural 1)")
+	default:
+		sb.WriteString("MISS")
+	}
+	if hit && len(parts) > 0 {
+		// The parts were checked above, so rolling them can't fail once the d20 is down.
+		rolled, _ := rollDamageParts(parts, crit)
+		var dealt []string
+		applied, total := 0, 0
+		for _, r := range rolled {
+			res := t.Block.ApplyDamage(r.Amount, r.Type, magical)
+			dealt = append(dealt, damageText(res))
+			applied += res.Applied
+			total += res.Rolled
+		}
+		status := tr.dealHit(t, applied, crit)
+		fmt.Fprintf(&sb, ". Damage %s → %s. %s", damageBreakdown(rolled), strings.Join(dealt, " + "), status)
+		data["damage"] = applied
+		data["damage_rolled"] = total
+		data["damage_type"] = rolled[0].Type
+	}
+	msg := sb.String()
+	tr.state().AppendLog(domain.LogEntry{Type: domain.LogRoll, Message: msg, Data: data})
+	tr.session.MarkModified()
+	return okResult(id, msg)
+}
+
+// saveBonus is a target's saving-throw bonus for an ability: from the party
+// sheet, else from the creature's stat block (listed save or ability modifier).
+func (t *combatTarget) saveBonus(a domain.Ability) int {
+	if t.PC != nil {
+		return t.PC.SaveBonus(a)
+	}
+	return t.Block.SaveBonus(a)
+}
+
+func (tr *ToolRouter) savingThrow(id string, args map[string]any) types.ToolResult {
+	ab, ok := domain.ParseAbility(fmt.Sprint(args["ability"]))
+	if !ok {
+		return errResult(id, "missing or unknown 'ability' (STR, DEX, CON, INT, WIS, CHA)")
+	}
+	dc, ok := intArg(args, "dc")
+	if !ok {
+		return errResult(id, "missing 'dc'")
+	}
+	var names []string
+	if n, _ := args["target"].(string); strings.TrimSpace(n) != "" {
+		names = append(names, n)
+	}
+	if list, ok := args["targets"].([]any); ok {
+		for _, raw := range list {
+			if n, _ := raw.(string); strings.TrimSpace(n) != "" {
+				names = append(names, n)
+			}
+		}
+	}
+	if len(names) == 0 {
+		return errResult(id, "provide 'target' or 'targets'")
+	}
+	targets := make([]*combatTarget, 0, len(names))
+	for _, n := range names {
+		t, err := tr.resolveTarget(n)
+		if err != nil {
+			return errResult(id, err.Error())
+		}
+		targets = append(targets, t)
+	}
+
+	label, _ := args["label"].(string)
+	damage, _ := args["damage"].(string)
+	dtype, _ := args["damage_type"].(string)
+	parts, err := parseDamageSpec(damage)
+	if err != nil {
+		return errResult(id, "damage: "+err.Error())
+	}
+	overrideDamageType(parts, dtype)
+	magical, _ := args["magical"].(bool)
+	half := true
+	if v, ok := args["half_on_success"].(bool); ok {
+		half = v
+	}
+	adv, _ := args["advantage"].(bool)
+	dis, _ := args["disadvantage"].(bool)
+	mode := D20ModeFrom(adv, dis)
+	override, hasOverride := intArg(args, "bonus")
+
+	// An area effect rolls its damage once for everyone it catches.
+	rolled, err := rollDamageParts(parts, false)
+	if err != nil {
+		return errResult(id, "damage: "+err.Error())
+	}
+
+	var lines []string
+	head := fmt.Sprintf("%s save DC %d", ab.FullName(), dc)
+	if label != "" {
+		head = label + " — " + head
+	}
+	if len(rolled) > 0 {
+		head += ", damage " + damageBreakdown(rolled)
+	}
+	lines = append(lines, head)
+	for _, t := range targets {
+		bonus := t.saveBonus(ab)
+		if hasOverride {
+			bonus = override
+		}
+		roll, other := RollD20Mode(bonus, mode)
+		success := roll.Total >= dc
+		line := fmt.Sprintf("%s: d20(%d)", t.Name, roll.Rolls[0])
+		if mode != D20Normal {
+			line += fmt.Sprintf(" [%s, other %d]", mode, other)
+		}
+		line += fmt.Sprintf("%s = %d — ", signed(bonus), roll.Total)
+		if success {
+			line += "SUCCESS"
+		} else {
+			line += "FAILURE"
+		}
+		data := map[string]any{
+			"target": t.Name, "ability": ab.String(), "dc": dc, "d20": roll.Rolls[0],
+			"bonus": bonus, "total": roll.Total, "mode": mode.String(), "success": success,
+		}
+		if len(rolled) > 0 {
+			var dealt []string
+			applied := 0
+			for _, r := range rolled {
+				dmg := r.Amount
+				if success {
+					dmg = 0
+					if half {
+						dmg = r.Amount / 2
+					}
+				}
+				if dmg > 0 || !success {
+					res := t.Block.ApplyDamage(dmg, r.Type, magical)
+					dealt = append(dealt, damageText(res))
+					applied += res.Applied
+				}
+			}
+			if len(dealt) > 0 {
+				status := tr.dealHit(t, applied, false)
+				line += fmt.Sprintf(", takes %s. %s", strings.Join(dealt, " + "), status)
+				data["damage"] = applied
+				data["damage_type"] = rolled[0].Type
+			} else {
+				line += ", no damage"
+			}
+		}
+		tr.state().AppendLog(domain.LogEntry{Type: domain.LogRoll, Message: head + " — " + line, Data: data})
+		lines = append(lines, line)
+	}
+	tr.session.MarkModified()
+	return okResult(id, strings.Join(lines, "\n"))
+}
+
+func (tr *ToolRouter) applyDamageTool(id string, args map[string]any) types.ToolResult {
+	targetName, _ := args["target"].(string)
+	t, err := tr.resolveTarget(targetName)
+	if err != nil {
+		return errResult(id, err.Error())
+	}
+	amount, ok := intArg(args, "amount")
+	if !ok || amount < 0 {
+		return errResult(id, "missing or negative 'amount'")
+	}
+	dtype, _ := args["damage_type"].(string)
+	magical, _ := args["magical"].(bool)
+	reason, _ := args["reason"].(string)
+	crit, _ := args["critical"].(bool)
+	res, status := tr.applyDamage(t, amount, domain.ParseDamageType(dtype), magical, crit)
+	msg := fmt.Sprintf("%s takes %s. %s", t.Name, damageText(res), status)
+	if reason != "" {
+		msg = reason + " — " + msg
+	}
+	tr.state().AppendLog(domain.LogEntry{Type: domain.LogRoll, Message: msg,
+		Data: map[string]any{"target": t.Name, "damage": res.Applied, "damage_rolled": res.Rolled, "damage_type": res.Type}})
+	tr.session.MarkModified()
+	return okResult(id, msg)
+}
@@ -0,0 +1,246 @@
+package engine
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/theburrowhub/thaimaturgy/internal/domain"
+	"github.com/theburrowhub/thaimaturgy/internal/srd"
+)
+
+// skeletonFight starts a tracked fight against an SRD skeleton (vulnerable to
+// bludgeoning, immune to poison) and returns a router for it.
+func skeletonFight(t *testing.T) (*domain.Session, *ToolRouter) {
+	t.Helper()
+	session := createTestSession()
+	tr := NewToolRouter(session)
+	if r := combatCall(tr, "start_combat", map[string]any{"combatants": []any{
+		map[string]any{"name": "Skeleton", "initiative": 10, "hp": 40},
+	}}); r.Error != "" {
+		t.Fatalf("start_combat: %s", r.Error)
+	}
+	return session, tr
+}
+
+func TestApplyDamageHonoursDefenses(t *testing.T) {
+	session, tr := skeletonFight(t)
+	hp := func() int { return session.State.CombatSnapshot().Find("Skeleton").CurrentHP }
+
+	r := combatCall(tr, "apply_damage", map[string]any{"target": "Skeleton", "amount": 5, "damage_type": "bludgeoning"})
+	if r.Error != "" || hp() != 30 || !strings.Contains(r.Content, "vulnerable: doubled → 10") {
+		t.Errorf("vulnerable damage: hp=%d %+v", hp(), r)
+	}
+	r = combatCall(tr, "apply_damage", map[string]any{"target": "skeleton", "amount": 9, "damage_type": "poison"})
+	if r.Error != "" || hp() != 30 || !strings.Contains(r.Content, "immune") {
+		t.Errorf("immune damage should do nothing: hp=%d %+v", hp(), r)
+	}
+	combatCall(tr, "apply_damage", map[string]any{"target": "Skeleton", "amount": 50})
+	if c := session.State.CombatSnapshot().Find("Skeleton"); c.CurrentHP != 0 || !c.Defeated {
+		t.Errorf("lethal damage should defeat the combatant: %+v", c)
+	}
+	if r := combatCall(tr, "apply_damage", map[string]any{"target": "Nobody", "amount": 1}); r.Error == "" {
+		t.Error("an unknown target should be an error")
+	}
+}
+
+func TestApplyDamageToPartyMember(t *testing.T) {
+	session := createTestSession()
+	pc := soloParty(session, domain.NewCharacter("Kael", "Elf", "Wizard"))
+	pc.MaxHP, pc.CurrentHP = 20, 20
+	tr := NewToolRouter(session)
+	if r := combatCall(tr, "apply_damage", map[string]any{"target": "kael", "amount": 6, "damage_type": "fire"}); r.Error != "" {
+		t.Fatal(r.Error)
+	}
+	if pc.CurrentHP != 14 {
+		t.Errorf("Kael HP = %d, want 14", pc.CurrentHP)
+	}
+}
+
+func TestAttackRollUsesStatBlockAction(t *testing.T) {
+	session := createTestSession()
+	pc := soloParty(session, domain.NewCharacter("Kael", "Elf", "Wizard"))
+	pc.MaxHP, pc.CurrentHP, pc.AC = 200, 200, 1
+	tr := NewToolRouter(session)
+
+	// AC 1 means only a natural 1 misses; retry past the odd fumble.
+	r := combatCall(tr, "attack_roll", map[string]any{"attacker": "Goblin", "target": "Kael", "action": "Scimitar"})
+	for i := 0; i < 20 && strings.Contains(r.Content, "natural 1"); i++ {
+		r = combatCall(tr, "attack_roll", map[string]any{"attacker": "Goblin", "target": "Kael", "action": "Scimitar"})
+	}
+	if r.Error != "" || !strings.Contains(r.Content, "HIT") || !strings.Contains(r.Content, "slashing") {
+		t.Fatalf("attack = %+v", r)
+	}
+	if pc.CurrentHP >= 200 {
+		t.Error("a hit should deduct the damage from the sheet")
+	}
+	last := session.State.RecentLog(2)
+	if e := last[len(last)-1]; e.Type != domain.LogRoll || e.Data["ac"] != 1 || e.Data["hit"] != true {
+		t.Errorf("attack log entry = %+v", e)
+	}
+
+	if r := combatCall(tr, "attack_roll", map[string]any{"attacker": "Goblin", "target": "Kael", "action": "Fireball"}); r.Error == "" {
+		t.Error("an unknown action should be an error")
+	}
+	if r := combatCall(tr, "attack_roll", map[string]any{"target": "Kael"}); r.Error == "" {
+		t.Error("an attack without a bonus should be an error")
+	}
+}
+
+func TestParseDamageSpec(t *testing.T) {
+	for in, want := range map[string][]damagePart{
+		"":                                    nil,
+		"7":                                   {{Notation: "7"}},
+		"1d6 + 2 Slashing":                    {{Notation: "1d6+2", Type: "slashing"}},
+		"2d8+2 piercing (Brute die included)": {{Notation: "2d8+2", Type: "piercing"}},
+		"2d6+2 piercing in melee, 1d6+2 at range":  {{Notation: "2d6+2", Type: "piercing"}},
+		"1d8+3 piercing plus 2d8 poison":           {{Notation: "1d8+3", Type: "piercing"}, {Notation: "2d8", Type: "poison"}},
+		"1d4 bludgeoning plus the target is prone": {{Notation: "1d4", Type: "bludgeoning"}},
+	} {
+		got, err := parseDamageSpec(in)
+		if err != nil || fmt.Sprint(got) != fmt.Sprint(want) {
+			t.Errorf("parseDamageSpec(%q) = %v, %v; want %v", in, got, err, want)
+		}
+	}
+	for _, bad := range []string{"a lot", "2q6 fire"} {
+		if _, err := parseDamageSpec(bad); err == nil {
+			t.Errorf("parseDamageSpec(%q) should fail", bad)
+		}
+	}
+}
+
+// Every damage expression in the SRD catalog must be usable by attack_roll.
+func TestParseDamageSpecSRDActions(t *testing.T) {
+	for _, name := range srd.Names() {
+		sb, _ := srd.Lookup(name)
+		for _, act := range append(append(sb.Actions, sb.Reactions...), sb.LegendaryActions...) {
+			if act.Damage == "" {
+				continue
+			}
+			if parts, err := parseDamageSpec(act.Damage); err != nil || len(parts) == 0 {
+				t.Errorf("%s %s: %q: %v", name, act.Name, act.Damage, err)
+			}
+		}
+	}
+}
+
+func TestAttackRollCompoundDamage(t *testing.T) {
+	session := createTestSession()
+	pc := soloParty(session, domain.NewCharacter("Kael", "Elf", "Wizard"))
+	pc.MaxHP, pc.CurrentHP, pc.AC = 200, 200, 1
+	tr := NewToolRouter(session)
+
+	args := map[string]any{"attacker": "Giant Spider", "target": "Kael", "action": "Bite"}
+	r := combatCall(tr, "attack_roll", args)
+	for i := 0; i < 20 && strings.Contains(r.Content, "natural 1"); i++ {
+		r = combatCall(tr, "attack_roll", args)
+	}
+	if r.Error != "" || !strings.Contains(r.Content, "piercing") || !strings.Contains(r.Content, "poison") {
+		t.Fatalf("attack = %+v", r)
+	}
+	last := session.State.RecentLog(2)
+	dmg, _ := last[len(last)-1].Data["damage"].(int)
+	// 1d8+3 plus 2d8: at least 6, at most 27 — or 51 on a critical hit, which
+	// doubles the dice.
+	max := 27
+	if crit, _ := last[len(last)-1].Data["crit"].(bool); crit {
+		max = 51
+	}
+	if dmg < 6 || dmg > max || pc.CurrentHP != 200-dmg {
+		t.Errorf("damage %d, HP %d", dmg, pc.CurrentHP)
+	}
+}
+
+// A hit of several damage types is one hit: a downed character fails one death
+// save for it (two on a crit), not one per damage type.
+func TestCompoundHitOnDownedCharacter(t *testing.T) {
+	session := createTestSession()
+	pc := soloParty(session, domain.NewCharacter("Kael", "Elf", "Wizard"))
+	pc.MaxHP, pc.CurrentHP, pc.AC = 20, 0, 1
+	tr := NewToolRouter(session)
+
+	args := map[string]any{"target": "Kael", "bonus": 100, "damage": "3 slashing plus 2 fire"}
+	r := combatCall(tr, "attack_roll", args)
+	for i := 0; i < 20 && strings.Contains(r.Content, "natural 1"); i++ {
+		r = combatCall(tr, "attack_roll", args)
+	}
+	want := 1
+	if strings.Contains(r.Content, "CRITICAL HIT") {
+		want = 2
+	}
+	if r.Error != "" || pc.DeathFailures != want || pc.Dead {
+		t.Errorf("death-save failures = %d (dead %v), want %d: %+v", pc.DeathFailures, pc.Dead, want, r)
+	}
+}
+
+// Massive damage is judged against the whole hit, so two parts that each fall
+// short of it can kill together.
+func TestCompoundHitMassiveDamage(t *testing.T) {
+	session := createTestSession()
+	pc := soloParty(session, domain.NewCharacter("Kael", "Elf", "Wizard"))
+	pc.MaxHP, pc.CurrentHP = 10, 4
+	tr := NewToolRouter(session)
+
+	r := combatCall(tr, "saving_throw", map[string]any{
+		"target": "Kael", "ability": "dex", "dc": 10, "bonus": -100, "damage": "7 slashing plus 7 fire",
+	})
+	if r.Error != "" || !pc.Dead || !strings.Contains(r.Content, "killed outright") {
+		t.Errorf("14 damage at 4/10 HP should kill outright: %+v %+v", pc, r)
+	}
+}
+
+// A bad damage expression fails before the d20 is rolled, leaving no half-logged attack.
+func TestAttackRollBadDamageRollsNothing(t *testing.T) {
+	session := createTestSession()
+	soloParty(session, domain.NewCharacter("Kael", "Elf", "Wizard"))
+	tr := NewToolRouter(session)
+	before := len(session.State.RecentLog(0))
+	r := combatCall(tr, "attack_roll", map[string]any{"target": "Kael", "bonus": 5, "damage": "lots"})
+	if r.Error == "" || len(session.State.RecentLog(0)) != before {
+		t.Errorf("attack with bad damage = %+v", r)
+	}
+}
+
+func TestSavingThrowAreaDamage(t *testing.T) {
+	session, tr := skeletonFight(t)
+	pc := soloParty(session, domain.NewCharacter("Kael", "Elf", "Wizard"))
+	pc.MaxHP, pc.CurrentHP = 100, 100
+
+	// A bonus of -100 always fails a DC 10 save (a natural 20 is no auto-success on saves).
+	r := combatCall(tr, "saving_throw", map[string]any{
+		"targets": []any{"Skeleton", "Kael"}, "ability": "dex", "dc": 10, "bonus": -100, "damage": "12 poison",
+	})
+	if r.Error != "" {
+		t.Fatal(r.Error)
+	}
+	if hp := session.State.CombatSnapshot().Find("Skeleton").CurrentHP; hp != 40 {
+		t.Errorf("poison-immune skeleton HP = %d, want 40", hp)
+	}
+	if pc.CurrentHP != 88 {
+		t.Errorf("Kael HP = %d, want 88 after a failed save", pc.CurrentHP)
+	}
+
+	r = combatCall(tr, "saving_throw", map[string]any{"target": "Kael", "ability": "CON", "dc": 1, "bonus": 100, "damage": "10 fire"})
+	if !strings.Contains(r.Content, "SUCCESS") || pc.CurrentHP != 83 {
+		t.Errorf("success should halve damage: HP=%d %+v", pc.CurrentHP, r)
+	}
+	if r := combatCall(tr, "saving_throw", map[string]any{"target": "Kael", "ability": "luck", "dc": 10}); r.Error == "" {
+		t.Error("an unknown ability should be an error")
+	}
+}
+
+func TestRollD20Mode(t *testing.T) {
+	for range 50 {
+		adv, other := RollD20Mode(2, D20Advantage)
+		if adv.Rolls[0] < other || adv.Total != adv.Rolls[0]+2 {
+			t.Fatalf("advantage kept %d over %d", adv.Rolls[0], other)
+		}
+		dis, other := RollD20Mode(0, D20Disadvantage)
+		if dis.Rolls[0] > other {
+			t.Fatalf("disadvantage kept %d over %d", dis.Rolls[0], other)
+		}
+	}
+	if D20ModeFrom(true, true) != D20Normal {
+		t.Error("advantage and disadvantage cancel out")
+	}
+}
@@ -0,0 +1,399 @@
+package engine
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/theburrowhub/thaimaturgy/internal/domain"
+	"github.com/theburrowhub/thaimaturgy/internal/types"
+)
+
+// This file implements sheet-aware checks: the bonus for a skill, ability check
+// or saving throw is read from the party sheet or the creature's stat block
+// rather than supplied by the model, with advantage/disadvantage, group checks
+// (at least half must succeed) and contests between two sides.
+
+// checkSpec is what a check tests: a skill, a raw ability check, or a save.
+type checkSpec struct {
+	Skill   string // canonical skill name, or "" for an ability check / save
+	Ability domain.Ability
+	Save    bool
+}
+
+// parseCheckSpec builds a spec from a skill or ability name. A skill implies its
+// ability; an ability alone is a raw ability check, or a save when save is set.
+func parseCheckSpec(skill, ability string, save bool) (checkSpec, error) {
+	if s := strings.TrimSpace(skill); s != "" {
+		sk, ok := domain.ParseSkill(s)
+		if !ok {
+			return checkSpec{}, fmt.Errorf("unknown skill %q", s)
+		}
+		if save {
+			return checkSpec{}, fmt.Errorf("a saving throw takes an ability, not a skill")
+		}
+		return checkSpec{Skill: sk.Name, Ability: sk.Ability}, nil
+	}
+	ab, ok := domain.ParseAbility(ability)
+	if !ok {
+		return checkSpec{}, fmt.Errorf("provide a 'skill' or an 'ability' (STR, DEX, CON, INT, WIS, CHA)")
+	}
+	return checkSpec{Ability: ab, Save: save}, nil
+}
+
+// tested is the skill, or the ability, the check tests.
+func (c checkSpec) tested() string {
+	if c.Skill != "" {
+		return c.Skill
+	}
+	return c.Ability.String()
+}
+
+func (c checkSpec) String() string {
+	switch {
+	case c.Skill != "":
+		return c.Skill + " check"
+	case c.Save:
+		return c.Ability.String() + " save"
+	}
+	return c.Ability.String() + " check"
+}
+
+// bonus is the target's bonus for the check: from the party sheet (a skill the
+// sheet doesn't list falls back to its ability modifier), else from the stat
+// block (listed value or ability modifier).
+func (c checkSpec) bonus(t *combatTarget) int {
+	if pc := t.PC; pc != nil {
+		switch {
+		case c.Save:
+			return pc.SaveBonus(c.Ability)
+		case c.Skill != "":
+			for _, s := range pc.Skills {
+				if s.Name == c.Skill {
+					return pc.SkillBonus(c.Skill)
+				}
+			}
+		}
+		return domain.Modifier(pc.Abilities.Get(c.Ability))
+	}
+	switch {
+	case c.Save:
+		return t.Block.SaveBonus(c.Ability)
+	case c.Skill != "":
+		return t.Block.SkillBonus(c.Skill)
+	case t.Block == nil || t.Block.Abilities == (domain.AbilityScores{}):
+		return 0
+	}
+	return domain.Modifier(t.Block.Abilities.Get(c.Ability))
+}
+
+// checkRoll is one d20 test rolled for a check.
+type checkRoll struct {
+	Name  string
+	Bonus int
+	Mode  D20Mode
+	D20   int // the kept die
+	Other int // the discarded die with advantage/disadvantage
+	Total int
+}
+
+func rollCheck(name string, bonus int, mode D20Mode) checkRoll {
+	roll, other := RollD20Mode(bonus, mode)
+	return checkRoll{Name: name, Bonus: bonus, Mode: mode, D20: roll.Rolls[0], Other: other, Total: roll.Total}
+}
+
+// String renders the roll as "d20(14) [advantage, other 3]+5 = 19".
+func (r checkRoll) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "d20(%d)", r.D20)
+	if r.Mode != D20Normal {
+		fmt.Fprintf(&b, " [%s, other %d]", r.Mode, r.Other)
+	}
+	fmt.Fprintf(&b, "%s = %d", signed(r.Bonus), r.Total)
+	switch r.D20 {
+	case 20:
+		b.WriteString(" [NAT 20]")
+	case 1:
+		b.WriteString(" [NAT 1]")
+	}
+	return b.String()
+}
+
+// data is the roll's breakdown for LogEntry.Data.
+func (r checkRoll) data() map[string]any {
+	d := map[string]any{"d20": r.D20, "bonus": r.Bonus, "total": r.Total, "mode": r.Mode.String()}
+	if r.Name != "" {
+		d["character"] = r.Name
+	}
+	if r.Mode != D20Normal {
+		d["other"] = r.Other
+	}
+	return d
+}
+
+// stringList collects a string argument and a list argument into one list of
+// non-blank names.
+func stringList(args map[string]any, one, many string) []string {
+	var out []string
+	if s, _ := args[one].(string); strings.TrimSpace(s) != "" {
+		out = append(out, strings.TrimSpace(s))
+	}
+	if list, ok := args[many].([]any); ok {
+		for _, raw := range list {
+			if s, _ := raw.(string); strings.TrimSpace(s) != "" {
+				out = append(out, strings.TrimSpace(s))
+			}
+		}
+	}
+	return out
+}
+
+func (tr *ToolRouter) abilityCheck(id string, args map[string]any) types.ToolResult {
+	skill, _ := args["skill"].(string)
+	ability, _ := args["ability"].(string)
+	save, _ := args["save"].(bool)
+	label, _ := args["label"].(string)
+	extra, _ := intArg(args, "bonus")
+	adv, _ := args["advantage"].(bool)
+	dis, _ := args["disadvantage"].(bool)
+	mode := D20ModeFrom(adv, dis)
+	dc, hasDC := intArg(args, "dc")
+	names := stringList(args, "character", "characters")
+	opponent, _ := args["opponent"].(string)
+
+	// Without a character the check is a bare d20 + modifier (e.g. for someone
+	// who has no sheet), as the tool has always rolled it.
+	if len(names) == 0 && strings.TrimSpace(opponent) == "" {
+		if !hasDC {
+			return errResult(id, "missing 'dc'")
+		}
+		mod, _ := intArg(args, "modifier")
+		r := rollCheck("", mod+extra, mode)
+		return tr.logCheck(id, label, fmt.Sprintf("Check (DC %d): %s [%s]", dc, r, successText(r.Total >= dc)),
+			merge(r.data(), map[string]any{"dc": dc, "success": r.Total >= dc}))
+	}
+	spec, err := parseCheckSpec(skill, ability, save)
+	if err != nil {
+		return errResult(id, err.Error())
+	}
+	if strings.TrimSpace(opponent) != "" {
+		if len(names) != 1 {
+			return errResult(id, "a contested check takes exactly one 'character' and an 'opponent'")
+		}
+		return tr.contestedCheck(id, args, names[0], opponent, spec, mode, extra, label)
+	}
+	if !hasDC {
+		return errResult(id, "missing 'dc'")
+	}
+	var rolls []checkRoll
+	_, room := tr.state().Location()
+	for _, n := range names {
+		t, err := tr.resolveTarget(n)
+		if err != nil {
+			return errResult(id, err.Error())
+		}
+		r := rollCheck(t.Name, spec.bonus(t)+extra, mode)
+		rolls = append(rolls, r)
+		// A party member's check here may meet a check: reveal condition.
+		if t.PC != nil && !spec.Save {
+			tr.state().RecordCheck(spec.tested(), room, r.Total)
+		}
+	}
+	if len(rolls) == 1 {
+		r := rolls[0]
+		msg := fmt.Sprintf("%s — %s (DC %d): %s [%s]", r.Name, spec, dc, r, successText(r.Total >= dc))
+		return tr.logCheck(id, label, msg, merge(r.data(), map[string]any{"check": spec.String(), "dc": dc, "success": r.Total >= dc}))
+	}
+
+	// Group check: the group succeeds when at least half of its members do.
+	lines := make([]string, 0, len(rolls))
+	entries := make([]any, 0, len(rolls))
+	passed := 0
+	for _, r := range rolls {
+		ok := r.Total >= dc
+		if ok {
+			passed++
+		}
+		lines = append(lines, fmt.Sprintf("  %s: %s [%s]", r.Name, r, successText(ok)))
+		entries = append(entries, merge(r.data(), map[string]any{"success": ok}))
+	}
+	success := passed*2 >= len(rolls)
+	msg := fmt.Sprintf("Group %s (DC %d): %s — %d of %d succeeded\n%s",
+		spec, dc, successText(success), passed, len(rolls), strings.Join(lines, "\n"))
+	return tr.logCheck(id, label, msg, map[string]any{
+		"check": spec.String(), "dc": dc, "group": true, "rolls": entries, "successes": passed, "success": success,
+	})
+}
+
+// contestedCheck rolls a character's check against an opponent's. The opponent
+// tests the same skill/ability unless opponent_skill/opponent_ability say
+// otherwise; a tie leaves the situation as it was.
+func (tr *ToolRouter) contestedCheck(id string, args map[string]any, name, opponent string, spec checkSpec, mode D20Mode, extra int, label string) types.ToolResult {
+	a, err := tr.resolveTarget(name)
+	if err != nil {
+		return errResult(id, err.Error())
+	}
+	b, err := tr.resolveTarget(opponent)
+	if err != nil {
+		return errResult(id, err.Error())
+	}
+	oppSpec := spec
+	oSkill, _ := args["opponent_skill"].(string)
+	oAbility, _ := args["opponent_ability"].(string)
+	if strings.TrimSpace(oSkill) != "" || strings.TrimSpace(oAbility) != "" {
+		if oppSpec, err = parseCheckSpec(oSkill, oAbility, false); err != nil {
+			return errResult(id, "opponent: "+err.Error())
+		}
+	}
+	oAdv, _ := args["opponent_advantage"].(bool)
+	oDis, _ := args["opponent_disadvantage"].(bool)
+
+	ra := rollCheck(a.Name, spec.bonus(a)+extra, mode)
+	rb := rollCheck(b.Name, oppSpec.bonus(b), D20ModeFrom(oAdv, oDis))
+	winner, outcome := "", "TIE — the situation stays as it was"
+	switch {
+	case ra.Total > rb.Total:
+		winner = ra.Name
+	case rb.Total > ra.Total:
+		winner = rb.Name
+	}
+	if winner != "" {
+		outcome = strings.ToUpper(winner) + " WINS"
+	}
+	msg := fmt.Sprintf("Contest — %s %s vs %s %s: %s %s; %s %s → %s",
+		ra.Name, spec, rb.Name, oppSpec, ra.Name, ra, rb.Name, rb, outcome)
+	return tr.logCheck(id, label, msg, map[string]any{
+		"check": spec.String(), "opponent_check": oppSpec.String(), "contest": true,
+		"rolls": []any{ra.data(), rb.data()}, "winner": winner,
+	})
+}
+
+// logCheck records a check on the timeline (with its breakdown in Data) and
+// returns it as the tool result.
+func (tr *ToolRouter) logCheck(id, label, msg string, data map[string]any) types.ToolResult {
+	if label != "" {
+		msg = label + " — " + msg
+		data["label"] = label
+	}
+	tr.state().AppendLog(domain.LogEntry{Type: domain.LogRoll, Message: msg, Data: data})
+	tr.session.MarkModified()
+	return okResult(id, msg)
+}
+
+func successText(ok bool) string {
+	if ok {
+		return "SUCCESS"
+	}
+	return "FAILURE"
+}
+
+// merge copies extra into d and returns d.
+func merge(d, extra map[string]any) map[string]any {
+	for k, v := range extra {
+		d[k] = v
+	}
+	return d
+}
+
+// parseCheckArgs turns "/check" arguments into ability_check tool arguments:
+//
+//	/check Kael stealth 15 adv
+//	/check Kael, Mira perception 12      (group check; "party" rolls everyone)
+//	/check Kael dex save 14
+//	/check Kael athletics vs Ogre        (contest; "vs Ogre acrobatics" sets its skill)
+//
+// The character may be omitted when the party has exactly one member.
+func parseCheckArgs(party []domain.Character, words []string) (map[string]any, error) {
+	// Find the skill or ability: the first run of up to three words that names one.
+	at, width := -1, 0
+	var skill, ability string
+	for i := 0; i < len(words) && at < 0; i++ {
+		for w := min(3, len(words)-i); w >= 1; w-- {
+			phrase := strings.Join(words[i:i+w], " ")
+			if sk, ok := domain.ParseSkill(phrase); ok {
+				at, width, skill = i, w, sk.Name
+				break
+			}
+			if w == 1 {
+				if ab, ok := domain.ParseAbility(phrase); ok {
+					at, width, ability = i, 1, ab.String()
+				}
+			}
+		}
+	}
+	if at < 0 {
+		return nil, fmt.Errorf("name a skill or ability to check")
+	}
+	args := map[string]any{}
+	if skill != "" {
+		args["skill"] = skill
+	} else {
+		args["ability"] = ability
+	}
+
+	who := strings.TrimSpace(strings.Join(words[:at], " "))
+	var names []any
+	switch {
+	case who == "" && len(party) == 1:
+		names = append(names, party[0].Name)
+	case who == "":
+		return nil, fmt.Errorf("say who makes the check (a character, a comma-separated list, or party)")
+	case strings.EqualFold(who, "party") || strings.EqualFold(who, "all"):
+		for _, c := range party {
+			names = append(names, c.Name)
+		}
+	default:
+		for _, n := range strings.Split(who, ",") {
+			if n = strings.TrimSpace(n); n != "" {
+				names = append(names, n)
+			}
+		}
+	}
+	if len(names) == 0 {
+		return nil, fmt.Errorf("the party is empty")
+	}
+	if len(names) == 1 {
+		args["character"] = names[0]
+	} else {
+		args["characters"] = names
+	}
+
+	rest := words[at+width:]
+	for i := 0; i < len(rest); i++ {
+		w := strings.ToLower(rest[i])
+		switch w {
+		case "save", "saving", "salvación", "salvacion":
+			args["save"] = true
+		case "throw", "dc":
+		case "adv", "advantage", "ventaja":
+			args["advantage"] = true
+		case "dis", "disadv", "disadvantage", "desventaja":
+			args["disadvantage"] = true
+		case "vs", "vs.", "versus", "contra":
+			opp := rest[i+1:]
+			// A trailing skill/ability sets what the opponent rolls.
+			for w := min(3, len(opp)-1); w >= 1; w-- {
+				phrase := strings.Join(opp[len(opp)-w:], " ")
+				if sk, ok := domain.ParseSkill(phrase); ok {
+					args["opponent_skill"], opp = sk.Name, opp[:len(opp)-w]
+					break
+				}
+				if ab, ok := domain.ParseAbility(phrase); ok && w == 1 {
+					args["opponent_ability"], opp = ab.String(), opp[:len(opp)-1]
+				}
+			}
+			if len(opp) == 0 {
+				return nil, fmt.Errorf("name the opponent after 'vs'")
+			}
+			args["opponent"] = strings.Join(opp, " ")
+			return args, nil
+		default:
+			dc, err := strconv.Atoi(strings.TrimPrefix(w, "dc"))
+			if err != nil {
+				return nil, fmt.Errorf("unexpected %q", rest[i])
+			}
+			args["dc"] = dc
+		}
+	}
+	return args, nil
+}
@@ -0,0 +1,122 @@
+package engine
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/theburrowhub/thaimaturgy/internal/domain"
+)
+
+// lastLog returns the newest timeline entry.
+func lastLog(session *domain.Session) domain.LogEntry {
+	recent := session.State.RecentLog(1)
+	return recent[len(recent)-1]
+}
+
+func TestAbilityCheckReadsTheSheet(t *testing.T) {
+	session := createTestSession()
+	pc := soloParty(session, domain.NewCharacter("Kael", "Elf", "Rogue"))
+	pc.Abilities.DEX, pc.Abilities.CON = 16, 14
+	pc.SetSkillProficiency("Stealth", true, false)
+	pc.SetSaveProficient(domain.CON, true)
+	tr := NewToolRouter(session)
+
+	r := combatCall(tr, "ability_check", map[string]any{"character": "kael", "skill": "stealth", "dc": 15, "advantage": true})
+	if r.Error != "" || !strings.Contains(r.Content, "Kael — Stealth check (DC 15)") || !strings.Contains(r.Content, "advantage, other") {
+		t.Fatalf("stealth check = %+v", r)
+	}
+	e := lastLog(session)
+	if e.Type != domain.LogRoll || e.Data["bonus"] != 5 || e.Data["mode"] != "advantage" || e.Data["other"] == nil || e.Data["dc"] != 15 {
+		t.Errorf("check log entry = %+v", e)
+	}
+	if kept, other := e.Data["d20"].(int), e.Data["other"].(int); kept < other {
+		t.Errorf("advantage kept %d over %d", kept, other)
+	}
+
+	combatCall(tr, "ability_check", map[string]any{"character": "Kael", "ability": "con", "save": true, "dc": 10})
+	if e := lastLog(session); e.Data["bonus"] != 4 || e.Data["check"] != "CON save" {
+		t.Errorf("CON save should add proficiency: %+v", e.Data)
+	}
+	if r := combatCall(tr, "ability_check", map[string]any{"character": "Kael", "skill": "juggling", "dc": 10}); r.Error == "" {
+		t.Error("an unknown skill should be an error")
+	}
+	if r := combatCall(tr, "ability_check", map[string]any{"character": "Kael", "skill": "stealth"}); r.Error == "" {
+		t.Error("a check without a DC should be an error")
+	}
+
+	// Without a character the model-supplied modifier is used as before.
+	combatCall(tr, "ability_check", map[string]any{"modifier": 3, "dc": 12})
+	if e := lastLog(session); e.Data["bonus"] != 3 || !strings.HasPrefix(e.Message, "Check (DC 12)") {
+		t.Errorf("bare check = %+v", e)
+	}
+}
+
+func TestGroupAndContestedChecks(t *testing.T) {
+	session := createTestSession()
+	session.State.Characters = []*domain.Character{
+		domain.NewCharacter("Kael", "Elf", "Rogue"),
+		domain.NewCharacter("Mira", "Human", "Cleric"),
+	}
+	tr := NewToolRouter(session)
+
+	r := combatCall(tr, "ability_check", map[string]any{"characters": []any{"Kael", "Mira"}, "skill": "Perception", "dc": 5, "bonus": 100})
+	if r.Error != "" || !strings.Contains(r.Content, "Group Perception check (DC 5): SUCCESS — 2 of 2") {
+		t.Fatalf("group check = %+v", r)
+	}
+	if e := lastLog(session); e.Data["group"] != true || len(e.Data["rolls"].([]any)) != 2 {
+		t.Errorf("group log entry = %+v", e.Data)
+	}
+
+	r = combatCall(tr, "ability_check", map[string]any{"character": "Kael", "skill": "athletics", "opponent": "Goblin", "bonus": 100})
+	if r.Error != "" || !strings.Contains(r.Content, "KAEL WINS") {
+		t.Fatalf("contest = %+v", r)
+	}
+	if e := lastLog(session); e.Data["winner"] != "Kael" || e.Data["contest"] != true {
+		t.Errorf("contest log entry = %+v", e.Data)
+	}
+	if r := combatCall(tr, "ability_check", map[string]any{"characters": []any{"Kael", "Mira"}, "skill": "athletics", "opponent": "Goblin"}); r.Error == "" {
+		t.Error("a contest takes exactly one character")
+	}
+}
+
+func TestParseCheckArgs(t *testing.T) {
+	party := []domain.Character{{Name: "Kael"}, {Name: "Mira"}}
+	for _, tc := range []struct {
+		in   string
+		want map[string]any
+	}{
+		{"Kael stealth 15 adv", map[string]any{"character": "Kael", "skill": "Stealth", "dc": 15, "advantage": true}},
+		{"Kael dex save DC 14 dis", map[string]any{"character": "Kael", "ability": "DEX", "save": true, "dc": 14, "disadvantage": true}},
+		{"Kael, Mira sleight of hand 12", map[string]any{"characters": []any{"Kael", "Mira"}, "skill": "Sleight of Hand", "dc": 12}},
+		{"party perception 10", map[string]any{"characters": []any{"Kael", "Mira"}, "skill": "Perception", "dc": 10}},
+		{"Kael athletics vs Gate Guard acrobatics", map[string]any{"character": "Kael", "skill": "Athletics", "opponent": "Gate Guard", "opponent_skill": "Acrobatics"}},
+	} {
+		got, err := parseCheckArgs(party, strings.Fields(tc.in))
+		if err != nil || !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("parseCheckArgs(%q) = %v, %v; want %v", tc.in, got, err, tc.want)
+		}
+	}
+	for _, bad := range []string{"Kael", "stealth 10", "Kael stealth banana", "Kael athletics vs"} {
+		if _, err := parseCheckArgs(party, strings.Fields(bad)); err == nil {
+			t.Errorf("parseCheckArgs(%q) should fail", bad)
+		}
+	}
+	// A solo party may leave the character out.
+	if got, err := parseCheckArgs(party[:1], []string{"insight", "12"}); err != nil || got["character"] != "Kael" {
+		t.Errorf("solo /check = %v, %v", got, err)
+	}
+}
+
+func TestCheckCommand(t *testing.T) {
+	session := createTestSession()
+	soloParty(session, domain.NewCharacter("Kael", "Elf", "Rogue"))
+	h := NewCommandHandler(session)
+	res := h.Execute(ParseCommand("/check wis save 10"))
+	if !res.Success || !strings.Contains(res.Message, "Kael — WIS save (DC 10)") {
+		t.Fatalf("/check = %+v", res)
+	}
+	if res := h.Execute(ParseCommand("/check Kael")); res.Success {
+		t.Error("/check without a skill should fail with usage")
+	}
+}
@@ -0,0 +1,252 @@
+package engine
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/theburrowhub/thaimaturgy/internal/domain"
+	"github.com/theburrowhub/thaimaturgy/internal/srd"
+	"github.com/theburrowhub/thaimaturgy/internal/types"
+)
+
+// This file implements the combat tracker tools (issue #22, Phase A): rolling
+// initiative, building the turn order and advancing it. The structure itself
+// lives in domain.CombatState; dice stay here in the engine.
+
+// maxCombatantCount caps the "count" of identical creatures a single
+// start_combat entry may add.
+const maxCombatantCount = 20
+
+// rollInitiative rolls 1d20 + bonus.
+func rollInitiative(bonus int) int {
+	return RollD20WithMod(bonus).Total
+}
+
+// statBlockInitBonus is a creature's initiative modifier: its DEX modifier, or 0
+// when the block has no ability scores.
+func statBlockInitBonus(sb *domain.StatBlock) int {
+	if sb == nil || sb.Abilities.DEX == 0 {
+		return 0
+	}
+	return domain.Modifier(sb.Abilities.DEX)
+}
+
+// npcByName finds an authored NPC by name (case-insensitive), or nil.
+func npcByName(adv *domain.Adventure, name string) *domain.NPC {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil
+	}
+	for i := range adv.NPCs {
+		if strings.EqualFold(adv.NPCs[i].Name, name) {
+			return &adv.NPCs[i]
+		}
+	}
+	return nil
+}
+
+// partyMember finds a party sheet by name (case-insensitive), or nil.
+func partyMember(party []domain.Character, name string) *domain.Character {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil
+	}
+	for i := range party {
+		if strings.EqualFold(party[i].Name, name) {
+			return &party[i]
+		}
+	}
+	return nil
+}
+
+// partyCombatant builds the combatant for a party sheet, rolling 1d20 + DEX mod
+// unless an initiative result is supplied.
+func partyCombatant(c *domain.Character, initiative int, rolled bool) domain.Combatant {
+	bonus := domain.Modifier(c.Abilities.DEX)
+	if !rolled {
+		initiative = rollInitiative(bonus)
+	}
+	return domain.Combatant{
+		Name:       c.Name,
+		Side:       domain.SideParty,
+		Initiative: initiative,
+		InitBonus:  bonus,
+		Character:  c.Name,
+	}
+}
+
+// resolveCombatant turns one start_combat entry into combatants. The stat block
+// comes from the authored NPC (by npc_id or name) or, failing that, the SRD; an
+// unknown creature is still allowed with whatever hp/ac the entry gives. Each of
+// "count" copies rolls its own initiative unless "initiative" is given.
+func (tr *ToolRouter) resolveCombatant(e map[string]any) ([]domain.Combatant, error) {
+	name, _ := e["name"].(string)
+	npcID, _ := e["npc_id"].(string)
+	side, _ := e["side"].(string)
+	name, npcID = strings.TrimSpace(name), strings.TrimSpace(npcID)
+	if inst, ok := tr.state().Creature(name); ok && npcID == "" {
+		return []domain.Combatant{tr.instanceCombatant(&inst, e)}, nil
+	}
+
+	var n *domain.NPC
+	if npcID != "" {
+		if n = tr.adv().NPC(npcID); n == nil {
+			return nil, fmt.Errorf("no npc with id %s", npcID)
+		}
+	} else {
+		n = npcByName(tr.adv(), name)
+	}
+	lookup := name
+	var sb *domain.StatBlock
+	if n != nil {
+		npcID = n.ID
+		if name == "" {
+			name = n.Name
+		}
+		lookup = n.Name
+		sb = n.StatBlock
+	}
+	if name == "" {
+		return nil, fmt.Errorf("each combatant needs a 'name' or 'npc_id'")
+	}
+	creature := ""
+	if sb == nil {
+		if b, ok := srd.Lookup(lookup); ok {
+			sb, creature = &b, lookup
+		}
+	}
+
+	cb := domain.Combatant{Name: name, Side: domain.ParseCombatSide(side), NPCID: npcID, Creature: creature}
+	if sb != nil {
+		cb.MaxHP, cb.CurrentHP, cb.AC = sb.MaxHP, sb.MaxHP, sb.AC
+		cb.InitBonus = statBlockInitBonus(sb)
+	}
+	if hp, ok := intArg(e, "hp"); ok && hp > 0 {
+		cb.MaxHP, cb.CurrentHP = hp, hp
+	}
+	if ac, ok := intArg(e, "ac"); ok && ac > 0 {
+		cb.AC = ac
+	}
+	if b, ok := intArg(e, "initiative_bonus"); ok {
+		cb.InitBonus = b
+	}
+	count, ok := intArg(e, "count")
+	if !ok || count < 1 {
+		count = 1
+	}
+	count = min(count, maxCombatantCount)
+	fixed, hasFixed := intArg(e, "initiative")
+	out := make([]domain.Combatant, 0, count)
+	for range count {
+		c := cb
+		if hasFixed {
+			c.Initiative = fixed
+		} else {
+			c.Initiative = rollInitiative(c.InitBonus)
+		}
+		out = append(out, c)
+	}
+	return out, nil
+}
+
+// instanceCombatant builds the combatant for a spawned creature instance, sharing
+// its HP (domain keeps the two in step) and rolling its initiative unless the
+// entry gives one.
+func (tr *ToolRouter) instanceCombatant(c *domain.CreatureInstance, e map[string]any) domain.Combatant {
+	side, _ := e["side"].(string)
+	cb := domain.Combatant{Name: c.Name, Side: domain.ParseCombatSide(side), NPCID: c.NPCID, Creature: c.Creature,
+		Instance: c.Name, CurrentHP: c.CurrentHP, MaxHP: c.MaxHP, AC: c.AC, Defeated: c.Defeated,
+		Conditions: slices.Clone(c.Conditions)}
+	cb.InitBonus = statBlockInitBonus(tr.instanceBlock(c))
+	if b, ok := intArg(e, "initiative_bonus"); ok {
+		cb.InitBonus = b
+	}
+	if v, ok := intArg(e, "initiative"); ok {
+		cb.Initiative = v
+	} else {
+		cb.Initiative = rollInitiative(cb.InitBonus)
+	}
+	return cb
+}
+
+func (tr *ToolRouter) startCombat(id string, args map[string]any) types.ToolResult {
+	includeParty := true
+	if v, ok := args["include_party"].(bool); ok {
+		includeParty = v
+	}
+	party := tr.state().PartySnapshot()
+	// An entry naming a party member only supplies that member's initiative (e.g.
+	// the player rolled at the table); the member itself comes from the sheet.
+	fixed := make(map[string]int)
+	var others []domain.Combatant
+	list, _ := args["combatants"].([]any)
+	for _, raw := range list {
+		e, ok := raw.(map[string]any)
+		if !ok {
+			continue
+		}
+		name, _ := e["name"].(string)
+		if pc := partyMember(party, name); pc != nil && includeParty {
+			if v, ok := intArg(e, "initiative"); ok {
+				fixed[strings.ToLower(pc.Name)] = v
+			}
+			continue
+		}
+		cs, err := tr.resolveCombatant(e)
+		if err != nil {
+			return errResult(id, err.Error())
+		}
+		others = append(others, cs...)
+	}
+	var all []domain.Combatant
+	if includeParty {
+		for i := range party {
+			v, ok := fixed[strings.ToLower(party[i].Name)]
+			all = append(all, partyCombatant(&party[i], v, ok))
+		}
+	}
+	all = append(all, others...)
+	if v, _ := args["include_creatures"].(bool); v {
+		_, room := tr.state().Location()
+		for _, c := range tr.state().CreaturesSnapshot() {
+			if c.Defeated || c.Room != room || slices.ContainsFunc(all, func(cb domain.Combatant) bool { return cb.Instance == c.Name }) {
+				continue
+			}
+			all = append(all, tr.instanceCombatant(&c, nil))
+		}
+	}
+	if len(all) == 0 {
+		return errResult(id, "no combatants: list the creatures in 'combatants' (or include the party)")
+	}
+	combat := tr.state().StartCombat(all)
+	tr.session.MarkModified()
+	return okResult(id, "Combat started.\n"+FormatCombat(combat, party, false))
+}
+
+func (tr *ToolRouter) endTurn(id string, args map[string]any) types.ToolResult {
+	if !tr.state().InCombat() {
+		return errResult(id, "no combat in progress (use start_combat)")
+	}
+	if list, ok := args["defeated"].([]any); ok {
+		for _, raw := range list {
+			name, _ := raw.(string)
+			if name = strings.TrimSpace(name); name != "" && !tr.state().SetCombatantDefeated(name, true) {
+				return errResult(id, "no combatant named "+name)
+			}
+		}
+	}
+	cur, _ := tr.state().EndTurn()
+	tr.session.MarkModified()
+	msg := fmt.Sprintf("Now: %s's turn.\n", cur.Name) + tr.dyingTurnHint(cur)
+	return okResult(id, msg+FormatCombat(tr.state().CombatSnapshot(), tr.state().PartySnapshot(), false))
+}
+
+func (tr *ToolRouter) endCombat(id string) types.ToolResult {
+	c := tr.state().EndCombat()
+	if c == nil {
+		return errResult(id, "no combat in progress")
+	}
+	tr.session.MarkModified()
+	return okResult(id, fmt.Sprintf("Combat ended after %d round(s).", c.Round))
+}
@@ -0,0 +1,173 @@
+package engine
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/theburrowhub/thaimaturgy/internal/domain"
+	"github.com/theburrowhub/thaimaturgy/internal/types"
+)
+
+func combatCall(tr *ToolRouter, name string, args map[string]any) types.ToolResult {
+	b, _ := json.Marshal(args)
+	return tr.Execute(types.ToolCall{Name: name, Arguments: b})
+}
+
+// TestStartCombatTool verifies start_combat builds the order from the party
+// sheets plus SRD/authored creatures, with stats from their stat blocks.
+func TestStartCombatTool(t *testing.T) {
+	session := createTestSession()
+	session.State.SetMode(domain.ModeVirtualDM)
+	pc := domain.NewCharacter("Kael", "Elf", "Wizard")
+	pc.Abilities.DEX = 16
+	soloParty(session, pc)
+	session.Adventure.NPCs[0].StatBlock = &domain.StatBlock{AC: 16, MaxHP: 11, Abilities: domain.AbilityScores{DEX: 12}}
+	tr := NewToolRouter(session)
+
+	res := combatCall(tr, "start_combat", map[string]any{"combatants": []any{
+		map[string]any{"name": "Goblin", "count": 2},
+		map[string]any{"npc_id": "guard", "side": "ally", "initiative": 30},
+		map[string]any{"name": "Kael", "initiative": 1},
+	}})
+	if res.Error != "" {
+		t.Fatalf("start_combat: %s", res.Error)
+	}
+	c := session.State.CombatSnapshot()
+	if c == nil || len(c.Combatants) != 4 {
+		t.Fatalf("combatants = %+v", c)
+	}
+	if first := c.Combatants[0]; first.Name != "Gate Guard" || first.Side != domain.SideAlly || first.AC != 16 || first.MaxHP != 11 {
+		t.Errorf("fixed-initiative ally should lead with its authored stats: %+v", first)
+	}
+	kael := c.Find("Kael")
+	if kael == nil || kael.Side != domain.SideParty || kael.Initiative != 1 || kael.InitBonus != 3 || kael.Character != "Kael" {
+		t.Errorf("party combatant = %+v", kael)
+	}
+	gob := c.Find("Goblin #2")
+	if gob == nil || gob.AC != 15 || gob.CurrentHP != 7 || gob.InitBonus != 2 {
+		t.Errorf("SRD goblin = %+v", gob)
+	}
+	if gob.Initiative < 3 || gob.Initiative > 22 {
+		t.Errorf("goblin initiative %d outside 1d20+2", gob.Initiative)
+	}
+
+	if r := combatCall(tr, "start_combat", map[string]any{"combatants": []any{map[string]any{"npc_id": "nope"}}}); r.Error == "" {
+		t.Error("an unknown npc_id should be an error")
+	}
+	if r := combatCall(tr, "start_combat", map[string]any{"include_party": false}); r.Error == "" {
+		t.Error("a fight with no combatants should be an error")
+	}
+}
+
+func TestEndTurnAndEndCombatTools(t *testing.T) {
+	session := createTestSession()
+	tr := NewToolRouter(session)
+	if r := combatCall(tr, "end_turn", nil); r.Error == "" {
+		t.Error("end_turn outside combat should be an error")
+	}
+	combatCall(tr, "start_combat", map[string]any{"combatants": []any{
+		map[string]any{"name": "Orc", "initiative": 15},
+		map[string]any{"name": "Wolf", "initiative": 10},
+		map[string]any{"name": "Bat", "initiative": 5, "hp": 1},
+	}})
+	r := combatCall(tr, "end_turn", map[string]any{"defeated": []any{"Wolf"}})
+	if r.Error != "" || !strings.Contains(r.Content, "Now: Bat's turn") {
+		t.Errorf("end_turn should skip the defeated wolf: %+v", r)
+	}
+	if r := combatCall(tr, "end_turn", map[string]any{"defeated": []any{"Dragon"}}); r.Error == "" {
+		t.Error("defeating an unknown combatant should be an error")
+	}
+	if r := combatCall(tr, "end_combat", nil); r.Error != "" || session.State.InCombat() {
+		t.Errorf("end_combat = %+v, InCombat=%v", r, session.State.InCombat())
+	}
+	if r := combatCall(tr, "end_combat", nil); r.Error == "" {
+		t.Error("end_combat with no fight should be an error")
+	}
+}
+
+func TestCombatCommand(t *testing.T) {
+	session := createTestSession()
+	h := NewCommandHandler(session)
+	if res := h.Execute(ParseCommand("/combat")); !strings.Contains(res.Response, "No combat") {
+		t.Errorf("/combat outside a fight = %q", res.Response)
+	}
+	res := h.Execute(ParseCommand("/combat start Goblin x3, guard"))
+	if !res.Success {
+		t.Fatalf("/combat start failed: %s", res.Message)
+	}
+	c := session.State.CombatSnapshot()
+	if c == nil || len(c.Combatants) != 4 || c.Find("Gate Guard") == nil || c.Find("Goblin #3") == nil {
+		t.Fatalf("combatants = %+v", c)
+	}
+	first := c.Current().Name
+	if res := h.Execute(ParseCommand("/combat next")); !res.Success {
+		t.Errorf("/combat next = %+v", res)
+	}
+	if session.State.CombatSnapshot().Current().Name == first {
+		t.Error("/combat next should advance the turn")
+	}
+	h.Execute(ParseCommand("/combat end"))
+	if session.State.InCombat() {
+		t.Error("/combat end should clear the fight")
+	}
+	if res := h.Execute(ParseCommand("/combat dance")); res.Success {
+		t.Error("an unknown subcommand should fail with usage")
+	}
+}
+
+// TestCombatGrounding verifies the active fight reaches the DM as a COMBAT
+// section, and disappears once it ends.
+func TestCombatGrounding(t *testing.T) {
+	session := createTestSession()
+	o := NewOracle(session, nil)
+	if strings.Contains(o.buildSystemPrompt(), "=== COMBAT") {
+		t.Error("no COMBAT section outside a fight")
+	}
+	session.State.StartCombat([]domain.Combatant{
+		{Name: "Orc", Side: domain.SideFoe, Initiative: 14, CurrentHP: 15, MaxHP: 15, AC: 13},
+	})
+	p := o.buildSystemPrompt()
+	if !strings.Contains(p, "=== COMBAT") || !strings.Contains(p, "Orc (foe) HP 15/15 AC 13") || !strings.Contains(p, "Current turn: Orc") {
+		t.Errorf("COMBAT section missing or incomplete:\n%s", p)
+	}
+	session.State.EndCombat()
+	if strings.Contains(o.buildSystemPrompt(), "=== COMBAT") {
+		t.Error("COMBAT section should go away after end_combat")
+	}
+}
+
+// TestFormatCombatPlayerView verifies players see foes' HP only as bands while
+// the party's HP comes exactly from the live sheets.
+func TestFormatCombatPlayerView(t *testing.T) {
+	c := &domain.CombatState{Round: 2, Turn: 1, Combatants: []domain.Combatant{
+		{Name: "Kael", Side: domain.SideParty, Initiative: 17, Character: "Kael"},
+		{Name: "Ogre", Side: domain.SideFoe, Initiative: 8, CurrentHP: 20, MaxHP: 59, AC: 11},
+		{Name: "Goblin", Side: domain.SideFoe, Initiative: 5, Defeated: true},
+	}}
+	party := []domain.Character{{Name: "Kael", CurrentHP: 6, MaxHP: 14, AC: 12, Conditions: []domain.Condition{"Poisoned"}}}
+
+	pv := FormatCombat(c, party, true)
+	if strings.Contains(pv, "20/59") || strings.Contains(pv, "AC 11") {
+		t.Errorf("player view leaks foe stats:\n%s", pv)
+	}
+	for _, want := range []string{"Round 2", "Kael (party) HP 6/14 AC 12 [Poisoned]", "▶  8  Ogre (foe) — Bloodied", "Goblin (foe) — defeated"} {
+		if !strings.Contains(pv, want) {
+			t.Errorf("player view missing %q:\n%s", want, pv)
+		}
+	}
+	if dm := FormatCombat(c, party, false); !strings.Contains(dm, "Ogre (foe) HP 20/59 AC 11") {
+		t.Errorf("DM view should show exact foe stats:\n%s", dm)
+	}
+}
+
+func TestHealthBand(t *testing.T) {
+	for _, tc := range []struct {
+		cur, max int
+		want     string
+	}{{10, 10, "Unharmed"}, {6, 10, "Hurt"}, {5, 10, "Bloodied"}, {0, 10, "Down"}} {
+		if got := HealthBand(tc.cur, tc.max); got != tc.want {
+			t.Errorf("HealthBand(%d,%d) = %q, want %q", tc.cur, tc.max, got, tc.want)
+		}
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"strings"
 
 	"github.com/theburrowhub/thaimaturgy/internal/domain"
+	"github.com/theburrowhub/thaimaturgy/internal/types"
 )
 
 // CommandType enumerates the DM-facing slash commands.
@@ -35,18 +36,25 @@ const (
 	CmdRoll
 	CmdSearch
 	CmdStatus
-	CmdChat     // in-character dialogue added as context (no round action)
-	CmdMeta     // out-of-character question/correction the DM answers immediately
-	CmdRest     // short/long rest for the party
-	CmdMode     // switch between oracle (assistant) and virtual-DM mode
-	CmdMet      // mark an NPC as met (known) in the session state
-	CmdTrigger  // mark a scripted event as triggered
-	CmdTable    // roll on a random table
-	CmdBegin    // (virtual-DM) start the game: the DM narrates the opening scene
-	CmdRecap    // instant "previously on…" recap built from session state
-	CmdScene    // show or switch the active narrative scene/phase
-	CmdGlossary // instant reference of known people + visited places
-	CmdOracle   // free-form query to the oracle (no slash prefix)
+	CmdChat      // in-character dialogue added as context (no round action)
+	CmdMeta      // out-of-character question/correction the DM answers immediately
+	CmdRest      // short/long rest for the party
+	CmdMode      // switch between oracle (assistant) and virtual-DM mode
+	CmdMet       // mark an NPC as met (known) in the session state
+	CmdTrigger   // mark a scripted event as triggered
+	CmdTable     // roll on a random table
+	CmdBegin     // (virtual-DM) start the game: the DM narrates the opening scene
+	CmdRecap     // instant "previously on…" recap built from session state
+	CmdScene     // show or switch the active narrative scene/phase
+	CmdGlossary  // instant reference of known people + visited places
+	CmdCombat    // show or drive the initiative / turn-order tracker
+	CmdCheck     // sheet-aware skill/ability check, save, group check or contest
+	CmdLevelUp   // advance a party member a level (XP thresholds or milestone)
+	CmdDeathSave // roll a death save for (or stabilize) a party member at 0 HP
+	CmdUsage     // tokens and estimated cost the session has used
+	CmdUndo      // roll back the last oracle turn or command
+	CmdRedo      // re-apply what /undo rolled back
+	CmdOracle    // free-form query to the oracle (no slash prefix)
 )
 
 // Command is a parsed DM instruction.
@@ -144,6 +152,20 @@ func ParseCommand(input string) *Command {
 		cmd.Type = CmdScene
 	case "glosario", "glossary", "who":
 		cmd.Type = CmdGlossary
+	case "combat", "combate", "init":
+		cmd.Type = CmdCombat
+	case "check", "chk", "prueba":
+		cmd.Type = CmdCheck
+	case "levelup", "level-up", "lvlup", "subirnivel":
+		cmd.Type = CmdLevelUp
+	case "deathsave", "death-save", "ds", "salvacion":
+		cmd.Type = CmdDeathSave
+	case "usage", "cost", "tokens", "uso":
+		cmd.Type = CmdUsage
+	case "undo", "deshacer":
+		cmd.Type = CmdUndo
+	case "redo", "rehacer":
+		cmd.Type = CmdRedo
 	default:
 		cmd.Type = CmdUnknown
 	}
@@ -159,7 +181,7 @@ type CommandResult struct {
 	Response   string
 	ShouldQuit bool
 	NeedsUI    bool
-	UIAction   string // "oracle" | "save" | "load" | "import" | "image"
+	UIAction   string // "oracle" | "save" | "load" | "snapshot" | "branch" | "import" | "image" | "mode" | "undo"
 	UIArg      string
 }
 
@@ -176,8 +198,21 @@ func NewCommandHandler(session *domain.Session) *CommandHandler {
 func (h *CommandHandler) adv() *domain.Adventure      { return h.session.Adventure }
 func (h *CommandHandler) state() *domain.SessionState { return h.session.State }
 
-// Execute runs a command and returns its result.
-func (h *CommandHandler) Execute(cmd *Command) *CommandResult {
+// Execute runs a command and returns its result. What a command changes is
+// one undoable step, except for /undo and /redo themselves.
+func (h *CommandHandler) Execute(cmd *Command) (r *CommandResult) {
+	if cmd.Type == CmdUndo || cmd.Type == CmdRedo {
+		return h.execute(cmd)
+	}
+	st := h.state()
+	st.BeginChange(cmd.Raw)
+	defer st.CommitChange()
+	name, _, _ := strings.Cut(cmd.Raw, " ")
+	st.TrackChange(name, func() { r = h.execute(cmd) })
+	return r
+}
+
+func (h *CommandHandler) execute(cmd *Command) *CommandResult {
 	r := &CommandResult{Success: true}
 
 	switch cmd.Type {
@@ -189,12 +224,29 @@ func (h *CommandHandler) Execute(cmd *Command) *CommandResult {
 		r.ShouldQuit = true
 		r.Message = "Farewell, Dungeon Master."
 	case CmdSave:
+		if len(cmd.Args) > 0 && isSnapshotArg(cmd.Args[0]) {
+			label := strings.Join(cmd.Args[1:], " ")
+			if label == "" {
+				r.Success = false
+				r.Message = "Usage: /save snapshot <label>"
+				break
+			}
+			r.NeedsUI, r.UIAction, r.UIArg = true, "snapshot", label
+			r.Message = "Taking snapshot “" + label + "”..."
+			break
+		}
 		if len(cmd.Args) > 0 {
 			h.state().Name = cmd.Args[0]
 		}
 		r.NeedsUI, r.UIAction = true, "save"
 		r.Message = "Saving session '" + h.state().Name + "'..."
 	case CmdLoad:
+		if len(cmd.Args) > 0 && isSnapshotArg(cmd.Args[0]) {
+			// Branch from a snapshot; without one, list them.
+			r.NeedsUI, r.UIAction = true, "branch"
+			r.UIArg = strings.Join(cmd.Args[1:], " ")
+			break
+		}
 		r.NeedsUI, r.UIAction = true, "load"
 		if len(cmd.Args) > 0 {
 			r.UIArg = cmd.Args[0]
@@ -259,6 +311,20 @@ func (h *CommandHandler) Execute(cmd *Command) *CommandResult {
 		h.handleScene(cmd, r)
 	case CmdGlossary:
 		r.Response = h.glossaryText()
+	case CmdCombat:
+		h.handleCombat(cmd, r)
+	case CmdCheck:
+		h.handleCheck(cmd, r)
+	case CmdLevelUp:
+		h.handleLevelUp(cmd, r)
+	case CmdDeathSave:
+		h.handleDeathSave(cmd, r)
+	case CmdUsage:
+		r.Response = FormatUsage(UsageReport(h.session))
+	case CmdUndo:
+		h.handleUndo(r, h.state().Undo, "Undid")
+	case CmdRedo:
+		h.handleUndo(r, h.state().Redo, "Redid")
 	case CmdUnknown:
 		r.Success = false
 		r.Message = "Unknown command: " + cmd.Raw + ". Type /help."
@@ -303,7 +369,7 @@ func (h *CommandHandler) handleRoom(_ *Command, r *CommandResult) {
 func (h *CommandHandler) sceneRoom(room *domain.Room) string {
 	scene := h.adv().Scene(h.state().Scene())
 	eff, present := effectiveRoom(scene, room)
-	out := FormatRoom(h.adv(), eff)
+	out := FormatRoom(h.adv(), eff, h.state())
 	if present != "" {
 		out = "In this scene, notably: " + present + "\n" + out
 	}
@@ -333,7 +399,7 @@ func (h *CommandHandler) handleNPC(cmd *Command, r *CommandResult) {
 		r.Success, r.Message = false, "No NPC with id "+cmd.Args[0]
 		return
 	}
-	r.Response = FormatNPC(h.adv(), n)
+	r.Response = FormatNPC(h.adv(), n, h.state())
 }
 
 func (h *CommandHandler) handleEvent(cmd *Command, r *CommandResult) {
@@ -346,7 +412,7 @@ func (h *CommandHandler) handleEvent(cmd *Command, r *CommandResult) {
 		r.Success, r.Message = false, "No event with id "+cmd.Args[0]
 		return
 	}
-	r.Response = FormatEvent(e)
+	r.Response = FormatEvent(e, h.state())
 }
 
 func (h *CommandHandler) handleItem(cmd *Command, r *CommandResult) {
@@ -359,7 +425,7 @@ func (h *CommandHandler) handleItem(cmd *Command, r *CommandResult) {
 		r.Success, r.Message = false, "No item with id "+cmd.Args[0]
 		return
 	}
-	r.Response = FormatItem(h.adv(), it)
+	r.Response = FormatItem(h.adv(), it, h.state())
 }
 
 func (h *CommandHandler) handleMap(cmd *Command, r *CommandResult) {
@@ -455,7 +521,7 @@ func (h *CommandHandler) handleFlag(cmd *Command, r *CommandResult) {
 func (h *CommandHandler) handleRoll(cmd *Command, r *CommandResult) {
 	notation := "1d20"
 	if len(cmd.Args) > 0 {
-		notation = cmd.Args[0]
+		notation = strings.Join(cmd.Args, " ")
 	}
 	roll, err := RollDice(notation)
 	if err != nil {
@@ -473,6 +539,20 @@ func (h *CommandHandler) handleRoll(cmd *Command, r *CommandResult) {
 	r.Message = msg
 }
 
+func (h *CommandHandler) handleCheck(cmd *Command, r *CommandResult) {
+	args, err := parseCheckArgs(h.state().PartySnapshot(), cmd.Args)
+	if err != nil {
+		r.Success, r.Message = false, err.Error()+". Usage: /check <character|party> <skill|ability> [save] [DC] [adv|dis] [vs <opponent> [skill]]"
+		return
+	}
+	res := NewToolRouter(h.session).abilityCheck("", args)
+	if res.Error != "" {
+		r.Success, r.Message = false, res.Error
+		return
+	}
+	r.Message = res.Content
+}
+
 func (h *CommandHandler) handleSearch(cmd *Command, r *CommandResult) {
 	if len(cmd.Args) == 0 {
 		r.Success, r.Message = false, "Usage: /search <query>"
@@ -651,6 +731,65 @@ func (h *CommandHandler) handleBegin(_ *Command, r *CommandResult) {
 	r.NeedsUI, r.UIAction, r.UIArg = true, "oracle", domain.DMKickoffPrompt(h.session.Config.Language)
 }
 
+// handleCombat shows or drives the combat tracker, through the same tool
+// handlers the oracle uses so both paths build the fight identically:
+//
+//	/combat                             show the initiative order
+//	/combat start Goblin x3, npc_id ... roll initiative (party + listed foes)
+//	/combat next                        end the current turn
+//	/combat end                         stop tracking the fight
+func (h *CommandHandler) handleCombat(cmd *Command, r *CommandResult) {
+	if len(cmd.Args) == 0 {
+		r.Response = FormatCombat(h.state().CombatSnapshot(), h.state().PartySnapshot(), false)
+		return
+	}
+	router := NewToolRouter(h.session)
+	var res types.ToolResult
+	switch strings.ToLower(cmd.Args[0]) {
+	case "start", "begin":
+		res = router.startCombat("", map[string]any{"combatants": h.parseCombatants(cmd.Args[1:])})
+	case "next", "end-turn", "endturn", "turn":
+		res = router.endTurn("", nil)
+	case "end", "stop", "over":
+		res = router.endCombat("")
+	default:
+		r.Success, r.Message = false, "Usage: /combat [start <creature>[ xN], ...|next|end]"
+		return
+	}
+	if res.Error != "" {
+		r.Success, r.Message = false, res.Error
+		return
+	}
+	r.Response = res.Content
+}
+
+// parseCombatants splits "/combat start" arguments into start_combat entries: a
+// comma-separated list of NPC ids or creature names, each optionally followed
+// by a count ("Goblin x3").
+func (h *CommandHandler) parseCombatants(args []string) []any {
+	var out []any
+	for _, part := range strings.Split(strings.Join(args, " "), ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		e := map[string]any{}
+		if i := strings.LastIndex(part, " "); i > 0 {
+			if n, err := strconv.Atoi(strings.TrimPrefix(strings.ToLower(part[i+1:]), "x")); err == nil {
+				e["count"] = float64(n)
+				part = strings.TrimSpace(part[:i])
+			}
+		}
+		if h.adv().NPC(part) != nil {
+			e["npc_id"] = part
+		} else {
+			e["name"] = part
+		}
+		out = append(out, e)
+	}
+	return out
+}
+
 func (h *CommandHandler) presentNPCsText() string {
 	room, _ := h.adv().Room(h.state().CurrentRoom)
 	if room == nil || len(room.NPCIDs) == 0 {
@@ -919,14 +1058,14 @@ func (h *CommandHandler) metNPCNames() []string {
 }
 
 // recentNarrative returns up to max recent timeline messages, dropping pure
-// mechanics (rolls, flags, party/system bookkeeping) and event markers (whose
+// mechanics (rolls, flags, party/system/combat bookkeeping) and event markers (whose
 // message carries the authored, possibly-spoilery event name) so the recap
 // reads as a player-safe story rather than a log dump. Order is preserved.
 func recentNarrative(entries []domain.LogEntry, max int) []string {
 	var out []string
 	for _, e := range entries {
 		switch e.Type {
-		case domain.LogRoll, domain.LogFlag, domain.LogParty, domain.LogSystem, domain.LogEvent:
+		case domain.LogRoll, domain.LogFlag, domain.LogParty, domain.LogSystem, domain.LogCombat, domain.LogEvent:
 			continue
 		}
 		if msg := strings.TrimSpace(e.Message); msg != "" {
@@ -954,12 +1093,45 @@ func (h *CommandHandler) statusText() string {
 	return sb.String()
 }
 
+// handleUndo runs /undo or /redo: roll is SessionState.Undo or Redo.
+func (h *CommandHandler) handleUndo(r *CommandResult, roll func() (domain.ChangeSet, error), verb string) {
+	set, err := roll()
+	if err != nil {
+		r.Success, r.Message = false, err.Error()
+		return
+	}
+	r.Message = DescribeChangeSet(verb, set)
+	// The conversation was rolled back too: the frontend redraws its transcript.
+	r.NeedsUI, r.UIAction = true, "undo"
+}
+
+// DescribeChangeSet is the status line for an undone or redone change set,
+// e.g. "Undid Turn: we open the door (set_location, trigger_event)".
+func DescribeChangeSet(verb string, set domain.ChangeSet) string {
+	msg := verb + " " + set.Label
+	if len(set.Changes) > 0 {
+		msg += " (" + strings.Join(set.Changes, ", ") + ")"
+	}
+	return msg
+}
+
+// isSnapshotArg reports whether a /save or /load argument selects snapshots.
+func isSnapshotArg(arg string) bool {
+	switch strings.ToLower(arg) {
+	case "snapshot", "snapshots", "snap":
+		return true
+	}
+	return false
+}
+
 func helpText() string {
 	return `DM COMMANDS:
   /help, /?            Show this help
   /import <path>       Import an adventure module (.tar.gz)
   /save [name]         Save the session
   /load [name]         Load a session
+  /save snapshot <label>  Take a named save point ("before the vault heist")
+  /load snapshot [label]  Branch a new session from a save point (lists them if omitted)
   /quit                Exit
 
 NAVIGATION & CONTENT:
@@ -985,8 +1157,27 @@ SESSION STATE:
   /recap               Quick "previously on…" recap of the session so far
   /glosario            Known people + visited places (aliases: /glossary, /who)
   /party               Show tracked player characters
-  /roll <dice>         Roll dice (e.g. /roll 2d6+3)
+  /roll <dice>         Roll dice (e.g. /roll 2d6+3, 4d6kh3, 2d20kl1+5,
+                       1d8+2d6[fire], 2d6r1, 1d6!, d%)
+  /combat [start <creatures>|next|end]
+                       Combat tracker: show the turn order, start a fight
+                       (e.g. /combat start Goblin x3, Bugbear), end the
+                       current turn, or end the fight
+  /check <who> <skill|ability> [save] [DC] [adv|dis] [vs <opponent>]
+                       Check with the bonus from the sheet (e.g. /check Kael
+                       stealth 15 adv, /check party perception 12,
+                       /check Kael dex save 14, /check Kael athletics vs Ogre)
+  /levelup [who] [roll|average] [milestone]
+                       Level up a character whose XP reached the next
+                       threshold (milestone grants a level regardless)
+  /deathsave [who] [stabilize | revive [hp]]
+                       Death saving throw for a character at 0 HP, or
+                       stabilize them (Medicine, Spare the Dying), or bring
+                       a dead one back (Revivify, Raise Dead)
   /status              Session status
+  /usage               Tokens used and estimated cost, by purpose and model
+  /undo, /redo         Roll back the last oracle turn or command (the state,
+                       timeline and conversation together), or re-apply it
   /mode [oracle|dm]    Toggle Oracle ↔ Virtual DM (AI runs the game; you play)
   /begin               (Virtual DM) Start the game — the DM narrates the opening
 
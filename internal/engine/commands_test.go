@@ -163,6 +163,28 @@ func TestCommandHandlerQuit(t *testing.T) {
 	}
 }
 
+func TestCommandHandlerSnapshots(t *testing.T) {
+	s := createTestSession()
+	handler := NewCommandHandler(s)
+	res := handler.Execute(ParseCommand("/save snapshot before the vault heist"))
+	if res.UIAction != "snapshot" || res.UIArg != "before the vault heist" || s.State.Name != "test_session" {
+		t.Errorf("snapshot = %+v (name %q)", res, s.State.Name)
+	}
+	if res := handler.Execute(ParseCommand("/save snap")); res.Success || res.NeedsUI {
+		t.Errorf("a snapshot without a label should fail: %+v", res)
+	}
+	res = handler.Execute(ParseCommand("/load snapshot before the vault heist"))
+	if res.UIAction != "branch" || res.UIArg != "before the vault heist" {
+		t.Errorf("branch = %+v", res)
+	}
+	if res := handler.Execute(ParseCommand("/load snapshots")); res.UIAction != "branch" || res.UIArg != "" {
+		t.Errorf("listing = %+v", res)
+	}
+	if res := handler.Execute(ParseCommand("/load other")); res.UIAction != "load" || res.UIArg != "other" {
+		t.Errorf("plain load = %+v", res)
+	}
+}
+
 func createTestSession() *domain.Session {
 	adv := &domain.Adventure{
 		SchemaVersion: domain.SchemaVersion,
@@ -0,0 +1,248 @@
+package engine
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/theburrowhub/thaimaturgy/internal/domain"
+	"github.com/theburrowhub/thaimaturgy/internal/srd"
+	"github.com/theburrowhub/thaimaturgy/internal/types"
+)
+
+// This file implements spawned creature instances: the spawn_creatures tool
+// turns a stat block (authored NPC or SRD creature, or every creature of one of
+// the room's encounters) into numbered instances with their own HP, and the
+// damage/heal/condition/remove tools act on one instance. The instances
+// themselves live in domain.SessionState; the engine rolls their HP.
+
+// creatureSource resolves a creature name to its stat block and links: an
+// authored NPC by id or name first, then the SRD. base is the name instances are
+// numbered after.
+func (tr *ToolRouter) creatureSource(name string) (base string, tmpl domain.CreatureInstance, sb *domain.StatBlock, err error) {
+	name = strings.TrimSpace(name)
+	n := tr.adv().NPC(name)
+	if n == nil {
+		n = npcByName(tr.adv(), name)
+	}
+	if n != nil {
+		tmpl.NPCID, base, sb = n.ID, n.Name, n.StatBlock
+		if sb == nil {
+			if b, ok := srd.Lookup(n.Name); ok {
+				sb = &b
+			}
+		}
+		if sb == nil {
+			return "", tmpl, nil, fmt.Errorf("npc %s has no stat block to spawn from", n.ID)
+		}
+		return base, tmpl, sb, nil
+	}
+	b, ok := srd.Lookup(name)
+	if !ok {
+		return "", tmpl, nil, fmt.Errorf("no NPC or SRD creature named %q", name)
+	}
+	// Name the instances after the singular: "4 goblins" are "Goblin #1".. #4.
+	if singular, cut := strings.CutSuffix(name, "s"); cut {
+		if _, ok := srd.Lookup(singular); ok {
+			name = singular
+		}
+	}
+	tmpl.Creature = name
+	return displayCreatureName(name), tmpl, &b, nil
+}
+
+// displayCreatureName title-cases the first letter of an SRD name for the
+// instances' names ("goblin" → "Goblin").
+func displayCreatureName(name string) string {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return name
+	}
+	return strings.ToUpper(name[:1]) + name[1:]
+}
+
+// rollCreatureHP rolls count HP totals from a stat block's hit dice, or uses its
+// MaxHP for each when average is set or the block has no usable hit dice.
+func rollCreatureHP(sb *domain.StatBlock, count int, average bool) []int {
+	hps := make([]int, count)
+	for i := range hps {
+		hps[i] = sb.MaxHP
+		if average || strings.TrimSpace(sb.HitDice) == "" {
+			continue
+		}
+		if dr, err := ParseDice(sb.HitDice); err == nil {
+			hps[i] = max(dr.Roll(), 1)
+		}
+	}
+	return hps
+}
+
+// spawn creates count instances of one creature in the current room.
+func (tr *ToolRouter) spawn(name, display, encounter string, count int, average bool) ([]domain.CreatureInstance, error) {
+	base, tmpl, sb, err := tr.creatureSource(name)
+	if err != nil {
+		return nil, err
+	}
+	if strings.TrimSpace(display) != "" {
+		base = display
+	}
+	_, tmpl.Room = tr.state().Location()
+	tmpl.AC, tmpl.Encounter = sb.AC, encounter
+	return tr.state().SpawnCreatures(base, tmpl, rollCreatureHP(sb, count, average)), nil
+}
+
+// roomEncounter finds an encounter of the current room by name
+// (case-insensitive).
+func (tr *ToolRouter) roomEncounter(name string) (*domain.Encounter, error) {
+	_, rid := tr.state().Location()
+	r, _ := tr.adv().Room(rid)
+	if r == nil {
+		return nil, fmt.Errorf("the party is in no room")
+	}
+	for i := range r.Encounters {
+		if strings.EqualFold(r.Encounters[i].Name, strings.TrimSpace(name)) {
+			return &r.Encounters[i], nil
+		}
+	}
+	return nil, fmt.Errorf("room %s has no encounter named %q", r.ID, name)
+}
+
+func (tr *ToolRouter) spawnCreatures(id string, args map[string]any) types.ToolResult {
+	creature, _ := args["creature"].(string)
+	encounter, _ := args["encounter"].(string)
+	display, _ := args["name"].(string)
+	average := false
+	if mode, _ := args["hp"].(string); strings.EqualFold(mode, "average") {
+		average = true
+	}
+	count, ok := intArg(args, "count")
+	if !ok || count < 1 {
+		count = 1
+	}
+	count = min(count, maxCombatantCount)
+
+	var spawned []domain.CreatureInstance
+	var skipped []string
+	switch {
+	case strings.TrimSpace(creature) != "":
+		cs, err := tr.spawn(creature, display, "", count, average)
+		if err != nil {
+			return errResult(id, err.Error())
+		}
+		spawned = cs
+	case strings.TrimSpace(encounter) != "":
+		e, err := tr.roomEncounter(encounter)
+		if err != nil {
+			return errResult(id, err.Error())
+		}
+		for _, entry := range e.Creatures {
+			name, n := domain.ParseCreatureEntry(entry)
+			cs, err := tr.spawn(name, "", e.Name, n, average)
+			if err != nil {
+				skipped = append(skipped, name)
+				continue
+			}
+			spawned = append(spawned, cs...)
+		}
+	default:
+		return errResult(id, "give a 'creature' (NPC or SRD name) or an 'encounter' of the current room")
+	}
+	if len(spawned) == 0 {
+		return errResult(id, "nothing spawned: no creature resolves to a stat block ("+strings.Join(skipped, ", ")+")")
+	}
+	tr.session.MarkModified()
+	lines := make([]string, len(spawned))
+	for i, c := range spawned {
+		lines[i] = "  " + c.Status()
+	}
+	msg := "Spawned:\n" + strings.Join(lines, "\n")
+	if len(skipped) > 0 {
+		msg += "\nNo stat block, not spawned: " + strings.Join(skipped, ", ")
+	}
+	return okResult(id, msg)
+}
+
+func (tr *ToolRouter) damageCreature(id string, args map[string]any) types.ToolResult {
+	name, _ := args["creature"].(string)
+	inst, ok := tr.state().Creature(name)
+	if !ok {
+		return errResult(id, "no spawned creature named "+strings.TrimSpace(name))
+	}
+	amount, ok := intArg(args, "amount")
+	if !ok || amount < 0 {
+		return errResult(id, "missing or negative 'amount'")
+	}
+	dtype, _ := args["damage_type"].(string)
+	magical, _ := args["magical"].(bool)
+	t := &combatTarget{Name: inst.Name, AC: inst.AC, Instance: true, Block: tr.instanceBlock(&inst)}
+	res, status := tr.applyDamage(t, amount, domain.ParseDamageType(dtype), magical, false)
+	tr.session.MarkModified()
+	return okResult(id, fmt.Sprintf("%s takes %s. %s", t.Name, damageText(res), status))
+}
+
+func (tr *ToolRouter) healCreature(id string, args map[string]any) types.ToolResult {
+	name, _ := args["creature"].(string)
+	amount, ok := intArg(args, "amount")
+	if !ok || amount < 0 {
+		return errResult(id, "missing or negative 'amount'")
+	}
+	c, ok := tr.state().AdjustCreatureHP(name, amount)
+	if !ok {
+		return errResult(id, "no spawned creature named "+strings.TrimSpace(name))
+	}
+	tr.session.MarkModified()
+	return okResult(id, c.Status())
+}
+
+func (tr *ToolRouter) creatureCondition(id string, args map[string]any) types.ToolResult {
+	name, _ := args["creature"].(string)
+	cond, _ := args["condition"].(string)
+	if strings.TrimSpace(cond) == "" {
+		return errResult(id, "missing 'condition'")
+	}
+	remove, _ := args["remove"].(bool)
+	c, ok := tr.state().SetCreatureCondition(name, domain.Condition(strings.TrimSpace(cond)), !remove)
+	if !ok {
+		return errResult(id, "no spawned creature named "+strings.TrimSpace(name))
+	}
+	tr.session.MarkModified()
+	return okResult(id, c.Status())
+}
+
+func (tr *ToolRouter) removeCreature(id string, args map[string]any) types.ToolResult {
+	if all, _ := args["defeated"].(bool); all {
+		gone := tr.state().RemoveDefeatedCreatures()
+		if len(gone) == 0 {
+			return okResult(id, "No defeated creatures to remove.")
+		}
+		tr.session.MarkModified()
+		return okResult(id, "Removed "+strings.Join(gone, ", "))
+	}
+	name, _ := args["creature"].(string)
+	if !tr.state().RemoveCreature(name) {
+		return errResult(id, "no spawned creature named "+strings.TrimSpace(name))
+	}
+	tr.session.MarkModified()
+	return okResult(id, "Removed "+strings.TrimSpace(name))
+}
+
+// instanceBlock returns the stat block a spawned instance was built from, or nil.
+func (tr *ToolRouter) instanceBlock(c *domain.CreatureInstance) *domain.StatBlock {
+	return tr.combatantBlock(&domain.Combatant{NPCID: c.NPCID, Creature: c.Creature})
+}
+
+// FormatCreatures renders the spawned instances still standing, one per line
+// with the room they were spawned in; "" when there are none.
+func FormatCreatures(cs []domain.CreatureInstance) string {
+	var sb strings.Builder
+	for _, c := range cs {
+		if c.Defeated {
+			continue
+		}
+		sb.WriteString("  " + c.Status())
+		if c.Room != "" {
+			sb.WriteString(" — in " + c.Room)
+		}
+		sb.WriteString("\n")
+	}
+	return strings.TrimRight(sb.String(), "\n")
+}
@@ -0,0 +1,105 @@
+package engine
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/theburrowhub/thaimaturgy/internal/domain"
+)
+
+func creatureSession() (*domain.Session, *ToolRouter) {
+	session := srdSession([]domain.NPC{{ID: "grask", Name: "Grask", StatBlock: &domain.StatBlock{AC: 14, MaxHP: 9}}})
+	session.Adventure.Zones[0].Rooms[0].Encounters = []domain.Encounter{{
+		Name: "Guard post", Creatures: []string{"3 goblins", "grask", "Mystery beast"},
+	}}
+	session.State.SetLocation("z", "r", "R")
+	return session, NewToolRouter(session)
+}
+
+func TestSpawnCreaturesTool(t *testing.T) {
+	session, tr := creatureSession()
+	res := combatCall(tr, "spawn_creatures", map[string]any{"encounter": "guard post"})
+	if res.Error != "" {
+		t.Fatalf("spawn encounter: %s", res.Error)
+	}
+	for _, want := range []string{"Goblin #1 HP", "Goblin #3 HP", "Grask HP 9/9 AC 14", "No stat block, not spawned: Mystery beast"} {
+		if !strings.Contains(res.Content, want) {
+			t.Errorf("spawn result missing %q:\n%s", want, res.Content)
+		}
+	}
+	cs := session.State.CreaturesSnapshot()
+	if len(cs) != 4 || cs[0].Encounter != "Guard post" || cs[0].Room != "r" || cs[0].Creature != "goblin" {
+		t.Fatalf("instances = %+v", cs)
+	}
+	for _, c := range cs[:3] {
+		if c.MaxHP < 2 || c.MaxHP > 12 || c.AC != 15 {
+			t.Errorf("goblin HP should be rolled from 2d6: %+v", c)
+		}
+	}
+
+	res = combatCall(tr, "spawn_creatures", map[string]any{"creature": "skeleton", "count": 2, "hp": "average"})
+	if res.Error != "" || !strings.Contains(res.Content, "Skeleton #2 HP 13/13") {
+		t.Errorf("average HP spawn = %+v", res)
+	}
+	if r := combatCall(tr, "spawn_creatures", map[string]any{"creature": "beholder"}); r.Error == "" {
+		t.Error("an unknown creature should be an error")
+	}
+	if r := combatCall(tr, "spawn_creatures", nil); r.Error == "" {
+		t.Error("spawn_creatures needs a creature or an encounter")
+	}
+}
+
+func TestCreatureInstanceTools(t *testing.T) {
+	session, tr := creatureSession()
+	combatCall(tr, "spawn_creatures", map[string]any{"creature": "skeleton", "count": 2, "hp": "average"})
+
+	// Skeletons are vulnerable to bludgeoning: 5 doubles to 10.
+	res := combatCall(tr, "damage_creature", map[string]any{"creature": "Skeleton #1", "amount": 5, "damage_type": "bludgeoning"})
+	if res.Error != "" || !strings.Contains(res.Content, "Skeleton #1 HP 3/13") {
+		t.Fatalf("damage_creature = %+v", res)
+	}
+	// The generic combat tools reach instances by name too.
+	res = combatCall(tr, "apply_damage", map[string]any{"target": "skeleton #1", "amount": 3})
+	if !strings.Contains(res.Content, "Skeleton #1 — defeated") {
+		t.Errorf("apply_damage on an instance = %+v", res)
+	}
+	if res = combatCall(tr, "heal_creature", map[string]any{"creature": "Skeleton #1", "amount": 2}); res.Content != "Skeleton #1 HP 2/13 AC 13" {
+		t.Errorf("heal_creature = %+v", res)
+	}
+	if res = combatCall(tr, "creature_condition", map[string]any{"creature": "Skeleton #2", "condition": "Prone"}); !strings.HasSuffix(res.Content, "[Prone]") {
+		t.Errorf("creature_condition = %+v", res)
+	}
+
+	p := NewOracle(session, nil).buildSystemPrompt()
+	if !strings.Contains(p, "=== SPAWNED CREATURES") || !strings.Contains(p, "Skeleton #2 HP 13/13 AC 13 [Prone] — in r") {
+		t.Errorf("grounding should list the standing instances:\n%s", p)
+	}
+
+	combatCall(tr, "damage_creature", map[string]any{"creature": "Skeleton #1", "amount": 2})
+	if res = combatCall(tr, "remove_creature", map[string]any{"defeated": true}); res.Content != "Removed Skeleton #1" {
+		t.Errorf("remove defeated = %+v", res)
+	}
+	if res = combatCall(tr, "remove_creature", map[string]any{"creature": "Skeleton #2"}); res.Error != "" {
+		t.Errorf("remove_creature = %+v", res)
+	}
+	if strings.Contains(NewOracle(session, nil).buildSystemPrompt(), "SPAWNED CREATURES") {
+		t.Error("the section should go away with the last instance")
+	}
+}
+
+func TestStartCombatWithInstances(t *testing.T) {
+	session, tr := creatureSession()
+	combatCall(tr, "spawn_creatures", map[string]any{"creature": "goblin", "count": 2, "hp": "average"})
+	res := combatCall(tr, "start_combat", map[string]any{"include_party": false, "include_creatures": true})
+	if res.Error != "" {
+		t.Fatalf("start_combat: %s", res.Error)
+	}
+	c := session.State.CombatSnapshot()
+	if len(c.Combatants) != 2 || c.Find("Goblin #2") == nil || c.Find("Goblin #2").Instance != "Goblin #2" {
+		t.Fatalf("combatants = %+v", c.Combatants)
+	}
+	combatCall(tr, "apply_damage", map[string]any{"target": "Goblin #2", "amount": 7})
+	if g, _ := session.State.Creature("Goblin #2"); !g.Defeated || !session.State.CombatSnapshot().Find("Goblin #2").Defeated {
+		t.Errorf("the tracker and the instance should agree: %+v", g)
+	}
+}
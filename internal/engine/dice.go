@@ -3,7 +3,8 @@ package engine
 import (
 	"fmt"
 	"math/rand"
-	"regexp"
+	"slices"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
@@ -11,104 +12,616 @@ import (
 
 var diceRng = rand.New(rand.NewSource(time.Now().UnixNano()))
 
+// DiceRoll is a parsed (and, once rolled, evaluated) dice expression. Simple
+// "NdM±K" notation fills NumDice/DiceSides/Modifier as it always has; richer
+// expressions (compound sums, keep/drop, exploding dice, rerolls, labels) are
+// held in Terms, with NumDice/DiceSides describing the first dice term and
+// Modifier the sum of the flat terms.
 type DiceRoll struct {
-	Notation  string `json:"notation"`
-	NumDice   int    `json:"num_dice"`
-	DiceSides int    `json:"dice_sides"`
-	Modifier  int    `json:"modifier"`
-	Rolls     []int  `json:"rolls"`
-	Total     int    `json:"total"`
+	Notation  string     `json:"notation"`
+	NumDice   int        `json:"num_dice"`
+	DiceSides int        `json:"dice_sides"`
+	Modifier  int        `json:"modifier"`
+	Rolls     []int      `json:"rolls"`
+	Total     int        `json:"total"`
+	Terms     []DiceTerm `json:"terms,omitempty"`
 }
 
-var diceRegex = regexp.MustCompile(`^(\d+)?d(\d+)([+-]\d+)?$`)
+// DiceTerm is one signed term of a dice expression: either a group of dice
+// ("4d6kh3", "2d6r1", "1d6!", "d%") or a flat number, optionally labelled
+// ("1d4[bless]").
+type DiceTerm struct {
+	Sign       int    `json:"sign"` // +1 or -1
+	Count      int    `json:"count,omitempty"`
+	Sides      int    `json:"sides,omitempty"` // 0 for a flat number
+	Constant   int    `json:"constant,omitempty"`
+	Percentile bool   `json:"percentile,omitempty"` // written as d%
+	KeepHigh   int    `json:"keep_high,omitempty"`
+	KeepLow    int    `json:"keep_low,omitempty"`
+	DropHigh   int    `json:"drop_high,omitempty"`
+	DropLow    int    `json:"drop_low,omitempty"`
+	Explode    bool   `json:"explode,omitempty"`
+	Reroll     []int  `json:"reroll,omitempty"`       // reroll (once) a die showing one of these
+	RerollUpTo int    `json:"reroll_up_to,omitempty"` // reroll (once) a die showing this or less
+	Label      string `json:"label,omitempty"`
+
+	Dice  []Die `json:"dice,omitempty"`
+	Value int   `json:"value"` // signed contribution to the total
+}
+
+// Die is a single rolled die within a term.
+type Die struct {
+	Value    int  `json:"value"`
+	Rerolled int  `json:"rerolled,omitempty"` // the face it showed before a reroll
+	Exploded bool `json:"exploded,omitempty"` // rolled its maximum and added a die
+	Dropped  bool `json:"dropped,omitempty"`
+}
+
+// Limits that keep a typed expression from turning into a denial of service.
+const (
+	maxDiceTerms     = 20
+	maxDicePerTerm   = 100
+	maxDiceTotal     = 200
+	maxDiceSides     = 1000
+	maxDiceExplodes  = 100
+	maxDiceLabelRune = 40
+)
 
+// ParseDice parses a dice expression. Besides plain "NdM±K" it understands:
+//
+//	2d6+1d4+3     compound sums (terms may be subtracted)
+//	4d6kh3 2d20kl1 keep highest/lowest N (k is kh); dh/dl drop highest/lowest N
+//	1d6!          exploding dice: a maximum roll adds another die
+//	2d6r1 2d6r<2  reroll a die showing 1 (or 2 or less), once
+//	d%            percentile, a d100
+//	1d4[bless]    a label on the preceding term
+//
+// Spaces are ignored outside labels.
 func ParseDice(notation string) (*DiceRoll, error) {
-	notation = strings.ToLower(strings.TrimSpace(notation))
+	notation = strings.TrimSpace(notation)
+	p := &diceParser{src: notation}
+	terms, err := p.parse()
+	if err != nil {
+		return nil, err
+	}
+	dr := &DiceRoll{Terms: terms}
+	dices := 0
+	for _, t := range terms {
+		if t.Sides == 0 {
+			dr.Modifier += t.Sign * t.Constant
+			continue
+		}
+		if dices == 0 {
+			dr.NumDice, dr.DiceSides = t.Count, t.Sides
+		}
+		dices++
+	}
+	if dices == 0 {
+		return nil, fmt.Errorf("invalid dice notation: %s (no dice to roll)", notation)
+	}
+	dr.Notation = dr.String()
+	return dr, nil
+}
+
+// diceParser is a small hand-written scanner for the ParseDice grammar.
+type diceParser struct {
+	src string
+	pos int
+}
+
+func (p *diceParser) errorf(format string, a ...any) error {
+	return fmt.Errorf("invalid dice notation: %s (%s)", p.src, fmt.Sprintf(format, a...))
+}
+
+func (p *diceParser) skipSpace() {
+	for p.pos < len(p.src) && (p.src[p.pos] == ' ' || p.src[p.pos] == '\t') {
+		p.pos++
+	}
+}
 
-	matches := diceRegex.FindStringSubmatch(notation)
-	if matches == nil {
-		return nil, fmt.Errorf("invalid dice notation: %s (expected format: NdM or NdM+K)", notation)
+// peek reports whether the input continues with s (case-insensitively).
+func (p *diceParser) peek(s string) bool {
+	return len(p.src)-p.pos >= len(s) && strings.EqualFold(p.src[p.pos:p.pos+len(s)], s)
+}
+
+func (p *diceParser) accept(s string) bool {
+	if p.peek(s) {
+		p.pos += len(s)
+		return true
 	}
+	return false
+}
 
-	numDice := 1
-	if matches[1] != "" {
-		var err error
-		numDice, err = strconv.Atoi(matches[1])
+// number reads an unsigned integer; ok is false when there are no digits.
+func (p *diceParser) number() (n int, ok bool, err error) {
+	start := p.pos
+	for p.pos < len(p.src) && p.src[p.pos] >= '0' && p.src[p.pos] <= '9' {
+		p.pos++
+	}
+	if p.pos == start {
+		return 0, false, nil
+	}
+	n, err = strconv.Atoi(p.src[start:p.pos])
+	if err != nil || n > 1_000_000 {
+		return 0, false, p.errorf("number too large")
+	}
+	return n, true, nil
+}
+
+func (p *diceParser) parse() ([]DiceTerm, error) {
+	if p.src == "" {
+		return nil, p.errorf("expected format: NdM or NdM+K")
+	}
+	var terms []DiceTerm
+	total := 0
+	sign := 1
+	p.skipSpace()
+	if p.accept("-") {
+		sign = -1
+	} else {
+		p.accept("+")
+	}
+	for {
+		p.skipSpace()
+		t, err := p.term()
 		if err != nil {
-			return nil, fmt.Errorf("invalid number of dice: %s", matches[1])
+			return nil, err
+		}
+		t.Sign = sign
+		total += t.Count
+		if total > maxDiceTotal {
+			return nil, fmt.Errorf("too many dice: at most %d per roll", maxDiceTotal)
+		}
+		if terms = append(terms, t); len(terms) > maxDiceTerms {
+			return nil, fmt.Errorf("too many terms: at most %d per roll", maxDiceTerms)
 		}
+		p.skipSpace()
+		if p.pos == len(p.src) {
+			return terms, nil
+		}
+		switch p.src[p.pos] {
+		case '+':
+			sign = 1
+		case '-':
+			sign = -1
+		default:
+			return nil, p.errorf("unexpected %q", p.src[p.pos:])
+		}
+		p.pos++
 	}
+}
 
-	diceSides, err := strconv.Atoi(matches[2])
+func (p *diceParser) term() (DiceTerm, error) {
+	var t DiceTerm
+	n, hasN, err := p.number()
 	if err != nil {
-		return nil, fmt.Errorf("invalid dice sides: %s", matches[2])
+		return t, err
 	}
+	if !p.accept("d") {
+		if !hasN {
+			return t, p.errorf("expected format: NdM or NdM+K")
+		}
+		t.Constant = n
+		return t, p.label(&t)
+	}
+	t.Count = 1
+	if hasN {
+		t.Count = n
+	}
+	if p.accept("%") {
+		t.Sides, t.Percentile = 100, true
+	} else {
+		sides, ok, err := p.number()
+		if err != nil {
+			return t, err
+		}
+		if !ok {
+			return t, p.errorf("missing the number of sides")
+		}
+		t.Sides = sides
+	}
+	if t.Count < 1 || t.Count > maxDicePerTerm {
+		return t, fmt.Errorf("number of dice must be between 1 and %d", maxDicePerTerm)
+	}
+	if t.Sides < 1 || t.Sides > maxDiceSides {
+		return t, fmt.Errorf("dice sides must be between 1 and %d", maxDiceSides)
+	}
+	if err := p.modifiers(&t); err != nil {
+		return t, err
+	}
+	return t, p.label(&t)
+}
 
-	modifier := 0
-	if matches[3] != "" {
-		modifier, err = strconv.Atoi(matches[3])
+// modifiers reads the keep/drop, explode and reroll suffixes of a dice term.
+func (p *diceParser) modifiers(t *DiceTerm) error {
+	count := func(what string) (int, error) {
+		n, ok, err := p.number()
 		if err != nil {
-			return nil, fmt.Errorf("invalid modifier: %s", matches[3])
+			return 0, err
 		}
+		if !ok || n < 1 {
+			return 0, p.errorf("%s needs a count of at least 1", what)
+		}
+		return n, nil
+	}
+	keep := func(dst *int, what string) error {
+		if t.KeepHigh+t.KeepLow+t.DropHigh+t.DropLow > 0 {
+			return p.errorf("only one keep/drop per term")
+		}
+		n, err := count(what)
+		*dst = n
+		return err
 	}
+	for {
+		var err error
+		switch {
+		case p.accept("kh"):
+			err = keep(&t.KeepHigh, "kh")
+		case p.accept("kl"):
+			err = keep(&t.KeepLow, "kl")
+		case p.accept("k"):
+			err = keep(&t.KeepHigh, "k")
+		case p.accept("dh"):
+			err = keep(&t.DropHigh, "dh")
+		case p.accept("dl"):
+			err = keep(&t.DropLow, "dl")
+		case p.accept("!"):
+			if t.Sides < 2 {
+				return p.errorf("a d%d cannot explode", t.Sides)
+			}
+			t.Explode = true
+		case p.accept("r<="), p.accept("r<"):
+			var n int
+			if n, err = count("r<"); err == nil {
+				if n >= t.Sides {
+					return p.errorf("r<%d would reroll every face of a d%d", n, t.Sides)
+				}
+				t.RerollUpTo = max(t.RerollUpTo, n)
+			}
+		case p.accept("r"):
+			var n int
+			if n, err = count("r"); err == nil {
+				if n > t.Sides {
+					return p.errorf("a d%d never shows %d", t.Sides, n)
+				}
+				t.Reroll = append(t.Reroll, n)
+			}
+		default:
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
 
-	if numDice < 1 || numDice > 100 {
-		return nil, fmt.Errorf("number of dice must be between 1 and 100")
+// label reads an optional "[text]" after a term.
+func (p *diceParser) label(t *DiceTerm) error {
+	p.skipSpace()
+	if !p.accept("[") {
+		return nil
 	}
-	if diceSides < 1 || diceSides > 1000 {
-		return nil, fmt.Errorf("dice sides must be between 1 and 1000")
+	end := strings.IndexByte(p.src[p.pos:], ']')
+	if end < 0 {
+		return p.errorf("unclosed label")
 	}
+	label := strings.TrimSpace(p.src[p.pos : p.pos+end])
+	p.pos += end + 1
+	if r := []rune(label); len(r) > maxDiceLabelRune {
+		label = string(r[:maxDiceLabelRune])
+	}
+	t.Label = label
+	return nil
+}
 
-	return &DiceRoll{
-		Notation:  notation,
-		NumDice:   numDice,
-		DiceSides: diceSides,
-		Modifier:  modifier,
-	}, nil
+// rerolls reports whether a die showing v should be rerolled.
+func (t *DiceTerm) rerolls(v int) bool {
+	return v <= t.RerollUpTo || slices.Contains(t.Reroll, v)
 }
 
-func (dr *DiceRoll) Roll() int {
-	dr.Rolls = make([]int, dr.NumDice)
+// roll evaluates the term with intn (which returns [0,n)).
+func (t *DiceTerm) roll(intn func(int) int) {
+	if t.Sides == 0 {
+		t.Value = t.Sign * t.Constant
+		return
+	}
+	die := func() Die {
+		d := Die{Value: intn(t.Sides) + 1}
+		if t.rerolls(d.Value) {
+			d.Rerolled, d.Value = d.Value, intn(t.Sides)+1
+		}
+		return d
+	}
+	t.Dice = t.Dice[:0]
+	extra := 0
+	for range t.Count {
+		d := die()
+		t.Dice = append(t.Dice, d)
+		for t.Explode && d.Value == t.Sides && extra < maxDiceExplodes {
+			t.Dice[len(t.Dice)-1].Exploded = true
+			d = die()
+			t.Dice = append(t.Dice, d)
+			extra++
+		}
+	}
+	t.applyKeep()
 	sum := 0
+	for _, d := range t.Dice {
+		if !d.Dropped {
+			sum += d.Value
+		}
+	}
+	t.Value = t.Sign * sum
+}
+
+// applyKeep marks the dice that keep/drop discards.
+func (t *DiceTerm) applyKeep() {
+	n := len(t.Dice)
+	drop, high := 0, false // drop the `drop` lowest (or highest) dice
+	switch {
+	case t.KeepHigh > 0:
+		drop = n - t.KeepHigh
+	case t.KeepLow > 0:
+		drop, high = n-t.KeepLow, true
+	case t.DropHigh > 0:
+		drop, high = t.DropHigh, true
+	case t.DropLow > 0:
+		drop = t.DropLow
+	}
+	drop = min(max(drop, 0), n)
+	if drop == 0 {
+		return
+	}
+	idx := make([]int, n)
+	for i := range idx {
+		idx[i] = i
+	}
+	sort.SliceStable(idx, func(a, b int) bool {
+		if high {
+			return t.Dice[idx[a]].Value > t.Dice[idx[b]].Value
+		}
+		return t.Dice[idx[a]].Value < t.Dice[idx[b]].Value
+	})
+	for _, i := range idx[:drop] {
+		t.Dice[i].Dropped = true
+	}
+}
+
+// notation renders the term back in canonical form (without its sign).
+func (t *DiceTerm) notation() string {
+	var b strings.Builder
+	switch {
+	case t.Sides == 0:
+		b.WriteString(strconv.Itoa(t.Constant))
+	case t.Percentile:
+		fmt.Fprintf(&b, "%dd%%", t.Count)
+	default:
+		fmt.Fprintf(&b, "%dd%d", t.Count, t.Sides)
+	}
+	for _, m := range []struct {
+		n   int
+		tag string
+	}{{t.KeepHigh, "kh"}, {t.KeepLow, "kl"}, {t.DropHigh, "dh"}, {t.DropLow, "dl"}} {
+		if m.n > 0 {
+			fmt.Fprintf(&b, "%s%d", m.tag, m.n)
+		}
+	}
+	if t.Explode {
+		b.WriteString("!")
+	}
+	if t.RerollUpTo > 0 {
+		fmt.Fprintf(&b, "r<%d", t.RerollUpTo)
+	}
+	for _, v := range t.Reroll {
+		fmt.Fprintf(&b, "r%d", v)
+	}
+	if t.Label != "" {
+		b.WriteString("[" + t.Label + "]")
+	}
+	return b.String()
+}
+
+// diceString renders the rolled dice: kept dice joined by "+", a reroll as
+// "1→4", an explosion with "!", and dropped dice listed at the end.
+func (t *DiceTerm) diceString() string {
+	var kept, dropped []string
+	for _, d := range t.Dice {
+		s := strconv.Itoa(d.Value)
+		if d.Rerolled != 0 {
+			s = fmt.Sprintf("%d→%d", d.Rerolled, d.Value)
+		}
+		if d.Exploded {
+			s += "!"
+		}
+		if d.Dropped {
+			dropped = append(dropped, s)
+		} else {
+			kept = append(kept, s)
+		}
+	}
+	s := strings.Join(kept, "+")
+	if len(dropped) > 0 {
+		s += ", dropped " + strings.Join(dropped, " ")
+	}
+	return "[" + s + "]"
+}
+
+// diceTerms counts the dice terms of the expression.
+func (dr *DiceRoll) diceTerms() int {
+	n := 0
+	for _, t := range dr.Terms {
+		if t.Sides > 0 {
+			n++
+		}
+	}
+	return n
+}
 
-	for i := 0; i < dr.NumDice; i++ {
-		roll := diceRng.Intn(dr.DiceSides) + 1
-		dr.Rolls[i] = roll
-		sum += roll
+// legacyTerms builds the single term that a plain NumDice/DiceSides/Modifier
+// roll (such as one from Roll) stands for.
+func (dr *DiceRoll) legacyTerms() {
+	dr.Terms = []DiceTerm{{Sign: 1, Count: dr.NumDice, Sides: dr.DiceSides}}
+	if dr.Modifier != 0 {
+		sign := 1
+		if dr.Modifier < 0 {
+			sign = -1
+		}
+		dr.Terms = append(dr.Terms, DiceTerm{Sign: sign, Constant: sign * dr.Modifier})
 	}
+}
 
-	dr.Total = sum + dr.Modifier
+func (dr *DiceRoll) Roll() int {
+	return dr.rollWith(diceRng.Intn)
+}
+
+func (dr *DiceRoll) rollWith(intn func(int) int) int {
+	if len(dr.Terms) == 0 {
+		dr.legacyTerms()
+	}
+	dr.Rolls = dr.Rolls[:0]
+	dr.Total = 0
+	for i := range dr.Terms {
+		t := &dr.Terms[i]
+		t.roll(intn)
+		dr.Total += t.Value
+		for _, d := range t.Dice {
+			if !d.Dropped {
+				dr.Rolls = append(dr.Rolls, d.Value)
+			}
+		}
+	}
 	return dr.Total
 }
 
+// DoubleDice doubles the number of dice in every dice term (a critical hit).
+// Keep/drop counts are left alone. The parser's limits still hold: a term stops
+// at maxDicePerTerm dice and the roll at maxDiceTotal, so an expression already
+// at a limit gains fewer dice than it has.
+func (dr *DiceRoll) DoubleDice() {
+	if len(dr.Terms) == 0 {
+		dr.legacyTerms()
+	}
+	total := 0
+	for _, t := range dr.Terms {
+		total += t.Count
+	}
+	first := true
+	for i := range dr.Terms {
+		t := &dr.Terms[i]
+		if extra := min(t.Count, maxDicePerTerm-t.Count, maxDiceTotal-total); extra > 0 {
+			t.Count += extra
+			total += extra
+		}
+		if first && t.Sides > 0 {
+			dr.NumDice, first = t.Count, false
+		}
+	}
+}
+
 func (dr *DiceRoll) String() string {
-	if dr.Modifier > 0 {
-		return fmt.Sprintf("%dd%d+%d", dr.NumDice, dr.DiceSides, dr.Modifier)
-	} else if dr.Modifier < 0 {
-		return fmt.Sprintf("%dd%d%d", dr.NumDice, dr.DiceSides, dr.Modifier)
+	if len(dr.Terms) == 0 {
+		if dr.Modifier > 0 {
+			return fmt.Sprintf("%dd%d+%d", dr.NumDice, dr.DiceSides, dr.Modifier)
+		} else if dr.Modifier < 0 {
+			return fmt.Sprintf("%dd%d%d", dr.NumDice, dr.DiceSides, dr.Modifier)
+		}
+		return fmt.Sprintf("%dd%d", dr.NumDice, dr.DiceSides)
+	}
+	var b strings.Builder
+	for i, t := range dr.Terms {
+		if t.Sign < 0 {
+			b.WriteString("-")
+		} else if i > 0 {
+			b.WriteString("+")
+		}
+		b.WriteString(t.notation())
 	}
-	return fmt.Sprintf("%dd%d", dr.NumDice, dr.DiceSides)
+	return b.String()
 }
 
+// ResultString shows how the total came about. A plain roll reads
+// "[4+5]+3 = 12"; an expression with several dice terms or labels names each
+// term: "2d6 [3+4] + 1d4[bless] [2] + 3 = 12".
 func (dr *DiceRoll) ResultString() string {
-	rollsStr := make([]string, len(dr.Rolls))
-	for i, r := range dr.Rolls {
-		rollsStr[i] = strconv.Itoa(r)
+	if len(dr.Terms) == 0 {
+		rollsStr := make([]string, len(dr.Rolls))
+		for i, r := range dr.Rolls {
+			rollsStr[i] = strconv.Itoa(r)
+		}
+		if dr.Modifier != 0 {
+			modStr := fmt.Sprintf("%+d", dr.Modifier)
+			return fmt.Sprintf("[%s]%s = %d", strings.Join(rollsStr, "+"), modStr, dr.Total)
+		}
+		return fmt.Sprintf("[%s] = %d", strings.Join(rollsStr, "+"), dr.Total)
+	}
+	named := dr.diceTerms() > 1
+	for _, t := range dr.Terms {
+		named = named || t.Label != ""
 	}
+	var b strings.Builder
+	for i, t := range dr.Terms {
+		op := "+"
+		if t.Sign < 0 {
+			op = "-"
+		}
+		switch {
+		case named && i > 0:
+			b.WriteString(" " + op + " ")
+		case i > 0 || t.Sign < 0:
+			b.WriteString(op)
+		}
+		switch {
+		case t.Sides == 0:
+			b.WriteString(t.notation())
+		case named:
+			b.WriteString(t.notation() + " " + t.diceString())
+		default:
+			b.WriteString(t.diceString())
+		}
+	}
+	fmt.Fprintf(&b, " = %d", dr.Total)
+	return b.String()
+}
 
-	if dr.Modifier != 0 {
-		modStr := fmt.Sprintf("%+d", dr.Modifier)
-		return fmt.Sprintf("[%s]%s = %d", strings.Join(rollsStr, "+"), modStr, dr.Total)
+// natural returns the face of the single kept d20 when the expression is a d20
+// test (1d20, 2d20kh1, 1d20+5, ...), or 0.
+func (dr *DiceRoll) natural() int {
+	if len(dr.Terms) == 0 {
+		if dr.NumDice == 1 && dr.DiceSides == 20 && len(dr.Rolls) > 0 {
+			return dr.Rolls[0]
+		}
+		return 0
 	}
-	return fmt.Sprintf("[%s] = %d", strings.Join(rollsStr, "+"), dr.Total)
+	if dr.diceTerms() != 1 {
+		return 0
+	}
+	for _, t := range dr.Terms {
+		if t.Sides == 0 {
+			continue
+		}
+		if t.Sides != 20 || t.Sign < 0 {
+			return 0
+		}
+		face := 0
+		for _, d := range t.Dice {
+			if !d.Dropped {
+				if face != 0 {
+					return 0
+				}
+				face = d.Value
+			}
+		}
+		return face
+	}
+	return 0
 }
 
 func (dr *DiceRoll) IsCriticalHit() bool {
-	return dr.NumDice == 1 && dr.DiceSides == 20 && len(dr.Rolls) > 0 && dr.Rolls[0] == 20
+	return dr.natural() == 20
 }
 
 func (dr *DiceRoll) IsCriticalFail() bool {
-	return dr.NumDice == 1 && dr.DiceSides == 20 && len(dr.Rolls) > 0 && dr.Rolls[0] == 1
+	return dr.natural() == 1
 }
 
 func RollDice(notation string) (*DiceRoll, error) {
@@ -139,6 +652,57 @@ func RollD20WithMod(modifier int) *DiceRoll {
 	return Roll(1, 20, modifier)
 }
 
+// D20Mode is how a d20 test is rolled: straight, with advantage (keep the higher
+// of two dice) or with disadvantage (keep the lower).
+type D20Mode int
+
+const (
+	D20Normal D20Mode = iota
+	D20Advantage
+	D20Disadvantage
+)
+
+// D20ModeFrom combines advantage/disadvantage flags; having both cancels out.
+func D20ModeFrom(advantage, disadvantage bool) D20Mode {
+	switch {
+	case advantage && !disadvantage:
+		return D20Advantage
+	case disadvantage && !advantage:
+		return D20Disadvantage
+	}
+	return D20Normal
+}
+
+func (m D20Mode) String() string {
+	switch m {
+	case D20Advantage:
+		return "advantage"
+	case D20Disadvantage:
+		return "disadvantage"
+	}
+	return "normal"
+}
+
+// RollD20Mode rolls a d20 test with the given mode. The returned roll is a plain
+// 1d20+modifier holding only the kept die (so IsCriticalHit/IsCriticalFail work
+// as usual); other is the discarded die, or 0 for a straight roll.
+func RollD20Mode(modifier int, mode D20Mode) (roll *DiceRoll, other int) {
+	roll = RollD20WithMod(modifier)
+	if mode == D20Normal {
+		return roll, 0
+	}
+	second := diceRng.Intn(20) + 1
+	kept := roll.Rolls[0]
+	if (mode == D20Advantage && second > kept) || (mode == D20Disadvantage && second < kept) {
+		kept, second = second, kept
+	}
+	roll.Rolls[0] = kept
+	roll.Terms[0].Dice[0].Value = kept
+	roll.Terms[0].Value = kept
+	roll.Total = kept + modifier
+	return roll, second
+}
+
 func RollAbilityScore() *DiceRoll {
 	rolls := make([]int, 4)
 	for i := 0; i < 4; i++ {
@@ -194,15 +758,6 @@ func (r *Roller) Roll(notation string) (*DiceRoll, error) {
 	if err != nil {
 		return nil, err
 	}
-
-	roll.Rolls = make([]int, roll.NumDice)
-	sum := 0
-	for i := 0; i < roll.NumDice; i++ {
-		result := r.rng.Intn(roll.DiceSides) + 1
-		roll.Rolls[i] = result
-		sum += result
-	}
-	roll.Total = sum + roll.Modifier
-
+	roll.rollWith(r.rng.Intn)
 	return roll, nil
 }
@@ -2,6 +2,8 @@ package engine
 
 import (
 	"testing"
+
+	"github.com/theburrowhub/thaimaturgy/internal/domain"
 )
 
 func TestParseDice(t *testing.T) {
@@ -207,3 +209,118 @@ func TestSeededRoller(t *testing.T) {
 		}
 	}
 }
+
+// scripted returns an intn that yields the given die faces in order.
+func scripted(faces ...int) func(int) int {
+	return func(int) int {
+		f := faces[0]
+		faces = faces[1:]
+		return f - 1
+	}
+}
+
+func TestParseDiceExpressions(t *testing.T) {
+	tests := []struct {
+		in, canonical string
+	}{
+		{"2d6 + 1d4 + 3", "2d6+1d4+3"},
+		{"4d6kh3", "4d6kh3"},
+		{"4D6K3", "4d6kh3"},
+		{"2d20kl1-1", "2d20kl1-1"},
+		{"4d6dl1", "4d6dl1"},
+		{"1d6!", "1d6!"},
+		{"2d6r1r2", "2d6r1r2"},
+		{"2d6r<2", "2d6r<2"},
+		{"d%", "1d%"},
+		{"1d8+3 [slashing] + 2d6[Fire]", "1d8+3[slashing]+2d6[Fire]"},
+		{"-1d4+10", "-1d4+10"},
+	}
+	for _, tt := range tests {
+		dr, err := ParseDice(tt.in)
+		if err != nil {
+			t.Errorf("ParseDice(%q) error: %v", tt.in, err)
+			continue
+		}
+		if got := dr.String(); got != tt.canonical {
+			t.Errorf("ParseDice(%q).String() = %q, want %q", tt.in, got, tt.canonical)
+		}
+	}
+	for _, bad := range []string{"3+2", "2d6+", "4d6kh0", "1d1!", "1d6r<6", "1d6r7", "2d6[fire", "2d6*2", "101d6", "1d6kh1kl1"} {
+		if _, err := ParseDice(bad); err == nil {
+			t.Errorf("ParseDice(%q) should fail", bad)
+		}
+	}
+}
+
+func TestDiceExpressionEvaluation(t *testing.T) {
+	tests := []struct {
+		in     string
+		faces  []int
+		total  int
+		result string
+	}{
+		{"2d6+3", []int{4, 5}, 12, "[4+5]+3 = 12"},
+		{"4d6kh3", []int{3, 6, 1, 5}, 14, "[3+6+5, dropped 1] = 14"},
+		{"2d20kl1+5", []int{17, 4}, 9, "[4, dropped 17]+5 = 9"},
+		{"1d6!", []int{6, 6, 2}, 14, "[6!+6!+2] = 14"},
+		{"2d6r1", []int{1, 3, 5}, 8, "[1→3+5] = 8"},
+		{"2d6r<2", []int{2, 2, 6}, 8, "[2→2+6] = 8"},
+		{"d%", []int{42}, 42, "[42] = 42"},
+		{"2d6+1d4[bless]-1", []int{3, 4, 2}, 8, "2d6 [3+4] + 1d4[bless] [2] - 1 = 8"},
+	}
+	for _, tt := range tests {
+		dr, err := ParseDice(tt.in)
+		if err != nil {
+			t.Fatalf("ParseDice(%q): %v", tt.in, err)
+		}
+		if got := dr.rollWith(scripted(tt.faces...)); got != tt.total {
+			t.Errorf("%s total = %d, want %d", tt.in, got, tt.total)
+		}
+		if got := dr.ResultString(); got != tt.result {
+			t.Errorf("%s ResultString() = %q, want %q", tt.in, got, tt.result)
+		}
+	}
+}
+
+func TestDiceExpressionCriticals(t *testing.T) {
+	dr, _ := ParseDice("2d20kh1+7")
+	dr.rollWith(scripted(3, 20))
+	if !dr.IsCriticalHit() {
+		t.Error("advantage keeping a 20 should be a critical hit")
+	}
+	dr, _ = ParseDice("1d20+1d4")
+	dr.rollWith(scripted(20, 2))
+	if dr.IsCriticalHit() {
+		t.Error("a d20 summed with other dice is not a natural roll")
+	}
+	dr, _ = ParseDice("1d6+2d8+1")
+	dr.DoubleDice()
+	dr.Roll()
+	if len(dr.Rolls) != 6 {
+		t.Errorf("DoubleDice should double every dice term, rolled %v", dr.Rolls)
+	}
+
+	// Doubling respects the parser's limits.
+	dr, _ = ParseDice("100d6+60d4")
+	dr.DoubleDice()
+	if dr.Terms[0].Count != maxDicePerTerm || dr.Terms[1].Count != maxDiceTotal-maxDicePerTerm {
+		t.Errorf("doubled counts = %d, %d", dr.Terms[0].Count, dr.Terms[1].Count)
+	}
+	dr, _ = ParseDice("60d6")
+	dr.DoubleDice()
+	if dr.Terms[0].Count != maxDicePerTerm || dr.NumDice != maxDicePerTerm {
+		t.Errorf("60d6 doubled to %d (NumDice %d), want the %d cap", dr.Terms[0].Count, dr.NumDice, maxDicePerTerm)
+	}
+}
+
+func TestRollTablePercentile(t *testing.T) {
+	table := &domain.Table{Dice: "d%", Rows: []domain.TableRow{
+		{Roll: "01-50", Cells: []string{"low"}},
+		{Roll: "51-00", Cells: []string{"high"}},
+	}}
+	for range 20 {
+		if n, row := RollTable(table); row == nil || n < 1 || n > 100 {
+			t.Fatalf("RollTable(d%%) = %d, %v", n, row)
+		}
+	}
+}
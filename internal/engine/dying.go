@@ -0,0 +1,146 @@
+package engine
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/theburrowhub/thaimaturgy/internal/domain"
+	"github.com/theburrowhub/thaimaturgy/internal/types"
+)
+
+// This file wires the domain dying rules (0 HP, death saves, stabilization,
+// revival) into the death_save tool, the /deathsave command and the combat
+// tracker. The engine only contributes the d20.
+
+// hpStatus is a party member's HP line, with the dying state when at 0 HP:
+// "Kael HP: 0/12 — dying (1 success, 0 failures)".
+func hpStatus(c *domain.Character) string {
+	s := fmt.Sprintf("%s HP: %d/%d", c.Name, c.CurrentHP, c.MaxHP)
+	if st := c.DyingStatus(); st != "" {
+		s += " — " + st
+	}
+	return s
+}
+
+// syncPartyCombatant marks a party member's combatant defeated once they die
+// (and back in the fight if they are revived), so the turn order skips the dead
+// but keeps a dying character's turn for their death saves.
+func (tr *ToolRouter) syncPartyCombatant(name string) {
+	c := tr.state().CombatSnapshot()
+	pc := partyMember(tr.state().PartySnapshot(), name)
+	if c == nil || pc == nil {
+		return
+	}
+	for _, cb := range c.Combatants {
+		if cb.Side == domain.SideParty && strings.EqualFold(cb.Character, pc.Name) && cb.Defeated != pc.Dead {
+			tr.state().SetCombatantDefeated(cb.Name, pc.Dead)
+		}
+	}
+}
+
+// SyncPartyCombatant is syncPartyCombatant for HP changes made outside the tools,
+// such as a player editing their own sheet from a chat.
+func SyncPartyCombatant(session *domain.Session, name string) {
+	NewToolRouter(session).syncPartyCombatant(name)
+}
+
+// dyingTurnHint reminds the DM that the combatant now acting must roll a death
+// save; "" when it isn't a dying party member.
+func (tr *ToolRouter) dyingTurnHint(cb domain.Combatant) string {
+	if cb.Side != domain.SideParty {
+		return ""
+	}
+	if pc := partyMember(tr.state().PartySnapshot(), cb.Character); pc != nil && pc.IsDying() {
+		return fmt.Sprintf("%s is dying — roll their death save (death_save) before anything else.\n", pc.Name)
+	}
+	return ""
+}
+
+func (tr *ToolRouter) deathSave(id string, args map[string]any) types.ToolResult {
+	stabilize, _ := args["stabilize"].(bool)
+	reviveHP, revive := intArg(args, "revive")
+	var failure error
+	var name, msg string
+	var data map[string]any
+	// The closure logs nothing itself (an empty message): the entry is appended
+	// below as a roll, with the tally in Data.
+	res := tr.mutatePC(id, args, func(c *domain.Character) string {
+		name = c.Name
+		if revive {
+			if !c.Revive(reviveHP) {
+				failure = fmt.Errorf("%s is not dead (%s)", c.Name, orUp(c.DyingStatus()))
+				return ""
+			}
+			msg = fmt.Sprintf("%s is brought back from death: %s", c.Name, hpStatus(c))
+			data = map[string]any{"revived": true, "hp": c.CurrentHP}
+			return ""
+		}
+		if stabilize {
+			if !c.Stabilize() {
+				failure = fmt.Errorf("%s is not dying (%s)", c.Name, orUp(c.DyingStatus()))
+				return ""
+			}
+			msg = c.Name + " is stabilized: unconscious at 0 HP but no longer dying"
+			data = map[string]any{"stabilized": true}
+			return ""
+		}
+		sv, err := c.DeathSave(Roll(1, 20, 0).Total)
+		if err != nil {
+			failure = err
+			return ""
+		}
+		msg = sv.String()
+		data = map[string]any{"death_save": true, "d20": sv.Roll, "success": sv.Success,
+			"successes": sv.Successes, "failures": sv.Failures, "died": sv.Died, "revived": sv.Revived}
+		return ""
+	})
+	if res.Error != "" {
+		return res
+	}
+	if failure != nil {
+		return errResult(id, failure.Error())
+	}
+	tr.state().AppendLog(domain.LogEntry{Type: domain.LogRoll, Message: msg, Data: data})
+	tr.session.MarkModified()
+	tr.syncPartyCombatant(name)
+	return okResult(id, msg)
+}
+
+// orUp describes a conscious character for error messages.
+func orUp(status string) string {
+	if status == "" {
+		return "conscious"
+	}
+	return status
+}
+
+func (h *CommandHandler) handleDeathSave(cmd *Command, r *CommandResult) {
+	args := map[string]any{}
+	var name []string
+	for i := 0; i < len(cmd.Args); i++ {
+		switch a := cmd.Args[i]; strings.ToLower(a) {
+		case "stabilize", "stabilise", "stable", "estabilizar":
+			args["stabilize"] = true
+		case "revive", "revivir":
+			args["revive"] = 1
+			if i+1 < len(cmd.Args) {
+				if hp, err := strconv.Atoi(cmd.Args[i+1]); err == nil {
+					args["revive"] = hp
+					i++
+				}
+			}
+		default:
+			name = append(name, a)
+		}
+	}
+	if len(name) > 0 {
+		args["character"] = strings.Join(name, " ")
+	}
+	res := NewToolRouter(h.session).deathSave("", args)
+	if res.Error != "" {
+		r.Success, r.Message = false, res.Error+". Usage: /deathsave [character] [stabilize | revive [hp]]"
+		return
+	}
+	r.Message = res.Content
+}
@@ -0,0 +1,92 @@
+package engine
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/theburrowhub/thaimaturgy/internal/domain"
+)
+
+func TestDownedCharacterInCombat(t *testing.T) {
+	session, tr := skeletonFight(t)
+	pc := soloParty(session, domain.NewCharacter("Kael", "Elf", "Wizard"))
+	pc.MaxHP, pc.CurrentHP = 20, 4
+	combatCall(tr, "end_combat", nil)
+	combatCall(tr, "start_combat", map[string]any{"combatants": []any{
+		map[string]any{"name": "Kael", "initiative": 20},
+		map[string]any{"name": "Skeleton", "initiative": 10, "hp": 40},
+	}})
+
+	r := combatCall(tr, "apply_damage", map[string]any{"target": "Kael", "amount": 6, "damage_type": "slashing"})
+	if r.Error != "" || !strings.Contains(r.Content, "unconscious and dying") || !pc.IsDying() {
+		t.Fatalf("drop to 0 = %+v", r)
+	}
+	if r := combatCall(tr, "end_turn", nil); !strings.Contains(r.Content, "Kael (party) HP 0/20 AC 10 — dying") {
+		t.Errorf("the tracker should show the dying state: %s", r.Content)
+	}
+	if r := combatCall(tr, "end_turn", nil); !strings.Contains(r.Content, "Kael is dying — roll their death save") {
+		t.Errorf("a dying character's turn should prompt a death save: %s", r.Content)
+	}
+
+	r = combatCall(tr, "death_save", map[string]any{"character": "Kael"})
+	if r.Error != "" || !strings.Contains(r.Content, "Kael death save: d20 = ") {
+		t.Fatalf("death_save = %+v", r)
+	}
+	if e := lastLog(session); e.Type != domain.LogRoll || e.Data["death_save"] != true {
+		t.Errorf("death save log entry = %+v", e)
+	}
+
+	// A critical hit while down finishes them off if the tally allows; force it.
+	pc.CurrentHP, pc.Dead, pc.Stable, pc.DeathFailures = 0, false, false, 1
+	combatCall(tr, "apply_damage", map[string]any{"target": "Kael", "amount": 2, "critical": true})
+	if !pc.Dead || !session.State.CombatSnapshot().Find("Kael").Defeated {
+		t.Errorf("dead character should be defeated in the tracker: %+v", pc)
+	}
+	if r := combatCall(tr, "death_save", map[string]any{"character": "Kael"}); !strings.Contains(r.Error, "dead") {
+		t.Errorf("the dead make no death saves: %+v", r)
+	}
+	if !strings.Contains(FormatCharacter(pc), "HP: 0/20 — DEAD") {
+		t.Errorf("sheet should show the state:\n%s", FormatCharacter(pc))
+	}
+
+	// Setting HP doesn't raise the dead; an explicit revive does, and puts them
+	// back in the order.
+	if r := combatCall(tr, "update_hp", map[string]any{"character": "Kael", "set": 5}); !pc.Dead || !strings.Contains(r.Content, "no effect") {
+		t.Errorf("update_hp set on the dead = %+v", r)
+	}
+	r = combatCall(tr, "death_save", map[string]any{"character": "Kael", "revive": 5})
+	if r.Error != "" || pc.Dead || pc.CurrentHP != 5 || session.State.CombatSnapshot().Find("Kael").Defeated {
+		t.Errorf("a revived character should rejoin the fight: %+v", r)
+	}
+	if r := combatCall(tr, "death_save", map[string]any{"character": "Kael", "revive": 5}); !strings.Contains(r.Error, "not dead") {
+		t.Errorf("only the dead can be revived: %+v", r)
+	}
+}
+
+func TestDeathSaveStabilizeAndCommand(t *testing.T) {
+	session := createTestSession()
+	pc := soloParty(session, domain.NewCharacter("Mira", "Human", "Cleric"))
+	tr := NewToolRouter(session)
+	if r := combatCall(tr, "death_save", nil); !strings.Contains(r.Error, "not dying") {
+		t.Errorf("a conscious character can't save: %+v", r)
+	}
+	if r := combatCall(tr, "update_hp", map[string]any{"delta": -100}); !strings.Contains(r.Content, "killed outright") {
+		t.Errorf("100 damage at 10 max HP is massive damage: %+v", r)
+	}
+
+	h := NewCommandHandler(session)
+	if res := h.Execute(ParseCommand("/deathsave Mira revive 3")); !res.Success || pc.Dead || pc.CurrentHP != 3 {
+		t.Fatalf("/deathsave revive = %+v", res)
+	}
+	pc.SetHP(0)
+	if res := h.Execute(ParseCommand("/deathsave Mira stabilize")); !res.Success || !pc.Stable {
+		t.Fatalf("/deathsave stabilize = %+v", res)
+	}
+	if res := h.Execute(ParseCommand("/ds")); res.Success || !strings.Contains(res.Message, "stable") {
+		t.Errorf("a stable character makes no saves: %+v", res)
+	}
+	combatCall(tr, "update_hp", map[string]any{"delta": 4})
+	if pc.CurrentHP != 4 || pc.Stable || pc.HasCondition(domain.ConditionUnconscious) {
+		t.Errorf("healing should wake a stable character: %+v", pc)
+	}
+}
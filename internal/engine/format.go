@@ -5,6 +5,7 @@ import (
 	"strings"
 
 	"github.com/theburrowhub/thaimaturgy/internal/domain"
+	"github.com/theburrowhub/thaimaturgy/internal/srd"
 )
 
 // This file renders authored adventure content into readable text blocks,
@@ -24,6 +25,8 @@ func LogIcon(t domain.LogEntryType) string {
 		return "🚩"
 	case domain.LogParty:
 		return "👥"
+	case domain.LogCombat:
+		return "⚔️"
 	case domain.LogSystem:
 		return "⚙️"
 	case domain.LogWorld:
@@ -66,91 +69,150 @@ func effectiveRoom(scene *domain.Scene, room *domain.Room) (*domain.Room, string
 	return &eff, sr.Present
 }
 
-// FormatRoom renders a room with its read-aloud text and DM notes.
-func FormatRoom(adv *domain.Adventure, r *domain.Room) string {
+// FormatRoom renders a room with its read-aloud text and exits, then its DM
+// notes, features, encounters and treasure inside the DM-ONLY fence. Encounters
+// carry their XP budget, rated against st's party. In virtual-DM mode, secret
+// features, exits and NPCs whose reveal_when doesn't hold yet are left out (the
+// fence only says something is withheld, and under what condition); otherwise
+// they are shown with their condition. st may be nil (no party, no gating).
+func FormatRoom(adv *domain.Adventure, r *domain.Room, st *domain.SessionState) string {
 	if r == nil {
 		return "(unknown room)"
 	}
+	gate := gated(st)
+	revealed := func(v domain.Visibility) bool { return !v.Secret || st != nil && st.IsRevealed(v, r.ID) }
+	var party []domain.Character
+	if st != nil {
+		party = st.PartySnapshot()
+	}
+	var hidden []string
+
 	var sb strings.Builder
-	fmt.Fprintf(&sb, "ROOM: %s [%s]\n", r.Name, r.ID)
+	roomTag := ""
+	if r.Secret {
+		roomTag = secretTag(r.Visibility, st != nil && st.RoomRevealed(r))
+	}
+	fmt.Fprintf(&sb, "ROOM: %s [%s]%s\n", r.Name, r.ID, roomTag)
 	if r.ReadAloud != "" {
 		sb.WriteString("\nRead-aloud:\n")
 		sb.WriteString(indent(r.ReadAloud))
 		sb.WriteString("\n")
 	}
-	if r.DMNotes != "" {
-		sb.WriteString("\nDM notes:\n")
-		sb.WriteString(indent(r.DMNotes))
-		sb.WriteString("\n")
-	}
 	if len(r.NPCIDs) > 0 {
-		sb.WriteString("\nNPCs present: ")
 		names := make([]string, 0, len(r.NPCIDs))
 		for _, id := range r.NPCIDs {
-			if n := adv.NPC(id); n != nil {
-				names = append(names, fmt.Sprintf("%s [%s]", n.Name, n.ID))
-			} else {
+			n := adv.NPC(id)
+			if n == nil {
 				names = append(names, id)
+				continue
 			}
+			shown := !n.Secret || st != nil && st.NPCRevealed(n)
+			if gate && !shown {
+				hidden = append(hidden, withheldEntry("an NPC", n.Visibility))
+				continue
+			}
+			names = append(names, fmt.Sprintf("%s [%s]%s", n.Name, n.ID, secretTag(n.Visibility, shown)))
+		}
+		if len(names) > 0 {
+			sb.WriteString("\nNPCs present: " + strings.Join(names, ", ") + "\n")
 		}
-		sb.WriteString(strings.Join(names, ", ") + "\n")
 	}
-	if len(r.Features) > 0 {
-		sb.WriteString("\nFeatures:\n")
-		for _, f := range r.Features {
-			line := "  - " + f.Name
-			if f.Skill != "" {
-				line += fmt.Sprintf(" (%s", f.Skill)
-				if f.DC > 0 {
-					line += fmt.Sprintf(" DC %d", f.DC)
-				}
-				line += ")"
-			}
-			if f.Description != "" {
-				line += ": " + f.Description
+	var exits []string
+	for _, ex := range r.Exits {
+		shown, cond := exitShown(adv, st, r.ID, ex)
+		if gate && !shown {
+			hidden = append(hidden, withheldEntry("an exit", cond))
+			continue
+		}
+		label := ex.Direction
+		if label == "" {
+			label = "→"
+		}
+		line := fmt.Sprintf("  - %s to %s [%s]%s", label, exitTargetName(adv, ex.To), ex.To, secretTag(ex.Visibility, shown))
+		if ex.Locked {
+			line += " (locked)"
+		}
+		if ex.Description != "" {
+			line += ": " + ex.Description
+		}
+		exits = append(exits, line)
+	}
+	if len(exits) > 0 {
+		sb.WriteString("\nExits:\n" + strings.Join(exits, "\n") + "\n")
+	}
+
+	var dm strings.Builder
+	if r.DMNotes != "" {
+		dm.WriteString("DM notes:\n")
+		dm.WriteString(indent(r.DMNotes))
+		dm.WriteString("\n")
+	}
+	var features []string
+	for _, f := range r.Features {
+		shown := revealed(f.Visibility)
+		if gate && !shown {
+			hidden = append(hidden, withheldEntry("a feature", f.Visibility))
+			continue
+		}
+		line := "  - " + f.Name + secretTag(f.Visibility, shown)
+		if f.Skill != "" {
+			line += fmt.Sprintf(" (%s", f.Skill)
+			if f.DC > 0 {
+				line += fmt.Sprintf(" DC %d", f.DC)
 			}
-			sb.WriteString(line + "\n")
+			line += ")"
+		}
+		if f.Description != "" {
+			line += ": " + f.Description
 		}
+		features = append(features, line)
+	}
+	if len(features) > 0 {
+		dm.WriteString("Features:\n" + strings.Join(features, "\n") + "\n")
 	}
 	if len(r.Encounters) > 0 {
-		sb.WriteString("\nEncounters:\n")
+		dm.WriteString("Encounters:\n")
 		for _, e := range r.Encounters {
-			sb.WriteString(fmt.Sprintf("  - %s", e.Name))
+			dm.WriteString(fmt.Sprintf("  - %s", e.Name))
 			if e.Difficulty != "" {
-				sb.WriteString(fmt.Sprintf(" [%s]", e.Difficulty))
+				dm.WriteString(fmt.Sprintf(" [%s]", e.Difficulty))
 			}
-			sb.WriteString("\n")
+			dm.WriteString("\n")
 			if e.Description != "" {
-				sb.WriteString(indent(e.Description) + "\n")
+				dm.WriteString(indent(e.Description) + "\n")
 			}
+			dm.WriteString(encounterBudgetLines(adv, &e, party))
 		}
 	}
 	if len(r.Treasure) > 0 {
-		sb.WriteString("\nTreasure: " + strings.Join(r.Treasure, ", ") + "\n")
-	}
-	if len(r.Exits) > 0 {
-		sb.WriteString("\nExits:\n")
-		for _, ex := range r.Exits {
-			label := ex.Direction
-			if label == "" {
-				label = "→"
-			}
-			line := fmt.Sprintf("  - %s to %s [%s]", label, exitTargetName(adv, ex.To), ex.To)
-			if ex.Locked {
-				line += " (locked)"
-			}
-			if ex.Description != "" {
-				line += ": " + ex.Description
-			}
-			sb.WriteString(line + "\n")
-		}
+		dm.WriteString("Treasure: " + strings.Join(r.Treasure, ", ") + "\n")
 	}
+	dm.WriteString(withheldLine(hidden))
+	writeDMOnly(&sb, dm.String())
 	writeImageLines(&sb, adv.RoomImages(r))
 	return strings.TrimRight(sb.String(), "\n")
 }
 
-// FormatNPC renders an NPC dossier: roleplay guidance and mechanics.
-func FormatNPC(adv *domain.Adventure, n *domain.NPC) string {
+// encounterBudgetLines renders an encounter's creatures and XP budget (rated for
+// the living party members) under its entry, plus any creatures that resolve to
+// no stat block; "" when it lists no creatures.
+func encounterBudgetLines(adv *domain.Adventure, e *domain.Encounter, party []domain.Character) string {
+	if len(e.Creatures) == 0 {
+		return ""
+	}
+	b := adv.EncounterBudget(e, srd.Lookup)
+	out := "    Creatures: " + strings.Join(e.Creatures, ", ") + "\n"
+	out += "    Budget: " + b.Summary(domain.PartyLevels(party)) + "\n"
+	if len(b.Unresolved) > 0 {
+		out += "    No stat block (not counted): " + strings.Join(b.Unresolved, ", ") + "\n"
+	}
+	return out
+}
+
+// FormatNPC renders an NPC dossier: what the players can perceive, then the
+// roleplay guidance and mechanics inside the DM-ONLY fence. st (which may be nil)
+// decides the secret tag; callers leave out an NPC the grounding withholds.
+func FormatNPC(adv *domain.Adventure, n *domain.NPC, st *domain.SessionState) string {
 	if n == nil {
 		return "(unknown NPC)"
 	}
@@ -159,12 +221,11 @@ func FormatNPC(adv *domain.Adventure, n *domain.NPC) string {
 	if n.Role != "" {
 		fmt.Fprintf(&sb, " — %s", n.Role)
 	}
+	if n.Secret {
+		sb.WriteString(secretTag(n.Visibility, st != nil && st.NPCRevealed(n)))
+	}
 	sb.WriteString("\n")
 	writeField(&sb, "Appearance", n.Appearance)
-	writeField(&sb, "Personality", n.Personality)
-	writeField(&sb, "Motivations", n.Motivations)
-	writeField(&sb, "Secrets", n.Secrets)
-	writeField(&sb, "Voice", n.Voice)
 	writeField(&sb, "Disposition", n.Disposition)
 	if len(n.Knowledge) > 0 {
 		sb.WriteString("Knows:\n")
@@ -178,9 +239,15 @@ func FormatNPC(adv *domain.Adventure, n *domain.NPC) string {
 			sb.WriteString("  \"" + d + "\"\n")
 		}
 	}
+	var dm strings.Builder
+	writeField(&dm, "Personality", n.Personality)
+	writeField(&dm, "Motivations", n.Motivations)
+	writeField(&dm, "Secrets", n.Secrets)
+	writeField(&dm, "Voice", n.Voice)
 	if n.StatBlock != nil {
-		sb.WriteString(formatStatBlock(n.StatBlock))
+		dm.WriteString(formatStatBlock(n.StatBlock))
 	}
+	writeDMOnly(&sb, dm.String())
 	writeImageLines(&sb, adv.NPCImages(n))
 	return strings.TrimRight(sb.String(), "\n")
 }
@@ -421,36 +488,49 @@ func FormatWorldState(label, description string, changes []domain.WorldChange) s
 	return FormatWorldChanges(changes)
 }
 
-// FormatEvent renders a scripted event.
-func FormatEvent(e *domain.Event) string {
+// FormatEvent renders a scripted event: its description and read-aloud, then
+// its trigger, DM notes, consequences and outcomes inside the DM-ONLY fence.
+func FormatEvent(e *domain.Event, st *domain.SessionState) string {
 	if e == nil {
 		return "(unknown event)"
 	}
 	var sb strings.Builder
-	fmt.Fprintf(&sb, "EVENT: %s [%s]\n", e.Name, e.ID)
-	writeField(&sb, "Trigger", e.Trigger)
+	fmt.Fprintf(&sb, "EVENT: %s [%s]", e.Name, e.ID)
+	if e.Secret {
+		sb.WriteString(secretTag(e.Visibility, st != nil && st.EventRevealed(e)))
+	}
+	sb.WriteString("\n")
 	writeField(&sb, "Description", e.Description)
 	if e.ReadAloud != "" {
 		sb.WriteString("Read-aloud:\n" + indent(e.ReadAloud) + "\n")
 	}
-	writeField(&sb, "DM notes", e.DMNotes)
-	writeField(&sb, "Consequences", e.Consequences)
+	var dm strings.Builder
+	writeField(&dm, "Trigger", e.Trigger)
+	writeField(&dm, "DM notes", e.DMNotes)
+	writeField(&dm, "Consequences", e.Consequences)
 	for _, o := range e.Outcomes {
-		fmt.Fprintf(&sb, "  If %s → %s\n", o.Condition, o.Result)
+		fmt.Fprintf(&dm, "  If %s → %s\n", o.Condition, o.Result)
 	}
+	writeDMOnly(&sb, dm.String())
 	return strings.TrimRight(sb.String(), "\n")
 }
 
-// FormatItem renders an item entry.
-func FormatItem(adv *domain.Adventure, it *domain.Item) string {
+// FormatItem renders an item entry, its mechanics inside the DM-ONLY fence.
+func FormatItem(adv *domain.Adventure, it *domain.Item, st *domain.SessionState) string {
 	if it == nil {
 		return "(unknown item)"
 	}
 	var sb strings.Builder
-	fmt.Fprintf(&sb, "ITEM: %s [%s]\n", it.Name, it.ID)
+	fmt.Fprintf(&sb, "ITEM: %s [%s]", it.Name, it.ID)
+	if it.Secret {
+		sb.WriteString(secretTag(it.Visibility, st != nil && st.IsRevealed(it.Visibility, "")))
+	}
+	sb.WriteString("\n")
 	writeField(&sb, "Rarity", it.Rarity)
 	writeField(&sb, "Description", it.Description)
-	writeField(&sb, "Mechanics", it.Mechanics)
+	if strings.TrimSpace(it.Mechanics) != "" {
+		writeDMOnly(&sb, "Mechanics: "+it.Mechanics)
+	}
 	writeImageLines(&sb, adv.ItemImages(it))
 	return strings.TrimRight(sb.String(), "\n")
 }
@@ -470,6 +550,9 @@ func FormatCharacter(c *domain.Character) string {
 	if c.TempHP > 0 {
 		fmt.Fprintf(&sb, " (+%d temp)", c.TempHP)
 	}
+	if st := c.DyingStatus(); st != "" {
+		sb.WriteString(" — " + strings.ToUpper(st))
+	}
 	fmt.Fprintf(&sb, " | AC: %d | Speed: %d | Prof: +%d", c.AC, c.Speed, c.ProficiencyBonus)
 	if c.Inspiration {
 		sb.WriteString(" | Inspiration")
@@ -577,6 +660,80 @@ func FormatParty(party []domain.Character) string {
 	return strings.Join(blocks, "\n\n")
 }
 
+// HealthBand describes a creature's HP the way players perceive it — never as
+// exact numbers (information discipline, #28).
+func HealthBand(current, max int) string {
+	switch {
+	case current <= 0:
+		return "Down"
+	case max <= 0 || current >= max:
+		return "Unharmed"
+	case current*2 > max:
+		return "Hurt"
+	default:
+		return "Bloodied"
+	}
+}
+
+// FormatCombat renders the tracked fight in initiative order with a marker on
+// the combatant whose turn it is. Party members show their live sheet HP/AC and
+// conditions (party is a PartySnapshot). With playerView, everyone else's HP is
+// shown only as a HealthBand and their AC is hidden, so the view is safe to show
+// players.
+func FormatCombat(c *domain.CombatState, party []domain.Character, playerView bool) string {
+	if c == nil {
+		return "No combat in progress."
+	}
+	sheets := make(map[string]*domain.Character, len(party))
+	for i := range party {
+		sheets[strings.ToLower(party[i].Name)] = &party[i]
+	}
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "Round %d — initiative order:\n", c.Round)
+	for i, cb := range c.Combatants {
+		marker := "  "
+		if i == c.Turn {
+			marker = "▶ "
+		}
+		hp, maxHP, ac, conds := cb.CurrentHP, cb.MaxHP, cb.AC, cb.Conditions
+		dying := ""
+		if pc := sheets[strings.ToLower(cb.Character)]; cb.Side == domain.SideParty && pc != nil {
+			hp, maxHP, ac, conds = pc.CurrentHP, pc.MaxHP, pc.AC, pc.Conditions
+			dying = pc.DyingStatus()
+		}
+		fmt.Fprintf(&sb, "%s%2d  %s (%s)", marker, cb.Initiative, cb.Name, cb.Side)
+		switch {
+		case cb.Defeated && dying == "dead":
+			sb.WriteString(" — dead")
+		case cb.Defeated:
+			sb.WriteString(" — defeated")
+		case playerView && cb.Side != domain.SideParty:
+			if maxHP > 0 {
+				sb.WriteString(" — " + HealthBand(hp, maxHP))
+			}
+		default:
+			if maxHP > 0 {
+				fmt.Fprintf(&sb, " HP %d/%d", hp, maxHP)
+			}
+			if ac > 0 {
+				fmt.Fprintf(&sb, " AC %d", ac)
+			}
+			if dying != "" {
+				sb.WriteString(" — " + dying)
+			}
+		}
+		if len(conds) > 0 && !cb.Defeated {
+			names := make([]string, len(conds))
+			for j, cd := range conds {
+				names[j] = string(cd)
+			}
+			sb.WriteString(" [" + strings.Join(names, ", ") + "]")
+		}
+		sb.WriteString("\n")
+	}
+	return strings.TrimRight(sb.String(), "\n")
+}
+
 func writeField(sb *strings.Builder, label, value string) {
 	if strings.TrimSpace(value) == "" {
 		return
@@ -15,6 +15,9 @@ type fakeProvider struct{ lastUser string }
 func (f *fakeProvider) Name() string         { return "fake" }
 func (f *fakeProvider) SupportsTools() bool  { return true }
 func (f *fakeProvider) SupportsVision() bool { return false }
+func (f *fakeProvider) ChatStream(ctx context.Context, req providers.ChatRequest, _ providers.StreamFunc) (*providers.ChatResponse, error) {
+	return f.Chat(ctx, req)
+}
 func (f *fakeProvider) Chat(_ context.Context, req providers.ChatRequest) (*providers.ChatResponse, error) {
 	for _, m := range req.Messages {
 		if m.Role == providers.RoleUser {
@@ -0,0 +1,103 @@
+package engine
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/theburrowhub/thaimaturgy/internal/domain"
+	"github.com/theburrowhub/thaimaturgy/internal/types"
+)
+
+// This file wires the domain level-up rules into the oracle tool and the
+// /levelup command. The engine only contributes the hit-die rolls; the sheet
+// arithmetic lives in domain.Character.LevelUp.
+
+// levelUpCharacter levels c up: every level its XP has earned, or exactly one
+// when milestone is set (the DM awards levels without XP). With roll the hit die
+// is rolled per level; otherwise the fixed average is taken.
+func levelUpCharacter(c *domain.Character, roll, milestone bool) ([]domain.LevelUpResult, error) {
+	n := c.LevelsPending()
+	if milestone {
+		n = 1
+	}
+	if n == 0 {
+		if c.Level >= domain.MaxLevel {
+			return nil, fmt.Errorf("%s is already level %d", c.Name, domain.MaxLevel)
+		}
+		return nil, fmt.Errorf("%s has %d XP; level %d needs %d (or use a milestone level-up)",
+			c.Name, c.XP, c.Level+1, domain.XPForLevel(c.Level+1))
+	}
+	var out []domain.LevelUpResult
+	for range n {
+		hd := 0
+		if roll {
+			hd = Roll(1, domain.HitDieFor(c.Class), 0).Total
+		}
+		res, err := c.LevelUp(hd)
+		if err != nil {
+			if len(out) > 0 {
+				break
+			}
+			return nil, err
+		}
+		out = append(out, res)
+	}
+	return out, nil
+}
+
+func (tr *ToolRouter) levelUp(id string, args map[string]any) types.ToolResult {
+	method, _ := args["hp"].(string)
+	roll := strings.EqualFold(strings.TrimSpace(method), "roll")
+	milestone, _ := args["milestone"].(bool)
+	var failure error
+	res := tr.mutatePC(id, args, func(c *domain.Character) string {
+		levels, err := levelUpCharacter(c, roll, milestone)
+		if err != nil {
+			failure = err
+			return ""
+		}
+		lines := make([]string, len(levels))
+		for i, l := range levels {
+			lines[i] = l.String()
+		}
+		return strings.Join(lines, "\n")
+	})
+	if failure != nil {
+		return errResult(id, failure.Error())
+	}
+	return res
+}
+
+// levelUpHint is appended to an XP award when the total has earned a level.
+func levelUpHint(c *domain.Character) string {
+	if c.LevelsPending() == 0 {
+		return ""
+	}
+	return fmt.Sprintf(" — enough for level %d (level up to apply it)", domain.LevelForXP(c.XP))
+}
+
+func (h *CommandHandler) handleLevelUp(cmd *Command, r *CommandResult) {
+	args := map[string]any{}
+	var name []string
+	for _, a := range cmd.Args {
+		switch strings.ToLower(a) {
+		case "roll", "rolled":
+			args["hp"] = "roll"
+		case "average", "avg", "fixed":
+			args["hp"] = "average"
+		case "milestone", "force":
+			args["milestone"] = true
+		default:
+			name = append(name, a)
+		}
+	}
+	if len(name) > 0 {
+		args["character"] = strings.Join(name, " ")
+	}
+	res := NewToolRouter(h.session).levelUp("", args)
+	if res.Error != "" {
+		r.Success, r.Message = false, res.Error+". Usage: /levelup [character] [roll|average] [milestone]"
+		return
+	}
+	r.Message = res.Content
+}
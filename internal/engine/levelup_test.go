@@ -0,0 +1,54 @@
+package engine
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/theburrowhub/thaimaturgy/internal/domain"
+)
+
+func TestLevelUpTool(t *testing.T) {
+	session := createTestSession()
+	pc := soloParty(session, domain.GenerateCharacter("Kael", "Elf", "Rogue", 1))
+	tr := NewToolRouter(session)
+
+	if r := combatCall(tr, "level_up", map[string]any{"character": "Kael"}); r.Error == "" || !strings.Contains(r.Error, "needs 300") {
+		t.Fatalf("level_up without the XP should fail: %+v", r)
+	}
+	r := combatCall(tr, "award_xp", map[string]any{"character": "Kael", "amount": 1000})
+	if !strings.Contains(r.Content, "enough for level 3") {
+		t.Errorf("award_xp should flag the pending level: %q", r.Content)
+	}
+	maxHP := pc.MaxHP
+	r = combatCall(tr, "level_up", map[string]any{"character": "Kael", "hp": "roll"})
+	if r.Error != "" || pc.Level != 3 || !strings.Contains(r.Content, "reached level 3") {
+		t.Fatalf("level_up = %+v (level %d)", r, pc.Level)
+	}
+	if pc.MaxHP <= maxHP {
+		t.Error("levelling up should raise max HP")
+	}
+	if e := session.State.RecentLog(1)[0]; e.Type != domain.LogParty {
+		t.Errorf("level-up should be logged as a party update: %+v", e)
+	}
+
+	r = combatCall(tr, "level_up", map[string]any{"character": "Kael", "milestone": true})
+	if r.Error != "" || pc.Level != 4 || !strings.Contains(r.Content, "Ability Score Improvement") {
+		t.Errorf("milestone level_up = %+v (level %d)", r, pc.Level)
+	}
+}
+
+func TestLevelUpCommand(t *testing.T) {
+	session := createTestSession()
+	pc := soloParty(session, domain.GenerateCharacter("Kael", "Elf", "Wizard", 1))
+	pc.XP = 300
+	h := NewCommandHandler(session)
+	if res := h.Execute(ParseCommand("/levelup")); !res.Success || pc.Level != 2 {
+		t.Fatalf("/levelup = %+v (level %d)", res, pc.Level)
+	}
+	if res := h.Execute(ParseCommand("/levelup Kael")); res.Success {
+		t.Error("a second /levelup without XP should fail")
+	}
+	if res := h.Execute(ParseCommand("/levelup Kael milestone")); !res.Success || pc.Level != 3 {
+		t.Errorf("/levelup milestone = %+v", res)
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
+	"slices"
 	"strings"
 	"time"
 
@@ -41,21 +42,53 @@ type Response struct {
 	TokensUsed int
 	LatencyMs  int64
 	Error      error
+	// Warning, when set, says the turn carried the session past its soft token
+	// budget.
+	Warning string
+	// Provider and Model name the backend that answered the turn; Fallback is
+	// set when a fallback answered any of its calls instead of the configured
+	// provider.
+	Provider string
+	Model    string
+	Fallback bool
 }
 
 // Ask sends a DM query to the oracle and runs the tool-calling loop.
 func (o *Oracle) Ask(ctx context.Context, input string) *Response {
+	return o.AskStream(ctx, input, nil)
+}
+
+// AskStream is Ask reporting the turn as it happens: the reply's text as the
+// provider streams it and each tool the oracle runs. The returned Response is
+// the same Ask would return. A nil fn is plain Ask.
+func (o *Oracle) AskStream(ctx context.Context, input string, fn EventFunc) *Response {
+	// Everything the turn changes is one undoable step (see domain.ChangeSet).
+	o.session.State.BeginChange(turnLabel(input))
+	defer o.session.State.CommitChange()
+	return o.askStream(ctx, input, fn)
+}
+
+// askStream is AskStream inside a change set the caller has begun.
+func (o *Oracle) askStream(ctx context.Context, input string, fn EventFunc) *Response {
 	resp := &Response{}
 	if o.provider == nil {
 		resp.Error = fmt.Errorf("no AI provider configured")
 		return resp
 	}
+	if err := o.checkBudget(); err != nil {
+		resp.Error = err
+		return resp
+	}
 
 	// The Claude CLI backend can't drive our tool-calling loop through Chat (it's
 	// text-only); instead we let Claude Code run the loop, calling our tools via an
 	// MCP server. Everything else uses the direct API tool loop below.
 	if cli, ok := o.provider.(*providers.ClaudeCLIProvider); ok {
-		return o.askViaCLI(ctx, cli, input)
+		resp := o.askViaCLI(ctx, cli, input)
+		if fn != nil && resp.Error == nil && resp.Answer != "" {
+			fn(Event{Kind: EventText, Text: resp.Answer})
+		}
+		return resp
 	}
 
 	o.session.State.AddUserMessage(input)
@@ -75,14 +108,28 @@ func (o *Oracle) Ask(ctx context.Context, input string) *Response {
 	if maxIter <= 0 {
 		maxIter = defaultMaxToolIterations
 	}
+	var onDelta providers.StreamFunc
+	if fn != nil && o.streamsText() {
+		onDelta = func(d string) { fn(Event{Kind: EventText, Text: d}) }
+	}
 	for iteration := 0; iteration < maxIter; iteration++ {
-		chat, err := o.provider.Chat(ctx, req)
+		var chat *providers.ChatResponse
+		var err error
+		if fn != nil {
+			chat, err = o.provider.ChatStream(ctx, req, onDelta)
+		} else {
+			chat, err = o.provider.Chat(ctx, req)
+		}
 		if err != nil {
 			resp.Error = fmt.Errorf("AI request failed: %w", err)
 			return resp
 		}
 		totalLatency += chat.Latency
 		totalTokens += chat.Usage.TotalTokens
+		resp.noteBackend(o.provider, req.Model, chat)
+		if w := o.recordUsage(o.provider, req.Model, domain.PurposeOracle, chat); w != "" {
+			resp.Warning = w
+		}
 
 		if len(chat.ToolCalls) == 0 {
 			answer := o.reviewSpoilers(ctx, chat.Content)
@@ -91,8 +138,14 @@ func (o *Oracle) Ask(ctx context.Context, input string) *Response {
 			resp.TokensUsed = totalTokens
 			o.session.State.AddAssistantMessage(answer)
 			o.session.MarkModified()
+			if fn != nil && onDelta == nil && answer != "" {
+				fn(Event{Kind: EventText, Text: answer})
+			}
 			return resp
 		}
+		if onDelta != nil && chat.Content != "" {
+			fn(Event{Kind: EventReset})
+		}
 
 		req.Messages = append(req.Messages, providers.Message{
 			Role:      providers.RoleAssistant,
@@ -106,6 +159,9 @@ func (o *Oracle) Ask(ctx context.Context, input string) *Response {
 			if result.Error != "" {
 				content = "Error: " + result.Error
 			}
+			if fn != nil {
+				fn(Event{Kind: EventTool, Tool: tc.Function.Name, Text: result.Error})
+			}
 			req.Messages = append(req.Messages, providers.Message{
 				Role:       providers.RoleTool,
 				Content:    content,
@@ -121,15 +177,36 @@ func (o *Oracle) Ask(ctx context.Context, input string) *Response {
 	return resp
 }
 
+// noteBackend records which backend answered a call of the turn.
+func (r *Response) noteBackend(prov providers.Provider, model string, chat *providers.ChatResponse) {
+	if chat.Provider != "" {
+		r.Provider, r.Model = chat.Provider, chat.Model
+	} else {
+		r.Provider, r.Model = prov.Name(), model
+	}
+	if r.Model == "" {
+		r.Model = chat.Model
+	}
+	r.Fallback = r.Fallback || chat.Fallback
+}
+
 // RunGroupTurn resolves a multiplayer round: it aggregates the players' declared
 // actions from the round buffer into a single DM prompt, runs the normal GM turn,
 // and clears the buffer on success. Returns an error if no actions were declared.
 func (o *Oracle) RunGroupTurn(ctx context.Context) *Response {
+	return o.RunGroupTurnStream(ctx, nil)
+}
+
+// RunGroupTurnStream is RunGroupTurn reporting the turn as AskStream does.
+func (o *Oracle) RunGroupTurnStream(ctx context.Context, fn EventFunc) *Response {
 	actions := o.session.State.RoundActions()
 	if len(actions) == 0 {
 		return &Response{Error: fmt.Errorf("no actions have been declared this round")}
 	}
-	resp := o.Ask(ctx, composeRoundInput(actions, o.session.Config.Language))
+	// The round's resolution, actions cleared included, is undone as one step.
+	o.session.State.BeginChange("Round: " + roundLabel(actions))
+	defer o.session.State.CommitChange()
+	resp := o.askStream(ctx, composeRoundInput(actions, o.session.Config.Language), fn)
 	if resp.Error == nil {
 		// Drop only the actions we actually resolved, so anything submitted while
 		// the DM was thinking survives into the next round.
@@ -148,6 +225,31 @@ func MetaInput(text string, lang domain.Language) string {
 	return "[META / OUT-OF-CHARACTER — this is the player talking to you (the DM), not an in-fiction action. Answer their question, clarify the rule, or apply their correction directly and briefly, without narrating a scene]: " + text
 }
 
+// turnLabel names an oracle turn for the undo history: its input, cut short.
+func turnLabel(input string) string {
+	return "Turn: " + truncate(input, 60)
+}
+
+// truncate collapses s's whitespace and cuts it to n runes.
+func truncate(s string, n int) string {
+	r := []rune(strings.Join(strings.Fields(s), " "))
+	if len(r) <= n {
+		return string(r)
+	}
+	return string(r[:n]) + "…"
+}
+
+// roundLabel names a round for the undo history by who acted in it.
+func roundLabel(actions []domain.RoundAction) string {
+	names := make([]string, 0, len(actions))
+	for _, a := range actions {
+		if !slices.Contains(names, a.CharacterName) {
+			names = append(names, a.CharacterName)
+		}
+	}
+	return strings.Join(names, ", ")
+}
+
 // composeRoundInput renders the round's declared actions into the DM prompt.
 func composeRoundInput(actions []domain.RoundAction, lang domain.Language) string {
 	var sb strings.Builder
@@ -250,6 +352,9 @@ func (o *Oracle) askViaCLI(ctx context.Context, cli *providers.ClaudeCLIProvider
 		return resp
 	}
 	resp.LatencyMs = time.Since(start).Milliseconds()
+	resp.Provider, resp.Model = cli.Name(), o.session.Config.Model
+	// The CLI reports no token counts; the call is still counted.
+	resp.Warning = o.recordUsage(cli, o.session.Config.Model, domain.PurposeOracle, &providers.ChatResponse{})
 
 	// Merge tool mutations back into the live state (in place) and record the reply.
 	if merged, e := readSessionFile(sessPath); e == nil {
@@ -377,10 +482,14 @@ func (o *Oracle) worldStateContext() string {
 }
 
 func (o *Oracle) buildMessages() []providers.Message {
-	msgs := []providers.Message{{
-		Role:    providers.RoleSystem,
-		Content: o.buildSystemPrompt(),
-	}}
+	// The system prompt goes in two parts: the stable prefix (instructions and
+	// adventure overview), marked so providers with prompt caching can cache it
+	// together with the tool definitions, then the situation, which changes
+	// every turn.
+	msgs := []providers.Message{
+		{Role: providers.RoleSystem, Content: o.stablePrompt(), Cache: true},
+		{Role: providers.RoleSystem, Content: strings.TrimLeft(o.situationPrompt(), "\n")},
+	}
 	// Deliver untrusted, player-influenced world state as a lower-priority data
 	// message (user role), not in the system prompt (see worldStateContext). It is
 	// ephemeral — recomputed each turn from current state, never persisted into the
@@ -416,9 +525,15 @@ func (o *Oracle) buildMessages() []providers.Message {
 // state, and the recent timeline. Everything else is fetched on demand via the
 // retrieval tools.
 func (o *Oracle) buildSystemPrompt() string {
+	return o.stablePrompt() + o.situationPrompt()
+}
+
+// stablePrompt is the part of the system prompt that stays the same from turn
+// to turn — the instructions and the adventure overview — so it can be cached.
+// Nothing that changes during play belongs here.
+func (o *Oracle) stablePrompt() string {
 	var sb strings.Builder
 	adv := o.session.Adventure
-	st := o.session.State
 
 	sb.WriteString(o.systemPromptBase())
 	sb.WriteString("\n\n=== ADVENTURE ===\n")
@@ -451,6 +566,16 @@ func (o *Oracle) buildSystemPrompt() string {
 		}
 		fmt.Fprintf(&sb, "Tables (use get_table / roll_table): %s\n", strings.Join(names, ", "))
 	}
+	return sb.String()
+}
+
+// situationPrompt is the volatile part of the system prompt: the current scene
+// and room, session state, party, combat, the story so far and the recent
+// timeline.
+func (o *Oracle) situationPrompt() string {
+	var sb strings.Builder
+	adv := o.session.Adventure
+	st := o.session.State
 
 	sb.WriteString("\n=== CURRENT SITUATION ===\n")
 	// Resolve the active scene (if any). Scenes let the same location read
@@ -500,7 +625,7 @@ func (o *Oracle) buildSystemPrompt() string {
 		if present != "" {
 			fmt.Fprintf(&sb, "In this scene, notably: %s\n", present)
 		}
-		sb.WriteString(FormatRoom(adv, effRoom))
+		sb.WriteString(FormatRoom(adv, effRoom, st))
 		sb.WriteString("\n")
 		// NB: DM-recorded world changes (issue #21) are NOT injected here. They are
 		// model-generated in response to player actions, so they are untrusted and
@@ -510,6 +635,9 @@ func (o *Oracle) buildSystemPrompt() string {
 			sb.WriteString("\n--- Present NPCs ---\n")
 			for _, nid := range effRoom.NPCIDs {
 				if n := adv.NPC(nid); n != nil {
+					if n.Secret && gated(st) && !st.NPCRevealed(n) {
+						continue // withheld until revealed (FormatRoom notes it)
+					}
 					// Same v2 suppression for an NPC whose current appearance was
 					// overridden (copy so the authored NPC isn't mutated).
 					if st.WorldDescription(worldTarget("npc", nid)) != "" {
@@ -517,7 +645,7 @@ func (o *Oracle) buildSystemPrompt() string {
 						cp.Appearance = ""
 						n = &cp
 					}
-					sb.WriteString(FormatNPC(adv, n))
+					sb.WriteString(FormatNPC(adv, n, st))
 					sb.WriteString("\n\n")
 				}
 			}
@@ -583,6 +711,26 @@ func (o *Oracle) buildSystemPrompt() string {
 		sb.WriteString("\n")
 	}
 
+	// While a fight is tracked, the turn order is ground truth: the DM resolves
+	// strictly in this order and calls end_turn, instead of improvising who acts
+	// next (issue #22).
+	if combat := st.CombatSnapshot(); combat != nil {
+		sb.WriteString("\n=== COMBAT (tracked — resolve strictly in initiative order with attack_roll / saving_throw / apply_damage; call end_turn when the current combatant is done, end_combat when the fight is over) ===\n")
+		sb.WriteString(FormatCombat(combat, st.PartySnapshot(), false))
+		sb.WriteString("\n")
+		if cur := combat.Current(); cur != nil {
+			fmt.Fprintf(&sb, "Current turn: %s (%s)\n", cur.Name, cur.Side)
+		}
+	}
+
+	// Spawned creature instances carry their own HP; while any is standing the DM
+	// must track each one rather than a single shared stat block.
+	if list := FormatCreatures(st.CreaturesSnapshot()); list != "" {
+		sb.WriteString("\n=== SPAWNED CREATURES (tracked per instance — target each by name; damage_creature / heal_creature / remove_creature) ===\n")
+		sb.WriteString(list)
+		sb.WriteString("\n")
+	}
+
 	if st.Summary != "" {
 		sb.WriteString("\n=== STORY SO FAR ===\n")
 		sb.WriteString(st.Summary)
@@ -646,6 +794,9 @@ func (o *Oracle) UpdateSummary(ctx context.Context) error {
 	if o.session.State.LogLen() < threshold {
 		return nil
 	}
+	if err := o.checkBudget(); err != nil {
+		return err
+	}
 
 	var sb strings.Builder
 	sb.WriteString("Summarize the following D&D session timeline into a concise recap (<300 words) of what the party has done, key decisions, and open threads:\n\n")
@@ -668,6 +819,7 @@ func (o *Oracle) UpdateSummary(ctx context.Context) error {
 	if err != nil {
 		return err
 	}
+	o.recordUsage(o.provider, o.session.Config.Model, domain.PurposeSummary, chat)
 	o.session.State.Summary = chat.Content
 	o.session.MarkModified()
 	return nil
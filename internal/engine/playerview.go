@@ -0,0 +1,88 @@
+package engine
+
+import (
+	"encoding/json"
+	"sort"
+	"strings"
+
+	"github.com/theburrowhub/thaimaturgy/internal/domain"
+)
+
+// PlayerView is the players' side of a session, for the web player portal: what
+// the table has been shown, and nothing the DM keeps back. Like /recap and
+// /glosario it draws on session state and the module's player-facing text only —
+// never DM notes, NPC secrets or motivations, stat blocks, or people and places
+// the party hasn't come across.
+type PlayerView struct {
+	Adventure string `json:"adventure"`
+	Zone      string `json:"zone,omitempty"`
+	Room      string `json:"room,omitempty"`
+	// ReadAloud is the current room's boxed text as the active scene presents
+	// it, or the DM's current description of the room when one is set.
+	ReadAloud string      `json:"read_aloud,omitempty"`
+	HasMap    bool        `json:"has_map"` // the current zone has a map (see PlayerMap)
+	NPCs      []PlayerNPC `json:"npcs"`    // the NPCs the party has met, by name
+	Recap     string      `json:"recap"`
+}
+
+// PlayerNPC is an NPC the party has met, as the players know them.
+type PlayerNPC struct {
+	ID          string `json:"id"`
+	Name        string `json:"name"`
+	Role        string `json:"role,omitempty"`
+	Appearance  string `json:"appearance,omitempty"` // the DM's current description, if set
+	Disposition string `json:"disposition,omitempty"`
+	Alive       bool   `json:"alive"`
+	HasPortrait bool   `json:"has_portrait"`
+}
+
+// NewPlayerView builds the players' view of a session. It reads a copy of the
+// state, so it's safe while a turn is running.
+func NewPlayerView(session *domain.Session) (*PlayerView, error) {
+	b, err := json.Marshal(session.State)
+	if err != nil {
+		return nil, err
+	}
+	var st domain.SessionState
+	if err := json.Unmarshal(b, &st); err != nil {
+		return nil, err
+	}
+	adv := session.Adventure
+	v := &PlayerView{Adventure: adv.Title, NPCs: []PlayerNPC{}}
+
+	if room, zone := adv.Room(st.CurrentRoom); room != nil {
+		v.Room = room.Name
+		if zone != nil {
+			v.Zone = zone.Name
+			v.HasMap = adv.ZoneMap(zone) != ""
+		}
+		eff, _ := effectiveRoom(adv.Scene(st.CurrentScene), room)
+		v.ReadAloud = eff.ReadAloud
+		if desc := st.WorldDescriptions[worldTarget("room", room.ID)]; desc != "" {
+			v.ReadAloud = desc
+		}
+	}
+
+	for id, ns := range st.KnownNPCs {
+		if ns == nil || !ns.Met {
+			continue
+		}
+		n := PlayerNPC{ID: id, Name: id, Disposition: ns.Disposition, Alive: ns.Alive}
+		if npc := adv.NPC(id); npc != nil {
+			if npc.Name != "" {
+				n.Name = npc.Name
+			}
+			n.Role = npc.Role
+			n.Appearance = npc.Appearance
+			n.HasPortrait = len(adv.NPCImages(npc)) > 0
+		}
+		if desc := st.WorldDescriptions[worldTarget("npc", id)]; desc != "" {
+			n.Appearance = desc
+		}
+		v.NPCs = append(v.NPCs, n)
+	}
+	sort.Slice(v.NPCs, func(i, j int) bool { return strings.ToLower(v.NPCs[i].Name) < strings.ToLower(v.NPCs[j].Name) })
+
+	v.Recap = NewCommandHandler(domain.NewSession(&st, adv, session.Config)).recapText()
+	return v, nil
+}
@@ -0,0 +1,36 @@
+package engine
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestPlayerViewShowsOnlyWhatThePartyKnows(t *testing.T) {
+	s := secretSession()
+	s.State.MeetNPC("butler", "Jeeves")
+
+	v, err := NewPlayerView(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v.Room != "Study" || v.ReadAloud != "Dusty shelves line the walls." {
+		t.Errorf("location = %q: %q", v.Room, v.ReadAloud)
+	}
+	if len(v.NPCs) != 1 || v.NPCs[0].Name != "Jeeves" || v.NPCs[0].Appearance != "Stiff and grey." {
+		t.Errorf("npcs = %+v", v.NPCs)
+	}
+	b, _ := json.Marshal(v)
+	for _, secret := range []string{"ledger", "poisoned", "Ghost", "vault key", "Vault"} {
+		if strings.Contains(string(b), secret) {
+			t.Errorf("the player view leaks %q:\n%s", secret, b)
+		}
+	}
+
+	// The DM's current descriptions replace the authored ones.
+	s.State.SetWorldDescription(worldTarget("room", "study"), "The shelves lie toppled.")
+	s.State.SetWorldDescription(worldTarget("npc", "butler"), "Pale and shaking.")
+	if v, _ = NewPlayerView(s); v.ReadAloud != "The shelves lie toppled." || v.NPCs[0].Appearance != "Pale and shaking." {
+		t.Errorf("overrides = %q / %q", v.ReadAloud, v.NPCs[0].Appearance)
+	}
+}
@@ -0,0 +1,91 @@
+package engine
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/theburrowhub/thaimaturgy/internal/domain"
+	"github.com/theburrowhub/thaimaturgy/internal/types"
+)
+
+// This file applies the secret/reveal_when markers (docs/dm-information-discipline.md)
+// to the grounding. In virtual-DM mode the model is the players' only window on
+// the module, so content still secret is left out of what it is given; in
+// assistant mode the human DM sees everything, tagged with its reveal condition.
+
+// DM-only fences: the boundary between what may be narrated and what only
+// adjudicates, made explicit to the model rather than inferred from field names.
+const (
+	dmOnlyOpen  = "=== DM-ONLY (never reveal verbatim) ==="
+	dmOnlyClose = "=== END DM-ONLY ==="
+)
+
+// gated reports whether secrets must be withheld from the grounding.
+func gated(st *domain.SessionState) bool {
+	return st != nil && st.EffectiveMode() == domain.ModeVirtualDM
+}
+
+// exitShown reports whether a room's exit may reach the players' side, with
+// the marker that decides it: the exit's own, or, once that holds, its target
+// room's, since an exit leads nowhere the players know of until its room is
+// revealed. st may be nil (nothing secret is revealed).
+func exitShown(adv *domain.Adventure, st *domain.SessionState, roomID string, ex domain.Exit) (bool, domain.Visibility) {
+	shown := !ex.Secret || st != nil && st.IsRevealed(ex.Visibility, roomID)
+	if to, _ := adv.Room(ex.To); shown && to != nil && to.Secret {
+		return st != nil && st.RoomRevealed(to), to.Visibility
+	}
+	return shown, ex.Visibility
+}
+
+// secretTag labels marked content for a view that shows it: " (secret until
+// check:Investigation>=15)", " (secret, revealed)", " (DM-only secret)"; "" when
+// unmarked. revealed is whether its condition holds.
+func secretTag(v domain.Visibility, revealed bool) string {
+	switch {
+	case !v.Secret:
+		return ""
+	case revealed:
+		return " (secret, revealed)"
+	case strings.TrimSpace(v.RevealWhen) == "":
+		return " (DM-only secret)"
+	}
+	return " (secret until " + v.RevealWhen + ")"
+}
+
+// withheldLine renders what the grounding left out, by kind and condition, so
+// the DM knows something can still be found without learning what it is.
+func withheldLine(hidden []string) string {
+	if len(hidden) == 0 {
+		return ""
+	}
+	return "Withheld until revealed: " + strings.Join(hidden, "; ") +
+		". When the players act to meet a condition (e.g. search, for a check), call for it; never hint at what it hides.\n"
+}
+
+// withheldEntry describes one withheld piece of content: "a feature
+// (check:Investigation>=15)", or just "a feature" for a DM-only secret.
+func withheldEntry(kind string, v domain.Visibility) string {
+	if strings.TrimSpace(v.RevealWhen) == "" {
+		return kind
+	}
+	return fmt.Sprintf("%s (%s)", kind, v.RevealWhen)
+}
+
+// writeDMOnly appends body inside the DM-ONLY fence; nothing when body is empty.
+func writeDMOnly(sb *strings.Builder, body string) {
+	if strings.TrimSpace(body) == "" {
+		return
+	}
+	sb.WriteString("\n" + dmOnlyOpen + "\n")
+	sb.WriteString(strings.TrimRight(body, "\n") + "\n")
+	sb.WriteString(dmOnlyClose + "\n")
+}
+
+// withheldResult is a retrieval tool's answer for content still secret in
+// virtual-DM mode: that it exists and what reveals it, nothing more.
+func withheldResult(id, what string, v domain.Visibility) types.ToolResult {
+	if strings.TrimSpace(v.RevealWhen) == "" {
+		return errResult(id, what+" is a DM-only secret and is not part of play")
+	}
+	return errResult(id, what+" is secret until "+v.RevealWhen+"; it is not part of play yet")
+}
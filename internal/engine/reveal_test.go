@@ -0,0 +1,193 @@
+package engine
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/theburrowhub/thaimaturgy/internal/domain"
+)
+
+// secretSession is a two-room module with a secret feature, a secret exit to a
+// secret room, and a secret NPC, the party standing in the first room.
+func secretSession() *domain.Session {
+	adv := &domain.Adventure{
+		SchemaVersion: domain.SchemaVersion, ID: "secrets", Title: "Secrets",
+		Zones: []domain.Zone{{ID: "z", Name: "Z", Rooms: []domain.Room{{
+			ID: "study", Name: "Study", ReadAloud: "Dusty shelves line the walls.",
+			DMNotes: "The baron hid his ledger here.",
+			NPCIDs:  []string{"butler", "ghost"},
+			Features: []domain.Feature{
+				{Name: "Desk", Description: "Scattered letters."},
+				{Name: "Hollow book", Description: "Holds the vault key.",
+					Visibility: domain.Visibility{Secret: true, RevealWhen: "check:Investigation>=15"}},
+			},
+			Exits: []domain.Exit{
+				{To: "hall", Direction: "south"},
+				{To: "vault", Direction: "down", Visibility: domain.Visibility{Secret: true, RevealWhen: "flag:trapdoor"}},
+			},
+		}, {ID: "hall", Name: "Hall"}, {
+			ID: "vault", Name: "Vault", Visibility: domain.Visibility{Secret: true, RevealWhen: "flag:trapdoor"},
+		}}}},
+		NPCs: []domain.NPC{
+			{ID: "butler", Name: "Jeeves", Appearance: "Stiff and grey.", Secrets: "He poisoned the baron."},
+			{ID: "ghost", Name: "The Baron's Ghost", Visibility: domain.Visibility{Secret: true, RevealWhen: "event:midnight"}},
+		},
+		Events: []domain.Event{{ID: "midnight", Name: "Midnight"}},
+	}
+	st := domain.NewSessionState("s", adv)
+	st.SetLocation("z", "study", "Study")
+	st.SetMode(domain.ModeVirtualDM)
+	s := domain.NewSession(st, adv, domain.DefaultConfig())
+	soloParty(s, domain.NewCharacter("Kael", "Elf", "Wizard"))
+	return s
+}
+
+func TestFormatRoomFencesAndWithholdsSecrets(t *testing.T) {
+	s := secretSession()
+	room, _ := s.Adventure.Room("study")
+	out := FormatRoom(s.Adventure, room, s.State)
+
+	open, end := strings.Index(out, dmOnlyOpen), strings.Index(out, dmOnlyClose)
+	if open < 0 || end < open {
+		t.Fatalf("no DM-ONLY fence:\n%s", out)
+	}
+	if notes := strings.Index(out, "The baron hid his ledger"); notes < open || notes > end {
+		t.Errorf("DM notes must sit inside the fence:\n%s", out)
+	}
+	if ra := strings.Index(out, "Dusty shelves"); ra < 0 || ra > open {
+		t.Errorf("read-aloud must stay outside the fence:\n%s", out)
+	}
+	for _, hidden := range []string{"Hollow book", "vault key", "Vault", "Ghost"} {
+		if strings.Contains(out, hidden) {
+			t.Errorf("virtual-DM grounding leaks %q:\n%s", hidden, out)
+		}
+	}
+	for _, want := range []string{"Desk", "Jeeves", "south to Hall",
+		"Withheld until revealed: an NPC (event:midnight); an exit (flag:trapdoor); a feature (check:Investigation>=15)"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("missing %q:\n%s", want, out)
+		}
+	}
+
+	// Meeting the conditions brings the content back.
+	s.State.SetFlag("trapdoor", true)
+	s.State.TriggerEvent("midnight", "Midnight")
+	s.State.RecordCheck("Investigation", "study", 16)
+	out = FormatRoom(s.Adventure, room, s.State)
+	for _, want := range []string{"Hollow book (secret, revealed)", "down to Vault", "The Baron's Ghost [ghost] (secret, revealed)"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("missing revealed %q:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "Withheld") {
+		t.Errorf("nothing is withheld any more:\n%s", out)
+	}
+}
+
+func TestAssistantModeShowsSecretsTagged(t *testing.T) {
+	s := secretSession()
+	s.State.SetMode(domain.ModeAssistant)
+	room, _ := s.Adventure.Room("study")
+	out := FormatRoom(s.Adventure, room, s.State)
+	for _, want := range []string{"Hollow book (secret until check:Investigation>=15)", "down to Vault [vault] (secret until flag:trapdoor)", "The Baron's Ghost"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("the human DM should see %q:\n%s", want, out)
+		}
+	}
+}
+
+func TestPartyCheckRevealsRoomSecret(t *testing.T) {
+	s := secretSession()
+	s.Adventure.Zones[0].Rooms[0].Features[1].RevealWhen = "check:Investigation>=1"
+	tr := NewToolRouter(s)
+	if res := combatCall(tr, "ability_check", map[string]any{"character": "Kael", "skill": "investigation", "dc": 1}); res.Error != "" {
+		t.Fatalf("ability_check: %s", res.Error)
+	}
+	if res := combatCall(tr, "get_room", map[string]any{"room_id": "study"}); !strings.Contains(res.Content, "Hollow book") {
+		t.Errorf("the party's check should reveal the feature:\n%s", res.Content)
+	}
+}
+
+func TestRetrievalToolsWithholdSecrets(t *testing.T) {
+	s := secretSession()
+	tr := NewToolRouter(s)
+	res := combatCall(tr, "get_room", map[string]any{"room_id": "vault"})
+	if res.Error == "" || !strings.Contains(res.Error, "secret until flag:trapdoor") {
+		t.Errorf("get_room on a hidden room: %+v", res)
+	}
+	if res := combatCall(tr, "get_npc", map[string]any{"npc_id": "ghost"}); res.Error == "" {
+		t.Errorf("get_npc on a hidden NPC returned:\n%s", res.Content)
+	}
+	res = combatCall(tr, "get_npc", map[string]any{"npc_id": "butler"})
+	if i := strings.Index(res.Content, "He poisoned"); i < 0 || i < strings.Index(res.Content, dmOnlyOpen) {
+		t.Errorf("NPC secrets must be fenced:\n%s", res.Content)
+	}
+
+	prompt := NewOracle(s, nil).buildSystemPrompt()
+	if strings.Contains(prompt, "Baron's Ghost") || strings.Contains(prompt, "Hollow book") {
+		t.Errorf("system prompt leaks a withheld secret")
+	}
+	if !strings.Contains(prompt, dmOnlyOpen) {
+		t.Errorf("system prompt has no DM-ONLY fence")
+	}
+}
+
+func TestSearchModuleSkipsSecrets(t *testing.T) {
+	s := secretSession()
+	s.Adventure.Zones[0].Rooms[2].DMNotes = "A chest of baron's gold."
+	s.Adventure.NPCs[1].Secrets = "The baron's gold is cursed."
+	tr := NewToolRouter(s)
+	res := combatCall(tr, "search_module", map[string]any{"query": "baron"})
+	if strings.Contains(res.Content, "vault") || strings.Contains(res.Content, "ghost") {
+		t.Errorf("search matched withheld content:\n%s", res.Content)
+	}
+	if !strings.Contains(res.Content, "room [study]") {
+		t.Errorf("search should still find the study:\n%s", res.Content)
+	}
+
+	s.State.SetFlag("trapdoor", true)
+	s.State.TriggerEvent("midnight", "Midnight")
+	res = combatCall(tr, "search_module", map[string]any{"query": "gold"})
+	if !strings.Contains(res.Content, "room [vault]") || !strings.Contains(res.Content, "npc [ghost]") {
+		t.Errorf("revealed content should be searchable:\n%s", res.Content)
+	}
+}
+
+func TestListPresentNPCsWithholdsSecrets(t *testing.T) {
+	s := secretSession()
+	tr := NewToolRouter(s)
+	res := combatCall(tr, "list_present_npcs", map[string]any{})
+	if strings.Contains(res.Content, "Ghost") || !strings.Contains(res.Content, "Jeeves") {
+		t.Errorf("list_present_npcs:\n%s", res.Content)
+	}
+	s.State.TriggerEvent("midnight", "Midnight")
+	if res := combatCall(tr, "list_present_npcs", map[string]any{}); !strings.Contains(res.Content, "Ghost") {
+		t.Errorf("a revealed NPC should be listed:\n%s", res.Content)
+	}
+}
+
+func TestNavigationToolsWithholdSecretExits(t *testing.T) {
+	s := secretSession()
+	tr := NewToolRouter(s)
+	res := combatCall(tr, "list_exits", map[string]any{})
+	if strings.Contains(res.Content, "Vault") || !strings.Contains(res.Content, "south to Hall") {
+		t.Errorf("list_exits:\n%s", res.Content)
+	}
+	if res := combatCall(tr, "find_path", map[string]any{"to_zone": "vault"}); res.Error == "" || strings.Contains(res.Error, "Vault") {
+		t.Errorf("find_path to a hidden room: %+v", res)
+	}
+	if res := combatCall(tr, "go_direction", map[string]any{"direction": "down"}); res.Error == "" {
+		t.Errorf("go_direction took a hidden exit: %s", res.Content)
+	}
+	if s.State.CurrentRoom != "study" {
+		t.Fatalf("party moved to %s", s.State.CurrentRoom)
+	}
+
+	s.State.SetFlag("trapdoor", true)
+	if res := combatCall(tr, "list_exits", map[string]any{}); !strings.Contains(res.Content, "down to Vault") {
+		t.Errorf("a revealed exit should be listed:\n%s", res.Content)
+	}
+	if res := combatCall(tr, "go_direction", map[string]any{"direction": "down"}); res.Error != "" || s.State.CurrentRoom != "vault" {
+		t.Errorf("go_direction down once revealed: %+v", res)
+	}
+}
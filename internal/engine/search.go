@@ -0,0 +1,155 @@
+package engine
+
+import (
+	"runtime"
+	"strings"
+	"sync"
+	"weak"
+
+	"github.com/theburrowhub/thaimaturgy/internal/domain"
+	"github.com/theburrowhub/thaimaturgy/internal/search"
+)
+
+// Field boosts: a match in a name outweighs one in a role or trigger, which
+// outweighs one in running prose.
+const (
+	boostName  = 3
+	boostShort = 2
+)
+
+// searchLimit caps search_module and /search results.
+const searchLimit = 20
+
+var (
+	indexMu sync.Mutex
+	indexes = map[weak.Pointer[domain.Adventure]]*search.Index{}
+)
+
+// AdventureIndex returns the full-text index of a loaded adventure, building it
+// the first time it is asked for. The index is kept while the adventure is in
+// memory, so it must not be edited afterwards; an editor builds its own with
+// NewAdventureIndex.
+func AdventureIndex(adv *domain.Adventure) *search.Index {
+	key := weak.Make(adv)
+	indexMu.Lock()
+	ix, ok := indexes[key]
+	indexMu.Unlock()
+	if ok {
+		return ix
+	}
+	ix = NewAdventureIndex(adv)
+	indexMu.Lock()
+	defer indexMu.Unlock()
+	if cur, ok := indexes[key]; ok {
+		return cur
+	}
+	indexes[key] = ix
+	runtime.AddCleanup(adv, func(k weak.Pointer[domain.Adventure]) {
+		indexMu.Lock()
+		delete(indexes, k)
+		indexMu.Unlock()
+	}, key)
+	return ix
+}
+
+// NewAdventureIndex indexes every text of an adventure: zones, rooms, NPCs,
+// events, items, tables, scenes, factions and lore.
+func NewAdventureIndex(adv *domain.Adventure) *search.Index {
+	return search.New(adventureDocs(adv), searchLang(adv.Language))
+}
+
+// searchLang maps an adventure's language to the stemmer to use.
+func searchLang(lang string) string {
+	if l := strings.ToLower(lang); l == "es" || strings.HasPrefix(l, "es-") || strings.HasPrefix(l, "span") || strings.HasPrefix(l, "espa") {
+		return "es"
+	}
+	return "en"
+}
+
+func adventureDocs(adv *domain.Adventure) []search.Doc {
+	var docs []search.Doc
+	add := func(kind, id, name string, fields ...search.Field) {
+		docs = append(docs, search.Doc{Kind: kind, ID: id, Name: name,
+			Fields: append([]search.Field{{Name: "name", Text: name, Boost: boostName}}, fields...)})
+	}
+	text := func(name, text string) search.Field { return search.Field{Name: name, Text: text} }
+	short := func(name, text string) search.Field { return search.Field{Name: name, Text: text, Boost: boostShort} }
+
+	for i := range adv.Zones {
+		z := &adv.Zones[i]
+		add("zone", z.ID, z.Name, text("overview", z.Overview), text("description", z.Description))
+		for j := range z.Rooms {
+			r := &z.Rooms[j]
+			fields := []search.Field{text("read_aloud", r.ReadAloud), text("dm_notes", r.DMNotes)}
+			for _, f := range r.Features {
+				fields = append(fields, short("feature", f.Name), text("feature", f.Description))
+			}
+			for _, e := range r.Encounters {
+				fields = append(fields, short("encounter", e.Name+" "+strings.Join(e.Creatures, ", ")), text("encounter", e.Description))
+			}
+			fields = append(fields, text("treasure", strings.Join(r.Treasure, "; ")))
+			add("room", r.ID, r.Name, fields...)
+		}
+	}
+	for i := range adv.NPCs {
+		n := &adv.NPCs[i]
+		add("npc", n.ID, n.Name, short("role", n.Role), text("appearance", n.Appearance),
+			text("personality", n.Personality), text("motivations", n.Motivations), text("secrets", n.Secrets),
+			text("knowledge", strings.Join(n.Knowledge, "\n")))
+	}
+	for i := range adv.Events {
+		e := &adv.Events[i]
+		add("event", e.ID, e.Name, short("trigger", e.Trigger), text("description", e.Description),
+			text("read_aloud", e.ReadAloud), text("dm_notes", e.DMNotes), text("consequences", e.Consequences))
+	}
+	for i := range adv.Items {
+		it := &adv.Items[i]
+		add("item", it.ID, it.Name, text("description", it.Description), text("mechanics", it.Mechanics))
+	}
+	for i := range adv.Tables {
+		t := &adv.Tables[i]
+		rows := make([]string, 0, len(t.Rows))
+		for _, r := range t.Rows {
+			rows = append(rows, strings.Join(r.Cells, " · "))
+		}
+		add("table", t.ID, t.Name, text("description", t.Description), text("rows", strings.Join(rows, "\n")))
+	}
+	for i := range adv.Scenes {
+		sc := &adv.Scenes[i]
+		add("scene", sc.ID, nameOrID(sc.Name, sc.ID), text("read_aloud", sc.ReadAloud), text("description", sc.Description))
+	}
+	for i := range adv.Factions {
+		f := &adv.Factions[i]
+		add("faction", f.ID, f.Name, text("description", f.Description), text("goals", f.Goals))
+	}
+	for i := range adv.Lore {
+		l := &adv.Lore[i]
+		add("lore", l.Title, l.Title, text("content", l.Content))
+	}
+	return docs
+}
+
+// searchVisible reports whether a search hit may be shown in a gated session:
+// secret rooms, NPCs, events and items stay out of results until revealed, as
+// a hit — even on a name alone — would tell the model they exist.
+func searchVisible(adv *domain.Adventure, st *domain.SessionState, kind, id string) bool {
+	switch kind {
+	case "room":
+		if r, _ := adv.Room(id); r != nil && r.Secret {
+			return st.RoomRevealed(r)
+		}
+	case "npc":
+		if n := adv.NPC(id); n != nil && n.Secret {
+			return st.NPCRevealed(n)
+		}
+	case "event":
+		if e := adv.Event(id); e != nil && e.Secret {
+			return st.EventRevealed(e)
+		}
+	case "item":
+		if it := adv.Item(id); it != nil && it.Secret {
+			return st.IsRevealed(it.Visibility, "")
+		}
+	}
+	return true
+}
@@ -0,0 +1,41 @@
+package engine
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/theburrowhub/thaimaturgy/internal/domain"
+)
+
+func TestSearchModuleRanksAndExcerpts(t *testing.T) {
+	s := createTestSession()
+	s.Adventure.Zones[0].Rooms[1].DMNotes = "The guard captain keeps the vault key on a chain around his neck."
+	s.Adventure.Tables = []domain.Table{{ID: "rumours", Name: "Rumours", Rows: []domain.TableRow{{Cells: []string{"The guards take bribes."}}}}}
+	s.Adventure.Scenes = []domain.Scene{{ID: "siege", Name: "The Siege", Description: "Guards man the walls."}}
+
+	res := combatCall(NewToolRouter(s), "search_module", map[string]any{"query": "guards"})
+	lines := strings.Split(res.Content, "\n")
+	if len(lines) != 4 || !strings.HasPrefix(lines[0], "npc [guard] Gate Guard") {
+		t.Fatalf("the NPC named for the query should rank first:\n%s", res.Content)
+	}
+	for _, want := range []string{"room [r2] Hall — The guard captain keeps the vault key", "table [rumours] Rumours", "scene [siege] The Siege"} {
+		if !strings.Contains(res.Content, want) {
+			t.Errorf("search missing %q:\n%s", want, res.Content)
+		}
+	}
+
+	r := NewCommandHandler(s).Execute(ParseCommand("/search vault key"))
+	if !strings.HasPrefix(r.Response, "room [r2] Hall") {
+		t.Errorf("/search = %q", r.Response)
+	}
+}
+
+func TestAdventureIndexIsCachedPerAdventure(t *testing.T) {
+	a, b := createTestSession().Adventure, createTestSession().Adventure
+	if AdventureIndex(a) != AdventureIndex(a) {
+		t.Error("the index should be built once per adventure")
+	}
+	if AdventureIndex(a) == AdventureIndex(b) {
+		t.Error("distinct adventures share an index")
+	}
+}
@@ -0,0 +1,536 @@
+package engine
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/theburrowhub/thaimaturgy/internal/domain"
+)
+
+// This file implements the edits a player may make to their OWN character sheet
+// (#31): HP, AC, temp HP, spell slots, conditions, gold, XP, inventory, save and
+// skill proficiencies, spells and notes. The Telegram bot (/hp, /gold, /item…)
+// and the web player portal share it, so a player can change exactly the same
+// things from either. Anything else on a sheet is the host's to change.
+
+const (
+	// maxSheetDelta bounds an HP/gold/XP amount so the subsequent arithmetic on the
+	// character can never overflow (a player can't wrap HP negative with a huge
+	// heal). maxItemQty bounds a single inventory quantity for the same reason.
+	maxSheetDelta = 1_000_000
+	maxItemQty    = 10_000
+)
+
+// SheetEdits names the player sheet edits, as ParseSheetEdit takes them.
+var SheetEdits = []string{"hp", "ac", "temphp", "slot", "condition", "uncondition", "gold", "xp", "item", "savethrow", "skill", "spell", "setnote"}
+
+// sheetEditAliases maps the Telegram command aliases to their edit.
+var sheetEditAliases = map[string]string{
+	"thp": "temphp", "slots": "slot", "cond": "condition", "uncond": "uncondition",
+	"inv": "item", "st": "savethrow", "spells": "spell",
+}
+
+// knownConditions maps a lower-cased condition name to its canonical form, so a
+// player can type "/condition poisoned" and get the standard "Poisoned".
+var knownConditions = map[string]domain.Condition{
+	"blinded": domain.ConditionBlinded, "charmed": domain.ConditionCharmed,
+	"deafened": domain.ConditionDeafened, "exhausted": domain.ConditionExhausted,
+	"frightened": domain.ConditionFrightened, "grappled": domain.ConditionGrappled,
+	"incapacitated": domain.ConditionIncapacitated, "invisible": domain.ConditionInvisible,
+	"paralyzed": domain.ConditionParalyzed, "petrified": domain.ConditionPetrified,
+	"poisoned": domain.ConditionPoisoned, "prone": domain.ConditionProne,
+	"restrained": domain.ConditionRestrained, "stunned": domain.ConditionStunned,
+	"unconscious": domain.ConditionUnconscious,
+}
+
+// canonicalCondition resolves free-form input to a known 5e condition, reporting
+// whether it matched.
+func canonicalCondition(s string) (domain.Condition, bool) {
+	c, ok := knownConditions[strings.ToLower(strings.TrimSpace(s))]
+	return c, ok
+}
+
+// SheetEdit is a parsed player sheet edit, ready to Apply.
+type SheetEdit struct {
+	Kind string // one of SheetEdits
+
+	n       int    // hp/ac/temphp/gold/xp amount
+	set     bool   // n sets the value rather than adjusting it
+	level   int    // slot or spell level
+	action  string // slot use|restore, item add|remove, spell add|remove|prepare|unprepare, skill prof|expert|none
+	name    string // item, skill or spell name
+	qty     int    // item quantity
+	cond    domain.Condition
+	ability domain.Ability
+	prof    bool   // savethrow on/off
+	text    string // setnote
+}
+
+const spellUsage = "Usage: /spell add <level 0-9> <name> | /spell remove <name> | /spell prepare <name> | /spell unprepare <name>"
+
+// ParseSheetEdit parses a player sheet edit: kind is its name (or a Telegram
+// alias such as "thp" or "inv") and arg the rest of the command as a player
+// types it ("-5", "add Rope x2", "Perception prof"). The error is a usage hint
+// to show the player.
+func ParseSheetEdit(kind, arg string) (*SheetEdit, error) {
+	kind = strings.ToLower(strings.TrimSpace(kind))
+	if k, ok := sheetEditAliases[kind]; ok {
+		kind = k
+	}
+	e := &SheetEdit{Kind: kind}
+	var err error
+	switch kind {
+	case "hp":
+		err = e.parseAmount(arg, "HP", "Usage: /hp -5 (damage), /hp +3 (heal), /hp =10 (set current HP)")
+	case "ac":
+		err = e.parseAmount(arg, "AC", "Usage: /ac =15 (set), /ac +2, or /ac -1")
+	case "temphp":
+		err = e.parseAmount(arg, "Temp HP", "Usage: /temphp =5 (set), /temphp +3, or /temphp -2")
+	case "gold":
+		err = e.parseAmount(arg, "Gold", "Usage: /gold +50, /gold -10, or /gold =100 (set)")
+	case "xp":
+		n, perr := strconv.Atoi(strings.TrimSpace(arg))
+		if perr != nil || n <= 0 || n > maxSheetDelta {
+			return nil, fmt.Errorf("Usage: /xp 150 — award experience (1..%d)", maxSheetDelta)
+		}
+		e.n = n
+	case "slot":
+		var ok bool
+		if e.level, e.action, ok = parseSlotArg(arg); !ok {
+			return nil, errors.New("Usage: /slot <level 1-9> use|restore")
+		}
+	case "condition", "uncondition":
+		var ok bool
+		if e.cond, ok = canonicalCondition(arg); !ok {
+			return nil, fmt.Errorf("Usage: /%s <name> — one of: blinded, charmed, deafened, exhausted, frightened, grappled, incapacitated, invisible, paralyzed, petrified, poisoned, prone, restrained, stunned, unconscious", kind)
+		}
+	case "item":
+		var ok bool
+		if e.action, e.name, e.qty, ok = parseItemArg(arg); !ok {
+			return nil, errors.New("Usage: /item add <name> [xN]  |  /item remove <name> [xN]")
+		}
+	case "savethrow":
+		err = e.parseSave(arg)
+	case "skill":
+		var ok bool
+		if e.name, e.action, ok = parseSkillArg(arg); !ok {
+			return nil, errors.New("Usage: /skill <name> prof|expert|none  (e.g. /skill Perception prof)")
+		}
+	case "spell":
+		var ok bool
+		if e.action, e.name, e.level, ok = parseSpellArg(arg); !ok {
+			return nil, errors.New(spellUsage)
+		}
+	case "setnote":
+		if e.text = strings.TrimSpace(arg); e.text == "" {
+			return nil, errors.New("Usage: /setnote <text> — set your character's notes")
+		}
+	default:
+		return nil, fmt.Errorf("unknown sheet edit %q (one of: %s)", kind, strings.Join(SheetEdits, ", "))
+	}
+	if err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+// parseAmount parses a set-or-delta amount ("=10", "+3", "-5") into e.
+func (e *SheetEdit) parseAmount(arg, what, usage string) error {
+	n, set, err := parseDelta(arg)
+	if strings.TrimSpace(arg) == "" || err != nil {
+		return errors.New(usage)
+	}
+	if !deltaInRange(n) {
+		return fmt.Errorf("%s amount out of range (use at most ±%d).", what, maxSheetDelta)
+	}
+	e.n, e.set = n, set
+	return nil
+}
+
+// parseSave parses "<ability> on|off" into e.
+func (e *SheetEdit) parseSave(arg string) error {
+	fields := strings.Fields(arg)
+	if len(fields) < 2 {
+		return errors.New("Usage: /savethrow <STR|DEX|CON|INT|WIS|CHA> on|off")
+	}
+	ab, ok := domain.ParseAbility(fields[0])
+	if !ok {
+		return errors.New("Unknown ability. Use one of: STR, DEX, CON, INT, WIS, CHA.")
+	}
+	switch strings.ToLower(fields[1]) {
+	case "on", "yes", "prof", "proficient", "true":
+		e.prof = true
+	case "off", "no", "none", "false":
+		e.prof = false
+	default:
+		return errors.New("Usage: /savethrow <ability> on|off")
+	}
+	e.ability = ab
+	return nil
+}
+
+// Apply applies the edit to c — call it under the session lock, through
+// SessionState.MutateCharacter (or use EditSheet) — and returns how the change
+// reads after the character's name in the timeline ("took 3 damage → 7/10 HP").
+// An edit the sheet doesn't allow (healing the dead, a slot the character
+// doesn't have, an item they don't carry) leaves c untouched and returns an
+// error to show the player. The Character mutators used here each clamp to
+// valid ranges (HP within [0, MaxHP], gold ≥ 0, …), so no separate
+// normalization pass is needed.
+func (e *SheetEdit) Apply(c *domain.Character) (string, error) {
+	switch e.Kind {
+	case "hp":
+		desc, dead := hpEdit(c, e.n, e.set)
+		if dead {
+			return "", fmt.Errorf("%s is dead — only the DM can bring them back.", c.Name)
+		}
+		return desc, nil
+	case "ac":
+		c.AC = max(e.adjusted(c.AC), 0)
+		return fmt.Sprintf("AC is now %d", c.AC), nil
+	case "temphp":
+		c.TempHP = max(e.adjusted(c.TempHP), 0)
+		return fmt.Sprintf("temp HP is now %d", c.TempHP), nil
+	case "gold":
+		if e.set {
+			c.SetGold(e.n)
+		} else {
+			// Clamp the pre-sum to avoid int overflow; SetGold clamps negatives.
+			sum := c.Gold + e.n
+			if (e.n > 0 && sum < c.Gold) || (e.n < 0 && sum > c.Gold) {
+				sum = c.Gold // overflow guard (unreachable given deltaInRange, defensive)
+			}
+			c.SetGold(sum)
+		}
+		return fmt.Sprintf("gold is now %d", c.Gold), nil
+	case "xp":
+		before := c.XP
+		c.AwardXP(e.n)
+		if c.XP < before {
+			c.XP = before // overflow guard (unreachable given the bound, defensive)
+		}
+		desc := fmt.Sprintf("gained %d XP → %d total", e.n, c.XP)
+		if c.LevelsPending() > 0 {
+			desc += fmt.Sprintf(" — enough for level %d (/levelup)", domain.LevelForXP(c.XP))
+		}
+		return desc, nil
+	case "slot":
+		return e.applySlot(c)
+	case "condition":
+		c.AddCondition(e.cond)
+		return "is now " + string(e.cond), nil
+	case "uncondition":
+		c.RemoveCondition(e.cond)
+		return "is no longer " + string(e.cond), nil
+	case "item":
+		return e.applyItem(c)
+	case "savethrow":
+		c.SetSaveProficient(e.ability, e.prof)
+		if e.prof {
+			return fmt.Sprintf("is now proficient in %s saves (%+d)", e.ability, c.SaveBonus(e.ability)), nil
+		}
+		return fmt.Sprintf("dropped %s save proficiency (%+d)", e.ability, c.SaveBonus(e.ability)), nil
+	case "skill":
+		canonical, found := c.SetSkillProficiency(e.name, e.action != "none", e.action == "expert")
+		if !found {
+			return "", fmt.Errorf("Unknown skill %q. Use a standard 5e skill name (e.g. Perception, Stealth, Arcana).", e.name)
+		}
+		switch e.action {
+		case "expert":
+			return fmt.Sprintf("has expertise in %s (%+d)", canonical, c.SkillBonus(canonical)), nil
+		case "prof":
+			return fmt.Sprintf("is proficient in %s (%+d)", canonical, c.SkillBonus(canonical)), nil
+		}
+		return fmt.Sprintf("is no longer proficient in %s (%+d)", canonical, c.SkillBonus(canonical)), nil
+	case "spell":
+		return e.applySpell(c)
+	case "setnote":
+		c.Notes = e.text
+		return "updated their character notes", nil
+	}
+	return "", fmt.Errorf("unknown sheet edit %q", e.Kind)
+}
+
+// adjusted is e's amount applied to cur: the amount itself for a set, else cur
+// plus it.
+func (e *SheetEdit) adjusted(cur int) int {
+	if e.set {
+		return e.n
+	}
+	return cur + e.n
+}
+
+func (e *SheetEdit) applySlot(c *domain.Character) (string, error) {
+	if c.Spellcasting == nil || c.Spellcasting.Slots.MaxAt(e.level) <= 0 {
+		return "", fmt.Errorf("%s has no level-%d spell slots.", c.Name, e.level)
+	}
+	if e.action == "use" {
+		if !c.UseSpellSlot(e.level) {
+			return "", fmt.Errorf("%s has no level-%d slots left.", c.Name, e.level)
+		}
+		return fmt.Sprintf("used a level-%d slot → %d/%d left", e.level, c.Spellcasting.Slots.RemainingAt(e.level), c.Spellcasting.Slots.MaxAt(e.level)), nil
+	}
+	// "restore" (parseSlotArg guarantees one of the two)
+	if !c.RestoreSpellSlot(e.level) {
+		return "", fmt.Errorf("%s already has all level-%d slots.", c.Name, e.level)
+	}
+	return fmt.Sprintf("restored a level-%d slot → %d/%d left", e.level, c.Spellcasting.Slots.RemainingAt(e.level), c.Spellcasting.Slots.MaxAt(e.level)), nil
+}
+
+func (e *SheetEdit) applyItem(c *domain.Character) (string, error) {
+	// RemoveItem matches the name exactly, so use the same match to read the
+	// current stack.
+	have := 0
+	for _, it := range c.Inventory {
+		if it.Name == e.name {
+			have = it.Quantity
+			break
+		}
+	}
+	if e.action == "add" {
+		// Bound the RESULTING stack (not just the incoming qty) so repeated valid
+		// adds can never overflow AddItem's accumulation.
+		if have+e.qty > maxItemQty || have+e.qty < have {
+			return "", fmt.Errorf("%s can't carry that many %q (max %d in a stack).", c.Name, e.name, maxItemQty)
+		}
+		c.AddItem(domain.InventoryItem{Name: e.name, Quantity: e.qty})
+		return fmt.Sprintf("picked up %s x%d", e.name, e.qty), nil
+	}
+	// Removal: report the quantity ACTUALLY removed (bounded by what's carried),
+	// so the DM-facing log never claims more was dropped than existed.
+	if have == 0 {
+		return "", fmt.Errorf("%s isn't carrying %q.", c.Name, e.name)
+	}
+	c.RemoveItem(e.name, e.qty)
+	return fmt.Sprintf("dropped %s x%d", e.name, min(e.qty, have)), nil
+}
+
+func (e *SheetEdit) applySpell(c *domain.Character) (string, error) {
+	switch e.action {
+	case "add":
+		c.AddSpell(domain.Spell{Name: e.name, Level: e.level})
+		if e.level == 0 {
+			return "learned the cantrip " + e.name, nil
+		}
+		return fmt.Sprintf("learned %s (level %d)", e.name, e.level), nil
+	case "remove":
+		if !c.RemoveSpell(e.name) {
+			return "", fmt.Errorf("%s doesn't know %q.", c.Name, e.name)
+		}
+		return "forgot " + e.name, nil
+	}
+	prepare := e.action == "prepare"
+	if !c.SetSpellPrepared(e.name, prepare) {
+		return "", fmt.Errorf("%s doesn't know %q.", c.Name, e.name)
+	}
+	if prepare {
+		return "prepared " + e.name, nil
+	}
+	return "unprepared " + e.name, nil
+}
+
+// EditSheet applies a player sheet edit to the named party member under the
+// session lock, keeps their combatant in step with an HP change, and records the
+// edit in the timeline as a LogParty entry so the DM sees it. It returns the
+// character's name and the description of the change. The caller persists.
+func EditSheet(session *domain.Session, char string, e *SheetEdit) (name, desc string, err error) {
+	// An edit is its own undoable step, so one made while the DM runs a turn
+	// waits for it rather than joining it.
+	session.State.BeginChange(char + ": /" + e.Kind)
+	defer session.State.CommitChange()
+	name, ok := session.State.MutateCharacter(char, func(c *domain.Character) {
+		desc, err = e.Apply(c)
+	})
+	if !ok {
+		return "", "", errors.New("Character not found: " + char)
+	}
+	if err != nil {
+		return name, "", err
+	}
+	if e.Kind == "hp" {
+		SyncPartyCombatant(session, name)
+	}
+	session.State.AppendLog(domain.LogEntry{Type: domain.LogParty, Message: name + " " + desc})
+	return name, desc, nil
+}
+
+// deltaInRange reports whether an HP/gold/XP amount is within safe bounds.
+func deltaInRange(n int) bool { return n >= -maxSheetDelta && n <= maxSheetDelta }
+
+// parseDelta parses an amount that may be a set ("=10") or a signed delta
+// ("+3", "-5", "7"), returning the value and whether it was a set.
+func parseDelta(arg string) (n int, set bool, err error) {
+	arg = strings.TrimSpace(arg)
+	if strings.HasPrefix(arg, "=") {
+		set = true
+		arg = strings.TrimSpace(arg[1:])
+	}
+	n, err = strconv.Atoi(arg)
+	return n, set, err
+}
+
+// hpEdit applies a player's /hp edit to their character and describes it. A dead
+// character is left untouched (dead is true): raising the dead is the DM's call.
+func hpEdit(c *domain.Character, n int, set bool) (desc string, dead bool) {
+	switch {
+	case c.Dead:
+		return "", true
+	case set:
+		c.SetHP(n)
+		desc = fmt.Sprintf("HP set to %d/%d", c.CurrentHP, c.MaxHP)
+	case n < 0:
+		out := c.TakeDamage(-n)
+		desc = fmt.Sprintf("took %d damage → %d/%d HP", -n, c.CurrentHP, c.MaxHP)
+		if note := out.Note(); note != "" {
+			desc += " — " + note
+		}
+	default:
+		c.Heal(n)
+		desc = fmt.Sprintf("healed %d → %d/%d HP", n, c.CurrentHP, c.MaxHP)
+	}
+	return desc, false
+}
+
+// parseSlotArg parses "<level> <use|restore>" into a 1-9 level and a normalized
+// action ("use" or "restore"). ok is false on bad level or action.
+func parseSlotArg(arg string) (level int, action string, ok bool) {
+	fields := strings.Fields(arg)
+	if len(fields) < 2 {
+		return 0, "", false
+	}
+	level, err := strconv.Atoi(fields[0])
+	if err != nil || level < 1 || level > 9 {
+		return 0, "", false
+	}
+	switch strings.ToLower(fields[1]) {
+	case "use", "spend", "cast":
+		return level, "use", true
+	case "restore", "recover", "undo":
+		return level, "restore", true
+	}
+	return 0, "", false
+}
+
+// parseSkillArg splits "<skill name…> prof|expert|none" into the skill name and a
+// normalized level ("prof", "expert" or "none"). The last field is the level and
+// everything before it is the (possibly multi-word) skill name. ok is false on
+// bad syntax.
+func parseSkillArg(arg string) (skill, level string, ok bool) {
+	fields := strings.Fields(arg)
+	if len(fields) < 2 {
+		return "", "", false
+	}
+	switch strings.ToLower(fields[len(fields)-1]) {
+	case "none", "off", "clear":
+		level = "none"
+	case "prof", "proficient", "on":
+		level = "prof"
+	case "expert", "expertise":
+		level = "expert"
+	default:
+		return "", "", false
+	}
+	skill = strings.TrimSpace(strings.Join(fields[:len(fields)-1], " "))
+	if skill == "" {
+		return "", "", false
+	}
+	return skill, level, true
+}
+
+// parseSpellArg parses a /spell argument into an action ("add", "remove",
+// "prepare" or "unprepare"), the spell name, and (for "add") the spell level
+// 0-9 (0 = cantrip). ok is false when the syntax is unusable.
+func parseSpellArg(arg string) (action, name string, level int, ok bool) {
+	fields := strings.Fields(arg)
+	if len(fields) < 2 {
+		return "", "", 0, false
+	}
+	rest := strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(arg), fields[0]))
+	switch strings.ToLower(fields[0]) {
+	case "add":
+		lf := strings.Fields(rest)
+		if len(lf) < 2 {
+			return "", "", 0, false
+		}
+		lvl, err := strconv.Atoi(lf[0])
+		if err != nil || lvl < 0 || lvl > 9 {
+			return "", "", 0, false
+		}
+		sn := strings.TrimSpace(strings.TrimPrefix(rest, lf[0]))
+		if sn == "" {
+			return "", "", 0, false
+		}
+		return "add", sn, lvl, true
+	case "remove", "rm", "forget":
+		return "remove", rest, 0, true
+	case "prepare", "prep":
+		return "prepare", rest, 0, true
+	case "unprepare", "unprep":
+		return "unprepare", rest, 0, true
+	}
+	return "", "", 0, false
+}
+
+// parseItemArg parses "add <name> [xN]" / "remove <name> [xN]" into a normalized
+// action ("add" or "remove"), the item name, and quantity (default 1). ok is
+// false when the syntax is unusable (missing action/name or unknown action).
+func parseItemArg(arg string) (action, itemName string, qty int, ok bool) {
+	fields := strings.Fields(arg)
+	if len(fields) < 2 {
+		return "", "", 0, false
+	}
+	switch strings.ToLower(fields[0]) {
+	case "add":
+		action = "add"
+	case "remove", "rm", "drop":
+		action = "remove"
+	default:
+		return "", "", 0, false
+	}
+	rest := strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(arg), fields[0]))
+	qty = 1
+	if i := strings.LastIndex(rest, " x"); i >= 0 {
+		suffix := strings.TrimSpace(rest[i+2:])
+		// Only a syntactically NUMERIC suffix is treated as a quantity; when it is,
+		// it must parse to a bounded positive int or the whole command is rejected.
+		// This rejects "x0", "x-2", and overflowing "x99999999999999999999" (which
+		// Atoi can't parse) rather than silently folding them into the item name.
+		// A non-numeric suffix (e.g. "x of holding") stays part of the name.
+		if looksNumeric(suffix) {
+			v, err := strconv.Atoi(suffix)
+			if err != nil || v < 1 || v > maxItemQty {
+				return "", "", 0, false
+			}
+			qty = v
+			rest = strings.TrimSpace(rest[:i])
+		}
+	}
+	itemName = strings.TrimSpace(rest)
+	if itemName == "" {
+		return "", "", 0, false
+	}
+	return action, itemName, qty, true
+}
+
+// looksNumeric reports whether s is a (possibly signed) run of decimal digits,
+// i.e. a quantity the user clearly intended — even if it overflows int.
+func looksNumeric(s string) bool {
+	if s == "" {
+		return false
+	}
+	i := 0
+	if s[0] == '+' || s[0] == '-' {
+		i = 1
+	}
+	if i == len(s) {
+		return false
+	}
+	for ; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+	return true
+}
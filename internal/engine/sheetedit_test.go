@@ -1,6 +1,7 @@
-package tgbot
+package engine
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/theburrowhub/thaimaturgy/internal/domain"
@@ -158,3 +159,53 @@ func TestParseSlotArg(t *testing.T) {
 		}
 	}
 }
+
+func TestHPEditLeavesTheDeadToTheDM(t *testing.T) {
+	c := domain.NewCharacter("Kael", "Elf", "Wizard")
+	c.MaxHP, c.CurrentHP = 10, 10
+	if desc, dead := hpEdit(c, -3, false); dead || c.CurrentHP != 7 || desc != "took 3 damage → 7/10 HP" {
+		t.Errorf("damage: %q dead=%v HP=%d", desc, dead, c.CurrentHP)
+	}
+	c.TakeDamage(30) // massive damage
+	for _, edit := range []struct {
+		n   int
+		set bool
+	}{{5, true}, {5, false}, {-1, false}} {
+		if _, dead := hpEdit(c, edit.n, edit.set); !dead || !c.Dead || c.CurrentHP != 0 {
+			t.Errorf("/hp %+v on a dead character: dead=%v HP=%d", edit, c.Dead, c.CurrentHP)
+		}
+	}
+}
+
+func TestEditSheet(t *testing.T) {
+	s := createTestSession()
+	c := domain.NewCharacter("Kael", "Elf", "Wizard")
+	c.MaxHP, c.CurrentHP = 10, 10
+	s.State.SetParty([]*domain.Character{c})
+
+	edit, err := ParseSheetEdit("hp", "-4")
+	if err != nil {
+		t.Fatal(err)
+	}
+	name, desc, err := EditSheet(s, "kael", edit)
+	if err != nil || name != "Kael" || desc != "took 4 damage → 6/10 HP" {
+		t.Fatalf("EditSheet = %q, %q, %v", name, desc, err)
+	}
+	if last := lastLog(s); last.Type != domain.LogParty || last.Message != "Kael took 4 damage → 6/10 HP" {
+		t.Errorf("timeline = %+v", last)
+	}
+
+	// Aliases resolve, and a refusal leaves the sheet alone.
+	if edit, err = ParseSheetEdit("inv", "remove Rope"); err != nil || edit.Kind != "item" {
+		t.Fatalf("inv = %+v, %v", edit, err)
+	}
+	if _, _, err := EditSheet(s, "Kael", edit); err == nil || !strings.Contains(err.Error(), "isn't carrying") {
+		t.Errorf("dropping what isn't carried: %v", err)
+	}
+	if _, err := ParseSheetEdit("hp", "lots"); err == nil || !strings.HasPrefix(err.Error(), "Usage:") {
+		t.Errorf("bad amount: %v", err)
+	}
+	if _, err := ParseSheetEdit("level", "20"); err == nil {
+		t.Error("an edit players can't make parsed")
+	}
+}
@@ -0,0 +1,178 @@
+package engine
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/theburrowhub/thaimaturgy/internal/domain"
+	"github.com/theburrowhub/thaimaturgy/internal/spells"
+	"github.com/theburrowhub/thaimaturgy/internal/types"
+)
+
+// This file wires the SRD spell catalog (internal/spells) into the oracle tools:
+// lookup_spell reads an entry, cast_spell spends the right slot on a caster's
+// sheet and reports the save DC / attack bonus and the scaled dice. Rolling the
+// dice stays with roll_dice / saving_throw / apply_damage.
+
+// formatSpell renders a catalog entry for the model and the DM.
+func formatSpell(s spells.Spell) string {
+	var b strings.Builder
+	head := s.LevelText()
+	if s.Ritual {
+		head += " (ritual)"
+	}
+	fmt.Fprintf(&b, "%s — %s\n", s.Name, head)
+	duration := s.Duration
+	if s.Concentration {
+		duration = "Concentration, " + strings.ToLower(duration)
+	}
+	fmt.Fprintf(&b, "Casting time: %s · Range: %s · Components: %s · Duration: %s\n",
+		s.CastingTime, s.Range, s.Components, duration)
+	if len(s.Classes) > 0 {
+		b.WriteString("Classes: " + strings.Join(s.Classes, ", ") + "\n")
+	}
+	b.WriteString(s.Description + "\n")
+	if s.HigherLevels != "" {
+		label := "At higher levels"
+		if s.Level == 0 {
+			label = "Cantrip upgrade"
+		}
+		b.WriteString(label + ": " + s.HigherLevels + "\n")
+	}
+	b.WriteString("Source: " + spells.Source)
+	return b.String()
+}
+
+func (tr *ToolRouter) lookupSpell(id string, args map[string]any) types.ToolResult {
+	name, _ := args["name"].(string)
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return errResult(id, "name is required")
+	}
+	s, ok := spells.Lookup(name)
+	if !ok {
+		if near := spells.Search(name); len(near) > 0 {
+			return errResult(id, fmt.Sprintf("no SRD spell named %q; did you mean: %s?", name, strings.Join(near, ", ")))
+		}
+		return errResult(id, fmt.Sprintf("no SRD spell named %q in the embedded subset. Available: %s. For anything else, describe the spell yourself.", name, strings.Join(spells.Names(), ", ")))
+	}
+	return okResult(id, formatSpell(s))
+}
+
+// spellbookEntry finds a spell on the sheet by name (case-insensitive).
+func spellbookEntry(c *domain.Character, name string) *domain.Spell {
+	for i := range c.Spellcasting.Spells {
+		if strings.EqualFold(c.Spellcasting.Spells[i].Name, strings.TrimSpace(name)) {
+			return &c.Spellcasting.Spells[i]
+		}
+	}
+	return nil
+}
+
+// lowestSlot is the lowest slot level at or above level that c has unspent, or
+// 0 when none is left. For a warlock it is the pact slot level.
+func lowestSlot(c *domain.Character, level int) int {
+	for l := max(level, 1); l <= 9; l++ {
+		if c.SpellSlotsRemaining(l) > 0 {
+			return l
+		}
+	}
+	return 0
+}
+
+// castSpell casts a spell from c's sheet: a catalog entry, or a homebrew spell
+// from the spellbook. A cantrip or a ritual costs no slot; otherwise slot (0 =
+// the lowest unspent slot that can hold the spell, which for a warlock is the
+// pact slot) is spent via UseSpellSlot and must be at least the spell's level —
+// a higher slot upcasts it.
+func castSpell(c *domain.Character, name string, slot int, ritual bool) (string, error) {
+	sc := c.Spellcasting
+	if sc == nil {
+		return "", fmt.Errorf("%s has no spellcasting", c.Name)
+	}
+	known := spellbookEntry(c, name)
+	sp, ok := spells.Lookup(name)
+	if !ok {
+		if known == nil {
+			return "", fmt.Errorf("no spell %q in the SRD catalog or %s's spellbook", name, c.Name)
+		}
+		sp = spells.Spell{Name: known.Name, Level: known.Level, School: known.School}
+	}
+
+	var how string
+	switch {
+	case sp.Level == 0:
+		how = "cantrip"
+	case ritual:
+		if !sp.Ritual {
+			return "", fmt.Errorf("%s can't be cast as a ritual", sp.Name)
+		}
+		slot = sp.Level
+		how = "as a ritual, no slot spent"
+	default:
+		if slot == 0 {
+			if slot = lowestSlot(c, sp.Level); slot == 0 {
+				return "", fmt.Errorf("%s has no spell slots of %s level or higher left", c.Name, spells.Ordinal(sp.Level))
+			}
+		}
+		if slot < sp.Level {
+			return "", fmt.Errorf("%s is a %s spell; it needs a slot of %s level or higher", sp.Name, sp.LevelText(), spells.Ordinal(sp.Level))
+		}
+		if !c.UseSpellSlot(slot) {
+			return "", fmt.Errorf("%s has no %s-level slots left", c.Name, spells.Ordinal(slot))
+		}
+		how = fmt.Sprintf("%s-level slot", spells.Ordinal(slot))
+		if slot > sp.Level {
+			how += ", upcast"
+		}
+		how += fmt.Sprintf("; %d left", c.SpellSlotsRemaining(slot))
+	}
+
+	parts := []string{}
+	if sp.Save != "" {
+		parts = append(parts, fmt.Sprintf("%s save DC %d", sp.Save, sc.SaveDC))
+	}
+	if sp.Attack {
+		parts = append(parts, fmt.Sprintf("spell attack %+d", sc.AttackBonus))
+	}
+	if dice := sp.DiceAt(slot, c.Level); dice != "" {
+		if sp.AddModifier {
+			amount, kind, _ := strings.Cut(dice, " ")
+			dice = strings.TrimSpace(fmt.Sprintf("%s%+d %s", amount, domain.Modifier(c.Abilities.Get(sc.Ability)), kind))
+		}
+		parts = append(parts, dice)
+	}
+	if sp.Concentration {
+		parts = append(parts, "concentration ("+strings.ToLower(sp.Duration)+")")
+	}
+	msg := fmt.Sprintf("%s casts %s (%s)", c.Name, sp.Name, how)
+	if len(parts) > 0 {
+		msg += ": " + strings.Join(parts, "; ")
+	}
+	if ok && known == nil && len(sc.Spells) > 0 {
+		msg += fmt.Sprintf(" [not in %s's spellbook]", c.Name)
+	}
+	return msg, nil
+}
+
+func (tr *ToolRouter) castSpell(id string, args map[string]any) types.ToolResult {
+	name, _ := args["spell"].(string)
+	if strings.TrimSpace(name) == "" {
+		return errResult(id, "spell is required")
+	}
+	slot, _ := intArg(args, "slot_level")
+	ritual, _ := args["ritual"].(bool)
+	var failure error
+	res := tr.mutatePC(id, args, func(c *domain.Character) string {
+		msg, err := castSpell(c, name, slot, ritual)
+		if err != nil {
+			failure = err
+			return ""
+		}
+		return msg
+	})
+	if failure != nil {
+		return errResult(id, failure.Error())
+	}
+	return res
+}
@@ -0,0 +1,90 @@
+package engine
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/theburrowhub/thaimaturgy/internal/domain"
+)
+
+func TestLookupSpellTool(t *testing.T) {
+	tr := NewToolRouter(createTestSession())
+	r := combatCall(tr, "lookup_spell", map[string]any{"name": "fireball"})
+	if r.Error != "" || !strings.Contains(r.Content, "Fireball — 3rd-level evocation") ||
+		!strings.Contains(r.Content, "At higher levels") || !strings.Contains(r.Content, "SRD 5.1") {
+		t.Fatalf("lookup_spell fireball = %+v", r)
+	}
+	r = combatCall(tr, "lookup_spell", map[string]any{"name": "bless"})
+	if !strings.Contains(r.Content, "Concentration, up to 1 minute") {
+		t.Errorf("Bless should show concentration: %s", r.Content)
+	}
+	if r := combatCall(tr, "lookup_spell", map[string]any{"name": "heal"}); r.Error != "" || !strings.HasPrefix(r.Content, "Heal —") {
+		t.Errorf("an exact name should win over partial matches: %+v", r)
+	}
+	if r := combatCall(tr, "lookup_spell", map[string]any{"name": "healing"}); !strings.Contains(r.Error, "Healing Word") {
+		t.Errorf("a partial name should suggest matches: %+v", r)
+	}
+}
+
+func TestCastSpellSpendsSlots(t *testing.T) {
+	session := createTestSession()
+	pc := soloParty(session, domain.GenerateCharacter("Mira", "Human", "Cleric", 3))
+	tr := NewToolRouter(session)
+	sc := pc.Spellcasting
+
+	r := combatCall(tr, "cast_spell", map[string]any{"spell": "cure wounds"})
+	if r.Error != "" || sc.Slots.RemainingAt(1) != 3 || !strings.Contains(r.Content, "1st-level slot; 3 left") ||
+		!strings.Contains(r.Content, "1d8+3 healing") {
+		t.Fatalf("cure wounds = %+v, slots %+v", r, sc.Slots)
+	}
+	if e := lastLog(session); e.Type != domain.LogParty || !strings.HasPrefix(e.Message, "Mira casts Cure Wounds") {
+		t.Errorf("cast log entry = %+v", e)
+	}
+
+	r = combatCall(tr, "cast_spell", map[string]any{"spell": "Guiding Bolt", "slot_level": 2})
+	if r.Error != "" || sc.Slots.RemainingAt(2) != 1 || !strings.Contains(r.Content, "upcast") || !strings.Contains(r.Content, "5d6 radiant") {
+		t.Errorf("upcast guiding bolt = %+v", r)
+	}
+	r = combatCall(tr, "cast_spell", map[string]any{"spell": "Sacred Flame"})
+	if r.Error != "" || !strings.Contains(r.Content, "cantrip") || !strings.Contains(r.Content, "DEX save DC 13") {
+		t.Errorf("sacred flame = %+v", r)
+	}
+	r = combatCall(tr, "cast_spell", map[string]any{"spell": "Detect Magic", "ritual": true})
+	if r.Error != "" || sc.Slots.RemainingAt(1) != 3 {
+		t.Errorf("a ritual should spend no slot: %+v", r)
+	}
+
+	for _, bad := range []map[string]any{
+		{"spell": "Spiritual Weapon", "slot_level": 1},
+		{"spell": "Bless", "ritual": true},
+		{"spell": "Wish of the Void"},
+		{},
+	} {
+		if r := combatCall(tr, "cast_spell", bad); r.Error == "" {
+			t.Errorf("cast_spell %v should fail: %+v", bad, r)
+		}
+	}
+
+	// Spending the last 2nd-level slot leaves no way to cast a 2nd-level spell.
+	combatCall(tr, "cast_spell", map[string]any{"spell": "Aid"})
+	if r := combatCall(tr, "cast_spell", map[string]any{"spell": "Aid"}); !strings.Contains(r.Error, "no spell slots of 2nd level or higher") {
+		t.Errorf("out of slots = %+v", r)
+	}
+}
+
+func TestCastSpellUsesPactSlots(t *testing.T) {
+	session := createTestSession()
+	pc := soloParty(session, domain.GenerateCharacter("Vex", "Human", "Warlock", 5))
+	tr := NewToolRouter(session)
+
+	r := combatCall(tr, "cast_spell", map[string]any{"spell": "Hellish Rebuke"})
+	if r.Error != "" || !strings.Contains(r.Content, "3rd-level slot, upcast; 1 left") || !strings.Contains(r.Content, "4d10 fire") {
+		t.Fatalf("pact-slot cast = %+v", r)
+	}
+	if got := pc.SpellSlotsRemaining(3); got != 1 {
+		t.Errorf("pact slots left = %d, want 1", got)
+	}
+	if r := combatCall(tr, "cast_spell", map[string]any{"spell": "Eldritch Blast"}); !strings.Contains(r.Content, "2d10 force") {
+		t.Errorf("a 5th-level warlock's cantrip should scale: %+v", r)
+	}
+}
@@ -101,6 +101,7 @@ func (o *Oracle) reviewSpoilers(ctx context.Context, narration string) string {
 	if err != nil || resp == nil {
 		return narration // fail-open
 	}
+	o.recordUsage(prov, model, domain.PurposeSpoilerGuard, resp)
 	cleaned := strings.TrimSpace(stripFences(resp.Content))
 	// Never ship an empty or cut-off narration; keep the original instead.
 	if cleaned == "" || resp.FinishReason == "length" || resp.FinishReason == "max_tokens" {
@@ -20,6 +20,9 @@ type auditFake struct {
 func (f *auditFake) Name() string         { return "audit-fake" }
 func (f *auditFake) SupportsTools() bool  { return true }
 func (f *auditFake) SupportsVision() bool { return false }
+func (f *auditFake) ChatStream(ctx context.Context, req providers.ChatRequest, _ providers.StreamFunc) (*providers.ChatResponse, error) {
+	return f.Chat(ctx, req)
+}
 func (f *auditFake) Chat(_ context.Context, req providers.ChatRequest) (*providers.ChatResponse, error) {
 	for _, m := range req.Messages {
 		if m.Role == providers.RoleSystem && strings.Contains(m.Content, "spoiler auditor") {
@@ -93,3 +93,27 @@ func TestFormatStatBlockOptionalFieldsIndependent(t *testing.T) {
 		t.Errorf("XP not rendered without CR:\n%s", out)
 	}
 }
+
+func TestGetRoomRatesEncountersForTheParty(t *testing.T) {
+	session := srdSession([]domain.NPC{{ID: "boss", Name: "Grask", StatBlock: &domain.StatBlock{CR: "1"}}})
+	session.Adventure.Zones[0].Rooms[0].Encounters = []domain.Encounter{{
+		Name: "Ambush", Difficulty: "easy", Creatures: []string{"Goblin x3", "boss", "Tarrasque-ish thing"},
+	}}
+	tr := NewToolRouter(session)
+
+	res := call(tr, "get_room", map[string]any{"room_id": "r"})
+	if !strings.Contains(res.Content, "Budget: 350 XP ×2 = 700 adjusted\n") {
+		t.Errorf("without a party the budget is unrated:\n%s", res.Content)
+	}
+	soloParty(session, domain.NewCharacter("Kael", "Elf", "Wizard"))
+	res = call(tr, "get_room", map[string]any{"room_id": "r"})
+	for _, want := range []string{
+		"Creatures: Goblin x3, boss, Tarrasque-ish thing",
+		"Budget: 350 XP ×2.5 = 875 adjusted — deadly for this party (easy 25 / medium 50 / hard 75 / deadly 100)",
+		"No stat block (not counted): Tarrasque-ish thing",
+	} {
+		if !strings.Contains(res.Content, want) {
+			t.Errorf("get_room missing %q:\n%s", want, res.Content)
+		}
+	}
+}
@@ -0,0 +1,35 @@
+package engine
+
+import "github.com/theburrowhub/thaimaturgy/internal/domain"
+
+// EventKind tells what an oracle stream event carries.
+type EventKind string
+
+const (
+	// EventText is a piece of the reply, to be appended to what came before.
+	EventText EventKind = "token"
+	// EventTool reports a tool the oracle ran; Text holds its error, if it failed.
+	EventTool EventKind = "tool"
+	// EventReset discards the text streamed so far: it was the model's preamble
+	// to tool calls, not part of the reply, which starts again after it.
+	EventReset EventKind = "reset"
+)
+
+// Event is one step of a streamed oracle turn.
+type Event struct {
+	Kind EventKind
+	Text string
+	Tool string
+}
+
+// EventFunc receives a streamed turn's events, in order, on the goroutine that
+// called AskStream.
+type EventFunc func(Event)
+
+// streamsText reports whether reply text may be streamed as it is generated.
+// With the spoiler guard reviewing the narration it may not: only the reviewed
+// reply reaches the players, as a single event once the turn is done.
+func (o *Oracle) streamsText() bool {
+	cfg := o.session.Config
+	return cfg == nil || !cfg.SpoilerGuard.Enabled || o.session.State.EffectiveMode() != domain.ModeVirtualDM
+}
@@ -0,0 +1,133 @@
+package engine
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/theburrowhub/thaimaturgy/internal/providers"
+)
+
+// streamFake streams a preamble with a dice roll, then the reply once the
+// roll's result is back; the review call of the spoiler guard gets review.
+type streamFake struct {
+	review   string
+	streamed bool // whether any call was given a StreamFunc
+}
+
+func (f *streamFake) Name() string         { return "stream-fake" }
+func (f *streamFake) SupportsTools() bool  { return true }
+func (f *streamFake) SupportsVision() bool { return false }
+func (f *streamFake) Chat(ctx context.Context, req providers.ChatRequest) (*providers.ChatResponse, error) {
+	return f.ChatStream(ctx, req, nil)
+}
+func (f *streamFake) ChatStream(_ context.Context, req providers.ChatRequest, fn providers.StreamFunc) (*providers.ChatResponse, error) {
+	if fn != nil {
+		f.streamed = true
+	} else {
+		fn = func(string) {}
+	}
+	if strings.Contains(req.Messages[0].Content, "spoiler auditor") {
+		return &providers.ChatResponse{Content: f.review, FinishReason: "stop"}, nil
+	}
+	if last := req.Messages[len(req.Messages)-1]; last.Role == providers.RoleTool {
+		fn("You hit ")
+		fn("the goblin.")
+		return &providers.ChatResponse{Content: "You hit the goblin.", FinishReason: "stop"}, nil
+	}
+	fn("Rolling.")
+	return &providers.ChatResponse{Content: "Rolling.", FinishReason: "tool_calls", ToolCalls: []providers.ToolCallInfo{{
+		ID: "c1", Type: "function",
+		Function: providers.FunctionCall{Name: "roll_dice", Arguments: `{"notation":"1d20"}`},
+	}}}, nil
+}
+
+// describe renders events compactly: "token:You hit", "tool:roll_dice", "reset".
+func describe(events []Event) []string {
+	var out []string
+	for _, e := range events {
+		switch e.Kind {
+		case EventReset:
+			out = append(out, "reset")
+		case EventTool:
+			out = append(out, "tool:"+e.Tool+e.Text)
+		default:
+			out = append(out, "token:"+e.Text)
+		}
+	}
+	return out
+}
+
+func TestAskStreamEvents(t *testing.T) {
+	fp := &streamFake{}
+	o := NewOracle(createTestSession(), fp)
+	var events []Event
+	resp := o.AskStream(context.Background(), "I attack", func(e Event) { events = append(events, e) })
+	if resp.Error != nil {
+		t.Fatal(resp.Error)
+	}
+	if resp.Answer != "You hit the goblin." {
+		t.Errorf("answer = %q", resp.Answer)
+	}
+	want := "token:Rolling.|reset|tool:roll_dice|token:You hit |token:the goblin."
+	if got := strings.Join(describe(events), "|"); got != want {
+		t.Errorf("events = %s, want %s", got, want)
+	}
+}
+
+func TestAskWithoutStreamUsesChat(t *testing.T) {
+	fp := &streamFake{}
+	if resp := NewOracle(createTestSession(), fp).Ask(context.Background(), "I attack"); resp.Answer != "You hit the goblin." {
+		t.Errorf("answer = %q", resp.Answer)
+	}
+	if fp.streamed {
+		t.Error("Ask must not stream")
+	}
+}
+
+// With the spoiler guard on, raw narration never streams: only the reviewed
+// reply is delivered, once.
+func TestAskStreamHoldsTextForSpoilerGuard(t *testing.T) {
+	s := spoilerTestSession()
+	s.Config.SpoilerGuard.Enabled = true
+	fp := &streamFake{review: "A cloaked figure watches."}
+	var events []Event
+	resp := NewOracle(s, fp).AskStream(context.Background(), "we look around", func(e Event) { events = append(events, e) })
+	if resp.Error != nil {
+		t.Fatal(resp.Error)
+	}
+	want := "tool:roll_dice|token:A cloaked figure watches."
+	if got := strings.Join(describe(events), "|"); got != want {
+		t.Errorf("events = %s, want %s", got, want)
+	}
+}
+
+// A recorded turn replays without the provider, its tool calls still run
+// through the router — though the dice come up differently this time.
+func TestReplayedTurnRunsTools(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "turn.json")
+	rec, err := providers.Record(&streamFake{}, path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp := NewOracle(createTestSession(), rec).Ask(context.Background(), "I attack"); resp.Error != nil {
+		t.Fatal(resp.Error)
+	}
+
+	replay, err := providers.Replay(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var events []Event
+	resp := NewOracle(createTestSession(), replay).AskStream(context.Background(), "I attack", func(e Event) { events = append(events, e) })
+	if resp.Error != nil {
+		t.Fatal(resp.Error)
+	}
+	if resp.Answer != "You hit the goblin." {
+		t.Errorf("answer = %q", resp.Answer)
+	}
+	if got := strings.Join(describe(events), "|"); !strings.Contains(got, "tool:roll_dice") {
+		t.Errorf("events = %s, want the replayed roll", got)
+	}
+}
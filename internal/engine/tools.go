@@ -10,10 +10,11 @@ import (
 	"github.com/theburrowhub/thaimaturgy/internal/types"
 )
 
-// AvailableTools is the oracle tool set. Tools fall into three groups:
+// AvailableTools is the oracle tool set. Tools fall into four groups:
 //   - retrieval: read authored content from the adventure module on demand
 //   - mutation:  record the running session state the DM feeds in
 //   - dice:      quick mechanical rolls for the DM
+//   - combat:    the initiative / turn-order tracker
 var AvailableTools = []types.Tool{
 	// --- Retrieval ------------------------------------------------------
 	{
@@ -81,7 +82,7 @@ var AvailableTools = []types.Tool{
 	},
 	{
 		Name:        "search_module",
-		Description: "Search the whole adventure module (zones, rooms, NPCs, events, items, lore) for a keyword and return matching IDs with names.",
+		Description: "Search the whole adventure module (zones, rooms, NPCs, events, items, tables, scenes, factions, lore). Takes one or more words; returns the best matches first, each with its ID, name and an excerpt of the matching text.",
 		Parameters: json.RawMessage(`{
 			"type":"object",
 			"properties":{"query":{"type":"string"}},
@@ -102,6 +103,15 @@ var AvailableTools = []types.Tool{
 			"required":["name"]
 		}`),
 	},
+	{
+		Name:        "lookup_spell",
+		Description: "Look up a spell by name from the embedded D&D 5e SRD: level, school, casting time, range, components, duration, concentration, what it does and how it scales at higher levels. Use it before adjudicating a spell you are unsure of.",
+		Parameters: json.RawMessage(`{
+			"type":"object",
+			"properties":{"name":{"type":"string","description":"The spell's name, e.g. 'fireball'"}},
+			"required":["name"]
+		}`),
+	},
 	{
 		Name:        "list_exits",
 		Description: "List the current room's exits and the zones directly adjacent to the current zone, with directions. Use it to know where the party may move next.",
@@ -275,7 +285,7 @@ var AvailableTools = []types.Tool{
 	// --- Dice -----------------------------------------------------------
 	{
 		Name:        "roll_dice",
-		Description: "Roll dice in standard notation (e.g. '1d20', '2d6+3', '8d6'). Use for the DM's quick rolls.",
+		Description: "Roll a dice expression (e.g. '1d20', '2d6+3', '8d6', '1d8+2d6[fire]+3', '2d20kh1+5' advantage, '2d20kl1' disadvantage, '4d6kh3', '2d6r1' reroll 1s once, '1d6!' exploding, 'd%'). Use for the DM's quick rolls.",
 		Parameters: json.RawMessage(`{
 			"type":"object",
 			"properties":{"notation":{"type":"string"},"reason":{"type":"string"}},
@@ -284,15 +294,180 @@ var AvailableTools = []types.Tool{
 	},
 	{
 		Name:        "ability_check",
-		Description: "Roll a d20 + modifier against a DC and report success/failure.",
+		Description: "Roll a check against a DC with the bonus computed from the sheet: give 'character' plus a 'skill' (e.g. 'Stealth'), or an 'ability' (a raw ability check, or a saving throw with save=true). NPCs and SRD creatures use their stat block. 'characters' makes a group check (succeeds if at least half succeed); 'opponent' makes a contested check instead (no DC; ties keep the status quo). Without a character, rolls d20 + 'modifier'.",
 		Parameters: json.RawMessage(`{
 			"type":"object",
 			"properties":{
-				"modifier":{"type":"integer"},
+				"character":{"type":"string","description":"Who rolls: a party member, NPC id/name or SRD creature"},
+				"characters":{"type":"array","items":{"type":"string"},"description":"Several characters: a group check"},
+				"skill":{"type":"string"},
+				"ability":{"type":"string","description":"STR, DEX, CON, INT, WIS or CHA"},
+				"save":{"type":"boolean","description":"Roll a saving throw for 'ability'"},
 				"dc":{"type":"integer"},
+				"advantage":{"type":"boolean"},
+				"disadvantage":{"type":"boolean"},
+				"bonus":{"type":"integer","description":"Situational bonus added to the computed one (e.g. guidance rolled separately)"},
+				"opponent":{"type":"string","description":"Contested check against this creature or character"},
+				"opponent_skill":{"type":"string","description":"The opponent's skill (defaults to the same check)"},
+				"opponent_ability":{"type":"string"},
+				"opponent_advantage":{"type":"boolean"},
+				"opponent_disadvantage":{"type":"boolean"},
+				"modifier":{"type":"integer","description":"Only without a character: the total bonus"},
 				"label":{"type":"string","description":"What the check is for"}
+			}
+		}`),
+	},
+	// --- Combat ---------------------------------------------------------
+	{
+		Name:        "start_combat",
+		Description: "Start tracking a fight: rolls initiative (1d20 + DEX mod) for the party and every listed combatant and sets the turn order. Creatures take HP/AC/DEX from the authored NPC (npc_id or name) or the SRD; give hp/ac for anything else. Replaces any fight in progress.",
+		Parameters: json.RawMessage(`{
+			"type":"object",
+			"properties":{
+				"combatants":{"type":"array","description":"Everyone besides the party. An entry naming a party member only sets that member's initiative.","items":{
+					"type":"object",
+					"properties":{
+						"name":{"type":"string","description":"Display name or SRD creature (e.g. 'Goblin')"},
+						"npc_id":{"type":"string","description":"Authored NPC id"},
+						"side":{"type":"string","enum":["foe","ally","party"],"description":"Default foe"},
+						"count":{"type":"integer","description":"Number of identical creatures (numbered automatically)"},
+						"initiative":{"type":"integer","description":"Use this initiative result instead of rolling"},
+						"initiative_bonus":{"type":"integer"},
+						"hp":{"type":"integer"},
+						"ac":{"type":"integer"}
+					}
+				}},
+				"include_party":{"type":"boolean","description":"Add every party member (default true)"},
+				"include_creatures":{"type":"boolean","description":"Add every spawned creature instance still standing in the current room"}
+			}
+		}`),
+	},
+	{
+		Name:        "end_turn",
+		Description: "End the current combatant's turn and advance to the next in initiative order (wrapping to a new round), skipping defeated combatants.",
+		Parameters: json.RawMessage(`{
+			"type":"object",
+			"properties":{
+				"defeated":{"type":"array","items":{"type":"string"},"description":"Combatants taken out this turn (skipped from now on)"}
+			}
+		}`),
+	},
+	{
+		Name:        "attack_roll",
+		Description: "Roll an attack against a target's AC (party sheet, combatant, NPC or SRD creature). On a hit, rolls the damage (dice doubled on a natural 20), applies the target's resistances/immunities/vulnerabilities for the damage type, and deducts it from the target's HP. Give 'bonus' and 'damage', or name one of the attacker's stat block 'action's to use its to-hit and damage.",
+		Parameters: json.RawMessage(`{
+			"type":"object",
+			"properties":{
+				"attacker":{"type":"string"},
+				"target":{"type":"string"},
+				"action":{"type":"string","description":"An action from the attacker's stat block (e.g. 'Scimitar')"},
+				"bonus":{"type":"integer","description":"Attack bonus"},
+				"damage":{"type":"string","description":"Damage dice with optional type, e.g. '1d8+3 slashing'"},
+				"damage_type":{"type":"string"},
+				"magical":{"type":"boolean","description":"Magical attack (bypasses 'nonmagical' resistances)"},
+				"advantage":{"type":"boolean"},
+				"disadvantage":{"type":"boolean"},
+				"ac":{"type":"integer","description":"Target AC when it has none on record"},
+				"label":{"type":"string"}
+			},
+			"required":["target"]
+		}`),
+	},
+	{
+		Name:        "saving_throw",
+		Description: "Roll a saving throw against a DC for one or more targets, using their sheet's save bonus or their stat block's saves. With 'damage' (rolled once for everyone, e.g. a Fireball), failures take it all and successes half (unless half_on_success is false), after resistances.",
+		Parameters: json.RawMessage(`{
+			"type":"object",
+			"properties":{
+				"target":{"type":"string"},
+				"targets":{"type":"array","items":{"type":"string"}},
+				"ability":{"type":"string","description":"STR, DEX, CON, INT, WIS or CHA"},
+				"dc":{"type":"integer"},
+				"bonus":{"type":"integer","description":"Override the save bonus"},
+				"damage":{"type":"string","description":"Damage dice with optional type, e.g. '8d6 fire'"},
+				"damage_type":{"type":"string"},
+				"magical":{"type":"boolean"},
+				"half_on_success":{"type":"boolean","description":"Default true"},
+				"advantage":{"type":"boolean"},
+				"disadvantage":{"type":"boolean"},
+				"label":{"type":"string"}
+			},
+			"required":["ability","dc"]
+		}`),
+	},
+	{
+		Name:        "apply_damage",
+		Description: "Deal a known amount of typed damage to a target, applying its resistances/immunities/vulnerabilities and deducting it from its HP. A party member dropped to 0 HP falls unconscious and starts making death saves; one already at 0 takes a death-save failure instead (two on a critical hit), and massive damage kills outright.",
+		Parameters: json.RawMessage(`{
+			"type":"object",
+			"properties":{
+				"target":{"type":"string"},
+				"amount":{"type":"integer"},
+				"damage_type":{"type":"string"},
+				"magical":{"type":"boolean"},
+				"critical":{"type":"boolean","description":"The damage is from a critical hit (counts as two death-save failures against a downed party member)"},
+				"reason":{"type":"string"}
+			},
+			"required":["target","amount"]
+		}`),
+	},
+	{
+		Name:        "end_combat",
+		Description: "Stop tracking the current fight (all foes defeated, fled or surrendered).",
+		Parameters:  json.RawMessage(`{"type":"object","properties":{}}`),
+	},
+	{
+		Name:        "spawn_creatures",
+		Description: "Spawn tracked creature instances with their own HP (rolled from the stat block's hit dice, or its average): 'count' of one NPC/SRD 'creature' (numbered 'Goblin #1', 'Goblin #2', ...), or every creature of one of the current room's encounters. Instances persist in the session until removed; target them by name with attack_roll, saving_throw, apply_damage or the *_creature tools, and list them in start_combat.",
+		Parameters: json.RawMessage(`{
+			"type":"object",
+			"properties":{
+				"creature":{"type":"string","description":"Authored NPC id/name or SRD creature, e.g. 'goblin'"},
+				"count":{"type":"integer","description":"How many (default 1)"},
+				"encounter":{"type":"string","description":"Instead of 'creature': spawn this encounter of the current room"},
+				"name":{"type":"string","description":"Display name to number the instances after (default the creature's)"},
+				"hp":{"type":"string","enum":["roll","average"],"description":"Roll each instance's HP (default) or use the stat block's average"}
+			}
+		}`),
+	},
+	{
+		Name:        "damage_creature",
+		Description: "Deal damage to one spawned creature instance (e.g. 'Goblin #2'), applying its resistances/immunities/vulnerabilities. At 0 HP it is defeated.",
+		Parameters: json.RawMessage(`{
+			"type":"object",
+			"properties":{
+				"creature":{"type":"string"},
+				"amount":{"type":"integer"},
+				"damage_type":{"type":"string"},
+				"magical":{"type":"boolean"}
 			},
-			"required":["modifier","dc"]
+			"required":["creature","amount"]
+		}`),
+	},
+	{
+		Name:        "heal_creature",
+		Description: "Restore HP to one spawned creature instance, up to its maximum; a defeated one healed above 0 is back on its feet.",
+		Parameters: json.RawMessage(`{
+			"type":"object",
+			"properties":{"creature":{"type":"string"},"amount":{"type":"integer"}},
+			"required":["creature","amount"]
+		}`),
+	},
+	{
+		Name:        "creature_condition",
+		Description: "Add (or with remove=true, clear) a condition on one spawned creature instance, e.g. prone, frightened, restrained.",
+		Parameters: json.RawMessage(`{
+			"type":"object",
+			"properties":{"creature":{"type":"string"},"condition":{"type":"string"},"remove":{"type":"boolean"}},
+			"required":["creature","condition"]
+		}`),
+	},
+	{
+		Name:        "remove_creature",
+		Description: "Remove a spawned creature instance that fled, surrendered or is no longer relevant, or with defeated=true clear every defeated instance.",
+		Parameters: json.RawMessage(`{
+			"type":"object",
+			"properties":{"creature":{"type":"string"},"defeated":{"type":"boolean"}}
 		}`),
 	},
 }
@@ -373,6 +548,44 @@ var playerCharacterTools = []types.Tool{
 			"required":["amount"]
 		}`),
 	},
+	{
+		Name:        "level_up",
+		Description: "Level up a party member by the 5e rules: every level their XP has earned (or one level with milestone=true). Raises max and current HP by a hit die + CON, and updates the proficiency bonus, spell slots and hit dice. Reports any Ability Score Improvement to apply.",
+		Parameters: json.RawMessage(`{
+			"type":"object",
+			"properties":{
+				"character":{"type":"string"},
+				"hp":{"type":"string","enum":["average","roll"],"description":"Take the fixed average per level (default) or roll the hit die"},
+				"milestone":{"type":"boolean","description":"Grant one level regardless of XP"}
+			}
+		}`),
+	},
+	{
+		Name:        "death_save",
+		Description: "Roll a death saving throw for a party member at 0 HP (do it at the start of each of their turns while dying): 10+ succeeds, a natural 1 is two failures, a natural 20 brings them back with 1 HP; three successes stabilize, three failures kill. With stabilize=true, instead stabilize them (a successful DC 10 Medicine check, Spare the Dying, a healer's kit). With revive, bring a DEAD character back with that many HP — only when the fiction does it (Revivify, Raise Dead); healing and update_hp never raise the dead.",
+		Parameters: json.RawMessage(`{
+			"type":"object",
+			"properties":{
+				"character":{"type":"string"},
+				"stabilize":{"type":"boolean","description":"Stabilize the dying character instead of rolling"},
+				"revive":{"type":"integer","description":"Bring the dead character back with this many HP instead of rolling"}
+			}
+		}`),
+	},
+	{
+		Name:        "cast_spell",
+		Description: "A party member casts a spell: spends the spell slot on their sheet (a cantrip or ritual spends none) and reports the spell save DC or attack bonus and the damage/healing dice at the slot level. Pass slot_level to upcast; by default the lowest available slot is used (a warlock's pact slot). Then resolve it with saving_throw, attack_roll, roll_dice or update_hp.",
+		Parameters: json.RawMessage(`{
+			"type":"object",
+			"properties":{
+				"character":{"type":"string"},
+				"spell":{"type":"string","description":"Spell name, e.g. 'Cure Wounds'"},
+				"slot_level":{"type":"integer","description":"Slot level to spend (1-9); at least the spell's level"},
+				"ritual":{"type":"boolean","description":"Cast as a ritual (ritual spells only; no slot spent)"}
+			},
+			"required":["spell"]
+		}`),
+	},
 }
 
 // ToolRouter executes oracle tool calls against a running session.
@@ -400,8 +613,14 @@ func (tr *ToolRouter) GetToolDefinitions() []types.Tool {
 func (tr *ToolRouter) adv() *domain.Adventure      { return tr.session.Adventure }
 func (tr *ToolRouter) state() *domain.SessionState { return tr.session.State }
 
-// Execute dispatches a tool call and returns its result.
-func (tr *ToolRouter) Execute(call types.ToolCall) types.ToolResult {
+// Execute dispatches a tool call and returns its result, naming the call in
+// the session's open change set when it changed the state.
+func (tr *ToolRouter) Execute(call types.ToolCall) (res types.ToolResult) {
+	tr.state().TrackChange(call.Name, func() { res = tr.dispatch(call) })
+	return res
+}
+
+func (tr *ToolRouter) dispatch(call types.ToolCall) types.ToolResult {
 	var args map[string]any
 	if len(call.Arguments) > 0 {
 		if err := json.Unmarshal(call.Arguments, &args); err != nil {
@@ -430,6 +649,8 @@ func (tr *ToolRouter) Execute(call types.ToolCall) types.ToolResult {
 		return tr.listPresentNPCs(call.ID)
 	case "lookup_creature":
 		return tr.lookupCreature(call.ID, args)
+	case "lookup_spell":
+		return tr.lookupSpell(call.ID, args)
 	case "list_exits":
 		return tr.listExits(call.ID)
 	case "find_path":
@@ -482,6 +703,34 @@ func (tr *ToolRouter) Execute(call types.ToolCall) types.ToolResult {
 		return tr.updateGold(call.ID, args)
 	case "award_xp":
 		return tr.awardXP(call.ID, args)
+	case "level_up":
+		return tr.levelUp(call.ID, args)
+	case "cast_spell":
+		return tr.castSpell(call.ID, args)
+	case "death_save":
+		return tr.deathSave(call.ID, args)
+	case "start_combat":
+		return tr.startCombat(call.ID, args)
+	case "end_turn":
+		return tr.endTurn(call.ID, args)
+	case "end_combat":
+		return tr.endCombat(call.ID)
+	case "spawn_creatures":
+		return tr.spawnCreatures(call.ID, args)
+	case "damage_creature":
+		return tr.damageCreature(call.ID, args)
+	case "heal_creature":
+		return tr.healCreature(call.ID, args)
+	case "creature_condition":
+		return tr.creatureCondition(call.ID, args)
+	case "remove_creature":
+		return tr.removeCreature(call.ID, args)
+	case "attack_roll":
+		return tr.attackRoll(call.ID, args)
+	case "saving_throw":
+		return tr.savingThrow(call.ID, args)
+	case "apply_damage":
+		return tr.applyDamageTool(call.ID, args)
 	default:
 		return errResult(call.ID, "unknown tool: "+call.Name)
 	}
@@ -495,6 +744,9 @@ func (tr *ToolRouter) getRoom(id string, args map[string]any) types.ToolResult {
 	if r == nil {
 		return errResult(id, "no room with id "+rid)
 	}
+	if r.Secret && gated(tr.state()) && !tr.state().RoomRevealed(r) {
+		return withheldResult(id, "room "+r.ID, r.Visibility)
+	}
 	// Render the room under the active scene so retrieval matches what the party
 	// currently sees (same location, scene-appropriate state).
 	eff, present := effectiveRoom(tr.adv().Scene(tr.state().Scene()), r)
@@ -506,7 +758,7 @@ func (tr *ToolRouter) getRoom(id string, args map[string]any) types.ToolResult {
 		cp.ReadAloud = ""
 		eff = &cp
 	}
-	body := FormatRoom(tr.adv(), eff)
+	body := FormatRoom(tr.adv(), eff, tr.state())
 	if present != "" {
 		body = "In this scene, notably: " + present + "\n" + body
 	}
@@ -528,6 +780,9 @@ func (tr *ToolRouter) getNPC(id string, args map[string]any) types.ToolResult {
 	if n == nil {
 		return errResult(id, "no npc with id "+nid)
 	}
+	if n.Secret && gated(tr.state()) && !tr.state().NPCRevealed(n) {
+		return withheldResult(id, "npc "+n.ID, n.Visibility)
+	}
 	// v2: suppress the authored appearance when a current description overrides it
 	// (copy — never mutate the module); the override is appended below.
 	disp := n
@@ -536,7 +791,7 @@ func (tr *ToolRouter) getNPC(id string, args map[string]any) types.ToolResult {
 		cp.Appearance = ""
 		disp = &cp
 	}
-	out := FormatNPC(tr.adv(), disp)
+	out := FormatNPC(tr.adv(), disp, tr.state())
 	// If the NPC has no authored stat block, auto-fill a full one from the SRD when
 	// its name matches a standard creature (#26). Authored blocks always win.
 	if n.StatBlock == nil {
@@ -569,7 +824,10 @@ func (tr *ToolRouter) getEvent(id string, args map[string]any) types.ToolResult
 	if e == nil {
 		return errResult(id, "no event with id "+eid)
 	}
-	out := FormatEvent(e)
+	if e.Secret && gated(tr.state()) && !tr.state().EventRevealed(e) {
+		return withheldResult(id, "event "+e.ID, e.Visibility)
+	}
+	out := FormatEvent(e, tr.state())
 	if tr.state().TriggeredEvents[eid] {
 		out += "\n[session: already triggered]"
 	}
@@ -582,7 +840,10 @@ func (tr *ToolRouter) getItem(id string, args map[string]any) types.ToolResult {
 	if it == nil {
 		return errResult(id, "no item with id "+iid)
 	}
-	return okResult(id, FormatItem(tr.adv(), it)+tr.worldChangesAppendix("item", it.ID))
+	if it.Secret && gated(tr.state()) && !tr.state().IsRevealed(it.Visibility, "") {
+		return withheldResult(id, "item "+it.ID, it.Visibility)
+	}
+	return okResult(id, FormatItem(tr.adv(), it, tr.state())+tr.worldChangesAppendix("item", it.ID))
 }
 
 // --- World overlay (issue #21) -------------------------------------------
@@ -745,63 +1006,48 @@ func (tr *ToolRouter) rollTable(id string, args map[string]any) types.ToolResult
 
 func (tr *ToolRouter) searchModule(id string, args map[string]any) types.ToolResult {
 	q, _ := args["query"].(string)
-	q = strings.ToLower(strings.TrimSpace(q))
+	q = strings.TrimSpace(q)
 	if q == "" {
 		return errResult(id, "empty query")
 	}
-	adv := tr.adv()
-	var hits []string
-	match := func(kind, id, name string, haystacks ...string) {
-		for _, h := range haystacks {
-			if strings.Contains(strings.ToLower(h), q) {
-				hits = append(hits, fmt.Sprintf("%s [%s] %s", kind, id, name))
-				return
-			}
-		}
-	}
-	for i := range adv.Zones {
-		z := &adv.Zones[i]
-		match("zone", z.ID, z.Name, z.Name, z.Overview, z.Description)
-		for j := range z.Rooms {
-			r := &z.Rooms[j]
-			match("room", r.ID, r.Name, r.Name, r.ReadAloud, r.DMNotes)
-		}
-	}
-	for i := range adv.NPCs {
-		n := &adv.NPCs[i]
-		match("npc", n.ID, n.Name, n.Name, n.Role, n.Personality, n.Motivations, n.Secrets)
-	}
-	for i := range adv.Events {
-		e := &adv.Events[i]
-		match("event", e.ID, e.Name, e.Name, e.Trigger, e.Description, e.DMNotes)
-	}
-	for i := range adv.Items {
-		it := &adv.Items[i]
-		match("item", it.ID, it.Name, it.Name, it.Description, it.Mechanics)
-	}
-	for i := range adv.Lore {
-		l := &adv.Lore[i]
-		match("lore", l.Title, l.Title, l.Title, l.Content)
+	adv, st := tr.adv(), tr.state()
+	// In virtual-DM mode content still secret is not searched at all: a hit,
+	// even on its name alone, would tell the model it exists.
+	var keep func(kind, id string) bool
+	if gated(st) {
+		keep = func(kind, id string) bool { return searchVisible(adv, st, kind, id) }
 	}
+	hits := AdventureIndex(adv).Search(q, searchLimit, keep)
 	if len(hits) == 0 {
 		return okResult(id, "no matches for "+q)
 	}
-	return okResult(id, strings.Join(hits, "\n"))
+	lines := make([]string, 0, len(hits))
+	for _, h := range hits {
+		line := fmt.Sprintf("%s [%s] %s", h.Kind, h.ID, h.Name)
+		if h.Snippet != "" {
+			line += " — " + h.Snippet
+		}
+		lines = append(lines, line)
+	}
+	return okResult(id, strings.Join(lines, "\n"))
 }
 
 func (tr *ToolRouter) listPresentNPCs(id string) types.ToolResult {
-	r, _ := tr.adv().Room(tr.state().CurrentRoom)
+	st := tr.state()
+	r, _ := tr.adv().Room(st.CurrentRoom)
 	if r == nil {
 		return okResult(id, "no current room set")
 	}
-	if len(r.NPCIDs) == 0 {
-		return okResult(id, "no NPCs in the current room")
-	}
 	var lines []string
 	for _, nid := range r.NPCIDs {
-		if n := tr.adv().NPC(nid); n != nil {
-			lines = append(lines, fmt.Sprintf("%s [%s] — %s", n.Name, n.ID, n.Role))
+		n := tr.adv().NPC(nid)
+		if n == nil || n.Secret && gated(st) && !st.NPCRevealed(n) {
+			continue
 		}
+		lines = append(lines, fmt.Sprintf("%s [%s] — %s", n.Name, n.ID, n.Role))
+	}
+	if len(lines) == 0 {
+		return okResult(id, "no NPCs in the current room")
 	}
 	return okResult(id, strings.Join(lines, "\n"))
 }
@@ -853,9 +1099,12 @@ func (tr *ToolRouter) adjacencyWarning(prevZone, newZone string) string {
 func (tr *ToolRouter) listExits(id string) types.ToolResult {
 	adv, st := tr.adv(), tr.state()
 	var sb strings.Builder
-	if room, _ := adv.Room(st.CurrentRoom); room != nil && len(room.Exits) > 0 {
-		sb.WriteString("Room exits:\n")
+	if room, _ := adv.Room(st.CurrentRoom); room != nil {
+		var lines []string
 		for _, ex := range room.Exits {
+			if shown, _ := exitShown(adv, st, room.ID, ex); !shown && gated(st) {
+				continue
+			}
 			dir := ex.Direction
 			if dir == "" {
 				dir = "→"
@@ -864,7 +1113,10 @@ func (tr *ToolRouter) listExits(id string) types.ToolResult {
 			if ex.Locked {
 				line += " (locked)"
 			}
-			sb.WriteString(line + "\n")
+			lines = append(lines, line)
+		}
+		if len(lines) > 0 {
+			sb.WriteString("Room exits:\n" + strings.Join(lines, "\n") + "\n")
 		}
 	}
 	if adj := FormatAdjacency(adv, st.CurrentZone); adj != "" {
@@ -881,8 +1133,11 @@ func (tr *ToolRouter) findPath(id string, args map[string]any) types.ToolResult
 	if to = strings.TrimSpace(to); to == "" {
 		return errResult(id, "to_zone is required")
 	}
-	adv := tr.adv()
-	from := tr.state().CurrentZone
+	adv, st := tr.adv(), tr.state()
+	if r, _ := adv.Room(to); r != nil && r.Secret && gated(st) && !st.RoomRevealed(r) {
+		return withheldResult(id, "room "+r.ID, r.Visibility)
+	}
+	from := st.CurrentZone
 	steps, ok := adv.PathZones(from, to, true)
 	if !ok {
 		return okResult(id, "No known route from the current zone to "+exitTargetName(adv, to)+".")
@@ -916,6 +1171,9 @@ func (tr *ToolRouter) goDirection(id string, args map[string]any) types.ToolResu
 	// Prefer a matching room exit, then a matching adjacent zone.
 	if room, _ := adv.Room(st.CurrentRoom); room != nil {
 		for _, ex := range room.Exits {
+			if shown, _ := exitShown(adv, st, room.ID, ex); !shown && gated(st) {
+				continue
+			}
 			if d, ok := domain.NormalizeDirection(ex.Direction); ok && d == nd {
 				return tr.moveToTarget(id, ex.To)
 			}
@@ -1120,21 +1378,33 @@ func (tr *ToolRouter) updateHP(id string, args map[string]any) types.ToolResult
 	if !hasSet && !hasDelta {
 		return errResult(id, "provide 'delta' or 'set'")
 	}
-	return tr.mutatePC(id, args, func(c *domain.Character) string {
+	var name string
+	res := tr.mutatePC(id, args, func(c *domain.Character) string {
+		name = c.Name
+		note := ""
 		switch {
 		case hasSet:
-			c.SetHP(vSet)
+			if !c.SetHP(vSet) {
+				note = "dead — setting HP has no effect; bring them back with death_save revive"
+			}
 		case delta < 0:
-			c.TakeDamage(-delta)
+			note = c.TakeDamage(-delta).Note()
+		case c.Dead:
+			note = "dead — healing has no effect"
 		default:
 			c.Heal(delta)
 		}
-		msg := fmt.Sprintf("%s HP: %d/%d", c.Name, c.CurrentHP, c.MaxHP)
+		msg := hpStatus(c)
+		if note != "" {
+			msg += " (" + note + ")"
+		}
 		if reason != "" {
 			msg = reason + " — " + msg
 		}
 		return msg
 	})
+	tr.syncPartyCombatant(name)
+	return res
 }
 
 func (tr *ToolRouter) addItem(id string, args map[string]any) types.ToolResult {
@@ -1221,7 +1491,7 @@ func (tr *ToolRouter) awardXP(id string, args map[string]any) types.ToolResult {
 	}
 	return tr.mutatePC(id, args, func(c *domain.Character) string {
 		c.AwardXP(amount) // ignores non-positive amounts
-		return fmt.Sprintf("%s gained %d XP (total %d)", c.Name, amount, c.XP)
+		return fmt.Sprintf("%s gained %d XP (total %d)%s", c.Name, amount, c.XP, levelUpHint(c))
 	})
 }
 
@@ -1252,34 +1522,6 @@ func (tr *ToolRouter) rollDice(id string, args map[string]any) types.ToolResult
 	return okResult(id, msg)
 }
 
-func (tr *ToolRouter) abilityCheck(id string, args map[string]any) types.ToolResult {
-	mod, _ := intArg(args, "modifier")
-	dc, ok := intArg(args, "dc")
-	if !ok {
-		return errResult(id, "missing 'dc'")
-	}
-	label, _ := args["label"].(string)
-	roll := RollD20WithMod(mod)
-	success := roll.Total >= dc
-	res := "FAILURE"
-	if success {
-		res = "SUCCESS"
-	}
-	crit := ""
-	if roll.IsCriticalHit() {
-		crit = " [NAT 20]"
-	} else if roll.IsCriticalFail() {
-		crit = " [NAT 1]"
-	}
-	msg := fmt.Sprintf("Check (DC %d): d20(%d)%+d = %d [%s]%s", dc, roll.Rolls[0], mod, roll.Total, res, crit)
-	if label != "" {
-		msg = label + " — " + msg
-	}
-	tr.state().AppendLog(domain.LogEntry{Type: domain.LogRoll, Message: msg})
-	tr.session.MarkModified()
-	return okResult(id, msg)
-}
-
 // --- helpers -------------------------------------------------------------
 
 func okResult(id, content string) types.ToolResult {
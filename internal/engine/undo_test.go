@@ -0,0 +1,80 @@
+package engine
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/theburrowhub/thaimaturgy/internal/providers"
+)
+
+// moveFake moves the party to the hall and meets the guard, then narrates.
+type moveFake struct{ fakeProvider }
+
+func (f *moveFake) Chat(_ context.Context, req providers.ChatRequest) (*providers.ChatResponse, error) {
+	if last := req.Messages[len(req.Messages)-1]; last.Role == providers.RoleTool {
+		return &providers.ChatResponse{Content: "You reach the hall.", FinishReason: "stop"}, nil
+	}
+	return &providers.ChatResponse{FinishReason: "tool_calls", ToolCalls: []providers.ToolCallInfo{
+		{ID: "c1", Type: "function", Function: providers.FunctionCall{Name: "list_exits"}},
+		{ID: "c2", Type: "function", Function: providers.FunctionCall{Name: "set_location", Arguments: `{"room_id":"r2"}`}},
+		{ID: "c3", Type: "function", Function: providers.FunctionCall{Name: "mark_npc_met", Arguments: `{"npc_id":"guard"}`}},
+	}}, nil
+}
+func (f *moveFake) ChatStream(ctx context.Context, req providers.ChatRequest, _ providers.StreamFunc) (*providers.ChatResponse, error) {
+	return f.Chat(ctx, req)
+}
+
+func TestUndoOracleTurn(t *testing.T) {
+	s := createTestSession()
+	st := s.State
+	h := NewCommandHandler(s)
+	logLen, convLen := st.LogLen(), st.Conversation.Len()
+
+	if resp := NewOracle(s, &moveFake{}).Ask(context.Background(), "we head north"); resp.Error != nil {
+		t.Fatal(resp.Error)
+	}
+	if _, room := st.Location(); room != "r2" || !st.NPCKnown("guard") {
+		t.Fatalf("turn didn't run its tools: room %q", room)
+	}
+
+	r := h.Execute(ParseCommand("/undo"))
+	if !r.Success || r.Message != "Undid Turn: we head north (set_location, mark_npc_met)" {
+		t.Fatalf("undo = %+v", r)
+	}
+	if _, room := st.Location(); room != "r1" || st.NPCKnown("guard") || st.VisitedRooms["r2"] {
+		t.Errorf("undo left room %q, guard known %v", room, st.NPCKnown("guard"))
+	}
+	if st.Conversation.Len() != convLen {
+		t.Errorf("conversation has %d messages, want %d", st.Conversation.Len(), convLen)
+	}
+	if st.LogLen() != logLen+1 || !strings.HasPrefix(st.RecentLog(1)[0].Message, "Undone: Turn") {
+		t.Errorf("log = %+v", st.RecentLog(3))
+	}
+
+	if r := h.Execute(ParseCommand("/redo")); !r.Success {
+		t.Fatalf("redo = %+v", r)
+	}
+	if _, room := st.Location(); room != "r2" || !st.NPCKnown("guard") || st.Conversation.Len() != convLen+2 {
+		t.Errorf("redo left room %q, %d messages", room, st.Conversation.Len())
+	}
+	if r := h.Execute(ParseCommand("/redo")); r.Success {
+		t.Error("a second redo should have nothing to redo")
+	}
+}
+
+func TestUndoCommand(t *testing.T) {
+	s := createTestSession()
+	h := NewCommandHandler(s)
+	h.Execute(ParseCommand("/status")) // changes nothing, so it isn't undoable
+	h.Execute(ParseCommand("/flag door=true"))
+	h.Execute(ParseCommand("/help"))
+
+	r := h.Execute(ParseCommand("/undo"))
+	if !r.Success || r.Message != "Undid /flag door=true (/flag)" || s.State.Flags["door"] {
+		t.Fatalf("undo = %+v, flags %v", r, s.State.Flags)
+	}
+	if r := h.Execute(ParseCommand("/undo")); r.Success || r.Message != "nothing to undo" {
+		t.Errorf("second undo = %+v", r)
+	}
+}
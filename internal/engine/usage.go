@@ -0,0 +1,110 @@
+package engine
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/theburrowhub/thaimaturgy/internal/domain"
+	"github.com/theburrowhub/thaimaturgy/internal/providers"
+)
+
+// recordUsage adds a model call to the session's usage ledger, under the
+// backend that answered when a fallback chain says which. It returns a
+// warning, once, when the call carries the session past its soft budget; the
+// crossing is also logged for the DM.
+func (o *Oracle) recordUsage(prov providers.Provider, model string, purpose domain.UsagePurpose, chat *providers.ChatResponse) string {
+	if chat == nil {
+		return ""
+	}
+	name := prov.Name()
+	if chat.Provider != "" {
+		name, model = chat.Provider, chat.Model
+	}
+	if model == "" {
+		model = chat.Model
+	}
+	st := o.session.State
+	before := st.TokensUsed()
+	after := st.RecordUsage(domain.UsageRecord{
+		Provider:         name,
+		Model:            model,
+		Purpose:          purpose,
+		PromptTokens:     chat.Usage.PromptTokens,
+		CompletionTokens: chat.Usage.CompletionTokens,
+		TotalTokens:      chat.Usage.TotalTokens,
+		CacheReadTokens:  chat.Usage.CacheReadTokens,
+		CacheWriteTokens: chat.Usage.CacheWriteTokens,
+	})
+	soft := o.session.Config.SessionBudget.Soft
+	if soft <= 0 || before >= soft || after < soft {
+		return ""
+	}
+	msg := fmt.Sprintf("This session has used %d tokens, past its soft budget of %d.", after, soft)
+	st.AppendLog(domain.LogEntry{Type: domain.LogSystem, Message: msg,
+		Data: map[string]any{"tokens": after, "soft_budget": soft}})
+	return msg
+}
+
+// checkBudget refuses a model call once the session has used up its hard
+// token budget.
+func (o *Oracle) checkBudget() error {
+	hard := o.session.Config.SessionBudget.Hard
+	if hard <= 0 {
+		return nil
+	}
+	if used := o.session.State.TokensUsed(); used >= hard {
+		return &domain.TokenBudgetError{Used: used, Hard: hard}
+	}
+	return nil
+}
+
+// UsageReport prices a session's usage ledger against the configured price
+// table and budget.
+func UsageReport(session *domain.Session) domain.UsageReport {
+	cfg := session.Config
+	if cfg == nil {
+		cfg = domain.DefaultConfig()
+	}
+	return session.State.UsageSnapshot().Report(cfg.Pricing, cfg.SessionBudget)
+}
+
+// FormatUsage renders a usage report for /usage: one line per purpose,
+// provider and model, then the totals and the budget.
+func FormatUsage(rep domain.UsageReport) string {
+	var sb strings.Builder
+	sb.WriteString("=== USAGE ===\n")
+	if len(rep.Lines) == 0 {
+		sb.WriteString("No model calls yet.\n")
+	}
+	for _, l := range rep.Lines {
+		model := l.Model
+		if model == "" {
+			model = "(default model)"
+		}
+		fmt.Fprintf(&sb, "%-14s %s %s: %d call(s), %d tokens (%d in / %d out",
+			l.Purpose, l.Provider, model, l.Calls, l.TotalTokens, l.PromptTokens, l.CompletionTokens)
+		if l.CacheReadTokens > 0 || l.CacheWriteTokens > 0 {
+			fmt.Fprintf(&sb, " / %d cache read / %d cache write", l.CacheReadTokens, l.CacheWriteTokens)
+		}
+		sb.WriteString(")")
+		if l.Priced {
+			fmt.Fprintf(&sb, " ≈ $%.4f", l.CostUSD)
+		} else {
+			sb.WriteString(" (no price)")
+		}
+		sb.WriteString("\n")
+	}
+	fmt.Fprintf(&sb, "Total: %d tokens ≈ $%.4f\n", rep.TotalTokens, rep.CostUSD)
+	if b := rep.Budget; b.Soft > 0 || b.Hard > 0 {
+		var parts []string
+		if b.Soft > 0 {
+			parts = append(parts, fmt.Sprintf("soft %d", b.Soft))
+		}
+		if b.Hard > 0 {
+			parts = append(parts, fmt.Sprintf("hard %d (%d%% used)", b.Hard, rep.TotalTokens*100/b.Hard))
+		}
+		sb.WriteString("Budget: " + strings.Join(parts, ", ") + "\n")
+	}
+	sb.WriteString("Costs are estimates from the price table in config.yaml.")
+	return sb.String()
+}
@@ -0,0 +1,130 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/theburrowhub/thaimaturgy/internal/domain"
+	"github.com/theburrowhub/thaimaturgy/internal/providers"
+)
+
+// usageFake replies with a fixed token count per call.
+type usageFake struct{ fakeProvider }
+
+func (f *usageFake) Chat(ctx context.Context, req providers.ChatRequest) (*providers.ChatResponse, error) {
+	resp, err := f.fakeProvider.Chat(ctx, req)
+	resp.Usage = providers.Usage{PromptTokens: 900, CompletionTokens: 100, TotalTokens: 1000}
+	return resp, err
+}
+func (f *usageFake) ChatStream(ctx context.Context, req providers.ChatRequest, _ providers.StreamFunc) (*providers.ChatResponse, error) {
+	return f.Chat(ctx, req)
+}
+
+func TestOracleRecordsUsage(t *testing.T) {
+	s := createTestSession()
+	o := NewOracle(s, &usageFake{})
+	if resp := o.Ask(context.Background(), "what is here?"); resp.Error != nil || resp.TokensUsed != 1000 {
+		t.Fatalf("ask = %+v", resp)
+	}
+	u := s.State.UsageSnapshot()
+	if len(u) != 1 || u[0].Purpose != domain.PurposeOracle || u[0].Provider != "fake" || u[0].Model != s.Config.Model || u[0].TotalTokens != 1000 {
+		t.Errorf("ledger = %+v", u)
+	}
+
+	res := NewCommandHandler(s).Execute(ParseCommand("/usage"))
+	for _, want := range []string{"oracle", "fake " + s.Config.Model, "1 call(s), 1000 tokens (900 in / 100 out)", "Total: 1000 tokens"} {
+		if !strings.Contains(res.Response, want) {
+			t.Errorf("/usage missing %q:\n%s", want, res.Response)
+		}
+	}
+}
+
+func TestOracleEnforcesTokenBudget(t *testing.T) {
+	s := createTestSession()
+	s.Config.SessionBudget = domain.TokenBudget{Soft: 1500, Hard: 2000}
+	o := NewOracle(s, &usageFake{})
+
+	if resp := o.Ask(context.Background(), "one"); resp.Error != nil || resp.Warning != "" {
+		t.Fatalf("first turn = %+v", resp)
+	}
+	resp := o.Ask(context.Background(), "two")
+	if resp.Error != nil || !strings.Contains(resp.Warning, "soft budget of 1500") {
+		t.Fatalf("crossing the soft budget should warn: %+v", resp)
+	}
+	if e := lastLog(s); e.Type != domain.LogSystem || !strings.Contains(e.Message, "soft budget") {
+		t.Errorf("soft budget log = %+v", e)
+	}
+
+	convo := len(s.State.Conversation.Messages)
+	resp = o.Ask(context.Background(), "three")
+	var be *domain.TokenBudgetError
+	if !errors.As(resp.Error, &be) || be.Used != 2000 || be.Hard != 2000 {
+		t.Fatalf("hard budget should refuse the turn: %+v", resp)
+	}
+	if len(s.State.Conversation.Messages) != convo || s.State.TokensUsed() != 2000 {
+		t.Error("a refused turn must not reach the model or the conversation")
+	}
+	if res := NewCommandHandler(s).Execute(ParseCommand("/usage")); !strings.Contains(res.Response, "Budget: soft 1500, hard 2000 (100% used)") {
+		t.Errorf("/usage budget line:\n%s", res.Response)
+	}
+}
+
+// TestStablePromptIsCachedAheadOfTheSituation verifies the oracle sends the
+// instructions and adventure overview as a cacheable system message that play
+// doesn't change, with the current situation in a separate one after it.
+func TestStablePromptIsCachedAheadOfTheSituation(t *testing.T) {
+	s := createTestSession()
+	o := NewOracle(s, &usageFake{})
+	msgs := o.buildMessages()
+	if len(msgs) < 2 || msgs[0].Role != providers.RoleSystem || !msgs[0].Cache || msgs[1].Role != providers.RoleSystem || msgs[1].Cache {
+		t.Fatalf("messages = %+v, want a cached system message then an uncached one", msgs)
+	}
+	stable, situation := msgs[0].Content, msgs[1].Content
+	if !strings.Contains(stable, "=== ADVENTURE ===") || strings.Contains(stable, "CURRENT SITUATION") {
+		t.Errorf("stable prefix should hold the overview and nothing volatile:\n%s", stable)
+	}
+	if !strings.HasPrefix(situation, "=== CURRENT SITUATION ===") {
+		t.Errorf("situation = %q", situation)
+	}
+
+	s.State.AppendLog(domain.LogEntry{Type: domain.LogSystem, Message: "The torch gutters."})
+	if next := o.buildMessages(); next[0].Content != stable || !strings.Contains(next[1].Content, "The torch gutters.") {
+		t.Error("play should change only the situation, never the cached prefix")
+	}
+	if o.buildSystemPrompt() != o.stablePrompt()+o.situationPrompt() {
+		t.Error("the single-string prompt should be the two parts joined")
+	}
+}
+
+// outageFake is a provider in the middle of an outage.
+type outageFake struct{ fakeProvider }
+
+func (f *outageFake) Name() string { return "down" }
+func (f *outageFake) Chat(context.Context, providers.ChatRequest) (*providers.ChatResponse, error) {
+	return nil, errors.New("unexpected status code: 503 (body: upstream unavailable)")
+}
+func (f *outageFake) ChatStream(ctx context.Context, req providers.ChatRequest, _ providers.StreamFunc) (*providers.ChatResponse, error) {
+	return f.Chat(ctx, req)
+}
+
+// TestOracleReportsFallbackBackend verifies a turn answered by a fallback says
+// so, and its usage is booked to the backend that answered.
+func TestOracleReportsFallbackBackend(t *testing.T) {
+	s := createTestSession()
+	chain := providers.NewChain([]providers.Backend{
+		{Provider: &outageFake{}, KeepModel: true},
+		{Provider: &usageFake{}, Model: "backup-model"},
+	}, 0)
+	resp := NewOracle(s, chain).Ask(context.Background(), "what is here?")
+	if resp.Error != nil || resp.Answer == "" {
+		t.Fatalf("ask = %+v", resp)
+	}
+	if resp.Provider != "fake" || resp.Model != "backup-model" || !resp.Fallback {
+		t.Errorf("backend = %q %q fallback=%v", resp.Provider, resp.Model, resp.Fallback)
+	}
+	if u := s.State.UsageSnapshot(); len(u) != 1 || u[0].Provider != "fake" || u[0].Model != "backup-model" {
+		t.Errorf("ledger = %+v", u)
+	}
+}
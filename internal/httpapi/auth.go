@@ -29,8 +29,9 @@ import (
 //     applies and the caller is treated as a local admin (unchanged local-dev
 //     behavior).
 //
-// The resolved user is attached to the request context; authorization by role
-// (restricting a player to their own characters) is layered on top separately.
+// The resolved user is attached to the request context. A player account is
+// then restricted to the player portal (playerRoute), where it sees and edits
+// only its own characters.
 
 const (
 	sessionTTL = 30 * 24 * time.Hour
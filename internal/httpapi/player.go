@@ -0,0 +1,97 @@
+package httpapi
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/theburrowhub/thaimaturgy/internal/appservice"
+)
+
+// The player portal. A player account (domain.RolePlayer) may only reach the
+// routes below, plus login/logout/whoami and the health and version probes;
+// withAuth turns it away from everything else with 403. The portal shows the
+// players' side of the sessions the player's roster characters are in (see
+// engine.PlayerView) and lets them make the same edits to their own sheets
+// that the chat bot's /hp, /gold, /item… allow.
+
+// playerRoute reports whether a player account may call path.
+func playerRoute(path string) bool {
+	switch path {
+	case "/api/logout", "/api/whoami", "/api/health", "/api/version":
+		return true
+	}
+	return strings.HasPrefix(path, "/api/player/")
+}
+
+// playerError maps a player-portal error to its response.
+func playerError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, appservice.ErrNotYourSession):
+		httpError(w, http.StatusNotFound, err.Error())
+	case errors.Is(err, appservice.ErrNotYourCharacter):
+		httpError(w, http.StatusForbidden, err.Error())
+	case errors.Is(err, appservice.ErrSessionHosted):
+		httpError(w, http.StatusConflict, "this session is being played in a chat — play it there")
+	case errors.Is(err, appservice.ErrDMBusy), errors.Is(err, appservice.ErrNotStarted), errors.Is(err, appservice.ErrNotVirtualDM):
+		httpError(w, http.StatusConflict, err.Error())
+	default:
+		httpError(w, http.StatusBadRequest, err.Error())
+	}
+}
+
+func (s *Server) playerSessions(w http.ResponseWriter, r *http.Request) {
+	u, _ := UserFromContext(r.Context())
+	writeJSON(w, http.StatusOK, s.svc.PlayerSessions(u))
+}
+
+func (s *Server) playerView(w http.ResponseWriter, r *http.Request) {
+	u, _ := UserFromContext(r.Context())
+	v, err := s.svc.PlayerView(r.PathValue("name"), u)
+	if err != nil {
+		playerError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, v)
+}
+
+// playerSheetEdit applies one sheet edit to one of the player's characters: edit
+// is the edit's name ("hp", "gold", "item"…) and arg its argument, as typed after
+// the chat command ("-5", "add Rope x2").
+func (s *Server) playerSheetEdit(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		Character string `json:"character"`
+		Edit      string `json:"edit"`
+		Arg       string `json:"arg"`
+	}
+	if !readJSON(w, r, &body) {
+		return
+	}
+	u, _ := UserFromContext(r.Context())
+	name, desc, err := s.svc.EditPlayerSheet(r.PathValue("name"), u, body.Character, body.Edit, body.Arg)
+	if err != nil {
+		playerError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]string{"character": name, "change": desc})
+}
+
+func (s *Server) playerMap(w http.ResponseWriter, r *http.Request) {
+	u, _ := UserFromContext(r.Context())
+	abs, err := s.svc.PlayerMap(r.PathValue("name"), u)
+	if err != nil {
+		httpError(w, http.StatusNotFound, err.Error())
+		return
+	}
+	http.ServeFile(w, r, abs)
+}
+
+func (s *Server) playerPortrait(w http.ResponseWriter, r *http.Request) {
+	u, _ := UserFromContext(r.Context())
+	abs, err := s.svc.PlayerPortrait(r.PathValue("name"), u, r.PathValue("npc"))
+	if err != nil {
+		httpError(w, http.StatusNotFound, err.Error())
+		return
+	}
+	http.ServeFile(w, r, abs)
+}
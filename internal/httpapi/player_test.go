@@ -0,0 +1,260 @@
+package httpapi
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/theburrowhub/thaimaturgy/internal/appservice"
+	"github.com/theburrowhub/thaimaturgy/internal/domain"
+	"github.com/theburrowhub/thaimaturgy/internal/providers"
+	"github.com/theburrowhub/thaimaturgy/internal/storage"
+)
+
+// playerServer builds a server with an open session of a module full of DM-only
+// content, whose party has one character linked to player "aria" and one that
+// isn't. It returns the server, aria's session token and the session name.
+func playerServer(t *testing.T) (ts *httptest.Server, token, name string) {
+	t.Helper()
+	return playerServerWith(t, nil)
+}
+
+// playerServerWith is playerServer with the DM answered by provider.
+func playerServerWith(t *testing.T, provider providers.Provider) (ts *httptest.Server, token, name string) {
+	t.Helper()
+	store, err := storage.NewWithPath(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	adv := &domain.Adventure{
+		SchemaVersion: domain.SchemaVersion, ID: "keep", Title: "The Keep",
+		Zones: []domain.Zone{{ID: "z", Name: "Courtyard", MapImage: "assets/map.png",
+			Rooms: []domain.Room{{ID: "gate", Name: "Gatehouse", ReadAloud: "A portcullis looms.",
+				DMNotes: "The winch is rigged to snap."}}}},
+		NPCs: []domain.NPC{
+			{ID: "warden", Name: "Warden Hale", Appearance: "Scarred and tall.", Image: "assets/map.png",
+				Secrets: "Sold the keys to the cult.", StatBlock: &domain.StatBlock{AC: 17}},
+			{ID: "cultist", Name: "Hooded Figure", Appearance: "Robed in red."},
+		},
+	}
+	dir := store.AdventureDir("keep")
+	if err := os.MkdirAll(filepath.Join(dir, "assets"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	data, _ := json.Marshal(adv)
+	if err := os.WriteFile(filepath.Join(dir, storage.AdventureFile), data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "assets", "map.png"), []byte("\x89PNG\r\n\x1a\nfake"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	svc := appservice.New(store, domain.DefaultConfig(), provider)
+	mine := domain.NewCharacter("Kael", "Elf", "Wizard")
+	mine.MaxHP, mine.CurrentHP = 10, 10
+	id, err := svc.SaveCharacter(mine)
+	if err != nil {
+		t.Fatal(err)
+	}
+	mine.ID = id
+	u, err := svc.CreateUser("aria", domain.RolePlayer, "pw123")
+	if err != nil {
+		t.Fatal(err)
+	}
+	u.AssignCharacter(id)
+	if err := svc.SaveUser(u); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := svc.CreateUser("bram", domain.RolePlayer, "pw456"); err != nil {
+		t.Fatal(err)
+	}
+	if name, err = svc.NewSession("keep"); err != nil {
+		t.Fatal(err)
+	}
+	if err := svc.SetParty(name, []*domain.Character{mine, domain.NewCharacter("Bryn", "Dwarf", "Cleric")}); err != nil {
+		t.Fatal(err)
+	}
+	open, _ := svc.Get(name)
+	open.Session.State.SetLocation("z", "gate", "Gatehouse")
+	open.Session.State.MeetNPC("warden", "Warden Hale")
+
+	ts = httptest.NewServer(New(svc, "master").Handler())
+	t.Cleanup(ts.Close)
+	return ts, login(t, ts, "aria", "pw123"), name
+}
+
+func login(t *testing.T, ts *httptest.Server, username, password string) string {
+	t.Helper()
+	_, out := req(t, "POST", ts.URL+"/api/login", "", `{"username":"`+username+`","password":"`+password+`"}`)
+	tok, _ := out["token"].(string)
+	if tok == "" {
+		t.Fatalf("login %s: %v", username, out)
+	}
+	return tok
+}
+
+// getAs GETs url with a bearer token and returns the status and body.
+func getAs(t *testing.T, url, bearer string) (int, string) {
+	t.Helper()
+	r, _ := http.NewRequest("GET", url, nil)
+	r.Header.Set("Authorization", "Bearer "+bearer)
+	resp, err := http.DefaultClient.Do(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	b, _ := io.ReadAll(resp.Body)
+	return resp.StatusCode, string(b)
+}
+
+func TestPlayerIsConfinedToThePortal(t *testing.T) {
+	ts, tok, name := playerServer(t)
+	for _, path := range []string{"/api/sessions", "/api/sessions/" + name, "/api/adventures/keep", "/api/roster"} {
+		if code, _ := getAs(t, ts.URL+path, tok); code != http.StatusForbidden {
+			t.Errorf("GET %s as a player = %d; want 403", path, code)
+		}
+	}
+	if resp, _ := req(t, "POST", ts.URL+"/api/sse-ticket", tok, ""); resp.StatusCode != http.StatusForbidden {
+		t.Errorf("a player minted an events ticket: %d", resp.StatusCode)
+	}
+	if resp, _ := req(t, "POST", ts.URL+"/api/sessions/"+name+"/command", tok, `{"input":"/flag x=true"}`); resp.StatusCode != http.StatusForbidden {
+		t.Errorf("a player ran a DM command: %d", resp.StatusCode)
+	}
+	if code, _ := getAs(t, ts.URL+"/api/whoami", tok); code != http.StatusOK {
+		t.Errorf("whoami as a player = %d", code)
+	}
+	// The master token still reaches everything.
+	if code, _ := getAs(t, ts.URL+"/api/sessions", "master"); code != http.StatusOK {
+		t.Errorf("admin GET /api/sessions = %d", code)
+	}
+}
+
+func TestPlayerView(t *testing.T) {
+	ts, tok, name := playerServer(t)
+	base := ts.URL + "/api/player/sessions/" + name
+
+	code, body := getAs(t, ts.URL+"/api/player/sessions", tok)
+	if code != http.StatusOK || !strings.Contains(body, `"characters":["Kael"]`) {
+		t.Fatalf("sessions = %d %s", code, body)
+	}
+
+	code, body = getAs(t, base, tok)
+	if code != http.StatusOK {
+		t.Fatalf("view = %d %s", code, body)
+	}
+	for _, want := range []string{"A portcullis looms.", "Scarred and tall.", `"name":"Kael"`} {
+		if !strings.Contains(body, want) {
+			t.Errorf("view lacks %q:\n%s", want, body)
+		}
+	}
+	for _, secret := range []string{"winch", "cult", "stat_block", "Hooded Figure", "Robed", "Bryn"} {
+		if strings.Contains(body, secret) {
+			t.Errorf("view leaks %q:\n%s", secret, body)
+		}
+	}
+
+	if code, _ := getAs(t, base+"/map", tok); code != http.StatusOK {
+		t.Errorf("map = %d", code)
+	}
+	if code, _ := getAs(t, base+"/portraits/warden", tok); code != http.StatusOK {
+		t.Errorf("met NPC's portrait = %d", code)
+	}
+	if code, _ := getAs(t, base+"/portraits/cultist", tok); code != http.StatusNotFound {
+		t.Errorf("unmet NPC's portrait = %d; want 404", code)
+	}
+
+	// A player with no character in the session doesn't see it.
+	other := login(t, ts, "bram", "pw456")
+	if code, _ := getAs(t, base, other); code != http.StatusNotFound {
+		t.Errorf("someone else's session = %d; want 404", code)
+	}
+}
+
+func TestPlayerSheetEdit(t *testing.T) {
+	ts, tok, name := playerServer(t)
+	url := ts.URL + "/api/player/sessions/" + name + "/sheet"
+
+	resp, out := req(t, "POST", url, tok, `{"character":"kael","edit":"hp","arg":"-3"}`)
+	if resp.StatusCode != http.StatusOK || out["character"] != "Kael" || out["change"] != "took 3 damage → 7/10 HP" {
+		t.Fatalf("hp edit = %d %v", resp.StatusCode, out)
+	}
+	if resp, _ := req(t, "POST", url, tok, `{"character":"Bryn","edit":"hp","arg":"-3"}`); resp.StatusCode != http.StatusForbidden {
+		t.Errorf("editing someone else's character = %d; want 403", resp.StatusCode)
+	}
+	if resp, out := req(t, "POST", url, tok, `{"character":"Kael","edit":"gold","arg":"lots"}`); resp.StatusCode != http.StatusBadRequest || !strings.HasPrefix(out["error"].(string), "Usage:") {
+		t.Errorf("a malformed edit = %d %v", resp.StatusCode, out)
+	}
+	if resp, _ := req(t, "POST", url, tok, `{"character":"Kael","edit":"maxhp","arg":"=99"}`); resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("an edit Telegram doesn't allow = %d", resp.StatusCode)
+	}
+}
+
+func TestPlayerRoundLoop(t *testing.T) {
+	ts, tok, name := playerServerWith(t, streamingProvider{})
+	base := ts.URL + "/api/player/sessions/" + name
+	host := ts.URL + "/api/sessions/" + name
+
+	if resp, out := req(t, "POST", base+"/claim", tok, `{"character":"kael"}`); resp.StatusCode != http.StatusConflict {
+		t.Fatalf("claim outside virtual-DM play = %d %v; want 409", resp.StatusCode, out)
+	}
+	if resp, _ := req(t, "POST", host+"/command", "master", `{"input":"/mode dm"}`); resp.StatusCode != http.StatusOK {
+		t.Fatalf("/mode dm = %d", resp.StatusCode)
+	}
+	if resp, _ := req(t, "POST", base+"/claim", tok, `{"character":"Bryn"}`); resp.StatusCode != http.StatusForbidden {
+		t.Errorf("claiming someone else's character = %d; want 403", resp.StatusCode)
+	}
+	if resp, out := req(t, "POST", base+"/claim", tok, `{"character":"kael"}`); resp.StatusCode != http.StatusOK || out["character"] != "Kael" {
+		t.Fatalf("claim = %d %v", resp.StatusCode, out)
+	}
+	if resp, _ := req(t, "POST", host+"/round/begin", tok, ""); resp.StatusCode != http.StatusForbidden {
+		t.Errorf("a player began the game: %d", resp.StatusCode)
+	}
+	if resp, out := req(t, "POST", host+"/round/begin", "master", ""); resp.StatusCode != http.StatusOK || out["answer"] != "The gate groans open." {
+		t.Fatalf("begin = %d %v", resp.StatusCode, out)
+	}
+
+	r, _ := http.NewRequest("GET", base+"/table", nil)
+	r.Header.Set("Authorization", "Bearer "+tok)
+	stream, err := http.DefaultClient.Do(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer stream.Body.Close()
+	lines := bufio.NewScanner(stream.Body)
+	next := func(event string) string {
+		t.Helper()
+		for lines.Scan() {
+			if lines.Text() == "event: "+event && lines.Scan() {
+				return strings.TrimPrefix(lines.Text(), "data: ")
+			}
+		}
+		t.Fatalf("stream ended before a %s event", event)
+		return ""
+	}
+	if data := next("round"); !strings.Contains(data, `"started":true`) || !strings.Contains(data, `"active":"Kael"`) {
+		t.Errorf("first round event = %s", data)
+	}
+
+	if resp, out := req(t, "POST", base+"/do", tok, `{"text":"I pull the lever"}`); resp.StatusCode != http.StatusOK || out["character"] != "Kael" {
+		t.Fatalf("do = %d %v", resp.StatusCode, out)
+	}
+	if data := next("round"); !strings.Contains(data, "I pull the lever") {
+		t.Errorf("round after acting = %s", data)
+	}
+	_, out := req(t, "GET", host+"/round", "master", "")
+	if acts, _ := out["actions"].([]any); len(acts) != 1 {
+		t.Errorf("host round = %v", out)
+	}
+	if resp, out := req(t, "POST", host+"/round/resolve", "master", ""); resp.StatusCode != http.StatusOK || out["answer"] != "The gate groans open." {
+		t.Fatalf("resolve = %d %v", resp.StatusCode, out)
+	}
+	if data := next("narration"); !strings.Contains(data, `"text":"The gate groans open."`) {
+		t.Errorf("narration = %s", data)
+	}
+}
@@ -0,0 +1,183 @@
+package httpapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+)
+
+// The multiplayer round loop (appservice.Service.ClaimCharacter … ResolveRound):
+// players play from the player portal, and the host begins the game and has
+// the DM resolve each round from the session's round routes. The players
+// follow the table live on /api/player/sessions/{name}/table, a Server-Sent
+// Events stream read with the player's bearer token (it isn't an /events route,
+// so it goes through withAuth like the rest of the portal).
+
+// roundBody is a player's request: the character it's for (empty for the
+// active one, except when claiming) and the text of an action, line or
+// question.
+type roundBody struct {
+	Character string `json:"character"`
+	Text      string `json:"text"`
+}
+
+func (s *Server) playerRound(w http.ResponseWriter, r *http.Request) {
+	u, _ := UserFromContext(r.Context())
+	rs, err := s.svc.PlayerRound(r.PathValue("name"), u)
+	if err != nil {
+		playerError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, rs)
+}
+
+func (s *Server) playerClaim(w http.ResponseWriter, r *http.Request) {
+	var body roundBody
+	if !readJSON(w, r, &body) {
+		return
+	}
+	u, _ := UserFromContext(r.Context())
+	name, err := s.svc.ClaimCharacter(r.PathValue("name"), u, body.Character)
+	if err != nil {
+		playerError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]string{"character": name})
+}
+
+func (s *Server) playerActive(w http.ResponseWriter, r *http.Request) {
+	var body roundBody
+	if !readJSON(w, r, &body) {
+		return
+	}
+	u, _ := UserFromContext(r.Context())
+	name, err := s.svc.SetActiveCharacter(r.PathValue("name"), u, body.Character)
+	if err != nil {
+		playerError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]string{"character": name})
+}
+
+func (s *Server) playerDo(w http.ResponseWriter, r *http.Request) {
+	var body roundBody
+	if !readJSON(w, r, &body) {
+		return
+	}
+	u, _ := UserFromContext(r.Context())
+	act, err := s.svc.SubmitAction(r.PathValue("name"), u, body.Character, body.Text)
+	if err != nil {
+		playerError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, act)
+}
+
+func (s *Server) playerChat(w http.ResponseWriter, r *http.Request) {
+	var body roundBody
+	if !readJSON(w, r, &body) {
+		return
+	}
+	u, _ := UserFromContext(r.Context())
+	speaker, err := s.svc.Chat(r.PathValue("name"), u, body.Character, body.Text)
+	if err != nil {
+		playerError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]string{"character": speaker, "text": body.Text})
+}
+
+// playerMeta answers a player's out-of-character question. The answer reaches
+// the whole table on its stream too. A failed turn's details stay in the
+// server log, as they do on Telegram.
+func (s *Server) playerMeta(w http.ResponseWriter, r *http.Request) {
+	var body roundBody
+	if !readJSON(w, r, &body) {
+		return
+	}
+	u, _ := UserFromContext(r.Context())
+	resp, err := s.svc.Meta(r.Context(), r.PathValue("name"), u, body.Text)
+	if err != nil {
+		playerError(w, err)
+		return
+	}
+	if resp.Error != nil {
+		log.Printf("httpapi: meta in %q: %v", r.PathValue("name"), resp.Error)
+		httpError(w, http.StatusBadGateway, "the DM couldn't answer right now — try again in a moment")
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]string{"answer": resp.Answer})
+}
+
+// playerTable streams the table to a player: the round as it fills, the DM's
+// narration, in-character lines, and "view" when the player view should be
+// refetched. Each event's type is the TableEvent's; a closed event ends the
+// stream when the session closes. A reconnecting player refetches the view and
+// gets the round afresh; narration missed meanwhile is in the recap.
+func (s *Server) playerTable(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		httpError(w, http.StatusInternalServerError, "streaming unsupported")
+		return
+	}
+	u, _ := UserFromContext(r.Context())
+	watch, err := s.svc.WatchTable(r.PathValue("name"), u)
+	if err != nil {
+		playerError(w, err)
+		return
+	}
+	defer watch.Close()
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+
+	heartbeat := time.NewTicker(sseHeartbeat)
+	defer heartbeat.Stop()
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case e, ok := <-watch.C:
+			if !ok {
+				return
+			}
+			data, _ := json.Marshal(e.Data)
+			fmt.Fprintf(w, "event: %s\ndata: %s\n\n", e.Type, data)
+			flusher.Flush()
+		case <-heartbeat.C:
+			fmt.Fprint(w, ": heartbeat\n\n")
+			flusher.Flush()
+		}
+	}
+}
+
+func (s *Server) getRound(w http.ResponseWriter, r *http.Request) {
+	rs, err := s.svc.Round(r.PathValue("name"))
+	if err != nil {
+		httpError(w, http.StatusNotFound, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, rs)
+}
+
+// beginGame has the DM set the opening scene for the players seated.
+func (s *Server) beginGame(w http.ResponseWriter, r *http.Request) {
+	resp, err := s.svc.BeginGame(r.Context(), r.PathValue("name"))
+	if err != nil {
+		httpError(w, http.StatusConflict, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, oracleResult(resp))
+}
+
+// resolveRound has the DM resolve the actions declared this round.
+func (s *Server) resolveRound(w http.ResponseWriter, r *http.Request) {
+	resp, err := s.svc.ResolveRound(r.Context(), r.PathValue("name"))
+	if err != nil {
+		httpError(w, http.StatusConflict, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, oracleResult(resp))
+}
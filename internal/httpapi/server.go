@@ -6,8 +6,8 @@
 //
 // SSE (not WebSocket) is used for server→client push: it needs no third-party
 // dependency, and client→server actions travel over REST, which covers the
-// current one-directional streaming need. It can be upgraded to WebSocket later
-// if bidirectional streaming (e.g. token-level oracle output) is added.
+// current one-directional streaming need. Token-level oracle output streams the
+// same way, as SSE on the response to POST /api/sessions/{name}/oracle.
 package httpapi
 
 import (
@@ -28,6 +28,7 @@ import (
 	"os"
 	"path"
 	"path/filepath"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
@@ -36,6 +37,8 @@ import (
 	"github.com/theburrowhub/thaimaturgy/internal/bookpdf"
 	"github.com/theburrowhub/thaimaturgy/internal/buildinfo"
 	"github.com/theburrowhub/thaimaturgy/internal/domain"
+	"github.com/theburrowhub/thaimaturgy/internal/engine"
+	"github.com/theburrowhub/thaimaturgy/internal/storage"
 )
 
 // webFS holds the embedded single-page web UI (issue #36, Phase C), so the server
@@ -127,6 +130,20 @@ func (s *Server) Handler() http.Handler {
 	mux.HandleFunc("POST /api/login", s.handleLogin)
 	mux.HandleFunc("POST /api/logout", s.handleLogout)
 	mux.HandleFunc("GET /api/whoami", s.handleWhoami)
+	// The player portal: the only routes a player account may call (see
+	// playerRoute).
+	mux.HandleFunc("GET /api/player/sessions", s.playerSessions)
+	mux.HandleFunc("GET /api/player/sessions/{name}", s.playerView)
+	mux.HandleFunc("POST /api/player/sessions/{name}/sheet", s.playerSheetEdit)
+	mux.HandleFunc("GET /api/player/sessions/{name}/map", s.playerMap)
+	mux.HandleFunc("GET /api/player/sessions/{name}/portraits/{npc}", s.playerPortrait)
+	mux.HandleFunc("GET /api/player/sessions/{name}/round", s.playerRound)
+	mux.HandleFunc("POST /api/player/sessions/{name}/claim", s.playerClaim)
+	mux.HandleFunc("POST /api/player/sessions/{name}/active", s.playerActive)
+	mux.HandleFunc("POST /api/player/sessions/{name}/do", s.playerDo)
+	mux.HandleFunc("POST /api/player/sessions/{name}/chat", s.playerChat)
+	mux.HandleFunc("POST /api/player/sessions/{name}/meta", s.playerMeta)
+	mux.HandleFunc("GET /api/player/sessions/{name}/table", s.playerTable)
 
 	mux.HandleFunc("GET /api/adventures", s.listAdventures)
 	mux.HandleFunc("POST /api/adventures/import", s.importAdventure)
@@ -139,6 +156,7 @@ func (s *Server) Handler() http.Handler {
 	mux.HandleFunc("GET /api/adventures/{id}/export", s.exportAdventure)
 	mux.HandleFunc("GET /api/adventures/{id}/dmbook", s.dmbookAdventure)
 	mux.HandleFunc("GET /api/adventures/{id}/asset", s.adventureAsset)
+	mux.HandleFunc("GET /api/adventures/{id}/search", s.searchAdventure)
 	mux.HandleFunc("GET /api/sessions", s.listSessions)
 	mux.HandleFunc("POST /api/sessions", s.newSession)
 	mux.HandleFunc("GET /api/sessions/{name}", s.getSession)
@@ -148,6 +166,16 @@ func (s *Server) Handler() http.Handler {
 	mux.HandleFunc("DELETE /api/sessions/{name}", s.deleteSession)
 	mux.HandleFunc("POST /api/sessions/{name}/command", s.command)
 	mux.HandleFunc("POST /api/sessions/{name}/oracle", s.oracle)
+	mux.HandleFunc("POST /api/sessions/{name}/undo", s.undo)
+	mux.HandleFunc("POST /api/sessions/{name}/redo", s.redo)
+	mux.HandleFunc("GET /api/sessions/{name}/snapshots", s.listSnapshots)
+	mux.HandleFunc("POST /api/sessions/{name}/snapshots", s.takeSnapshot)
+	mux.HandleFunc("DELETE /api/sessions/{name}/snapshots/{id}", s.deleteSnapshot)
+	mux.HandleFunc("POST /api/sessions/{name}/snapshots/{id}/branch", s.branchSnapshot)
+	mux.HandleFunc("GET /api/sessions/{name}/usage", s.sessionUsage)
+	mux.HandleFunc("GET /api/sessions/{name}/round", s.getRound)
+	mux.HandleFunc("POST /api/sessions/{name}/round/begin", s.beginGame)
+	mux.HandleFunc("POST /api/sessions/{name}/round/resolve", s.resolveRound)
 	mux.HandleFunc("GET /api/sessions/{name}/telegram", s.telegramStatus)
 	mux.HandleFunc("POST /api/sessions/{name}/telegram/start", s.startTelegramHost)
 	mux.HandleFunc("POST /api/sessions/{name}/telegram/stop", s.stopTelegramHost)
@@ -166,6 +194,7 @@ func (s *Server) Handler() http.Handler {
 	mux.HandleFunc("GET /api/novel-jobs/{id}/download", s.downloadNovel)
 	mux.HandleFunc("GET /api/novel-jobs/{id}/result", s.novelJobResult)
 	mux.HandleFunc("GET /api/sessions/{name}/events", s.sessionEvents)
+	mux.HandleFunc("GET /api/sessions/{name}/ws", s.sessionSocket)
 	mux.HandleFunc("POST /api/sse-ticket", s.sseTicket)
 	mux.HandleFunc("GET /api/chargen/options", s.chargenOptions)
 	mux.HandleFunc("POST /api/chargen", s.chargen)
@@ -185,7 +214,7 @@ func (s *Server) Handler() http.Handler {
 // instead. When NO token is configured the server is loopback-only, so instead we
 // apply an anti-CSRF / anti-DNS-rebinding guard: the Host must be a loopback name
 // and any Origin must be same-origin, blocking a malicious web page from driving
-// the local API.
+// the local API. A player account is confined to the player portal.
 func (s *Server) withAuth(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		if !strings.HasPrefix(r.URL.Path, "/api") {
@@ -193,9 +222,9 @@ func (s *Server) withAuth(next http.Handler) http.Handler {
 			return
 		}
 		// Login must be reachable without prior auth (it's how you get a token). The
-		// SSE stream authenticates with a single-use ticket in the query string, not
-		// a header, so it's exempt from the bearer gate here.
-		if r.URL.Path == "/api/login" || strings.HasSuffix(r.URL.Path, "/events") {
+		// SSE stream and the WebSocket authenticate with a ticket in the query
+		// string, not a header, so they're exempt from the bearer gate here.
+		if r.URL.Path == "/api/login" || strings.HasSuffix(r.URL.Path, "/events") || strings.HasSuffix(r.URL.Path, "/ws") {
 			next.ServeHTTP(w, r)
 			return
 		}
@@ -215,6 +244,12 @@ func (s *Server) withAuth(next http.Handler) http.Handler {
 				return
 			}
 		}
+		// A player account only gets the player portal: everything else shows or
+		// changes what the DM keeps back.
+		if !user.IsAdmin() && !playerRoute(r.URL.Path) {
+			httpError(w, http.StatusForbidden, "player accounts can only use the player portal")
+			return
+		}
 		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), userCtxKey, user)))
 	})
 }
@@ -401,7 +436,8 @@ func (s *Server) saveAdventure(w http.ResponseWriter, r *http.Request) {
 }
 
 // validateAdventure runs full validation over a candidate adventure and returns
-// the list of problems (empty when valid), so the editor can surface them.
+// the problems that make it invalid ("errors", empty when valid) and the
+// non-blocking "warnings", so the editor can surface both.
 func (s *Server) validateAdventure(w http.ResponseWriter, r *http.Request) {
 	id := r.PathValue("id")
 	if !s.svc.AdventureExists(id) {
@@ -412,7 +448,8 @@ func (s *Server) validateAdventure(w http.ResponseWriter, r *http.Request) {
 	if !readJSONLimited(w, r, &adv, maxAdventureBytes) {
 		return
 	}
-	writeJSON(w, http.StatusOK, map[string]any{"errors": s.svc.ValidateAdventure(id, &adv)})
+	problems, warnings := s.svc.ValidateAdventure(id, &adv)
+	writeJSON(w, http.StatusOK, map[string]any{"errors": problems, "warnings": warnings})
 }
 
 // exportAdventure streams the adventure packaged as a .tar.gz download. A missing
@@ -608,6 +645,31 @@ func (s *Server) getAdventure(w http.ResponseWriter, r *http.Request) {
 	writeJSON(w, http.StatusOK, adv)
 }
 
+// searchAdventure runs a ranked full-text search over an adventure:
+// ?q=<words>[&limit=N] (default 20).
+func (s *Server) searchAdventure(w http.ResponseWriter, r *http.Request) {
+	q := strings.TrimSpace(r.URL.Query().Get("q"))
+	if q == "" {
+		httpError(w, http.StatusBadRequest, "missing query (q)")
+		return
+	}
+	limit := 20
+	if v := r.URL.Query().Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 {
+			httpError(w, http.StatusBadRequest, "limit must be a positive number")
+			return
+		}
+		limit = n
+	}
+	hits, err := s.svc.SearchAdventure(r.PathValue("id"), q, limit)
+	if err != nil {
+		httpError(w, http.StatusNotFound, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"query": q, "hits": hits})
+}
+
 // adventureAsset streams a module image (map/art) by its module-relative path.
 // The path is resolved and bounds-checked inside the adventure directory by
 // AdventureAsset, so path traversal is rejected. It stays under the normal auth
@@ -665,6 +727,15 @@ func (s *Server) getSession(w http.ResponseWriter, r *http.Request) {
 	writeJSON(w, http.StatusOK, os.Session.State)
 }
 
+func (s *Server) sessionUsage(w http.ResponseWriter, r *http.Request) {
+	rep, err := s.svc.Usage(r.PathValue("name"))
+	if err != nil {
+		httpError(w, http.StatusNotFound, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, rep)
+}
+
 func (s *Server) saveSession(w http.ResponseWriter, r *http.Request) {
 	if err := s.svc.SaveSession(r.PathValue("name")); err != nil {
 		httpError(w, http.StatusConflict, err.Error())
@@ -715,13 +786,18 @@ func (s *Server) command(w http.ResponseWriter, r *http.Request) {
 		httpError(w, http.StatusConflict, err.Error())
 		return
 	}
-	writeJSON(w, http.StatusOK, map[string]any{
+	writeJSON(w, http.StatusOK, commandResult(res))
+}
+
+// commandResult is the JSON body of a command's result.
+func commandResult(res *engine.CommandResult) map[string]any {
+	return map[string]any{
 		"success":   res.Success,
 		"message":   res.Message,
 		"response":  res.Response,
 		"ui_action": res.UIAction,
 		"ui_arg":    res.UIArg,
-	})
+	}
 }
 
 func (s *Server) oracle(w http.ResponseWriter, r *http.Request) {
@@ -731,11 +807,21 @@ func (s *Server) oracle(w http.ResponseWriter, r *http.Request) {
 	if !readJSON(w, r, &body) {
 		return
 	}
+	if wantsStream(r) {
+		s.oracleStream(w, r, body.Input)
+		return
+	}
 	resp, err := s.svc.AskOracle(r.Context(), r.PathValue("name"), body.Input)
 	if err != nil {
 		httpError(w, http.StatusConflict, err.Error())
 		return
 	}
+	writeJSON(w, http.StatusOK, oracleResult(resp))
+}
+
+// oracleResult is the JSON body of an oracle turn, and the data of the final
+// "done" event of a streamed one.
+func oracleResult(resp *engine.Response) map[string]any {
 	out := map[string]any{
 		"answer":      resp.Answer,
 		"tokens_used": resp.TokensUsed,
@@ -744,7 +830,149 @@ func (s *Server) oracle(w http.ResponseWriter, r *http.Request) {
 	if resp.Error != nil {
 		out["error"] = resp.Error.Error()
 	}
-	writeJSON(w, http.StatusOK, out)
+	if resp.Warning != "" {
+		out["warning"] = resp.Warning
+	}
+	if resp.Fallback {
+		out["fallback"] = true
+		out["provider"] = resp.Provider
+		out["model"] = resp.Model
+	}
+	return out
+}
+
+// wantsStream reports whether an oracle request asked for the streaming variant:
+// ?stream=1, or an Accept header naming text/event-stream.
+func wantsStream(r *http.Request) bool {
+	if v := r.URL.Query().Get("stream"); v == "1" || v == "true" {
+		return true
+	}
+	return strings.Contains(r.Header.Get("Accept"), "text/event-stream")
+}
+
+// oracleStream runs an oracle turn as Server-Sent Events on the POST response:
+// "token" ({"text"}) for each piece of the reply, "tool" ({"tool","error"}) for
+// each tool the DM runs, "reset" when the text so far was preamble to tool calls
+// and should be discarded, then one "done" carrying what the JSON variant
+// returns. A session that can't take the turn fails with a plain HTTP error
+// before the stream starts.
+func (s *Server) oracleStream(w http.ResponseWriter, r *http.Request, input string) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		httpError(w, http.StatusInternalServerError, "streaming unsupported")
+		return
+	}
+	started := false
+	send := func(event string, data any) {
+		if !started {
+			w.Header().Set("Content-Type", "text/event-stream")
+			w.Header().Set("Cache-Control", "no-cache")
+			w.WriteHeader(http.StatusOK)
+			started = true
+		}
+		b, _ := json.Marshal(data)
+		fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event, b)
+		flusher.Flush()
+	}
+	resp, err := s.svc.AskOracleStream(r.Context(), r.PathValue("name"), input, func(e engine.Event) {
+		send(string(e.Kind), oracleEventData(e))
+	})
+	if err != nil {
+		httpError(w, http.StatusConflict, err.Error())
+		return
+	}
+	send("done", oracleResult(resp))
+}
+
+// oracleEventData is the data of a streamed turn's event: {"text"} for a piece
+// of the reply, {"tool","error"} for a tool call, {} for a reset.
+func oracleEventData(e engine.Event) any {
+	switch e.Kind {
+	case engine.EventTool:
+		return map[string]string{"tool": e.Tool, "error": e.Text}
+	case engine.EventReset:
+		return struct{}{}
+	}
+	return map[string]string{"text": e.Text}
+}
+
+// undo rolls back a session's last oracle turn or command; it works while the
+// session is hosted on Telegram too, as one of the host's controls.
+func (s *Server) undo(w http.ResponseWriter, r *http.Request) {
+	set, err := s.svc.Undo(r.PathValue("name"))
+	writeChangeSet(w, "Undid", set, err)
+}
+
+// redo re-applies what undo rolled back.
+func (s *Server) redo(w http.ResponseWriter, r *http.Request) {
+	set, err := s.svc.Redo(r.PathValue("name"))
+	writeChangeSet(w, "Redid", set, err)
+}
+
+func writeChangeSet(w http.ResponseWriter, verb string, set domain.ChangeSet, err error) {
+	if err != nil {
+		httpError(w, http.StatusConflict, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{
+		"label":   set.Label,
+		"changes": set.Changes,
+		"message": engine.DescribeChangeSet(verb, set),
+	})
+}
+
+// listSnapshots returns a session's named save points, oldest first.
+func (s *Server) listSnapshots(w http.ResponseWriter, r *http.Request) {
+	list, err := s.svc.ListSnapshots(r.PathValue("name"))
+	if err != nil {
+		httpError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if list == nil {
+		list = []storage.SnapshotInfo{}
+	}
+	writeJSON(w, http.StatusOK, list)
+}
+
+// takeSnapshot saves a named save point of a session ({"label": ...}).
+func (s *Server) takeSnapshot(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		Label string `json:"label"`
+	}
+	if !readJSON(w, r, &body) {
+		return
+	}
+	info, err := s.svc.Snapshot(r.PathValue("name"), body.Label)
+	if err != nil {
+		httpError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusCreated, info)
+}
+
+func (s *Server) deleteSnapshot(w http.ResponseWriter, r *http.Request) {
+	if err := s.svc.DeleteSnapshot(r.PathValue("name"), r.PathValue("id")); err != nil {
+		httpError(w, http.StatusNotFound, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]string{"status": "deleted"})
+}
+
+// branchSnapshot forks a new session from a snapshot ({"name": ...}, optional)
+// and returns its name; the original session is left as it is.
+func (s *Server) branchSnapshot(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		Name string `json:"name"`
+	}
+	if !readJSON(w, r, &body) {
+		return
+	}
+	name, err := s.svc.Branch(r.PathValue("name"), r.PathValue("id"), body.Name)
+	if err != nil {
+		httpError(w, http.StatusConflict, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusCreated, map[string]string{"name": name})
 }
 
 // telegramStatus reports whether a session is currently hosted on Telegram.
@@ -960,56 +1188,125 @@ func (s *Server) downloadNovel(w http.ResponseWriter, r *http.Request) {
 	_, _ = w.Write([]byte(md))
 }
 
-// sessionEvents streams new timeline entries for a session as Server-Sent Events,
-// resuming the session if needed. It sends the current log first, then tails for
-// new entries until the client disconnects.
+// sseHeartbeat is how often an idle event stream sends a heartbeat, so proxies
+// don't time the connection out.
+const sseHeartbeat = 15 * time.Second
+
+// streamEvent is one event of a session stream, as the SSE stream and the
+// WebSocket carry it.
+type streamEvent struct {
+	id, event string
+	data      json.RawMessage
+}
+
+// streamSession resumes the session a stream request names, if it isn't open,
+// writing the error response if it can't be.
+func (s *Server) streamSession(w http.ResponseWriter, r *http.Request) (*appservice.OpenSession, bool) {
+	name := r.PathValue("name")
+	if os, ok := s.svc.Get(name); ok {
+		return os, true
+	}
+	os, err := s.svc.ResumeSession(name)
+	if err != nil {
+		httpError(w, http.StatusNotFound, err.Error())
+		return nil, false
+	}
+	return os, true
+}
+
+// openStream subscribes to a session's events for a client that last saw
+// lastID, and returns what the client gets first: the events after lastID if
+// they can be resumed, or else a sync event ({"resync": true} when lastID was
+// given, so the client refetches the state) and the whole timeline as log
+// events. Live events on sub.C up to replayed are covered by those.
+func openStream(os *appservice.OpenSession, lastID string) (sub *appservice.Subscription, first []streamEvent, replayed uint64) {
+	sub, backlog, resumed := os.Subscribe(lastID)
+	if resumed {
+		for _, e := range backlog {
+			first = append(first, streamEvent{e.ID, string(e.Topic), e.Data})
+		}
+		return sub, first, 0
+	}
+	entries, seq := os.Session.State.LogAt()
+	sync, _ := json.Marshal(map[string]bool{"resync": lastID != ""})
+	first = append(first, streamEvent{sub.ID(seq), "sync", sync})
+	for _, e := range entries {
+		if b, err := json.Marshal(e); err == nil {
+			first = append(first, streamEvent{"", string(domain.TopicLog), b})
+		}
+	}
+	return sub, first, seq
+}
+
+// sessionEvents streams a session's state events as Server-Sent Events,
+// resuming the session if needed. Each event's type is its topic (log,
+// location, npc, party, round, world, mode, conversation, rewind) and its id
+// lets the client resume: given the id of the last event it saw (the
+// Last-Event-ID header EventSource sends on reconnect, or ?last_event_id=) the
+// stream carries on from there if the server still has the events after it.
+// Otherwise it starts with a sync event ("resync" true when an id was given but
+// couldn't be resumed, so the client should refetch the state) followed by the
+// whole timeline as log events. A closed event ends the stream when the session
+// is closed.
 func (s *Server) sessionEvents(w http.ResponseWriter, r *http.Request) {
 	if !s.sseAuthorized(r) {
 		httpError(w, http.StatusUnauthorized, "missing or invalid SSE ticket (POST /api/sse-ticket)")
 		return
 	}
-	name := r.PathValue("name")
-	os, ok := s.svc.Get(name)
+	os, ok := s.streamSession(w, r)
 	if !ok {
-		var err error
-		if os, err = s.svc.ResumeSession(name); err != nil {
-			httpError(w, http.StatusNotFound, err.Error())
-			return
-		}
+		return
 	}
 	flusher, ok := w.(http.Flusher)
 	if !ok {
 		httpError(w, http.StatusInternalServerError, "streaming unsupported")
 		return
 	}
+	lastID := r.Header.Get("Last-Event-ID")
+	if lastID == "" {
+		lastID = r.URL.Query().Get("last_event_id")
+	}
+	sub, first, replayed := openStream(os, lastID)
+	defer sub.Close()
 	w.Header().Set("Content-Type", "text/event-stream")
 	w.Header().Set("Cache-Control", "no-cache")
 	w.Header().Set("Connection", "keep-alive")
 
-	st := os.Session.State
-	sent := 0
-	ticker := time.NewTicker(time.Second)
-	defer ticker.Stop()
-	for {
-		entries := st.RecentLog(0) // full timeline (copy, under lock)
-		if len(entries) < sent {
-			sent = 0 // log was trimmed/reset; resync
+	write := func(e streamEvent) {
+		if e.id != "" {
+			fmt.Fprintf(w, "id: %s\n", e.id)
 		}
-		for _, e := range entries[sent:] {
-			if b, err := json.Marshal(e); err == nil {
-				fmt.Fprintf(w, "event: log\ndata: %s\n\n", b)
-			}
-		}
-		if len(entries) > sent {
-			sent = len(entries)
-		} else {
-			fmt.Fprint(w, ": heartbeat\n\n") // keep the connection alive
-		}
-		flusher.Flush()
+		fmt.Fprintf(w, "event: %s\ndata: %s\n\n", e.event, e.data)
+	}
+	for _, e := range first {
+		write(e)
+	}
+	flusher.Flush()
+
+	heartbeat := time.NewTicker(sseHeartbeat)
+	defer heartbeat.Stop()
+	for {
 		select {
 		case <-r.Context().Done():
 			return
-		case <-ticker.C:
+		case e, ok := <-sub.C:
+			if !ok {
+				// Dropped for falling behind (the client reconnects and
+				// resumes) or the session closed.
+				if sub.SessionClosed() {
+					write(streamEvent{event: "closed", data: json.RawMessage("{}")})
+					flusher.Flush()
+				}
+				return
+			}
+			if e.Seq <= replayed {
+				continue
+			}
+			write(streamEvent{e.ID, string(e.Topic), e.Data})
+			flusher.Flush()
+		case <-heartbeat.C:
+			fmt.Fprint(w, ": heartbeat\n\n")
+			flusher.Flush()
 		}
 	}
 }
@@ -1055,7 +1352,7 @@ func (s *Server) deleteRosterCharacter(w http.ResponseWriter, r *http.Request) {
 func (s *Server) getConfig(w http.ResponseWriter, r *http.Request) {
 	c := s.svc.Config()
 	authSource := c.AuthSource
-	c.OpenAIAPIKey, c.AnthropicAPIKey, c.GeminiAPIKey, c.TelegramToken = "", "", "", ""
+	c.OpenAIAPIKey, c.AnthropicAPIKey, c.GeminiAPIKey, c.LocalAPIKey, c.TelegramToken = "", "", "", "", ""
 	writeJSON(w, http.StatusOK, struct {
 		*domain.Config
 		AuthSource string `json:"auth_source,omitempty"`
@@ -1071,7 +1368,7 @@ func (s *Server) getConfig(w http.ResponseWriter, r *http.Request) {
 func (s *Server) putConfig(w http.ResponseWriter, r *http.Request) {
 	cfg := s.svc.Config() // *domain.Config; decode overlays present fields
 	oldOpenAI, oldAnthropic := cfg.OpenAIAPIKey, cfg.AnthropicAPIKey
-	oldGemini, oldTelegram := cfg.GeminiAPIKey, cfg.TelegramToken
+	oldGemini, oldLocal, oldTelegram := cfg.GeminiAPIKey, cfg.LocalAPIKey, cfg.TelegramToken
 	if !readJSON(w, r, cfg) {
 		return
 	}
@@ -1084,6 +1381,9 @@ func (s *Server) putConfig(w http.ResponseWriter, r *http.Request) {
 	if cfg.GeminiAPIKey == "" {
 		cfg.GeminiAPIKey = oldGemini
 	}
+	if cfg.LocalAPIKey == "" {
+		cfg.LocalAPIKey = oldLocal
+	}
 	if cfg.TelegramToken == "" {
 		cfg.TelegramToken = oldTelegram
 	}
@@ -5,6 +5,7 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"fmt"
 	"io"
 	"mime/multipart"
 	"net/http"
@@ -17,10 +18,17 @@ import (
 
 	"github.com/theburrowhub/thaimaturgy/internal/appservice"
 	"github.com/theburrowhub/thaimaturgy/internal/domain"
+	"github.com/theburrowhub/thaimaturgy/internal/providers"
 	"github.com/theburrowhub/thaimaturgy/internal/storage"
 )
 
 func newTestServer(t *testing.T, token string) *httptest.Server {
+	t.Helper()
+	return newTestServerWith(t, token, nil)
+}
+
+// newTestServerWith is newTestServer with sessions answered by provider.
+func newTestServerWith(t *testing.T, token string, provider providers.Provider) *httptest.Server {
 	t.Helper()
 	store, err := storage.NewWithPath(t.TempDir())
 	if err != nil {
@@ -43,7 +51,7 @@ func newTestServer(t *testing.T, token string) *httptest.Server {
 	if err := os.WriteFile(filepath.Join(dir, "assets", "map.png"), []byte("\x89PNG\r\n\x1a\nfake"), 0o644); err != nil {
 		t.Fatalf("write asset: %v", err)
 	}
-	svc := appservice.New(store, domain.DefaultConfig(), nil)
+	svc := appservice.New(store, domain.DefaultConfig(), provider)
 	ts := httptest.NewServer(New(svc, token).Handler())
 	t.Cleanup(ts.Close)
 	return ts
@@ -406,6 +414,15 @@ func TestAdventureEditorEndpoints(t *testing.T) {
 	} else if errs, _ := v["errors"].([]any); len(errs) != 0 {
 		t.Errorf("valid module should have no errors, got %v", errs)
 	}
+	// A creature with no stat block is a warning, not an error.
+	warned := `{"schema_version":"1.0","id":"crypt","title":"X","zones":[{"id":"z1","name":"Z","rooms":[{"id":"r1","name":"R","encounters":[{"name":"Eye","creatures":["Beholder","Goblin x2"]}]}]}]}`
+	if _, v := doJSON(t, "POST", ts.URL+"/api/adventures/crypt/validate", warned); func() bool {
+		errs, _ := v["errors"].([]any)
+		warns, _ := v["warnings"].([]any)
+		return len(errs) != 0 || len(warns) != 1 || !strings.Contains(fmt.Sprint(warns[0]), `creature "Beholder" resolves to no stat block`)
+	}() {
+		t.Errorf("an unresolved creature should be one warning and no errors, got %v", v)
+	}
 	broken := `{"schema_version":"1.0","id":"crypt","title":"X","zones":[{"id":"z1","name":"Z","rooms":[{"id":"r1","name":"R","npc_ids":["ghost"]}]}]}`
 	if _, v := doJSON(t, "POST", ts.URL+"/api/adventures/crypt/validate", broken); func() bool {
 		errs, _ := v["errors"].([]any)
@@ -724,3 +741,242 @@ func TestSSEStreamsLog(t *testing.T) {
 		t.Error("SSE did not stream the session's log entry")
 	}
 }
+
+// sseEvent is one parsed Server-Sent Event.
+type sseEvent struct{ id, event, data string }
+
+// readSSE reads the next event from sc, skipping comments.
+func readSSE(t *testing.T, sc *bufio.Scanner) sseEvent {
+	t.Helper()
+	var ev sseEvent
+	for sc.Scan() {
+		line := sc.Text()
+		switch {
+		case strings.HasPrefix(line, "id: "):
+			ev.id = line[len("id: "):]
+		case strings.HasPrefix(line, "event: "):
+			ev.event = line[len("event: "):]
+		case strings.HasPrefix(line, "data: "):
+			ev.data = line[len("data: "):]
+		case line == "" && ev.event != "":
+			return ev
+		}
+	}
+	t.Fatalf("stream ended: %v", sc.Err())
+	return ev
+}
+
+func TestSSEPushesStateEventsAndResumes(t *testing.T) {
+	ts := newTestServer(t, "")
+	_, out := doJSON(t, "POST", ts.URL+"/api/sessions", `{"adventure_id":"crypt"}`)
+	name := out["name"].(string)
+	open := func(lastID string) (*bufio.Scanner, func()) {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		req, _ := http.NewRequestWithContext(ctx, "GET", ts.URL+"/api/sessions/"+name+"/events", nil)
+		if lastID != "" {
+			req.Header.Set("Last-Event-ID", lastID)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("sse: %v", err)
+		}
+		return bufio.NewScanner(resp.Body), func() { resp.Body.Close(); cancel() }
+	}
+
+	sc, done := open("")
+	sync := readSSE(t, sc)
+	if sync.event != "sync" || sync.id == "" || sync.data != `{"resync":false}` {
+		t.Fatalf("first event = %+v; want a sync", sync)
+	}
+	doJSON(t, "POST", ts.URL+"/api/sessions/"+name+"/command", `{"input":"/flag gate=true"}`)
+	var world sseEvent
+	for world.event != "world" {
+		world = readSSE(t, sc)
+	}
+	if world.id == "" || !strings.Contains(world.data, `"gate":true`) {
+		t.Errorf("world data = %s", world.data)
+	}
+	done()
+
+	// Reconnecting with the sync id picks up right after it.
+	sc, done = open(sync.id)
+	defer done()
+	if ev := readSSE(t, sc); ev.event != "log" || ev.id == "" || !strings.Contains(ev.data, "gate") {
+		t.Errorf("resumed with %+v; want the flag's log entry", ev)
+	}
+	if ev := readSSE(t, sc); ev.id != world.id {
+		t.Errorf("then %+v; want %s", ev, world.id)
+	}
+
+	// An id the server can't resume from starts over, asking for a resync.
+	sc2, done2 := open("stale-7")
+	defer done2()
+	if ev := readSSE(t, sc2); ev.event != "sync" || ev.data != `{"resync":true}` {
+		t.Errorf("stale id = %+v; want a resync", ev)
+	}
+}
+
+// streamingProvider streams a fixed reply in two pieces.
+type streamingProvider struct{}
+
+func (streamingProvider) Name() string         { return "streaming" }
+func (streamingProvider) SupportsTools() bool  { return true }
+func (streamingProvider) SupportsVision() bool { return false }
+func (p streamingProvider) Chat(ctx context.Context, req providers.ChatRequest) (*providers.ChatResponse, error) {
+	return p.ChatStream(ctx, req, nil)
+}
+func (streamingProvider) ChatStream(_ context.Context, _ providers.ChatRequest, fn providers.StreamFunc) (*providers.ChatResponse, error) {
+	if fn != nil {
+		fn("The gate ")
+		fn("groans open.")
+	}
+	return &providers.ChatResponse{Content: "The gate groans open.", FinishReason: "stop"}, nil
+}
+
+func TestOracleStreams(t *testing.T) {
+	ts := newTestServerWith(t, "", streamingProvider{})
+	_, out := doJSON(t, "POST", ts.URL+"/api/sessions", `{"adventure_id":"crypt"}`)
+	name := out["name"].(string)
+
+	// The plain variant still answers with JSON.
+	if _, out := doJSON(t, "POST", ts.URL+"/api/sessions/"+name+"/oracle", `{"input":"we push the gate"}`); out["answer"] != "The gate groans open." {
+		t.Fatalf("oracle = %v", out)
+	}
+
+	resp, err := http.Post(ts.URL+"/api/sessions/"+name+"/oracle?stream=1", "application/json", strings.NewReader(`{"input":"again"}`))
+	if err != nil {
+		t.Fatalf("oracle stream: %v", err)
+	}
+	defer resp.Body.Close()
+	if ct := resp.Header.Get("Content-Type"); !strings.HasPrefix(ct, "text/event-stream") {
+		t.Fatalf("content-type = %q; want text/event-stream", ct)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	want := "event: token\ndata: {\"text\":\"The gate \"}\n\n" +
+		"event: token\ndata: {\"text\":\"groans open.\"}\n\n" +
+		"event: done\ndata: {\"answer\":\"The gate groans open.\",\"latency_ms\":0,\"tokens_used\":0}\n\n"
+	if string(body) != want {
+		t.Errorf("stream =\n%s\nwant\n%s", body, want)
+	}
+
+	// A session that isn't open fails before the stream starts.
+	resp, err = http.Post(ts.URL+"/api/sessions/nope/oracle?stream=1", "application/json", strings.NewReader(`{"input":"x"}`))
+	if err != nil {
+		t.Fatalf("oracle stream: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusConflict {
+		t.Errorf("unknown session status = %d; want 409", resp.StatusCode)
+	}
+}
+
+func TestSessionUsage(t *testing.T) {
+	ts := newTestServerWith(t, "", streamingProvider{})
+	_, out := doJSON(t, "POST", ts.URL+"/api/sessions", `{"adventure_id":"crypt"}`)
+	name := out["name"].(string)
+	doJSON(t, "POST", ts.URL+"/api/sessions/"+name+"/oracle", `{"input":"we push the gate"}`)
+
+	resp, out := doJSON(t, "GET", ts.URL+"/api/sessions/"+name+"/usage", "")
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("usage status = %d", resp.StatusCode)
+	}
+	lines, _ := out["lines"].([]any)
+	if len(lines) != 1 {
+		t.Fatalf("usage = %v", out)
+	}
+	if l := lines[0].(map[string]any); l["purpose"] != "oracle" || l["provider"] != "streaming" || l["calls"] != float64(1) {
+		t.Errorf("usage line = %v", l)
+	}
+	if resp, _ := doJSON(t, "GET", ts.URL+"/api/sessions/nope/usage", ""); resp.StatusCode != http.StatusNotFound {
+		t.Errorf("unknown session status = %d; want 404", resp.StatusCode)
+	}
+}
+
+func TestUndoRedoEndpoints(t *testing.T) {
+	ts := newTestServerWith(t, "", streamingProvider{})
+	_, out := doJSON(t, "POST", ts.URL+"/api/sessions", `{"adventure_id":"crypt"}`)
+	name := out["name"].(string)
+	if resp, _ := doJSON(t, "POST", ts.URL+"/api/sessions/"+name+"/undo", ""); resp.StatusCode != http.StatusConflict {
+		t.Errorf("undo with no history = %d; want 409", resp.StatusCode)
+	}
+	doJSON(t, "POST", ts.URL+"/api/sessions/"+name+"/oracle", `{"input":"we push the gate"}`)
+
+	resp, out := doJSON(t, "POST", ts.URL+"/api/sessions/"+name+"/undo", "")
+	if resp.StatusCode != http.StatusOK || out["label"] != "Turn: we push the gate" {
+		t.Fatalf("undo = %d (%v)", resp.StatusCode, out)
+	}
+	if _, st := doJSON(t, "GET", ts.URL+"/api/sessions/"+name, ""); len(st["conversation"].(map[string]any)["messages"].([]any)) != 0 {
+		t.Errorf("conversation after undo = %v", st["conversation"])
+	}
+	if resp, out := doJSON(t, "POST", ts.URL+"/api/sessions/"+name+"/redo", ""); resp.StatusCode != http.StatusOK || out["message"] != "Redid Turn: we push the gate" {
+		t.Errorf("redo = %d (%v)", resp.StatusCode, out)
+	}
+}
+
+func TestSnapshotEndpoints(t *testing.T) {
+	ts := newTestServer(t, "")
+	_, out := doJSON(t, "POST", ts.URL+"/api/sessions", `{"adventure_id":"crypt"}`)
+	name := out["name"].(string)
+	base := ts.URL + "/api/sessions/" + name + "/snapshots"
+	if list := getArray(t, base); len(list) != 0 {
+		t.Fatalf("fresh session snapshots = %v", list)
+	}
+	if resp, _ := doJSON(t, "POST", base, `{"label":""}`); resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("unlabelled snapshot = %d; want 400", resp.StatusCode)
+	}
+	resp, snap := doJSON(t, "POST", base, `{"label":"before the vault heist"}`)
+	if resp.StatusCode != http.StatusCreated || snap["label"] != "before the vault heist" {
+		t.Fatalf("snapshot = %d (%v)", resp.StatusCode, snap)
+	}
+	id := snap["id"].(string)
+	if list := getArray(t, base); len(list) != 1 || list[0]["id"] != id {
+		t.Fatalf("snapshots = %v", list)
+	}
+
+	resp, out = doJSON(t, "POST", base+"/"+id+"/branch", `{"name":"what-if"}`)
+	if resp.StatusCode != http.StatusCreated || out["name"] != "what-if" {
+		t.Fatalf("branch = %d (%v)", resp.StatusCode, out)
+	}
+	if resp, _ := doJSON(t, "POST", base+"/"+id+"/branch", `{"name":"what-if"}`); resp.StatusCode != http.StatusConflict {
+		t.Errorf("branch onto an existing session = %d; want 409", resp.StatusCode)
+	}
+	if resp, _ := doJSON(t, "GET", ts.URL+"/api/sessions/what-if", ""); resp.StatusCode != http.StatusOK {
+		t.Errorf("branch can't be opened: %d", resp.StatusCode)
+	}
+	if resp, _ := doJSON(t, "DELETE", base+"/"+id, ""); resp.StatusCode != http.StatusOK {
+		t.Errorf("delete snapshot = %d", resp.StatusCode)
+	}
+	if resp, _ := doJSON(t, "DELETE", base+"/"+id, ""); resp.StatusCode != http.StatusNotFound {
+		t.Errorf("delete a gone snapshot = %d; want 404", resp.StatusCode)
+	}
+}
+
+func TestSearchAdventure(t *testing.T) {
+	ts := newTestServer(t, "")
+	resp, out := doJSON(t, "GET", ts.URL+"/api/adventures/crypt/search?q=gates", "")
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("search status = %d", resp.StatusCode)
+	}
+	hits, _ := out["hits"].([]any)
+	if len(hits) != 1 || hits[0].(map[string]any)["id"] != "r1" || hits[0].(map[string]any)["kind"] != "room" {
+		t.Fatalf("hits = %v", out)
+	}
+
+	// Saving the adventure refreshes its index.
+	_, adv := doJSON(t, "GET", ts.URL+"/api/adventures/crypt", "")
+	room := adv["zones"].([]any)[0].(map[string]any)["rooms"].([]any)[0].(map[string]any)
+	room["read_aloud"] = "A rusted portcullis blocks the way."
+	b, _ := json.Marshal(adv)
+	doJSON(t, "PUT", ts.URL+"/api/adventures/crypt", string(b))
+	_, out = doJSON(t, "GET", ts.URL+"/api/adventures/crypt/search?q=portcullis&limit=5", "")
+	if hits, _ := out["hits"].([]any); len(hits) != 1 || !strings.Contains(hits[0].(map[string]any)["snippet"].(string), "rusted portcullis") {
+		t.Errorf("search after save = %v", out)
+	}
+
+	if resp, _ := doJSON(t, "GET", ts.URL+"/api/adventures/crypt/search", ""); resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("missing query status = %d; want 400", resp.StatusCode)
+	}
+	if resp, _ := doJSON(t, "GET", ts.URL+"/api/adventures/nope/search?q=gate", ""); resp.StatusCode != http.StatusNotFound {
+		t.Errorf("unknown adventure status = %d; want 404", resp.StatusCode)
+	}
+}
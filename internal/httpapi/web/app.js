@@ -15,7 +15,7 @@ const el = (tag, cls, text) => {
 
 const tokenInput = $("#token");
 tokenInput.value = sessionStorage.getItem("thaim_token") || "";
-tokenInput.addEventListener("change", () => { sessionStorage.setItem("thaim_token", tokenInput.value.trim()); loadVersion(); });
+tokenInput.addEventListener("change", () => { sessionStorage.setItem("thaim_token", tokenInput.value.trim()); loadVersion(); applyRole(); });
 const token = () => tokenInput.value.trim();
 
 function status(msg, isErr) {
@@ -44,6 +44,46 @@ async function api(method, path, body) {
   return data;
 }
 
+// apiStream posts to a streaming endpoint (or, with no body, gets one) and hands
+// each Server-Sent Event to onEvent(event, data) as it arrives, until the stream
+// ends or signal aborts it. Errors before the stream starts come back as plain
+// JSON, as with api().
+async function apiStream(path, body, onEvent, signal) {
+  const headers = { "Accept": "text/event-stream" };
+  if (body !== undefined) headers["Content-Type"] = "application/json";
+  if (token()) headers["Authorization"] = "Bearer " + token();
+  const resp = await fetch("/api" + path, {
+    method: body !== undefined ? "POST" : "GET", headers, signal,
+    body: body !== undefined ? JSON.stringify(body) : undefined,
+  });
+  if (!resp.ok) {
+    let data = null;
+    try { data = await resp.json(); } catch { /* non-JSON */ }
+    throw new Error((data && data.error) || resp.statusText || ("HTTP " + resp.status));
+  }
+  const reader = resp.body.getReader();
+  const decoder = new TextDecoder();
+  let buf = "";
+  for (;;) {
+    const { done, value } = await reader.read();
+    if (done) break;
+    buf += decoder.decode(value, { stream: true }).replace(/\r\n/g, "\n");
+    let i;
+    while ((i = buf.indexOf("\n\n")) >= 0) {
+      const block = buf.slice(0, i);
+      buf = buf.slice(i + 2);
+      let event = "message", data = "";
+      for (const line of block.split("\n")) {
+        if (line.startsWith("event:")) event = line.slice(6).trim();
+        else if (line.startsWith("data:")) data += (data ? "\n" : "") + line.slice(5).trimStart();
+      }
+      let parsed = null;
+      try { parsed = data ? JSON.parse(data) : null; } catch { /* non-JSON */ }
+      onEvent(event, parsed);
+    }
+  }
+}
+
 // Module images are behind the same auth as the API, and an <img> tag can't send
 // the bearer header, so we fetch them as authenticated blobs and cache the object
 // URLs for the life of the open session.
@@ -69,12 +109,14 @@ function clearAssets() {
 
 function show(view) {
   leaveSession(); // tear down any live session stream before switching
+  leavePlayerGame();
   document.querySelectorAll(".view").forEach((v) => v.classList.add("hidden"));
   $("#view-" + view).classList.remove("hidden");
   document.querySelectorAll(".nav").forEach((b) => b.classList.toggle("active", b.dataset.view === view));
   if (view === "library") loadLibrary();
   if (view === "roster") loadRoster();
   if (view === "settings") loadSettings();
+  if (view === "player") loadPlayerSessions();
 }
 document.querySelectorAll(".nav").forEach((b) => b.addEventListener("click", () => show(b.dataset.view)));
 
@@ -126,12 +168,59 @@ async function loadLibrary() {
         try { await api("DELETE", "/sessions/" + encodeURIComponent(s.name)); loadLibrary(); }
         catch (e) { status(e.message, true); }
       };
-      c.append(open, ren, del);
-      sess.append(c);
+      // Named save points, listed under the card; each can fork a new session.
+      const snapBox = el("div", "list small snapshots hidden");
+      const snaps = el("button", "ghost", "Snapshots");
+      snaps.onclick = () => {
+        snapBox.classList.toggle("hidden");
+        if (!snapBox.classList.contains("hidden")) renderSnapshots(s.name, snapBox);
+      };
+      c.append(open, snaps, ren, del);
+      sess.append(c, snapBox);
     }
   } catch (e) { status(e.message, true); }
 }
 
+// renderSnapshots lists a session's snapshots into box, with Branch/Delete.
+async function renderSnapshots(name, box) {
+  box.innerHTML = "";
+  try {
+    const list = (await api("GET", "/sessions/" + encodeURIComponent(name) + "/snapshots")) || [];
+    if (!list.length) {
+      box.append(el("div", "muted", "No snapshots yet. Take one in play with 📌 Snapshot or /save snapshot <label>."));
+      return;
+    }
+    for (const sn of list) {
+      const c = el("div", "card");
+      c.append(el("span", "title", sn.label));
+      c.append(el("span", "muted", [fmtTime(sn.created_at), sn.current_room].filter(Boolean).join(" · ")));
+      c.append(el("span", "spacer"));
+      const branch = el("button", null, "Branch");
+      branch.onclick = () => branchFrom(name, sn.id);
+      const del = el("button", "ghost", "Delete");
+      del.onclick = async () => {
+        if (!confirm("Delete snapshot “" + sn.label + "”?")) return;
+        try { await api("DELETE", "/sessions/" + encodeURIComponent(name) + "/snapshots/" + encodeURIComponent(sn.id)); renderSnapshots(name, box); }
+        catch (e) { status(e.message, true); }
+      };
+      c.append(branch, del);
+      box.append(c);
+    }
+  } catch (e) { status(e.message, true); }
+}
+
+// branchFrom forks a new session from a snapshot (by id or label) and opens
+// it; the original session is left as it is.
+async function branchFrom(name, ref) {
+  const nn = prompt("Name the new branch (empty for " + name + "-branch)", "");
+  if (nn === null) return;
+  try {
+    const r = await api("POST", "/sessions/" + encodeURIComponent(name) + "/snapshots/" + encodeURIComponent(ref) + "/branch", { name: nn.trim() });
+    status("Branched “" + r.name + "” from " + name + ".");
+    openSession(r.name);
+  } catch (e) { status(e.message, true); }
+}
+
 // Import a module (.tar.gz) via multipart upload. FormData sets its own
 // Content-Type boundary, so we only add the bearer header when a token is set.
 $("#import-form").addEventListener("submit", async (e) => {
@@ -224,25 +313,30 @@ let current = null;      // session name
 let adv = null;          // loaded adventure content
 let sess = null;         // last-known session state
 let evtSource = null;    // EventSource
+let lastEventId = "";    // id of the last stream event, to resume after it
+let localTurns = 0;      // turns this tab has in flight (they render their own replies)
 let openGen = 0;         // bumped on each open/leave; stale async work checks it
 let selectedKey = null;  // selected browser node, e.g. "room:r1"
 let lastZone = null;     // for auto zone-art on zone change
 
 function appendLine(cls, text) {
   const t = $("#transcript");
-  t.append(el("div", cls, text));
+  const line = el("div", cls, text);
+  t.append(line);
   t.scrollTop = t.scrollHeight;
+  return line;
 }
 
 function leaveSession() {
   openGen++;
-  current = null; adv = null; sess = null; selectedKey = null; lastZone = null;
+  current = null; adv = null; sess = null; selectedKey = null; lastZone = null; lastEventId = "";
   if (evtSource) { evtSource.close(); evtSource = null; }
   clearAssets();
 }
 
 async function openSession(name) {
   const gen = ++openGen;
+  lastEventId = "";
   if (evtSource) { evtSource.close(); evtSource = null; }
   clearAssets();
   try {
@@ -256,11 +350,7 @@ async function openSession(name) {
     // Show the session view.
     document.querySelectorAll(".view").forEach((v) => v.classList.add("hidden"));
     $("#view-session").classList.remove("hidden");
-    // Transcript replay.
-    $("#transcript").innerHTML = "";
-    const conv = (st.conversation && st.conversation.messages) || [];
-    for (const m of conv) appendLine(m.role === "assistant" ? "a" : "u", (m.role === "assistant" ? "" : "» ") + m.content);
-    const t = $("#transcript"); t.scrollTop = t.scrollHeight;
+    renderTranscript();
     // Panels.
     renderBrowser();
     renderParty();
@@ -275,6 +365,14 @@ async function openSession(name) {
   } catch (e) { if (gen === openGen) status(e.message, true); }
 }
 
+// renderTranscript replays the session's conversation into the transcript.
+function renderTranscript() {
+  $("#transcript").innerHTML = "";
+  const conv = (sess.conversation && sess.conversation.messages) || [];
+  for (const m of conv) appendLine(m.role === "assistant" ? "a" : "u", (m.role === "assistant" ? "" : "» ") + m.content);
+  const t = $("#transcript"); t.scrollTop = t.scrollHeight;
+}
+
 // refreshState re-fetches the session state after a mutation and re-renders the
 // state-dependent panels (browser markers, party, location, mode UI).
 async function refreshState() {
@@ -611,10 +709,49 @@ function applyModeUI() {
     ? "Describe what your character does…  (Enter sends · Shift/Ctrl+Enter = newline)"
     : "Ask the oracle, or type a /command.  (Enter sends · Shift/Ctrl+Enter = newline)";
   applyTelegramUI();
+  applyRoundUI();
 }
 
 $("#mode-toggle").onclick = () => runCommand("/mode");
-$("#begin").onclick = () => runCommand("/begin");
+$("#begin").onclick = () => { if (seatedPlayers()) roundTurn("begin"); else runCommand("/begin"); };
+
+// --- Web table -------------------------------------------------------------
+// When players have taken seats from the player portal, the host runs the
+// round loop here, as the Telegram bot does for a chat: Begin has the DM set
+// the scene for them, and Resolve round has the DM resolve the actions they
+// declared. The narration reaches the players on their table stream.
+let roundBusy = false; // a begin/resolve request is in flight?
+
+function seatedPlayers() { return Object.keys((sess && sess.players) || {}).length; }
+
+function applyRoundUI() {
+  const btn = $("#resolve");
+  const acts = (sess && sess.round && sess.round.actions) || [];
+  const started = !!(sess && sess.started);
+  btn.classList.toggle("hidden", !(effectiveMode() === "dm" && started && seatedPlayers()));
+  btn.textContent = `Resolve round (${acts.length})`;
+  btn.title = acts.map((a) => `${a.character_name} (${a.display_name}): ${a.text}`).join("\n") ||
+    "No actions declared yet — the players declare them from the player portal";
+  btn.disabled = hosting || roundBusy || !acts.length;
+  if (roundBusy) $("#begin").disabled = true;
+}
+
+async function roundTurn(verb) {
+  if (!current || roundBusy) return;
+  const gen = openGen;
+  roundBusy = true;
+  applyRoundUI();
+  appendLine("log", verb === "begin" ? "The DM is setting the scene for the table…" : "The DM is resolving the round…");
+  try {
+    const r = await api("POST", "/sessions/" + encodeURIComponent(current) + "/round/" + verb);
+    if (gen === openGen && r.error) appendLine("err", "⚠ " + r.error);
+  } catch (e) { if (gen === openGen) appendLine("err", "⚠ " + e.message); }
+  finally {
+    roundBusy = false;
+    if (gen === openGen) applyModeUI();
+  }
+}
+$("#resolve").onclick = () => roundTurn("resolve");
 
 // --- Host on Telegram (server-side bot) ---------------------------------
 // The SERVER runs the Telegram bot bound to this session (token from Settings).
@@ -683,6 +820,40 @@ $("#telegram").onclick = async () => {
   }
 };
 
+// --- Snapshots ---------------------------------------------------------------
+// A named save point of the session ("before the vault heist"); the library
+// lists them, and any can fork a new session.
+async function takeSnapshot(label) {
+  if (!current || !label) return;
+  try {
+    await api("POST", "/sessions/" + encodeURIComponent(current) + "/snapshots", { label });
+    appendLine("log", "📌 Snapshot “" + label + "” taken.");
+  } catch (e) { appendLine("err", "⚠ " + e.message); }
+}
+$("#snapshot").onclick = () => {
+  const label = prompt("Name this save point", "");
+  if (label && label.trim()) takeSnapshot(label.trim());
+};
+
+// --- Undo / Redo -----------------------------------------------------------
+// Rolls the last oracle turn or command back (state, log and conversation), or
+// re-applies it. Also a host control: it works while hosting on Telegram, where
+// the bot tells the table.
+async function rollChange(verb) {
+  if (!current) return;
+  const gen = openGen;
+  try {
+    const r = await api("POST", "/sessions/" + encodeURIComponent(current) + "/" + verb);
+    if (gen !== openGen) return;
+    await refreshState();
+    renderTranscript();
+    renderLog();
+    appendLine("log", r.message);
+  } catch (e) { if (gen === openGen) appendLine("err", "⚠ " + e.message); }
+}
+$("#undo").onclick = () => rollChange("undo");
+$("#redo").onclick = () => rollChange("redo");
+
 $("#rest").onclick = () => {
   const kind = prompt("Rest type: short or long?", "short");
   if (!kind) return;
@@ -854,6 +1025,7 @@ $("#close").onclick = async () => {
 // browser markers and panels reflect any mutation.
 async function runCommand(input) {
   if (!current) return;
+  localTurns++;
   try {
     const r = await api("POST", "/sessions/" + encodeURIComponent(current) + "/command", { input });
     if (r.message) appendLine("log", r.message);
@@ -861,22 +1033,51 @@ async function runCommand(input) {
     if (r.ui_action === "oracle" && r.ui_arg) { await askOracle(r.ui_arg); }
     else if (r.ui_action === "image" && r.ui_arg) { showImageDetail(r.ui_arg); }
     else if (r.ui_action === "save") { await api("POST", "/sessions/" + encodeURIComponent(current) + "/save"); status("Saved."); }
+    else if (r.ui_action === "snapshot") { await takeSnapshot(r.ui_arg); }
+    else if (r.ui_action === "branch") {
+      if (r.ui_arg) { await branchFrom(current, r.ui_arg); return; }
+      const list = (await api("GET", "/sessions/" + encodeURIComponent(current) + "/snapshots")) || [];
+      appendLine("a", list.length ? "Snapshots:\n" + list.map((sn) => "• " + sn.label + " (" + fmtTime(sn.created_at) + ")").join("\n")
+        : "No snapshots yet. Take one with /save snapshot <label>.");
+    }
     await refreshState();
     renderLog();
     if (r.ui_action === "mode") { detailPlaceholder(); }
+    if (r.ui_action === "undo") { renderTranscript(); appendLine("log", r.message); }
   } catch (e) { appendLine("err", "⚠ " + e.message); }
+  finally { localTurns--; }
 }
 
+// askOracle streams the DM's reply into a single transcript line as it is
+// written; tool activity shows on the thinking line until the turn is done.
 async function askOracle(input) {
+  localTurns++;
   appendLine("u", "» " + input);
-  appendLine("log", "…thinking…");
+  const thinking = appendLine("log", "…thinking…");
+  let answer = null;
+  const t = $("#transcript");
   try {
-    const r = await api("POST", "/sessions/" + encodeURIComponent(current) + "/oracle", { input });
-    if (r.error) appendLine("err", "⚠ " + r.error);
-    else appendLine("a", r.answer || "(no answer)");
+    await apiStream("/sessions/" + encodeURIComponent(current) + "/oracle", { input }, (event, d) => {
+      if (event === "token") {
+        if (!answer) answer = appendLine("a", "");
+        answer.textContent += d.text;
+      } else if (event === "reset") {
+        if (answer) { answer.remove(); answer = null; }
+      } else if (event === "tool") {
+        thinking.textContent = "…" + d.tool + (d.error ? " (failed)" : "") + "…";
+      } else if (event === "done") {
+        if (d.error) appendLine("err", "⚠ " + d.error);
+        else if (!answer) answer = appendLine("a", d.answer || "(no answer)");
+        else answer.textContent = d.answer || answer.textContent;
+        if (d.warning) appendLine("log", "⚠ " + d.warning);
+      }
+      t.scrollTop = t.scrollHeight;
+    });
   } catch (e) { appendLine("err", "⚠ " + e.message); }
+  thinking.remove();
   await refreshState();
   renderLog();
+  localTurns--;
 }
 
 async function submitInput(text) {
@@ -905,28 +1106,79 @@ $("#ask-input").addEventListener("keydown", (e) => {
   submitInput(text);
 });
 
-// --- Live log stream (SSE) ----------------------------------------------
+// --- Live session stream (SSE) ------------------------------------------
+// The server pushes the session's changes as typed events: log entries, and
+// the parts of the state that changed (location, npc, party, round, world,
+// mode, conversation), which are merged into sess and re-rendered, so changes
+// made by other clients (a Telegram host, another tab) show up live. Each event
+// carries an id; a reconnect resumes after the last one seen.
 
 async function subscribeEvents(name, gen) {
   if (evtSource) { evtSource.close(); evtSource = null; }
-  let url = "/api/sessions/" + encodeURIComponent(name) + "/events";
+  const q = [];
   if (token()) {
     try {
       const t = await api("POST", "/sse-ticket");
       if (gen !== openGen) return;
-      url += "?ticket=" + encodeURIComponent(t.ticket);
+      q.push("ticket=" + encodeURIComponent(t.ticket));
     } catch (e) { if (gen === openGen) status("live updates unavailable: " + e.message, true); return; }
   }
   if (gen !== openGen) return;
-  evtSource = new EventSource(url);
-  evtSource.addEventListener("log", (ev) => {
-    try {
-      const entry = JSON.parse(ev.data);
-      $("#log").append(logEntry(entry));
-      $("#log").scrollTop = $("#log").scrollHeight;
-    } catch { /* ignore */ }
+  if (lastEventId) q.push("last_event_id=" + encodeURIComponent(lastEventId));
+  const src = new EventSource("/api/sessions/" + encodeURIComponent(name) + "/events" + (q.length ? "?" + q.join("&") : ""));
+  evtSource = src;
+  const on = (type, fn) => src.addEventListener(type, (ev) => {
+    if (gen !== openGen) return;
+    if (ev.lastEventId) lastEventId = ev.lastEventId;
+    let d;
+    try { d = JSON.parse(ev.data); } catch { return; }
+    fn(d);
+  });
+  on("sync", (d) => {
+    // The whole timeline follows as log events.
+    if (sess) sess.log = { entries: [] };
+    $("#log").innerHTML = "";
+    if (d.resync) refreshState().then(() => { if (gen === openGen) renderTranscript(); });
+  });
+  on("log", (entry) => {
+    if (sess) { sess.log = sess.log || { entries: [] }; (sess.log.entries = sess.log.entries || []).push(entry); }
+    $("#log").append(logEntry(entry));
+    $("#log").scrollTop = $("#log").scrollHeight;
   });
-  evtSource.onerror = () => { /* EventSource auto-reconnects within the ticket window */ };
+  on("rewind", (d) => {
+    if (sess && sess.log && sess.log.entries) sess.log.entries.length = Math.min(sess.log.entries.length, d.log_length);
+    renderLog();
+  });
+  const merge = (render) => (d) => { if (sess) { Object.assign(sess, d); render(); } };
+  on("location", merge(() => { renderBrowser(); maybeAutoZoneArt(); }));
+  on("npc", merge(renderBrowser));
+  on("world", merge(renderBrowser));
+  on("party", merge(() => { renderParty(); applyRoundUI(); }));
+  on("round", merge(applyRoundUI));
+  on("mode", merge(applyModeUI));
+  on("conversation", (d) => {
+    if (!sess) return;
+    sess.conversation = sess.conversation || {};
+    const msgs = sess.conversation.messages = sess.conversation.messages || [];
+    if (d.message && d.length === msgs.length + 1) {
+      msgs.push(d.message);
+      if (!localTurns) appendLine(d.message.role === "assistant" ? "a" : "u", (d.message.role === "assistant" ? "" : "» ") + d.message.content);
+    } else if (d.length !== msgs.length && !localTurns) {
+      refreshState().then(() => { if (gen === openGen) renderTranscript(); });
+    }
+  });
+  on("closed", () => {
+    src.close();
+    if (evtSource === src) evtSource = null;
+    status("The session was closed.");
+  });
+  src.onerror = () => {
+    // EventSource reconnects by itself (resuming via Last-Event-ID) while the
+    // ticket is valid; once it gives up, subscribe again with a fresh one.
+    if (src.readyState !== EventSource.CLOSED || evtSource !== src) return;
+    evtSource = null;
+    setTimeout(() => { if (gen === openGen) subscribeEvents(name, gen); }, 3000);
+  };
 }
 
 // --- Dice roller ---------------------------------------------------------
@@ -1017,11 +1269,15 @@ async function loadSettings() {
   f.append(el("p", "muted small", "Detected credential (server): " + (c.auth_source || "(none detected)")));
 
   sec("Provider & models");
-  const provider = g("Provider", selectFrom(["openai", "anthropic", "gemini", "claude-cli"], c.provider || "openai"));
+  const provider = g("Provider", selectFrom(["openai", "anthropic", "gemini", "claude-cli", "local"], c.provider || "openai"));
   const model = g("Model", input(c.model || ""));
   const runModel = g("Run model (oracle)", input(c.run_model || ""));
   const editModel = g("Edit model (import)", input(c.edit_model || ""));
 
+  sec("Local model server (OpenAI-compatible: Ollama, LM Studio, llama.cpp, vLLM)");
+  const localURL = g("Server URL (as seen from the server, e.g. http://localhost:11434/v1)", input(c.local_base_url || ""));
+  const localTools = g("Tool calling", selectFrom(["auto", "native", "prompt"], c.local_tool_mode || "auto"));
+
   sec("Language");
   const lang = g("UI language", selectFrom(["en", "es"], c.language || "en"));
   const importLang = g("Import language", input(c.import_language || ""));
@@ -1041,13 +1297,14 @@ async function loadSettings() {
 
   sec("Spoiler guard (Virtual DM)");
   const sgEnabled = g("Review DM narration for spoilers", checkbox(c.spoiler_guard && c.spoiler_guard.enabled));
-  const sgProvider = g("Review provider", selectFrom(["(same as oracle)", "openai", "anthropic", "gemini", "claude-cli"], (c.spoiler_guard && c.spoiler_guard.provider) || "(same as oracle)"));
+  const sgProvider = g("Review provider", selectFrom(["(same as oracle)", "openai", "anthropic", "gemini", "claude-cli", "local"], (c.spoiler_guard && c.spoiler_guard.provider) || "(same as oracle)"));
   const sgModel = g("Review model (optional; blank = provider default)", input((c.spoiler_guard && c.spoiler_guard.model) || ""));
 
   sec("API keys (write-only)");
   const kOpenAI = g("OpenAI API key", passwordInput());
   const kAnthropic = g("Anthropic API key", passwordInput());
   const kGemini = g("Gemini API key", passwordInput());
+  const kLocal = g("Local server API key (optional)", passwordInput());
 
   sec("Telegram");
   const tgToken = g("Bot token (write-only)", passwordInput());
@@ -1055,7 +1312,7 @@ async function loadSettings() {
   const tgUsers = g("Allowed users (one numeric id per line)", textarea((c.telegram_allowed_users || []).join("\n"), 3));
 
   const save = el("button", null, "Save settings"); save.type = "submit"; f.append(save);
-  settingsRefs = { provider, model, runModel, editModel, lang, importLang, temp, maxTokens, importMax, oracleIters, timeout, autosave, autosaveInt, ttsEnabled, ttsVoice, sgEnabled, sgProvider, sgModel, kOpenAI, kAnthropic, kGemini, tgToken, tgChat, tgUsers };
+  settingsRefs = { provider, model, runModel, editModel, localURL, localTools, lang, importLang, temp, maxTokens, importMax, oracleIters, timeout, autosave, autosaveInt, ttsEnabled, ttsVoice, sgEnabled, sgProvider, sgModel, kOpenAI, kAnthropic, kGemini, kLocal, tgToken, tgChat, tgUsers };
 }
 
 $("#settings-form").addEventListener("submit", async (e) => {
@@ -1066,6 +1323,8 @@ $("#settings-form").addEventListener("submit", async (e) => {
   cfg.model = r.model.value.trim();
   cfg.run_model = r.runModel.value.trim();
   cfg.edit_model = r.editModel.value.trim();
+  cfg.local_base_url = r.localURL.value.trim();
+  cfg.local_tool_mode = r.localTools.value === "auto" ? "" : r.localTools.value;
   cfg.language = r.lang.value;
   cfg.import_language = r.importLang.value.trim();
   cfg.temperature = parseFloat(r.temp.value) || 0;
@@ -1084,6 +1343,7 @@ $("#settings-form").addEventListener("submit", async (e) => {
   cfg.openai_api_key = r.kOpenAI.value.trim();
   cfg.anthropic_api_key = r.kAnthropic.value.trim();
   cfg.gemini_api_key = r.kGemini.value.trim();
+  cfg.local_api_key = r.kLocal.value.trim();
   cfg.telegram_token = r.tgToken.value.trim();
   try { await api("PUT", "/config", cfg); status("Settings saved."); loadSettings(); }
   catch (err) { status(err.message, true); }
@@ -1670,9 +1930,13 @@ $("#ed-save").onclick = async () => {
 $("#ed-validate").onclick = async () => {
   try {
     const r = await api("POST", "/adventures/" + encodeURIComponent(editId) + "/validate", editAdv);
-    const errs = r.errors || [];
-    if (!errs.length) { status("Valid — no problems found."); }
-    else { alert("Validation problems (" + errs.length + "):\n\n" + errs.join("\n")); }
+    const errs = r.errors || [], warns = r.warnings || [];
+    if (errs.length) {
+      alert("Validation problems (" + errs.length + "):\n\n" + errs.join("\n") +
+        (warns.length ? "\n\nWarnings (" + warns.length + "):\n\n" + warns.join("\n") : ""));
+    } else if (warns.length) {
+      alert("Valid, with " + warns.length + " warning(s) that don't block saving or import:\n\n" + warns.join("\n"));
+    } else { status("Valid — no problems found."); }
   } catch (e) { status(e.message, true); }
 };
 $("#ed-export").onclick = () => downloadAuthed("/adventures/" + encodeURIComponent(editId) + "/export", editId + ".tar.gz");
@@ -1692,6 +1956,299 @@ async function downloadAuthed(path, filename) {
   } catch (e) { status(e.message, true); }
 }
 
+// --- Player portal -------------------------------------------------------
+// A player account signs in with a username and password and only gets the
+// player portal: the sessions its characters are in, the players' side of each
+// (where the party is, who they've met, the recap) and its own sheets, with the
+// same edits Telegram's /hp, /gold, /item… allow. In a virtual-DM game the
+// player also plays there, as on Telegram: takes a seat with a character,
+// declares actions, speaks in character or asks the DM, and follows the table
+// live on its stream, which also says when the view needs refetching.
+
+const sheetEdits = [
+  ["hp", "HP", "-5 damage · +3 heal · =10 set"], ["temphp", "Temp HP", "=5 · +3 · -2"],
+  ["ac", "AC", "=15 · +2 · -1"], ["gold", "Gold", "+50 · -10 · =100"], ["xp", "XP", "150"],
+  ["condition", "Add condition", "poisoned"], ["uncondition", "Remove condition", "poisoned"],
+  ["item", "Item", "add Rope x2 · remove Torch"], ["slot", "Spell slot", "2 use · 2 restore"],
+  ["spell", "Spell", "add 1 Shield · prepare Shield · remove Shield"],
+  ["savethrow", "Save proficiency", "WIS on · DEX off"], ["skill", "Skill", "Perception prof|expert|none"],
+  ["setnote", "Notes", "your character's notes"],
+];
+
+let playerGame = null;     // name of the session shown in the portal
+let playerStream = null;   // AbortController of the table stream while a game is shown
+let playerImages = [];     // object URLs of the shown game's images
+let playerEditing = false; // a sheet field has focus: don't re-render under it
+let playerStale = false;   // the view changed while a sheet field had focus
+let playerOwn = [];        // names of the player's characters in the game
+let playerRound = null;    // the round as the table stream last reported it
+
+async function playerImage(path) {
+  const headers = {};
+  if (token()) headers["Authorization"] = "Bearer " + token();
+  const resp = await fetch("/api" + path, { headers });
+  if (!resp.ok) throw new Error("image " + resp.status);
+  const url = URL.createObjectURL(await resp.blob());
+  playerImages.push(url);
+  return url;
+}
+
+function leavePlayerGame() {
+  playerGame = null; playerRound = null; playerOwn = [];
+  if (playerStream) { playerStream.abort(); playerStream = null; }
+  for (const url of playerImages) URL.revokeObjectURL(url);
+  playerImages = [];
+}
+
+async function loadPlayerSessions() {
+  const list = $("#player-sessions"); list.innerHTML = "";
+  $("#player-game").classList.add("hidden");
+  try {
+    const games = await api("GET", "/player/sessions");
+    if (!games.length) list.append(el("div", "muted", "None of your characters is in an open game yet. Ask your DM to open the session."));
+    for (const g of games) {
+      const card = el("div", "card");
+      card.append(el("span", "title", g.name), el("span", "muted", g.adventure + " · " + g.characters.join(", ")), el("span", "spacer"));
+      const b = el("button", null, "Open"); b.onclick = () => openPlayerGame(g.name);
+      card.append(b); list.append(card);
+    }
+  } catch (e) { status(e.message, true); }
+}
+
+async function openPlayerGame(name) {
+  leavePlayerGame();
+  playerGame = name;
+  $("#player-name").textContent = name;
+  $("#player-table").innerHTML = "";
+  $("#player-game").classList.remove("hidden");
+  await refreshPlayerGame();
+  watchTable(name);
+}
+
+// watchTable follows the table on the player's stream, reconnecting after a
+// drop while the game is still shown. What was narrated meanwhile is in the
+// recap, so a reconnect refetches the view.
+async function watchTable(name) {
+  const ctl = new AbortController();
+  playerStream = ctl;
+  let closed = false;
+  try {
+    await apiStream("/player/sessions/" + encodeURIComponent(name) + "/table", undefined, (event, d) => {
+      if (playerStream !== ctl) return;
+      if (event === "round") renderPlayerRound(d);
+      else if (event === "narration") tableLine("a", d.text, d);
+      else if (event === "chat") tableLine("u", "💬 " + d.text);
+      else if (event === "view") { if (playerEditing) playerStale = true; else refreshPlayerGame(); }
+      else if (event === "closed") { closed = true; status("The host closed this game.", true); }
+    }, ctl.signal);
+  } catch (e) {
+    if (ctl.signal.aborted) return;
+    status(e.message, true);
+  }
+  if (closed || playerStream !== ctl) return;
+  setTimeout(() => {
+    if (playerStream !== ctl) return;
+    refreshPlayerGame();
+    watchTable(name);
+  }, 3000);
+}
+
+// tableLine adds a line to the table; a narration's suggested actions are
+// folded away, as Telegram hides them behind a spoiler.
+function tableLine(cls, text, narration) {
+  const t = $("#player-table");
+  const line = el("div", cls, text);
+  if (narration && narration.actions) {
+    const more = el("details");
+    more.append(el("summary", null, narration.heading || "Possible actions"), el("div", null, narration.actions));
+    line.append(more);
+  }
+  t.append(line);
+  t.scrollTop = t.scrollHeight;
+}
+
+function renderPlayerRound(rs) {
+  playerRound = rs;
+  const box = $("#player-round");
+  const playing = rs.playing || [];
+  if (rs.resolving) box.textContent = "🎲 The DM is at work…";
+  else if (!rs.started) box.textContent = playing.length ? "Seated. The host begins the game." : "Take a seat with one of your characters; the host begins the game.";
+  else if (rs.pending.length) box.textContent = "Waiting on: " + rs.pending.join(", ");
+  else box.textContent = rs.actions.length ? "Everyone has acted — the host resolves the round." : "Declare what your characters do.";
+  for (const a of rs.actions) box.append(el("div", null, `▸ ${a.character}: ${a.text}`));
+
+  const as = $("#player-as"), keep = as.value;
+  as.innerHTML = "";
+  for (const c of playing) { const o = el("option", null, c); o.value = c; as.append(o); }
+  as.value = playing.includes(keep) ? keep : (rs.active || "");
+  $("#player-act").classList.toggle("hidden", !playing.length);
+  renderSeats();
+}
+
+// renderSeats offers a seat with each of the player's characters not yet
+// played.
+function renderSeats() {
+  const box = $("#player-seats"); box.innerHTML = "";
+  const playing = (playerRound && playerRound.playing) || [];
+  for (const name of playerOwn) {
+    if (playing.includes(name)) continue;
+    const b = el("button", "ghost", "Play " + name);
+    b.onclick = async () => {
+      try { const r = await playerPost("claim", { character: name }); status("You now play " + r.character + "."); }
+      catch (e) { status(e.message, true); }
+    };
+    box.append(b);
+  }
+}
+
+function playerPost(verb, body) {
+  return api("POST", "/player/sessions/" + encodeURIComponent(playerGame) + "/" + verb, body);
+}
+
+const playerKinds = {
+  do: "What your character does…",
+  chat: "What your character says…",
+  meta: "A question or correction for the DM…",
+};
+$("#player-kind").onchange = () => { $("#player-text").placeholder = playerKinds[$("#player-kind").value]; };
+$("#player-as").onchange = async () => {
+  try { const r = await playerPost("active", { character: $("#player-as").value }); status("You're acting as " + r.character + "."); }
+  catch (e) { status(e.message, true); }
+};
+$("#player-act").onsubmit = async (ev) => {
+  ev.preventDefault();
+  const kind = $("#player-kind").value, text = $("#player-text").value.trim();
+  if (!text || !playerGame) return;
+  if (kind === "meta") status("The DM is considering your note…");
+  try {
+    const r = await playerPost(kind, { character: $("#player-as").value, text });
+    $("#player-text").value = "";
+    if (kind === "do") status("Action recorded for " + r.character + ".");
+  } catch (e) { status(e.message, true); }
+};
+
+async function refreshPlayerGame() {
+  const name = playerGame;
+  if (!name) return;
+  const base = "/player/sessions/" + encodeURIComponent(name);
+  let v;
+  try { v = await api("GET", base); }
+  catch (e) { status(e.message, true); return; }
+  if (name !== playerGame) return;
+  playerStale = false;
+  playerOwn = v.characters.map((c) => c.name);
+  renderSeats();
+  for (const url of playerImages) URL.revokeObjectURL(url);
+  playerImages = [];
+
+  $("#player-loc").textContent = [v.zone, v.room].filter(Boolean).join(" — ") || "not yet placed";
+  $("#player-readaloud").textContent = v.read_aloud || "";
+  const map = $("#player-map"); map.innerHTML = "";
+  if (v.has_map) {
+    playerImage(base + "/map").then((url) => { const img = el("img"); img.src = url; img.alt = v.zone + " map"; map.append(img); }).catch(() => {});
+  }
+  const npcs = $("#player-npcs"); npcs.innerHTML = "";
+  if (!v.npcs.length) npcs.append(el("div", "muted", "(no one yet)"));
+  for (const n of v.npcs) {
+    const card = el("div", "card npc");
+    const text = el("div");
+    let title = n.name + (n.role ? " — " + n.role : "");
+    const tags = [n.disposition, n.alive ? "" : "deceased"].filter(Boolean);
+    if (tags.length) title += " (" + tags.join(", ") + ")";
+    text.append(el("div", "title", title));
+    if (n.appearance) text.append(el("div", "muted", n.appearance));
+    card.append(text);
+    if (n.has_portrait) {
+      playerImage(base + "/portraits/" + encodeURIComponent(n.id)).then((url) => { const img = el("img"); img.src = url; img.alt = n.name; card.prepend(img); }).catch(() => {});
+    }
+    npcs.append(card);
+  }
+  $("#player-recap").textContent = v.recap || "";
+
+  const chars = $("#player-chars"); chars.innerHTML = "";
+  for (const c of v.characters) chars.append(playerSheet(base, c));
+}
+
+// playerSheet renders one of the player's characters with a sheet-edit form.
+function playerSheet(base, c) {
+  const box = el("div", "sheet");
+  box.append(el("h4", null, `${c.name} — L${c.level || 1} ${c.race || ""} ${c.class || ""}`));
+  const hp = `HP ${c.current_hp}/${c.max_hp}` + (c.temp_hp ? ` (+${c.temp_hp} temp)` : "");
+  box.append(el("div", null, `${hp} · AC ${c.ac} · ${c.gold || 0} gp · ${c.xp || 0} XP`));
+  if ((c.conditions || []).length) box.append(el("div", "muted", "Conditions: " + c.conditions.join(", ")));
+  if ((c.inventory || []).length) box.append(el("div", "muted", "Carrying: " + c.inventory.map((it) => it.quantity > 1 ? `${it.name} x${it.quantity}` : it.name).join(", ")));
+  if (c.notes) box.append(el("div", "muted", "Notes: " + c.notes));
+
+  const form = el("form", "row");
+  const kind = el("select");
+  for (const [value, label] of sheetEdits) { const o = el("option", null, label); o.value = value; kind.append(o); }
+  const arg = el("input"); arg.autocomplete = "off";
+  const hint = () => { arg.placeholder = sheetEdits.find((e) => e[0] === kind.value)[2]; };
+  kind.onchange = hint; hint();
+  // Hold re-renders while the form has focus; catch up once it's left (after a
+  // beat, so a click on Apply lands first).
+  form.addEventListener("focusin", () => { playerEditing = true; });
+  form.addEventListener("focusout", (ev) => {
+    if (form.contains(ev.relatedTarget)) return;
+    playerEditing = false;
+    setTimeout(() => { if (playerStale && !playerEditing) refreshPlayerGame(); }, 300);
+  });
+  form.append(kind, arg, el("button", null, "Apply"));
+  form.onsubmit = async (ev) => {
+    ev.preventDefault();
+    try {
+      const r = await api("POST", base + "/sheet", { character: c.name, edit: kind.value, arg: arg.value });
+      status(r.character + " " + r.change);
+      playerEditing = false;
+      await refreshPlayerGame();
+    } catch (e) { status(e.message, true); }
+  };
+  box.append(form);
+  return box;
+}
+
+$("#player-refresh").onclick = () => refreshPlayerGame();
+
+// --- Sign in -------------------------------------------------------------
+
+// applyRole shows what the signed-in account may use: a player gets only the
+// portal, anyone else the full app.
+async function applyRole() {
+  let me = null;
+  try { me = await api("GET", "/whoami"); } catch { /* not signed in (yet) */ }
+  const player = !!me && me.role === "player";
+  document.querySelectorAll(".nav").forEach((b) => b.classList.toggle("hidden", player !== (b.dataset.view === "player")));
+  $("#token").classList.toggle("hidden", player);
+  $("#signin").classList.toggle("hidden", !!(me && me.id));
+  $("#signout").classList.toggle("hidden", !(me && me.id));
+  show(player ? "player" : "library");
+}
+
+function setToken(t) {
+  tokenInput.value = t;
+  sessionStorage.setItem("thaim_token", t);
+}
+
+$("#signin").onclick = () => { $("#login-modal").classList.remove("hidden"); $("#login-user").focus(); };
+$("#login-close").onclick = () => $("#login-modal").classList.add("hidden");
+$("#login-form").onsubmit = async (ev) => {
+  ev.preventDefault();
+  try {
+    const r = await api("POST", "/login", { username: $("#login-user").value.trim(), password: $("#login-pass").value });
+    $("#login-pass").value = "";
+    $("#login-modal").classList.add("hidden");
+    setToken(r.token);
+    status("Signed in as " + r.user.username + ".");
+    loadVersion();
+    await applyRole();
+  } catch (e) { status(e.message, true); }
+};
+$("#signout").onclick = async () => {
+  try { await api("POST", "/logout"); } catch { /* the token is dropped either way */ }
+  setToken("");
+  await applyRole();
+};
+
 // loadVersion fills the corner badge with the server's version (best-effort:
 // stays blank until the request is authorized).
 async function loadVersion() {
@@ -1702,5 +2259,5 @@ async function loadVersion() {
 }
 
 // --- boot ----------------------------------------------------------------
-show("library");
+applyRole();
 loadVersion();
@@ -0,0 +1,177 @@
+package httpapi
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"golang.org/x/net/websocket"
+
+	"github.com/theburrowhub/thaimaturgy/internal/appservice"
+	"github.com/theburrowhub/thaimaturgy/internal/engine"
+)
+
+// wsWriteTimeout bounds one frame write, so a client that stopped reading
+// can't hold up the turn that's writing to it.
+const wsWriteTimeout = 10 * time.Second
+
+// wsMaxRequest bounds a client's request frame, like the REST body limit.
+const wsMaxRequest = 1 << 20
+
+// wsRequest is a client's frame: a command or an oracle turn to run. Ref is
+// echoed on the frames that answer it.
+type wsRequest struct {
+	Ref   string `json:"ref"`
+	Type  string `json:"type"` // "command" or "oracle"
+	Input string `json:"input"`
+}
+
+// wsFrame is a server frame. A state event has the event's id, its topic as
+// Type and its data, as on the SSE stream (sync, closed and heartbeat frames
+// too). A reply has the request's Ref and a Type of "result" (a command's
+// result), "token", "tool" or "reset" (an oracle turn as it streams), "done"
+// (the turn's result) or "error".
+type wsFrame struct {
+	Type  string `json:"type"`
+	ID    string `json:"id,omitempty"`
+	Ref   string `json:"ref,omitempty"`
+	Data  any    `json:"data,omitempty"`
+	Error string `json:"error,omitempty"`
+}
+
+// wsAuthorized reports whether a WebSocket request may proceed. With a token
+// configured it needs an SSE ticket, as the events stream does. Without one the
+// loopback anti-CSRF guard runs here, since withAuth lets the socket through:
+// a browser doesn't hold cross-origin WebSockets to the same-origin policy.
+func (s *Server) wsAuthorized(r *http.Request) bool {
+	if s.token == "" {
+		code, _ := csrfGuard(r)
+		return code == 0
+	}
+	return s.validTicket(r.URL.Query().Get("ticket"))
+}
+
+// sessionSocket serves WS /api/sessions/{name}/ws, one connection for an
+// interactive client: it pushes the session's state events, as the events
+// stream does (resuming after ?last_event_id=), and runs the commands and
+// oracle turns the client sends, one at a time in order, streaming each turn's
+// reply as it's written.
+func (s *Server) sessionSocket(w http.ResponseWriter, r *http.Request) {
+	if !s.wsAuthorized(r) {
+		httpError(w, http.StatusUnauthorized, "missing or invalid ticket (POST /api/sse-ticket)")
+		return
+	}
+	os, ok := s.streamSession(w, r)
+	if !ok {
+		return
+	}
+	srv := websocket.Server{
+		// Origin was checked by wsAuthorized (or a ticket stands in for it).
+		Handshake: func(*websocket.Config, *http.Request) error { return nil },
+		Handler: func(ws *websocket.Conn) {
+			s.serveSocket(ws, os, r.URL.Query().Get("last_event_id"))
+		},
+	}
+	srv.ServeHTTP(w, r)
+}
+
+func (s *Server) serveSocket(ws *websocket.Conn, os *appservice.OpenSession, lastID string) {
+	ws.MaxPayloadBytes = wsMaxRequest
+	var wmu sync.Mutex
+	send := func(f wsFrame) error {
+		wmu.Lock()
+		defer wmu.Unlock()
+		_ = ws.SetWriteDeadline(time.Now().Add(wsWriteTimeout))
+		return websocket.JSON.Send(ws, f)
+	}
+	sub, first, replayed := openStream(os, lastID)
+	defer sub.Close()
+	for _, e := range first {
+		if send(wsFrame{Type: e.event, ID: e.id, Data: e.data}) != nil {
+			return
+		}
+	}
+
+	// The reader hands requests to the worker, which runs them in order; a
+	// turn in flight is cancelled when the client goes away.
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	reqs := make(chan wsRequest, 16)
+	gone := make(chan struct{})
+	go func() {
+		defer close(gone)
+		defer close(reqs)
+		for {
+			var req wsRequest
+			if err := websocket.JSON.Receive(ws, &req); err != nil {
+				return
+			}
+			select {
+			case reqs <- req:
+			default:
+				_ = send(wsFrame{Type: "error", Ref: req.Ref, Error: "too many requests in flight"})
+			}
+		}
+	}()
+	name := os.Session.State.Name
+	go func() {
+		for req := range reqs {
+			s.socketRequest(ctx, name, req, send)
+		}
+	}()
+
+	heartbeat := time.NewTicker(sseHeartbeat)
+	defer heartbeat.Stop()
+	for {
+		select {
+		case <-gone:
+			return
+		case e, ok := <-sub.C:
+			if !ok {
+				// Dropped for falling behind (the client reconnects and
+				// resumes) or the session closed.
+				if sub.SessionClosed() {
+					_ = send(wsFrame{Type: "closed", Data: struct{}{}})
+				}
+				return
+			}
+			if e.Seq <= replayed {
+				continue
+			}
+			if send(wsFrame{Type: string(e.Topic), ID: e.ID, Data: e.Data}) != nil {
+				return
+			}
+		case <-heartbeat.C:
+			if send(wsFrame{Type: "heartbeat"}) != nil {
+				return
+			}
+		}
+	}
+}
+
+// socketRequest runs one client request and sends its reply frames.
+func (s *Server) socketRequest(ctx context.Context, name string, req wsRequest, send func(wsFrame) error) {
+	fail := func(err error) { _ = send(wsFrame{Type: "error", Ref: req.Ref, Error: err.Error()}) }
+	switch req.Type {
+	case "command":
+		res, err := s.svc.ExecuteCommand(name, req.Input)
+		if err != nil {
+			fail(err)
+			return
+		}
+		_ = send(wsFrame{Type: "result", Ref: req.Ref, Data: commandResult(res)})
+	case "oracle":
+		resp, err := s.svc.AskOracleStream(ctx, name, req.Input, func(e engine.Event) {
+			_ = send(wsFrame{Type: string(e.Kind), Ref: req.Ref, Data: oracleEventData(e)})
+		})
+		if err != nil {
+			fail(err)
+			return
+		}
+		_ = send(wsFrame{Type: "done", Ref: req.Ref, Data: oracleResult(resp)})
+	default:
+		_ = send(wsFrame{Type: "error", Ref: req.Ref, Error: "unknown request type " + strconv.Quote(req.Type)})
+	}
+}
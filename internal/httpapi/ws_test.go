@@ -0,0 +1,141 @@
+package httpapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/net/websocket"
+)
+
+// dialSession opens a session's WebSocket from origin, with an optional query.
+func dialSession(t *testing.T, base, name, query, origin string) (*websocket.Conn, error) {
+	t.Helper()
+	u := "ws" + strings.TrimPrefix(base, "http") + "/api/sessions/" + name + "/ws"
+	if query != "" {
+		u += "?" + query
+	}
+	return websocket.Dial(u, "", origin)
+}
+
+// readFrame reads the next frame that isn't a heartbeat.
+func readFrame(t *testing.T, ws *websocket.Conn) map[string]any {
+	t.Helper()
+	_ = ws.SetReadDeadline(time.Now().Add(5 * time.Second))
+	for {
+		var f map[string]any
+		if err := websocket.JSON.Receive(ws, &f); err != nil {
+			t.Fatalf("read frame: %v", err)
+		}
+		if f["type"] != "heartbeat" {
+			return f
+		}
+	}
+}
+
+func TestSessionSocket(t *testing.T) {
+	ts := newTestServerWith(t, "", streamingProvider{})
+	_, out := doJSON(t, "POST", ts.URL+"/api/sessions", `{"adventure_id":"crypt"}`)
+	name := out["name"].(string)
+	ws, err := dialSession(t, ts.URL, name, "", ts.URL)
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	defer ws.Close()
+	if f := readFrame(t, ws); f["type"] != "sync" || f["id"] == "" {
+		t.Fatalf("first frame = %v; want a sync", f)
+	}
+
+	// A command answers with its result; the state events it causes are pushed.
+	if err := websocket.JSON.Send(ws, wsRequest{Ref: "1", Type: "command", Input: "/flag gate=true"}); err != nil {
+		t.Fatal(err)
+	}
+	seen := map[string]bool{}
+	for !seen["result"] || !seen["world"] {
+		f := readFrame(t, ws)
+		seen[f["type"].(string)] = true
+		if f["type"] == "result" && (f["ref"] != "1" || f["data"].(map[string]any)["success"] != true) {
+			t.Errorf("result = %v", f)
+		}
+	}
+
+	// An oracle turn streams its reply, then its result.
+	if err := websocket.JSON.Send(ws, wsRequest{Ref: "2", Type: "oracle", Input: "we push the gate"}); err != nil {
+		t.Fatal(err)
+	}
+	var text strings.Builder
+	for {
+		f := readFrame(t, ws)
+		if f["ref"] != "2" {
+			continue // state events of the turn
+		}
+		data, _ := f["data"].(map[string]any)
+		if f["type"] == "token" {
+			text.WriteString(data["text"].(string))
+			continue
+		}
+		if f["type"] != "done" || data["answer"] != "The gate groans open." {
+			t.Fatalf("final frame = %v", f)
+		}
+		break
+	}
+	if text.String() != "The gate groans open." {
+		t.Errorf("streamed %q", text.String())
+	}
+
+	// A request that fails answers with an error.
+	_ = websocket.JSON.Send(ws, wsRequest{Ref: "3", Type: "dance"})
+	for {
+		if f := readFrame(t, ws); f["ref"] == "3" {
+			if f["type"] != "error" {
+				t.Errorf("unknown request = %v", f)
+			}
+			break
+		}
+	}
+
+	// Closing the session ends the socket.
+	doJSON(t, "POST", ts.URL+"/api/sessions/"+name+"/close", "")
+	for {
+		if f := readFrame(t, ws); f["type"] == "closed" {
+			break
+		}
+	}
+}
+
+func TestSessionSocketAuth(t *testing.T) {
+	// Token-less loopback: a cross-origin page can't open the socket.
+	ts := newTestServer(t, "")
+	_, out := doJSON(t, "POST", ts.URL+"/api/sessions", `{"adventure_id":"crypt"}`)
+	if _, err := dialSession(t, ts.URL, out["name"].(string), "", "http://evil.example.com"); err == nil {
+		t.Error("a cross-origin socket was accepted")
+	}
+
+	// With a token the socket needs a ticket.
+	ts = newTestServer(t, "s3cret")
+	authed := func(method, path string) map[string]any {
+		req, _ := http.NewRequest(method, ts.URL+path, strings.NewReader(`{"adventure_id":"crypt"}`))
+		req.Header.Set("Authorization", "Bearer s3cret")
+		req.Header.Set("Content-Type", "application/json")
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		var m map[string]any
+		_ = json.NewDecoder(resp.Body).Decode(&m)
+		return m
+	}
+	name := authed("POST", "/api/sessions")["name"].(string)
+	if _, err := dialSession(t, ts.URL, name, "", ts.URL); err == nil {
+		t.Error("a socket without a ticket was accepted")
+	}
+	ticket := authed("POST", "/api/sse-ticket")["ticket"].(string)
+	ws, err := dialSession(t, ts.URL, name, "ticket="+ticket, ts.URL)
+	if err != nil {
+		t.Fatalf("dial with a ticket: %v", err)
+	}
+	ws.Close()
+}
@@ -0,0 +1,347 @@
+// Package ircbot is the IRC adapter of the multiplayer chat host
+// (internal/chathost): players play a virtual-DM session from an IRC channel,
+// alone or at the same table as players on other platforms.
+//
+// IRC clients treat a leading "/" as their own command, so players type the
+// host's commands with "!" instead (!pick, !do, !dm), and the adapter rewrites
+// the "/pick"-style mentions in what it posts to match. IRC has no images or
+// spoilers: a picture is announced by its caption, and spoilered text is sent
+// black on black, readable when selected.
+package ircbot
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/theburrowhub/thaimaturgy/internal/chathost"
+)
+
+// Options configures an Adapter.
+type Options struct {
+	Addr     string // server host:port (required)
+	TLS      bool   // connect over TLS
+	Nick     string // the bot's nickname (required); "_" is appended while it's taken
+	Password string // server password (PASS), if the server wants one
+	Channel  string // the channel to play in, e.g. "#thaimaturgy" (required)
+	Key      string // the channel key, if the channel has one (+k)
+	// Throttle is the pause between lines sent, keeping the bot under the
+	// server's flood limit (0 = 500ms).
+	Throttle time.Duration
+}
+
+// lineLimit is the most text sent in one PRIVMSG, leaving room in the 512-byte
+// IRC line for the prefix the server adds when relaying it.
+const lineLimit = 400
+
+// retryDelay is how long Receive waits before reconnecting after the
+// connection drops.
+const retryDelay = 15 * time.Second
+
+// Adapter connects a chathost.Host to an IRC channel. It plays only in
+// Options.Channel: private messages are ignored, since anyone on the network
+// could send them.
+type Adapter struct {
+	opts Options
+
+	mu   sync.Mutex // guards conn
+	conn net.Conn
+
+	sendMu sync.Mutex // serializes PRIVMSGs so their lines don't interleave
+	last   time.Time  // when the last PRIVMSG line was written; guarded by sendMu
+
+	stop     chan struct{}
+	stopOnce sync.Once
+}
+
+// New checks opts and returns an Adapter; it connects when Receive runs.
+func New(opts Options) (*Adapter, error) {
+	if strings.TrimSpace(opts.Addr) == "" || strings.TrimSpace(opts.Nick) == "" {
+		return nil, errors.New("IRC needs a server address and a nickname")
+	}
+	if !strings.HasPrefix(opts.Channel, "#") && !strings.HasPrefix(opts.Channel, "&") {
+		return nil, fmt.Errorf("IRC channel %q must start with # or &", opts.Channel)
+	}
+	if opts.Throttle == 0 {
+		opts.Throttle = 500 * time.Millisecond
+	}
+	return &Adapter{opts: opts, stop: make(chan struct{})}, nil
+}
+
+// Platform implements chathost.Adapter.
+func (a *Adapter) Platform() string { return "IRC" }
+
+// Home is the configured channel.
+func (a *Adapter) Home() string { return a.opts.Channel }
+
+// Receive connects, registers and joins the channel, and delivers the channel's
+// messages to fn until ctx is cancelled or Stop is called, reconnecting when the
+// connection drops.
+func (a *Adapter) Receive(ctx context.Context, fn func(chathost.Message)) {
+	for {
+		err := a.session(ctx, fn)
+		select {
+		case <-ctx.Done():
+			return
+		case <-a.stop:
+			return
+		default:
+		}
+		log.Printf("irc: %v; reconnecting in %s", err, retryDelay)
+		select {
+		case <-ctx.Done():
+			return
+		case <-a.stop:
+			return
+		case <-time.After(retryDelay):
+		}
+	}
+}
+
+// Stop ends Receive and closes the connection.
+func (a *Adapter) Stop() {
+	a.stopOnce.Do(func() { close(a.stop) })
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.conn != nil {
+		a.conn.Close()
+	}
+}
+
+// session runs one connection until it fails or is closed.
+func (a *Adapter) session(ctx context.Context, fn func(chathost.Message)) error {
+	conn, err := a.dial(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	a.mu.Lock()
+	a.conn = conn
+	a.mu.Unlock()
+	defer func() {
+		a.mu.Lock()
+		a.conn = nil
+		a.mu.Unlock()
+	}()
+	// Unblock the read below when the host goes away.
+	unwatch := context.AfterFunc(ctx, func() { conn.Close() })
+	defer unwatch()
+	select {
+	case <-a.stop:
+		return nil // stopped while dialing
+	default:
+	}
+
+	nick := a.opts.Nick
+	if a.opts.Password != "" {
+		a.write("PASS " + a.opts.Password)
+	}
+	a.write("NICK " + nick)
+	a.write("USER " + a.opts.Nick + " 0 * :thAImaturgy DM")
+
+	r := bufio.NewReader(conn)
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return err
+		}
+		prefix, cmd, params := parseLine(strings.TrimRight(line, "\r\n"))
+		switch cmd {
+		case "PING":
+			a.write("PONG :" + strings.Join(params, " "))
+		case "433": // nickname in use
+			nick += "_"
+			a.write("NICK " + nick)
+		case "001": // registered
+			join := "JOIN " + a.opts.Channel
+			if a.opts.Key != "" {
+				join += " " + a.opts.Key
+			}
+			a.write(join)
+			log.Printf("thaimaturgy-bot online on IRC as %s in %s", nick, a.opts.Channel)
+		case "PRIVMSG":
+			if len(params) < 2 || !strings.EqualFold(params[0], a.opts.Channel) {
+				continue // a private message, or another channel
+			}
+			if m, ok := message(prefix, params[0], params[1]); ok {
+				fn(m)
+			}
+		}
+	}
+}
+
+func (a *Adapter) dial(ctx context.Context) (net.Conn, error) {
+	d := &net.Dialer{Timeout: 30 * time.Second}
+	if a.opts.TLS {
+		host, _, _ := net.SplitHostPort(a.opts.Addr)
+		td := &tls.Dialer{NetDialer: d, Config: &tls.Config{ServerName: host}}
+		return td.DialContext(ctx, "tcp", a.opts.Addr)
+	}
+	return d.DialContext(ctx, "tcp", a.opts.Addr)
+}
+
+// message turns a channel PRIVMSG into a chathost.Message. Commands start with
+// "!"; CTCP requests (/me actions and the like) are skipped.
+func message(prefix, channel, text string) (chathost.Message, bool) {
+	nick, _, _ := strings.Cut(prefix, "!")
+	if nick == "" || strings.HasPrefix(text, "\x01") {
+		return chathost.Message{}, false
+	}
+	// IRC nicks are the only identity the protocol carries, and they're
+	// case-insensitive.
+	m := chathost.Message{
+		Chat: channel,
+		From: chathost.User{ID: "irc:" + strings.ToLower(nick), Name: nick, Username: nick},
+	}
+	if cmd, ok := strings.CutPrefix(strings.TrimSpace(text), "!"); ok && cmd != "" {
+		cmd, args, _ := strings.Cut(cmd, " ")
+		m.Command, m.Args = strings.ToLower(cmd), strings.TrimSpace(args)
+	}
+	return m, true
+}
+
+// parseLine splits an IRC line into its prefix (without the colon), command and
+// parameters, the last of which may contain spaces.
+func parseLine(line string) (prefix, cmd string, params []string) {
+	if rest, ok := strings.CutPrefix(line, ":"); ok {
+		prefix, line, _ = strings.Cut(rest, " ")
+	}
+	cmd, line, _ = strings.Cut(line, " ")
+	for line != "" {
+		if trailing, ok := strings.CutPrefix(line, ":"); ok {
+			params = append(params, trailing)
+			break
+		}
+		var p string
+		p, line, _ = strings.Cut(line, " ")
+		if p != "" {
+			params = append(params, p)
+		}
+	}
+	return prefix, strings.ToUpper(cmd), params
+}
+
+// write sends one raw line, returning an error when there's no connection.
+func (a *Adapter) write(line string) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.conn == nil {
+		return errors.New("not connected to IRC")
+	}
+	_, err := a.conn.Write([]byte(line + "\r\n"))
+	return err
+}
+
+// privmsg sends each line to chat, paced by Options.Throttle.
+func (a *Adapter) privmsg(chat string, lines []string) error {
+	a.sendMu.Lock()
+	defer a.sendMu.Unlock()
+	for _, l := range lines {
+		if wait := time.Until(a.last.Add(a.opts.Throttle)); wait > 0 {
+			select {
+			case <-a.stop:
+				return errors.New("IRC adapter stopped")
+			case <-time.After(wait):
+			}
+		}
+		if err := a.write("PRIVMSG " + chat + " :" + l); err != nil {
+			return err
+		}
+		a.last = time.Now()
+	}
+	return nil
+}
+
+// Send posts text to a channel, a line at a time.
+func (a *Adapter) Send(chat, text string) error {
+	return a.privmsg(chat, ircLines(bangCommands(text)))
+}
+
+// SendImage announces a picture by its caption; IRC can't carry images.
+func (a *Adapter) SendImage(chat, _, caption string) error {
+	return a.Send(chat, caption+" (the picture can't be shown on IRC)")
+}
+
+// SendSpoiler sends body black on black under a bold heading, so it reads only
+// once selected.
+func (a *Adapter) SendSpoiler(chat, heading, body string) error {
+	var lines []string
+	if heading = strings.TrimSpace(heading); heading != "" {
+		lines = append(lines, "\x02"+bangCommands(heading)+"\x02")
+	}
+	for _, l := range ircLines(bangCommands(body)) {
+		lines = append(lines, "\x0301,01"+l+"\x0f")
+	}
+	return a.privmsg(chat, lines)
+}
+
+// ircLines breaks text into lines IRC can send: no line breaks (a stray CR would
+// end the PRIVMSG and start a raw command), none blank, and none longer than
+// lineLimit bytes (cut at a space where possible, never mid-rune).
+func ircLines(text string) []string {
+	var out []string
+	for _, l := range strings.Split(text, "\n") {
+		l = strings.TrimRight(strings.NewReplacer("\r", "", "\x00", "").Replace(l), " \t")
+		for len(l) > lineLimit {
+			cut := strings.LastIndex(l[:lineLimit], " ")
+			if cut <= 0 {
+				cut = lineLimit
+				for cut > 0 && !utf8.RuneStart(l[cut]) {
+					cut--
+				}
+			}
+			out = append(out, l[:cut])
+			l = strings.TrimLeft(l[cut:], " ")
+		}
+		if l != "" {
+			out = append(out, l)
+		}
+	}
+	return out
+}
+
+// bangCommands rewrites command mentions the host words for slash commands
+// ("/pick <name>", "(/glossary, /who)") to IRC's "!" form. A slash counts only
+// at the start of a word and before a bare lowercase word, so "and/or" and
+// paths like "/srv/data.json" are left alone.
+func bangCommands(text string) string {
+	b := []byte(text)
+	for i := 0; i < len(b); i++ {
+		if b[i] != '/' {
+			continue
+		}
+		if i > 0 && !strings.ContainsRune(" \t\n(\"'", rune(b[i-1])) && !bytes.HasSuffix(b[:i], []byte("“")) {
+			continue
+		}
+		j := i + 1
+		for j < len(b) && b[j] >= 'a' && b[j] <= 'z' {
+			j++
+		}
+		if j == i+1 || (j < len(b) && !wordEnd(b[j:])) {
+			continue
+		}
+		b[i] = '!'
+	}
+	return string(b)
+}
+
+// wordEnd reports whether rest, what follows a lowercase word, ends it: a space,
+// punctuation, or a full stop that ends the sentence — not more of a name.
+func wordEnd(rest []byte) bool {
+	switch c := rest[0]; {
+	case c == '.':
+		return len(rest) == 1 || rest[1] == ' ' || rest[1] == '\n'
+	case c == '/' || c == '_' || c == '-' || c >= '0' && c <= '9' || c >= 'A' && c <= 'Z':
+		return false
+	}
+	return true
+}
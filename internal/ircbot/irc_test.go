@@ -0,0 +1,149 @@
+package ircbot
+
+import (
+	"bufio"
+	"context"
+	"net"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/theburrowhub/thaimaturgy/internal/chathost"
+)
+
+// testServer is the server end of one client connection to a local IRC server.
+type testServer struct {
+	t    *testing.T
+	conn net.Conn
+	r    *bufio.Reader
+}
+
+func (s *testServer) send(line string) {
+	s.t.Helper()
+	if _, err := s.conn.Write([]byte(line + "\r\n")); err != nil {
+		s.t.Fatal(err)
+	}
+}
+
+// expect reads the client's next line and checks it is want.
+func (s *testServer) expect(want string) {
+	s.t.Helper()
+	_ = s.conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	line, err := s.r.ReadString('\n')
+	if err != nil {
+		s.t.Fatalf("waiting for %q: %v", want, err)
+	}
+	if got := strings.TrimRight(line, "\r\n"); got != want {
+		s.t.Fatalf("client sent %q; want %q", got, want)
+	}
+}
+
+func TestAdapterAgainstLocalServer(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	a, err := New(Options{Addr: ln.Addr().String(), Nick: "dm", Channel: "#table", Throttle: time.Millisecond})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	got := make(chan chathost.Message, 4)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		a.Receive(ctx, func(m chathost.Message) { got <- m })
+	}()
+
+	conn, err := ln.Accept()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	s := &testServer{t: t, conn: conn, r: bufio.NewReader(conn)}
+	s.expect("NICK dm")
+	s.expect("USER dm 0 * :thAImaturgy DM")
+	s.send(":irc.test 433 * dm :Nickname is already in use")
+	s.expect("NICK dm_")
+	s.send(":irc.test 001 dm_ :Welcome")
+	s.expect("JOIN #table")
+	s.send("PING :irc.test")
+	s.expect("PONG :irc.test")
+
+	s.send(":Eve!e@h PRIVMSG dm_ :!dm") // private: ignored
+	s.send(":Bob!b@h PRIVMSG #Table :\x01ACTION waves\x01")
+	s.send(":Bob!b@h PRIVMSG #Table :!Do I knock on the door")
+	s.send(":Bob!b@h PRIVMSG #table :nice")
+	var m chathost.Message
+	for _, want := range []chathost.Message{
+		{Chat: "#Table", From: chathost.User{ID: "irc:bob", Name: "Bob", Username: "Bob"}, Command: "do", Args: "I knock on the door"},
+		{Chat: "#table", From: chathost.User{ID: "irc:bob", Name: "Bob", Username: "Bob"}},
+	} {
+		select {
+		case m = <-got:
+		case <-time.After(5 * time.Second):
+			t.Fatal("no message delivered")
+		}
+		if m.Chat != want.Chat || m.From != want.From || m.Command != want.Command || m.Args != want.Args {
+			t.Errorf("delivered %+v; want %+v", m, want)
+		}
+	}
+
+	if err := a.Send("#table", "Pick with /pick <name>,\n\nthen /do."); err != nil {
+		t.Fatal(err)
+	}
+	s.expect("PRIVMSG #table :Pick with !pick <name>,")
+	s.expect("PRIVMSG #table :then !do.")
+	if err := a.SendSpoiler("#table", "Possible actions:", "- Step inside"); err != nil {
+		t.Fatal(err)
+	}
+	s.expect("PRIVMSG #table :\x02Possible actions:\x02")
+	s.expect("PRIVMSG #table :\x0301,01- Step inside\x0f")
+	if err := a.SendImage("#table", "/maps/crypt.png", "🗺 Crypt"); err != nil {
+		t.Fatal(err)
+	}
+	s.expect("PRIVMSG #table :🗺 Crypt (the picture can't be shown on IRC)")
+
+	a.Stop()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Receive didn't return after Stop")
+	}
+	if err := a.Send("#table", "anyone?"); err == nil {
+		t.Error("Send after Stop succeeded")
+	}
+}
+
+func TestIRCLines(t *testing.T) {
+	if got := ircLines("one\r\n\ntwo\rQUIT"); len(got) != 2 || got[0] != "one" || got[1] != "twoQUIT" {
+		t.Errorf("ircLines = %q", got)
+	}
+	long := strings.TrimSpace(strings.Repeat("acción-ñoño ", 100)) + strings.Repeat("ñ", 300)
+	lines := ircLines(long)
+	if len(lines) < 3 {
+		t.Fatalf("expected the line to be cut, got %d piece(s)", len(lines))
+	}
+	for i, l := range lines {
+		if len(l) > lineLimit || !utf8.ValidString(l) {
+			t.Errorf("piece %d is %d bytes, valid UTF-8 %v", i, len(l), utf8.ValidString(l))
+		}
+	}
+}
+
+func TestBangCommands(t *testing.T) {
+	for in, want := range map[string]string{
+		"/pick <name>, then /do.":          "!pick <name>, then !do.",
+		"/glosario (/glossary, /who) — x":  "!glosario (!glossary, !who) — x",
+		"or “/do <name>: …”":               "or “!do <name>: …”",
+		"and/or 1/2 /srv/data.json /Users": "and/or 1/2 /srv/data.json /Users",
+		"open /data.json: denied":          "open /data.json: denied",
+	} {
+		if got := bangCommands(in); got != want {
+			t.Errorf("bangCommands(%q) = %q; want %q", in, got, want)
+		}
+	}
+}
@@ -21,6 +21,9 @@ type scriptProvider struct {
 func (p *scriptProvider) Name() string         { return "script" }
 func (p *scriptProvider) SupportsTools() bool  { return false }
 func (p *scriptProvider) SupportsVision() bool { return false }
+func (p *scriptProvider) ChatStream(ctx context.Context, req providers.ChatRequest, _ providers.StreamFunc) (*providers.ChatResponse, error) {
+	return p.Chat(ctx, req)
+}
 func (p *scriptProvider) Chat(_ context.Context, req providers.ChatRequest) (*providers.ChatResponse, error) {
 	p.calls++
 	var users []string
@@ -0,0 +1,206 @@
+// Package playtest runs an adventure headless: simulated players, each driven
+// by a model, declare actions for a virtual-DM session round after round, and
+// the run ends with a coverage report of what the party reached — rooms,
+// events, NPCs, scenes — and where it got stuck or the DM's tools failed.
+//
+// Stub answers for the DM and the players alike, so a run needs no model and
+// no network: a smoke test for a module's structure rather than its prose.
+package playtest
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/theburrowhub/thaimaturgy/internal/domain"
+	"github.com/theburrowhub/thaimaturgy/internal/engine"
+	"github.com/theburrowhub/thaimaturgy/internal/providers"
+)
+
+// Defaults for a run's Options.
+const (
+	DefaultRounds  = 10
+	DefaultPlayers = 2
+	// stallRounds is how many rounds in a row the party may go without reaching
+	// anything new before the stretch is reported as a dead end.
+	stallRounds = 3
+	// maxFailedTurns aborts a run whose DM keeps failing: the provider is down
+	// or the budget spent, and more rounds would only repeat the error.
+	maxFailedTurns = 3
+	// narrationWindow is how many of the DM's latest replies a simulated player
+	// is shown.
+	narrationWindow = 3
+)
+
+// Options tune a run.
+type Options struct {
+	Rounds  int // rounds to play after the opening (0 = DefaultRounds)
+	Players int // simulated players, one character each (0 = DefaultPlayers)
+
+	// PlayerProvider and PlayerModel are what the simulated players think
+	// with; a nil provider uses the DM's, with the session's model.
+	PlayerProvider providers.Provider
+	PlayerModel    string
+
+	// Progress, when set, is told about each round as it is resolved.
+	Progress func(round int, actions []domain.RoundAction, narration string)
+}
+
+// Run plays session for opts.Rounds rounds with dm as the DM's provider and
+// reports the coverage. The session is switched to virtual-DM mode and given
+// its default party if it has none; it is played in place, so the caller can
+// save it afterwards. An error means the run could not start or was cut short
+// by ctx; failed turns are part of the report.
+func Run(ctx context.Context, session *domain.Session, dm providers.Provider, opts Options) (*Report, error) {
+	if dm == nil {
+		return nil, fmt.Errorf("no AI provider configured")
+	}
+	if opts.Rounds <= 0 {
+		opts.Rounds = DefaultRounds
+	}
+	if opts.Players <= 0 {
+		opts.Players = DefaultPlayers
+	}
+	if opts.PlayerProvider == nil {
+		opts.PlayerProvider = dm
+		if opts.PlayerModel == "" {
+			opts.PlayerModel = session.Config.Model
+		}
+	}
+
+	st := session.State
+	st.SetMode(domain.ModeVirtualDM)
+	st.EnsureParty()
+	party := st.PartySnapshot()
+	if len(party) == 0 {
+		return nil, fmt.Errorf("the session has no party")
+	}
+	if opts.Players > len(party) {
+		opts.Players = len(party)
+	}
+	players := make([]*player, opts.Players)
+	for i := range players {
+		p := &player{id: fmt.Sprintf("playtest-%d", i+1), name: fmt.Sprintf("Player %d", i+1), character: party[i]}
+		if _, err := st.ClaimCharacter(p.id, p.name, p.character.Name); err != nil {
+			return nil, fmt.Errorf("seat %s: %w", p.name, err)
+		}
+		players[i] = p
+	}
+
+	t := newTracker(session)
+	oracle := engine.NewOracle(session, dm)
+	var narration []string
+	failed := 0
+	turn := func(round int, resp *engine.Response) {
+		if resp.Error != nil {
+			t.turnFailed(round, resp.Error)
+			failed++
+			return
+		}
+		failed = 0
+		narration = append(narration, resp.Answer)
+		if len(narration) > narrationWindow {
+			narration = narration[len(narration)-narrationWindow:]
+		}
+	}
+
+	if st.StartGame() {
+		turn(0, oracle.AskStream(ctx, domain.DMKickoffPrompt(session.Config.Language), t.events(0)))
+		t.endRound(0)
+	}
+	for round := 1; round <= opts.Rounds && failed < maxFailedTurns; round++ {
+		if err := ctx.Err(); err != nil {
+			return t.report(round - 1), err
+		}
+		for _, p := range players {
+			action, err := p.act(ctx, opts.PlayerProvider, opts.PlayerModel, session, narration, st.RoundActions())
+			if err != nil {
+				t.playerFailed(round, p.name, err)
+				continue
+			}
+			if _, err := st.SubmitAction(p.id, "", action); err != nil {
+				t.playerFailed(round, p.name, err)
+			}
+		}
+		actions := st.RoundActions()
+		if len(actions) == 0 {
+			failed++
+			continue
+		}
+		resp := oracle.RunGroupTurnStream(ctx, t.events(round))
+		turn(round, resp)
+		t.endRound(round)
+		if opts.Progress != nil {
+			opts.Progress(round, actions, resp.Answer)
+		}
+	}
+	rounds := opts.Rounds
+	if failed >= maxFailedTurns {
+		rounds = t.lastRound
+	}
+	return t.report(rounds), nil
+}
+
+// player is a simulated player seated at one character.
+type player struct {
+	id, name  string
+	character domain.Character
+}
+
+// act asks the player's model what their character does next.
+func (p *player) act(ctx context.Context, prov providers.Provider, model string, session *domain.Session, narration []string, declared []domain.RoundAction) (string, error) {
+	c := p.character
+	var sys strings.Builder
+	fmt.Fprintf(&sys, "%s\nYou play %s, a level %d %s %s, in %q.", playerPromptHeader, c.Name, c.Level, c.Race, c.Class, session.Adventure.Title)
+	if session.Config.Language == domain.LangSpanish {
+		sys.WriteString(" Write your action in Spanish.")
+	}
+	var user strings.Builder
+	if len(narration) == 0 {
+		user.WriteString("The game is about to begin.\n")
+	} else {
+		user.WriteString("The DM's latest narration:\n\n")
+		user.WriteString(strings.Join(narration, "\n\n---\n\n"))
+		user.WriteString("\n")
+	}
+	for _, a := range declared {
+		fmt.Fprintf(&user, "\n%s has already declared: %s", a.CharacterName, a.Text)
+	}
+	fmt.Fprintf(&user, "\n\nWhat does %s do now?", c.Name)
+
+	resp, err := prov.Chat(ctx, providers.ChatRequest{
+		Model: model,
+		Messages: []providers.Message{
+			{Role: providers.RoleSystem, Content: sys.String()},
+			{Role: providers.RoleUser, Content: user.String()},
+		},
+		Temperature: 0.9,
+		MaxTokens:   200,
+	})
+	if err != nil {
+		return "", err
+	}
+	name := prov.Name()
+	if resp.Provider != "" {
+		name, model = resp.Provider, resp.Model
+	}
+	if model == "" {
+		model = resp.Model
+	}
+	session.State.RecordUsage(domain.UsageRecord{
+		Provider:         name,
+		Model:            model,
+		Purpose:          domain.PurposePlaytest,
+		PromptTokens:     resp.Usage.PromptTokens,
+		CompletionTokens: resp.Usage.CompletionTokens,
+		TotalTokens:      resp.Usage.TotalTokens,
+	})
+	return strings.TrimSpace(resp.Content), nil
+}
+
+// playerPromptHeader opens every simulated player's system prompt; Stub
+// recognises a player's request by it.
+const playerPromptHeader = "You are a player at a tabletop role-playing game, not the game master. " +
+	"Each round, declare what your character does next: one or two sentences in the first person, " +
+	"in character, with no narration of the outcome. Explore, talk to people, and pursue the adventure's goal; " +
+	"don't repeat an action that got nowhere."
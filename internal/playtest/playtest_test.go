@@ -0,0 +1,106 @@
+package playtest
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/theburrowhub/thaimaturgy/internal/domain"
+)
+
+// keepAdventure has a yard leading to a hall, a cellar reachable from nowhere,
+// and a scene transition to a scene that was never written.
+func keepAdventure() *domain.Adventure {
+	return &domain.Adventure{
+		SchemaVersion: domain.SchemaVersion,
+		ID:            "keep", Title: "The Keep",
+		StartRoom: "yard",
+		Zones: []domain.Zone{
+			{ID: "outer", Name: "Outer Ward", Rooms: []domain.Room{
+				{ID: "yard", Name: "Yard", NPCIDs: []string{"warden"}, Exits: []domain.Exit{{To: "hall", Direction: "north"}}},
+				{ID: "hall", Name: "Great Hall", EventIDs: []string{"feast"}, Exits: []domain.Exit{{To: "yard", Direction: "south"}}},
+			}},
+			{ID: "below", Name: "Undercroft", Rooms: []domain.Room{{ID: "cellar", Name: "Cellar"}}},
+		},
+		NPCs:   []domain.NPC{{ID: "warden", Name: "Warden Hask"}, {ID: "ghost", Name: "The Grey Lady"}},
+		Events: []domain.Event{{ID: "feast", Name: "The Feast"}, {ID: "fire", Name: "Fire in the Stables"}},
+		Scenes: []domain.Scene{
+			{ID: "arrival", Name: "Arrival", Initial: true, Next: []domain.SceneTransition{{To: "siege"}}},
+		},
+	}
+}
+
+func TestRunWithStub(t *testing.T) {
+	adv := keepAdventure()
+	session := domain.NewSession(domain.NewSessionState("playtest", adv), adv, domain.DefaultConfig())
+	var rounds int
+	rep, err := Run(context.Background(), session, NewStub(adv), Options{
+		Rounds:   7,
+		Progress: func(int, []domain.RoundAction, string) { rounds++ },
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rounds != 7 || rep.Rounds != 7 || rep.Players != DefaultPlayers {
+		t.Errorf("rounds = %d (reported %d), players = %d", rounds, rep.Rounds, rep.Players)
+	}
+	if len(rep.Rooms.Reached) != 3 || rep.Rooms.Total != 3 {
+		t.Errorf("rooms = %+v", rep.Rooms)
+	}
+	if len(rep.Zones) != 2 || rep.Zones[1].Visited != 1 {
+		t.Errorf("zones = %+v", rep.Zones)
+	}
+	if len(rep.Events.Missed) != 1 || rep.Events.Missed[0].ID != "fire" {
+		t.Errorf("events = %+v", rep.Events)
+	}
+	if len(rep.NPCs.Reached) != 1 || rep.NPCs.Missed[0].ID != "ghost" {
+		t.Errorf("npcs = %+v", rep.NPCs)
+	}
+	if len(rep.Scenes.Reached) != 1 || rep.Scenes.Total != 1 {
+		t.Errorf("scenes = %+v", rep.Scenes)
+	}
+	if len(rep.ToolErrors) != 1 || rep.ToolErrors[0].Tool != "set_scene" || rep.ToolErrors[0].Round != 0 {
+		t.Errorf("tool errors = %+v", rep.ToolErrors)
+	}
+	// With every room seen by round 2, the rest of the run is a dead end.
+	if len(rep.DeadEnds) != 1 || rep.DeadEnds[0].FromRound != 3 || rep.DeadEnds[0].ToRound != 7 || rep.DeadEnds[0].Room.ID != "cellar" {
+		t.Errorf("dead ends = %+v", rep.DeadEnds)
+	}
+	if len(rep.Failures) != 0 {
+		t.Errorf("failures = %+v", rep.Failures)
+	}
+
+	// The players' actions went through the round buffer into the DM's turns.
+	var actions int
+	for _, m := range session.State.Conversation.Messages {
+		if m.Role == domain.RoleUser && strings.Contains(m.Content, "I look around carefully") {
+			actions++
+		}
+	}
+	if actions == 0 {
+		t.Error("no simulated player action reached the DM")
+	}
+
+	md := rep.Markdown()
+	for _, want := range []string{"# Playtest: The Keep", "| Rooms | 3 | 3 |", "Fire in the Stables (`fire`)", "## Dead ends", "`set_scene`: 1"} {
+		if !strings.Contains(md, want) {
+			t.Errorf("report missing %q:\n%s", want, md)
+		}
+	}
+}
+
+func TestRunStopsWhenTheDMKeepsFailing(t *testing.T) {
+	adv := keepAdventure()
+	cfg := domain.DefaultConfig()
+	cfg.SessionBudget.Hard = 1
+	session := domain.NewSession(domain.NewSessionState("playtest", adv), adv, cfg)
+	session.State.RecordUsage(domain.UsageRecord{Provider: "stub", Purpose: domain.PurposeOracle, TotalTokens: 10})
+
+	rep, err := Run(context.Background(), session, NewStub(adv), Options{Rounds: 20})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rep.Rounds != maxFailedTurns-1 || len(rep.Failures) != maxFailedTurns {
+		t.Errorf("rounds = %d, failures = %+v", rep.Rounds, rep.Failures)
+	}
+}
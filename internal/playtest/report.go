@@ -0,0 +1,282 @@
+package playtest
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/theburrowhub/thaimaturgy/internal/domain"
+	"github.com/theburrowhub/thaimaturgy/internal/engine"
+)
+
+// Report is what a playtest reached of the adventure, and what went wrong on
+// the way. Coverage is the session's progress at the end of the run, so a
+// resumed session counts what it had reached before.
+type Report struct {
+	Adventure string `json:"adventure"`
+	Rounds    int    `json:"rounds"` // rounds played, the opening not counted
+	Players   int    `json:"players"`
+
+	Rooms  Coverage       `json:"rooms"`
+	Zones  []ZoneCoverage `json:"zones,omitempty"`
+	Events Coverage       `json:"events"`
+	NPCs   Coverage       `json:"npcs"`
+	Scenes Coverage       `json:"scenes"`
+
+	DeadEnds   []DeadEnd   `json:"dead_ends,omitempty"`
+	ToolErrors []ToolError `json:"tool_errors,omitempty"`
+	Failures   []Failure   `json:"failures,omitempty"`
+
+	Tokens int `json:"tokens"`
+}
+
+// Coverage splits an adventure's rooms (or events, NPCs, scenes) into those
+// the party reached and those it missed.
+type Coverage struct {
+	Total   int   `json:"total"`
+	Reached []Ref `json:"reached,omitempty"`
+	Missed  []Ref `json:"missed,omitempty"`
+}
+
+// Ref names a piece of the adventure.
+type Ref struct {
+	ID   string `json:"id"`
+	Name string `json:"name,omitempty"`
+}
+
+// ZoneCoverage counts a zone's visited rooms.
+type ZoneCoverage struct {
+	Ref
+	Visited int `json:"visited"`
+	Rooms   int `json:"rooms"`
+}
+
+// DeadEnd is a stretch of rounds in which the party reached nothing new.
+type DeadEnd struct {
+	Room      Ref `json:"room"`
+	FromRound int `json:"from_round"`
+	ToRound   int `json:"to_round"`
+}
+
+// ToolError is a DM tool call that failed.
+type ToolError struct {
+	Round int    `json:"round"`
+	Tool  string `json:"tool"`
+	Error string `json:"error"`
+}
+
+// Failure is a DM turn, or a simulated player's action, that failed outright.
+type Failure struct {
+	Round int    `json:"round"`
+	Who   string `json:"who"` // "DM" or the player's name
+	Error string `json:"error"`
+}
+
+// tracker follows a run round by round.
+type tracker struct {
+	session    *domain.Session
+	scenes     map[string]bool
+	toolErrors []ToolError
+	failures   []Failure
+	deadEnds   []DeadEnd
+	lastRound  int
+
+	progress int // things reached at the end of the previous round
+
+	// The current stretch of rounds without progress: its first round, the
+	// room it started in, and its length so far.
+	stallFrom  int
+	stallRoom  string
+	stallCount int
+}
+
+func newTracker(session *domain.Session) *tracker {
+	t := &tracker{session: session, scenes: map[string]bool{}}
+	t.progress = t.reached()
+	return t
+}
+
+// events returns the oracle event callback for a round, collecting the tools
+// that failed.
+func (t *tracker) events(round int) engine.EventFunc {
+	return func(e engine.Event) {
+		if e.Kind == engine.EventTool && e.Text != "" {
+			t.toolErrors = append(t.toolErrors, ToolError{Round: round, Tool: e.Tool, Error: e.Text})
+		}
+	}
+}
+
+func (t *tracker) turnFailed(round int, err error) {
+	t.failures = append(t.failures, Failure{Round: round, Who: "DM", Error: err.Error()})
+}
+
+func (t *tracker) playerFailed(round int, name string, err error) {
+	t.failures = append(t.failures, Failure{Round: round, Who: name, Error: err.Error()})
+}
+
+// reached counts what the party has reached so far, noting the current scene.
+func (t *tracker) reached() int {
+	st := t.session.State
+	if sc := st.Scene(); sc != "" {
+		t.scenes[sc] = true
+	}
+	met := 0
+	for _, n := range st.KnownNPCs {
+		if n != nil && n.Met {
+			met++
+		}
+	}
+	return len(st.VisitedRooms) + len(st.TriggeredEvents) + met + len(t.scenes)
+}
+
+// endRound checks the round for progress, closing or extending a stretch
+// without any.
+func (t *tracker) endRound(round int) {
+	t.lastRound = round
+	now := t.reached()
+	if now > t.progress {
+		t.progress = now
+		t.closeStall()
+		return
+	}
+	if t.stallCount == 0 {
+		t.stallFrom = round
+		_, t.stallRoom = t.session.State.Location()
+	}
+	t.stallCount++
+}
+
+func (t *tracker) closeStall() {
+	if t.stallCount >= stallRounds {
+		t.deadEnds = append(t.deadEnds, DeadEnd{Room: t.roomRef(t.stallRoom), FromRound: t.stallFrom, ToRound: t.stallFrom + t.stallCount - 1})
+	}
+	t.stallCount = 0
+}
+
+func (t *tracker) roomRef(id string) Ref {
+	if r, _ := t.session.Adventure.Room(id); r != nil {
+		return Ref{ID: r.ID, Name: r.Name}
+	}
+	return Ref{ID: id}
+}
+
+func (t *tracker) report(rounds int) *Report {
+	t.reached()
+	t.closeStall()
+	adv, st := t.session.Adventure, t.session.State
+	rep := &Report{
+		Adventure:  adv.Title,
+		Rounds:     rounds,
+		Players:    st.PlayerCount(),
+		DeadEnds:   t.deadEnds,
+		ToolErrors: t.toolErrors,
+		Failures:   t.failures,
+		Tokens:     st.TokensUsed(),
+	}
+	for _, z := range adv.Zones {
+		zc := ZoneCoverage{Ref: Ref{ID: z.ID, Name: z.Name}, Rooms: len(z.Rooms)}
+		for _, r := range z.Rooms {
+			rep.Rooms.add(r.ID, r.Name, st.VisitedRooms[r.ID])
+			if st.VisitedRooms[r.ID] {
+				zc.Visited++
+			}
+		}
+		rep.Zones = append(rep.Zones, zc)
+	}
+	for _, e := range adv.Events {
+		rep.Events.add(e.ID, e.Name, st.TriggeredEvents[e.ID])
+	}
+	for _, n := range adv.NPCs {
+		status := st.KnownNPCs[n.ID]
+		rep.NPCs.add(n.ID, n.Name, status != nil && status.Met)
+	}
+	for _, sc := range adv.Scenes {
+		rep.Scenes.add(sc.ID, sc.Name, t.scenes[sc.ID])
+	}
+	return rep
+}
+
+func (c *Coverage) add(id, name string, reached bool) {
+	c.Total++
+	if reached {
+		c.Reached = append(c.Reached, Ref{ID: id, Name: name})
+	} else {
+		c.Missed = append(c.Missed, Ref{ID: id, Name: name})
+	}
+}
+
+// Markdown renders the report for a person to read.
+func (r *Report) Markdown() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "# Playtest: %s\n\n", r.Adventure)
+	fmt.Fprintf(&sb, "%d rounds, %d simulated players, %d tokens.\n\n", r.Rounds, r.Players, r.Tokens)
+
+	sb.WriteString("## Coverage\n\n| | Reached | Total |\n|---|---|---|\n")
+	for _, row := range []struct {
+		label string
+		c     Coverage
+	}{{"Rooms", r.Rooms}, {"Events", r.Events}, {"NPCs", r.NPCs}, {"Scenes", r.Scenes}} {
+		if row.c.Total > 0 {
+			fmt.Fprintf(&sb, "| %s | %d | %d |\n", row.label, len(row.c.Reached), row.c.Total)
+		}
+	}
+	if len(r.Zones) > 0 {
+		sb.WriteString("\n### Rooms visited by zone\n\n")
+		for _, z := range r.Zones {
+			fmt.Fprintf(&sb, "- %s: %d/%d\n", refName(z.Ref), z.Visited, z.Rooms)
+		}
+	}
+	for _, m := range []struct {
+		label string
+		refs  []Ref
+	}{{"Rooms never visited", r.Rooms.Missed}, {"Events never triggered", r.Events.Missed}, {"NPCs never met", r.NPCs.Missed}, {"Scenes never reached", r.Scenes.Missed}} {
+		if len(m.refs) == 0 {
+			continue
+		}
+		names := make([]string, len(m.refs))
+		for i, ref := range m.refs {
+			names[i] = refName(ref)
+		}
+		fmt.Fprintf(&sb, "\n### %s\n\n- %s\n", m.label, strings.Join(names, "\n- "))
+	}
+
+	if len(r.DeadEnds) > 0 {
+		sb.WriteString("\n## Dead ends\n\n")
+		for _, d := range r.DeadEnds {
+			fmt.Fprintf(&sb, "- Rounds %d–%d: nothing new reached from %s\n", d.FromRound, d.ToRound, refName(d.Room))
+		}
+	}
+	if len(r.ToolErrors) > 0 {
+		sb.WriteString("\n## Tool errors\n\n")
+		counts := map[string]int{}
+		for _, e := range r.ToolErrors {
+			counts[e.Tool]++
+		}
+		tools := make([]string, 0, len(counts))
+		for name := range counts {
+			tools = append(tools, name)
+		}
+		sort.Strings(tools)
+		for _, name := range tools {
+			fmt.Fprintf(&sb, "- `%s`: %d\n", name, counts[name])
+		}
+		sb.WriteString("\n")
+		for _, e := range r.ToolErrors {
+			fmt.Fprintf(&sb, "- Round %d, `%s`: %s\n", e.Round, e.Tool, e.Error)
+		}
+	}
+	if len(r.Failures) > 0 {
+		sb.WriteString("\n## Failed turns\n\n")
+		for _, f := range r.Failures {
+			fmt.Fprintf(&sb, "- Round %d, %s: %s\n", f.Round, f.Who, f.Error)
+		}
+	}
+	return sb.String()
+}
+
+func refName(r Ref) string {
+	if r.Name == "" || r.Name == r.ID {
+		return "`" + r.ID + "`"
+	}
+	return fmt.Sprintf("%s (`%s`)", r.Name, r.ID)
+}
@@ -0,0 +1,195 @@
+package playtest
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/theburrowhub/thaimaturgy/internal/domain"
+	"github.com/theburrowhub/thaimaturgy/internal/providers"
+)
+
+// Stub is a scripted provider for offline runs. As the DM it walks the
+// adventure's map one room per turn — along exits to the nearest room it
+// hasn't been to, else to the next unvisited room as authored — calling the
+// same tools a model would to move the party, meet the room's NPCs, trigger
+// its events and follow the first transition out of the current scene. As a
+// player it cycles through a few stock actions. Its narration is a plain
+// account of what it did.
+//
+// A run against Stub checks a module's structure: every room reachable, every
+// id the rooms reference resolving, the tools accepting what they are given.
+type Stub struct {
+	adv *domain.Adventure
+
+	mu      sync.Mutex
+	room    string // where the stub believes the party is
+	scene   string
+	visited map[string]bool
+	started bool
+	calls   int
+	actions int
+}
+
+// NewStub returns a stub that plays adv.
+func NewStub(adv *domain.Adventure) *Stub {
+	s := &Stub{adv: adv, room: adv.StartRoomID(), scene: adv.InitialSceneID(), visited: map[string]bool{}}
+	if s.room != "" {
+		s.visited[s.room] = true
+	}
+	return s
+}
+
+func (s *Stub) Name() string         { return "stub" }
+func (s *Stub) SupportsTools() bool  { return true }
+func (s *Stub) SupportsVision() bool { return false }
+
+func (s *Stub) ChatStream(ctx context.Context, req providers.ChatRequest, fn providers.StreamFunc) (*providers.ChatResponse, error) {
+	resp, err := s.Chat(ctx, req)
+	if err == nil && fn != nil && resp.Content != "" {
+		fn(resp.Content)
+	}
+	return resp, err
+}
+
+func (s *Stub) Chat(_ context.Context, req providers.ChatRequest) (*providers.ChatResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(req.Messages) > 0 && strings.HasPrefix(req.Messages[0].Content, playerPromptHeader) {
+		return s.playerAction(), nil
+	}
+	if len(req.Tools) == 0 {
+		// A summary or a spoiler review: an empty reply leaves the text as it is.
+		return &providers.ChatResponse{Model: "stub", FinishReason: "stop"}, nil
+	}
+	if last := req.Messages[len(req.Messages)-1]; last.Role == providers.RoleTool {
+		return s.narrate(req.Messages), nil
+	}
+	return s.turn(), nil
+}
+
+var stubActions = []string{
+	"I look around carefully, taking in every detail.",
+	"I greet whoever is here and ask what they know.",
+	"I search the area for anything hidden or useful.",
+	"I lead the way onward to somewhere we haven't been.",
+}
+
+func (s *Stub) playerAction() *providers.ChatResponse {
+	a := stubActions[s.actions%len(stubActions)]
+	s.actions++
+	return &providers.ChatResponse{Content: a, Model: "stub", FinishReason: "stop"}
+}
+
+// turn opens a DM turn with its tool calls: on the first turn the starting
+// room's, afterwards a move to the next room and that room's.
+func (s *Stub) turn() *providers.ChatResponse {
+	var calls []providers.ToolCallInfo
+	call := func(name string, args map[string]string) {
+		b, _ := json.Marshal(args)
+		s.calls++
+		calls = append(calls, providers.ToolCallInfo{ID: fmt.Sprintf("stub_%d", s.calls), Type: "function",
+			Function: providers.FunctionCall{Name: name, Arguments: string(b)}})
+	}
+	if s.started {
+		if next := s.nextRoom(); next != "" {
+			s.room = next
+			s.visited[next] = true
+			call("set_location", map[string]string{"room_id": next})
+		}
+	}
+	s.started = true
+	if r, _ := s.adv.Room(s.room); r != nil {
+		for _, id := range r.NPCIDs {
+			call("mark_npc_met", map[string]string{"npc_id": id})
+		}
+		for _, id := range r.EventIDs {
+			call("trigger_event", map[string]string{"event_id": id})
+		}
+	}
+	if sc := s.adv.Scene(s.scene); sc != nil && len(sc.Next) > 0 {
+		s.scene = sc.Next[0].To
+		call("set_scene", map[string]string{"scene_id": s.scene})
+	}
+	if len(calls) == 0 {
+		call("list_exits", nil)
+	}
+	return &providers.ChatResponse{Model: "stub", FinishReason: "tool_calls", ToolCalls: calls}
+}
+
+// narrate closes a DM turn, reporting the tools' results.
+func (s *Stub) narrate(msgs []providers.Message) *providers.ChatResponse {
+	var sb strings.Builder
+	if r, _ := s.adv.Room(s.room); r != nil {
+		fmt.Fprintf(&sb, "The party is in %s.", r.Name)
+	}
+	first := len(msgs)
+	for first > 0 && msgs[first-1].Role == providers.RoleTool {
+		first--
+	}
+	for _, m := range msgs[first:] {
+		fmt.Fprintf(&sb, " [%s: %s]", m.Name, firstLine(m.Content))
+	}
+	sb.WriteString(" What do you do?")
+	return &providers.ChatResponse{Content: strings.TrimSpace(sb.String()), Model: "stub", FinishReason: "stop"}
+}
+
+// nextRoom picks where to go: the first step towards the nearest unvisited
+// room along exits, else the first unvisited room as authored, else nowhere.
+func (s *Stub) nextRoom() string {
+	type hop struct{ room, first string }
+	queue := []hop{{room: s.room}}
+	seen := map[string]bool{s.room: true}
+	for len(queue) > 0 {
+		h := queue[0]
+		queue = queue[1:]
+		r, _ := s.adv.Room(h.room)
+		if r == nil {
+			continue
+		}
+		for _, ex := range r.Exits {
+			to := s.exitRoom(ex.To)
+			if to == "" || seen[to] {
+				continue
+			}
+			seen[to] = true
+			first := h.first
+			if first == "" {
+				first = to
+			}
+			if !s.visited[to] {
+				return first
+			}
+			queue = append(queue, hop{room: to, first: first})
+		}
+	}
+	for _, z := range s.adv.Zones {
+		for _, r := range z.Rooms {
+			if !s.visited[r.ID] {
+				return r.ID
+			}
+		}
+	}
+	return ""
+}
+
+// exitRoom resolves an exit target, a room or a zone, to a room id.
+func (s *Stub) exitRoom(to string) string {
+	if r, _ := s.adv.Room(to); r != nil {
+		return r.ID
+	}
+	if z := s.adv.Zone(to); z != nil && len(z.Rooms) > 0 {
+		return z.Rooms[0].ID
+	}
+	return ""
+}
+
+func firstLine(s string) string {
+	s = strings.TrimSpace(s)
+	if i := strings.IndexByte(s, '\n'); i >= 0 {
+		s = s[:i]
+	}
+	return s
+}
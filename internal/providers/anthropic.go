@@ -65,10 +65,24 @@ func (p *AnthropicProvider) SupportsTools() bool {
 type anthropicRequest struct {
 	Model       string             `json:"model"`
 	Messages    []anthropicMessage `json:"messages"`
-	System      string             `json:"system,omitempty"`
+	System      []anthropicSystem  `json:"system,omitempty"`
 	MaxTokens   int                `json:"max_tokens"`
 	Temperature *float64           `json:"temperature,omitempty"` // omitted when nil (some models deprecate it)
 	Tools       []anthropicTool    `json:"tools,omitempty"`
+	Stream      bool               `json:"stream,omitempty"`
+}
+
+// anthropicSystem is one block of the system prompt. A block with
+// CacheControl is a cache breakpoint: the tools and system blocks up to it are
+// cached and re-read at a fraction of the input price on later calls.
+type anthropicSystem struct {
+	Type         string                 `json:"type"` // "text"
+	Text         string                 `json:"text"`
+	CacheControl *anthropicCacheControl `json:"cache_control,omitempty"`
+}
+
+type anthropicCacheControl struct {
+	Type string `json:"type"` // "ephemeral"
 }
 
 type anthropicMessage struct {
@@ -97,27 +111,38 @@ type anthropicTool struct {
 	Name        string          `json:"name"`
 	Description string          `json:"description"`
 	InputSchema json.RawMessage `json:"input_schema"`
+	// CacheControl on the last tool caches the tool definitions, which the
+	// API places before the system prompt.
+	CacheControl *anthropicCacheControl `json:"cache_control,omitempty"`
+}
+
+// anthropicUsage is a reply's token count. Input tokens exclude those written
+// to or read from the prompt cache.
+type anthropicUsage struct {
+	InputTokens              int `json:"input_tokens"`
+	OutputTokens             int `json:"output_tokens"`
+	CacheCreationInputTokens int `json:"cache_creation_input_tokens"`
+	CacheReadInputTokens     int `json:"cache_read_input_tokens"`
+}
+
+type anthropicResponseBlock struct {
+	Type  string          `json:"type"`
+	Text  string          `json:"text,omitempty"`
+	ID    string          `json:"id,omitempty"`
+	Name  string          `json:"name,omitempty"`
+	Input json.RawMessage `json:"input,omitempty"`
 }
 
 type anthropicResponse struct {
-	ID      string `json:"id"`
-	Type    string `json:"type"`
-	Role    string `json:"role"`
-	Content []struct {
-		Type  string          `json:"type"`
-		Text  string          `json:"text,omitempty"`
-		ID    string          `json:"id,omitempty"`
-		Name  string          `json:"name,omitempty"`
-		Input json.RawMessage `json:"input,omitempty"`
-	} `json:"content"`
-	Model        string `json:"model"`
-	StopReason   string `json:"stop_reason"`
-	StopSequence string `json:"stop_sequence,omitempty"`
-	Usage        struct {
-		InputTokens  int `json:"input_tokens"`
-		OutputTokens int `json:"output_tokens"`
-	} `json:"usage"`
-	Error *struct {
+	ID           string                   `json:"id"`
+	Type         string                   `json:"type"`
+	Role         string                   `json:"role"`
+	Content      []anthropicResponseBlock `json:"content"`
+	Model        string                   `json:"model"`
+	StopReason   string                   `json:"stop_reason"`
+	StopSequence string                   `json:"stop_sequence,omitempty"`
+	Usage        anthropicUsage           `json:"usage"`
+	Error        *struct {
 		Type    string `json:"type"`
 		Message string `json:"message"`
 	} `json:"error,omitempty"`
@@ -126,23 +151,47 @@ type anthropicResponse struct {
 // Chat sends the request and, if the chosen model is unavailable or throttled,
 // retries once with the fallback model so subscription logins keep working.
 func (p *AnthropicProvider) Chat(ctx context.Context, req ChatRequest) (*ChatResponse, error) {
-	resp, err := p.chatOnce(ctx, req)
+	return p.withRecovery(req, nil, func(req ChatRequest) (*ChatResponse, error) {
+		return p.chatOnce(ctx, req)
+	})
+}
+
+// ChatStream is Chat over the streaming Messages API, with the same recovery
+// as long as nothing has been streamed: once a delta has reached fn, a retry
+// would repeat it, so a failure after that is returned as is.
+func (p *AnthropicProvider) ChatStream(ctx context.Context, req ChatRequest, fn StreamFunc) (*ChatResponse, error) {
+	if fn == nil {
+		fn = func(string) {}
+	}
+	started := false
+	return p.withRecovery(req, &started, func(req ChatRequest) (*ChatResponse, error) {
+		return p.streamOnce(ctx, req, func(d string) { started = true; fn(d) })
+	})
+}
+
+// withRecovery runs one request attempt, then retries it without a temperature
+// and/or on the fallback model when the API asks for it. started, when not nil,
+// reports whether a streaming attempt has delivered text; no retry follows one
+// that has.
+func (p *AnthropicProvider) withRecovery(req ChatRequest, started *bool, once func(ChatRequest) (*ChatResponse, error)) (*ChatResponse, error) {
+	resp, err := once(req)
+	recoverable := func() bool { return err != nil && (started == nil || !*started) }
 
 	// Some newer models reject an explicit temperature ("deprecated"). Drop it for
 	// the rest of this session and retry THIS request without it. We retry whenever
 	// we see the error (not only the first time): under concurrency several requests
 	// may have already been built with a temperature before the flag was set, and
 	// each must still be retried, or its result is lost.
-	if err != nil && temperatureDeprecated(err) {
+	if recoverable() && temperatureDeprecated(err) {
 		p.omitTemp.Store(true)
-		resp, err = p.chatOnce(ctx, req)
+		resp, err = once(req)
 	}
 
 	// If the chosen model is unavailable or throttled, retry with the fallback.
-	if err != nil && p.fallbackModel != "" && req.Model != p.fallbackModel && anthropicRetryable(err) {
+	if recoverable() && p.fallbackModel != "" && req.Model != p.fallbackModel && anthropicRetryable(err) {
 		log.Printf("anthropic: model %q unavailable (%v); falling back to %q", req.Model, err, p.fallbackModel)
 		req.Model = p.fallbackModel
-		resp, err = p.chatOnce(ctx, req)
+		resp, err = once(req)
 	}
 
 	return resp, err
@@ -168,22 +217,7 @@ func (p *AnthropicProvider) chatOnce(ctx context.Context, req ChatRequest) (*Cha
 		return nil, fmt.Errorf("failed to marshal request: %w", err)
 	}
 
-	resp, err := doWithRetry(ctx, p.httpClient, func() (*http.Request, error) {
-		r, err := http.NewRequestWithContext(ctx, "POST", anthropicBaseURL+"/messages", bytes.NewReader(body))
-		if err != nil {
-			return nil, err
-		}
-		r.Header.Set("Content-Type", "application/json")
-		r.Header.Set("anthropic-version", anthropicAPIVersion)
-		if p.oauthToken != "" {
-			// Reused Claude Code login: authenticate as an OAuth bearer.
-			r.Header.Set("Authorization", "Bearer "+p.oauthToken)
-			r.Header.Set("anthropic-beta", "oauth-2025-04-20")
-		} else {
-			r.Header.Set("x-api-key", p.apiKey)
-		}
-		return r, nil
-	})
+	resp, err := p.post(ctx, body)
 	if err != nil {
 		return nil, fmt.Errorf("request failed: %w", err)
 	}
@@ -213,13 +247,183 @@ func (p *AnthropicProvider) chatOnce(ctx context.Context, req ChatRequest) (*Cha
 	return p.convertResponse(anthropicResp, latency), nil
 }
 
+// post sends a Messages API request body with the provider's credentials.
+func (p *AnthropicProvider) post(ctx context.Context, body []byte) (*http.Response, error) {
+	return doWithRetry(ctx, p.httpClient, func() (*http.Request, error) {
+		r, err := http.NewRequestWithContext(ctx, "POST", anthropicBaseURL+"/messages", bytes.NewReader(body))
+		if err != nil {
+			return nil, err
+		}
+		r.Header.Set("Content-Type", "application/json")
+		r.Header.Set("anthropic-version", anthropicAPIVersion)
+		if p.oauthToken != "" {
+			// Reused Claude Code login: authenticate as an OAuth bearer.
+			r.Header.Set("Authorization", "Bearer "+p.oauthToken)
+			r.Header.Set("anthropic-beta", "oauth-2025-04-20")
+		} else {
+			r.Header.Set("x-api-key", p.apiKey)
+		}
+		return r, nil
+	})
+}
+
+// anthropicStreamEvent is one event of a streamed Messages reply: message_start
+// (model, input and cache usage), content_block_start/delta/stop (text, or a tool_use
+// whose input arrives as partial JSON), message_delta (stop reason, output
+// usage), message_stop, ping and error.
+type anthropicStreamEvent struct {
+	Type    string `json:"type"`
+	Message *struct {
+		Model string         `json:"model"`
+		Usage anthropicUsage `json:"usage"`
+	} `json:"message,omitempty"`
+	Index        int `json:"index"`
+	ContentBlock *struct {
+		Type string `json:"type"`
+		Text string `json:"text"`
+		ID   string `json:"id"`
+		Name string `json:"name"`
+	} `json:"content_block,omitempty"`
+	Delta *struct {
+		Type        string `json:"type"`
+		Text        string `json:"text"`
+		PartialJSON string `json:"partial_json"`
+		StopReason  string `json:"stop_reason"`
+	} `json:"delta,omitempty"`
+	Usage *struct {
+		OutputTokens int `json:"output_tokens"`
+	} `json:"usage,omitempty"`
+	Error *struct {
+		Type    string `json:"type"`
+		Message string `json:"message"`
+	} `json:"error,omitempty"`
+}
+
+// streamOnce makes one streaming request and reassembles the events into an
+// anthropicResponse, so the result converts exactly like a non-streamed one.
+func (p *AnthropicProvider) streamOnce(ctx context.Context, req ChatRequest, fn StreamFunc) (*ChatResponse, error) {
+	startTime := time.Now()
+
+	anthropicReq := p.convertRequest(req)
+	anthropicReq.Stream = true
+	body, err := json.Marshal(anthropicReq)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	resp, err := p.post(ctx, body)
+	if err != nil {
+		return nil, fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, streamStatusError(resp, func(b []byte) error {
+			var r anthropicResponse
+			if json.Unmarshal(b, &r) == nil && r.Error != nil {
+				return fmt.Errorf("Anthropic API error: %s (type: %s)", r.Error.Message, r.Error.Type)
+			}
+			return nil
+		})
+	}
+
+	var out anthropicResponse
+	type block struct {
+		typ, id, name string
+		text, input   strings.Builder
+	}
+	var blocks []*block
+	at := func(i int) *block {
+		for len(blocks) <= i {
+			blocks = append(blocks, &block{})
+		}
+		return blocks[i]
+	}
+	err = readSSE(resp.Body, func(_, data string) error {
+		var ev anthropicStreamEvent
+		if err := json.Unmarshal([]byte(data), &ev); err != nil {
+			return fmt.Errorf("failed to parse stream event: %w", err)
+		}
+		switch ev.Type {
+		case "error":
+			if ev.Error != nil {
+				return fmt.Errorf("Anthropic API error: %s (type: %s)", ev.Error.Message, ev.Error.Type)
+			}
+		case "message_start":
+			if ev.Message != nil {
+				out.Model = ev.Message.Model
+				out.Usage = ev.Message.Usage
+			}
+		case "content_block_start":
+			if cb := ev.ContentBlock; cb != nil {
+				b := at(ev.Index)
+				b.typ, b.id, b.name = cb.Type, cb.ID, cb.Name
+				b.text.WriteString(cb.Text)
+			}
+		case "content_block_delta":
+			if d := ev.Delta; d != nil {
+				b := at(ev.Index)
+				switch d.Type {
+				case "text_delta":
+					if b.typ == "" {
+						b.typ = "text"
+					}
+					b.text.WriteString(d.Text)
+					fn(d.Text)
+				case "input_json_delta":
+					b.input.WriteString(d.PartialJSON)
+				}
+			}
+		case "message_delta":
+			if ev.Delta != nil && ev.Delta.StopReason != "" {
+				out.StopReason = ev.Delta.StopReason
+			}
+			if ev.Usage != nil {
+				out.Usage.OutputTokens = ev.Usage.OutputTokens
+			}
+		case "message_stop":
+			return errStopSSE
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, b := range blocks {
+		switch b.typ {
+		case "text":
+			out.Content = append(out.Content, anthropicResponseBlock{Type: "text", Text: b.text.String()})
+		case "tool_use":
+			input := json.RawMessage(b.input.String())
+			if len(input) == 0 {
+				input = json.RawMessage("{}")
+			}
+			out.Content = append(out.Content, anthropicResponseBlock{Type: "tool_use", ID: b.id, Name: b.name, Input: input})
+		}
+	}
+	return p.convertResponse(out, time.Since(startTime).Milliseconds()), nil
+}
+
+// convertRequest maps a ChatRequest onto the Messages API. System messages
+// become system blocks, in order; one marked Cache becomes a cache breakpoint,
+// and then the tool definitions (which precede the system prompt) are cached
+// too.
 func (p *AnthropicProvider) convertRequest(req ChatRequest) anthropicRequest {
-	var systemPrompt string
+	var system []anthropicSystem
 	var messages []anthropicMessage
+	cached := false
 
 	for _, msg := range req.Messages {
 		if msg.Role == RoleSystem {
-			systemPrompt = msg.Content
+			if msg.Content == "" {
+				continue
+			}
+			blk := anthropicSystem{Type: "text", Text: msg.Content}
+			if msg.Cache {
+				blk.CacheControl = &anthropicCacheControl{Type: "ephemeral"}
+				cached = true
+			}
+			system = append(system, blk)
 			continue
 		}
 
@@ -292,6 +496,9 @@ func (p *AnthropicProvider) convertRequest(req ChatRequest) anthropicRequest {
 			InputSchema: t.Parameters,
 		})
 	}
+	if cached && len(tools) > 0 {
+		tools[len(tools)-1].CacheControl = &anthropicCacheControl{Type: "ephemeral"}
+	}
 
 	maxTokens := req.MaxTokens
 	if maxTokens <= 0 {
@@ -301,7 +508,7 @@ func (p *AnthropicProvider) convertRequest(req ChatRequest) anthropicRequest {
 	anthropicReq := anthropicRequest{
 		Model:     req.Model,
 		Messages:  messages,
-		System:    systemPrompt,
+		System:    system,
 		MaxTokens: maxTokens,
 	}
 	if !p.omitTemp.Load() {
@@ -337,6 +544,7 @@ func (p *AnthropicProvider) convertResponse(resp anthropicResponse, latencyMs in
 		}
 	}
 
+	u := resp.Usage
 	finishReason := resp.StopReason
 	if finishReason == "end_turn" {
 		finishReason = "stop"
@@ -349,9 +557,11 @@ func (p *AnthropicProvider) convertResponse(resp anthropicResponse, latencyMs in
 		ToolCalls:    toolCalls,
 		FinishReason: finishReason,
 		Usage: Usage{
-			PromptTokens:     resp.Usage.InputTokens,
-			CompletionTokens: resp.Usage.OutputTokens,
-			TotalTokens:      resp.Usage.InputTokens + resp.Usage.OutputTokens,
+			PromptTokens:     u.InputTokens,
+			CompletionTokens: u.OutputTokens,
+			TotalTokens:      u.InputTokens + u.OutputTokens + u.CacheCreationInputTokens + u.CacheReadInputTokens,
+			CacheReadTokens:  u.CacheReadInputTokens,
+			CacheWriteTokens: u.CacheCreationInputTokens,
 		},
 		Model:   resp.Model,
 		Latency: latencyMs,
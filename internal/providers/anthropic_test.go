@@ -8,6 +8,8 @@ import (
 	"net/http/httptest"
 	"strings"
 	"testing"
+
+	"github.com/theburrowhub/thaimaturgy/internal/types"
 )
 
 // TestAnthropicTemperatureSelfHeal verifies that when a model rejects an
@@ -137,3 +139,69 @@ func TestAnthropicModelFallback(t *testing.T) {
 		t.Errorf("first call should use the requested model, got %q", models[0])
 	}
 }
+
+// TestAnthropicPromptCaching verifies a system message marked Cache becomes a
+// cache breakpoint, along with the last tool definition, and that cache reads
+// and writes are reported apart from the uncached prompt tokens.
+func TestAnthropicPromptCaching(t *testing.T) {
+	var sent map[string]any
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		_ = json.Unmarshal(body, &sent)
+		_, _ = w.Write([]byte(`{"content":[{"type":"text","text":"OK"}],"stop_reason":"end_turn","usage":{"input_tokens":40,"output_tokens":10,"cache_creation_input_tokens":0,"cache_read_input_tokens":2000}}`))
+	}))
+	defer srv.Close()
+
+	orig := anthropicBaseURL
+	anthropicBaseURL = srv.URL
+	defer func() { anthropicBaseURL = orig }()
+
+	schema := json.RawMessage(`{"type":"object"}`)
+	resp, err := NewAnthropicProvider("sk-test").Chat(context.Background(), ChatRequest{
+		Model: "claude-sonnet-5",
+		Messages: []Message{
+			{Role: RoleSystem, Content: "rules and adventure", Cache: true},
+			{Role: RoleSystem, Content: "current situation"},
+			{Role: RoleUser, Content: "I open the door."},
+		},
+		Tools: []types.Tool{
+			{Name: "roll_dice", Parameters: schema},
+			{Name: "move_to", Parameters: schema},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	system, _ := sent["system"].([]any)
+	if len(system) != 2 {
+		t.Fatalf("system = %v, want two blocks", sent["system"])
+	}
+	first, second := system[0].(map[string]any), system[1].(map[string]any)
+	if first["text"] != "rules and adventure" || first["cache_control"] == nil {
+		t.Errorf("stable block = %v, want a cache breakpoint", first)
+	}
+	if second["text"] != "current situation" || second["cache_control"] != nil {
+		t.Errorf("situation block = %v, want it after the breakpoint and uncached", second)
+	}
+	tools, _ := sent["tools"].([]any)
+	if len(tools) != 2 || tools[0].(map[string]any)["cache_control"] != nil || tools[1].(map[string]any)["cache_control"] == nil {
+		t.Errorf("tools = %v, want a breakpoint on the last one only", tools)
+	}
+
+	if u := resp.Usage; u.PromptTokens != 40 || u.CacheReadTokens != 2000 || u.CacheWriteTokens != 0 || u.TotalTokens != 2050 {
+		t.Errorf("usage = %+v", u)
+	}
+}
+
+// TestAnthropicNoCacheWithoutMark verifies a request without a Cache mark
+// carries no cache breakpoints.
+func TestAnthropicNoCacheWithoutMark(t *testing.T) {
+	req := NewAnthropicProvider("sk-test").convertRequest(ChatRequest{
+		Messages: []Message{{Role: RoleSystem, Content: "sys"}, {Role: RoleUser, Content: "hi"}},
+		Tools:    []types.Tool{{Name: "roll_dice", Parameters: json.RawMessage(`{}`)}},
+	})
+	if len(req.System) != 1 || req.System[0].CacheControl != nil || req.Tools[0].CacheControl != nil {
+		t.Errorf("request = %+v, want no cache breakpoints", req)
+	}
+}
@@ -0,0 +1,299 @@
+package providers
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/theburrowhub/thaimaturgy/internal/domain"
+)
+
+// cassetteVersion is written to every cassette; a file of another version is
+// refused rather than misread.
+const cassetteVersion = 1
+
+// A cassette is a file of recorded model calls: each request with the response
+// (or error) it got. Record wraps a provider to write one; Replay serves the
+// responses back without a provider, so a session can be reproduced — tool
+// calls included, which the oracle still runs through its ToolRouter — or an
+// adventure regression-tested offline.
+//
+// Replay matches a request to a recording by fingerprint: exactly when it can,
+// else by the shape of the conversation (the user's messages, the model's
+// replies and tool calls) ignoring the system prompt and tool results, which
+// change with the dice. Each recording is served once, in recorded order.
+type cassette struct {
+	Version      int           `json:"version"`
+	Interactions []interaction `json:"interactions"`
+
+	path string
+	mu   sync.Mutex
+	used []bool // replay: recordings already served
+}
+
+type interaction struct {
+	Provider string          `json:"provider"`
+	Match    fingerprints    `json:"match"`
+	Request  cassetteRequest `json:"request"`
+	Response *ChatResponse   `json:"response,omitempty"`
+	Error    string          `json:"error,omitempty"`
+}
+
+type fingerprints struct {
+	Exact string `json:"exact"`
+	Loose string `json:"loose"`
+}
+
+// cassetteRequest is a request as recorded: the tools by name only, for a file
+// a person can read.
+type cassetteRequest struct {
+	Model       string    `json:"model,omitempty"`
+	Messages    []Message `json:"messages"`
+	Tools       []string  `json:"tools,omitempty"`
+	Temperature float64   `json:"temperature,omitempty"`
+	MaxTokens   int       `json:"max_tokens,omitempty"`
+}
+
+// ErrCassetteMiss is returned by a replaying provider for a request the
+// cassette has no unused recording of.
+var ErrCassetteMiss = errors.New("no recording matches this request")
+
+// cassettes holds the open cassettes by mode and path, so every provider
+// built for a session (oracle, spoiler guard, a rebuilt one after a settings
+// change) records to, or replays from, the same one.
+var cassettes = struct {
+	sync.Mutex
+	m map[string]*cassette
+}{m: map[string]*cassette{}}
+
+func openCassette(mode, path string) (*cassette, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return nil, err
+	}
+	cassettes.Lock()
+	defer cassettes.Unlock()
+	key := mode + ":" + abs
+	if c, ok := cassettes.m[key]; ok {
+		return c, nil
+	}
+	c := &cassette{Version: cassetteVersion, path: abs}
+	if mode == domain.CassetteReplay {
+		data, err := os.ReadFile(abs)
+		if err != nil {
+			return nil, fmt.Errorf("cassette: %w", err)
+		}
+		if err := json.Unmarshal(data, c); err != nil {
+			return nil, fmt.Errorf("cassette %s: %w", path, err)
+		}
+		if c.Version != cassetteVersion {
+			return nil, fmt.Errorf("cassette %s: unsupported version %d", path, c.Version)
+		}
+		c.used = make([]bool, len(c.Interactions))
+	}
+	cassettes.m[key] = c
+	return c, nil
+}
+
+// Record wraps p so every call it makes is appended to the cassette at path,
+// which is rewritten after each call (so a crash loses nothing) and replaces
+// any file already there.
+func Record(p Provider, path string) (Provider, error) {
+	c, err := openCassette(domain.CassetteRecord, path)
+	if err != nil {
+		return nil, err
+	}
+	return &recorder{Provider: p, cassette: c}, nil
+}
+
+type recorder struct {
+	Provider
+	cassette *cassette
+}
+
+func (r *recorder) Chat(ctx context.Context, req ChatRequest) (*ChatResponse, error) {
+	resp, err := r.Provider.Chat(ctx, req)
+	r.record(req, resp, err)
+	return resp, err
+}
+
+func (r *recorder) ChatStream(ctx context.Context, req ChatRequest, fn StreamFunc) (*ChatResponse, error) {
+	resp, err := r.Provider.ChatStream(ctx, req, fn)
+	r.record(req, resp, err)
+	return resp, err
+}
+
+func (r *recorder) record(req ChatRequest, resp *ChatResponse, err error) {
+	if errors.Is(err, context.Canceled) {
+		return // the caller gave up; nothing worth replaying
+	}
+	in := interaction{Provider: r.Provider.Name(), Match: fingerprint(req), Request: recordedRequest(req), Response: resp}
+	if resp != nil && resp.Provider != "" {
+		in.Provider = resp.Provider
+	}
+	if err != nil {
+		in.Error = err.Error()
+	}
+	c := r.cassette
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.Interactions = append(c.Interactions, in)
+	data, merr := json.MarshalIndent(c, "", "  ")
+	if merr == nil {
+		merr = writeFileAtomic(c.path, data)
+	}
+	if merr != nil {
+		log.Printf("cassette: could not save %s: %v", c.path, merr)
+	}
+}
+
+// Replay returns a provider that answers from the cassette at path instead of
+// calling a model. A request with no matching recording fails with
+// ErrCassetteMiss.
+func Replay(path string) (Provider, error) {
+	c, err := openCassette(domain.CassetteReplay, path)
+	if err != nil {
+		return nil, err
+	}
+	return &replayer{cassette: c}, nil
+}
+
+type replayer struct {
+	cassette *cassette
+	err      error // the cassette couldn't be opened: every call fails with it
+}
+
+// Name is the provider the cassette was recorded with, so usage is booked as
+// it was then.
+func (r *replayer) Name() string {
+	if r.cassette != nil && len(r.cassette.Interactions) > 0 {
+		return r.cassette.Interactions[0].Provider
+	}
+	return "replay"
+}
+
+func (r *replayer) SupportsTools() bool  { return true }
+func (r *replayer) SupportsVision() bool { return true }
+
+func (r *replayer) Chat(_ context.Context, req ChatRequest) (*ChatResponse, error) {
+	if r.err != nil {
+		return nil, r.err
+	}
+	c := r.cassette
+	fp := fingerprint(req)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	i := c.find(func(m fingerprints) bool { return m.Exact == fp.Exact })
+	if i < 0 {
+		i = c.find(func(m fingerprints) bool { return m.Loose == fp.Loose })
+	}
+	if i < 0 {
+		return nil, fmt.Errorf("cassette %s: %w (fingerprint %s)", filepath.Base(c.path), ErrCassetteMiss, fp.Loose[:12])
+	}
+	c.used[i] = true
+	in := c.Interactions[i]
+	if in.Error != "" {
+		return nil, errors.New(in.Error)
+	}
+	if in.Response == nil {
+		return nil, fmt.Errorf("cassette %s: recording %d has no response", filepath.Base(c.path), i+1)
+	}
+	resp := *in.Response
+	return &resp, nil
+}
+
+// ChatStream delivers a replayed reply in one delta.
+func (r *replayer) ChatStream(ctx context.Context, req ChatRequest, fn StreamFunc) (*ChatResponse, error) {
+	resp, err := r.Chat(ctx, req)
+	if err == nil && fn != nil && resp.Content != "" {
+		fn(resp.Content)
+	}
+	return resp, err
+}
+
+// find returns the first unused recording whose fingerprints satisfy match, or
+// -1. The caller holds c.mu.
+func (c *cassette) find(match func(fingerprints) bool) int {
+	for i := range c.Interactions {
+		if !c.used[i] && match(c.Interactions[i].Match) {
+			return i
+		}
+	}
+	return -1
+}
+
+func recordedRequest(req ChatRequest) cassetteRequest {
+	out := cassetteRequest{Model: req.Model, Messages: req.Messages, Temperature: req.Temperature, MaxTokens: req.MaxTokens}
+	for _, t := range req.Tools {
+		out.Tools = append(out.Tools, t.Name)
+	}
+	return out
+}
+
+// fingerprint hashes a request two ways. Exact covers everything sent but the
+// tool schemas. Loose keeps only what steers the conversation: the user's
+// messages, the model's replies and tool calls, and which tools answered.
+func fingerprint(req ChatRequest) fingerprints {
+	type looseMsg struct {
+		Role      Role     `json:"role"`
+		Content   string   `json:"content,omitempty"`
+		ToolCalls []string `json:"tool_calls,omitempty"`
+		Tool      string   `json:"tool,omitempty"`
+		Images    int      `json:"images,omitempty"`
+	}
+	var loose []looseMsg
+	for _, m := range req.Messages {
+		switch m.Role {
+		case RoleSystem:
+			continue
+		case RoleTool:
+			loose = append(loose, looseMsg{Role: m.Role, Tool: m.Name})
+		default:
+			lm := looseMsg{Role: m.Role, Content: m.Content, Images: len(m.Images)}
+			for _, tc := range m.ToolCalls {
+				lm.ToolCalls = append(lm.ToolCalls, tc.Function.Name+" "+tc.Function.Arguments)
+			}
+			loose = append(loose, lm)
+		}
+	}
+	images := 0
+	for _, m := range req.Messages {
+		images += len(m.Images)
+	}
+	exact := struct {
+		Request cassetteRequest `json:"request"`
+		Images  int             `json:"images"`
+	}{recordedRequest(req), images}
+	return fingerprints{Exact: hashJSON(exact), Loose: hashJSON(loose)}
+}
+
+func hashJSON(v any) string {
+	b, _ := json.Marshal(v)
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:])
+}
+
+// writeFileAtomic writes data next to path and renames it into place, so a
+// reader never sees a half-written cassette.
+func writeFileAtomic(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".tmp-"+filepath.Base(path)+"-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
@@ -0,0 +1,96 @@
+package providers
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/theburrowhub/thaimaturgy/internal/domain"
+	"github.com/theburrowhub/thaimaturgy/internal/types"
+)
+
+// toolTurn is the shape of an oracle turn: the player's action, the model's
+// tool call, and the tool's result — which changes from run to run with the
+// dice.
+func toolTurn(system, result string) ChatRequest {
+	return ChatRequest{
+		Model: "gpt-4o",
+		Messages: []Message{
+			{Role: RoleSystem, Content: system},
+			{Role: RoleUser, Content: "I attack the goblin."},
+			{Role: RoleAssistant, ToolCalls: []ToolCallInfo{{ID: "c1", Type: "function", Function: FunctionCall{Name: "roll", Arguments: `{"dice":"1d20"}`}}}},
+			{Role: RoleTool, ToolCallID: "c1", Name: "roll", Content: result},
+		},
+		Tools: []types.Tool{{Name: "roll"}},
+	}
+}
+
+func TestCassetteRecordsAndReplays(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "bug.json")
+	live := &scripted{name: "openai"}
+	rec, err := Record(live, path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	first := ChatRequest{Model: "gpt-4o", Messages: []Message{{Role: RoleSystem, Content: "HP 12"}, {Role: RoleUser, Content: "I attack the goblin."}}}
+	if _, err := rec.Chat(context.Background(), first); err != nil {
+		t.Fatal(err)
+	}
+	var deltas []string
+	if _, err := rec.ChatStream(context.Background(), toolTurn("HP 12", "rolled 17"), collect(&deltas)); err != nil {
+		t.Fatal(err)
+	}
+	live.err = errOverloaded
+	if _, err := rec.Chat(context.Background(), ChatRequest{Messages: []Message{{Role: RoleUser, Content: "again"}}}); err == nil {
+		t.Fatal("want the live error")
+	}
+
+	rp, err := Replay(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rp.Name() != "openai" {
+		t.Errorf("Name = %q, want the recorded provider", rp.Name())
+	}
+
+	// Exact match.
+	resp, err := rp.Chat(context.Background(), first)
+	if err != nil || resp.Content != "from openai" || resp.Usage.TotalTokens != 7 {
+		t.Fatalf("replayed %+v, %v", resp, err)
+	}
+
+	// A different system prompt and dice result still find the turn.
+	deltas = nil
+	resp, err = rp.ChatStream(context.Background(), toolTurn("HP 9", "rolled 3"), collect(&deltas))
+	if err != nil || resp.Content != "from openai" || strings.Join(deltas, "") != resp.Content {
+		t.Fatalf("loose replay %+v, %v, deltas %q", resp, err, deltas)
+	}
+
+	// Recorded errors come back as errors; each recording is served once.
+	if _, err := rp.Chat(context.Background(), ChatRequest{Messages: []Message{{Role: RoleUser, Content: "again"}}}); err == nil || !strings.Contains(err.Error(), "Overloaded") {
+		t.Errorf("err = %v, want the recorded error", err)
+	}
+	if _, err := rp.Chat(context.Background(), first); !errors.Is(err, ErrCassetteMiss) {
+		t.Errorf("err = %v, want a miss once the recording is used", err)
+	}
+}
+
+func TestNewWithCassette(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "session.json")
+	c := &domain.Config{Provider: domain.ProviderOpenAI, OpenAIAPIKey: "sk", Cassette: domain.CassetteConfig{Mode: domain.CassetteRecord, Path: path}}
+	if _, ok := New(c).(*recorder); !ok {
+		t.Errorf("record mode: New = %T", New(c))
+	}
+
+	// Replay needs no credential; a missing cassette fails every call.
+	c = &domain.Config{Cassette: domain.CassetteConfig{Mode: domain.CassetteReplay, Path: path}}
+	p := New(c)
+	if p == nil {
+		t.Fatal("replay mode should not need a credential")
+	}
+	if _, err := p.Chat(context.Background(), ChatRequest{}); err == nil || !strings.Contains(err.Error(), "session.json") {
+		t.Errorf("err = %v, want the unreadable cassette", err)
+	}
+}
@@ -0,0 +1,233 @@
+package providers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Circuit breaker tuning: a backend that fails breakerThreshold times in a row
+// is skipped for breakerCooldown, then given a trial request again.
+const (
+	breakerThreshold = 3
+	breakerCooldown  = time.Minute
+)
+
+// defaultFallbackTimeout is how long a backend may take to start answering
+// before the chain gives up on it and asks the next one.
+const defaultFallbackTimeout = 45 * time.Second
+
+// Backend is one link of a fallback chain.
+type Backend struct {
+	Provider Provider
+	// Model replaces the request's model for this backend. KeepModel sends the
+	// request's model unchanged instead — for the primary backend, whose model
+	// the caller already chose.
+	Model     string
+	KeepModel bool
+}
+
+// Chain is a Provider that asks its backends in order: when one fails with a
+// transient error (an outage, overload, rate limit or timeout) the request goes
+// to the next. A backend that keeps failing trips its circuit breaker and is
+// skipped until it cools down, so an outage costs one timeout, not one per
+// turn. Responses name the backend that answered.
+type Chain struct {
+	links   []*link
+	timeout time.Duration
+	now     func() time.Time // a field so tests can move the clock
+}
+
+type link struct {
+	Backend
+	mu        sync.Mutex
+	failures  int // consecutive transient failures
+	openUntil time.Time
+}
+
+// NewChain chains backends, the first being the primary. timeout bounds how
+// long each backend but the last may take to start answering (0 = 45s).
+func NewChain(backends []Backend, timeout time.Duration) *Chain {
+	if timeout <= 0 {
+		timeout = defaultFallbackTimeout
+	}
+	c := &Chain{timeout: timeout, now: time.Now}
+	for _, b := range backends {
+		c.links = append(c.links, &link{Backend: b})
+	}
+	return c
+}
+
+// Name is the primary backend's; each response names the one that answered.
+func (c *Chain) Name() string { return c.links[0].Provider.Name() }
+
+func (c *Chain) SupportsTools() bool {
+	for _, l := range c.links {
+		if !l.Provider.SupportsTools() {
+			return false
+		}
+	}
+	return true
+}
+
+// SupportsVision is the primary's: image inputs are only sent when it takes
+// them, and a fallback that doesn't fails the call like any other error.
+func (c *Chain) SupportsVision() bool { return c.links[0].Provider.SupportsVision() }
+
+func (c *Chain) Chat(ctx context.Context, req ChatRequest) (*ChatResponse, error) {
+	return c.run(ctx, req, nil)
+}
+
+// ChatStream falls back only until the first delta: once text has reached the
+// caller another backend can't take over without repeating it, so a failure
+// mid-reply is returned as is.
+func (c *Chain) ChatStream(ctx context.Context, req ChatRequest, fn StreamFunc) (*ChatResponse, error) {
+	if fn == nil {
+		fn = func(string) {}
+	}
+	return c.run(ctx, req, fn)
+}
+
+func (c *Chain) run(ctx context.Context, req ChatRequest, fn StreamFunc) (*ChatResponse, error) {
+	order := c.available()
+	var failures []string
+	var lastErr error
+	var lastName string
+	for i, l := range order {
+		r := req
+		if !l.KeepModel {
+			r.Model = l.Model
+		}
+		resp, started, err := c.attempt(ctx, l, r, fn, i == len(order)-1)
+		if err == nil {
+			l.succeeded()
+			resp.Provider = l.Provider.Name()
+			resp.Fallback = l != c.links[0]
+			if r.Model != "" {
+				resp.Model = r.Model
+			}
+			return resp, nil
+		}
+		if ctx.Err() != nil || !retryable(err) {
+			return nil, err
+		}
+		c.failed(l)
+		lastErr, lastName = err, l.Provider.Name()
+		failures = append(failures, l.Provider.Name()+": "+err.Error())
+		if started {
+			break
+		}
+		if i < len(order)-1 {
+			log.Printf("providers: %s failed (%v); trying %s", l.Provider.Name(), err, order[i+1].Provider.Name())
+		}
+	}
+	if len(failures) == 1 {
+		return nil, lastErr
+	}
+	return nil, fmt.Errorf("every provider failed: %s; %s: %w", strings.Join(failures[:len(failures)-1], "; "), lastName, lastErr)
+}
+
+// attempt asks one backend. Unless it is the last one left, it has c.timeout
+// to start answering: the whole reply for Chat, the first delta for
+// ChatStream. started reports whether any text was streamed.
+func (c *Chain) attempt(ctx context.Context, l *link, req ChatRequest, fn StreamFunc, last bool) (resp *ChatResponse, started bool, err error) {
+	if last {
+		if fn == nil {
+			resp, err = l.Provider.Chat(ctx, req)
+		} else {
+			resp, err = l.Provider.ChatStream(ctx, req, func(d string) { started = true; fn(d) })
+		}
+		return resp, started, err
+	}
+
+	actx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	timer := time.AfterFunc(c.timeout, cancel)
+	if fn == nil {
+		resp, err = l.Provider.Chat(actx, req)
+	} else {
+		resp, err = l.Provider.ChatStream(actx, req, func(d string) {
+			if !started {
+				started = true
+				timer.Stop()
+			}
+			fn(d)
+		})
+	}
+	timer.Stop()
+	if err != nil && ctx.Err() == nil && actx.Err() != nil {
+		err = fmt.Errorf("no answer within %s: %w", c.timeout, errTimedOut)
+	}
+	return resp, started, err
+}
+
+var errTimedOut = errors.New("backend timed out")
+
+// available returns the backends whose breaker lets requests through, in
+// order. When every breaker is open the one that reopens first is tried
+// anyway: failing fast helps nobody when there is nothing else to ask.
+func (c *Chain) available() []*link {
+	now := c.now()
+	var out []*link
+	var soonest *link
+	var soonestAt time.Time
+	for _, l := range c.links {
+		l.mu.Lock()
+		open := l.failures >= breakerThreshold && now.Before(l.openUntil)
+		until := l.openUntil
+		l.mu.Unlock()
+		if !open {
+			out = append(out, l)
+		} else if soonest == nil || until.Before(soonestAt) {
+			soonest, soonestAt = l, until
+		}
+	}
+	if len(out) == 0 {
+		out = []*link{soonest}
+	}
+	return out
+}
+
+// failed counts a transient failure, tripping the breaker at the threshold. A
+// failed trial after a cooldown trips it again straight away.
+func (c *Chain) failed(l *link) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.failures++
+	if l.failures >= breakerThreshold {
+		l.openUntil = c.now().Add(breakerCooldown)
+		log.Printf("providers: %s failed %d times in a row; skipping it for %s", l.Provider.Name(), l.failures, breakerCooldown)
+	}
+}
+
+func (l *link) succeeded() {
+	l.mu.Lock()
+	l.failures = 0
+	l.mu.Unlock()
+}
+
+// retryable reports whether another backend might answer where this one
+// failed: network errors, timeouts, overload, rate limits and 5xx responses.
+// Anything else (a bad request, a refused credential) is returned to the
+// caller, as the next backend would likely fail the same way or hide a
+// misconfiguration.
+func retryable(err error) bool {
+	if errors.Is(err, errTimedOut) || errors.Is(err, context.DeadlineExceeded) || isTransient(err) {
+		return true
+	}
+	s := strings.ToLower(err.Error())
+	for _, m := range []string{
+		"status code: 408", "status code: 429", "status code: 5",
+		"overloaded", "rate_limit", "rate limit", "resource_exhausted",
+		"unavailable", "server_error", "api_error", "timeout",
+	} {
+		if strings.Contains(s, m) {
+			return true
+		}
+	}
+	return false
+}
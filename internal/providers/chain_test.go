@@ -0,0 +1,170 @@
+package providers
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/theburrowhub/thaimaturgy/internal/domain"
+)
+
+// scripted is a backend that fails with err (when set) or answers with its
+// name, recording the models it was asked for.
+type scripted struct {
+	name   string
+	err    error
+	delay  time.Duration
+	stream []string
+	models []string
+}
+
+func (s *scripted) Name() string         { return s.name }
+func (s *scripted) SupportsTools() bool  { return true }
+func (s *scripted) SupportsVision() bool { return false }
+
+func (s *scripted) Chat(ctx context.Context, req ChatRequest) (*ChatResponse, error) {
+	return s.ChatStream(ctx, req, nil)
+}
+
+func (s *scripted) ChatStream(ctx context.Context, req ChatRequest, fn StreamFunc) (*ChatResponse, error) {
+	s.models = append(s.models, req.Model)
+	if fn != nil {
+		for _, d := range s.stream {
+			fn(d)
+		}
+	}
+	if s.delay > 0 {
+		select {
+		case <-time.After(s.delay):
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+	if s.err != nil {
+		return nil, s.err
+	}
+	return &ChatResponse{Content: "from " + s.name, Model: "reported-by-api", Usage: Usage{TotalTokens: 7}}, nil
+}
+
+var errOverloaded = errors.New("Anthropic API error: Overloaded (type: overloaded_error)")
+
+func TestChainFallsBackOnTransientErrors(t *testing.T) {
+	primary := &scripted{name: "anthropic", err: errOverloaded}
+	backup := &scripted{name: "openai"}
+	c := NewChain([]Backend{{Provider: primary, KeepModel: true}, {Provider: backup, Model: "gpt-4o-mini"}}, 0)
+
+	resp, err := c.Chat(context.Background(), ChatRequest{Model: "claude-sonnet-5"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Content != "from openai" || resp.Provider != "openai" || resp.Model != "gpt-4o-mini" || !resp.Fallback {
+		t.Errorf("response = %+v", resp)
+	}
+	if primary.models[0] != "claude-sonnet-5" || backup.models[0] != "gpt-4o-mini" {
+		t.Errorf("models sent = %v, %v", primary.models, backup.models)
+	}
+
+	// A non-transient error is the caller's to see: no fallback.
+	primary.err = errors.New("Anthropic API error: invalid x-api-key (type: authentication_error)")
+	if _, err := c.Chat(context.Background(), ChatRequest{}); err == nil || !strings.Contains(err.Error(), "authentication_error") {
+		t.Errorf("err = %v, want the primary's auth error", err)
+	}
+	if len(backup.models) != 1 {
+		t.Errorf("backup asked %d times, want once", len(backup.models))
+	}
+
+	// The primary answering reports itself, not a fallback.
+	primary.err = nil
+	resp, err = c.Chat(context.Background(), ChatRequest{Model: "claude-sonnet-5"})
+	if err != nil || resp.Provider != "anthropic" || resp.Fallback {
+		t.Errorf("response = %+v, err = %v", resp, err)
+	}
+}
+
+func TestChainCircuitBreaker(t *testing.T) {
+	primary := &scripted{name: "gemini", err: errors.New("Gemini API error: The model is overloaded (status: UNAVAILABLE)")}
+	backup := &scripted{name: "local"}
+	c := NewChain([]Backend{{Provider: primary, KeepModel: true}, {Provider: backup}}, 0)
+	now := time.Date(2026, 10, 16, 20, 0, 0, 0, time.UTC)
+	c.now = func() time.Time { return now }
+
+	for i := 0; i < breakerThreshold+2; i++ {
+		if _, err := c.Chat(context.Background(), ChatRequest{}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if len(primary.models) != breakerThreshold {
+		t.Errorf("primary asked %d times, want %d before the breaker trips", len(primary.models), breakerThreshold)
+	}
+
+	// After the cooldown the primary gets a trial; a success closes the breaker.
+	now = now.Add(breakerCooldown)
+	primary.err = nil
+	resp, err := c.Chat(context.Background(), ChatRequest{})
+	if err != nil || resp.Provider != "gemini" {
+		t.Fatalf("trial after cooldown: %+v, %v", resp, err)
+	}
+	if _, err := c.Chat(context.Background(), ChatRequest{}); err != nil || len(primary.models) != breakerThreshold+2 {
+		t.Errorf("primary asked %d times after recovering, want %d", len(primary.models), breakerThreshold+2)
+	}
+}
+
+func TestChainTimeoutAndStreaming(t *testing.T) {
+	slow := &scripted{name: "openai", delay: time.Second}
+	backup := &scripted{name: "local", stream: []string{"from ", "local"}}
+	c := NewChain([]Backend{{Provider: slow, KeepModel: true}, {Provider: backup}}, 20*time.Millisecond)
+
+	var deltas []string
+	resp, err := c.ChatStream(context.Background(), ChatRequest{}, collect(&deltas))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Provider != "local" || strings.Join(deltas, "") != "from local" {
+		t.Errorf("response = %+v, deltas = %q", resp, deltas)
+	}
+
+	// Once text has been streamed a failure can't move to another backend.
+	failing := &scripted{name: "anthropic", stream: []string{"The door"}, err: errOverloaded}
+	c = NewChain([]Backend{{Provider: failing, KeepModel: true}, {Provider: backup}}, 0)
+	deltas = nil
+	if _, err := c.ChatStream(context.Background(), ChatRequest{}, collect(&deltas)); !errors.Is(err, errOverloaded) {
+		t.Errorf("err = %v, want the mid-stream error", err)
+	}
+	if len(deltas) != 1 {
+		t.Errorf("deltas = %q", deltas)
+	}
+
+	// When everything fails the error names every backend.
+	down := &scripted{name: "local", err: errors.New("request failed: dial tcp: connection refused")}
+	c = NewChain([]Backend{{Provider: &scripted{name: "openai", err: errOverloaded}, KeepModel: true}, {Provider: down}}, 0)
+	_, err = c.Chat(context.Background(), ChatRequest{})
+	if err == nil || !strings.Contains(err.Error(), "openai: ") || !strings.Contains(err.Error(), "local: request failed") {
+		t.Errorf("err = %v", err)
+	}
+}
+
+func TestNewBuildsFallbackChain(t *testing.T) {
+	c := &domain.Config{
+		Provider:     domain.ProviderOpenAI,
+		OpenAIAPIKey: "sk",
+		GeminiAPIKey: "AIza",
+		Fallbacks: []domain.ProviderChoice{
+			{Provider: domain.ProviderAnthropic}, // no credential here: skipped
+			{Provider: domain.ProviderGemini},
+		},
+	}
+	chain, ok := New(c).(*Chain)
+	if !ok || len(chain.links) != 2 {
+		t.Fatalf("New = %#v, want a two-backend chain", New(c))
+	}
+	if l := chain.links[1]; l.Provider.Name() != "gemini" || l.Model != domain.DefaultModel(domain.ProviderGemini) {
+		t.Errorf("fallback = %s %q", l.Provider.Name(), l.Model)
+	}
+
+	c.Fallbacks = c.Fallbacks[:1]
+	if _, ok := New(c).(*OpenAIProvider); !ok {
+		t.Error("with no usable fallback New should return the provider itself")
+	}
+}
@@ -42,6 +42,16 @@ type claudeCLIResult struct {
 	Subtype string `json:"subtype"`
 }
 
+// ChatStream runs Chat and delivers the whole reply as one delta: print mode
+// returns the result only when the run is over.
+func (p *ClaudeCLIProvider) ChatStream(ctx context.Context, req ChatRequest, fn StreamFunc) (*ChatResponse, error) {
+	resp, err := p.Chat(ctx, req)
+	if err == nil && fn != nil && resp.Content != "" {
+		fn(resp.Content)
+	}
+	return resp, err
+}
+
 func (p *ClaudeCLIProvider) Chat(ctx context.Context, req ChatRequest) (*ChatResponse, error) {
 	// This backend is text-only (SupportsVision is false); any inline images are
 	// ignored rather than fatal, so callers that don't check the capability still
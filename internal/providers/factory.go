@@ -1,15 +1,93 @@
 package providers
 
 import (
+	"log"
 	"os/exec"
+	"time"
 
 	"github.com/theburrowhub/thaimaturgy/internal/domain"
 )
 
 // New builds the Provider for the active configuration, using an API key or a
-// reused local OAuth token, whichever is present. Returns nil if the active
-// provider has no usable credential.
+// reused local OAuth token, whichever is present. With fallbacks configured it
+// returns a Chain of the active provider and every fallback that has a
+// credential here. Returns nil if none has a usable credential.
+//
+// A cassette mode wraps the result: "record" writes every call to the
+// cassette file, "replay" answers from it and needs no credential at all.
 func New(c *domain.Config) Provider {
+	switch c.Cassette.Mode {
+	case domain.CassetteReplay:
+		p, err := Replay(cassettePath(c))
+		if err != nil {
+			log.Printf("providers: %v", err)
+			return &replayer{err: err}
+		}
+		return p
+	case domain.CassetteRecord:
+		p := newChain(c)
+		if p == nil {
+			return nil
+		}
+		if _, ok := p.(*ClaudeCLIProvider); ok {
+			log.Printf("providers: the claude CLI runs its own tool loop; not recording it")
+			return p
+		}
+		rec, err := Record(p, cassettePath(c))
+		if err != nil {
+			log.Printf("providers: %v; not recording", err)
+			return p
+		}
+		return rec
+	}
+	return newChain(c)
+}
+
+func cassettePath(c *domain.Config) string {
+	if c.Cassette.Path != "" {
+		return c.Cassette.Path
+	}
+	return "cassette.json"
+}
+
+func newChain(c *domain.Config) Provider {
+	primary := newProvider(c)
+	if len(c.Fallbacks) == 0 {
+		return primary
+	}
+	if _, ok := primary.(*ClaudeCLIProvider); ok {
+		return primary // the oracle runs the CLI's own tool loop; it can't be chained
+	}
+	var backends []Backend
+	if primary != nil {
+		backends = append(backends, Backend{Provider: primary, KeepModel: true})
+	}
+	for _, f := range c.Fallbacks {
+		sub := *c
+		sub.Provider = f.Provider
+		p := newProvider(&sub)
+		if p == nil {
+			continue // no credential for it on this machine
+		}
+		if _, ok := p.(*ClaudeCLIProvider); ok {
+			continue // text-only through Chat: it can't run the tool loop
+		}
+		model := f.Model
+		if model == "" {
+			model = domain.DefaultModel(f.Provider)
+		}
+		backends = append(backends, Backend{Provider: p, Model: model})
+	}
+	if len(backends) == 0 {
+		return nil
+	}
+	if len(backends) == 1 && primary != nil {
+		return primary
+	}
+	return NewChain(backends, time.Duration(c.FallbackTimeoutSeconds)*time.Second)
+}
+
+func newProvider(c *domain.Config) Provider {
 	switch c.Provider {
 	case domain.ProviderOpenAI:
 		if c.OpenAIAPIKey != "" {
@@ -33,6 +111,10 @@ func New(c *domain.Config) Provider {
 		if bin, err := exec.LookPath("claude"); err == nil {
 			return NewClaudeCLIProvider(bin)
 		}
+	case domain.ProviderLocal:
+		if c.LocalBaseURL != "" {
+			return NewLocalProvider(c.LocalBaseURL, c.LocalAPIKey, c.LocalToolMode)
+		}
 	}
 	return nil
 }
@@ -5,14 +5,17 @@ import (
 	"context"
 	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
 	"strings"
 	"time"
 )
 
-const geminiBaseURL = "https://generativelanguage.googleapis.com/v1beta"
+// geminiBaseURL is a var (not const) so tests can point it at a stub server.
+var geminiBaseURL = "https://generativelanguage.googleapis.com/v1beta"
 
 // GeminiProvider talks to Google's Generative Language API. It authenticates
 // with an API key (query param) or an OAuth bearer token reused from a local
@@ -85,11 +88,13 @@ type geminiGenConfig struct {
 	MaxOutputTokens int     `json:"maxOutputTokens,omitempty"`
 }
 
+type geminiCandidate struct {
+	Content      geminiContent `json:"content"`
+	FinishReason string        `json:"finishReason"`
+}
+
 type geminiResponse struct {
-	Candidates []struct {
-		Content      geminiContent `json:"content"`
-		FinishReason string        `json:"finishReason"`
-	} `json:"candidates"`
+	Candidates    []geminiCandidate `json:"candidates"`
 	UsageMetadata struct {
 		PromptTokenCount     int `json:"promptTokenCount"`
 		CandidatesTokenCount int `json:"candidatesTokenCount"`
@@ -105,6 +110,106 @@ type geminiResponse struct {
 func (p *GeminiProvider) Chat(ctx context.Context, req ChatRequest) (*ChatResponse, error) {
 	start := time.Now()
 
+	resp, err := p.post(ctx, req, "generateContent")
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+
+	var gr geminiResponse
+	if err := json.Unmarshal(respBody, &gr); err != nil {
+		return nil, fmt.Errorf("failed to parse response: %w (body: %s)", err, string(respBody))
+	}
+	if gr.Error != nil {
+		return nil, fmt.Errorf("Gemini API error: %s (status: %s)", gr.Error.Message, gr.Error.Status)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code: %d (body: %s)", resp.StatusCode, string(respBody))
+	}
+
+	return p.convertResponse(gr, time.Since(start).Milliseconds()), nil
+}
+
+// ChatStream streams the reply from streamGenerateContent (alt=sse). Each event
+// is a partial geminiResponse; their parts are merged into one candidate so the
+// result converts exactly like a non-streamed reply.
+func (p *GeminiProvider) ChatStream(ctx context.Context, req ChatRequest, fn StreamFunc) (*ChatResponse, error) {
+	start := time.Now()
+	if fn == nil {
+		fn = func(string) {}
+	}
+
+	resp, err := p.post(ctx, req, "streamGenerateContent")
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, streamStatusError(resp, func(b []byte) error {
+			var gr geminiResponse
+			if json.Unmarshal(b, &gr) == nil && gr.Error != nil {
+				return fmt.Errorf("Gemini API error: %s (status: %s)", gr.Error.Message, gr.Error.Status)
+			}
+			return nil
+		})
+	}
+
+	var merged geminiResponse
+	var parts []geminiPart
+	var text strings.Builder
+	finish := ""
+	err = readSSE(resp.Body, func(_, data string) error {
+		var gr geminiResponse
+		if err := json.Unmarshal([]byte(data), &gr); err != nil {
+			return fmt.Errorf("failed to parse stream chunk: %w", err)
+		}
+		if gr.Error != nil {
+			return fmt.Errorf("Gemini API error: %s (status: %s)", gr.Error.Message, gr.Error.Status)
+		}
+		if gr.UsageMetadata.TotalTokenCount > 0 {
+			merged.UsageMetadata = gr.UsageMetadata
+		}
+		if len(gr.Candidates) == 0 {
+			return nil
+		}
+		cand := gr.Candidates[0]
+		if cand.FinishReason != "" {
+			finish = cand.FinishReason
+		}
+		for _, part := range cand.Content.Parts {
+			switch {
+			case part.FunctionCall != nil:
+				parts = append(parts, part)
+			case part.Text != "":
+				text.WriteString(part.Text)
+				fn(part.Text)
+			}
+		}
+		return nil
+	})
+	// Gemini has no end-of-stream event; its last chunk carries the finish
+	// reason, so a stream that ends after one is complete.
+	if errors.Is(err, errStreamTruncated) && finish != "" {
+		err = nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if text.Len() > 0 {
+		parts = append([]geminiPart{{Text: text.String()}}, parts...)
+	}
+	merged.Candidates = []geminiCandidate{{Content: geminiContent{Role: "model", Parts: parts}, FinishReason: finish}}
+	return p.convertResponse(merged, time.Since(start).Milliseconds()), nil
+}
+
+// post sends req to a model method ("generateContent", or
+// "streamGenerateContent", which is asked for SSE).
+func (p *GeminiProvider) post(ctx context.Context, req ChatRequest, method string) (*http.Response, error) {
 	model := strings.TrimPrefix(req.Model, "models/")
 	if model == "" {
 		model = "gemini-2.5-flash"
@@ -115,12 +220,19 @@ func (p *GeminiProvider) Chat(ctx context.Context, req ChatRequest) (*ChatRespon
 		return nil, fmt.Errorf("failed to marshal request: %w", err)
 	}
 
-	url := fmt.Sprintf("%s/models/%s:generateContent", geminiBaseURL, model)
+	params := url.Values{}
+	if method == "streamGenerateContent" {
+		params.Set("alt", "sse")
+	}
 	if p.apiKey != "" {
-		url += "?key=" + p.apiKey
+		params.Set("key", p.apiKey)
+	}
+	endpoint := fmt.Sprintf("%s/models/%s:%s", geminiBaseURL, model, method)
+	if len(params) > 0 {
+		endpoint += "?" + params.Encode()
 	}
 	resp, err := doWithRetry(ctx, p.httpClient, func() (*http.Request, error) {
-		r, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewReader(body))
+		r, err := http.NewRequestWithContext(ctx, "POST", endpoint, bytes.NewReader(body))
 		if err != nil {
 			return nil, err
 		}
@@ -133,25 +245,7 @@ func (p *GeminiProvider) Chat(ctx context.Context, req ChatRequest) (*ChatRespon
 	if err != nil {
 		return nil, fmt.Errorf("request failed: %w", err)
 	}
-	defer resp.Body.Close()
-
-	respBody, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, fmt.Errorf("failed to read response: %w", err)
-	}
-
-	var gr geminiResponse
-	if err := json.Unmarshal(respBody, &gr); err != nil {
-		return nil, fmt.Errorf("failed to parse response: %w (body: %s)", err, string(respBody))
-	}
-	if gr.Error != nil {
-		return nil, fmt.Errorf("Gemini API error: %s (status: %s)", gr.Error.Message, gr.Error.Status)
-	}
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("unexpected status code: %d (body: %s)", resp.StatusCode, string(respBody))
-	}
-
-	return p.convertResponse(gr, time.Since(start).Milliseconds()), nil
+	return resp, nil
 }
 
 func (p *GeminiProvider) convertRequest(req ChatRequest) geminiRequest {
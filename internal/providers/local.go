@@ -0,0 +1,343 @@
+package providers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/theburrowhub/thaimaturgy/internal/domain"
+	"github.com/theburrowhub/thaimaturgy/internal/types"
+)
+
+// LocalProvider talks to a self-hosted server with an OpenAI-compatible API —
+// llama.cpp, Ollama, vLLM, LM Studio — so the game runs with no internet.
+//
+// Not every local model or server can do native tool calls. Under the "auto"
+// tool mode a request whose tools the server rejects is retried with the tools
+// described in the system prompt instead, and the model answers a call with a
+// JSON object the provider turns back into tool calls; the switch sticks for
+// the rest of the session. The "prompt" mode uses that protocol from the start.
+type LocalProvider struct {
+	client      *OpenAIProvider
+	toolMode    string
+	promptTools atomic.Bool  // set once the server rejects native tool calls
+	calls       atomic.Int64 // numbers the tool calls read from prompt replies
+
+	modelMu sync.Mutex
+	model   string // the server's first model, for requests that name none
+}
+
+// NewLocalProvider returns a provider for the server at baseURL (see
+// NormalizeBaseURL). apiKey may be empty; toolMode is one of the
+// domain.LocalTools* modes, empty meaning auto.
+func NewLocalProvider(baseURL, apiKey, toolMode string) *LocalProvider {
+	p := &LocalProvider{
+		client:   &OpenAIProvider{apiKey: apiKey, baseURL: NormalizeBaseURL(baseURL), httpClient: newHTTPClient()},
+		toolMode: toolMode,
+	}
+	p.promptTools.Store(toolMode == domain.LocalToolsPrompt)
+	return p
+}
+
+func (p *LocalProvider) Name() string        { return "local" }
+func (p *LocalProvider) SupportsTools() bool { return true }
+
+// SupportsVision is false: few local models take images, so the importer uses
+// a vision-capable provider instead when one is configured.
+func (p *LocalProvider) SupportsVision() bool { return false }
+
+func (p *LocalProvider) Chat(ctx context.Context, req ChatRequest) (*ChatResponse, error) {
+	req = p.prepare(ctx, req)
+	if len(req.Tools) > 0 && p.promptTools.Load() {
+		return p.chatPrompt(ctx, req)
+	}
+	resp, err := p.client.Chat(ctx, req)
+	if err != nil && p.fallBack(req, err) {
+		return p.chatPrompt(ctx, req)
+	}
+	return resp, err
+}
+
+// ChatStream streams native replies. Under the prompt protocol a reply can't
+// be told apart from a tool call until it is complete, so it is delivered in
+// one delta (none for a tool call). A native stream that fails after sending
+// text isn't retried under the prompt protocol, which would send it again.
+func (p *LocalProvider) ChatStream(ctx context.Context, req ChatRequest, fn StreamFunc) (*ChatResponse, error) {
+	if fn == nil {
+		fn = func(string) {}
+	}
+	req = p.prepare(ctx, req)
+	if len(req.Tools) == 0 || !p.promptTools.Load() {
+		started := false
+		resp, err := p.client.ChatStream(ctx, req, func(d string) { started = true; fn(d) })
+		if err == nil || started || !p.fallBack(req, err) {
+			return resp, err
+		}
+	}
+	resp, err := p.chatPrompt(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.Content != "" {
+		fn(resp.Content)
+	}
+	return resp, nil
+}
+
+// prepare fills in the model when the request names none, and merges runs of
+// system messages: many local chat templates accept a single one.
+func (p *LocalProvider) prepare(ctx context.Context, req ChatRequest) ChatRequest {
+	if req.Model == "" {
+		req.Model = p.defaultModel(ctx)
+	}
+	req.Messages = mergeRuns(req.Messages, RoleSystem)
+	return req
+}
+
+// defaultModel returns the first model the server lists, asking it once.
+func (p *LocalProvider) defaultModel(ctx context.Context) string {
+	p.modelMu.Lock()
+	defer p.modelMu.Unlock()
+	if p.model == "" {
+		if models, err := ListModels(ctx, p.client.baseURL, p.client.apiKey); err == nil && len(models) > 0 {
+			p.model = models[0]
+		}
+	}
+	return p.model
+}
+
+// fallBack reports whether a failed native request should be retried under
+// the prompt protocol, and if so switches to it for good. As with Anthropic's
+// temperature recovery, every request that hits the error retries, not just
+// the first: concurrent ones may have been sent before the switch.
+func (p *LocalProvider) fallBack(req ChatRequest, err error) bool {
+	if len(req.Tools) == 0 || p.toolMode == domain.LocalToolsNative || !toolsUnsupported(err) {
+		return false
+	}
+	if !p.promptTools.Swap(true) {
+		log.Printf("local: server rejected tool calls (%v); describing tools in the prompt instead", err)
+	}
+	return true
+}
+
+// toolsUnsupported recognises the errors servers give for tool calls they
+// can't make: Ollama's "does not support tools", llama.cpp's "tools param
+// requires --jinja flag", vLLM's "requires --enable-auto-tool-choice".
+func toolsUnsupported(err error) bool {
+	s := strings.ToLower(err.Error())
+	return strings.Contains(s, "tool") || strings.Contains(s, "jinja")
+}
+
+// chatPrompt sends req with its tools described in the system prompt and reads
+// any tool calls back out of the reply.
+func (p *LocalProvider) chatPrompt(ctx context.Context, req ChatRequest) (*ChatResponse, error) {
+	resp, err := p.client.Chat(ctx, promptToolRequest(req))
+	if err != nil {
+		return nil, err
+	}
+	if calls := parsePromptToolCalls(resp.Content); len(calls) > 0 {
+		for i := range calls {
+			calls[i].ID = fmt.Sprintf("call_%d", p.calls.Add(1))
+		}
+		resp.Content = ""
+		resp.ToolCalls = calls
+		resp.FinishReason = "tool_calls"
+	}
+	return resp, nil
+}
+
+// promptToolRequest rewrites a request for a server without tool calls: the
+// tools move into the system prompt, earlier calls become the JSON the model
+// is asked to write, and their results become user messages.
+func promptToolRequest(req ChatRequest) ChatRequest {
+	names := map[string]string{}
+	msgs := make([]Message, 0, len(req.Messages)+1)
+	for _, m := range req.Messages {
+		switch {
+		case m.Role == RoleAssistant && len(m.ToolCalls) > 0:
+			calls := make([]promptToolCall, len(m.ToolCalls))
+			for i, tc := range m.ToolCalls {
+				names[tc.ID] = tc.Function.Name
+				calls[i] = promptToolCall{Name: tc.Function.Name, Arguments: json.RawMessage(orEmptyObject(tc.Function.Arguments))}
+			}
+			b, _ := json.Marshal(promptToolReply{ToolCalls: calls})
+			content := string(b)
+			if m.Content != "" {
+				content = m.Content + "\n" + content
+			}
+			msgs = append(msgs, Message{Role: RoleAssistant, Content: content})
+		case m.Role == RoleTool:
+			name := names[m.ToolCallID]
+			if name == "" {
+				name = m.Name
+			}
+			msgs = append(msgs, Message{Role: RoleUser, Content: fmt.Sprintf("Result of %s:\n%s", name, m.Content)})
+		default:
+			msgs = append(msgs, m)
+		}
+	}
+
+	tools := promptToolsText(req.Tools)
+	if len(msgs) > 0 && msgs[0].Role == RoleSystem {
+		msgs[0].Content += "\n\n" + tools
+	} else {
+		msgs = append([]Message{{Role: RoleSystem, Content: tools}}, msgs...)
+	}
+	req.Messages = mergeRuns(msgs, RoleSystem, RoleUser)
+	req.Tools = nil
+	return req
+}
+
+func orEmptyObject(args string) string {
+	if strings.TrimSpace(args) == "" {
+		return "{}"
+	}
+	return args
+}
+
+type promptToolCall struct {
+	Name      string          `json:"name"`
+	Arguments json.RawMessage `json:"arguments"`
+}
+
+type promptToolReply struct {
+	ToolCalls []promptToolCall `json:"tool_calls"`
+}
+
+// promptToolsText describes the tools and the JSON reply that calls them.
+func promptToolsText(tools []types.Tool) string {
+	var sb strings.Builder
+	sb.WriteString("## Tools\n")
+	sb.WriteString("You can call the tools below. To call tools, reply with ONLY a JSON object, nothing before or after it:\n")
+	sb.WriteString(`{"tool_calls": [{"name": "<tool name>", "arguments": {<arguments>}}]}` + "\n")
+	sb.WriteString("You may call several tools at once. Their results come back in the next message. " +
+		"When you don't need a tool, reply in plain text, with no JSON.\n\nAvailable tools:\n")
+	for _, t := range tools {
+		fmt.Fprintf(&sb, "- %s: %s\n", t.Name, t.Description)
+		if len(t.Parameters) > 0 {
+			var compact bytes.Buffer
+			if json.Compact(&compact, t.Parameters) == nil {
+				fmt.Fprintf(&sb, "  parameters: %s\n", compact.String())
+			}
+		}
+	}
+	return strings.TrimRight(sb.String(), "\n")
+}
+
+// parsePromptToolCalls reads the tool calls out of a prompt-protocol reply,
+// tolerating a Markdown code fence or text around the JSON. A reply that isn't
+// a tool call yields none.
+func parsePromptToolCalls(text string) []ToolCallInfo {
+	text = strings.TrimSpace(text)
+	if !strings.Contains(text, `"tool_calls"`) {
+		return nil
+	}
+	start, end := strings.Index(text, "{"), strings.LastIndex(text, "}")
+	if start < 0 || end < start {
+		return nil
+	}
+	var reply promptToolReply
+	if err := json.Unmarshal([]byte(text[start:end+1]), &reply); err != nil {
+		return nil
+	}
+	var calls []ToolCallInfo
+	for _, c := range reply.ToolCalls {
+		if c.Name == "" {
+			continue
+		}
+		args := strings.TrimSpace(string(c.Arguments))
+		if args == "" || args == "null" {
+			args = "{}"
+		}
+		calls = append(calls, ToolCallInfo{Type: "function", Function: FunctionCall{Name: c.Name, Arguments: args}})
+	}
+	return calls
+}
+
+// mergeRuns joins consecutive text messages of the given roles into one, for
+// chat templates that want roles to alternate.
+func mergeRuns(msgs []Message, roles ...Role) []Message {
+	merge := func(r Role) bool {
+		for _, x := range roles {
+			if r == x {
+				return true
+			}
+		}
+		return false
+	}
+	out := make([]Message, 0, len(msgs))
+	for _, m := range msgs {
+		if n := len(out); n > 0 && out[n-1].Role == m.Role && merge(m.Role) &&
+			len(out[n-1].Images) == 0 && len(m.Images) == 0 && len(m.ToolCalls) == 0 && len(out[n-1].ToolCalls) == 0 {
+			out[n-1].Content += "\n\n" + m.Content
+			continue
+		}
+		out = append(out, m)
+	}
+	return out
+}
+
+// NormalizeBaseURL turns what a user types for a local server into an API base
+// URL: "localhost:11434" becomes "http://localhost:11434/v1". A URL with a
+// path is kept as given, minus a trailing slash.
+func NormalizeBaseURL(raw string) string {
+	s := strings.TrimRight(strings.TrimSpace(raw), "/")
+	if s == "" {
+		return ""
+	}
+	if !strings.Contains(s, "://") {
+		s = "http://" + s
+	}
+	if u, err := url.Parse(s); err == nil && u.Path == "" {
+		s += "/v1"
+	}
+	return s
+}
+
+// ListModels returns the ids of the models an OpenAI-compatible server offers
+// (GET {baseURL}/models). It doesn't retry, so probing a port nobody listens on
+// fails fast.
+func ListModels(ctx context.Context, baseURL, apiKey string) ([]string, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", NormalizeBaseURL(baseURL)+"/models", nil)
+	if err != nil {
+		return nil, err
+	}
+	if apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+apiKey)
+	}
+	resp, err := newHTTPClient().Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 4<<20))
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+	var list struct {
+		Data []struct {
+			ID string `json:"id"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(body, &list); err != nil {
+		return nil, fmt.Errorf("not an OpenAI-compatible model list: %w", err)
+	}
+	models := make([]string, 0, len(list.Data))
+	for _, m := range list.Data {
+		if m.ID != "" {
+			models = append(models, m.ID)
+		}
+	}
+	return models, nil
+}
@@ -0,0 +1,188 @@
+package providers
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/theburrowhub/thaimaturgy/internal/domain"
+	"github.com/theburrowhub/thaimaturgy/internal/types"
+)
+
+var roomTool = types.Tool{Name: "get_room", Description: "Look up a room.", Parameters: json.RawMessage(`{"type":"object","properties":{"room_id":{"type":"string"}}}`)}
+
+// ollamaStub mimics an Ollama server hosting a model without tool support: it
+// lists its models, rejects requests that carry tools, and otherwise replies
+// with reply, handing each chat request to check.
+func ollamaStub(t *testing.T, reply string, check func(req map[string]any)) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/v1/models" {
+			_, _ = io.WriteString(w, `{"object":"list","data":[{"id":"llama3.2:3b"},{"id":"qwen2.5:7b"}]}`)
+			return
+		}
+		body, _ := io.ReadAll(r.Body)
+		var req map[string]any
+		_ = json.Unmarshal(body, &req)
+		if check != nil {
+			check(req)
+		}
+		if _, ok := req["tools"]; ok {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = io.WriteString(w, `{"error":{"message":"registry.ollama.ai/library/llama3.2:3b does not support tools","type":"api_error","param":null,"code":null}}`)
+			return
+		}
+		out, _ := json.Marshal(map[string]any{
+			"model":   req["model"],
+			"choices": []any{map[string]any{"message": map[string]any{"role": "assistant", "content": reply}, "finish_reason": "stop"}},
+			"usage":   map[string]any{"prompt_tokens": 40, "completion_tokens": 8, "total_tokens": 48},
+		})
+		_, _ = w.Write(out)
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestLocalFallsBackToPromptTools(t *testing.T) {
+	var requests atomic.Int32
+	var lastSystem string
+	srv := ollamaStub(t, "```json\n{\"tool_calls\": [{\"name\": \"get_room\", \"arguments\": {\"room_id\": \"r1\"}}]}\n```", func(req map[string]any) {
+		requests.Add(1)
+		if req["model"] != "llama3.2:3b" {
+			t.Errorf("model = %v, want the first listed model", req["model"])
+		}
+		msgs := req["messages"].([]any)
+		lastSystem = msgs[0].(map[string]any)["content"].(string)
+	})
+	p := NewLocalProvider(srv.URL, "", "")
+
+	req := ChatRequest{
+		Messages: []Message{
+			{Role: RoleSystem, Content: "You are the DM.", Cache: true},
+			{Role: RoleSystem, Content: "The party is at the gate."},
+			{Role: RoleUser, Content: "I look around."},
+		},
+		Tools: []types.Tool{roomTool},
+	}
+	resp, err := p.Chat(context.Background(), req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if requests.Load() != 2 {
+		t.Errorf("requests = %d, want the native try and the prompt retry", requests.Load())
+	}
+	if len(resp.ToolCalls) != 1 || resp.ToolCalls[0].Function.Name != "get_room" ||
+		resp.ToolCalls[0].Function.Arguments != `{"room_id": "r1"}` || resp.ToolCalls[0].ID == "" {
+		t.Fatalf("tool calls = %+v", resp.ToolCalls)
+	}
+	if resp.Content != "" || resp.FinishReason != "tool_calls" || resp.Usage.TotalTokens != 48 {
+		t.Errorf("response = %+v", resp)
+	}
+	if !strings.HasPrefix(lastSystem, "You are the DM.\n\nThe party is at the gate.") || !strings.Contains(lastSystem, "- get_room: Look up a room.") {
+		t.Errorf("system prompt = %q", lastSystem)
+	}
+
+	// The switch sticks: the next request goes straight to the prompt protocol.
+	requests.Store(0)
+	if _, err := p.Chat(context.Background(), req); err != nil {
+		t.Fatal(err)
+	}
+	if requests.Load() != 1 {
+		t.Errorf("requests after the switch = %d, want 1", requests.Load())
+	}
+}
+
+func TestLocalNativeModeDoesNotFallBack(t *testing.T) {
+	srv := ollamaStub(t, "unused", nil)
+	p := NewLocalProvider(srv.URL, "", domain.LocalToolsNative)
+	_, err := p.Chat(context.Background(), ChatRequest{Model: "m", Messages: []Message{{Role: RoleUser, Content: "hi"}}, Tools: []types.Tool{roomTool}})
+	if err == nil || !strings.Contains(err.Error(), "does not support tools") {
+		t.Errorf("err = %v, want the server's tool error", err)
+	}
+}
+
+func TestLocalPromptModeStreamsPlainReply(t *testing.T) {
+	var sent map[string]any
+	srv := ollamaStub(t, "The gate is shut.", func(req map[string]any) { sent = req })
+	p := NewLocalProvider(srv.URL, "", domain.LocalToolsPrompt)
+
+	req := ChatRequest{
+		Model: "qwen2.5:7b",
+		Messages: []Message{
+			{Role: RoleSystem, Content: "You are the DM."},
+			{Role: RoleUser, Content: "Open the gate."},
+			{Role: RoleAssistant, ToolCalls: []ToolCallInfo{{ID: "c1", Type: "function", Function: FunctionCall{Name: "get_room", Arguments: `{"room_id":"r1"}`}}}},
+			{Role: RoleTool, ToolCallID: "c1", Content: "Gate: a rusted portcullis."},
+		},
+		Tools: []types.Tool{roomTool},
+	}
+	var deltas []string
+	resp, err := p.ChatStream(context.Background(), req, collect(&deltas))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Content != "The gate is shut." || len(resp.ToolCalls) != 0 || len(deltas) != 1 || deltas[0] != resp.Content {
+		t.Errorf("response = %+v, deltas = %q", resp, deltas)
+	}
+	msgs := sent["messages"].([]any)
+	if len(msgs) != 4 {
+		t.Fatalf("messages = %v", msgs)
+	}
+	call := msgs[2].(map[string]any)
+	result := msgs[3].(map[string]any)
+	if call["content"] != `{"tool_calls":[{"name":"get_room","arguments":{"room_id":"r1"}}]}` || call["tool_calls"] != nil {
+		t.Errorf("assistant call = %v", call)
+	}
+	if result["role"] != "user" || result["content"] != "Result of get_room:\nGate: a rusted portcullis." {
+		t.Errorf("tool result = %v", result)
+	}
+}
+
+func TestParsePromptToolCalls(t *testing.T) {
+	for _, tc := range []struct {
+		in   string
+		want int
+	}{
+		{`{"tool_calls":[{"name":"roll","arguments":{"dice":"1d20"}},{"name":"get_room"}]}`, 2},
+		{"Let me check.\n{\"tool_calls\": [{\"name\": \"roll\", \"arguments\": {}}]}", 1},
+		{"The door creaks open.", 0},
+		{`{"tool_calls": "nope"}`, 0},
+		{`{"answer": {"x": 1}}`, 0},
+	} {
+		got := parsePromptToolCalls(tc.in)
+		if len(got) != tc.want {
+			t.Errorf("parsePromptToolCalls(%q) = %+v, want %d call(s)", tc.in, got, tc.want)
+		}
+		for _, c := range got {
+			if c.Function.Arguments == "" {
+				t.Errorf("call %+v has no arguments", c)
+			}
+		}
+	}
+}
+
+func TestListModelsAndNormalizeBaseURL(t *testing.T) {
+	srv := ollamaStub(t, "", nil)
+	models, err := ListModels(context.Background(), strings.TrimPrefix(srv.URL, "http://"), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(models, ",") != "llama3.2:3b,qwen2.5:7b" {
+		t.Errorf("models = %v", models)
+	}
+	for in, want := range map[string]string{
+		"localhost:11434":            "http://localhost:11434/v1",
+		"http://127.0.0.1:8080/":     "http://127.0.0.1:8080/v1",
+		"https://box.lan/llm/v1/":    "https://box.lan/llm/v1",
+		" http://localhost:1234/v1 ": "http://localhost:1234/v1",
+	} {
+		if got := NormalizeBaseURL(in); got != want {
+			t.Errorf("NormalizeBaseURL(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
@@ -0,0 +1,46 @@
+package providers
+
+import "context"
+
+// UsageFunc receives the usage of a completed call, with the provider that
+// answered and the model it was for (the request's, else the one the response
+// names; a fallback chain's answer names both).
+type UsageFunc func(provider, model string, u Usage)
+
+// Meter wraps p so that every completed call reports its usage to record. It is
+// for callers that hand a provider to code that doesn't track usage itself
+// (novel writing, AI import).
+func Meter(p Provider, record UsageFunc) Provider {
+	return &metered{Provider: p, record: record}
+}
+
+type metered struct {
+	Provider
+	record UsageFunc
+}
+
+func (m *metered) Chat(ctx context.Context, req ChatRequest) (*ChatResponse, error) {
+	resp, err := m.Provider.Chat(ctx, req)
+	m.note(req, resp)
+	return resp, err
+}
+
+func (m *metered) ChatStream(ctx context.Context, req ChatRequest, fn StreamFunc) (*ChatResponse, error) {
+	resp, err := m.Provider.ChatStream(ctx, req, fn)
+	m.note(req, resp)
+	return resp, err
+}
+
+func (m *metered) note(req ChatRequest, resp *ChatResponse) {
+	if resp == nil {
+		return
+	}
+	name, model := m.Provider.Name(), req.Model
+	if resp.Provider != "" {
+		name, model = resp.Provider, resp.Model
+	}
+	if model == "" {
+		model = resp.Model
+	}
+	m.record(name, model, resp.Usage)
+}
@@ -8,15 +8,18 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/theburrowhub/thaimaturgy/internal/types"
 )
 
-const openAIBaseURL = "https://api.openai.com/v1"
+// openAIBaseURL is a var (not const) so tests can point it at a stub server.
+var openAIBaseURL = "https://api.openai.com/v1"
 
 type OpenAIProvider struct {
 	apiKey     string
+	baseURL    string // empty means openAIBaseURL
 	httpClient *http.Client
 }
 
@@ -43,6 +46,13 @@ type openAIRequest struct {
 	Tools       []openAITool    `json:"tools,omitempty"`
 	Temperature float64         `json:"temperature,omitempty"`
 	MaxTokens   int             `json:"max_tokens,omitempty"`
+	Stream      bool            `json:"stream,omitempty"`
+	// StreamOptions asks for a final chunk carrying the usage when streaming.
+	StreamOptions *openAIStreamOptions `json:"stream_options,omitempty"`
+}
+
+type openAIStreamOptions struct {
+	IncludeUsage bool `json:"include_usage"`
 }
 
 type openAIMessage struct {
@@ -103,12 +113,26 @@ type openAIResponse struct {
 		TotalTokens      int `json:"total_tokens"`
 	} `json:"usage"`
 	Error *struct {
-		Message string `json:"message"`
-		Type    string `json:"type"`
-		Code    string `json:"code"`
+		Message string     `json:"message"`
+		Type    string     `json:"type"`
+		Code    openAICode `json:"code"`
 	} `json:"error,omitempty"`
 }
 
+// openAICode is an API error code: a string from OpenAI, a number from some
+// compatible servers (llama.cpp).
+type openAICode string
+
+func (c *openAICode) UnmarshalJSON(b []byte) error {
+	var s string
+	if json.Unmarshal(b, &s) == nil {
+		*c = openAICode(s)
+		return nil
+	}
+	*c = openAICode(strings.Trim(string(b), `"`))
+	return nil
+}
+
 func (p *OpenAIProvider) Chat(ctx context.Context, req ChatRequest) (*ChatResponse, error) {
 	startTime := time.Now()
 
@@ -119,15 +143,7 @@ func (p *OpenAIProvider) Chat(ctx context.Context, req ChatRequest) (*ChatRespon
 		return nil, fmt.Errorf("failed to marshal request: %w", err)
 	}
 
-	resp, err := doWithRetry(ctx, p.httpClient, func() (*http.Request, error) {
-		r, err := http.NewRequestWithContext(ctx, "POST", openAIBaseURL+"/chat/completions", bytes.NewReader(body))
-		if err != nil {
-			return nil, err
-		}
-		r.Header.Set("Content-Type", "application/json")
-		r.Header.Set("Authorization", "Bearer "+p.apiKey)
-		return r, nil
-	})
+	resp, err := p.post(ctx, body)
 	if err != nil {
 		return nil, fmt.Errorf("request failed: %w", err)
 	}
@@ -140,6 +156,9 @@ func (p *OpenAIProvider) Chat(ctx context.Context, req ChatRequest) (*ChatRespon
 
 	var openAIResp openAIResponse
 	if err := json.Unmarshal(respBody, &openAIResp); err != nil {
+		if resp.StatusCode != http.StatusOK {
+			return nil, fmt.Errorf("unexpected status code: %d (body: %s)", resp.StatusCode, string(respBody))
+		}
 		return nil, fmt.Errorf("failed to parse response: %w", err)
 	}
 
@@ -149,7 +168,7 @@ func (p *OpenAIProvider) Chat(ctx context.Context, req ChatRequest) (*ChatRespon
 	}
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+		return nil, fmt.Errorf("unexpected status code: %d (body: %s)", resp.StatusCode, string(respBody))
 	}
 
 	if len(openAIResp.Choices) == 0 {
@@ -161,6 +180,144 @@ func (p *OpenAIProvider) Chat(ctx context.Context, req ChatRequest) (*ChatRespon
 	return p.convertResponse(openAIResp, latency), nil
 }
 
+// post sends a chat completions request body.
+func (p *OpenAIProvider) post(ctx context.Context, body []byte) (*http.Response, error) {
+	return doWithRetry(ctx, p.httpClient, func() (*http.Request, error) {
+		r, err := http.NewRequestWithContext(ctx, "POST", p.endpoint()+"/chat/completions", bytes.NewReader(body))
+		if err != nil {
+			return nil, err
+		}
+		r.Header.Set("Content-Type", "application/json")
+		if p.apiKey != "" { // local servers often take none
+			r.Header.Set("Authorization", "Bearer "+p.apiKey)
+		}
+		return r, nil
+	})
+}
+
+func (p *OpenAIProvider) endpoint() string {
+	if p.baseURL != "" {
+		return p.baseURL
+	}
+	return openAIBaseURL
+}
+
+// openAIStreamChunk is one "data:" event of a streamed chat completion. Tool
+// calls arrive in pieces keyed by index: the id and name first, then the
+// arguments JSON in fragments.
+type openAIStreamChunk struct {
+	Model   string `json:"model"`
+	Choices []struct {
+		Delta struct {
+			Content   string `json:"content"`
+			ToolCalls []struct {
+				Index    int    `json:"index"`
+				ID       string `json:"id"`
+				Type     string `json:"type"`
+				Function struct {
+					Name      string `json:"name"`
+					Arguments string `json:"arguments"`
+				} `json:"function"`
+			} `json:"tool_calls"`
+		} `json:"delta"`
+		FinishReason string `json:"finish_reason"`
+	} `json:"choices"`
+	Usage *struct {
+		PromptTokens     int `json:"prompt_tokens"`
+		CompletionTokens int `json:"completion_tokens"`
+		TotalTokens      int `json:"total_tokens"`
+	} `json:"usage"`
+	Error *struct {
+		Message string     `json:"message"`
+		Type    string     `json:"type"`
+		Code    openAICode `json:"code"`
+	} `json:"error,omitempty"`
+}
+
+// ChatStream streams the completion over SSE ("stream": true), reassembling the
+// text and tool calls into the same response Chat returns.
+func (p *OpenAIProvider) ChatStream(ctx context.Context, req ChatRequest, fn StreamFunc) (*ChatResponse, error) {
+	startTime := time.Now()
+	if fn == nil {
+		fn = func(string) {}
+	}
+
+	openAIReq := p.convertRequest(req)
+	openAIReq.Stream = true
+	openAIReq.StreamOptions = &openAIStreamOptions{IncludeUsage: true}
+	body, err := json.Marshal(openAIReq)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	resp, err := p.post(ctx, body)
+	if err != nil {
+		return nil, fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, streamStatusError(resp, func(b []byte) error {
+			var r openAIResponse
+			if json.Unmarshal(b, &r) == nil && r.Error != nil {
+				return fmt.Errorf("OpenAI API error: %s (type: %s, code: %s)", r.Error.Message, r.Error.Type, r.Error.Code)
+			}
+			return nil
+		})
+	}
+
+	out := &ChatResponse{}
+	var content strings.Builder
+	err = readSSE(resp.Body, func(_, data string) error {
+		if data == "[DONE]" {
+			return errStopSSE
+		}
+		var chunk openAIStreamChunk
+		if err := json.Unmarshal([]byte(data), &chunk); err != nil {
+			return fmt.Errorf("failed to parse stream chunk: %w", err)
+		}
+		if chunk.Error != nil {
+			return fmt.Errorf("OpenAI API error: %s (type: %s, code: %s)", chunk.Error.Message, chunk.Error.Type, chunk.Error.Code)
+		}
+		if chunk.Model != "" {
+			out.Model = chunk.Model
+		}
+		if u := chunk.Usage; u != nil {
+			out.Usage = Usage{PromptTokens: u.PromptTokens, CompletionTokens: u.CompletionTokens, TotalTokens: u.TotalTokens}
+		}
+		if len(chunk.Choices) == 0 {
+			return nil
+		}
+		choice := chunk.Choices[0]
+		if choice.Delta.Content != "" {
+			content.WriteString(choice.Delta.Content)
+			fn(choice.Delta.Content)
+		}
+		for _, tc := range choice.Delta.ToolCalls {
+			for len(out.ToolCalls) <= tc.Index {
+				out.ToolCalls = append(out.ToolCalls, ToolCallInfo{Type: "function"})
+			}
+			call := &out.ToolCalls[tc.Index]
+			if tc.ID != "" {
+				call.ID = tc.ID
+			}
+			if tc.Function.Name != "" {
+				call.Function.Name = tc.Function.Name
+			}
+			call.Function.Arguments += tc.Function.Arguments
+		}
+		if choice.FinishReason != "" {
+			out.FinishReason = choice.FinishReason
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	out.Content = content.String()
+	out.Latency = time.Since(startTime).Milliseconds()
+	return out, nil
+}
+
 func (p *OpenAIProvider) convertRequest(req ChatRequest) openAIRequest {
 	messages := make([]openAIMessage, len(req.Messages))
 	for i, msg := range req.Messages {
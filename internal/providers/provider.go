@@ -25,6 +25,10 @@ type Message struct {
 	// Images attaches inline image inputs to a user message for multimodal
 	// (vision) models. Encoded per-provider at request time.
 	Images []ImageData `json:"-"`
+	// Cache marks a system message as the end of a stable prompt prefix (it and
+	// everything before it, tools included, repeat unchanged from call to call).
+	// Backends with prompt caching cache up to it; the rest ignore it.
+	Cache bool `json:"-"`
 }
 
 // ImageData is an inline image attached to a message for vision models.
@@ -59,16 +63,33 @@ type ChatResponse struct {
 	Usage        Usage          `json:"usage"`
 	Model        string         `json:"model"`
 	Latency      int64          `json:"latency_ms"`
+	// Provider names the backend that answered, and Fallback is set when it
+	// wasn't the first choice. Only a fallback chain fills them in.
+	Provider string `json:"provider,omitempty"`
+	Fallback bool   `json:"fallback,omitempty"`
 }
 
+// Usage is a call's token count. Prompt tokens read from or written to a
+// prompt cache are counted apart from PromptTokens (they are billed at other
+// rates) but are part of TotalTokens.
 type Usage struct {
 	PromptTokens     int `json:"prompt_tokens"`
 	CompletionTokens int `json:"completion_tokens"`
 	TotalTokens      int `json:"total_tokens"`
+	CacheReadTokens  int `json:"cache_read_tokens,omitempty"`
+	CacheWriteTokens int `json:"cache_write_tokens,omitempty"`
 }
 
+// StreamFunc receives a streamed reply's text as it is generated, one delta at
+// a time, in order, on the calling goroutine.
+type StreamFunc func(delta string)
+
 type Provider interface {
 	Chat(ctx context.Context, req ChatRequest) (*ChatResponse, error)
+	// ChatStream is Chat with the reply delivered incrementally to fn as it is
+	// generated; it still returns the complete response (text, tool calls,
+	// usage). Backends that can't stream deliver the whole text in one delta.
+	ChatStream(ctx context.Context, req ChatRequest, fn StreamFunc) (*ChatResponse, error)
 	Name() string
 	SupportsTools() bool
 	// SupportsVision reports whether the backend accepts inline image inputs
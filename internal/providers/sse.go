@@ -0,0 +1,90 @@
+package providers
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// maxSSELine bounds one Server-Sent-Events line; a streamed chunk carrying a
+// large tool-call argument can exceed bufio's default 64 KiB.
+const maxSSELine = 4 << 20
+
+// errStopSSE ends readSSE without an error: fn returns it on the provider's
+// terminal event (OpenAI's "data: [DONE]", Anthropic's message_stop).
+var errStopSSE = errors.New("stop")
+
+// errStreamTruncated is readSSE's error when the body ends before fn saw the
+// terminal event: the connection dropped mid-reply, and what arrived is only
+// part of it. It reads as an unexpected EOF, so a fallback chain treats it as
+// the network failure it is.
+var errStreamTruncated = fmt.Errorf("stream ended before the reply was complete: %w", io.ErrUnexpectedEOF)
+
+// readSSE parses a text/event-stream body, calling fn with each event's name
+// ("" when the stream doesn't name events) and its data lines joined by "\n".
+// Comments and unknown fields are skipped. It returns fn's first error, except
+// errStopSSE, which ends the stream cleanly; a body that ends before fn returns
+// errStopSSE is errStreamTruncated.
+func readSSE(r io.Reader, fn func(event, data string) error) error {
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 0, 64<<10), maxSSELine)
+	var event string
+	var data []string
+	dispatch := func() error {
+		if len(data) == 0 {
+			event = ""
+			return nil
+		}
+		err := fn(event, strings.Join(data, "\n"))
+		event, data = "", nil
+		return err
+	}
+	for sc.Scan() {
+		line := strings.TrimSuffix(sc.Text(), "\r")
+		if line == "" {
+			if err := dispatch(); err != nil {
+				if err == errStopSSE {
+					return nil
+				}
+				return err
+			}
+			continue
+		}
+		if strings.HasPrefix(line, ":") {
+			continue
+		}
+		field, value, _ := strings.Cut(line, ":")
+		value = strings.TrimPrefix(value, " ")
+		switch field {
+		case "event":
+			event = value
+		case "data":
+			data = append(data, value)
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return err
+	}
+	switch err := dispatch(); err {
+	case errStopSSE:
+		return nil
+	case nil:
+		return errStreamTruncated
+	default:
+		return err
+	}
+}
+
+// streamStatusError turns a non-200 reply to a streaming request (a JSON error
+// body, not an event stream) into an error: the provider's API error when
+// apiErr recognizes one, else the status and body.
+func streamStatusError(resp *http.Response, apiErr func([]byte) error) error {
+	b, _ := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err := apiErr(b); err != nil {
+		return err
+	}
+	return fmt.Errorf("unexpected status code: %d (body: %s)", resp.StatusCode, string(b))
+}
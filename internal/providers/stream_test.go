@@ -0,0 +1,276 @@
+package providers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// sseServer replies to every request with the given event-stream body, after
+// handing the decoded request body to check.
+func sseServer(t *testing.T, stream string, check func(path string, req map[string]any)) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		var req map[string]any
+		_ = json.Unmarshal(body, &req)
+		if check != nil {
+			check(r.URL.String(), req)
+		}
+		w.Header().Set("Content-Type", "text/event-stream")
+		_, _ = w.Write([]byte(stream))
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+// collect records the deltas a stream delivers.
+func collect(deltas *[]string) StreamFunc {
+	return func(d string) { *deltas = append(*deltas, d) }
+}
+
+func TestReadSSE(t *testing.T) {
+	in := ": comment\r\nevent: a\r\ndata: one\r\ndata: two\r\n\r\ndata: three\n\nid: 7\n\ndata: last"
+	var got []string
+	err := readSSE(strings.NewReader(in), func(event, data string) error {
+		got = append(got, event+"|"+data)
+		if data == "last" {
+			return errStopSSE
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"a|one\ntwo", "|three", "|last"}
+	if strings.Join(got, ";") != strings.Join(want, ";") {
+		t.Errorf("events = %q, want %q", got, want)
+	}
+
+	err = readSSE(strings.NewReader("data: one\n\n"), func(string, string) error { return nil })
+	if !errors.Is(err, errStreamTruncated) {
+		t.Errorf("a body without its terminal event read as %v", err)
+	}
+}
+
+// TestChatStreamTruncated verifies a stream cut off before the provider's
+// terminal event fails instead of passing off part of a reply as all of it.
+func TestChatStreamTruncated(t *testing.T) {
+	cases := []struct {
+		name, stream string
+		base         *string
+		p            Provider
+	}{
+		{"openai", "data: {\"choices\":[{\"delta\":{\"content\":\"The door \"}}]}\n\n", &openAIBaseURL, NewOpenAIProvider("sk")},
+		{"anthropic", "event: content_block_delta\ndata: {\"type\":\"content_block_delta\",\"index\":0,\"delta\":{\"type\":\"text_delta\",\"text\":\"The door \"}}\n\n", &anthropicBaseURL, NewAnthropicProvider("sk")},
+		{"gemini", "data: {\"candidates\":[{\"content\":{\"role\":\"model\",\"parts\":[{\"text\":\"The door \"}]}}]}\n\n", &geminiBaseURL, NewGeminiProvider("k")},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			srv := sseServer(t, c.stream, nil)
+			orig := *c.base
+			*c.base = srv.URL
+			defer func() { *c.base = orig }()
+
+			var deltas []string
+			resp, err := c.p.ChatStream(context.Background(), ChatRequest{Model: "m"}, collect(&deltas))
+			if !errors.Is(err, errStreamTruncated) {
+				t.Errorf("got %+v, %v; want the truncation error", resp, err)
+			}
+			if len(deltas) != 1 {
+				t.Errorf("deltas = %q", deltas)
+			}
+		})
+	}
+}
+
+func TestOpenAIChatStream(t *testing.T) {
+	stream := `data: {"model":"gpt-x","choices":[{"delta":{"content":"The door "}}]}
+
+data: {"choices":[{"delta":{"content":"creaks."}}]}
+
+data: {"choices":[{"delta":{"tool_calls":[{"index":0,"id":"call_1","type":"function","function":{"name":"get_room","arguments":""}}]}}]}
+
+data: {"choices":[{"delta":{"tool_calls":[{"index":0,"function":{"arguments":"{\"room_id\":"}}]}}]}
+
+data: {"choices":[{"delta":{"tool_calls":[{"index":0,"function":{"arguments":"\"r1\"}"}}]}}]}
+
+data: {"choices":[{"delta":{},"finish_reason":"tool_calls"}]}
+
+data: {"choices":[],"usage":{"prompt_tokens":10,"completion_tokens":5,"total_tokens":15}}
+
+data: [DONE]
+
+`
+	srv := sseServer(t, stream, func(_ string, req map[string]any) {
+		if req["stream"] != true {
+			t.Errorf("request not marked stream: %v", req)
+		}
+	})
+	orig := openAIBaseURL
+	openAIBaseURL = srv.URL
+	defer func() { openAIBaseURL = orig }()
+
+	var deltas []string
+	resp, err := NewOpenAIProvider("sk").ChatStream(context.Background(), ChatRequest{Model: "gpt-x"}, collect(&deltas))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Content != "The door creaks." || resp.FinishReason != "tool_calls" || resp.Usage.TotalTokens != 15 || resp.Model != "gpt-x" {
+		t.Errorf("response = %+v", resp)
+	}
+	if len(resp.ToolCalls) != 1 || resp.ToolCalls[0].ID != "call_1" || resp.ToolCalls[0].Function.Arguments != `{"room_id":"r1"}` {
+		t.Errorf("tool calls = %+v", resp.ToolCalls)
+	}
+	if len(deltas) != 2 || deltas[0] != "The door " {
+		t.Errorf("deltas = %+v", deltas)
+	}
+}
+
+func TestAnthropicChatStream(t *testing.T) {
+	stream := `event: message_start
+data: {"type":"message_start","message":{"model":"claude-x","usage":{"input_tokens":12,"cache_creation_input_tokens":300}}}
+
+event: content_block_start
+data: {"type":"content_block_start","index":0,"content_block":{"type":"text","text":""}}
+
+event: ping
+data: {"type":"ping"}
+
+event: content_block_delta
+data: {"type":"content_block_delta","index":0,"delta":{"type":"text_delta","text":"Let me look."}}
+
+event: content_block_start
+data: {"type":"content_block_start","index":1,"content_block":{"type":"tool_use","id":"tu_1","name":"roll_dice"}}
+
+event: content_block_delta
+data: {"type":"content_block_delta","index":1,"delta":{"type":"input_json_delta","partial_json":"{\"expr"}}
+
+event: content_block_delta
+data: {"type":"content_block_delta","index":1,"delta":{"type":"input_json_delta","partial_json":"ession\":\"1d20\"}"}}
+
+event: message_delta
+data: {"type":"message_delta","delta":{"stop_reason":"tool_use"},"usage":{"output_tokens":7}}
+
+event: message_stop
+data: {"type":"message_stop"}
+
+`
+	srv := sseServer(t, stream, func(_ string, req map[string]any) {
+		if req["stream"] != true {
+			t.Errorf("request not marked stream: %v", req)
+		}
+	})
+	orig := anthropicBaseURL
+	anthropicBaseURL = srv.URL
+	defer func() { anthropicBaseURL = orig }()
+
+	var deltas []string
+	resp, err := NewAnthropicProvider("sk").ChatStream(context.Background(), ChatRequest{Model: "claude-x", MaxTokens: 16}, collect(&deltas))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Content != "Let me look." || resp.FinishReason != "tool_calls" || resp.Usage.TotalTokens != 319 || resp.Usage.CacheWriteTokens != 300 || resp.Model != "claude-x" {
+		t.Errorf("response = %+v", resp)
+	}
+	if len(resp.ToolCalls) != 1 || resp.ToolCalls[0].ID != "tu_1" || resp.ToolCalls[0].Function.Arguments != `{"expression":"1d20"}` {
+		t.Errorf("tool calls = %+v", resp.ToolCalls)
+	}
+	if len(deltas) != 1 || deltas[0] != "Let me look." {
+		t.Errorf("deltas = %+v", deltas)
+	}
+}
+
+// TestAnthropicChatStreamFallback verifies a throttled model falls back before
+// anything is streamed, as Chat does.
+func TestAnthropicChatStreamFallback(t *testing.T) {
+	var models []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		var req map[string]any
+		_ = json.Unmarshal(body, &req)
+		model, _ := req["model"].(string)
+		models = append(models, model)
+		if model != anthropicFallbackModel {
+			w.WriteHeader(http.StatusTooManyRequests)
+			_, _ = w.Write([]byte(`{"error":{"type":"rate_limit_error","message":"slow down"}}`))
+			return
+		}
+		_, _ = w.Write([]byte("data: {\"type\":\"content_block_delta\",\"index\":0,\"delta\":{\"type\":\"text_delta\",\"text\":\"OK\"}}\n\n" +
+			"data: {\"type\":\"message_stop\"}\n\n"))
+	}))
+	defer srv.Close()
+	orig := anthropicBaseURL
+	anthropicBaseURL = srv.URL
+	defer func() { anthropicBaseURL = orig }()
+
+	resp, err := NewAnthropicProvider("sk").ChatStream(context.Background(), ChatRequest{Model: "claude-sonnet-5"}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Content != "OK" || len(models) != 2 {
+		t.Errorf("content %q after calls %v", resp.Content, models)
+	}
+}
+
+// TestAnthropicChatStreamNoFallbackAfterText verifies a stream that fails
+// mid-reply isn't replayed on the fallback model: the caller already has the
+// first part, and a second attempt would deliver it again.
+func TestAnthropicChatStreamNoFallbackAfterText(t *testing.T) {
+	calls := 0
+	srv := sseServer(t, `event: content_block_delta
+data: {"type":"content_block_delta","index":0,"delta":{"type":"text_delta","text":"The door "}}
+
+event: error
+data: {"type":"error","error":{"type":"rate_limit_error","message":"slow down"}}
+
+`, func(string, map[string]any) { calls++ })
+	orig := anthropicBaseURL
+	anthropicBaseURL = srv.URL
+	defer func() { anthropicBaseURL = orig }()
+
+	var deltas []string
+	_, err := NewAnthropicProvider("sk").ChatStream(context.Background(), ChatRequest{Model: "claude-sonnet-5"}, collect(&deltas))
+	if err == nil || !strings.Contains(err.Error(), "rate_limit_error") {
+		t.Errorf("err = %v; want the mid-stream error", err)
+	}
+	if calls != 1 || len(deltas) != 1 {
+		t.Errorf("%d request(s), deltas %q; want one of each", calls, deltas)
+	}
+}
+
+func TestGeminiChatStream(t *testing.T) {
+	stream := `data: {"candidates":[{"content":{"role":"model","parts":[{"text":"Roll "}]}}]}
+
+data: {"candidates":[{"content":{"role":"model","parts":[{"text":"for it."},{"functionCall":{"name":"roll_dice","args":{"expression":"1d20"}}}]},"finishReason":"STOP"}],"usageMetadata":{"promptTokenCount":4,"candidatesTokenCount":3,"totalTokenCount":7}}
+
+`
+	var path string
+	srv := sseServer(t, stream, func(p string, _ map[string]any) { path = p })
+	orig := geminiBaseURL
+	geminiBaseURL = srv.URL
+	defer func() { geminiBaseURL = orig }()
+
+	var deltas []string
+	resp, err := NewGeminiProvider("k").ChatStream(context.Background(), ChatRequest{Model: "gemini-x"}, collect(&deltas))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(path, "gemini-x:streamGenerateContent?alt=sse&key=k") {
+		t.Errorf("request path = %s", path)
+	}
+	if resp.Content != "Roll for it." || resp.FinishReason != "tool_calls" || resp.Usage.TotalTokens != 7 {
+		t.Errorf("response = %+v", resp)
+	}
+	if len(resp.ToolCalls) != 1 || resp.ToolCalls[0].Function.Arguments != `{"expression":"1d20"}` {
+		t.Errorf("tool calls = %+v", resp.ToolCalls)
+	}
+	if len(deltas) != 2 || deltas[1] != "for it." {
+		t.Errorf("deltas = %+v", deltas)
+	}
+}
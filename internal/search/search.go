@@ -0,0 +1,286 @@
+// Package search is a small in-memory full-text index: documents made of
+// weighted fields, ranked with BM25, with light English and Spanish stemming
+// and an excerpt of the best matching field for each hit. It knows nothing
+// about adventures — the engine turns a module into documents.
+package search
+
+import (
+	"math"
+	"sort"
+	"strings"
+)
+
+// BM25 parameters: term-frequency saturation and length normalisation.
+const (
+	bm25K1 = 1.2
+	bm25B  = 0.75
+)
+
+// prefixWeight scales the score of a query word matched only as the prefix of
+// an indexed term ("gob" finding "goblin"), so whole-word matches rank first.
+const prefixWeight = 0.5
+
+// Field is one searchable text of a document. Boost weights its matches (a hit
+// in a name counts more than one in a long description); zero means 1.
+type Field struct {
+	Name  string
+	Text  string
+	Boost float64
+}
+
+// Doc is one searchable entity.
+type Doc struct {
+	Kind   string // e.g. "room", "npc"
+	ID     string
+	Name   string
+	Fields []Field
+}
+
+// Hit is a ranked search result. Field names the field the excerpt comes from.
+type Hit struct {
+	Kind    string  `json:"kind"`
+	ID      string  `json:"id"`
+	Name    string  `json:"name"`
+	Score   float64 `json:"score"`
+	Field   string  `json:"field,omitempty"`
+	Snippet string  `json:"snippet,omitempty"`
+}
+
+type posting struct {
+	doc int
+	tf  float64 // boost-weighted term frequency
+}
+
+// Index is an immutable inverted index over a set of documents. It is safe
+// for concurrent searches.
+type Index struct {
+	lang     string
+	docs     []Doc
+	lens     []float64 // boost-weighted document lengths
+	avgLen   float64
+	postings map[string][]posting
+}
+
+// New indexes docs, stemming for lang ("es" for Spanish; anything else is
+// treated as English).
+func New(docs []Doc, lang string) *Index {
+	ix := &Index{lang: lang, docs: docs, lens: make([]float64, len(docs)), postings: map[string][]posting{}}
+	var total float64
+	for d, doc := range docs {
+		tf := map[string]float64{}
+		for _, f := range doc.Fields {
+			boost := f.Boost
+			if boost == 0 {
+				boost = 1
+			}
+			for _, t := range tokenize(f.Text, lang) {
+				tf[t.term] += boost
+				ix.lens[d] += boost
+			}
+		}
+		for term, n := range tf {
+			ix.postings[term] = append(ix.postings[term], posting{doc: d, tf: n})
+		}
+		total += ix.lens[d]
+	}
+	if len(docs) > 0 {
+		ix.avgLen = total / float64(len(docs))
+	}
+	return ix
+}
+
+// Len returns the number of indexed documents.
+func (ix *Index) Len() int { return len(ix.docs) }
+
+// Search ranks the documents matching any word of query, best first, and
+// returns at most limit of them (all when limit <= 0). Documents matching more
+// of the words rank above those matching fewer. keep, when non-nil, filters
+// documents out before ranking.
+func (ix *Index) Search(query string, limit int, keep func(kind, id string) bool) []Hit {
+	words := queryTerms(query, ix.lang)
+	if len(words) == 0 || len(ix.docs) == 0 {
+		return nil
+	}
+	scores := map[int]float64{}
+	matched := map[int]int{}
+	for _, w := range words {
+		seen := map[int]bool{}
+		for _, m := range ix.expand(w) {
+			for _, p := range ix.postings[m.term] {
+				if keep != nil && !keep(ix.docs[p.doc].Kind, ix.docs[p.doc].ID) {
+					continue
+				}
+				scores[p.doc] += m.weight * ix.bm25(m.term, p)
+				if !seen[p.doc] {
+					seen[p.doc] = true
+					matched[p.doc]++
+				}
+			}
+		}
+	}
+
+	type ranked struct {
+		doc   int
+		score float64
+	}
+	order := make([]ranked, 0, len(scores))
+	for d, sc := range scores {
+		order = append(order, ranked{d, sc * float64(matched[d]) / float64(len(words))})
+	}
+	sort.Slice(order, func(i, j int) bool {
+		a, b := order[i], order[j]
+		if a.score != b.score {
+			return a.score > b.score
+		}
+		if ix.docs[a.doc].Kind != ix.docs[b.doc].Kind {
+			return ix.docs[a.doc].Kind < ix.docs[b.doc].Kind
+		}
+		return ix.docs[a.doc].ID < ix.docs[b.doc].ID
+	})
+	if limit > 0 && len(order) > limit {
+		order = order[:limit]
+	}
+	hits := make([]Hit, len(order))
+	for i, r := range order {
+		doc := ix.docs[r.doc]
+		hits[i] = Hit{Kind: doc.Kind, ID: doc.ID, Name: doc.Name, Score: r.score}
+		hits[i].Field, hits[i].Snippet = ix.snippet(doc, words)
+	}
+	return hits
+}
+
+// queryWord is a word of a query: its stemmed term and the folded, unstemmed
+// form used for prefix matching.
+type queryWord struct{ term, raw string }
+
+func queryTerms(query, lang string) []queryWord {
+	var out []queryWord
+	seen := map[string]bool{}
+	for _, t := range tokenize(query, lang) {
+		if !seen[t.term] {
+			seen[t.term] = true
+			out = append(out, queryWord{term: t.term, raw: t.raw})
+		}
+	}
+	return out
+}
+
+type expansion struct {
+	term   string
+	weight float64
+}
+
+// expand returns the indexed terms a query word matches: its own stem when
+// indexed, else every term its stem or folded form is a prefix of.
+func (ix *Index) expand(w queryWord) []expansion {
+	if _, ok := ix.postings[w.term]; ok {
+		return []expansion{{w.term, 1}}
+	}
+	if len([]rune(w.raw)) < 3 {
+		return nil
+	}
+	var out []expansion
+	for term := range ix.postings {
+		if strings.HasPrefix(term, w.term) || strings.HasPrefix(term, w.raw) {
+			out = append(out, expansion{term, prefixWeight})
+		}
+	}
+	return out
+}
+
+func (ix *Index) bm25(term string, p posting) float64 {
+	n := float64(len(ix.docs))
+	df := float64(len(ix.postings[term]))
+	idf := math.Log(1 + (n-df+0.5)/(df+0.5))
+	norm := 1 - bm25B
+	if ix.avgLen > 0 {
+		norm += bm25B * ix.lens[p.doc] / ix.avgLen
+	}
+	return idf * p.tf * (bm25K1 + 1) / (p.tf + bm25K1*norm)
+}
+
+// snippet picks the field with the most weighted matches and cuts an excerpt
+// around its first match. The "name" field is never excerpted (the hit carries
+// the name already); a hit on the name alone excerpts the first other field
+// with text.
+func (ix *Index) snippet(doc Doc, words []queryWord) (field, text string) {
+	best, bestScore, bestAt := -1, 0.0, 0
+	for i, f := range doc.Fields {
+		if f.Name == "name" || f.Text == "" {
+			continue
+		}
+		boost := f.Boost
+		if boost == 0 {
+			boost = 1
+		}
+		score, at := 0.0, -1
+		for _, t := range tokenize(f.Text, ix.lang) {
+			if matchesAny(t, words) {
+				score += boost
+				if at < 0 {
+					at = t.start
+				}
+			}
+		}
+		if score > bestScore {
+			best, bestScore, bestAt = i, score, at
+		}
+	}
+	if best >= 0 {
+		return doc.Fields[best].Name, excerpt(doc.Fields[best].Text, bestAt)
+	}
+	for _, f := range doc.Fields {
+		if f.Name != "name" && f.Text != "" {
+			return f.Name, excerpt(f.Text, 0)
+		}
+	}
+	return "", ""
+}
+
+// matchesAny reports whether a document token matches a query word: by stem,
+// or by prefix for words of three letters or more.
+func matchesAny(t token, words []queryWord) bool {
+	for _, w := range words {
+		if t.term == w.term {
+			return true
+		}
+		if len([]rune(w.raw)) >= 3 && (strings.HasPrefix(t.term, w.term) || strings.HasPrefix(t.term, w.raw)) {
+			return true
+		}
+	}
+	return false
+}
+
+// Excerpt bounds, in bytes around the match.
+const (
+	excerptBefore = 60
+	excerptAfter  = 120
+)
+
+// excerpt cuts text around byte offset at on word boundaries, flattening
+// whitespace and marking cut ends with an ellipsis.
+func excerpt(text string, at int) string {
+	start, end := at-excerptBefore, at+excerptAfter
+	if start <= 0 {
+		start = 0
+	} else if i := strings.IndexAny(text[start:at], " \n\t"); i >= 0 {
+		start += i + 1
+	} else {
+		start = at
+	}
+	if end >= len(text) {
+		end = len(text)
+	} else if i := strings.LastIndexAny(text[at:end], " \n\t"); i > 0 {
+		end = at + i
+	} else {
+		end = len(text)
+	}
+	out := strings.Join(strings.Fields(text[start:end]), " ")
+	if start > 0 {
+		out = "…" + out
+	}
+	if end < len(text) {
+		out += "…"
+	}
+	return out
+}
@@ -0,0 +1,108 @@
+package search
+
+import (
+	"strings"
+	"testing"
+)
+
+func testDocs() []Doc {
+	return []Doc{
+		{Kind: "room", ID: "crypt", Name: "The Crypt", Fields: []Field{
+			{Name: "name", Text: "The Crypt", Boost: 3},
+			{Name: "read_aloud", Text: "Cold stone coffins line the walls. Something skitters in the dark."},
+		}},
+		{Kind: "npc", ID: "grik", Name: "Grik", Fields: []Field{
+			{Name: "name", Text: "Grik", Boost: 3},
+			{Name: "role", Text: "goblin chief", Boost: 2},
+			{Name: "personality", Text: "Cowardly and greedy; bargains for his life."},
+		}},
+		{Kind: "room", ID: "warren", Name: "Goblin Warren", Fields: []Field{
+			{Name: "name", Text: "Goblin Warren", Boost: 3},
+			{Name: "dm_notes", Text: "Six goblins sleep here around a smoking fire. Their chief keeps the key to the crypt."},
+		}},
+		{Kind: "item", ID: "key", Name: "Iron Key", Fields: []Field{
+			{Name: "name", Text: "Iron Key", Boost: 3},
+			{Name: "description", Text: "A heavy key, cold to the touch."},
+		}},
+	}
+}
+
+func ids(hits []Hit) []string {
+	var out []string
+	for _, h := range hits {
+		out = append(out, h.Kind+":"+h.ID)
+	}
+	return out
+}
+
+func TestSearchRanksNameMatchesFirst(t *testing.T) {
+	ix := New(testDocs(), "en")
+	hits := ix.Search("goblins", 0, nil)
+	if got := ids(hits); len(got) != 2 || got[0] != "room:warren" || got[1] != "npc:grik" {
+		t.Fatalf("hits = %v", got)
+	}
+	if hits[0].Field != "dm_notes" || !strings.Contains(hits[0].Snippet, "Six goblins sleep") {
+		t.Errorf("snippet = %q from %q", hits[0].Snippet, hits[0].Field)
+	}
+}
+
+func TestSearchPrefersDocumentsMatchingEveryWord(t *testing.T) {
+	ix := New(testDocs(), "en")
+	hits := ix.Search("crypt key", 0, nil)
+	if got := ids(hits); len(got) != 3 || got[0] != "room:warren" {
+		t.Errorf("the warren mentions both words and should lead: %v", got)
+	}
+	if hits := ix.Search("crypt key", 1, nil); len(hits) != 1 {
+		t.Errorf("limit ignored: %v", ids(hits))
+	}
+}
+
+func TestSearchMatchesPrefixesAndFilters(t *testing.T) {
+	ix := New(testDocs(), "en")
+	if got := ids(ix.Search("gob", 0, nil)); len(got) != 2 {
+		t.Errorf("a prefix should match as you type: %v", got)
+	}
+	keep := func(kind, id string) bool { return kind != "room" }
+	if got := ids(ix.Search("goblin", 0, keep)); len(got) != 1 || got[0] != "npc:grik" {
+		t.Errorf("filtered hits = %v", got)
+	}
+	if hits := ix.Search("the and", 0, nil); hits != nil {
+		t.Errorf("a query of stop words matches nothing: %v", ids(hits))
+	}
+}
+
+func TestSearchStemsSpanish(t *testing.T) {
+	ix := New([]Doc{
+		{Kind: "room", ID: "torre", Name: "La Torre", Fields: []Field{
+			{Name: "name", Text: "La Torre", Boost: 3},
+			{Name: "read_aloud", Text: "Las luces de la ciudad brillan bajo el dragón."},
+		}},
+	}, "es")
+	for _, q := range []string{"luz", "ciudades", "dragon", "DRAGONES", "torres"} {
+		if hits := ix.Search(q, 0, nil); len(hits) != 1 {
+			t.Errorf("%q found %v", q, ids(hits))
+		}
+	}
+}
+
+func TestStemEnglish(t *testing.T) {
+	for in, want := range map[string]string{
+		"stories": "story", "boxes": "box", "caves": "cave", "cave": "cave",
+		"running": "run", "cursed": "curs", "curse": "curs", "glass": "glass",
+	} {
+		if got := stem(in, "en"); got != want {
+			t.Errorf("stem(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestExcerptCutsOnWordBoundaries(t *testing.T) {
+	text := strings.Repeat("lorem ipsum ", 20) + "the hidden lever " + strings.Repeat("dolor sit ", 20)
+	got := excerpt(text, strings.Index(text, "hidden"))
+	if !strings.HasPrefix(got, "…") || !strings.HasSuffix(got, "…") || !strings.Contains(got, "hidden lever") {
+		t.Errorf("excerpt = %q", got)
+	}
+	if strings.HasPrefix(strings.TrimPrefix(got, "…"), " ") || strings.Contains(got, "  ") {
+		t.Errorf("excerpt not trimmed to words: %q", got)
+	}
+}
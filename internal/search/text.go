@@ -0,0 +1,170 @@
+package search
+
+import (
+	"strings"
+	"unicode"
+)
+
+// token is an indexed word: its stemmed term, its folded form before stemming,
+// and its byte offset in the source text.
+type token struct {
+	term, raw string
+	start     int
+}
+
+// tokenize splits text into lowercase, accent-folded, stemmed words, dropping
+// stop words and single letters.
+func tokenize(text, lang string) []token {
+	var out []token
+	var sb strings.Builder
+	start := -1
+	flush := func() {
+		if start < 0 {
+			return
+		}
+		raw := sb.String()
+		sb.Reset()
+		s := start
+		start = -1
+		if len([]rune(raw)) < 2 && !isDigits(raw) || isStopWord(raw, lang) {
+			return
+		}
+		out = append(out, token{term: stem(raw, lang), raw: raw, start: s})
+	}
+	for i, r := range text {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			if start < 0 {
+				start = i
+			}
+			sb.WriteString(fold(r))
+			continue
+		}
+		flush()
+	}
+	flush()
+	return out
+}
+
+func isDigits(s string) bool {
+	for _, r := range s {
+		if !unicode.IsDigit(r) {
+			return false
+		}
+	}
+	return s != ""
+}
+
+// fold lowercases a rune and strips the accents common in English and Spanish
+// text, so "Dragón" and "dragon" index alike.
+func fold(r rune) string {
+	r = unicode.ToLower(r)
+	switch r {
+	case 'á', 'à', 'â', 'ä', 'ã':
+		return "a"
+	case 'é', 'è', 'ê', 'ë':
+		return "e"
+	case 'í', 'ì', 'î', 'ï':
+		return "i"
+	case 'ó', 'ò', 'ô', 'ö', 'õ':
+		return "o"
+	case 'ú', 'ù', 'û', 'ü':
+		return "u"
+	case 'ñ':
+		return "n"
+	case 'ç':
+		return "c"
+	}
+	return string(r)
+}
+
+var stopWords = map[string]map[string]bool{
+	"en": set("a", "an", "and", "are", "as", "at", "be", "but", "by", "for", "from", "has", "have", "he", "her", "his",
+		"in", "into", "is", "it", "its", "of", "on", "or", "she", "that", "the", "their", "them", "there", "these",
+		"they", "this", "to", "was", "were", "will", "with"),
+	"es": set("a", "al", "como", "con", "de", "del", "el", "ella", "ellos", "en", "es", "esta", "este", "la", "las",
+		"le", "les", "lo", "los", "mas", "o", "para", "pero", "por", "que", "se", "sin", "su", "sus", "un", "una",
+		"unos", "unas", "y"),
+}
+
+func set(words ...string) map[string]bool {
+	m := make(map[string]bool, len(words))
+	for _, w := range words {
+		m[w] = true
+	}
+	return m
+}
+
+func isStopWord(w, lang string) bool {
+	if lang != "es" {
+		lang = "en"
+	}
+	return stopWords[lang][w]
+}
+
+// stem reduces a folded word to a light stem: plurals and the commonest
+// inflections only, so it over-merges little. The same stemming is applied to
+// documents and queries, so a stem need not be a real word.
+func stem(w, lang string) string {
+	if isDigits(w) {
+		return w
+	}
+	if lang == "es" {
+		return stemES(w)
+	}
+	return stemEN(w)
+}
+
+func stemEN(w string) string {
+	n := len(w)
+	switch {
+	case n > 4 && strings.HasSuffix(w, "ies"):
+		w = w[:n-3] + "y"
+	case n > 4 && strings.HasSuffix(w, "sses"):
+		w = w[:n-2]
+	case n > 4 && (strings.HasSuffix(w, "ches") || strings.HasSuffix(w, "shes") || strings.HasSuffix(w, "xes") || strings.HasSuffix(w, "zes")):
+		w = w[:n-2]
+	case n > 3 && strings.HasSuffix(w, "s") && !strings.HasSuffix(w, "ss") && !strings.HasSuffix(w, "us") && !strings.HasSuffix(w, "is"):
+		w = w[:n-1]
+	}
+	n = len(w)
+	switch {
+	case n > 5 && strings.HasSuffix(w, "ing"):
+		w = undouble(w[:n-3])
+	case n > 4 && strings.HasSuffix(w, "ed"):
+		w = undouble(w[:n-2])
+	case n > 4 && strings.HasSuffix(w, "ly"):
+		w = w[:n-2]
+	}
+	if n = len(w); n > 4 && strings.HasSuffix(w, "e") {
+		w = w[:n-1]
+	}
+	return w
+}
+
+// undouble drops a doubled final consonant left by a stripped suffix
+// ("running" → "runn" → "run").
+func undouble(w string) string {
+	n := len(w)
+	if n > 2 && w[n-1] == w[n-2] && !strings.ContainsRune("aeiouls", rune(w[n-1])) {
+		return w[:n-1]
+	}
+	return w
+}
+
+func stemES(w string) string {
+	n := len(w)
+	switch {
+	case n > 4 && strings.HasSuffix(w, "ces"):
+		w = w[:n-3] + "z"
+	case n > 4 && strings.HasSuffix(w, "es") && !isVowel(w[n-3]):
+		w = w[:n-2]
+	case n > 3 && strings.HasSuffix(w, "s"):
+		w = w[:n-1]
+	}
+	if n = len(w); n > 3 && strings.ContainsRune("aoe", rune(w[n-1])) {
+		w = w[:n-1]
+	}
+	return w
+}
+
+func isVowel(b byte) bool { return strings.IndexByte("aeiou", b) >= 0 }
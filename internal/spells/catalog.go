@@ -0,0 +1,551 @@
+package spells
+
+// Class lists, shared by the entries below.
+var (
+	arcane     = []string{"Sorcerer", "Wizard"}
+	arcaneAll  = []string{"Bard", "Sorcerer", "Warlock", "Wizard"}
+	healers    = []string{"Bard", "Cleric", "Druid", "Paladin", "Ranger"}
+	clericOnly = []string{"Cleric"}
+	druidOnly  = []string{"Druid"}
+)
+
+// catalog holds the embedded spells, grouped by level. Within a level the
+// spells a starting character most often picks come first; chargen takes its
+// starting spell list from the front of each class's list.
+var catalog = []Spell{
+	// ── Cantrips ──────────────────────────────────────────────────────────────
+	{
+		Name: "Fire Bolt", Level: 0, School: "evocation", CastingTime: "1 action", Range: "120 feet",
+		Components: "V, S", Duration: "Instantaneous", Classes: arcane,
+		Description:  "Hurl a mote of fire at a creature or object in range. On a hit with a ranged spell attack the target takes 1d10 fire damage; an unattended flammable object ignites.",
+		HigherLevels: "The damage increases by 1d10 at 5th level (2d10), 11th level (3d10) and 17th level (4d10).",
+		Attack:       true, Dice: "1d10 fire",
+	},
+	{
+		Name: "Sacred Flame", Level: 0, School: "evocation", CastingTime: "1 action", Range: "60 feet",
+		Components: "V, S", Duration: "Instantaneous", Classes: clericOnly,
+		Description:  "Flame-like radiance descends on a creature you can see. It must succeed on a Dexterity saving throw or take 1d8 radiant damage; cover gives no benefit against this save.",
+		HigherLevels: "The damage increases by 1d8 at 5th level (2d8), 11th level (3d8) and 17th level (4d8).",
+		Save:         "DEX", Dice: "1d8 radiant",
+	},
+	{
+		Name: "Eldritch Blast", Level: 0, School: "evocation", CastingTime: "1 action", Range: "120 feet",
+		Components: "V, S", Duration: "Instantaneous", Classes: []string{"Warlock"},
+		Description:  "A beam of crackling energy streaks toward a creature in range. Make a ranged spell attack; on a hit the target takes 1d10 force damage.",
+		HigherLevels: "The spell creates more beams: two at 5th level, three at 11th and four at 17th. Each beam is a separate attack, at the same or different targets.",
+		Attack:       true, Dice: "1d10 force",
+	},
+	{
+		Name: "Vicious Mockery", Level: 0, School: "enchantment", CastingTime: "1 action", Range: "60 feet",
+		Components: "V", Duration: "Instantaneous", Classes: []string{"Bard"},
+		Description:  "Unleash a string of insults laced with subtle enchantment at a creature that can hear you. On a failed Wisdom saving throw it takes 1d4 psychic damage and has disadvantage on its next attack roll before the end of its next turn.",
+		HigherLevels: "The damage increases by 1d4 at 5th level (2d4), 11th level (3d4) and 17th level (4d4).",
+		Save:         "WIS", Dice: "1d4 psychic",
+	},
+	{
+		Name: "Produce Flame", Level: 0, School: "conjuration", CastingTime: "1 action", Range: "Self",
+		Components: "V, S", Duration: "10 minutes", Classes: druidOnly,
+		Description:  "A flickering flame appears in your hand, shedding bright light for 10 feet. You can hurl it at a creature within 30 feet as a ranged spell attack, dealing 1d8 fire damage on a hit; this ends the spell.",
+		HigherLevels: "The damage increases by 1d8 at 5th level (2d8), 11th level (3d8) and 17th level (4d8).",
+		Attack:       true, Dice: "1d8 fire",
+	},
+	{
+		Name: "Guidance", Level: 0, School: "divination", CastingTime: "1 action", Range: "Touch",
+		Components: "V, S", Duration: "Up to 1 minute", Concentration: true, Classes: []string{"Cleric", "Druid"},
+		Description: "Touch a willing creature. Once before the spell ends it can roll a d4 and add it to one ability check of its choice.",
+	},
+	{
+		Name: "Ray of Frost", Level: 0, School: "evocation", CastingTime: "1 action", Range: "60 feet",
+		Components: "V, S", Duration: "Instantaneous", Classes: arcane,
+		Description:  "A frigid beam of blue-white light streaks toward a creature. On a hit with a ranged spell attack it takes 1d8 cold damage and its speed drops by 10 feet until the start of your next turn.",
+		HigherLevels: "The damage increases by 1d8 at 5th level (2d8), 11th level (3d8) and 17th level (4d8).",
+		Attack:       true, Dice: "1d8 cold",
+	},
+	{
+		Name: "Mage Hand", Level: 0, School: "conjuration", CastingTime: "1 action", Range: "30 feet",
+		Components: "V, S", Duration: "1 minute", Classes: arcaneAll,
+		Description: "A spectral, floating hand appears at a point in range. It can manipulate an object, open an unlocked door or container, or carry up to 10 pounds, but cannot attack or activate magic items.",
+	},
+	{
+		Name: "Light", Level: 0, School: "evocation", CastingTime: "1 action", Range: "Touch",
+		Components: "V, M (a firefly or phosphorescent moss)", Duration: "1 hour", Classes: []string{"Bard", "Cleric", "Sorcerer", "Wizard"},
+		Description: "An object no larger than 10 feet in any dimension sheds bright light in a 20-foot radius and dim light for a further 20 feet. A hostile creature holding the object can avoid it with a Dexterity saving throw.",
+	},
+	{
+		Name: "Shocking Grasp", Level: 0, School: "evocation", CastingTime: "1 action", Range: "Touch",
+		Components: "V, S", Duration: "Instantaneous", Classes: arcane,
+		Description:  "Lightning springs from your hand. Make a melee spell attack, with advantage if the target wears metal armor; on a hit it takes 1d8 lightning damage and can't take reactions until the start of its next turn.",
+		HigherLevels: "The damage increases by 1d8 at 5th level (2d8), 11th level (3d8) and 17th level (4d8).",
+		Attack:       true, Dice: "1d8 lightning",
+	},
+	{
+		Name: "Minor Illusion", Level: 0, School: "illusion", CastingTime: "1 action", Range: "30 feet",
+		Components: "S, M (a bit of fleece)", Duration: "1 minute", Classes: arcaneAll,
+		Description: "Create a sound or an image of an object no larger than a 5-foot cube. A creature that uses its action to examine it can see through it with an Intelligence (Investigation) check against your spell save DC.",
+	},
+	{
+		Name: "Prestidigitation", Level: 0, School: "transmutation", CastingTime: "1 action", Range: "10 feet",
+		Components: "V, S", Duration: "Up to 1 hour", Classes: arcaneAll,
+		Description: "A minor magical trick: a harmless sensory effect, lighting or snuffing a small flame, cleaning or soiling an object, chilling or warming food, or a small mark or trinket that lasts an hour.",
+	},
+	{
+		Name: "Thaumaturgy", Level: 0, School: "transmutation", CastingTime: "1 action", Range: "30 feet",
+		Components: "V", Duration: "Up to 1 minute", Classes: clericOnly,
+		Description: "Manifest a minor wonder: your voice booms, flames flicker, tremors shake the ground, a door flies open, or your eyes change appearance.",
+	},
+	{
+		Name: "Resistance", Level: 0, School: "abjuration", CastingTime: "1 action", Range: "Touch",
+		Components: "V, S, M (a miniature cloak)", Duration: "Up to 1 minute", Concentration: true, Classes: []string{"Cleric", "Druid"},
+		Description: "Touch a willing creature. Once before the spell ends it can roll a d4 and add it to one saving throw of its choice.",
+	},
+	{
+		Name: "Druidcraft", Level: 0, School: "transmutation", CastingTime: "1 action", Range: "30 feet",
+		Components: "V, S", Duration: "Instantaneous", Classes: druidOnly,
+		Description: "Whisper to the spirits of nature: predict the weather, make a flower bloom, create a harmless sensory effect, or light or snuff a small flame.",
+	},
+	{
+		Name: "Poison Spray", Level: 0, School: "conjuration", CastingTime: "1 action", Range: "10 feet",
+		Components: "V, S", Duration: "Instantaneous", Classes: []string{"Druid", "Sorcerer", "Warlock", "Wizard"},
+		Description:  "Project a puff of noxious gas at a creature. It must succeed on a Constitution saving throw or take 1d12 poison damage.",
+		HigherLevels: "The damage increases by 1d12 at 5th level (2d12), 11th level (3d12) and 17th level (4d12).",
+		Save:         "CON", Dice: "1d12 poison",
+	},
+	{
+		Name: "Chill Touch", Level: 0, School: "necromancy", CastingTime: "1 action", Range: "120 feet",
+		Components: "V, S", Duration: "1 round", Classes: []string{"Sorcerer", "Warlock", "Wizard"},
+		Description:  "A ghostly, skeletal hand clings to a creature. On a hit with a ranged spell attack it takes 1d8 necrotic damage and can't regain hit points until the start of your next turn; an undead target also has disadvantage on attacks against you.",
+		HigherLevels: "The damage increases by 1d8 at 5th level (2d8), 11th level (3d8) and 17th level (4d8).",
+		Attack:       true, Dice: "1d8 necrotic",
+	},
+	{
+		Name: "Acid Splash", Level: 0, School: "conjuration", CastingTime: "1 action", Range: "60 feet",
+		Components: "V, S", Duration: "Instantaneous", Classes: arcane,
+		Description:  "Hurl a bubble of acid at one creature, or two creatures within 5 feet of each other. Each must succeed on a Dexterity saving throw or take 1d6 acid damage.",
+		HigherLevels: "The damage increases by 1d6 at 5th level (2d6), 11th level (3d6) and 17th level (4d6).",
+		Save:         "DEX", Dice: "1d6 acid",
+	},
+	{
+		Name: "Spare the Dying", Level: 0, School: "necromancy", CastingTime: "1 action", Range: "Touch",
+		Components: "V, S", Duration: "Instantaneous", Classes: clericOnly,
+		Description: "Touch a living creature that has 0 hit points. It becomes stable. The spell has no effect on undead or constructs.",
+	},
+
+	// ── 1st level ─────────────────────────────────────────────────────────────
+	{
+		Name: "Magic Missile", Level: 1, School: "evocation", CastingTime: "1 action", Range: "120 feet",
+		Components: "V, S", Duration: "Instantaneous", Classes: arcane,
+		Description:  "Three glowing darts of magical force each hit a creature of your choice that you can see, dealing 1d4+1 force damage. The darts strike simultaneously and can target one creature or several.",
+		HigherLevels: "One more dart for each slot level above 1st.",
+		Dice:         "3d4+3 force", Upcast: "1d4+1",
+	},
+	{
+		Name: "Cure Wounds", Level: 1, School: "evocation", CastingTime: "1 action", Range: "Touch",
+		Components: "V, S", Duration: "Instantaneous", Classes: healers,
+		Description:  "A creature you touch regains hit points equal to 1d8 + your spellcasting ability modifier. No effect on undead or constructs.",
+		HigherLevels: "The healing increases by 1d8 for each slot level above 1st.",
+		Dice:         "1d8 healing", Upcast: "1d8", AddModifier: true,
+	},
+	{
+		Name: "Healing Word", Level: 1, School: "evocation", CastingTime: "1 bonus action", Range: "60 feet",
+		Components: "V", Duration: "Instantaneous", Classes: []string{"Bard", "Cleric", "Druid"},
+		Description:  "A creature of your choice that you can see regains hit points equal to 1d4 + your spellcasting ability modifier. No effect on undead or constructs.",
+		HigherLevels: "The healing increases by 1d4 for each slot level above 1st.",
+		Dice:         "1d4 healing", Upcast: "1d4", AddModifier: true,
+	},
+	{
+		Name: "Shield", Level: 1, School: "abjuration", CastingTime: "1 reaction, when you are hit by an attack or targeted by magic missile", Range: "Self",
+		Components: "V, S", Duration: "1 round", Classes: arcane,
+		Description: "An invisible barrier of magical force protects you. Until the start of your next turn you have a +5 bonus to AC, including against the triggering attack, and take no damage from magic missile.",
+	},
+	{
+		Name: "Bless", Level: 1, School: "enchantment", CastingTime: "1 action", Range: "30 feet",
+		Components: "V, S, M (a sprinkling of holy water)", Duration: "Up to 1 minute", Concentration: true, Classes: []string{"Cleric", "Paladin"},
+		Description:  "Bless up to three creatures in range. Whenever a target makes an attack roll or a saving throw before the spell ends, it adds a d4 to the roll.",
+		HigherLevels: "One more creature for each slot level above 1st.",
+	},
+	{
+		Name: "Guiding Bolt", Level: 1, School: "evocation", CastingTime: "1 action", Range: "120 feet",
+		Components: "V, S", Duration: "1 round", Classes: clericOnly,
+		Description:  "A flash of light streaks toward a creature. On a hit with a ranged spell attack it takes 4d6 radiant damage, and the next attack roll against it before the end of your next turn has advantage.",
+		HigherLevels: "The damage increases by 1d6 for each slot level above 1st.",
+		Attack:       true, Dice: "4d6 radiant", Upcast: "1d6",
+	},
+	{
+		Name: "Sleep", Level: 1, School: "enchantment", CastingTime: "1 action", Range: "90 feet",
+		Components: "V, S, M (a pinch of fine sand, rose petals, or a cricket)", Duration: "1 minute", Classes: []string{"Bard", "Sorcerer", "Wizard"},
+		Description:  "Roll 5d8: that many hit points of creatures within 20 feet of a point in range fall unconscious, starting with the creature with the fewest current hit points. Undead and creatures immune to being charmed aren't affected.",
+		HigherLevels: "Roll an additional 2d8 for each slot level above 1st.",
+		Dice:         "5d8 hit points of creatures", Upcast: "2d8",
+	},
+	{
+		Name: "Burning Hands", Level: 1, School: "evocation", CastingTime: "1 action", Range: "Self (15-foot cone)",
+		Components: "V, S", Duration: "Instantaneous", Classes: arcane,
+		Description:  "A thin sheet of flames shoots from your outstretched fingertips. Each creature in the cone makes a Dexterity saving throw, taking 3d6 fire damage on a failure or half as much on a success.",
+		HigherLevels: "The damage increases by 1d6 for each slot level above 1st.",
+		Save:         "DEX", Dice: "3d6 fire", Upcast: "1d6",
+	},
+	{
+		Name: "Hunter's Mark", Level: 1, School: "divination", CastingTime: "1 bonus action", Range: "90 feet",
+		Components: "V", Duration: "Up to 1 hour", Concentration: true, Classes: []string{"Ranger"},
+		Description:  "Mark a creature you can see as your quarry. You deal an extra 1d6 damage to it whenever you hit it with a weapon attack, and have advantage on Wisdom (Perception) and (Survival) checks to find it. If it drops to 0 hit points you can move the mark with a bonus action.",
+		HigherLevels: "With a 3rd- or 4th-level slot the spell lasts up to 8 hours; with a 5th-level or higher slot, up to 24 hours.",
+	},
+	{
+		Name: "Thunderwave", Level: 1, School: "evocation", CastingTime: "1 action", Range: "Self (15-foot cube)",
+		Components: "V, S", Duration: "Instantaneous", Classes: []string{"Bard", "Druid", "Sorcerer", "Wizard"},
+		Description:  "A wave of thunderous force sweeps out from you. Each creature in the cube makes a Constitution saving throw; on a failure it takes 2d8 thunder damage and is pushed 10 feet away, on a success half as much and no push.",
+		HigherLevels: "The damage increases by 1d8 for each slot level above 1st.",
+		Save:         "CON", Dice: "2d8 thunder", Upcast: "1d8",
+	},
+	{
+		Name: "Charm Person", Level: 1, School: "enchantment", CastingTime: "1 action", Range: "30 feet",
+		Components: "V, S", Duration: "1 hour", Classes: []string{"Bard", "Druid", "Sorcerer", "Warlock", "Wizard"},
+		Description:  "A humanoid you can see makes a Wisdom saving throw, with advantage if you or your companions are fighting it. On a failure it is charmed by you until the spell ends or you or your companions harm it, and it knows it was charmed afterwards.",
+		HigherLevels: "One more creature for each slot level above 1st.",
+		Save:         "WIS",
+	},
+	{
+		Name: "Hellish Rebuke", Level: 1, School: "evocation", CastingTime: "1 reaction, when a creature you can see damages you", Range: "60 feet",
+		Components: "V, S", Duration: "Instantaneous", Classes: []string{"Warlock"},
+		Description:  "The creature that damaged you is wreathed in hellish flames and makes a Dexterity saving throw, taking 2d10 fire damage on a failure or half as much on a success.",
+		HigherLevels: "The damage increases by 1d10 for each slot level above 1st.",
+		Save:         "DEX", Dice: "2d10 fire", Upcast: "1d10",
+	},
+	{
+		Name: "Faerie Fire", Level: 1, School: "evocation", CastingTime: "1 action", Range: "60 feet",
+		Components: "V", Duration: "Up to 1 minute", Concentration: true, Classes: []string{"Bard", "Druid"},
+		Description: "Each object in a 20-foot cube is outlined in light, as is each creature that fails a Dexterity saving throw. Attack rolls against an affected creature or object have advantage, and it can't benefit from being invisible.",
+		Save:        "DEX",
+	},
+	{
+		Name: "Entangle", Level: 1, School: "conjuration", CastingTime: "1 action", Range: "90 feet",
+		Components: "V, S", Duration: "Up to 1 minute", Concentration: true, Classes: druidOnly,
+		Description: "Grasping weeds and vines sprout in a 20-foot square, making it difficult terrain. A creature in the area when you cast the spell must succeed on a Strength saving throw or be restrained; it can use its action to repeat the check.",
+		Save:        "STR",
+	},
+	{
+		Name: "Shield of Faith", Level: 1, School: "abjuration", CastingTime: "1 bonus action", Range: "60 feet",
+		Components: "V, S, M (a small parchment with a bit of holy text)", Duration: "Up to 10 minutes", Concentration: true, Classes: []string{"Cleric", "Paladin"},
+		Description: "A shimmering field surrounds a creature of your choice, granting it a +2 bonus to AC for the duration.",
+	},
+	{
+		Name: "Divine Favor", Level: 1, School: "evocation", CastingTime: "1 bonus action", Range: "Self",
+		Components: "V, S", Duration: "Up to 1 minute", Concentration: true, Classes: []string{"Paladin"},
+		Description: "Your prayer empowers you with divine radiance. Your weapon attacks deal an extra 1d4 radiant damage on a hit.",
+	},
+	{
+		Name: "Command", Level: 1, School: "enchantment", CastingTime: "1 action", Range: "60 feet",
+		Components: "V", Duration: "1 round", Classes: []string{"Cleric", "Paladin"},
+		Description:  "Speak a one-word command (approach, drop, flee, grovel, halt…) to a creature you can see. On a failed Wisdom saving throw it follows the command on its next turn.",
+		HigherLevels: "One more creature for each slot level above 1st.",
+		Save:         "WIS",
+	},
+	{
+		Name: "Inflict Wounds", Level: 1, School: "necromancy", CastingTime: "1 action", Range: "Touch",
+		Components: "V, S", Duration: "Instantaneous", Classes: clericOnly,
+		Description:  "Make a melee spell attack against a creature you can reach. On a hit it takes 3d10 necrotic damage.",
+		HigherLevels: "The damage increases by 1d10 for each slot level above 1st.",
+		Attack:       true, Dice: "3d10 necrotic", Upcast: "1d10",
+	},
+	{
+		Name: "Mage Armor", Level: 1, School: "abjuration", CastingTime: "1 action", Range: "Touch",
+		Components: "V, S, M (a piece of cured leather)", Duration: "8 hours", Classes: arcane,
+		Description: "A willing creature not wearing armor gains a protective magical force: its base AC becomes 13 + its Dexterity modifier. The spell ends if it dons armor.",
+	},
+	{
+		Name: "Goodberry", Level: 1, School: "transmutation", CastingTime: "1 action", Range: "Touch",
+		Components: "V, S, M (a sprig of mistletoe)", Duration: "Instantaneous", Classes: []string{"Druid", "Ranger"},
+		Description: "Up to ten berries appear in your hand, infused with magic for 24 hours. Eating a berry restores 1 hit point and provides enough nourishment for a day.",
+	},
+	{
+		Name: "Detect Magic", Level: 1, School: "divination", CastingTime: "1 action", Range: "Self",
+		Components: "V, S", Duration: "Up to 10 minutes", Concentration: true, Ritual: true, Classes: []string{"Bard", "Cleric", "Druid", "Paladin", "Ranger", "Sorcerer", "Wizard"},
+		Description: "You sense the presence of magic within 30 feet. With an action you can see a faint aura around a visible magical creature or object and learn its school of magic, if any.",
+	},
+	{
+		Name: "Identify", Level: 1, School: "divination", CastingTime: "1 minute", Range: "Touch",
+		Components: "V, S, M (a pearl worth at least 100 gp and an owl feather)", Duration: "Instantaneous", Ritual: true, Classes: []string{"Bard", "Wizard"},
+		Description: "Learn the properties of a magic item you touch — how to use it, whether it needs attunement and how many charges it has — or the spells affecting a creature or object.",
+	},
+	{
+		Name: "Feather Fall", Level: 1, School: "transmutation", CastingTime: "1 reaction, when you or a creature within 60 feet falls", Range: "60 feet",
+		Components: "V, M (a small feather or piece of down)", Duration: "1 minute", Classes: []string{"Bard", "Sorcerer", "Wizard"},
+		Description: "Up to five falling creatures in range descend at 60 feet per round and take no falling damage if they land before the spell ends.",
+	},
+	{
+		Name: "Sanctuary", Level: 1, School: "abjuration", CastingTime: "1 bonus action", Range: "30 feet",
+		Components: "V, S, M (a small silver mirror)", Duration: "1 minute", Classes: clericOnly,
+		Description: "Ward a creature against attack. Anyone targeting it with an attack or harmful spell must first succeed on a Wisdom saving throw or choose a new target. The spell ends if the warded creature attacks or casts a spell that affects an enemy.",
+		Save:        "WIS",
+	},
+	{
+		Name: "Protection from Evil and Good", Level: 1, School: "abjuration", CastingTime: "1 action", Range: "Touch",
+		Components: "V, S, M (holy water or powdered silver and iron, consumed)", Duration: "Up to 10 minutes", Concentration: true, Classes: []string{"Cleric", "Paladin", "Warlock", "Wizard"},
+		Description: "A willing creature is protected against aberrations, celestials, elementals, fey, fiends and undead: they have disadvantage on attacks against it, and it can't be charmed, frightened or possessed by them.",
+	},
+
+	// ── 2nd level ─────────────────────────────────────────────────────────────
+	{
+		Name: "Misty Step", Level: 2, School: "conjuration", CastingTime: "1 bonus action", Range: "Self",
+		Components: "V", Duration: "Instantaneous", Classes: []string{"Sorcerer", "Warlock", "Wizard"},
+		Description: "Briefly surrounded by silvery mist, you teleport up to 30 feet to an unoccupied space that you can see.",
+	},
+	{
+		Name: "Hold Person", Level: 2, School: "enchantment", CastingTime: "1 action", Range: "60 feet",
+		Components: "V, S, M (a small, straight piece of iron)", Duration: "Up to 1 minute", Concentration: true, Classes: []string{"Bard", "Cleric", "Druid", "Sorcerer", "Warlock", "Wizard"},
+		Description:  "A humanoid you can see must succeed on a Wisdom saving throw or be paralyzed for the duration. It repeats the save at the end of each of its turns, ending the spell on a success.",
+		HigherLevels: "One more humanoid for each slot level above 2nd; the targets must be within 30 feet of each other.",
+		Save:         "WIS",
+	},
+	{
+		Name: "Spiritual Weapon", Level: 2, School: "evocation", CastingTime: "1 bonus action", Range: "60 feet",
+		Components: "V, S", Duration: "1 minute", Classes: clericOnly,
+		Description:  "A floating spectral weapon appears and attacks: make a melee spell attack against a creature within 5 feet of it, dealing 1d8 + your spellcasting ability modifier force damage. On later turns a bonus action moves it 20 feet and repeats the attack.",
+		HigherLevels: "The damage increases by 1d8 for every two slot levels above 2nd.",
+		Attack:       true, Dice: "1d8 force", AddModifier: true,
+	},
+	{
+		Name: "Scorching Ray", Level: 2, School: "evocation", CastingTime: "1 action", Range: "120 feet",
+		Components: "V, S", Duration: "Instantaneous", Classes: arcane,
+		Description:  "Create three rays of fire and hurl them at targets in range. Make a ranged spell attack for each ray; on a hit the target takes 2d6 fire damage.",
+		HigherLevels: "One more ray for each slot level above 2nd.",
+		Attack:       true, Dice: "2d6 fire",
+	},
+	{
+		Name: "Shatter", Level: 2, School: "evocation", CastingTime: "1 action", Range: "60 feet",
+		Components: "V, S, M (a chip of mica)", Duration: "Instantaneous", Classes: arcaneAll,
+		Description:  "A painfully loud ringing erupts in a 10-foot-radius sphere. Each creature in it makes a Constitution saving throw, taking 3d8 thunder damage on a failure or half on a success; creatures of inorganic material have disadvantage.",
+		HigherLevels: "The damage increases by 1d8 for each slot level above 2nd.",
+		Save:         "CON", Dice: "3d8 thunder", Upcast: "1d8",
+	},
+	{
+		Name: "Lesser Restoration", Level: 2, School: "abjuration", CastingTime: "1 action", Range: "Touch",
+		Components: "V, S", Duration: "Instantaneous", Classes: healers,
+		Description: "Touch a creature to end either one disease or one condition afflicting it: blinded, deafened, paralyzed or poisoned.",
+	},
+	{
+		Name: "Moonbeam", Level: 2, School: "evocation", CastingTime: "1 action", Range: "120 feet",
+		Components: "V, S, M (several seeds of a moonseed plant and a piece of opalescent feldspar)", Duration: "Up to 1 minute", Concentration: true, Classes: druidOnly,
+		Description:  "A silvery 5-foot-radius beam of pale light shines down. A creature entering it for the first time on a turn or starting its turn there makes a Constitution saving throw, taking 2d10 radiant damage on a failure or half on a success. You can move the beam 60 feet as an action.",
+		HigherLevels: "The damage increases by 1d10 for each slot level above 2nd.",
+		Save:         "CON", Dice: "2d10 radiant", Upcast: "1d10",
+	},
+	{
+		Name: "Invisibility", Level: 2, School: "illusion", CastingTime: "1 action", Range: "Touch",
+		Components: "V, S, M (an eyelash encased in gum arabic)", Duration: "Up to 1 hour", Concentration: true, Classes: arcaneAll,
+		Description:  "A creature you touch becomes invisible, along with what it wears and carries, until the spell ends. The spell ends for a target that attacks or casts a spell.",
+		HigherLevels: "One more creature for each slot level above 2nd.",
+	},
+	{
+		Name: "Aid", Level: 2, School: "abjuration", CastingTime: "1 action", Range: "30 feet",
+		Components: "V, S, M (a tiny strip of white cloth)", Duration: "8 hours", Classes: []string{"Cleric", "Paladin"},
+		Description:  "Up to three creatures in range each have their hit point maximum and current hit points increased by 5 for the duration.",
+		HigherLevels: "The increase is 5 more for each slot level above 2nd.",
+	},
+	{
+		Name: "Flaming Sphere", Level: 2, School: "conjuration", CastingTime: "1 action", Range: "60 feet",
+		Components: "V, S, M (a bit of tallow, a pinch of brimstone and a dusting of powdered iron)", Duration: "Up to 1 minute", Concentration: true, Classes: []string{"Druid", "Wizard"},
+		Description:  "A 5-foot sphere of fire appears. A creature ending its turn within 5 feet of it makes a Dexterity saving throw, taking 2d6 fire damage on a failure or half on a success. A bonus action rolls it up to 30 feet, ramming creatures in its path.",
+		HigherLevels: "The damage increases by 1d6 for each slot level above 2nd.",
+		Save:         "DEX", Dice: "2d6 fire", Upcast: "1d6",
+	},
+	{
+		Name: "Prayer of Healing", Level: 2, School: "evocation", CastingTime: "10 minutes", Range: "30 feet",
+		Components: "V", Duration: "Instantaneous", Classes: clericOnly,
+		Description:  "Up to six creatures of your choice that you can see each regain hit points equal to 2d8 + your spellcasting ability modifier.",
+		HigherLevels: "The healing increases by 1d8 for each slot level above 2nd.",
+		Dice:         "2d8 healing", Upcast: "1d8", AddModifier: true,
+	},
+	{
+		Name: "Web", Level: 2, School: "conjuration", CastingTime: "1 action", Range: "60 feet",
+		Components: "V, S, M (a bit of spiderweb)", Duration: "Up to 1 hour", Concentration: true, Classes: arcane,
+		Description: "Thick, sticky webbing fills a 20-foot cube, making it difficult terrain and lightly obscured. A creature starting its turn in or entering the webs must succeed on a Dexterity saving throw or be restrained; the webs are flammable.",
+		Save:        "DEX",
+	},
+	{
+		Name: "Darkness", Level: 2, School: "evocation", CastingTime: "1 action", Range: "60 feet",
+		Components: "V, M (bat fur and a drop of pitch or piece of coal)", Duration: "Up to 10 minutes", Concentration: true, Classes: []string{"Sorcerer", "Warlock", "Wizard"},
+		Description: "Magical darkness spreads from a point in a 15-foot radius. Darkvision can't see through it and nonmagical light can't illuminate it.",
+	},
+	{
+		Name: "Suggestion", Level: 2, School: "enchantment", CastingTime: "1 action", Range: "30 feet",
+		Components: "V, M (a snake's tongue and a bit of honeycomb or sweet oil)", Duration: "Up to 8 hours", Concentration: true, Classes: arcaneAll,
+		Description: "Suggest a reasonable-sounding course of activity to a creature that can hear and understand you. On a failed Wisdom saving throw it pursues the activity as best it can.",
+		Save:        "WIS",
+	},
+
+	// ── 3rd level ─────────────────────────────────────────────────────────────
+	{
+		Name: "Fireball", Level: 3, School: "evocation", CastingTime: "1 action", Range: "150 feet",
+		Components: "V, S, M (a tiny ball of bat guano and sulfur)", Duration: "Instantaneous", Classes: arcane,
+		Description:  "A bright streak blossoms into an explosion of flame. Each creature in a 20-foot-radius sphere makes a Dexterity saving throw, taking 8d6 fire damage on a failure or half on a success. The fire spreads around corners and ignites unattended flammable objects.",
+		HigherLevels: "The damage increases by 1d6 for each slot level above 3rd.",
+		Save:         "DEX", Dice: "8d6 fire", Upcast: "1d6",
+	},
+	{
+		Name: "Spirit Guardians", Level: 3, School: "conjuration", CastingTime: "1 action", Range: "Self (15-foot radius)",
+		Components: "V, S, M (a holy symbol)", Duration: "Up to 10 minutes", Concentration: true, Classes: clericOnly,
+		Description:  "Spirits flit around you to a distance of 15 feet. An enemy's speed is halved in the area, and when it enters the area for the first time on a turn or starts its turn there it makes a Wisdom saving throw, taking 3d8 radiant (or necrotic) damage on a failure or half on a success.",
+		HigherLevels: "The damage increases by 1d8 for each slot level above 3rd.",
+		Save:         "WIS", Dice: "3d8 radiant", Upcast: "1d8",
+	},
+	{
+		Name: "Counterspell", Level: 3, School: "abjuration", CastingTime: "1 reaction, when you see a creature within 60 feet casting a spell", Range: "60 feet",
+		Components: "S", Duration: "Instantaneous", Classes: []string{"Sorcerer", "Warlock", "Wizard"},
+		Description:  "Interrupt a creature casting a spell. A spell of 3rd level or lower fails; for a higher one, make an ability check with your spellcasting ability against DC 10 + the spell's level.",
+		HigherLevels: "The spell automatically fails if its level is no higher than the slot you used.",
+	},
+	{
+		Name: "Lightning Bolt", Level: 3, School: "evocation", CastingTime: "1 action", Range: "Self (100-foot line)",
+		Components: "V, S, M (a bit of fur and a rod of amber, crystal or glass)", Duration: "Instantaneous", Classes: arcane,
+		Description:  "A stroke of lightning forms a line 100 feet long and 5 feet wide. Each creature in the line makes a Dexterity saving throw, taking 8d6 lightning damage on a failure or half on a success.",
+		HigherLevels: "The damage increases by 1d6 for each slot level above 3rd.",
+		Save:         "DEX", Dice: "8d6 lightning", Upcast: "1d6",
+	},
+	{
+		Name: "Mass Healing Word", Level: 3, School: "evocation", CastingTime: "1 bonus action", Range: "60 feet",
+		Components: "V", Duration: "Instantaneous", Classes: clericOnly,
+		Description:  "Up to six creatures of your choice that you can see each regain hit points equal to 1d4 + your spellcasting ability modifier.",
+		HigherLevels: "The healing increases by 1d4 for each slot level above 3rd.",
+		Dice:         "1d4 healing", Upcast: "1d4", AddModifier: true,
+	},
+	{
+		Name: "Revivify", Level: 3, School: "necromancy", CastingTime: "1 action", Range: "Touch",
+		Components: "V, S, M (diamonds worth 300 gp, consumed)", Duration: "Instantaneous", Classes: []string{"Cleric", "Paladin"},
+		Description: "Touch a creature that has died within the last minute. It returns to life with 1 hit point, unless it died of old age; the spell can't restore missing body parts.",
+	},
+	{
+		Name: "Dispel Magic", Level: 3, School: "abjuration", CastingTime: "1 action", Range: "120 feet",
+		Components: "V, S", Duration: "Instantaneous", Classes: []string{"Bard", "Cleric", "Druid", "Paladin", "Sorcerer", "Warlock", "Wizard"},
+		Description:  "End each spell of 3rd level or lower on a creature, object or magical effect in range. For a higher-level spell, make an ability check with your spellcasting ability against DC 10 + the spell's level.",
+		HigherLevels: "Spells of a level no higher than the slot you used end automatically.",
+	},
+	{
+		Name: "Call Lightning", Level: 3, School: "conjuration", CastingTime: "1 action", Range: "120 feet",
+		Components: "V, S", Duration: "Up to 10 minutes", Concentration: true, Classes: druidOnly,
+		Description:  "A storm cloud appears overhead. As an action on this and later turns you call a bolt down on a point below it; each creature within 5 feet makes a Dexterity saving throw, taking 3d10 lightning damage on a failure or half on a success.",
+		HigherLevels: "The damage increases by 1d10 for each slot level above 3rd.",
+		Save:         "DEX", Dice: "3d10 lightning", Upcast: "1d10",
+	},
+	{
+		Name: "Fly", Level: 3, School: "transmutation", CastingTime: "1 action", Range: "Touch",
+		Components: "V, S, M (a wing feather from any bird)", Duration: "Up to 10 minutes", Concentration: true, Classes: []string{"Sorcerer", "Warlock", "Wizard"},
+		Description:  "A willing creature gains a flying speed of 60 feet. When the spell ends it falls if it is still aloft.",
+		HigherLevels: "One more creature for each slot level above 3rd.",
+	},
+	{
+		Name: "Haste", Level: 3, School: "transmutation", CastingTime: "1 action", Range: "30 feet",
+		Components: "V, S, M (a shaving of licorice root)", Duration: "Up to 1 minute", Concentration: true, Classes: arcane,
+		Description: "A willing creature's speed doubles, it gains +2 AC and advantage on Dexterity saves, and it gets an additional action each turn (one attack, Dash, Disengage, Hide or Use an Object). When the spell ends it can't move or act until after its next turn.",
+	},
+	{
+		Name: "Hypnotic Pattern", Level: 3, School: "illusion", CastingTime: "1 action", Range: "120 feet",
+		Components: "S, M (a glowing stick of incense or a crystal vial filled with phosphorescent material)", Duration: "Up to 1 minute", Concentration: true, Classes: []string{"Bard", "Sorcerer", "Warlock", "Wizard"},
+		Description: "A twisting pattern of colors fills a 30-foot cube. Each creature that sees it must succeed on a Wisdom saving throw or be charmed — incapacitated with a speed of 0 — until it takes damage or someone shakes it free.",
+		Save:        "WIS",
+	},
+
+	// ── 4th level ─────────────────────────────────────────────────────────────
+	{
+		Name: "Banishment", Level: 4, School: "abjuration", CastingTime: "1 action", Range: "60 feet",
+		Components: "V, S, M (an item distasteful to the target)", Duration: "Up to 1 minute", Concentration: true, Classes: []string{"Cleric", "Paladin", "Sorcerer", "Warlock", "Wizard"},
+		Description:  "A creature you can see must succeed on a Charisma saving throw or be banished to a harmless demiplane (or, if native to another plane, to its home plane). If the spell lasts its full duration a native of another plane doesn't return.",
+		HigherLevels: "One more creature for each slot level above 4th.",
+		Save:         "CHA",
+	},
+	{
+		Name: "Dimension Door", Level: 4, School: "conjuration", CastingTime: "1 action", Range: "500 feet",
+		Components: "V", Duration: "Instantaneous", Classes: arcaneAll,
+		Description: "Teleport yourself, and optionally one willing creature of your size or smaller carried with you, to any spot within range that you can see, picture or describe by direction and distance.",
+	},
+	{
+		Name: "Greater Invisibility", Level: 4, School: "illusion", CastingTime: "1 action", Range: "Touch",
+		Components: "V, S", Duration: "Up to 1 minute", Concentration: true, Classes: []string{"Bard", "Sorcerer", "Wizard"},
+		Description: "A creature you touch becomes invisible until the spell ends, even while it attacks or casts spells.",
+	},
+	{
+		Name: "Polymorph", Level: 4, School: "transmutation", CastingTime: "1 action", Range: "60 feet",
+		Components: "V, S, M (a caterpillar cocoon)", Duration: "Up to 1 hour", Concentration: true, Classes: []string{"Bard", "Druid", "Sorcerer", "Wizard"},
+		Description: "Transform a creature into a beast whose challenge rating is no higher than its own (or level). An unwilling creature makes a Wisdom saving throw. It takes the beast's statistics and hit points, reverting when they drop to 0.",
+		Save:        "WIS",
+	},
+	{
+		Name: "Wall of Fire", Level: 4, School: "evocation", CastingTime: "1 action", Range: "120 feet",
+		Components: "V, S, M (a small piece of phosphorus)", Duration: "Up to 1 minute", Concentration: true, Classes: []string{"Druid", "Sorcerer", "Wizard"},
+		Description:  "Create a wall of fire up to 60 feet long (or a ring 20 feet across). Creatures in its area make a Dexterity saving throw, taking 5d8 fire damage on a failure or half on a success; one side deals the damage to creatures that end their turn nearby or enter the wall.",
+		HigherLevels: "The damage increases by 1d8 for each slot level above 4th.",
+		Save:         "DEX", Dice: "5d8 fire", Upcast: "1d8",
+	},
+
+	// ── 5th level ─────────────────────────────────────────────────────────────
+	{
+		Name: "Cone of Cold", Level: 5, School: "evocation", CastingTime: "1 action", Range: "Self (60-foot cone)",
+		Components: "V, S, M (a small crystal or glass cone)", Duration: "Instantaneous", Classes: arcane,
+		Description:  "A blast of cold air erupts from your hands. Each creature in the cone makes a Constitution saving throw, taking 8d8 cold damage on a failure or half on a success.",
+		HigherLevels: "The damage increases by 1d8 for each slot level above 5th.",
+		Save:         "CON", Dice: "8d8 cold", Upcast: "1d8",
+	},
+	{
+		Name: "Mass Cure Wounds", Level: 5, School: "evocation", CastingTime: "1 action", Range: "60 feet",
+		Components: "V, S", Duration: "Instantaneous", Classes: []string{"Bard", "Cleric", "Druid"},
+		Description:  "A wave of healing energy washes out from a point. Up to six creatures in a 30-foot-radius sphere each regain hit points equal to 3d8 + your spellcasting ability modifier.",
+		HigherLevels: "The healing increases by 1d8 for each slot level above 5th.",
+		Dice:         "3d8 healing", Upcast: "1d8", AddModifier: true,
+	},
+	{
+		Name: "Hold Monster", Level: 5, School: "enchantment", CastingTime: "1 action", Range: "90 feet",
+		Components: "V, S, M (a small, straight piece of iron)", Duration: "Up to 1 minute", Concentration: true, Classes: arcaneAll,
+		Description:  "A creature you can see must succeed on a Wisdom saving throw or be paralyzed for the duration. It repeats the save at the end of each of its turns. Undead are unaffected.",
+		HigherLevels: "One more creature for each slot level above 5th.",
+		Save:         "WIS",
+	},
+	{
+		Name: "Flame Strike", Level: 5, School: "evocation", CastingTime: "1 action", Range: "60 feet",
+		Components: "V, S, M (a pinch of sulfur)", Duration: "Instantaneous", Classes: clericOnly,
+		Description:  "A column of divine fire 10 feet in radius and 40 feet high roars down. Each creature in it makes a Dexterity saving throw, taking 4d6 fire damage and 4d6 radiant damage on a failure or half as much on a success.",
+		HigherLevels: "The fire or the radiant damage (your choice) increases by 1d6 for each slot level above 5th.",
+		Save:         "DEX", Dice: "4d6 fire plus 4d6 radiant", Upcast: "1d6",
+	},
+	{
+		Name: "Raise Dead", Level: 5, School: "necromancy", CastingTime: "1 hour", Range: "Touch",
+		Components: "V, S, M (a diamond worth at least 500 gp, consumed)", Duration: "Instantaneous", Classes: []string{"Bard", "Cleric", "Paladin"},
+		Description: "Return a creature dead no longer than 10 days to life with 1 hit point, if its soul is willing and free. It takes a −4 penalty to attacks, saves and checks, reduced by 1 every long rest.",
+	},
+
+	// ── 6th level and above ───────────────────────────────────────────────────
+	{
+		Name: "Heal", Level: 6, School: "evocation", CastingTime: "1 action", Range: "60 feet",
+		Components: "V, S", Duration: "Instantaneous", Classes: []string{"Cleric", "Druid"},
+		Description:  "A creature you can see regains 70 hit points and is cured of blindness, deafness and any diseases.",
+		HigherLevels: "The healing increases by 10 for each slot level above 6th.",
+	},
+	{
+		Name: "Disintegrate", Level: 6, School: "transmutation", CastingTime: "1 action", Range: "60 feet",
+		Components: "V, S, M (a lodestone and a pinch of dust)", Duration: "Instantaneous", Classes: arcane,
+		Description:  "A thin green ray springs toward a target. A creature makes a Dexterity saving throw, taking 10d6+40 force damage on a failure; one reduced to 0 hit points is disintegrated into fine gray dust.",
+		HigherLevels: "The damage increases by 3d6 for each slot level above 6th.",
+		Save:         "DEX", Dice: "10d6+40 force", Upcast: "3d6",
+	},
+	{
+		Name: "Finger of Death", Level: 7, School: "necromancy", CastingTime: "1 action", Range: "60 feet",
+		Components: "V, S", Duration: "Instantaneous", Classes: []string{"Sorcerer", "Warlock", "Wizard"},
+		Description: "Negative energy racks a creature you can see. It makes a Constitution saving throw, taking 7d8+30 necrotic damage on a failure or half on a success. A humanoid killed by it rises as a zombie under your command.",
+		Save:        "CON", Dice: "7d8+30 necrotic",
+	},
+	{
+		Name: "Power Word Stun", Level: 8, School: "enchantment", CastingTime: "1 action", Range: "60 feet",
+		Components: "V", Duration: "Instantaneous", Classes: arcaneAll,
+		Description: "Speak a word of power at a creature you can see. If it has 150 hit points or fewer it is stunned, repeating a Constitution saving throw at the end of each of its turns to end the effect.",
+	},
+	{
+		Name: "Meteor Swarm", Level: 9, School: "evocation", CastingTime: "1 action", Range: "1 mile",
+		Components: "V, S", Duration: "Instantaneous", Classes: arcane,
+		Description: "Four blazing orbs of fire plummet to points you can see. Each creature in a 40-foot-radius sphere around each point makes a Dexterity saving throw, taking 20d6 fire and 20d6 bludgeoning damage on a failure or half on a success.",
+		Save:        "DEX", Dice: "20d6 fire plus 20d6 bludgeoning",
+	},
+	{
+		Name: "Wish", Level: 9, School: "conjuration", CastingTime: "1 action", Range: "Self",
+		Components: "V", Duration: "Instantaneous", Classes: arcane,
+		Description: "The mightiest spell a mortal can cast. Duplicate any spell of 8th level or lower without its components, or state a wish the DM adjudicates; anything beyond duplicating a spell risks stress and losing the ability to cast Wish ever again.",
+	},
+}
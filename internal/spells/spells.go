@@ -0,0 +1,191 @@
+// Package spells provides a curated subset of the D&D 5e System Reference
+// Document (SRD 5.1) spells, embedded so the DM tools can look a spell up, cast
+// it against the right slot, and so chargen can give a caster a starting spell
+// list without any authored data.
+//
+// SRD 5.1 is published by Wizards of the Coast LLC under the Creative Commons
+// Attribution 4.0 International License (CC-BY-4.0). See docs/srd-statblocks.md
+// for the attribution notice. Descriptions are condensed; only a curated subset
+// of commonly cast spells is embedded, and the catalog is the extension point
+// for adding more.
+//
+// The package has no internal dependencies so that domain (chargen) can use it.
+package spells
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Source is the attribution carried by every catalog spell.
+const Source = "SRD 5.1 (CC-BY-4.0)"
+
+// Spell is one catalog entry.
+type Spell struct {
+	Name          string
+	Level         int // 0 = cantrip
+	School        string
+	CastingTime   string
+	Range         string
+	Components    string // e.g. "V, S, M (a tiny ball of bat guano and sulfur)"
+	Duration      string
+	Concentration bool
+	Ritual        bool
+	Classes       []string // class names as in domain.Classes
+	Description   string
+	HigherLevels  string // what a higher slot (or, for a cantrip, caster level) adds
+
+	// Save is the ability of the saving throw the spell calls for ("DEX"), if any;
+	// Attack marks a spell attack.
+	Save   string
+	Attack bool
+	// Dice is the spell's base damage or healing ("8d6 fire", "1d8 healing").
+	// Upcast is the dice added per slot level above the spell's own ("1d6");
+	// cantrip dice instead multiply at caster levels 5, 11 and 17.
+	// AddModifier adds the caster's spellcasting modifier to the roll.
+	Dice        string
+	Upcast      string
+	AddModifier bool
+}
+
+// LevelText describes the spell's level and school: "3rd-level evocation" or
+// "Evocation cantrip".
+func (s Spell) LevelText() string {
+	if s.Level == 0 {
+		return capitalize(s.School) + " cantrip"
+	}
+	return Ordinal(s.Level) + "-level " + strings.ToLower(s.School)
+}
+
+// Ordinal renders 1 as "1st", 2 as "2nd", and so on.
+func Ordinal(n int) string {
+	suffix := "th"
+	switch {
+	case n%100 >= 11 && n%100 <= 13:
+	case n%10 == 1:
+		suffix = "st"
+	case n%10 == 2:
+		suffix = "nd"
+	case n%10 == 3:
+		suffix = "rd"
+	}
+	return strconv.Itoa(n) + suffix
+}
+
+func capitalize(s string) string {
+	if s == "" {
+		return s
+	}
+	return strings.ToUpper(s[:1]) + s[1:]
+}
+
+// diceRe splits a dice string into count, die, flat bonus and trailing text.
+var diceRe = regexp.MustCompile(`^(\d+)d(\d+)([+-]\d+)?(.*)$`)
+
+// DiceAt is the spell's dice when cast with a slot of slotLevel by a caster of
+// casterLevel (the latter only matters for cantrips). It returns "" for a spell
+// without dice. An Upcast on a different die than the base is not combined.
+func (s Spell) DiceAt(slotLevel, casterLevel int) string {
+	m := diceRe.FindStringSubmatch(s.Dice)
+	if m == nil {
+		return s.Dice
+	}
+	n, _ := strconv.Atoi(m[1])
+	flat, _ := strconv.Atoi(m[3])
+	switch {
+	case s.Level == 0:
+		mult := 1
+		for _, l := range []int{5, 11, 17} {
+			if casterLevel >= l {
+				mult++
+			}
+		}
+		n *= mult
+	case slotLevel > s.Level && s.Upcast != "":
+		if u := diceRe.FindStringSubmatch(s.Upcast); u != nil && u[2] == m[2] {
+			un, _ := strconv.Atoi(u[1])
+			uf, _ := strconv.Atoi(u[3])
+			n += un * (slotLevel - s.Level)
+			flat += uf * (slotLevel - s.Level)
+		}
+	}
+	out := fmt.Sprintf("%dd%s", n, m[2])
+	if flat != 0 {
+		out += fmt.Sprintf("%+d", flat)
+	}
+	return out + m[4]
+}
+
+// normalize lower-cases a spell name and drops punctuation and extra spaces, so
+// "hunters mark" finds "Hunter's Mark".
+func normalize(name string) string {
+	name = strings.NewReplacer("'", "", "’", "", "-", " ", "/", " ").Replace(strings.ToLower(name))
+	return strings.Join(strings.Fields(name), " ")
+}
+
+var index = func() map[string]int {
+	m := make(map[string]int, len(catalog))
+	for i, s := range catalog {
+		m[normalize(s.Name)] = i
+	}
+	return m
+}()
+
+// Lookup returns a catalog spell by name (case- and punctuation-insensitive).
+// The returned value is a copy; its Classes slice is cloned.
+func Lookup(name string) (Spell, bool) {
+	i, ok := index[normalize(name)]
+	if !ok {
+		return Spell{}, false
+	}
+	s := catalog[i]
+	s.Classes = slices.Clone(s.Classes)
+	return s, true
+}
+
+// Names returns the catalog's spell names, sorted.
+func Names() []string {
+	out := make([]string, len(catalog))
+	for i, s := range catalog {
+		out[i] = s.Name
+	}
+	slices.Sort(out)
+	return out
+}
+
+// Search returns the names of the spells whose name contains query
+// (normalized), sorted.
+func Search(query string) []string {
+	q := normalize(query)
+	var out []string
+	for _, s := range catalog {
+		if q != "" && strings.Contains(normalize(s.Name), q) {
+			out = append(out, s.Name)
+		}
+	}
+	slices.Sort(out)
+	return out
+}
+
+// ForClass returns the catalog spells on a class's list up to maxLevel
+// (inclusive; 0 for cantrips only), in catalog order — the more commonly chosen
+// spells of each level come first.
+func ForClass(class string, maxLevel int) []Spell {
+	var out []Spell
+	for _, s := range catalog {
+		if s.Level > maxLevel {
+			continue
+		}
+		for _, c := range s.Classes {
+			if strings.EqualFold(c, strings.TrimSpace(class)) {
+				s.Classes = slices.Clone(s.Classes)
+				out = append(out, s)
+				break
+			}
+		}
+	}
+	return out
+}
@@ -0,0 +1,125 @@
+package spells
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestCatalogEntriesAreWellFormed(t *testing.T) {
+	classes := []string{"Barbarian", "Bard", "Cleric", "Druid", "Fighter", "Monk", "Paladin", "Ranger", "Rogue", "Sorcerer", "Warlock", "Wizard"}
+	seen := map[string]bool{}
+	for _, s := range catalog {
+		if seen[normalize(s.Name)] {
+			t.Errorf("duplicate spell %q", s.Name)
+		}
+		seen[normalize(s.Name)] = true
+		if s.Level < 0 || s.Level > 9 || s.School == "" || s.CastingTime == "" || s.Range == "" ||
+			s.Components == "" || s.Duration == "" || s.Description == "" || len(s.Classes) == 0 {
+			t.Errorf("%s is missing a field: %+v", s.Name, s)
+		}
+		for _, c := range s.Classes {
+			if !slices.Contains(classes, c) {
+				t.Errorf("%s lists unknown class %q", s.Name, c)
+			}
+		}
+		if s.Concentration != (len(s.Duration) > 5 && s.Duration[:5] == "Up to" && s.Name != "Prestidigitation" && s.Name != "Thaumaturgy") {
+			t.Errorf("%s: concentration %v does not match duration %q", s.Name, s.Concentration, s.Duration)
+		}
+		if s.Dice != "" && diceRe.FindStringSubmatch(s.Dice) == nil {
+			t.Errorf("%s: unparsable dice %q", s.Name, s.Dice)
+		}
+		if s.Upcast != "" && (s.Level == 0 || diceRe.FindStringSubmatch(s.Upcast) == nil) {
+			t.Errorf("%s: bad upcast %q", s.Name, s.Upcast)
+		}
+	}
+}
+
+func TestLookupIsCaseAndPunctuationInsensitive(t *testing.T) {
+	s, ok := Lookup("  hunters MARK ")
+	if !ok || s.Name != "Hunter's Mark" || !s.Concentration {
+		t.Fatalf("Lookup(hunters mark) = %+v, %v", s, ok)
+	}
+	if _, ok := Lookup("hex"); ok {
+		t.Error("a spell outside the SRD subset must not resolve")
+	}
+	s.Classes[0] = "MUTATED"
+	if again, _ := Lookup("hunter's mark"); again.Classes[0] == "MUTATED" {
+		t.Error("Lookup must copy Classes — the catalog entry was corrupted")
+	}
+}
+
+func TestLevelText(t *testing.T) {
+	fb, _ := Lookup("fireball")
+	ft, _ := Lookup("fire bolt")
+	if got := fb.LevelText(); got != "3rd-level evocation" {
+		t.Errorf("Fireball = %q", got)
+	}
+	if got := ft.LevelText(); got != "Evocation cantrip" {
+		t.Errorf("Fire Bolt = %q", got)
+	}
+	for n, want := range map[int]string{1: "1st", 2: "2nd", 3: "3rd", 4: "4th", 11: "11th", 12: "12th", 21: "21st"} {
+		if got := Ordinal(n); got != want {
+			t.Errorf("Ordinal(%d) = %q, want %q", n, got, want)
+		}
+	}
+}
+
+func TestDiceAtScales(t *testing.T) {
+	for _, tc := range []struct {
+		spell       string
+		slot, level int
+		want        string
+	}{
+		{"Fireball", 3, 5, "8d6 fire"},
+		{"Fireball", 5, 9, "10d6 fire"},
+		{"Magic Missile", 3, 5, "5d4+5 force"},
+		{"Cure Wounds", 2, 3, "2d8 healing"},
+		{"Fire Bolt", 0, 4, "1d10 fire"},
+		{"Fire Bolt", 0, 5, "2d10 fire"},
+		{"Fire Bolt", 0, 17, "4d10 fire"},
+		{"Disintegrate", 7, 13, "13d6+40 force"},
+		{"Scorching Ray", 4, 7, "2d6 fire"}, // more rays, not more dice
+		{"Shield", 2, 3, ""},
+	} {
+		s, ok := Lookup(tc.spell)
+		if !ok {
+			t.Fatalf("%s missing", tc.spell)
+		}
+		if got := s.DiceAt(tc.slot, tc.level); got != tc.want {
+			t.Errorf("%s.DiceAt(%d, %d) = %q, want %q", tc.spell, tc.slot, tc.level, got, tc.want)
+		}
+	}
+}
+
+func TestForClass(t *testing.T) {
+	cantrips := ForClass("cleric", 0)
+	if len(cantrips) < 3 {
+		t.Fatalf("cleric cantrips = %d, want at least 3", len(cantrips))
+	}
+	for _, s := range cantrips {
+		if s.Level != 0 || !slices.Contains(s.Classes, "Cleric") {
+			t.Errorf("ForClass(cleric, 0) returned %s (level %d)", s.Name, s.Level)
+		}
+	}
+	wiz := ForClass("Wizard", 3)
+	if !slices.ContainsFunc(wiz, func(s Spell) bool { return s.Name == "Fireball" }) ||
+		slices.ContainsFunc(wiz, func(s Spell) bool { return s.Level > 3 }) {
+		t.Error("ForClass(Wizard, 3) should include Fireball and nothing above 3rd level")
+	}
+	if got := ForClass("Fighter", 9); len(got) != 0 {
+		t.Errorf("fighters have no spell list, got %d", len(got))
+	}
+}
+
+func TestNamesAndSearch(t *testing.T) {
+	names := Names()
+	if len(names) != len(catalog) || !slices.IsSorted(names) {
+		t.Errorf("Names() should list every spell, sorted")
+	}
+	if got := Search("heal"); !slices.Equal(got, []string{"Heal", "Healing Word", "Mass Healing Word", "Prayer of Healing"}) {
+		t.Errorf("Search(heal) = %v", got)
+	}
+	if got := Search(""); got != nil {
+		t.Errorf("an empty query should match nothing, got %v", got)
+	}
+}
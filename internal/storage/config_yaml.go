@@ -13,7 +13,7 @@ import (
 // the code is unaffected by the file layout.
 type fileConfig struct {
 	Provider struct {
-		Name            string  `yaml:"name"`       // openai | anthropic | gemini | claude-cli
+		Name            string  `yaml:"name"`       // openai | anthropic | gemini | claude-cli | local
 		Model           string  `yaml:"model"`      // default model id (both apps)
 		RunModel        string  `yaml:"run_model"`  // optional: model for the player/oracle
 		EditModel       string  `yaml:"edit_model"` // optional: model for the editor/import
@@ -22,6 +22,14 @@ type fileConfig struct {
 		OpenAIAPIKey    string  `yaml:"openai_api_key"`    // optional; prefer env / local login
 		AnthropicAPIKey string  `yaml:"anthropic_api_key"` // optional
 		GeminiAPIKey    string  `yaml:"gemini_api_key"`    // optional
+		// Local OpenAI-compatible server (llama.cpp, Ollama, vLLM, LM Studio).
+		LocalBaseURL  string `yaml:"local_base_url"`  // e.g. http://localhost:11434/v1
+		LocalAPIKey   string `yaml:"local_api_key"`   // optional; most local servers take none
+		LocalToolMode string `yaml:"local_tool_mode"` // auto | native | prompt
+		// Fallbacks are tried in order when the provider above fails with a
+		// transient error (outage, overload, rate limit, timeout).
+		Fallbacks              []fileProviderChoice `yaml:"fallbacks,omitempty"`
+		FallbackTimeoutSeconds int                  `yaml:"fallback_timeout_seconds"` // per backend; 0 = 45
 	} `yaml:"provider"`
 
 	UI struct {
@@ -69,15 +77,38 @@ type fileConfig struct {
 		Model    string `yaml:"model,omitempty"`
 	} `yaml:"spoiler_guard"`
 
+	Usage struct {
+		// Token budgets per session: past the soft one the DM is warned, at the
+		// hard one the oracle stops answering. 0 disables either.
+		SoftTokenBudget int `yaml:"soft_token_budget"`
+		HardTokenBudget int `yaml:"hard_token_budget"`
+		// Pricing estimates costs in USD per million tokens, keyed by model name
+		// or name prefix: {input: 2.5, output: 10}.
+		Pricing map[string]domain.ModelPrice `yaml:"pricing,omitempty"`
+	} `yaml:"usage"`
+
 	Telegram struct {
 		BotToken     string   `yaml:"bot_token"`               // token to host the multiplayer bot
 		ChatID       int64    `yaml:"chat_id"`                 // optional: restrict the bot to this chat
 		AllowedUsers []string `yaml:"allowed_users,omitempty"` // optional: user ids / @usernames allowed to talk to the bot
 	} `yaml:"telegram"`
 
+	// Cassette records every model call to path, or replays them from it
+	// (mode: record | replay), to reproduce a session without an API key.
+	Cassette struct {
+		Mode string `yaml:"mode,omitempty"`
+		Path string `yaml:"path,omitempty"`
+	} `yaml:"cassette,omitempty"`
+
 	SystemPrompt string `yaml:"system_prompt,omitempty"`
 }
 
+// fileProviderChoice is a fallback backend in the YAML file.
+type fileProviderChoice struct {
+	Name  string `yaml:"name"`
+	Model string `yaml:"model,omitempty"` // empty → the provider's default model
+}
+
 func fromConfig(c *domain.Config) fileConfig {
 	var fc fileConfig
 	fc.Provider.Name = string(c.Provider)
@@ -89,6 +120,13 @@ func fromConfig(c *domain.Config) fileConfig {
 	fc.Provider.OpenAIAPIKey = c.OpenAIAPIKey
 	fc.Provider.AnthropicAPIKey = c.AnthropicAPIKey
 	fc.Provider.GeminiAPIKey = c.GeminiAPIKey
+	fc.Provider.LocalBaseURL = c.LocalBaseURL
+	fc.Provider.LocalAPIKey = c.LocalAPIKey
+	fc.Provider.LocalToolMode = c.LocalToolMode
+	for _, f := range c.Fallbacks {
+		fc.Provider.Fallbacks = append(fc.Provider.Fallbacks, fileProviderChoice{Name: string(f.Provider), Model: f.Model})
+	}
+	fc.Provider.FallbackTimeoutSeconds = c.FallbackTimeoutSeconds
 
 	fc.UI.Language = string(c.Language)
 	fc.UI.ShowScanlines = c.ShowScanlines
@@ -118,10 +156,17 @@ func fromConfig(c *domain.Config) fileConfig {
 	fc.SpoilerGuard.Provider = string(c.SpoilerGuard.Provider)
 	fc.SpoilerGuard.Model = c.SpoilerGuard.Model
 
+	fc.Usage.SoftTokenBudget = c.SessionBudget.Soft
+	fc.Usage.HardTokenBudget = c.SessionBudget.Hard
+	fc.Usage.Pricing = c.Pricing
+
 	fc.Telegram.BotToken = c.TelegramToken
 	fc.Telegram.ChatID = c.TelegramChatID
 	fc.Telegram.AllowedUsers = c.TelegramAllowedUsers
 
+	fc.Cassette.Mode = c.Cassette.Mode
+	fc.Cassette.Path = c.Cassette.Path
+
 	fc.SystemPrompt = c.SystemPrompt
 	return fc
 }
@@ -136,6 +181,14 @@ func toConfig(fc *fileConfig, c *domain.Config) {
 	c.OpenAIAPIKey = fc.Provider.OpenAIAPIKey
 	c.AnthropicAPIKey = fc.Provider.AnthropicAPIKey
 	c.GeminiAPIKey = fc.Provider.GeminiAPIKey
+	c.LocalBaseURL = fc.Provider.LocalBaseURL
+	c.LocalAPIKey = fc.Provider.LocalAPIKey
+	c.LocalToolMode = fc.Provider.LocalToolMode
+	c.Fallbacks = nil
+	for _, f := range fc.Provider.Fallbacks {
+		c.Fallbacks = append(c.Fallbacks, domain.ProviderChoice{Provider: domain.ProviderType(f.Name), Model: f.Model})
+	}
+	c.FallbackTimeoutSeconds = fc.Provider.FallbackTimeoutSeconds
 
 	c.Language = domain.Language(fc.UI.Language)
 	c.ShowScanlines = fc.UI.ShowScanlines
@@ -165,10 +218,17 @@ func toConfig(fc *fileConfig, c *domain.Config) {
 	c.SpoilerGuard.Provider = domain.ProviderType(fc.SpoilerGuard.Provider)
 	c.SpoilerGuard.Model = fc.SpoilerGuard.Model
 
+	c.SessionBudget.Soft = fc.Usage.SoftTokenBudget
+	c.SessionBudget.Hard = fc.Usage.HardTokenBudget
+	c.Pricing = fc.Usage.Pricing
+
 	c.TelegramToken = fc.Telegram.BotToken
 	c.TelegramChatID = fc.Telegram.ChatID
 	c.TelegramAllowedUsers = fc.Telegram.AllowedUsers
 
+	c.Cassette.Mode = fc.Cassette.Mode
+	c.Cassette.Path = fc.Cassette.Path
+
 	c.SystemPrompt = fc.SystemPrompt
 }
 
@@ -190,6 +250,7 @@ func marshalYAML(c *domain.Config) ([]byte, error) {
 	fc.Provider.OpenAIAPIKey = ""
 	fc.Provider.AnthropicAPIKey = ""
 	fc.Provider.GeminiAPIKey = ""
+	fc.Provider.LocalAPIKey = ""
 
 	body, err := yaml.Marshal(&fc)
 	if err != nil {
@@ -16,6 +16,8 @@ func clearProviderEnv(t *testing.T) {
 		"THAIM_OPENAI_API_KEY", "OPENAI_API_KEY",
 		"THAIM_ANTHROPIC_API_KEY", "ANTHROPIC_API_KEY",
 		"THAIM_GEMINI_API_KEY", "GEMINI_API_KEY", "GOOGLE_API_KEY",
+		"THAIM_LOCAL_BASE_URL", "THAIM_LOCAL_API_KEY",
+		"THAIM_CASSETTE_MODE", "THAIM_CASSETTE",
 	} {
 		t.Setenv(k, "")
 	}
@@ -172,3 +174,129 @@ func TestConfigSpoilerGuardProviderRoundTrip(t *testing.T) {
 		t.Errorf("spoiler guard not round-tripped: %+v", loaded.SpoilerGuard)
 	}
 }
+
+// TestConfigLocalProviderRoundTrip verifies the local server settings persist
+// while its API key, like the others, stays off disk.
+func TestConfigLocalProviderRoundTrip(t *testing.T) {
+	clearProviderEnv(t)
+	store, _ := NewWithPath(t.TempDir())
+
+	c := domain.DefaultConfig()
+	c.Provider = domain.ProviderLocal
+	c.Model = "qwen2.5:7b"
+	c.LocalBaseURL = "http://localhost:11434/v1"
+	c.LocalAPIKey = "local-SECRET"
+	c.LocalToolMode = domain.LocalToolsPrompt
+	if err := store.SaveConfig(c); err != nil {
+		t.Fatalf("SaveConfig: %v", err)
+	}
+	raw, _ := os.ReadFile(store.ConfigPath())
+	if strings.Contains(string(raw), "local-SECRET") || !strings.Contains(string(raw), "local_base_url: http://localhost:11434/v1") {
+		t.Errorf("local provider section wrong:\n%s", raw)
+	}
+	loaded, err := store.LoadConfig()
+	if err != nil {
+		t.Fatalf("LoadConfig: %v", err)
+	}
+	if loaded.Provider != domain.ProviderLocal || loaded.LocalBaseURL != c.LocalBaseURL || loaded.LocalToolMode != domain.LocalToolsPrompt || loaded.LocalAPIKey != "" {
+		t.Errorf("local provider not round-tripped: %q %q %q %q", loaded.Provider, loaded.LocalBaseURL, loaded.LocalToolMode, loaded.LocalAPIKey)
+	}
+
+	t.Setenv("THAIM_LOCAL_BASE_URL", "http://gpu-box.lan:8000/v1")
+	if loaded, _ = store.LoadConfig(); loaded.LocalBaseURL != "http://gpu-box.lan:8000/v1" {
+		t.Errorf("THAIM_LOCAL_BASE_URL not applied: %q", loaded.LocalBaseURL)
+	}
+}
+
+// TestConfigFallbacksRoundTrip verifies the provider fallback chain persists in
+// order, and that a chain edited in the file replaces the one loaded.
+func TestConfigFallbacksRoundTrip(t *testing.T) {
+	clearProviderEnv(t)
+	store, _ := NewWithPath(t.TempDir())
+
+	c := domain.DefaultConfig()
+	c.Fallbacks = []domain.ProviderChoice{{Provider: domain.ProviderAnthropic, Model: "claude-haiku-4-5"}, {Provider: domain.ProviderLocal}}
+	c.FallbackTimeoutSeconds = 20
+	if err := store.SaveConfig(c); err != nil {
+		t.Fatalf("SaveConfig: %v", err)
+	}
+	loaded, err := store.LoadConfig()
+	if err != nil {
+		t.Fatalf("LoadConfig: %v", err)
+	}
+	if len(loaded.Fallbacks) != 2 || loaded.Fallbacks[0] != c.Fallbacks[0] || loaded.Fallbacks[1] != c.Fallbacks[1] || loaded.FallbackTimeoutSeconds != 20 {
+		t.Errorf("fallbacks = %+v, timeout %d", loaded.Fallbacks, loaded.FallbackTimeoutSeconds)
+	}
+
+	raw, _ := os.ReadFile(store.ConfigPath())
+	edited := strings.Replace(string(raw), "name: anthropic", "name: gemini", 1)
+	if err := os.WriteFile(store.ConfigPath(), []byte(edited), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if loaded, _ = store.LoadConfig(); len(loaded.Fallbacks) != 2 || loaded.Fallbacks[0].Provider != domain.ProviderGemini {
+		t.Errorf("edited fallbacks = %+v", loaded.Fallbacks)
+	}
+}
+
+func TestConfigCassette(t *testing.T) {
+	clearProviderEnv(t)
+	store, _ := NewWithPath(t.TempDir())
+
+	c := domain.DefaultConfig()
+	if err := store.SaveConfig(c); err != nil {
+		t.Fatalf("SaveConfig: %v", err)
+	}
+	if raw, _ := os.ReadFile(store.ConfigPath()); strings.Contains(string(raw), "cassette") {
+		t.Errorf("an unused cassette section should be left out:\n%s", raw)
+	}
+
+	c.Cassette = domain.CassetteConfig{Mode: domain.CassetteRecord, Path: "/tmp/bug.json"}
+	if err := store.SaveConfig(c); err != nil {
+		t.Fatalf("SaveConfig: %v", err)
+	}
+	loaded, _ := store.LoadConfig()
+	if loaded.Cassette != c.Cassette {
+		t.Errorf("cassette = %+v", loaded.Cassette)
+	}
+
+	t.Setenv("THAIM_CASSETTE_MODE", "Replay")
+	t.Setenv("THAIM_CASSETTE", "repro.json")
+	loaded, _ = store.LoadConfig()
+	if loaded.Cassette.Mode != domain.CassetteReplay || loaded.Cassette.Path != "repro.json" {
+		t.Errorf("env cassette = %+v", loaded.Cassette)
+	}
+}
+
+// TestConfigUsageRoundTrip verifies the token budgets and the price table
+// persist, and that a partial price table in the file extends the defaults.
+func TestConfigUsageRoundTrip(t *testing.T) {
+	clearProviderEnv(t)
+	store, _ := NewWithPath(t.TempDir())
+
+	c := domain.DefaultConfig()
+	c.SessionBudget = domain.TokenBudget{Soft: 400000, Hard: 500000}
+	if err := store.SaveConfig(c); err != nil {
+		t.Fatalf("SaveConfig: %v", err)
+	}
+	raw, _ := os.ReadFile(store.ConfigPath())
+	if !strings.Contains(string(raw), "hard_token_budget: 500000") || !strings.Contains(string(raw), "gpt-4o-mini:") {
+		t.Errorf("usage section not written:\n%s", raw)
+	}
+	edited := strings.Replace(string(raw), "pricing:\n", "pricing:\n        local-llama:\n            input: 0\n            output: 0.1\n", 1)
+	if err := os.WriteFile(store.ConfigPath(), []byte(edited), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := store.LoadConfig()
+	if err != nil {
+		t.Fatalf("LoadConfig: %v", err)
+	}
+	if loaded.SessionBudget != c.SessionBudget {
+		t.Errorf("budget = %+v", loaded.SessionBudget)
+	}
+	if p, ok := loaded.Pricing["local-llama"]; !ok || p.Output != 0.1 {
+		t.Errorf("added price = %+v", loaded.Pricing)
+	}
+	if _, ok := loaded.Pricing["gpt-4o"]; !ok {
+		t.Errorf("default prices should remain: %+v", loaded.Pricing)
+	}
+}
@@ -104,7 +104,7 @@ func (s *Storage) ImportModule(srcPath string) (*domain.Adventure, error) {
 		info, statErr := os.Stat(p)
 		return statErr == nil && !info.IsDir()
 	}
-	if verrs := domain.ValidateAdventure(&adv, imageExists); len(verrs) > 0 {
+	if verrs := domain.ValidationErrors(domain.ValidateAdventure(&adv, imageExists, nil)); len(verrs) > 0 {
 		return nil, fmt.Errorf("adventure validation failed:\n%s", joinErrs(verrs))
 	}
 
@@ -0,0 +1,212 @@
+package storage
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/theburrowhub/thaimaturgy/internal/domain"
+)
+
+// A session's snapshots — named save points such as "before the vault heist" —
+// live in a sibling directory "<name>.snapshots/" next to the session JSON and
+// its journal, one JSON file each. A snapshot is never loaded in place: playing
+// from one forks a new session (BranchSession), so the canon run stays intact.
+
+// SnapshotInfo is lightweight metadata for listing a session's snapshots.
+type SnapshotInfo struct {
+	ID          string    `json:"id"`
+	Label       string    `json:"label"`
+	Session     string    `json:"session"`
+	CreatedAt   time.Time `json:"created_at"`
+	CurrentRoom string    `json:"current_room"`
+}
+
+// snapshotFile is the on-disk form: the metadata plus the full session state.
+type snapshotFile struct {
+	SnapshotInfo
+	State *domain.SessionState `json:"state"`
+}
+
+// sessionSnapshotsDir is the session's snapshot directory, validated like the
+// novel path so a crafted name can't point outside the sessions directory.
+func (s *Storage) sessionSnapshotsDir(name string) (string, error) {
+	if name == "" || strings.ContainsAny(name, `/\`) || strings.Contains(name, "..") || strings.ContainsRune(name, 0) {
+		return "", fmt.Errorf("invalid session name: %q", name)
+	}
+	dir := filepath.Join(s.basePath, SessionsDir)
+	p := filepath.Join(dir, name+".snapshots")
+	if filepath.Dir(p) != dir {
+		return "", fmt.Errorf("invalid session name: %q", name)
+	}
+	return p, nil
+}
+
+// SaveSnapshot writes a named snapshot of a session's current state and returns
+// its metadata. The id is the time it was taken plus a slug of the label, so
+// snapshots sort in the order they were taken.
+func (s *Storage) SaveSnapshot(state *domain.SessionState, label string) (SnapshotInfo, error) {
+	label = strings.TrimSpace(label)
+	if label == "" {
+		return SnapshotInfo{}, fmt.Errorf("a snapshot needs a label")
+	}
+	dir, err := s.sessionSnapshotsDir(state.Name)
+	if err != nil {
+		return SnapshotInfo{}, err
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return SnapshotInfo{}, err
+	}
+	now := time.Now()
+	base := now.Format("20060102-150405") + "-" + slugifyName(label)
+	id := base
+	for i := 2; ; i++ {
+		if _, err := os.Stat(filepath.Join(dir, id+".json")); os.IsNotExist(err) {
+			break
+		}
+		id = fmt.Sprintf("%s-%d", base, i)
+	}
+	_, room := state.Location()
+	info := SnapshotInfo{ID: id, Label: label, Session: state.Name, CreatedAt: now, CurrentRoom: room}
+	data, err := json.MarshalIndent(snapshotFile{SnapshotInfo: info, State: state}, "", "  ")
+	if err != nil {
+		return SnapshotInfo{}, fmt.Errorf("failed to marshal snapshot: %w", err)
+	}
+	if err := atomicWriteFile(filepath.Join(dir, id+".json"), data, 0644); err != nil {
+		return SnapshotInfo{}, fmt.Errorf("failed to write snapshot: %w", err)
+	}
+	return info, nil
+}
+
+// ListSnapshots returns a session's snapshots, oldest first. A session without
+// any has none (no error).
+func (s *Storage) ListSnapshots(name string) ([]SnapshotInfo, error) {
+	dir, err := s.sessionSnapshotsDir(name)
+	if err != nil {
+		return nil, err
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read snapshots: %w", err)
+	}
+	var out []SnapshotInfo
+	for _, e := range entries {
+		if e.IsDir() || !strings.HasSuffix(e.Name(), ".json") {
+			continue
+		}
+		f, err := readSnapshot(filepath.Join(dir, e.Name()))
+		if err != nil {
+			continue
+		}
+		// The file name and the directory are authoritative (a rename moves the
+		// directory without rewriting the files).
+		f.SnapshotInfo.ID = strings.TrimSuffix(e.Name(), ".json")
+		f.SnapshotInfo.Session = name
+		out = append(out, f.SnapshotInfo)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].CreatedAt.Before(out[j].CreatedAt) })
+	return out, nil
+}
+
+// LoadSnapshot reads a snapshot of a session by id or, failing that, by label
+// (case-insensitive; the latest one wins when several share it).
+func (s *Storage) LoadSnapshot(name, ref string) (*domain.SessionState, SnapshotInfo, error) {
+	ref = strings.TrimSpace(ref)
+	list, err := s.ListSnapshots(name)
+	if err != nil {
+		return nil, SnapshotInfo{}, err
+	}
+	var found *SnapshotInfo
+	for i := range list {
+		if list[i].ID == ref {
+			found = &list[i]
+			break
+		}
+		if strings.EqualFold(list[i].Label, ref) {
+			found = &list[i] // keep scanning: a later one with the label wins
+		}
+	}
+	if found == nil {
+		return nil, SnapshotInfo{}, fmt.Errorf("session %q has no snapshot %q", name, ref)
+	}
+	dir, _ := s.sessionSnapshotsDir(name)
+	f, err := readSnapshot(filepath.Join(dir, found.ID+".json"))
+	if err != nil {
+		return nil, SnapshotInfo{}, err
+	}
+	if f.State == nil {
+		return nil, SnapshotInfo{}, fmt.Errorf("snapshot %q has no session state", found.ID)
+	}
+	// As LoadSession: keep the full history from here on.
+	if f.State.Log != nil {
+		f.State.Log.MaxSize = 0
+	}
+	if f.State.Conversation != nil {
+		f.State.Conversation.MaxSize = 0
+	}
+	return f.State, *found, nil
+}
+
+// DeleteSnapshot removes one snapshot of a session.
+func (s *Storage) DeleteSnapshot(name, id string) error {
+	dir, err := s.sessionSnapshotsDir(name)
+	if err != nil {
+		return err
+	}
+	if id == "" || strings.ContainsAny(id, `/\`) || strings.Contains(id, "..") {
+		return fmt.Errorf("invalid snapshot id: %q", id)
+	}
+	if err := os.Remove(filepath.Join(dir, id+".json")); err != nil {
+		if os.IsNotExist(err) {
+			return fmt.Errorf("session %q has no snapshot %q", name, id)
+		}
+		return fmt.Errorf("failed to delete snapshot: %w", err)
+	}
+	return nil
+}
+
+// BranchSession forks a new session named newName from one of name's snapshots
+// (by id or label). The original session and its snapshots are left as they
+// are; the branch starts with its own journal and no snapshots. It won't
+// overwrite an existing session.
+func (s *Storage) BranchSession(name, ref, newName string) (*domain.SessionState, error) {
+	newName = strings.TrimSpace(newName)
+	if newName == "" {
+		return nil, fmt.Errorf("a name for the branch is required")
+	}
+	if strings.ContainsAny(newName, `/\`) || strings.Contains(newName, "..") {
+		return nil, fmt.Errorf("invalid session name: %q", newName)
+	}
+	if s.SessionExists(newName) {
+		return nil, fmt.Errorf("a session named %q already exists", newName)
+	}
+	state, info, err := s.LoadSnapshot(name, ref)
+	if err != nil {
+		return nil, err
+	}
+	state.Name = newName
+	state.AddNote(fmt.Sprintf("Branched from %q at snapshot “%s”.", name, info.Label))
+	if err := s.SaveSession(state); err != nil {
+		return nil, err
+	}
+	return state, nil
+}
+
+func readSnapshot(path string) (*snapshotFile, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read snapshot: %w", err)
+	}
+	var f snapshotFile
+	if err := json.Unmarshal(data, &f); err != nil {
+		return nil, fmt.Errorf("failed to parse snapshot: %w", err)
+	}
+	return &f, nil
+}
@@ -0,0 +1,95 @@
+package storage
+
+import (
+	"os"
+	"testing"
+
+	"github.com/theburrowhub/thaimaturgy/internal/domain"
+)
+
+func TestSnapshotAndBranch(t *testing.T) {
+	tmpDir, _ := os.MkdirTemp("", "thaimaturgy-snap-*")
+	defer os.RemoveAll(tmpDir)
+	store, _ := NewWithPath(tmpDir)
+
+	state := domain.NewSessionState("canon", sampleAdventure())
+	state.SetFlag("vault_open", false)
+	if err := store.SaveSession(state); err != nil {
+		t.Fatalf("SaveSession: %v", err)
+	}
+	info, err := store.SaveSnapshot(state, "Before the vault heist")
+	if err != nil {
+		t.Fatalf("SaveSnapshot: %v", err)
+	}
+	if _, err := store.SaveSnapshot(state, "  "); err == nil {
+		t.Error("a snapshot without a label should be rejected")
+	}
+
+	// The canon run goes on after the snapshot.
+	state.SetFlag("vault_open", true)
+	_ = store.SaveSession(state)
+
+	list, err := store.ListSnapshots("canon")
+	if err != nil || len(list) != 1 || list[0].ID != info.ID || list[0].Label != "Before the vault heist" {
+		t.Fatalf("ListSnapshots = %+v, %v", list, err)
+	}
+
+	branch, err := store.BranchSession("canon", "before the VAULT heist", "what-if")
+	if err != nil {
+		t.Fatalf("BranchSession: %v", err)
+	}
+	if branch.Name != "what-if" || branch.Flags["vault_open"] {
+		t.Errorf("branch = %q, flags %v", branch.Name, branch.Flags)
+	}
+	loaded, err := store.LoadSession("what-if")
+	if err != nil || loaded.Flags["vault_open"] {
+		t.Fatalf("LoadSession(branch) = %v, %v", loaded, err)
+	}
+	canon, _ := store.LoadSession("canon")
+	if !canon.Flags["vault_open"] {
+		t.Error("branching must leave the original session intact")
+	}
+	if _, err := store.BranchSession("canon", info.ID, "what-if"); err == nil {
+		t.Error("branching onto an existing session should fail")
+	}
+	if _, err := store.BranchSession("canon", "no such point", "other"); err == nil {
+		t.Error("branching from an unknown snapshot should fail")
+	}
+	if got, _ := store.ListSnapshots("what-if"); len(got) != 0 {
+		t.Errorf("a new branch should have no snapshots, got %d", len(got))
+	}
+}
+
+func TestSnapshotsFollowRenameAndDelete(t *testing.T) {
+	tmpDir, _ := os.MkdirTemp("", "thaimaturgy-snap2-*")
+	defer os.RemoveAll(tmpDir)
+	store, _ := NewWithPath(tmpDir)
+
+	state := domain.NewSessionState("run1", sampleAdventure())
+	_ = store.SaveSession(state)
+	info, err := store.SaveSnapshot(state, "camp")
+	if err != nil {
+		t.Fatalf("SaveSnapshot: %v", err)
+	}
+	if err := store.RenameSession("run1", "run2"); err != nil {
+		t.Fatalf("RenameSession: %v", err)
+	}
+	list, _ := store.ListSnapshots("run2")
+	if len(list) != 1 || list[0].ID != info.ID || list[0].Session != "run2" {
+		t.Fatalf("snapshots after rename = %+v", list)
+	}
+	if list, _ := store.ListSnapshots("run1"); len(list) != 0 {
+		t.Errorf("old name kept %d snapshots", len(list))
+	}
+
+	if err := store.DeleteSession("run2"); err != nil {
+		t.Fatalf("DeleteSession: %v", err)
+	}
+	_ = store.SaveSession(domain.NewSessionState("run2", sampleAdventure()))
+	if list, _ := store.ListSnapshots("run2"); len(list) != 0 {
+		t.Errorf("a new session reusing the name inherited %d snapshots", len(list))
+	}
+	if _, err := store.ListSnapshots("../etc"); err == nil {
+		t.Error("a traversal name should be rejected")
+	}
+}
@@ -172,6 +172,18 @@ func (s *Storage) mergeEnvConfig(config *domain.Config) {
 	if apiKey := os.Getenv("GOOGLE_API_KEY"); apiKey != "" && config.GeminiAPIKey == "" {
 		config.GeminiAPIKey = apiKey
 	}
+	if baseURL := os.Getenv("THAIM_LOCAL_BASE_URL"); baseURL != "" {
+		config.LocalBaseURL = baseURL
+	}
+	if apiKey := os.Getenv("THAIM_LOCAL_API_KEY"); apiKey != "" {
+		config.LocalAPIKey = apiKey
+	}
+	if mode := os.Getenv("THAIM_CASSETTE_MODE"); mode != "" {
+		config.Cassette.Mode = strings.ToLower(mode)
+	}
+	if path := os.Getenv("THAIM_CASSETTE"); path != "" {
+		config.Cassette.Path = path
+	}
 }
 
 // SaveConfig writes the config as organized YAML (secrets stripped) to the
@@ -238,9 +250,9 @@ func (s *Storage) SaveSession(state *domain.SessionState) error {
 	return nil
 }
 
-// DeleteSession removes a persisted session and its saved novelization (so a
-// later session reusing the name can't inherit a stale novel). The journal is
-// intentionally left as a historical record, matching prior behavior.
+// DeleteSession removes a persisted session, its saved novelization and its
+// snapshots (so a later session reusing the name can't inherit them). The
+// journal is intentionally left as a historical record, matching prior behavior.
 func (s *Storage) DeleteSession(name string) error {
 	if err := os.Remove(s.sessionPath(name)); err != nil {
 		if os.IsNotExist(err) {
@@ -249,6 +261,9 @@ func (s *Storage) DeleteSession(name string) error {
 		return fmt.Errorf("failed to delete session file: %w", err)
 	}
 	_ = s.DeleteNovel(name)
+	if dir, err := s.sessionSnapshotsDir(name); err == nil {
+		_ = os.RemoveAll(dir)
+	}
 
 	return nil
 }
@@ -295,6 +310,18 @@ func (s *Storage) RenameSession(oldName, newName string) error {
 	if err := os.Rename(oldNovel, newNovel); err != nil && !os.IsNotExist(err) {
 		return fmt.Errorf("failed to move session novel: %w", err)
 	}
+	// And its snapshots.
+	oldSnaps, err := s.sessionSnapshotsDir(oldName)
+	if err != nil {
+		return err
+	}
+	newSnaps, err := s.sessionSnapshotsDir(newName)
+	if err != nil {
+		return err
+	}
+	if err := os.Rename(oldSnaps, newSnaps); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to move session snapshots: %w", err)
+	}
 	return s.DeleteSession(oldName)
 }
 